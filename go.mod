@@ -9,12 +9,17 @@ require (
 	github.com/sirupsen/logrus v1.9.2
 	github.com/telekom-mms/tnd v0.1.0
 	github.com/vishvananda/netlink v1.1.0
+	github.com/vishvananda/netns v0.0.4
 	golang.org/x/sys v0.8.0
+	google.golang.org/grpc v1.56.3
+	google.golang.org/protobuf v1.30.0
 )
 
 require (
-	github.com/vishvananda/netns v0.0.4 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
 	golang.org/x/mod v0.10.0 // indirect
 	golang.org/x/net v0.10.0 // indirect
+	golang.org/x/text v0.9.0 // indirect
 	golang.org/x/tools v0.9.2 // indirect
+	google.golang.org/genproto v0.0.0-20230410155749-daa745c078e1 // indirect
 )
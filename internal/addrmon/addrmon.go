@@ -1,6 +1,8 @@
 package addrmon
 
 import (
+	"context"
+	"fmt"
 	"net"
 
 	log "github.com/sirupsen/logrus"
@@ -18,7 +20,8 @@ type Update struct {
 type AddrMon struct {
 	updates chan *Update
 	upsDone chan struct{}
-	done    chan struct{}
+	done    <-chan struct{}
+	cancel  context.CancelFunc
 }
 
 // sendUpdate sends an address update
@@ -30,27 +33,24 @@ func (a *AddrMon) sendUpdate(update *Update) {
 }
 
 // RegisterAddrUpdates registers for addr update events
-var RegisterAddrUpdates = func(a *AddrMon) chan netlink.AddrUpdate {
+var RegisterAddrUpdates = func(a *AddrMon) (chan netlink.AddrUpdate, error) {
 	// register for addr update events
 	events := make(chan netlink.AddrUpdate)
 	options := netlink.AddrSubscribeOptions{
 		ListExisting: true,
 	}
 	if err := netlink.AddrSubscribeWithOptions(events, a.upsDone, options); err != nil {
-		log.WithError(err).Fatal("AddrMon address subscribe error")
+		return nil, err
 	}
 
-	return events
+	return events, nil
 }
 
 // start starts the address monitor
-func (a *AddrMon) start() {
+func (a *AddrMon) start(events chan netlink.AddrUpdate) {
 	defer close(a.updates)
 	defer close(a.upsDone)
 
-	// register for addr update events
-	events := RegisterAddrUpdates(a)
-
 	// handle events
 	for {
 		select {
@@ -58,7 +58,12 @@ func (a *AddrMon) start() {
 			if !ok {
 				// unexpected close of events, try to re-open
 				log.Error("AddrMon got unexpected close of addr events")
-				events = RegisterAddrUpdates(a)
+				var err error
+				events, err = RegisterAddrUpdates(a)
+				if err != nil {
+					log.WithError(err).Error("AddrMon address subscribe error")
+					return
+				}
 				break
 			}
 
@@ -85,14 +90,24 @@ func (a *AddrMon) start() {
 	}
 }
 
-// Start starts the address monitor
-func (a *AddrMon) Start() {
-	go a.start()
+// Start starts the address monitor, it runs until Stop is called or ctx is
+// canceled
+func (a *AddrMon) Start(ctx context.Context) error {
+	// register for addr update events
+	events, err := RegisterAddrUpdates(a)
+	if err != nil {
+		return fmt.Errorf("AddrMon address subscribe error: %w", err)
+	}
+
+	ctx, a.cancel = context.WithCancel(ctx)
+	a.done = ctx.Done()
+	go a.start(events)
+	return nil
 }
 
 // Stop stops the address monitor
 func (a *AddrMon) Stop() {
-	close(a.done)
+	a.cancel()
 	for range a.updates {
 		// wait for channel close
 	}
@@ -108,6 +123,5 @@ func NewAddrMon() *AddrMon {
 	return &AddrMon{
 		updates: make(chan *Update),
 		upsDone: make(chan struct{}),
-		done:    make(chan struct{}),
 	}
 }
@@ -1,6 +1,8 @@
 package addrmon
 
 import (
+	"context"
+	"errors"
 	"log"
 	"testing"
 
@@ -12,10 +14,12 @@ func TestAddrMonStartStop(t *testing.T) {
 	addrMon := NewAddrMon()
 
 	// test without AddrUpdates
-	RegisterAddrUpdates = func(a *AddrMon) chan netlink.AddrUpdate {
-		return nil
+	RegisterAddrUpdates = func(a *AddrMon) (chan netlink.AddrUpdate, error) {
+		return nil, nil
+	}
+	if err := addrMon.Start(context.Background()); err != nil {
+		t.Fatal(err)
 	}
-	addrMon.Start()
 	addrMon.Stop()
 
 	// helper function for AddrUpdates
@@ -33,12 +37,14 @@ func TestAddrMonStartStop(t *testing.T) {
 
 	// test with AddrUpdates
 	addrMon = NewAddrMon()
-	RegisterAddrUpdates = func(a *AddrMon) chan netlink.AddrUpdate {
+	RegisterAddrUpdates = func(a *AddrMon) (chan netlink.AddrUpdate, error) {
 		updates := make(chan netlink.AddrUpdate)
 		go addrUpdates(updates, a.upsDone)
-		return updates
+		return updates, nil
+	}
+	if err := addrMon.Start(context.Background()); err != nil {
+		t.Fatal(err)
 	}
-	addrMon.Start()
 	for i := 0; i < 3; i++ {
 		log.Println(<-addrMon.Updates())
 	}
@@ -47,7 +53,7 @@ func TestAddrMonStartStop(t *testing.T) {
 	// test with unexpected close and AddrUpdates
 	addrMon = NewAddrMon()
 	runOnce := false
-	RegisterAddrUpdates = func(a *AddrMon) chan netlink.AddrUpdate {
+	RegisterAddrUpdates = func(a *AddrMon) (chan netlink.AddrUpdate, error) {
 		updates := make(chan netlink.AddrUpdate)
 		if !runOnce {
 			runOnce = true
@@ -55,13 +61,43 @@ func TestAddrMonStartStop(t *testing.T) {
 		} else {
 			go addrUpdates(updates, a.upsDone)
 		}
-		return updates
+		return updates, nil
+	}
+	if err := addrMon.Start(context.Background()); err != nil {
+		t.Fatal(err)
 	}
-	addrMon.Start()
 	log.Println(<-addrMon.Updates())
 	addrMon.Stop()
 }
 
+// TestAddrMonStartError tests Start of AddrMon with register error
+func TestAddrMonStartError(t *testing.T) {
+	RegisterAddrUpdates = func(a *AddrMon) (chan netlink.AddrUpdate, error) {
+		return nil, errors.New("test error")
+	}
+	addrMon := NewAddrMon()
+	if err := addrMon.Start(context.Background()); err == nil {
+		t.Error("start should fail")
+	}
+}
+
+// TestAddrMonContext tests cancelling the context of AddrMon
+func TestAddrMonContext(t *testing.T) {
+	RegisterAddrUpdates = func(a *AddrMon) (chan netlink.AddrUpdate, error) {
+		return nil, nil
+	}
+	addrMon := NewAddrMon()
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := addrMon.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	for range addrMon.Updates() {
+		// wait for channel close
+	}
+	addrMon.Stop()
+}
+
 // TestAddrMonUpdates tests Updates of AddrMon
 func TestAddrMonUpdates(t *testing.T) {
 	addrMon := NewAddrMon()
@@ -76,8 +112,7 @@ func TestAddrMonUpdates(t *testing.T) {
 func TestNewAddrMon(t *testing.T) {
 	addrMon := NewAddrMon()
 	if addrMon.updates == nil ||
-		addrMon.upsDone == nil ||
-		addrMon.done == nil {
+		addrMon.upsDone == nil {
 
 		t.Errorf("got nil, want != nil")
 	}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"io"
+	"math"
+)
+
+const (
+	// CompressionThreshold is the payload length above which payloads
+	// are compressed
+	CompressionThreshold = 512
+
+	// MaxUncompressedLength is the maximum allowed length of a compressed
+	// message payload after decompression
+	MaxUncompressedLength = math.MaxUint16
+)
+
+// compress returns the compressed payload p
+func compress(p []byte) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	w, err := flate.NewWriter(buf, flate.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(p); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress returns the decompressed payload p
+func decompress(p []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(p))
+	defer func() { _ = r.Close() }()
+
+	b, err := io.ReadAll(io.LimitReader(r, MaxUncompressedLength+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > MaxUncompressedLength {
+		return nil, errors.New("invalid uncompressed message length")
+	}
+	return b, nil
+}
+
+// NewCompressedMessage returns a new message with type t and payload p. If p
+// is larger than CompressionThreshold, p is compressed and the message is
+// flagged as compressed. It returns nil if p is too long
+func NewCompressedMessage(t uint16, p []byte) *Message {
+	if len(p) <= CompressionThreshold {
+		return NewMessage(t, p)
+	}
+	if len(p) > MaxUncompressedLength {
+		return nil
+	}
+
+	c, err := compress(p)
+	if err != nil {
+		return nil
+	}
+	m := NewMessage(t, c)
+	if m == nil {
+		return nil
+	}
+	m.Flags |= FlagCompressed
+	return m
+}
+
+// Decompress decompresses the payload of m if it is flagged as compressed
+func (m *Message) Decompress() error {
+	if m.Flags&FlagCompressed == 0 {
+		return nil
+	}
+	b, err := decompress(m.Value)
+	if err != nil {
+		return err
+	}
+	m.Flags &^= FlagCompressed
+	m.Length = uint32(len(b))
+	m.Value = b
+	return nil
+}
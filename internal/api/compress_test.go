@@ -0,0 +1,99 @@
+package api
+
+import (
+	"bytes"
+	"net"
+	"testing"
+)
+
+// TestNewCompressedMessage tests NewCompressedMessage
+func TestNewCompressedMessage(t *testing.T) {
+	// test small payload, not compressed
+	small := []byte("small payload")
+	m := NewCompressedMessage(TypeOK, small)
+	if m.Flags&FlagCompressed != 0 || !bytes.Equal(m.Value, small) {
+		t.Errorf("small payload should not be compressed")
+	}
+
+	// test large payload, compressed
+	large := bytes.Repeat([]byte("large payload "), 1000)
+	m = NewCompressedMessage(TypeOK, large)
+	if m.Flags&FlagCompressed == 0 || len(m.Value) > MaxPayloadLength {
+		t.Errorf("large payload should be compressed")
+	}
+	if err := m.Decompress(); err != nil {
+		t.Fatal(err)
+	}
+	if m.Flags&FlagCompressed != 0 || !bytes.Equal(m.Value, large) {
+		t.Errorf("got %s, want %s", m.Value, large)
+	}
+
+	// test too long payload
+	if m := NewCompressedMessage(TypeOK,
+		make([]byte, MaxUncompressedLength+1)); m != nil {
+		t.Errorf("got %v, want nil", m)
+	}
+}
+
+// TestMessageDecompressInvalid tests Decompress of Message with invalid data
+func TestMessageDecompressInvalid(t *testing.T) {
+	m := NewMessage(TypeOK, []byte("not compressed"))
+	m.Flags |= FlagCompressed
+	if err := m.Decompress(); err == nil {
+		t.Errorf("invalid compressed data should return error")
+	}
+}
+
+// TestReadWriteCompressedMessage tests ReadMessage and WriteMessage with
+// compressed messages
+func TestReadWriteCompressedMessage(t *testing.T) {
+	payload := bytes.Repeat([]byte("payload"), 1000)
+	want := NewCompressedMessage(TypeVPNConfigUpdate, payload)
+	want.Flags |= FlagAcceptCompression
+
+	buf := new(bytes.Buffer)
+	if err := WriteMessage(buf, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadMessage(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Header != want.Header {
+		t.Errorf("got %v, want %v", got.Header, want.Header)
+	}
+	if err := got.Decompress(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Value, payload) {
+		t.Errorf("got %s, want %s", got.Value, payload)
+	}
+}
+
+// TestRequestCloseCompressed tests Close of Request with compressed reply
+func TestRequestCloseCompressed(t *testing.T) {
+	c1, c2 := net.Pipe()
+	msg := NewMessage(TypeVPNConfigUpdate, nil)
+	msg.Flags |= FlagAcceptCompression
+	reply := bytes.Repeat([]byte("reply"), 1000)
+	req := &Request{
+		msg:  msg,
+		conn: c1,
+	}
+	req.Reply(reply)
+	go req.Close()
+
+	got, err := ReadMessage(c2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Flags&FlagCompressed == 0 {
+		t.Errorf("reply should be compressed")
+	}
+	if err := got.Decompress(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Value, reply) {
+		t.Errorf("got %s, want %s", got.Value, reply)
+	}
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// ProtocolVersion is the version of the API protocol
+	ProtocolVersion = 1
+
+	// MinProtocolVersion is the oldest version of the API protocol the
+	// server accepts in a handshake
+	MinProtocolVersion = 1
+)
+
+// Capability flags of clients and server
+const (
+	// CapabilityCompression indicates support of compressed messages
+	CapabilityCompression uint32 = 1 << iota
+
+	// CapabilitySubscribe indicates support of subscriptions
+	CapabilitySubscribe
+
+	// CapabilityChunking indicates support of chunked messages
+	CapabilityChunking
+
+	// CapabilityCancel indicates support of request timeouts and Cancel
+	// messages
+	CapabilityCancel
+
+	// CapabilityChallenge indicates support of the challenge-response
+	// authentication of VPN config updates
+	CapabilityChallenge
+)
+
+// Capabilities are the capabilities of this API implementation
+const Capabilities = CapabilityCompression | CapabilitySubscribe |
+	CapabilityChunking | CapabilityCancel | CapabilityChallenge
+
+// challengeLength is the length of challenges in Hello messages
+const challengeLength = 32
+
+// ErrHandshakeRejected is the error returned by Handshake if the server
+// rejects the handshake, e.g., because of an unsupported protocol version
+var ErrHandshakeRejected = errors.New("handshake rejected")
+
+// Hello is the payload of Hello messages that clients send before their
+// request and servers send as reply; Timeout is the timeout of the request
+// in milliseconds, the server's default if 0; Challenge is the random
+// challenge of the server for clients with the Challenge capability
+type Hello struct {
+	Version      int
+	Capabilities uint32
+	Timeout      int64  `json:",omitempty"`
+	Challenge    []byte `json:",omitempty"`
+}
+
+// message returns h as Hello message
+func (h *Hello) message() *Message {
+	b, err := json.Marshal(h)
+	if err != nil {
+		return nil
+	}
+	return NewMessage(TypeHello, b)
+}
+
+// NewHelloMessage returns a new Hello message with version, capabilities and
+// timeout
+func NewHelloMessage(version int, capabilities uint32, timeout time.Duration) *Message {
+	h := &Hello{
+		Version:      version,
+		Capabilities: capabilities,
+		Timeout:      timeout.Milliseconds(),
+	}
+	return h.message()
+}
+
+// ChallengeResponse returns the response to challenge with the shared
+// secret, the HMAC-SHA256 of challenge
+func ChallengeResponse(secret, challenge []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(challenge)
+	return mac.Sum(nil)
+}
+
+// NewCancelMessage returns a new Cancel message
+func NewCancelMessage() *Message {
+	return NewMessage(TypeCancel, nil)
+}
+
+// requestTimeout returns the timeout of requests of clients that sent h, it
+// is limited to serverTimeout
+func (h *Hello) requestTimeout() time.Duration {
+	timeout := time.Duration(h.Timeout) * time.Millisecond
+	if timeout <= 0 || timeout > serverTimeout {
+		return serverTimeout
+	}
+	return timeout
+}
+
+// parseHello parses the Hello payload in b
+func parseHello(b []byte) (*Hello, error) {
+	h := &Hello{}
+	if err := json.Unmarshal(b, h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// handleHello handles the Hello message msg from the client on conn, it
+// replies with the negotiated version, the capabilities of the server, the
+// request timeout and a new challenge if the client supports it and returns
+// the negotiated version, the capabilities of the client, the request
+// timeout and the challenge
+func (s *Server) handleHello(conn net.Conn, msg *Message) (*Hello, error) {
+	h, err := parseHello(msg.Value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hello: %w", err)
+	}
+	if h.Version < MinProtocolVersion {
+		e := NewError([]byte("unsupported protocol version"))
+		if err := WriteMessage(conn, e); err != nil {
+			log.WithError(err).Error("Daemon message send error")
+		}
+		return nil, fmt.Errorf("unsupported protocol version %d", h.Version)
+	}
+
+	// use the older version if the client is newer
+	version := h.Version
+	if version > ProtocolVersion {
+		version = ProtocolVersion
+	}
+	reply := &Hello{
+		Version:      version,
+		Capabilities: Capabilities,
+		Timeout:      h.requestTimeout().Milliseconds(),
+	}
+	if h.Capabilities&CapabilityChallenge != 0 {
+		reply.Challenge = make([]byte, challengeLength)
+		if _, err := rand.Read(reply.Challenge); err != nil {
+			return nil, fmt.Errorf("could not create challenge: %w", err)
+		}
+	}
+	if err := WriteMessage(conn, reply.message()); err != nil {
+		return nil, err
+	}
+	reply.Capabilities = h.Capabilities
+	return reply, nil
+}
+
+// Handshake sends a Hello message with the capabilities of the client and
+// the timeout of its request on conn and returns the Hello reply of the
+// server with the negotiated version, the capabilities of the server and the
+// request timeout it enforces. Servers without handshake support close the
+// connection, the client can connect again and send its request without
+// handshake
+func Handshake(conn net.Conn, capabilities uint32, timeout time.Duration) (*Hello, error) {
+	if err := WriteMessage(conn,
+		NewHelloMessage(ProtocolVersion, capabilities, timeout)); err != nil {
+		return nil, err
+	}
+	reply, err := ReadMessage(conn)
+	if err != nil {
+		return nil, err
+	}
+	switch reply.Type {
+	case TypeHello:
+		return parseHello(reply.Value)
+	case TypeError:
+		return nil, fmt.Errorf("%w: %s", ErrHandshakeRejected, reply.Value)
+	}
+	return nil, fmt.Errorf("unexpected reply type %d", reply.Type)
+}
@@ -0,0 +1,219 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"net"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// startTestServer starts a server with a temporary sock file
+func startTestServer(t *testing.T) (*Server, string) {
+	sockFile := filepath.Join(t.TempDir(), "test.sock")
+	server := NewServer(sockFile)
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return server, sockFile
+}
+
+// TestHandshake tests Handshake and handleHello of Server
+func TestHandshake(t *testing.T) {
+	server, sockFile := startTestServer(t)
+	defer server.Stop()
+
+	// handshake and request
+	conn, err := net.Dial("unix", sockFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	hello, err := Handshake(conn, CapabilityCompression, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Hello{
+		Version:      ProtocolVersion,
+		Capabilities: Capabilities,
+		Timeout:      1000,
+	}
+	if !reflect.DeepEqual(*hello, want) {
+		t.Errorf("got %v, want %v", hello, want)
+	}
+	if err := WriteMessage(conn,
+		NewMessage(TypeVPNConfigUpdate, nil)); err != nil {
+		t.Fatal(err)
+	}
+	r := <-server.Requests()
+	if r.Version() != ProtocolVersion || r.Challenge() != nil {
+		t.Errorf("got %d, %v, want %d, no challenge", r.Version(),
+			r.Challenge(), ProtocolVersion)
+	}
+	r.Close()
+
+	// newer client
+	conn2, err := net.Dial("unix", sockFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn2.Close() }()
+	if err := WriteMessage(conn2,
+		NewHelloMessage(ProtocolVersion+1, 0, 0)); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := ReadMessage(conn2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = conn2.Close()
+	if h, err := parseHello(reply.Value); err != nil ||
+		h.Version != ProtocolVersion {
+		t.Errorf("got %v, %v, want version %d", h, err, ProtocolVersion)
+	}
+
+	// unsupported version
+	conn3, err := net.Dial("unix", sockFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn3.Close() }()
+	if err := WriteMessage(conn3,
+		NewHelloMessage(MinProtocolVersion-1, 0, 0)); err != nil {
+		t.Fatal(err)
+	}
+	reply, err = ReadMessage(conn3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Type != TypeError {
+		t.Errorf("got type %d, want %d", reply.Type, TypeError)
+	}
+}
+
+// TestHandshakeChallenge tests the challenge in Handshake and handleHello of
+// Server
+func TestHandshakeChallenge(t *testing.T) {
+	server, sockFile := startTestServer(t)
+	defer server.Stop()
+
+	// get challenges of two handshakes
+	challenges := [][]byte{}
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("unix", sockFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = conn.Close() }()
+		hello, err := Handshake(conn, CapabilityChallenge, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(hello.Challenge) != challengeLength {
+			t.Fatalf("got challenge %v, want %d bytes", hello.Challenge,
+				challengeLength)
+		}
+		if err := WriteMessage(conn,
+			NewMessage(TypeVPNConfigUpdate, nil)); err != nil {
+			t.Fatal(err)
+		}
+		r := <-server.Requests()
+		if !bytes.Equal(r.Challenge(), hello.Challenge) {
+			t.Errorf("got %v, want %v", r.Challenge(), hello.Challenge)
+		}
+		r.Close()
+		challenges = append(challenges, hello.Challenge)
+	}
+	if bytes.Equal(challenges[0], challenges[1]) {
+		t.Error("challenges should differ")
+	}
+}
+
+// TestChallengeResponse tests ChallengeResponse
+func TestChallengeResponse(t *testing.T) {
+	// test vector of RFC 4231, test case 2
+	got := ChallengeResponse([]byte("Jefe"),
+		[]byte("what do ya want for nothing?"))
+	want := "5bdcc146bf60754e6a042426089575c7" +
+		"5a003f089d2739839dec58b964ec3843"
+	if hex.EncodeToString(got) != want {
+		t.Errorf("got %x, want %s", got, want)
+	}
+}
+
+// TestHandshakeLegacy tests requests without handshake
+func TestHandshakeLegacy(t *testing.T) {
+	server, sockFile := startTestServer(t)
+	defer server.Stop()
+
+	conn, err := net.Dial("unix", sockFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	if err := WriteMessage(conn,
+		NewMessage(TypeVPNConfigUpdate, nil)); err != nil {
+		t.Fatal(err)
+	}
+	r := <-server.Requests()
+	if r.Version() != 0 {
+		t.Errorf("got %d, want 0", r.Version())
+	}
+	r.Close()
+}
+
+// TestHelloRequestTimeout tests requestTimeout of Hello
+func TestHelloRequestTimeout(t *testing.T) {
+	for _, test := range []struct {
+		timeout int64
+		want    time.Duration
+	}{
+		{0, serverTimeout},
+		{-1, serverTimeout},
+		{500, 500 * time.Millisecond},
+		{serverTimeout.Milliseconds() + 1, serverTimeout},
+	} {
+		h := &Hello{Timeout: test.timeout}
+		if got := h.requestTimeout(); got != test.want {
+			t.Errorf("%d: got %s, want %s", test.timeout, got, test.want)
+		}
+	}
+}
+
+// TestHandshakeErrors tests Handshake with errors
+func TestHandshakeErrors(t *testing.T) {
+	// server without handshake support closes the connection
+	c1, c2 := net.Pipe()
+	go func() {
+		_, _ = ReadMessage(c2)
+		_ = c2.Close()
+	}()
+	if _, err := Handshake(c1, Capabilities, 0); err == nil ||
+		errors.Is(err, ErrHandshakeRejected) {
+		t.Errorf("got %v, want connection error", err)
+	}
+
+	// rejected handshake
+	c1, c2 = net.Pipe()
+	go func() {
+		_, _ = ReadMessage(c2)
+		_ = WriteMessage(c2, NewError([]byte("unsupported")))
+	}()
+	if _, err := Handshake(c1, Capabilities, 0); !errors.Is(err, ErrHandshakeRejected) {
+		t.Errorf("got %v, want %v", err, ErrHandshakeRejected)
+	}
+
+	// unexpected reply
+	c1, c2 = net.Pipe()
+	go func() {
+		_, _ = ReadMessage(c2)
+		_ = WriteMessage(c2, NewOK(nil))
+	}()
+	if _, err := Handshake(c1, Capabilities, 0); err == nil {
+		t.Error("unexpected reply should fail")
+	}
+}
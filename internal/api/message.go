@@ -4,11 +4,21 @@ import (
 	"encoding/binary"
 	"errors"
 	"io"
+	"net"
+	"sync"
 )
 
 const (
+	// HeaderLength is the length of a message header
+	HeaderLength = 4
+
 	// MaxPayloadLength is the maximum allowed length of a message payload
+	// or of a chunk of a chunked message payload
 	MaxPayloadLength = 2048
+
+	// MaxMessageLength is the maximum allowed length of a chunked message
+	// payload
+	MaxMessageLength = 1024 * 1024
 )
 
 // Message types
@@ -17,13 +27,35 @@ const (
 	TypeOK
 	TypeError
 	TypeVPNConfigUpdate
+	TypeSubscribe
+	TypeEvent
+	TypeHello
+	TypeCancel
 	TypeUndefined
 )
 
-// Header is a message header
+// Message flags, they are sent in the upper bits of the message type
+const (
+	// FlagAcceptCompression indicates that the sender of a request
+	// accepts compressed replies
+	FlagAcceptCompression uint16 = 1 << 14
+
+	// FlagCompressed indicates that the message payload is compressed
+	FlagCompressed uint16 = 1 << 15
+
+	// FlagMore indicates that more chunks of the message payload follow
+	FlagMore uint16 = 1 << 13
+
+	// flagsMask is the mask of all flags in the message type
+	flagsMask = FlagAcceptCompression | FlagCompressed | FlagMore
+)
+
+// Header is a message header, Length is the length of the complete payload
+// that exceeds MaxPayloadLength in chunked messages
 type Header struct {
 	Type   uint16
-	Length uint16
+	Length uint32
+	Flags  uint16
 }
 
 // Message is an API message
@@ -40,7 +72,27 @@ func NewMessage(t uint16, p []byte) *Message {
 	return &Message{
 		Header: Header{
 			Type:   t,
-			Length: uint16(len(p)),
+			Length: uint32(len(p)),
+		},
+		Value: p,
+	}
+}
+
+// NewChunkedMessage returns a new message with type t and payload p that is
+// sent in chunks if p is larger than MaxPayloadLength, it returns nil if p is
+// larger than MaxMessageLength. Chunked messages must only be sent to peers
+// with CapabilityChunking
+func NewChunkedMessage(t uint16, p []byte) *Message {
+	if len(p) <= MaxPayloadLength {
+		return NewMessage(t, p)
+	}
+	if len(p) > MaxMessageLength {
+		return nil
+	}
+	return &Message{
+		Header: Header{
+			Type:   t,
+			Length: uint32(len(p)),
 		},
 		Value: p,
 	}
@@ -56,54 +108,173 @@ func NewError(p []byte) *Message {
 	return NewMessage(TypeError, p)
 }
 
-// ReadMessage returns the next message from r
-func ReadMessage(r io.Reader) (*Message, error) {
+// frame contains scratch buffers for reading and writing messages
+type frame struct {
+	header [HeaderLength]byte
+	bufs   [2][]byte
+	iov    net.Buffers
+}
+
+// framePool contains reusable frames, so reading and writing messages does
+// not allocate memory
+var framePool = sync.Pool{
+	New: func() any {
+		return &frame{}
+	},
+}
+
+// messagePool contains reusable messages with payload buffers of
+// MaxPayloadLength, see GetMessage and PutMessage
+var messagePool = sync.Pool{
+	New: func() any {
+		return &Message{
+			Value: make([]byte, 0, MaxPayloadLength),
+		}
+	},
+}
+
+// GetMessage returns an empty message from the message pool. The message
+// can be reused with ReadMessageInto and must be returned with PutMessage
+// when it is not used anymore
+func GetMessage() *Message {
+	return messagePool.Get().(*Message)
+}
+
+// PutMessage returns message m to the message pool, m must not be used
+// after this call
+func PutMessage(m *Message) {
+	if m == nil || cap(m.Value) != MaxPayloadLength {
+		// not from pool
+		return
+	}
+	m.Header = Header{}
+	m.Value = m.Value[:0]
+	messagePool.Put(m)
+}
+
+// readChunk reads the next chunk of a message from r, appends its payload to
+// b and returns the header of the chunk
+func readChunk(r io.Reader, f *frame, b []byte) (Header, []byte, error) {
 	// read header
-	h := &Header{}
-	err := binary.Read(r, binary.LittleEndian, h)
-	if err != nil {
-		return nil, err
+	if _, err := io.ReadFull(r, f.header[:]); err != nil {
+		return Header{}, b, err
+	}
+	t := binary.LittleEndian.Uint16(f.header[0:2])
+	h := Header{
+		Type:   t &^ flagsMask,
+		Length: uint32(binary.LittleEndian.Uint16(f.header[2:4])),
+		Flags:  t & flagsMask,
 	}
 
-	// check if message is valid
+	// check if chunk is valid
 	if h.Type == TypeNone || h.Type >= TypeUndefined {
-		return nil, errors.New("invalid message type")
+		return h, b, errors.New("invalid message type")
 	}
 	if h.Length > MaxPayloadLength {
-		return nil, errors.New("invalid message length")
+		return h, b, errors.New("invalid message length")
+	}
+	n := len(b) + int(h.Length)
+	if n > MaxMessageLength {
+		return h, b, errors.New("invalid chunked message length")
 	}
 
 	// read payload
-	b := make([]byte, h.Length)
-	_, err = io.ReadFull(r, b)
-	if err != nil {
-		return nil, err
+	if cap(b) < n {
+		grown := make([]byte, len(b), 2*cap(b)+int(h.Length))
+		copy(grown, b)
+		b = grown
 	}
-
-	// return message
-	m := &Message{
-		Header: *h,
-		Value:  b,
+	l := len(b)
+	b = b[:n]
+	if _, err := io.ReadFull(r, b[l:]); err != nil {
+		return h, b, err
 	}
-	return m, nil
+	return h, b, nil
 }
 
-// WriteMessage writes message m to r
-func WriteMessage(w io.Writer, m *Message) error {
-	// write header
-	err := binary.Write(w, binary.LittleEndian, m.Header)
+// ReadMessageInto reads the next message from r into m, reusing the payload
+// buffer of m if it is large enough. The chunks of chunked messages are
+// combined into a single message
+func ReadMessageInto(r io.Reader, m *Message) error {
+	f := framePool.Get().(*frame)
+	defer framePool.Put(f)
+
+	// read first chunk
+	h, b, err := readChunk(r, f, m.Value[:0])
 	if err != nil {
 		return err
 	}
 
-	// write payload
-	if len(m.Value) == 0 {
-		return nil
+	// read more chunks of the same type
+	for c := h; c.Flags&FlagMore != 0; {
+		c, b, err = readChunk(r, f, b)
+		if err != nil {
+			return err
+		}
+		if c.Type != h.Type {
+			return errors.New("invalid chunk type")
+		}
 	}
-	err = binary.Write(w, binary.LittleEndian, m.Value)
-	if err != nil {
-		return err
+
+	h.Length = uint32(len(b))
+	h.Flags &^= FlagMore
+	m.Header = h
+	m.Value = b
+	return nil
+}
+
+// ReadMessage returns the next message from r
+func ReadMessage(r io.Reader) (*Message, error) {
+	m := &Message{}
+	if err := ReadMessageInto(r, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// writeChunk writes the chunk of a message with type t, flags, length and
+// payload p to w
+func writeChunk(w io.Writer, f *frame, t, flags, length uint16, p []byte) error {
+	// encode header
+	binary.LittleEndian.PutUint16(f.header[0:2], t|flags)
+	binary.LittleEndian.PutUint16(f.header[2:4], length)
+
+	// write header and payload
+	f.bufs[0] = f.header[:]
+	f.bufs[1] = p
+	f.iov = f.bufs[:]
+	if len(p) == 0 {
+		f.iov = f.bufs[:1]
+	}
+	_, err := f.iov.WriteTo(w)
+
+	// do not keep a reference to the payload in the pool
+	f.bufs[1] = nil
+	return err
+}
+
+// WriteMessage writes message m to w. Header and payload are written with a
+// single vectored write if w supports it, e.g., for unix socket connections.
+// Payloads larger than MaxPayloadLength are written in chunks
+func WriteMessage(w io.Writer, m *Message) error {
+	f := framePool.Get().(*frame)
+	defer framePool.Put(f)
+
+	if len(m.Value) <= MaxPayloadLength {
+		return writeChunk(w, f, m.Type, m.Flags, uint16(m.Length), m.Value)
 	}
 
+	// write chunks, all but the last one are flagged with more
+	for p := m.Value; len(p) > 0; {
+		c, flags := p, m.Flags
+		if len(c) > MaxPayloadLength {
+			c = p[:MaxPayloadLength]
+			flags |= FlagMore
+		}
+		if err := writeChunk(w, f, m.Type, flags, uint16(len(c)), c); err != nil {
+			return err
+		}
+		p = p[len(c):]
+	}
 	return nil
 }
@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"io"
 	"log"
 	"reflect"
 	"testing"
@@ -14,6 +15,10 @@ func TestNewMessage(t *testing.T) {
 		TypeOK,
 		TypeError,
 		TypeVPNConfigUpdate,
+		TypeSubscribe,
+		TypeEvent,
+		TypeHello,
+		TypeCancel,
 		TypeUndefined,
 	} {
 		log.Println("NewMessage with type", typ)
@@ -67,3 +72,184 @@ func TestReadWriteMessage(t *testing.T) {
 		t.Errorf("got %v, want %v", got, want)
 	}
 }
+
+// TestReadMessageInto tests ReadMessageInto
+func TestReadMessageInto(t *testing.T) {
+	buf := new(bytes.Buffer)
+	m := GetMessage()
+	defer PutMessage(m)
+
+	// test reading multiple messages into same message
+	for _, want := range []*Message{
+		NewMessage(TypeVPNConfigUpdate, []byte("some data")),
+		NewOK(nil),
+		NewError([]byte("error")),
+	} {
+		if err := WriteMessage(buf, want); err != nil {
+			t.Fatal(err)
+		}
+		if err := ReadMessageInto(buf, m); err != nil {
+			t.Fatal(err)
+		}
+		if m.Header != want.Header || !bytes.Equal(m.Value, want.Value) {
+			t.Errorf("got %v, want %v", m, want)
+		}
+	}
+
+	// test invalid messages
+	for _, invalid := range []*Message{
+		{Header: Header{Type: TypeNone}},
+		{Header: Header{Type: TypeUndefined}},
+		{Header: Header{Type: TypeOK, Length: MaxPayloadLength + 1}},
+	} {
+		buf.Reset()
+		if err := WriteMessage(buf, invalid); err != nil {
+			t.Fatal(err)
+		}
+		if err := ReadMessageInto(buf, m); err == nil {
+			t.Errorf("invalid message %v should return error", invalid)
+		}
+	}
+}
+
+// TestReadWriteChunkedMessage tests ReadMessageInto and WriteMessage with
+// chunked messages
+func TestReadWriteChunkedMessage(t *testing.T) {
+	buf := new(bytes.Buffer)
+	m := GetMessage()
+	defer PutMessage(m)
+
+	for _, length := range []int{
+		MaxPayloadLength + 1,
+		2 * MaxPayloadLength,
+		MaxMessageLength,
+	} {
+		p := make([]byte, length)
+		for i := range p {
+			p[i] = byte(i)
+		}
+		want := NewChunkedMessage(TypeVPNConfigUpdate, p)
+		want.Flags |= FlagAcceptCompression
+
+		buf.Reset()
+		if err := WriteMessage(buf, want); err != nil {
+			t.Fatal(err)
+		}
+		chunks := (length + MaxPayloadLength - 1) / MaxPayloadLength
+		if buf.Len() != length+chunks*HeaderLength {
+			t.Errorf("got %d bytes, want %d", buf.Len(),
+				length+chunks*HeaderLength)
+		}
+		if err := ReadMessageInto(buf, m); err != nil {
+			t.Fatal(err)
+		}
+		if m.Header != want.Header || !bytes.Equal(m.Value, want.Value) {
+			t.Errorf("got %v, want %v", m.Header, want.Header)
+		}
+	}
+
+	// small payload is not chunked
+	if m := NewChunkedMessage(TypeOK, []byte("small")); m == nil ||
+		m.Length != 5 {
+		t.Errorf("got %v, want small message", m)
+	}
+
+	// too long
+	if m := NewChunkedMessage(TypeOK,
+		make([]byte, MaxMessageLength+1)); m != nil {
+		t.Errorf("got %v, want nil", m)
+	}
+}
+
+// TestReadChunkedMessageInvalid tests ReadMessage with invalid chunks
+func TestReadChunkedMessageInvalid(t *testing.T) {
+	// different types
+	buf := new(bytes.Buffer)
+	first := NewMessage(TypeVPNConfigUpdate, []byte("first"))
+	first.Flags |= FlagMore
+	if err := WriteMessage(buf, first); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteMessage(buf, NewOK([]byte("second"))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadMessage(buf); err == nil {
+		t.Error("different chunk types should return error")
+	}
+
+	// too long
+	buf.Reset()
+	chunk := NewMessage(TypeVPNConfigUpdate, make([]byte, MaxPayloadLength))
+	chunk.Flags |= FlagMore
+	for i := 0; i <= MaxMessageLength/MaxPayloadLength; i++ {
+		if err := WriteMessage(buf, chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := ReadMessage(buf); err == nil {
+		t.Error("too long chunked message should return error")
+	}
+
+	// missing chunk
+	buf.Reset()
+	if err := WriteMessage(buf, first); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadMessage(buf); err == nil {
+		t.Error("missing chunk should return error")
+	}
+}
+
+// TestReadWriteMessageAllocs tests that reading and writing messages with a
+// pooled message does not allocate memory
+func TestReadWriteMessageAllocs(t *testing.T) {
+	buf := bytes.NewBuffer(make([]byte, 0, HeaderLength+MaxPayloadLength))
+	msg := NewMessage(TypeVPNConfigUpdate, make([]byte, MaxPayloadLength))
+	m := GetMessage()
+	defer PutMessage(m)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		buf.Reset()
+		if err := WriteMessage(buf, msg); err != nil {
+			t.Fatal(err)
+		}
+		if err := ReadMessageInto(buf, m); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("got %f allocs, want 0", allocs)
+	}
+}
+
+// BenchmarkWriteMessage benchmarks WriteMessage
+func BenchmarkWriteMessage(b *testing.B) {
+	msg := NewMessage(TypeVPNConfigUpdate, make([]byte, MaxPayloadLength))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := WriteMessage(io.Discard, msg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkReadMessageInto benchmarks ReadMessageInto
+func BenchmarkReadMessageInto(b *testing.B) {
+	buf := new(bytes.Buffer)
+	msg := NewMessage(TypeVPNConfigUpdate, make([]byte, MaxPayloadLength))
+	if err := WriteMessage(buf, msg); err != nil {
+		b.Fatal(err)
+	}
+	data := buf.Bytes()
+	r := bytes.NewReader(data)
+	m := GetMessage()
+	defer PutMessage(m)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.Reset(data)
+		if err := ReadMessageInto(r, m); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
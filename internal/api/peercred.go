@@ -0,0 +1,79 @@
+package api
+
+import (
+	"errors"
+	"net"
+	"os/user"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// userGroupIDs returns the group IDs of the user with uid, it can be
+// replaced in tests
+var userGroupIDs = func(uid uint32) ([]string, error) {
+	u, err := user.LookupId(strconv.FormatUint(uint64(uid), 10))
+	if err != nil {
+		return nil, err
+	}
+	return u.GroupIds()
+}
+
+// PeerCredentials returns the credentials of the client process connected
+// on conn, the kernel sets them when the client connects
+func PeerCredentials(conn net.Conn) (*unix.Ucred, error) {
+	uc, ok := conn.(*net.UnixConn)
+	if !ok {
+		return nil, errors.New("not a unix socket connection")
+	}
+	raw, err := uc.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+	var cred *unix.Ucred
+	var credErr error
+	if err := raw.Control(func(fd uintptr) {
+		cred, credErr = unix.GetsockoptUcred(int(fd),
+			unix.SOL_SOCKET, unix.SO_PEERCRED)
+	}); err != nil {
+		return nil, err
+	}
+	return cred, credErr
+}
+
+// AllowConfigUpdate returns whether a client with cred may send VPN config
+// updates: root, the user with uid owner and members of the group with gid
+// group, owner and group are -1 if not set
+func AllowConfigUpdate(cred *unix.Ucred, owner, group int) bool {
+	if cred.Uid == 0 {
+		return true
+	}
+	if owner >= 0 && int(cred.Uid) == owner {
+		return true
+	}
+	if group < 0 {
+		return false
+	}
+	if int(cred.Gid) == group {
+		return true
+	}
+
+	// check supplementary groups of the user
+	gids, err := userGroupIDs(cred.Uid)
+	if err != nil {
+		return false
+	}
+	g := strconv.Itoa(group)
+	for _, gid := range gids {
+		if gid == g {
+			return true
+		}
+	}
+	return false
+}
+
+// allowConfigUpdate returns whether a client with cred may send VPN config
+// updates: root, the owner of the sock file and members of its group
+func (s *Server) allowConfigUpdate(cred *unix.Ucred) bool {
+	return AllowConfigUpdate(cred, s.owner, s.group)
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// TestPeerCredentials tests PeerCredentials
+func TestPeerCredentials(t *testing.T) {
+	// not a unix socket
+	c1, c2 := net.Pipe()
+	defer func() { _ = c1.Close() }()
+	defer func() { _ = c2.Close() }()
+	if _, err := PeerCredentials(c1); err == nil {
+		t.Error("pipe should fail")
+	}
+
+	// unix socket
+	sockFile := filepath.Join(t.TempDir(), "test.sock")
+	listen, err := net.Listen("unix", sockFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = listen.Close() }()
+	go func() {
+		conn, err := net.Dial("unix", sockFile)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		_, _ = conn.Read(make([]byte, 1))
+	}()
+	conn, err := listen.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	cred, err := PeerCredentials(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int(cred.Uid) != os.Getuid() || int(cred.Gid) != os.Getgid() ||
+		int(cred.Pid) != os.Getpid() {
+		t.Errorf("got %+v, want uid %d, gid %d, pid %d",
+			cred, os.Getuid(), os.Getgid(), os.Getpid())
+	}
+}
+
+// TestServerAllowConfigUpdate tests allowConfigUpdate of Server
+func TestServerAllowConfigUpdate(t *testing.T) {
+	oldUserGroupIDs := userGroupIDs
+	defer func() { userGroupIDs = oldUserGroupIDs }()
+	userGroupIDs = func(uid uint32) ([]string, error) {
+		if uid == 1002 {
+			return []string{"1002", "2000"}, nil
+		}
+		return nil, errors.New("test error")
+	}
+
+	s := NewServer("test.sock")
+
+	// root only
+	for _, test := range []struct {
+		cred *unix.Ucred
+		want bool
+	}{
+		{&unix.Ucred{Uid: 0, Gid: 0}, true},
+		{&unix.Ucred{Uid: 1000, Gid: 1000}, false},
+		{&unix.Ucred{Uid: 1001, Gid: 2000}, false},
+		{&unix.Ucred{Uid: 1002, Gid: 1002}, false},
+	} {
+		if got := s.allowConfigUpdate(test.cred); got != test.want {
+			t.Errorf("%+v: got %t, want %t", test.cred, got, test.want)
+		}
+	}
+
+	// root, owner and group
+	s.SetOwner(1000)
+	s.SetGroup(2000)
+	for _, test := range []struct {
+		cred *unix.Ucred
+		want bool
+	}{
+		{&unix.Ucred{Uid: 0, Gid: 0}, true},
+		{&unix.Ucred{Uid: 1000, Gid: 1000}, true},
+		{&unix.Ucred{Uid: 1001, Gid: 2000}, true},
+		{&unix.Ucred{Uid: 1002, Gid: 1002}, true},
+		{&unix.Ucred{Uid: 1003, Gid: 1003}, false},
+	} {
+		if got := s.allowConfigUpdate(test.cred); got != test.want {
+			t.Errorf("%+v: got %t, want %t", test.cred, got, test.want)
+		}
+	}
+}
+
+// TestServerSetGroup tests SetGroup of Server
+func TestServerSetGroup(t *testing.T) {
+	sockFile := filepath.Join(t.TempDir(), "test.sock")
+	server := NewServer(sockFile)
+	server.SetGroup(os.Getgid())
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	fi, err := os.Stat(sockFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0770 {
+		t.Errorf("got %o, want %o", perm, 0770)
+	}
+}
@@ -1,17 +1,70 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net"
+	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/logging"
 )
 
+// abortTimeout is the timeout for sending the error reply of an aborted
+// request to the client
+const abortTimeout = time.Second
+
+// errRequestCanceled is the error of requests canceled by the client
+var errRequestCanceled = errors.New("request canceled")
+
+// newRequestError returns a new error message with msg and the request ID id
+func newRequestError(msg, id string) *Message {
+	return NewError([]byte(fmt.Sprintf("%s (request %s)", msg, id)))
+}
+
 // Request is a request from a client
 type Request struct {
+	// id identifies the request in log entries and error replies
+	id string
+
 	msg   *Message
 	reply []byte
 	err   string
 	conn  net.Conn
+
+	// ctx is canceled when the client cancels the request, closes the
+	// connection or the deadline of the request is exceeded
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// once ensures that only one reply is sent, either by Close or
+	// when the request is aborted
+	once sync.Once
+
+	// version is the protocol version negotiated with the client, 0 if
+	// the client did not send a handshake
+	version int
+
+	// chunking specifies if the client accepts chunked replies
+	chunking bool
+
+	// challenge is the challenge sent to the client in the handshake, nil
+	// if the client does not support it
+	challenge []byte
+}
+
+// ID returns the ID of the request
+func (r *Request) ID() string {
+	return r.id
+}
+
+// Challenge returns the challenge sent to the client in the handshake, the
+// client authenticates its request with the response to it, nil if the client
+// did not send a handshake or does not support challenges
+func (r *Request) Challenge() []byte {
+	return r.challenge
 }
 
 // Type returns the type of the request
@@ -19,6 +72,22 @@ func (r *Request) Type() uint16 {
 	return r.msg.Type
 }
 
+// Version returns the protocol version of the request, 0 if the client did
+// not send a handshake, e.g., an older vpnc-script
+func (r *Request) Version() int {
+	return r.version
+}
+
+// Context returns the context of the request, it is done when the client
+// canceled the request or its deadline is exceeded, so the daemon can skip
+// requests that nobody waits for anymore
+func (r *Request) Context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	return r.ctx
+}
+
 // Data returns the data in the API request
 func (r *Request) Data() []byte {
 	return r.msg.Value
@@ -34,9 +103,33 @@ func (r *Request) Error(msg string) {
 	r.err = msg
 }
 
-// sendOK sends an ok message back to the client
+// Failed returns whether the error reply message is set for this request
+func (r *Request) Failed() bool {
+	return r.err != ""
+}
+
+// acceptsCompression returns whether the client accepts compressed replies
+func (r *Request) acceptsCompression() bool {
+	return r.msg != nil && r.msg.Flags&FlagAcceptCompression != 0
+}
+
+// sendOK sends an ok message back to the client, the reply is compressed if
+// the client accepts it
 func (r *Request) sendOK() {
 	o := NewOK(r.reply)
+	if r.acceptsCompression() {
+		o = NewCompressedMessage(TypeOK, r.reply)
+	}
+	if o == nil && r.chunking {
+		o = NewChunkedMessage(TypeOK, r.reply)
+	}
+	if o == nil {
+		log.WithFields(log.Fields{
+			logging.RequestField: r.id,
+			"length":             len(r.reply),
+		}).Error("Daemon reply too long for client")
+		o = newRequestError("reply too long", r.id)
+	}
 	if err := WriteMessage(r.conn, o); err != nil {
 		log.WithError(err).Error("Daemon message send error")
 	}
@@ -44,21 +137,78 @@ func (r *Request) sendOK() {
 
 // sendError sends an error back to the client
 func (r *Request) sendError() {
-	e := NewError([]byte(r.err))
+	e := newRequestError(r.err, r.id)
 	if err := WriteMessage(r.conn, e); err != nil {
 		log.WithError(err).Error("Daemon message send error")
 	}
 }
 
+// abort sends the error err to the client and closes the connection if the
+// request is not closed yet, e.g., when the daemon did not handle the request
+// before its deadline
+func (r *Request) abort(err error) {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.once.Do(func() {
+		log.WithError(err).WithField(logging.RequestField, r.id).
+			Error("Daemon aborted client request")
+
+		// the deadline of the connection may be exceeded already
+		_ = r.conn.SetWriteDeadline(time.Now().Add(abortTimeout))
+		e := newRequestError(err.Error(), r.id)
+		if err := WriteMessage(r.conn, e); err != nil {
+			log.WithError(err).Error("Daemon message send error")
+		}
+		_ = r.conn.Close()
+	})
+}
+
+// watch watches the connection of the request for cancel messages of the
+// client until the request is closed, the connection deadline is the
+// deadline of the request
+func (r *Request) watch() {
+	m, err := ReadMessage(r.conn)
+	switch {
+	case err == nil && m.Type == TypeCancel:
+		r.abort(errRequestCanceled)
+	case errors.Is(err, net.ErrClosed):
+		// request closed
+	case errors.Is(err, context.DeadlineExceeded) || isTimeout(err):
+		r.abort(context.DeadlineExceeded)
+	default:
+		// client closed connection or sent invalid message, nobody
+		// waits for the reply
+		r.cancel()
+	}
+}
+
+// isTimeout returns whether err is a timeout error
+func isTimeout(err error) bool {
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
+
 // Close closes the API request
 func (r *Request) Close() {
 	defer func() {
-		_ = r.conn.Close()
+		if r.cancel != nil {
+			r.cancel()
+		}
+
+		// message and its data must not be used after close
+		PutMessage(r.msg)
 	}()
 
-	if r.err != "" {
-		r.sendError()
-		return
-	}
-	r.sendOK()
+	r.once.Do(func() {
+		defer func() {
+			_ = r.conn.Close()
+		}()
+
+		if r.err != "" {
+			r.sendError()
+			return
+		}
+		r.sendOK()
+	})
 }
@@ -7,6 +7,16 @@ import (
 	"testing"
 )
 
+// TestRequestID tests ID of Request
+func TestRequestID(t *testing.T) {
+	req := &Request{
+		id: "1234abcd",
+	}
+	if req.ID() != "1234abcd" {
+		t.Errorf("got %s, want 1234abcd", req.ID())
+	}
+}
+
 // TestRequestType tests Type of Request
 func TestRequestType(t *testing.T) {
 	req := &Request{
@@ -76,6 +86,7 @@ func TestRequestClose(t *testing.T) {
 	// test Error
 	c1, c2 = net.Pipe()
 	req = &Request{
+		id:   "1234abcd",
 		conn: c1,
 	}
 	req.Error("fail")
@@ -87,4 +98,44 @@ func TestRequestClose(t *testing.T) {
 	if msg.Type != TypeError {
 		t.Errorf("got %d, want %d", msg.Type, TypeError)
 	}
+	if string(msg.Value) != "fail (request 1234abcd)" {
+		t.Errorf("got %q, want error with request ID", msg.Value)
+	}
+}
+
+// TestRequestCloseLongReply tests Close of Request with long replies
+func TestRequestCloseLongReply(t *testing.T) {
+	reply := make([]byte, MaxPayloadLength+1)
+
+	// client without chunking
+	c1, c2 := net.Pipe()
+	req := &Request{
+		conn: c1,
+	}
+	req.Reply(reply)
+	go req.Close()
+	msg, err := ReadMessage(c2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Type != TypeError {
+		t.Errorf("got %d, want %d", msg.Type, TypeError)
+	}
+
+	// client with chunking
+	c1, c2 = net.Pipe()
+	req = &Request{
+		conn:     c1,
+		chunking: true,
+	}
+	req.Reply(reply)
+	go req.Close()
+	msg, err = ReadMessage(c2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Type != TypeOK || !bytes.Equal(msg.Value, reply) {
+		t.Errorf("got %d with %d bytes, want OK with %d bytes",
+			msg.Type, len(msg.Value), len(reply))
+	}
 }
@@ -1,28 +1,44 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net"
 	"os"
 	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/logging"
 )
 
 const (
 	// serverTimeout is the timeout for an entire request/response exchange
 	// initiated by a client
 	serverTimeout = 30 * time.Second
+
+	// readTimeout is the timeout for reading the request of a client
+	readTimeout = 5 * time.Second
+
+	// maxConnections is the maximum number of concurrently handled client
+	// connections, subscribers are limited separately
+	maxConnections = 16
 )
 
 // Server is a Daemon API server
 type Server struct {
 	sockFile string
+	owner    int
+	group    int
 	listen   net.Listener
 	requests chan *Request
+	conns    chan struct{}
+	handlers sync.WaitGroup
 
-	mutex sync.Mutex
-	stop  bool
+	mutex       sync.Mutex
+	stop        bool
+	subscribers map[*subscriber]struct{}
 }
 
 // setStopping marks the server as stopping
@@ -42,47 +58,183 @@ func (s *Server) isStopping() bool {
 
 }
 
+// readClientMessage reads the next message from the client on conn into msg
+// and decompresses its payload, it returns false on errors
+func readClientMessage(conn net.Conn, msg *Message) bool {
+	if err := ReadMessageInto(conn, msg); err != nil {
+		log.WithError(err).Error("Daemon receive message error")
+		return false
+	}
+	if err := msg.Decompress(); err != nil {
+		log.WithError(err).Error("Daemon decompress message error")
+		return false
+	}
+	return true
+}
+
+// setDeadlines sets the deadline of the entire request/response exchange
+// started at start on conn and limits reading the request to readTimeout
+func setDeadlines(conn net.Conn, start, deadline time.Time) error {
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	read := start.Add(readTimeout)
+	if read.After(deadline) {
+		read = deadline
+	}
+	return conn.SetReadDeadline(read)
+}
+
 // handleRequest handles a request from the client
 func (s *Server) handleRequest(conn net.Conn) {
 	// set timeout for entire request/response exchange
-	deadline := time.Now().Add(serverTimeout)
-	if err := conn.SetDeadline(deadline); err != nil {
+	id := logging.NewRequestID()
+	start := time.Now()
+	deadline := start.Add(serverTimeout)
+	if err := setDeadlines(conn, start, deadline); err != nil {
 		log.WithError(err).Error("Daemon error setting deadline")
 		_ = conn.Close()
 		return
 	}
 
 	// read message from client
-	msg, err := ReadMessage(conn)
-	if err != nil {
-		log.WithError(err).Error("Daemon receive message error")
+	msg := GetMessage()
+	if !readClientMessage(conn, msg) {
+		PutMessage(msg)
 		_ = conn.Close()
 		return
 	}
 
+	// handle optional handshake, clients without handshake use the
+	// message format of protocol version 0
+	hello := &Hello{}
+	if msg.Type == TypeHello {
+		h, err := s.handleHello(conn, msg)
+		if err != nil {
+			log.WithError(err).Error("Daemon handshake error")
+			PutMessage(msg)
+			_ = conn.Close()
+			return
+		}
+		hello = h
+
+		// use request timeout of the client
+		deadline = start.Add(hello.requestTimeout())
+		if err := setDeadlines(conn, start, deadline); err != nil {
+			log.WithError(err).Error("Daemon error setting deadline")
+			PutMessage(msg)
+			_ = conn.Close()
+			return
+		}
+		if !readClientMessage(conn, msg) {
+			PutMessage(msg)
+			_ = conn.Close()
+			return
+		}
+	}
+
 	// check if its a known message type
 	switch msg.Type {
 	case TypeVPNConfigUpdate:
+		// only allow config updates of root, owner and group
+		if !s.checkConfigUpdate(conn, id) {
+			e := newRequestError("permission denied", id)
+			if err := WriteMessage(conn, e); err != nil {
+				log.WithError(err).Error("Daemon message send error")
+			}
+			PutMessage(msg)
+			_ = conn.Close()
+			return
+		}
+	case TypeSubscribe:
+		// keep connection open and send events
+		s.subscribe(conn, msg, hello)
+		return
 	default:
 		// send Error and disconnect
-		e := NewError([]byte("invalid message"))
+		e := newRequestError("invalid message", id)
 		if err := WriteMessage(conn, e); err != nil {
 			log.WithError(err).Error("Daemon message send error")
 		}
+		PutMessage(msg)
+		_ = conn.Close()
+		return
+	}
+
+	// request is read, allow cancel messages until the deadline
+	if err := conn.SetReadDeadline(deadline); err != nil {
+		log.WithError(err).Error("Daemon error setting deadline")
+		PutMessage(msg)
 		_ = conn.Close()
+		return
+	}
+
+	// forward client's request to daemon, abort it if the daemon does not
+	// take it before the deadline, e.g., because it is stuck in another
+	// request
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	r := &Request{
+		id:        id,
+		msg:       msg,
+		conn:      conn,
+		ctx:       ctx,
+		cancel:    cancel,
+		version:   hello.Version,
+		chunking:  hello.Capabilities&CapabilityChunking != 0,
+		challenge: hello.Challenge,
+	}
+	select {
+	case s.requests <- r:
+	case <-ctx.Done():
+		r.abort(context.DeadlineExceeded)
+		PutMessage(msg)
+		return
+	}
+
+	// abort request if the client cancels it or the deadline is exceeded
+	go r.watch()
+}
+
+// checkConfigUpdate returns whether the client connected on conn may send
+// VPN config updates with the request with id
+func (s *Server) checkConfigUpdate(conn net.Conn, id string) bool {
+	cred, err := PeerCredentials(conn)
+	if err != nil {
+		log.WithError(err).Error("Daemon could not get client credentials")
+		return false
+	}
+	if !s.allowConfigUpdate(cred) {
+		log.WithField(logging.CodeField, logging.CodeConfigUpdateDenied).
+			WithFields(log.Fields{
+				logging.RequestField: id,
+				"uid":                cred.Uid,
+				"gid":                cred.Gid,
+				"pid":                cred.Pid,
+			}).Error("Daemon rejected VPN config update of unauthorized client")
+		return false
 	}
+	return true
+}
 
-	// forward client's request to daemon
-	s.requests <- &Request{
-		msg:  msg,
-		conn: conn,
+// reject rejects the client connection conn because there are too many
+// concurrent client connections
+func (s *Server) reject(conn net.Conn) {
+	log.Error("Daemon rejected client, too many connections")
+	_ = conn.SetWriteDeadline(time.Now().Add(abortTimeout))
+	e := NewError([]byte("too many connections"))
+	if err := WriteMessage(conn, e); err != nil {
+		log.WithError(err).Error("Daemon message send error")
 	}
+	_ = conn.Close()
 }
 
 // handleClients handles client connections
 func (s *Server) handleClients() {
 	defer func() {
+		s.setStopping()
 		_ = s.listen.Close()
+		s.handlers.Wait()
+		s.closeSubscribers()
 		close(s.requests)
 	}()
 	for {
@@ -98,13 +250,30 @@ func (s *Server) handleClients() {
 			return
 		}
 
-		// read request from client connection and handle it
-		s.handleRequest(conn)
+		// limit concurrent client connections
+		select {
+		case s.conns <- struct{}{}:
+		default:
+			s.reject(conn)
+			continue
+		}
+
+		// read request from client connection and handle it, a slow
+		// client must not block other clients
+		s.handlers.Add(1)
+		go func() {
+			defer func() {
+				<-s.conns
+				s.handlers.Done()
+			}()
+			s.handleRequest(conn)
+		}()
 	}
 }
 
-// Start starts the API server
-func (s *Server) Start() {
+// Start starts the API server, it runs until Stop is called or ctx is
+// canceled
+func (s *Server) Start(ctx context.Context) error {
 	// cleanup existing sock file, this should normally fail
 	if err := os.Remove(s.sockFile); err == nil {
 		log.Warn("Removed existing unix socket file")
@@ -113,32 +282,63 @@ func (s *Server) Start() {
 	// start listener
 	listen, err := net.Listen("unix", s.sockFile)
 	if err != nil {
-		log.WithError(err).Fatal("Daemon could not start unix listener")
+		return fmt.Errorf("Daemon could not start unix listener: %w", err)
 	}
 	s.listen = listen
 
-	// make sure only we can access the sock file
-	if err := os.Chmod(s.sockFile, 0700); err != nil {
+	// make sure only we, the owner and the group can access the sock file
+	mode := os.FileMode(0700)
+	if s.group >= 0 {
+		mode = 0770
+	}
+	if err := os.Chmod(s.sockFile, mode); err != nil {
 		log.WithError(err).Error("Daemon could not set permissions of sock file")
 	}
+	if s.owner >= 0 || s.group >= 0 {
+		if err := os.Chown(s.sockFile, s.owner, s.group); err != nil {
+			log.WithError(err).Error("Daemon could not set owner of sock file")
+		}
+	}
 
 	// handle client connections
 	go s.handleClients()
+
+	// close listener when ctx is canceled
+	go func() {
+		<-ctx.Done()
+		s.setStopping()
+		_ = s.listen.Close()
+	}()
+
+	return nil
 }
 
 // Stop stops the API server
 func (s *Server) Stop() {
 	// stop listener
 	s.setStopping()
-	err := s.listen.Close()
-	if err != nil {
-		log.WithError(err).Fatal("Daemon could not close unix listener")
+	if err := s.listen.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		log.WithError(err).Error("Daemon could not close unix listener")
 	}
 	for range s.requests {
 		// wait for clients channel close
 	}
 }
 
+// SetOwner sets the owner of the sock file to the user with uid, e.g., to
+// allow vpnc-script calls of an openconnect process without root
+// privileges; it must be called before Start
+func (s *Server) SetOwner(uid int) {
+	s.owner = uid
+}
+
+// SetGroup sets the group of the sock file to the group with gid and allows
+// its members to send VPN config updates besides root and the owner; it must
+// be called before Start
+func (s *Server) SetGroup(gid int) {
+	s.group = gid
+}
+
 // Requests returns the clients channel
 func (s *Server) Requests() chan *Request {
 	return s.requests
@@ -147,7 +347,11 @@ func (s *Server) Requests() chan *Request {
 // NewServer returns a new API server
 func NewServer(sockFile string) *Server {
 	return &Server{
-		sockFile: sockFile,
-		requests: make(chan *Request),
+		sockFile:    sockFile,
+		owner:       -1,
+		group:       -1,
+		requests:    make(chan *Request),
+		conns:       make(chan struct{}, maxConnections),
+		subscribers: make(map[*subscriber]struct{}),
 	}
 }
@@ -1,14 +1,47 @@
 package api
 
-import "testing"
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
 
 // TestServerStartStop tests Start and Stop of Server
 func TestServerStartStop(t *testing.T) {
 	server := NewServer("test.sock")
-	server.Start()
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
 	server.Stop()
 }
 
+// TestServerStartError tests Start of Server with listener error
+func TestServerStartError(t *testing.T) {
+	sockFile := filepath.Join(t.TempDir(), "does-not-exist", "test.sock")
+	server := NewServer(sockFile)
+	if err := server.Start(context.Background()); err == nil {
+		t.Error("start should fail")
+	}
+}
+
+// TestServerContext tests Server with canceled context
+func TestServerContext(t *testing.T) {
+	server := NewServer("test.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := server.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	for range server.Requests() {
+		// wait for requests channel close
+	}
+}
+
 // TestServerRequests tests Requests of Server
 func TestServerRequests(t *testing.T) {
 	server := NewServer("test.sock")
@@ -27,4 +60,173 @@ func TestNewServer(t *testing.T) {
 	if server.requests == nil {
 		t.Errorf("got nil, want != nil")
 	}
+	if cap(server.conns) != maxConnections {
+		t.Errorf("got %d, want %d", cap(server.conns), maxConnections)
+	}
+}
+
+// TestServerSetOwner tests SetOwner of Server
+func TestServerSetOwner(t *testing.T) {
+	sockFile := filepath.Join(t.TempDir(), "test.sock")
+	server := NewServer(sockFile)
+	server.SetOwner(os.Getuid())
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	fi, err := os.Stat(sockFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uid := fi.Sys().(*syscall.Stat_t).Uid; int(uid) != os.Getuid() {
+		t.Errorf("got %d, want %d", uid, os.Getuid())
+	}
+}
+
+// sendTestRequest connects to the server on sockFile with request timeout and
+// sends a config update request
+func sendTestRequest(t *testing.T, sockFile string, timeout time.Duration) net.Conn {
+	conn, err := net.Dial("unix", sockFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Handshake(conn, Capabilities, timeout); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteMessage(conn,
+		NewMessage(TypeVPNConfigUpdate, nil)); err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+// readTestError reads an error reply with msg and the request ID from conn
+func readTestError(t *testing.T, conn net.Conn, msg string) {
+	reply, err := ReadMessage(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := string(reply.Value)
+	if reply.Type != TypeError ||
+		!strings.HasPrefix(value, msg+" (request ") ||
+		len(value) != len(msg+" (request 01234567)") {
+		t.Errorf("got %d %q, want error %q with request ID",
+			reply.Type, reply.Value, msg)
+	}
+}
+
+// TestServerRequestDeadline tests requests of Server that exceed their
+// deadline
+func TestServerRequestDeadline(t *testing.T) {
+	sockFile := filepath.Join(t.TempDir(), "test.sock")
+	server := NewServer(sockFile)
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	// request not taken by daemon
+	conn := sendTestRequest(t, sockFile, 50*time.Millisecond)
+	defer func() { _ = conn.Close() }()
+	readTestError(t, conn, context.DeadlineExceeded.Error())
+
+	// request not closed by daemon
+	conn = sendTestRequest(t, sockFile, 50*time.Millisecond)
+	defer func() { _ = conn.Close() }()
+	r := <-server.Requests()
+	readTestError(t, conn, context.DeadlineExceeded.Error())
+	<-r.Context().Done()
+	r.Close()
+}
+
+// TestServerRequestCancel tests requests of Server canceled by the client
+func TestServerRequestCancel(t *testing.T) {
+	sockFile := filepath.Join(t.TempDir(), "test.sock")
+	server := NewServer(sockFile)
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	// cancel message
+	conn := sendTestRequest(t, sockFile, time.Second)
+	defer func() { _ = conn.Close() }()
+	r := <-server.Requests()
+	if err := WriteMessage(conn, NewCancelMessage()); err != nil {
+		t.Fatal(err)
+	}
+	readTestError(t, conn, errRequestCanceled.Error())
+	<-r.Context().Done()
+	r.Close()
+
+	// client closes connection
+	conn = sendTestRequest(t, sockFile, time.Second)
+	r = <-server.Requests()
+	_ = conn.Close()
+	<-r.Context().Done()
+	r.Close()
+}
+
+// TestServerSlowClient tests Server with a client that does not send its
+// request
+func TestServerSlowClient(t *testing.T) {
+	sockFile := filepath.Join(t.TempDir(), "test.sock")
+	server := NewServer(sockFile)
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	// connect slow client
+	slow, err := net.Dial("unix", sockFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = slow.Close() }()
+
+	// slow client should not block request of other client
+	conn := sendTestRequest(t, sockFile, time.Second)
+	defer func() { _ = conn.Close() }()
+	select {
+	case r := <-server.Requests():
+		r.Close()
+	case <-time.After(readTimeout / 2):
+		t.Fatal("request blocked by slow client")
+	}
+}
+
+// TestServerMaxConnections tests the maximum number of concurrent client
+// connections of Server
+func TestServerMaxConnections(t *testing.T) {
+	sockFile := filepath.Join(t.TempDir(), "test.sock")
+	server := NewServer(sockFile)
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	// connect maximum number of clients that do not send requests
+	for i := 0; i < maxConnections; i++ {
+		conn, err := net.Dial("unix", sockFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = conn.Close() }()
+	}
+
+	// next client should be rejected
+	conn, err := net.Dial("unix", sockFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	reply, err := ReadMessage(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Type != TypeError || string(reply.Value) != "too many connections" {
+		t.Errorf("got %d %q, want too many connections error",
+			reply.Type, reply.Value)
+	}
 }
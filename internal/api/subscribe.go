@@ -0,0 +1,188 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// maxSubscribers is the maximum number of concurrent subscribers
+	maxSubscribers = 16
+
+	// subscriberBuffer is the number of events buffered per subscriber,
+	// a subscriber that falls further behind is disconnected
+	subscriberBuffer = 64
+
+	// subscriberTimeout is the timeout for writing an event to a
+	// subscriber
+	subscriberTimeout = 5 * time.Second
+)
+
+// Event is a status change event sent to subscribers: either the change of
+// the status property Property to Value or the signal Signal with Values,
+// property and signal names are the names in the D-Bus API
+type Event struct {
+	Property string `json:",omitempty"`
+	Value    any
+	Signal   string `json:",omitempty"`
+	Values   []any  `json:",omitempty"`
+}
+
+// subscriber is a client connection that receives events
+type subscriber struct {
+	conn     net.Conn
+	compress bool
+	chunking bool
+	events   chan *Message
+	done     chan struct{}
+}
+
+// send sends the events to the subscriber until it is removed
+func (s *Server) send(sub *subscriber) {
+	defer s.unsubscribe(sub)
+	for {
+		select {
+		case m := <-sub.events:
+			deadline := time.Now().Add(subscriberTimeout)
+			if err := sub.conn.SetWriteDeadline(deadline); err != nil {
+				log.WithError(err).Error("Daemon error setting deadline")
+				return
+			}
+			if err := WriteMessage(sub.conn, m); err != nil {
+				log.WithError(err).Debug("Daemon could not send event")
+				return
+			}
+		case <-sub.done:
+			return
+		}
+	}
+}
+
+// wait waits until the subscriber closes the connection
+func (s *Server) wait(sub *subscriber) {
+	_, _ = io.Copy(io.Discard, sub.conn)
+	s.unsubscribe(sub)
+}
+
+// subscribe adds the client with conn, message msg and hello as subscriber
+func (s *Server) subscribe(conn net.Conn, msg *Message, hello *Hello) {
+	sub := &subscriber{
+		conn:     conn,
+		compress: msg.Flags&FlagAcceptCompression != 0,
+		chunking: hello.Capabilities&CapabilityChunking != 0,
+		events:   make(chan *Message, subscriberBuffer),
+		done:     make(chan struct{}),
+	}
+	PutMessage(msg)
+
+	// check number of subscribers
+	s.mutex.Lock()
+	if s.stop || len(s.subscribers) >= maxSubscribers {
+		s.mutex.Unlock()
+		e := NewError([]byte("too many subscribers"))
+		if err := WriteMessage(conn, e); err != nil {
+			log.WithError(err).Error("Daemon message send error")
+		}
+		_ = conn.Close()
+		return
+	}
+	s.subscribers[sub] = struct{}{}
+	s.mutex.Unlock()
+
+	// confirm subscription, events have no overall deadline
+	if err := WriteMessage(conn, NewOK(nil)); err != nil {
+		log.WithError(err).Error("Daemon message send error")
+		s.unsubscribe(sub)
+		return
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		log.WithError(err).Error("Daemon error setting deadline")
+		s.unsubscribe(sub)
+		return
+	}
+	log.Debug("Daemon added subscriber")
+
+	go s.send(sub)
+	go s.wait(sub)
+}
+
+// removeSubscriber removes sub and closes its connection, s.mutex must be
+// held
+func (s *Server) removeSubscriber(sub *subscriber) {
+	if _, ok := s.subscribers[sub]; !ok {
+		// already removed
+		return
+	}
+	delete(s.subscribers, sub)
+	close(sub.done)
+	_ = sub.conn.Close()
+}
+
+// unsubscribe removes the subscriber sub
+func (s *Server) unsubscribe(sub *subscriber) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.removeSubscriber(sub)
+}
+
+// Publish sends the event e to all subscribers, subscribers that cannot
+// keep up with the events are disconnected
+func (s *Server) Publish(e *Event) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.subscribers) == 0 {
+		return
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		log.WithError(err).Error("Daemon could not convert event to JSON")
+		return
+	}
+	var plain, compressed, chunked *Message
+	for sub := range s.subscribers {
+		m := plain
+		if sub.compress {
+			if compressed == nil {
+				compressed = NewCompressedMessage(TypeEvent, b)
+			}
+			m = compressed
+		} else if plain == nil {
+			plain = NewMessage(TypeEvent, b)
+			m = plain
+		}
+		if m == nil && sub.chunking {
+			if chunked == nil {
+				chunked = NewChunkedMessage(TypeEvent, b)
+			}
+			m = chunked
+		}
+		if m == nil {
+			log.WithField("length", len(b)).
+				Warn("Daemon dropped event too long for subscriber")
+			continue
+		}
+
+		select {
+		case sub.events <- m:
+		default:
+			log.Warn("Daemon disconnected slow subscriber")
+			s.removeSubscriber(sub)
+		}
+	}
+}
+
+// closeSubscribers removes all subscribers
+func (s *Server) closeSubscribers() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for sub := range s.subscribers {
+		s.removeSubscriber(sub)
+	}
+}
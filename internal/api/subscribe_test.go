@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestServerSubscribe tests subscribe and Publish of Server
+func TestServerSubscribe(t *testing.T) {
+	sockFile := filepath.Join(t.TempDir(), "test.sock")
+	server := NewServer(sockFile)
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	// subscribe
+	conn, err := net.Dial("unix", sockFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	if err := WriteMessage(conn, NewMessage(TypeSubscribe, nil)); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := ReadMessage(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Type != TypeOK {
+		t.Fatalf("got type %d, want %d", reply.Type, TypeOK)
+	}
+
+	// publish events
+	for _, want := range []*Event{
+		{Property: "ConnectionState", Value: float64(2)},
+		{Signal: "ConnectionDropped"},
+	} {
+		server.Publish(want)
+		msg, err := ReadMessage(conn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.Type != TypeEvent {
+			t.Fatalf("got type %d, want %d", msg.Type, TypeEvent)
+		}
+		got := &Event{}
+		if err := json.Unmarshal(msg.Value, got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+
+	// unsubscribe by closing the connection
+	_ = conn.Close()
+	server.Stop()
+	if len(server.subscribers) != 0 {
+		t.Errorf("got %d subscribers, want 0", len(server.subscribers))
+	}
+}
+
+// TestServerPublishChunked tests Publish of Server with a subscriber that
+// accepts chunked messages
+func TestServerPublishChunked(t *testing.T) {
+	server := NewServer("test.sock")
+	c1, c2 := net.Pipe()
+	defer func() { _ = c1.Close() }()
+	defer func() { _ = c2.Close() }()
+	sub := &subscriber{
+		conn:     c2,
+		chunking: true,
+		events:   make(chan *Message, 1),
+		done:     make(chan struct{}),
+	}
+	server.subscribers[sub] = struct{}{}
+
+	want := &Event{Property: "VPNConfig", Value: string(make([]byte, MaxPayloadLength))}
+	server.Publish(want)
+	m := <-sub.events
+	if len(m.Value) <= MaxPayloadLength {
+		t.Errorf("got %d bytes, want chunked event", len(m.Value))
+	}
+}
+
+// TestServerSubscribeMax tests subscribe of Server with too many subscribers
+func TestServerSubscribeMax(t *testing.T) {
+	server := NewServer("test.sock")
+	for i := 0; i < maxSubscribers; i++ {
+		server.subscribers[&subscriber{}] = struct{}{}
+	}
+
+	c1, c2 := net.Pipe()
+	defer func() { _ = c1.Close() }()
+	go server.subscribe(c2, NewMessage(TypeSubscribe, nil), &Hello{})
+	reply, err := ReadMessage(c1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Type != TypeError {
+		t.Errorf("got type %d, want %d", reply.Type, TypeError)
+	}
+}
+
+// TestServerPublishSlow tests Publish of Server with a slow subscriber
+func TestServerPublishSlow(t *testing.T) {
+	server := NewServer("test.sock")
+	c1, c2 := net.Pipe()
+	defer func() { _ = c1.Close() }()
+	sub := &subscriber{
+		conn:   c2,
+		events: make(chan *Message, subscriberBuffer),
+		done:   make(chan struct{}),
+	}
+	server.subscribers[sub] = struct{}{}
+
+	// fill buffer, subscriber is not sending events
+	for i := 0; i < subscriberBuffer; i++ {
+		server.Publish(&Event{Property: "RXBytes", Value: i})
+	}
+	if len(server.subscribers) != 1 {
+		t.Fatal("subscriber should not be removed")
+	}
+
+	// buffer overflow
+	server.Publish(&Event{Property: "RXBytes", Value: subscriberBuffer})
+	if len(server.subscribers) != 0 {
+		t.Error("slow subscriber should be removed")
+	}
+	select {
+	case <-sub.done:
+	default:
+		t.Error("slow subscriber should be done")
+	}
+}
@@ -0,0 +1,105 @@
+// Package audit contains the connection audit log
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Audit log targets
+const (
+	// TargetJournald logs to journald
+	TargetJournald = "journald"
+)
+
+// Event types
+const (
+	EventConnect        = "connect"
+	EventDisconnect     = "disconnect"
+	EventTrustedNetwork = "trusted-network"
+	EventProfileUpdate  = "profile-update"
+	EventTrafPol        = "trafpol"
+	EventHostscan       = "hostscan"
+	EventSplitExclude   = "split-exclude"
+	EventPause          = "pause"
+	EventOpenConnect    = "openconnect"
+)
+
+// Senders that are not D-Bus clients
+const (
+	// SenderDaemon is used for events caused by the daemon itself
+	SenderDaemon = "daemon"
+)
+
+// UIDUnknown is the UID of an unknown user
+const UIDUnknown int64 = -1
+
+// Record is an audit log record
+type Record struct {
+	Time    time.Time
+	Event   string
+	Sender  string
+	UID     int64
+	Details string `json:",omitempty"`
+}
+
+// Logger is an audit logger
+type Logger interface {
+	Log(r *Record) error
+	Close() error
+}
+
+// FileLogger is an audit logger that appends records as JSON lines to a
+// file
+type FileLogger struct {
+	mutex sync.Mutex
+	file  *os.File
+}
+
+// Log appends record r to the audit log file
+func (f *FileLogger) Log(r *Record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	_, err = f.file.Write(b)
+	return err
+}
+
+// Close closes the audit log file
+func (f *FileLogger) Close() error {
+	return f.file.Close()
+}
+
+// NewFileLogger returns a new FileLogger that appends to file
+func NewFileLogger(file string) (*FileLogger, error) {
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileLogger{file: f}, nil
+}
+
+// NewLogger returns a new audit logger for target, target is either
+// TargetJournald or an absolute file path
+func NewLogger(target string) (Logger, error) {
+	if target == TargetJournald {
+		return NewJournalLogger()
+	}
+	if !filepath.IsAbs(target) {
+		return nil, errors.New("invalid audit log target")
+	}
+	return NewFileLogger(target)
+}
@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testRecord returns a record for testing
+func testRecord() *Record {
+	return &Record{
+		Time:    time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC),
+		Event:   EventConnect,
+		Sender:  ":1.23",
+		UID:     1000,
+		Details: "vpn.example.com",
+	}
+}
+
+// TestFileLogger tests FileLogger
+func TestFileLogger(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "audit", "audit.log")
+
+	// write records, reopen file in between to check appending
+	want := []*Record{testRecord(), testRecord()}
+	for _, r := range want {
+		l, err := NewFileLogger(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := l.Log(r); err != nil {
+			t.Fatal(err)
+		}
+		if err := l.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// read records
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	got := []*Record{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		r := &Record{}
+		if err := json.Unmarshal(scanner.Bytes(), r); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, r)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestNewLogger tests NewLogger
+func TestNewLogger(t *testing.T) {
+	// test invalid target
+	if _, err := NewLogger("relative/audit.log"); err == nil {
+		t.Errorf("relative file should return error")
+	}
+
+	// test file
+	l, err := NewLogger(filepath.Join(t.TempDir(), "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := l.(*FileLogger); !ok {
+		t.Errorf("got %T, want *FileLogger", l)
+	}
+	_ = l.Close()
+
+	// test journald
+	l, err = NewLogger(TargetJournald)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := l.(*JournalLogger); !ok {
+		t.Errorf("got %T, want *JournalLogger", l)
+	}
+	_ = l.Close()
+}
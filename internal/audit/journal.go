@@ -0,0 +1,64 @@
+package audit
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/journal"
+)
+
+const (
+	// journalIdentifier is the syslog identifier of audit log records
+	journalIdentifier = "oc-daemon-audit"
+
+	// journalPriority is the priority of audit log records, notice
+	journalPriority = "5"
+)
+
+// JournalLogger is an audit logger that sends records to journald
+type JournalLogger struct {
+	conn *journal.Conn
+}
+
+// encodeRecord encodes record r with the journald native protocol
+func encodeRecord(r *Record) []byte {
+	msg := fmt.Sprintf("Audit: %s by %s (uid %d)", r.Event, r.Sender, r.UID)
+	if r.Details != "" {
+		msg += ": " + r.Details
+	}
+
+	b := new(bytes.Buffer)
+	journal.AppendField(b, "MESSAGE", msg)
+	journal.AppendField(b, "PRIORITY", journalPriority)
+	journal.AppendField(b, "SYSLOG_IDENTIFIER", journalIdentifier)
+	journal.AppendField(b, "OC_DAEMON_AUDIT_TIME", r.Time.Format(time.RFC3339Nano))
+	journal.AppendField(b, "OC_DAEMON_AUDIT_EVENT", r.Event)
+	journal.AppendField(b, "OC_DAEMON_AUDIT_SENDER", r.Sender)
+	journal.AppendField(b, "OC_DAEMON_AUDIT_UID", strconv.FormatInt(r.UID, 10))
+	if r.Details != "" {
+		journal.AppendField(b, "OC_DAEMON_AUDIT_DETAILS", r.Details)
+	}
+	return b.Bytes()
+}
+
+// Log sends record r to journald
+func (j *JournalLogger) Log(r *Record) error {
+	_, err := j.conn.Write(encodeRecord(r))
+	return err
+}
+
+// Close closes the connection to journald
+func (j *JournalLogger) Close() error {
+	return j.conn.Close()
+}
+
+// NewJournalLogger returns a new JournalLogger
+func NewJournalLogger() (*JournalLogger, error) {
+	conn, err := journal.Dial()
+	if err != nil {
+		return nil, err
+	}
+	return &JournalLogger{conn: conn}, nil
+}
@@ -0,0 +1,64 @@
+package audit
+
+import (
+	"bytes"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/internal/journal"
+)
+
+// TestEncodeRecord tests encodeRecord
+func TestEncodeRecord(t *testing.T) {
+	r := testRecord()
+	want := "MESSAGE=Audit: connect by :1.23 (uid 1000): vpn.example.com\n" +
+		"PRIORITY=5\n" +
+		"SYSLOG_IDENTIFIER=oc-daemon-audit\n" +
+		"OC_DAEMON_AUDIT_TIME=2023-06-01T12:00:00Z\n" +
+		"OC_DAEMON_AUDIT_EVENT=connect\n" +
+		"OC_DAEMON_AUDIT_SENDER=:1.23\n" +
+		"OC_DAEMON_AUDIT_UID=1000\n" +
+		"OC_DAEMON_AUDIT_DETAILS=vpn.example.com\n"
+	got := string(encodeRecord(r))
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// TestJournalLogger tests JournalLogger
+func TestJournalLogger(t *testing.T) {
+	// create test journal socket
+	old := journal.Socket
+	journal.Socket = filepath.Join(t.TempDir(), "socket")
+	defer func() { journal.Socket = old }()
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{
+		Name: journal.Socket,
+		Net:  "unixgram",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	// log record
+	l, err := NewJournalLogger()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = l.Close() }()
+	r := testRecord()
+	if err := l.Log(r); err != nil {
+		t.Fatal(err)
+	}
+
+	// check received record
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf[:n], encodeRecord(r)) {
+		t.Errorf("got %q, want %q", buf[:n], encodeRecord(r))
+	}
+}
@@ -0,0 +1,47 @@
+// Package charset converts text from VPN gateways to UTF-8, older gateways
+// send banners and messages in legacy charsets that otherwise render as
+// mojibake or break D-Bus, which only allows valid UTF-8 strings
+package charset
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// windows1252 maps the bytes 0x80 to 0x9F of Windows-1252 to runes, the
+// other bytes above 0x7F are the same as in ISO-8859-1; unused bytes are
+// mapped to the replacement character
+var windows1252 = [32]rune{
+	'€', utf8.RuneError, '‚', 'ƒ', '„', '…', '†', '‡',
+	'ˆ', '‰', 'Š', '‹', 'Œ', utf8.RuneError, 'Ž', utf8.RuneError,
+	utf8.RuneError, '‘', '’', '“', '”', '•', '–', '—',
+	'˜', '™', 'š', '›', 'œ', utf8.RuneError, 'ž', 'Ÿ',
+}
+
+// DecodeWindows1252 returns s decoded from Windows-1252, a superset of
+// ISO-8859-1, as UTF-8
+func DecodeWindows1252(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c < 0x80:
+			b.WriteByte(c)
+		case c < 0xA0:
+			b.WriteRune(windows1252[c-0x80])
+		default:
+			b.WriteRune(rune(c))
+		}
+	}
+	return b.String()
+}
+
+// ToUTF8 returns s as UTF-8, s is returned unchanged if it is valid UTF-8
+// and decoded from Windows-1252 otherwise
+func ToUTF8(s string) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+	return DecodeWindows1252(s)
+}
@@ -0,0 +1,40 @@
+package charset
+
+import "testing"
+
+// TestDecodeWindows1252 tests DecodeWindows1252
+func TestDecodeWindows1252(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"Welcome", "Welcome"},
+		{"Gr\xfc\xdfe", "Grüße"},
+		{"\x80 5", "€ 5"},
+		{"\x93quoted\x94", "“quoted”"},
+		{"\x81", "�"},
+	} {
+		if got := DecodeWindows1252(test.in); got != test.want {
+			t.Errorf("%q: got %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+// TestToUTF8 tests ToUTF8
+func TestToUTF8(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"Grüße", "Grüße"},
+		{"こんにちは", "こんにちは"},
+		{"Gr\xfc\xdfe", "Grüße"},
+		{"caf\xe9", "café"},
+	} {
+		if got := ToUTF8(test.in); got != test.want {
+			t.Errorf("%q: got %q, want %q", test.in, got, test.want)
+		}
+	}
+}
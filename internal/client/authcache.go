@@ -0,0 +1,64 @@
+package client
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/pkg/client"
+)
+
+// authCacheName is the name of the file with the time of the last
+// successful authentication in the user config dir, it does not contain
+// any credentials
+const authCacheName = "auth.json"
+
+// authCache is the content of the auth cache file
+type authCache struct {
+	Time time.Time
+}
+
+// authCacheFile returns the file with the time of the last successful
+// authentication
+func authCacheFile() string {
+	return filepath.Join(filepath.Dir(client.UserConfig()), authCacheName)
+}
+
+// saveAuthCache saves the current time as time of the last successful
+// authentication
+func saveAuthCache() {
+	b, err := json.Marshal(&authCache{Time: time.Now()})
+	if err != nil {
+		log.WithError(err).Error("Client could not save authentication time")
+		return
+	}
+	file := authCacheFile()
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		log.WithError(err).Error("Client could not create user dir")
+		return
+	}
+	if err := os.WriteFile(file, b, 0600); err != nil {
+		log.WithError(err).Error("Client could not save authentication time")
+	}
+}
+
+// recentAuth returns whether the last successful authentication was less
+// than timeout ago
+func recentAuth(timeout time.Duration) bool {
+	if timeout <= 0 {
+		return false
+	}
+	b, err := os.ReadFile(authCacheFile())
+	if err != nil {
+		return false
+	}
+	cache := &authCache{}
+	if err := json.Unmarshal(b, cache); err != nil {
+		log.WithError(err).Warn("Client could not parse authentication time")
+		return false
+	}
+	since := time.Since(cache.Time)
+	return since >= 0 && since < timeout
+}
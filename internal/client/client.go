@@ -1,10 +1,16 @@
 package client
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/logging"
+	"github.com/telekom-mms/oc-daemon/internal/splitrt"
 	"github.com/telekom-mms/oc-daemon/pkg/client"
 	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
 	"github.com/telekom-mms/oc-daemon/pkg/xmlprofile"
@@ -13,12 +19,24 @@ import (
 const (
 	// maxReconnectTries is the maximum amount or reconnect retries
 	maxReconnectTries = 5
+
+	// deviceAuthTimeout is the timeout for the approval of a login with
+	// device authorization
+	deviceAuthTimeout = 15 * time.Minute
 )
 
+// newClient returns a new client, for the remote host if it is set
+func newClient() (client.Client, error) {
+	if host != "" {
+		return client.NewRemoteClient(config, host)
+	}
+	return client.NewClient(config)
+}
+
 // listServers gets the VPN status from the daemon and prints the VPN servers in it
 func listServers() {
 	// create client
-	c, err := client.NewClient(config)
+	c, err := newClient()
 	if err != nil {
 		log.WithError(err).Fatal("error creating client")
 	}
@@ -31,31 +49,237 @@ func listServers() {
 	}
 
 	// print servers in status
+	printList("Servers", status.Servers)
+}
+
+// listDaemonServers gets the VPN servers in the XML profile from the daemon
+// and prints them, with their reachability and latency if pingServer is set
+func listDaemonServers() {
+	// create client
+	c, err := newClient()
+	if err != nil {
+		log.WithError(err).Fatal("error creating client")
+	}
+	defer func() { _ = c.Close() }()
+
+	// get servers and preferred server
+	servers, err := c.ListServers(pingServer)
+	if err != nil {
+		log.WithError(err).Fatal("error listing servers")
+	}
+	status, err := c.Query()
+	if err != nil {
+		log.WithError(err).Fatal("error getting status")
+	}
+
+	// print servers
 	fmt.Printf("Servers:\n")
-	for _, server := range status.Servers {
-		fmt.Printf("  - \"%s\"\n", server)
+	for _, s := range servers {
+		preferred := ""
+		if s.Name == status.PreferredServer {
+			preferred = " [preferred]"
+		}
+		switch {
+		case !pingServer:
+			fmt.Printf("  - \"%s\" (%s)%s\n", s.Name, s.Address,
+				preferred)
+		case s.Reachable:
+			fmt.Printf("  - \"%s\" (%s): reachable, %s%s\n", s.Name,
+				s.Address, s.Latency.Round(time.Millisecond),
+				preferred)
+		default:
+			fmt.Printf("  - \"%s\" (%s): unreachable%s\n", s.Name,
+				s.Address, preferred)
+		}
+	}
+}
+
+// setPreferredServer sets the preferred VPN server of the daemon, it resets
+// the preferred server if no server is given
+func setPreferredServer() {
+	// create client
+	c, err := newClient()
+	if err != nil {
+		log.WithError(err).Fatal("error creating client")
+	}
+	defer func() { _ = c.Close() }()
+
+	// set preferred server
+	if err := c.SetPreferredServer(preferServer); err != nil {
+		log.WithError(err).Fatal("error setting preferred server")
+	}
+}
+
+// listRoutes gets the VPN configuration from the daemon and prints its split
+// routes, as effective routing decisions if effective is set
+func listRoutes() {
+	// create client
+	c, err := newClient()
+	if err != nil {
+		log.WithError(err).Fatal("error creating client")
+	}
+	defer func() { _ = c.Close() }()
+
+	// get status
+	status, err := c.Query()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if status.VPNConfig == nil {
+		fmt.Println("Routes: not connected")
+		return
+	}
+
+	// print configured routes
+	fmt.Printf("Routes:\n")
+	if !effective {
+		split := status.VPNConfig.Split
+		fmt.Printf("  Includes:\n")
+		for _, r := range append(split.IncludeIPv4, split.IncludeIPv6...) {
+			fmt.Printf("    - %s\n", r)
+		}
+		fmt.Printf("  Excludes:\n")
+		for _, r := range append(split.ExcludeIPv4, split.ExcludeIPv6...) {
+			fmt.Printf("    - %s\n", r)
+		}
+		return
+	}
+
+	// print effective routing decisions
+	fmt.Printf("  - default: %s\n", splitrt.ActionTunnel)
+	for _, d := range splitrt.NewConfigResolver(status.VPNConfig).Decisions() {
+		parent := "default"
+		if d.Parent != nil {
+			parent = d.Parent.String()
+		}
+		if d.Overrides() {
+			fmt.Printf("  - %s: %s (overrides %s of %s)\n", d.Prefix,
+				d.Action, d.ParentAction, parent)
+			continue
+		}
+		fmt.Printf("  - %s: %s (same as %s)\n", d.Prefix, d.Action, parent)
+	}
+}
+
+// setSplitExcludes lists, adds or removes split excludes of the current VPN
+// connection in the daemon
+func setSplitExcludes() {
+	// create client
+	c, err := newClient()
+	if err != nil {
+		log.WithError(err).Fatal("error creating client")
+	}
+	defer func() { _ = c.Close() }()
+
+	switch excludeAction {
+	case "add":
+		if err := c.AddSplitExclude(excludeAddress); err != nil {
+			log.WithError(err).Fatal("error adding split exclude")
+		}
+	case "remove":
+		if err := c.RemoveSplitExclude(excludeAddress); err != nil {
+			log.WithError(err).Fatal("error removing split exclude")
+		}
+	case "":
+		excludes, err := c.ListSplitExcludes()
+		if err != nil {
+			log.WithError(err).Fatal("error listing split excludes")
+		}
+		printList("Excludes", excludes)
+	default:
+		log.Fatalf("unknown excludes action: %s", excludeAction)
+	}
+}
+
+// listProfiles prints the installed XML profiles
+func listProfiles() {
+	profiles, err := xmlprofile.ListProfiles(xmlprofile.ProfilesDir)
+	if err != nil {
+		log.WithError(err).Fatal("error listing XML profiles")
+	}
+
+	fmt.Printf("Profiles:\n")
+	fmt.Printf("  - \"\" (default: %s)\n", xmlprofile.SystemProfile)
+	for _, profile := range profiles {
+		fmt.Printf("  - \"%s\"\n", profile)
 	}
 }
 
+// authHint returns guidance for the user on the authentication error err
+func authHint(err error) string {
+	switch {
+	case errors.Is(err, client.ErrAuthCredentials):
+		return "check your username and password"
+	case errors.Is(err, client.ErrAuthCertificate):
+		return "check the client certificate and key in the configuration"
+	case errors.Is(err, client.ErrAuthServerCertificate):
+		return "check the CA certificate in the configuration or the VPN server address"
+	case errors.Is(err, client.ErrAuthForm):
+		return "the login form of the VPN server is not supported, contact your administrator"
+	}
+	return ""
+}
+
 // connectVPN connects to the VPN if necessary
 func connectVPN() {
 	// create client
-	c, err := client.NewClient(config)
+	c, err := newClient()
 	if err != nil {
 		log.WithError(err).Fatal("error creating client")
 	}
 	defer func() { _ = c.Close() }()
 
+	// connect with device authorization?
+	if deviceAuth {
+		connectVPNDevice(c)
+		return
+	}
+
 	// try to read current xml profile
-	pre := xmlprofile.LoadSystemProfile()
+	pre := xmlprofile.LoadNamedProfile(config.Profile)
+
+	// prefer the preferred server of the daemon, unless the server is set
+	// on the command line
+	preferred := ""
+	if !serverOverride && config.Tunnel == "" {
+		if status, err := c.Query(); err == nil {
+			preferred = status.PreferredServer
+		}
+	}
+	if preferred != "" {
+		log.WithField("server", preferred).Info("Using preferred VPN server")
+		config.VPNServer = preferred
+		c.SetConfig(config)
+	}
+
+	// prefer the last used server on the current network, unless the
+	// server is set on the command line or preferred by the daemon
+	network := ""
+	if config.StickyServers && host == "" && preferred == "" {
+		network = currentNetwork()
+	}
+	if network != "" && !serverOverride {
+		if server := stickyServer(pre, network); server != "" {
+			log.WithFields(log.Fields{
+				"network": network,
+				"server":  server,
+			}).Info("Using last VPN server on network")
+			config.VPNServer = server
+			c.SetConfig(config)
+		}
+	}
 
 	// authenticate
 	if err := c.Authenticate(); err != nil {
+		if hint := authHint(err); hint != "" {
+			log.WithError(err).WithField("hint", hint).
+				Fatal("error authenticating user for VPN")
+		}
 		log.WithError(err).Fatal("error authenticating user for VPN")
 	}
 
 	// warn user if profile changed
-	post := xmlprofile.LoadSystemProfile()
+	post := xmlprofile.LoadNamedProfile(config.Profile)
 	if !pre.Equal(post) {
 		time.Sleep(2 * time.Second)
 		log.Warnln("XML Profile was updated. Connection attempt " +
@@ -68,12 +292,62 @@ func connectVPN() {
 	if err := c.Connect(); err != nil {
 		log.WithError(err).Fatal("error connecting to VPN")
 	}
+
+	// remember server on the current network
+	if network != "" {
+		saveStickyServer(network, config.VPNServer)
+	}
+
+	// remember successful authentication for reconnects
+	if config.AuthCacheTimeout > 0 && config.Tunnel == "" {
+		saveAuthCache()
+	}
+}
+
+// connectVPNDevice connects to the VPN with device authorization, it shows
+// the user code and waits until the login is approved and the VPN is
+// connected
+func connectVPNDevice(c client.Client) {
+	// subscribe to status updates
+	updates, err := c.Subscribe()
+	if err != nil {
+		log.WithError(err).Fatal("error subscribing to status updates")
+	}
+
+	// start connect
+	code, err := c.ConnectDevice()
+	if err != nil {
+		log.WithError(err).Fatal("error connecting to VPN")
+	}
+	fmt.Printf("To connect, open %s on your phone or another device "+
+		"and enter the code: %s\n", code.VerificationURI, code.UserCode)
+	if code.VerificationURIComplete != "" {
+		fmt.Printf("Or open: %s\n", code.VerificationURIComplete)
+	}
+	progress("Waiting for approval...\n")
+
+	// wait for connection
+	timeout := time.After(deviceAuthTimeout)
+	for {
+		select {
+		case status, ok := <-updates:
+			if !ok {
+				log.Fatal("error waiting for VPN connection")
+			}
+			if status.ConnectionState.Connected() {
+				progress("VPN connected\n")
+				return
+			}
+		case <-timeout:
+			log.Fatal("timeout waiting for approval")
+		}
+	}
 }
 
 // disconnectVPN disconnects the VPN
 func disconnectVPN() {
 	// create client
-	c, err := client.NewClient(config)
+	c, err := newClient()
 	if err != nil {
 		log.WithError(err).Fatal("error creating client")
 	}
@@ -86,17 +360,88 @@ func disconnectVPN() {
 	}
 }
 
+// cancelVPN cancels a connection attempt to the VPN
+func cancelVPN() {
+	// create client
+	c, err := newClient()
+	if err != nil {
+		log.WithError(err).Fatal("error creating client")
+	}
+	defer func() { _ = c.Close() }()
+
+	// cancel
+	if err := c.Cancel(); err != nil {
+		log.WithError(err).Fatal("error canceling VPN connection attempt")
+	}
+}
+
+// pauseVPN pauses the VPN connection
+func pauseVPN() {
+	// create client
+	c, err := newClient()
+	if err != nil {
+		log.WithError(err).Fatal("error creating client")
+	}
+	defer func() { _ = c.Close() }()
+
+	// pause
+	if err := c.Pause(pauseTimeout); err != nil {
+		log.WithError(err).Fatal("error pausing VPN connection")
+	}
+}
+
+// resumeVPN resumes the paused VPN connection
+func resumeVPN() {
+	// create client
+	c, err := newClient()
+	if err != nil {
+		log.WithError(err).Fatal("error creating client")
+	}
+	defer func() { _ = c.Close() }()
+
+	// resume
+	if err := c.Resume(); err != nil {
+		log.WithError(err).Fatal("error resuming VPN connection")
+	}
+}
+
+// hasCapability returns whether the daemon has capability, it is false if
+// the daemon does not support capabilities
+func hasCapability(c client.Client, capability string) bool {
+	caps, err := c.GetCapabilities()
+	if err != nil {
+		return false
+	}
+	return caps.Has(capability)
+}
+
 // reconnectVPN reconnects to the VPN
 func reconnectVPN() {
 	// create client
-	client, err := client.NewClient(config)
+	c, err := newClient()
 	if err != nil {
 		log.WithError(err).Fatal("error creating client")
 	}
-	defer func() { _ = client.Close() }()
+	defer func() { _ = c.Close() }()
+
+	// reconnect in the daemon if supported, it disconnects, waits for
+	// the teardown and connects with the login info cached in the daemon
+	cached := !serverOverride && config.Tunnel == ""
+	if cached && hasCapability(c, client.CapabilityReconnect) {
+		err := c.Reconnect()
+		if err == nil {
+			return
+		}
+		if !errors.Is(err, client.ErrReauthRequired) {
+			log.WithError(err).Fatal("error reconnecting to VPN")
+		}
+		log.WithError(err).Warn("Could not reconnect " +
+			"without authentication, authenticating")
+		cached = false
+	}
 
 	// check status
-	status, err := client.Query()
+	status, err := c.Query()
 	if err != nil {
 		log.WithError(err).Fatal("error reconnecting to VPN")
 	}
@@ -110,7 +455,7 @@ func reconnectVPN() {
 	// wait for status to switch to untrusted network and not running
 	try := 0
 	for {
-		status, err := client.Query()
+		status, err := c.Query()
 		if err != nil {
 			log.WithError(err).Fatal("error reconnecting to VPN")
 		}
@@ -118,6 +463,17 @@ func reconnectVPN() {
 		if !status.TrustedNetwork.Trusted() &&
 			!status.ConnectionState.Connected() &&
 			!status.OCRunning.Running() {
+			// connect with the login info cached in the daemon
+			// after a recent authentication
+			if cached && recentAuth(config.AuthCacheTimeout) {
+				err := c.ConnectCached()
+				if err == nil {
+					return
+				}
+				log.WithError(err).Warn("Could not reconnect " +
+					"without authentication, authenticating")
+			}
+
 			// authenticate and connect
 			connectVPN()
 			return
@@ -135,52 +491,371 @@ func reconnectVPN() {
 
 }
 
+// setTND enables or disables trusted network detection in the daemon
+func setTND() {
+	enabled := false
+	switch tndAction {
+	case "enable":
+		enabled = true
+	case "disable":
+	default:
+		log.Fatalf("unknown tnd action: %s", tndAction)
+	}
+
+	// create client
+	c, err := newClient()
+	if err != nil {
+		log.WithError(err).Fatal("error creating client")
+	}
+	defer func() { _ = c.Close() }()
+
+	// set tnd
+	if err := c.SetTND(enabled, tndTimeout); err != nil {
+		log.WithError(err).Fatal("error setting trusted network detection")
+	}
+}
+
+// setTrafPol enables or disables traffic policing in the daemon
+func setTrafPol() {
+	enabled := false
+	switch trafPolAction {
+	case "enable":
+		enabled = true
+	case "disable":
+	default:
+		log.Fatalf("unknown trafpol action: %s", trafPolAction)
+	}
+
+	// create client
+	c, err := newClient()
+	if err != nil {
+		log.WithError(err).Fatal("error creating client")
+	}
+	defer func() { _ = c.Close() }()
+
+	// set trafpol
+	if err := c.SetTrafPol(enabled, trafPolTimeout); err != nil {
+		log.WithError(err).Fatal("error setting traffic policing")
+	}
+}
+
+// setNotificationPolicy sets or resets the notification policy of the daemon
+func setNotificationPolicy() {
+	var policy *client.NotificationPolicy
+	if !notifyReset {
+		policy = &notifyPolicy
+		if notifyQuiet != "" {
+			start, end, ok := strings.Cut(notifyQuiet, "-")
+			if !ok {
+				log.Fatalf("invalid quiet hours: %s", notifyQuiet)
+			}
+			policy.QuietHours = []client.QuietWindow{{
+				Start: start,
+				End:   end,
+			}}
+		}
+	}
+
+	// create client
+	c, err := newClient()
+	if err != nil {
+		log.WithError(err).Fatal("error creating client")
+	}
+	defer func() { _ = c.Close() }()
+
+	// set notification policy
+	if err := c.SetNotificationPolicy(policy); err != nil {
+		log.WithError(err).Fatal("error setting notification policy")
+	}
+}
+
+// reloadProfile makes the daemon read its XML profile again
+func reloadProfile() {
+	// create client
+	c, err := newClient()
+	if err != nil {
+		log.WithError(err).Fatal("error creating client")
+	}
+	defer func() { _ = c.Close() }()
+
+	// reload profile
+	if err := c.ReloadProfile(); err != nil {
+		log.WithError(err).Fatal("error reloading XML profile")
+	}
+}
+
+// printLogs prints the recent log entries of the daemon
+func printLogs() {
+	// create client
+	c, err := newClient()
+	if err != nil {
+		log.WithError(err).Fatal("error creating client")
+	}
+	defer func() { _ = c.Close() }()
+
+	// get logs
+	logs, err := c.GetLogs(logLines)
+	if err != nil {
+		log.WithError(err).Fatal("error getting logs")
+	}
+	for _, l := range logs {
+		fmt.Println(l)
+	}
+}
+
+// printUsage gets the VPN usage per month from the daemon and prints it
+func printUsage() {
+	// create client
+	c, err := newClient()
+	if err != nil {
+		log.WithError(err).Fatal("error creating client")
+	}
+	defer func() { _ = c.Close() }()
+
+	// get usage
+	usage, err := c.GetUsage()
+	if err != nil {
+		log.WithError(err).Fatal("error getting usage")
+	}
+
+	// print usage
+	if jsonOutput {
+		b, err := json.MarshalIndent(usage, "", "  ")
+		if err != nil {
+			log.WithError(err).Fatal("error converting usage to JSON")
+		}
+		fmt.Println(string(b))
+		return
+	}
+	for _, u := range usage {
+		fmt.Printf("%s  received %d bytes, sent %d bytes, "+
+			"%d connections, connected %s\n", u.Month, u.RXBytes,
+			u.TXBytes, u.Connections, u.Connected)
+	}
+}
+
+// printMetrics gets the request metrics of the APIs from the daemon and
+// prints them
+func printMetrics() {
+	// create client
+	c, err := newClient()
+	if err != nil {
+		log.WithError(err).Fatal("error creating client")
+	}
+	defer func() { _ = c.Close() }()
+
+	// get metrics
+	metrics, err := c.GetMetrics()
+	if err != nil {
+		log.WithError(err).Fatal("error getting metrics")
+	}
+
+	// print metrics
+	if jsonOutput {
+		b, err := json.MarshalIndent(metrics, "", "  ")
+		if err != nil {
+			log.WithError(err).Fatal("error converting metrics to JSON")
+		}
+		fmt.Println(string(b))
+		return
+	}
+	for _, m := range metrics {
+		average := time.Duration(0)
+		if m.Count > 0 {
+			average = m.Latency / time.Duration(m.Count)
+		}
+		fmt.Printf("%-6s  %-24s  %d requests, %d errors, "+
+			"average latency %s, latency buckets %v\n", m.API, m.Request,
+			m.Count, m.Errors, average, m.LatencyBuckets)
+	}
+}
+
+// printCodes prints the event codes of the daemon log messages
+func printCodes() {
+	codes := logging.Codes()
+	if jsonOutput {
+		b, err := json.MarshalIndent(codes, "", "  ")
+		if err != nil {
+			log.WithError(err).Fatal("error converting codes to JSON")
+		}
+		fmt.Println(string(b))
+		return
+	}
+	for _, c := range codes {
+		fmt.Printf("%s  %-7s  %s\n", c.Code, c.Level, c.Description)
+	}
+}
+
+// progress prints the progress message with format and args unless the
+// output is quiet
+func progress(format string, args ...any) {
+	if quietOutput {
+		return
+	}
+	fmt.Printf(format, args...)
+}
+
+// printField prints the status field with name and value, the values are
+// aligned unless the output is plain
+func printField(name string, value any) {
+	if plainOutput {
+		fmt.Printf("%s: %v\n", name, value)
+		return
+	}
+	fmt.Printf("%-18s%v\n", name+":", value)
+}
+
+// printList prints the status field with name and the list of values, the
+// values are printed on one line if the output is plain
+func printList(name string, values []string) {
+	quoted := []string{}
+	for _, v := range values {
+		quoted = append(quoted, fmt.Sprintf("\"%s\"", v))
+	}
+	if plainOutput {
+		fmt.Printf("%s: %s\n", name, strings.Join(quoted, ", "))
+		return
+	}
+	fmt.Printf("%s:\n", name)
+	for _, v := range quoted {
+		fmt.Printf("  - %s\n", v)
+	}
+}
+
 // printStatus prints status on the command line
 func printStatus(status *vpnstatus.Status) {
-	fmt.Printf("Trusted Network:  %s\n", status.TrustedNetwork)
-	fmt.Printf("Connection State: %s\n", status.ConnectionState)
-	fmt.Printf("IP:               %s\n", status.IP)
-	fmt.Printf("Device:           %s\n", status.Device)
+	printField("Trusted Network", status.TrustedNetwork)
+	printField("Connection State", status.ConnectionState)
+	printField("IP", status.IP)
+	printField("Device", status.Device)
 
 	connectedAt := time.Unix(status.ConnectedAt, 0)
 	if connectedAt.IsZero() {
-		fmt.Printf("Connected At:     0\n")
+		printField("Connected At", 0)
+	} else {
+		printField("Connected At", connectedAt)
+	}
+	printField("Uptime", time.Duration(status.Uptime)*time.Second)
+	if status.OwnerUID >= 0 {
+		printField("Owner", fmt.Sprintf("%s (uid %d)", status.Owner,
+			status.OwnerUID))
 	} else {
-		fmt.Printf("Connected At:     %s\n", connectedAt)
+		printField("Owner", "")
 	}
 
-	fmt.Printf("Servers:\n")
-	for _, server := range status.Servers {
-		fmt.Printf("  - \"%s\"\n", server)
+	printList("Servers", status.Servers)
+
+	printField("OC Running", status.OCRunning)
+	printField("VPN Config", fmt.Sprintf("%+v", status.VPNConfig))
+	if status.VPNConfig != nil && status.VPNConfig.Banner.Text != "" {
+		printField("Banner", status.VPNConfig.Banner.Text)
+	}
+	printField("Proxy", status.Proxy)
+	printField("Received", fmt.Sprintf("%d bytes, %d packets",
+		status.RXBytes, status.RXPackets))
+	printField("Sent", fmt.Sprintf("%d bytes, %d packets",
+		status.TXBytes, status.TXPackets))
+	printField("DNS Leaks", fmt.Sprintf("%d blocked", status.DNSLeaksBlocked))
+	printField("Route Churn", fmt.Sprintf("%d aggregations",
+		status.RouteAggregations))
+	printList("Gateway Excludes", status.GatewayExcludes)
+	printField("Schedule", status.ScheduleState)
+	printField("Connectivity", status.Connectivity)
+	printField("Compression", status.Compression)
+	printField("TND", status.TNDState)
+	printField("Last Disconnect", status.DisconnectReason)
+	printField("APIs", strings.Join(status.APIs, ", "))
+	printField("TrafPol", status.TrafPolState)
+	printField("Pause", status.PauseState)
+	printField("Preferred Server", status.PreferredServer)
+
+	if verbose {
+		printList("DNS Servers", status.DNSServers)
+		printList("Search Domains", status.DNSSearchDomains)
+		printList("Split Domains", status.DNSSplitDomains)
 	}
 
-	fmt.Printf("OC Running:       %s\n", status.OCRunning)
-	fmt.Printf("VPN Config:       %+v\n", status.VPNConfig)
+	if status.RetryAt > 0 {
+		retryIn := time.Until(time.Unix(status.RetryAt, 0)).Round(time.Second)
+		if retryIn < 0 {
+			retryIn = 0
+		}
+		printField("Retrying In", fmt.Sprintf("%s (attempt %d)", retryIn,
+			status.RetryAttempt))
+	}
 }
 
 // getStatus gets the VPN status from the daemon
 func getStatus() {
 	// create client
-	c, err := client.NewClient(config)
+	c, err := newClient()
 	if err != nil {
+		printCachedStatus(err)
 		log.WithError(err).Fatal("error creating client")
 	}
 	defer func() { _ = c.Close() }()
 
+	// print status snapshot as JSON
+	if jsonOutput && config.Tunnel == "" {
+		status, err := c.GetStatus()
+		if err != nil {
+			printCachedStatus(err)
+			log.WithError(err).Fatal("error getting status")
+		}
+		saveStatusCache(status)
+		printStatusJSON(status)
+		return
+	}
+
 	// get status
 	status, err := c.Query()
 	if err != nil {
+		printCachedStatus(err)
 		log.Fatal(err)
 	}
+	saveStatusCache(status)
 
 	// print status
 	printStatus(status)
 }
 
+// printStatusJSON prints status as JSON
+func printStatusJSON(status *vpnstatus.Status) {
+	b, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		log.WithError(err).Fatal("error converting status to JSON")
+	}
+	fmt.Println(string(b))
+}
+
+// printCachedStatus prints the last known status with a warning if the
+// daemon is unreachable because of err and exits with exit status 1, it
+// returns if there is no last known status
+func printCachedStatus(err error) {
+	if !useStatusCache() {
+		return
+	}
+	cache, cerr := loadStatusCache()
+	if cerr != nil {
+		log.WithError(cerr).Debug("Client could not load last known status")
+		return
+	}
+	log.WithError(err).WithFields(log.Fields{
+		"time": cache.Time.Format(time.RFC3339),
+		"age":  time.Since(cache.Time).Round(time.Second),
+	}).Warn("Daemon unreachable, showing last known status, it may be stale")
+	if jsonOutput {
+		printStatusJSON(cache.Status)
+	} else {
+		printStatus(cache.Status)
+	}
+	os.Exit(1)
+}
+
 // monitor subscribes to VPN status updates from the daemon and displays them
 func monitor() {
 	// create client
-	c, err := client.NewClient(config)
+	c, err := newClient()
 	if err != nil {
 		log.WithError(err).Fatal("error creating client")
 	}
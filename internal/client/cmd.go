@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/telekom-mms/oc-daemon/internal/daemon"
 	"github.com/telekom-mms/oc-daemon/pkg/client"
+	"github.com/telekom-mms/oc-daemon/pkg/xmlprofile"
 )
 
 var (
@@ -16,7 +18,30 @@ var (
 	config *client.Config
 
 	// command line arguments
-	command = ""
+	command        = ""
+	host           = ""
+	jsonOutput     = false
+	plainOutput    = false
+	quietOutput    = false
+	verbose        = false
+	deviceAuth     = false
+	tndAction      = ""
+	tndTimeout     time.Duration
+	trafPolAction  = ""
+	trafPolTimeout time.Duration
+	pauseTimeout   time.Duration
+	logLines       = 0
+	pingServer     = false
+	effective      = false
+	preferServer   = ""
+	excludeAction  = ""
+	excludeAddress = ""
+	notifyReset    = false
+	notifyPolicy   client.NotificationPolicy
+	notifyQuiet    = ""
+
+	// serverOverride specifies if the server is set on the command line
+	serverOverride = false
 )
 
 // saveConfig saves the user config to the user dir
@@ -31,6 +56,21 @@ func saveConfig() {
 	}
 }
 
+// setOutputMode sets the log output of the plain and quiet output modes
+func setOutputMode() {
+	if plainOutput {
+		// stable "key=value" lines without colors
+		log.SetFormatter(&log.TextFormatter{
+			DisableColors:    true,
+			DisableTimestamp: true,
+		})
+	}
+	if quietOutput {
+		// fatal errors still exit with exit status 1
+		log.SetLevel(log.PanicLevel)
+	}
+}
+
 // parseCommandLine parses the command line
 func parseCommandLine() {
 	// define command line arguments
@@ -40,9 +80,23 @@ func parseCommandLine() {
 	ca := flag.String("ca", "", "set additional CA certificate `file`")
 	srv := flag.String("server", "", "set server `address`")
 	usr := flag.String("user", "", "set `username`")
+	prf := flag.String("profile", "", "set `name` of XML profile in "+
+		"profiles directory")
+	tun := flag.String("tunnel", "", "use additional tunnel with `name` "+
+		"of OC-Daemon instead of the main VPN connection")
 	sys := flag.Bool("system-settings", false, "use system settings "+
 		"instead of user configuration")
 	ver := flag.Bool("version", false, "print version")
+	hst := flag.String("host", "", "control OC-Daemon on remote `host` "+
+		"over ssh, e.g., user@machine")
+	jsn := flag.Bool("json", false, "print output as JSON (facts, status, codes, usage, metrics)")
+	vrb := flag.Bool("verbose", false, "print verbose output (status)")
+	pln := flag.Bool("plain", false, "print plain output without colors "+
+		"and alignment, e.g., for screen readers")
+	qut := flag.Bool("quiet", false, "print no progress and error "+
+		"messages, only report the result with the exit status")
+	dev := flag.Bool("device", false, "connect with login approved on "+
+		"another device, e.g., a phone (connect)")
 
 	// set usage output
 	flag.Usage = func() {
@@ -63,27 +117,90 @@ func parseCommandLine() {
 		usage("        connect to the VPN (default)\n")
 		usage("  disconnect\n")
 		usage("        disconnect from the VPN\n")
+		usage("  cancel\n")
+		usage("        cancel a connection attempt that is still connecting\n")
 		usage("  reconnect\n")
 		usage("        reconnect to the VPN\n")
 		usage("  list\n")
 		usage("        list VPN servers in XML Profile\n")
+		usage("  servers [-ping]\n")
+		usage("        list VPN servers in XML Profile of OC-Daemon and " +
+			"optionally ping them\n")
+		usage("  routes [-effective]\n")
+		usage("        show split routes of the VPN connection and " +
+			"optionally their effective routing decisions\n")
+		usage("  profiles\n")
+		usage("        list installed XML profiles\n")
+		usage("  prefer [server]\n")
+		usage("        set preferred VPN server of OC-Daemon, reset it " +
+			"without server\n")
+		usage("  excludes [add|remove address]\n")
+		usage("        list, add or remove split excludes of the current " +
+			"VPN connection (root)\n")
+		usage("  pause [-timeout duration]\n")
+		usage("        pause VPN connection: remove split routing and DNS " +
+			"but keep the connection\n")
+		usage("  resume\n")
+		usage("        resume paused VPN connection\n")
+		usage("  notifications [reset] [-min-severity severity] " +
+			"[-rate-interval duration] [-rate-burst number] [-quiet hours]\n")
+		usage("        set notification policy of OC-Daemon until next " +
+			"config reload or reset it\n")
+		usage("  reload-profile\n")
+		usage("        make OC-Daemon read its XML profile again (root)\n")
 		usage("  status\n")
 		usage("        show VPN status\n")
 		usage("  monitor\n")
 		usage("        monitor VPN status updates\n")
+		usage("  facts\n")
+		usage("        show VPN status as facts for configuration management\n")
 		usage("  save\n")
 		usage("        save current settings to user configuration\n")
+		usage("  tnd enable|disable [-timeout duration]\n")
+		usage("        enable or disable trusted network detection (root)\n")
+		usage("  trafpol enable|disable [-timeout duration]\n")
+		usage("        enable or disable traffic policing (root)\n")
+		usage("  logs [-lines number]\n")
+		usage("        show recent log entries of OC-Daemon\n")
+		usage("  usage\n")
+		usage("        show VPN usage of OC-Daemon per month\n")
+		usage("  metrics\n")
+		usage("        show request metrics of the OC-Daemon APIs\n")
+		usage("  codes\n")
+		usage("        show event codes of OC-Daemon log messages\n")
 		usage("\nExamples:\n")
 		usage("  %s connect\n", cmd)
 		usage("  %s disconnect\n", cmd)
 		usage("  %s reconnect\n", cmd)
 		usage("  %s status\n", cmd)
+		usage("  %s status -verbose\n", cmd)
 		usage("  %s list\n", cmd)
+		usage("  %s servers -ping\n", cmd)
+		usage("  %s prefer \"My SSL VPN Server\"\n", cmd)
+		usage("  %s routes -effective\n", cmd)
+		usage("  %s -json facts\n", cmd)
+		usage("  %s -json status\n", cmd)
+		usage("  %s -plain status\n", cmd)
+		usage("  %s -quiet connect\n", cmd)
 		usage("  %s -server \"My SSL VPN Server\" connect\n", cmd)
 		usage("  %s -server \"My SSL VPN Server\" save\n", cmd)
 		usage("  %s -user exampleuser connect\n", cmd)
+		usage("  %s -profile lab connect\n", cmd)
+		usage("  %s -tunnel lab -profile lab connect\n", cmd)
+		usage("  %s -device connect\n", cmd)
 		usage("  %s -user $USER save\n", cmd)
 		usage("  %s -system-settings save\n", cmd)
+		usage("  %s -host user@machine status\n", cmd)
+		usage("  sudo %s tnd disable -timeout 30m\n", cmd)
+		usage("  sudo %s trafpol disable -timeout 10m\n", cmd)
+		usage("  %s pause -timeout 5m\n", cmd)
+		usage("  sudo %s excludes add 192.168.1.0/24\n", cmd)
+		usage("  %s notifications -min-severity warning -quiet 22:00-07:00\n", cmd)
+		usage("  %s notifications -rate-interval 10m -rate-burst 2\n", cmd)
+		usage("  sudo %s reload-profile\n", cmd)
+		usage("  %s logs -lines 100\n", cmd)
+		usage("  %s -json usage\n", cmd)
+		usage("  %s -json codes\n", cmd)
 	}
 
 	// parse arguments
@@ -98,6 +215,114 @@ func parseCommandLine() {
 	// set command
 	command = flag.Arg(0)
 
+	// set remote host
+	host = *hst
+
+	// set json output
+	jsonOutput = *jsn
+
+	// set plain and quiet output
+	plainOutput = *pln
+	quietOutput = *qut
+	setOutputMode()
+
+	// set device authorization
+	deviceAuth = *dev
+
+	// set verbose output, also allow it after the status command
+	verbose = *vrb
+	if command == "status" && flag.NArg() > 1 {
+		flags := flag.NewFlagSet("status", flag.ExitOnError)
+		flags.BoolVar(&verbose, "verbose", verbose, "print verbose output")
+		_ = flags.Parse(flag.Args()[1:])
+	}
+
+	// set server of the prefer command
+	if command == "prefer" {
+		preferServer = flag.Arg(1)
+	}
+
+	// set action and address of the excludes command
+	if command == "excludes" {
+		excludeAction = flag.Arg(1)
+		excludeAddress = flag.Arg(2)
+	}
+
+	// set reset and policy of the notifications command
+	if command == "notifications" {
+		args := flag.Args()[1:]
+		if flag.Arg(1) == "reset" {
+			notifyReset = true
+			args = args[1:]
+		}
+		flags := flag.NewFlagSet("notifications", flag.ExitOnError)
+		flags.StringVar(&notifyPolicy.MinSeverity, "min-severity",
+			client.NotificationSeverityInfo, "emit only notifications "+
+				"with `severity` info, warning or critical or higher")
+		flags.DurationVar(&notifyPolicy.RateInterval, "rate-interval", 0,
+			"allow each notification only once per `duration` after "+
+				"the burst")
+		flags.IntVar(&notifyPolicy.RateBurst, "rate-burst", 1,
+			"allow `number` of each notification at once")
+		flags.StringVar(&notifyQuiet, "quiet", "", "no notifications "+
+			"during quiet `hours`, e.g., 22:00-07:00")
+		_ = flags.Parse(args)
+	}
+
+	// set tnd action and timeout of the tnd command
+	if command == "tnd" {
+		tndAction = flag.Arg(1)
+		if flag.NArg() > 2 {
+			flags := flag.NewFlagSet("tnd", flag.ExitOnError)
+			flags.DurationVar(&tndTimeout, "timeout", 0,
+				"enable trusted network detection again after timeout")
+			_ = flags.Parse(flag.Args()[2:])
+		}
+	}
+
+	// set trafpol action and timeout of the trafpol command
+	if command == "trafpol" {
+		trafPolAction = flag.Arg(1)
+		if flag.NArg() > 2 {
+			flags := flag.NewFlagSet("trafpol", flag.ExitOnError)
+			flags.DurationVar(&trafPolTimeout, "timeout", 0,
+				"enable traffic policing again after timeout")
+			_ = flags.Parse(flag.Args()[2:])
+		}
+	}
+
+	// set timeout of the pause command
+	if command == "pause" && flag.NArg() > 1 {
+		flags := flag.NewFlagSet("pause", flag.ExitOnError)
+		flags.DurationVar(&pauseTimeout, "timeout", 0,
+			"resume VPN connection after timeout")
+		_ = flags.Parse(flag.Args()[1:])
+	}
+
+	// set number of lines of the logs command
+	if command == "logs" && flag.NArg() > 1 {
+		flags := flag.NewFlagSet("logs", flag.ExitOnError)
+		flags.IntVar(&logLines, "lines", 0,
+			"show only the last `number` of log entries")
+		_ = flags.Parse(flag.Args()[1:])
+	}
+
+	// set ping of the servers command
+	if command == "servers" && flag.NArg() > 1 {
+		flags := flag.NewFlagSet("servers", flag.ExitOnError)
+		flags.BoolVar(&pingServer, "ping", false,
+			"probe reachability and latency of the servers")
+		_ = flags.Parse(flag.Args()[1:])
+	}
+
+	// set effective of the routes command
+	if command == "routes" && flag.NArg() > 1 {
+		flags := flag.NewFlagSet("routes", flag.ExitOnError)
+		flags.BoolVar(&effective, "effective", false,
+			"show effective routing decisions of conflicting routes")
+		_ = flags.Parse(flag.Args()[1:])
+	}
+
 	// set client certificate
 	if *cert != "" {
 		config.ClientCertificate = *cert
@@ -116,6 +341,7 @@ func parseCommandLine() {
 	// set vpn server
 	if *srv != "" {
 		config.VPNServer = *srv
+		serverOverride = true
 	}
 
 	// set username
@@ -123,6 +349,19 @@ func parseCommandLine() {
 		config.User = *usr
 	}
 
+	// set profile
+	if *prf != "" {
+		if !xmlprofile.ValidProfileName(*prf) {
+			log.WithField("profile", *prf).Fatal("Client got invalid profile name")
+		}
+		config.Profile = *prf
+	}
+
+	// set additional tunnel
+	if *tun != "" {
+		config.Tunnel = *tun
+	}
+
 	// reset to system settings
 	if *sys {
 		systemConfig := client.SystemConfig()
@@ -144,6 +383,12 @@ func Run() {
 	// parse command line arguments
 	parseCommandLine()
 
+	// the event codes do not depend on the configuration
+	if command == "codes" {
+		printCodes()
+		return
+	}
+
 	// make sure config is not empty
 	if config.Empty() {
 		log.Fatal("Cannot run with empty configuration. You need to " +
@@ -155,18 +400,50 @@ func Run() {
 	switch command {
 	case "list":
 		listServers()
+	case "servers":
+		listDaemonServers()
+	case "routes":
+		listRoutes()
+	case "profiles":
+		listProfiles()
+	case "prefer":
+		setPreferredServer()
+	case "excludes":
+		setSplitExcludes()
+	case "notifications":
+		setNotificationPolicy()
+	case "reload-profile":
+		reloadProfile()
 	case "", "connect":
 		connectVPN()
 	case "disconnect":
 		disconnectVPN()
+	case "cancel":
+		cancelVPN()
 	case "reconnect":
 		reconnectVPN()
+	case "pause":
+		pauseVPN()
+	case "resume":
+		resumeVPN()
 	case "status":
 		getStatus()
 	case "monitor":
 		monitor()
+	case "facts":
+		printFacts()
 	case "save":
 		saveConfig()
+	case "tnd":
+		setTND()
+	case "trafpol":
+		setTrafPol()
+	case "logs":
+		printLogs()
+	case "usage":
+		printUsage()
+	case "metrics":
+		printMetrics()
 	default:
 		log.Fatalf("unknown command: %s", command)
 	}
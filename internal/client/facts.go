@@ -0,0 +1,118 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/daemon"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+	"github.com/telekom-mms/oc-daemon/pkg/xmlprofile"
+)
+
+const (
+	// factUnknown is the value of facts that cannot be determined
+	factUnknown = "unknown"
+)
+
+// Traffic policing modes in facts
+const (
+	trafPolModeOff      = "off"
+	trafPolModeInactive = "inactive"
+	trafPolModeActive   = "active"
+)
+
+// fact is a configuration management fact
+type fact struct {
+	key   string
+	value string
+}
+
+// getProfileHash returns the sha256 hash of the system xml profile
+func getProfileHash() string {
+	b, err := os.ReadFile(xmlprofile.SystemProfile)
+	if err != nil {
+		return factUnknown
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(b))
+}
+
+// getTrafPolMode returns the traffic policing mode based on the xml profile
+// and status
+func getTrafPolMode(profile *xmlprofile.Profile, status *vpnstatus.Status) string {
+	if profile == nil {
+		return factUnknown
+	}
+	if !profile.GetAlwaysOn() ||
+		(status.VPNConfig != nil && status.VPNConfig.Flags.DisableAlwaysOnVPN) {
+		return trafPolModeOff
+	}
+	if status.TrustedNetwork.Trusted() {
+		return trafPolModeInactive
+	}
+	return trafPolModeActive
+}
+
+// getFacts returns the facts from status. Version and xml profile are only
+// available on the local host
+func getFacts(status *vpnstatus.Status) []fact {
+	version := factUnknown
+	profileHash := factUnknown
+	trafPolMode := factUnknown
+	if host == "" {
+		version = daemon.Version
+		profileHash = getProfileHash()
+		trafPolMode = getTrafPolMode(xmlprofile.LoadSystemProfile(), status)
+	}
+
+	return []fact{
+		{"oc_daemon_version", version},
+		{"oc_daemon_connection_state", status.ConnectionState.String()},
+		{"oc_daemon_trusted_network", status.TrustedNetwork.String()},
+		{"oc_daemon_oc_running", status.OCRunning.String()},
+		{"oc_daemon_ip", status.IP},
+		{"oc_daemon_device", status.Device},
+		{"oc_daemon_profile_hash", profileHash},
+		{"oc_daemon_trafpol_mode", trafPolMode},
+	}
+}
+
+// printFacts gets the VPN status from the daemon and prints it as facts for
+// configuration management tools
+func printFacts() {
+	// create client
+	c, err := newClient()
+	if err != nil {
+		log.WithError(err).Fatal("error creating client")
+	}
+	defer func() { _ = c.Close() }()
+
+	// get status
+	status, err := c.Query()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	facts := getFacts(status)
+
+	// print facts as JSON
+	if jsonOutput {
+		m := make(map[string]string)
+		for _, f := range facts {
+			m[f.key] = f.value
+		}
+		b, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			log.WithError(err).Fatal("error converting facts to JSON")
+		}
+		fmt.Println(string(b))
+		return
+	}
+
+	// print facts as key=value
+	for _, f := range facts {
+		fmt.Printf("%s=%s\n", f.key, f.value)
+	}
+}
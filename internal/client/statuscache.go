@@ -0,0 +1,70 @@
+package client
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/pkg/client"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// statusCacheName is the name of the file with the last known VPN status in
+// the user config dir
+const statusCacheName = "status.json"
+
+// statusCache is the content of the status cache file
+type statusCache struct {
+	Time   time.Time
+	Status *vpnstatus.Status
+}
+
+// statusCacheFile returns the file with the last known VPN status
+func statusCacheFile() string {
+	return filepath.Join(filepath.Dir(client.UserConfig()), statusCacheName)
+}
+
+// useStatusCache returns whether the status cache is used, it only contains
+// the status of the VPN connection on the local host
+func useStatusCache() bool {
+	return host == "" && config.Tunnel == ""
+}
+
+// saveStatusCache saves status with the current time as last known VPN
+// status
+func saveStatusCache(status *vpnstatus.Status) {
+	if !useStatusCache() {
+		return
+	}
+	b, err := json.Marshal(&statusCache{Time: time.Now(), Status: status})
+	if err != nil {
+		log.WithError(err).Error("Client could not save status")
+		return
+	}
+	file := statusCacheFile()
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		log.WithError(err).Error("Client could not create user dir")
+		return
+	}
+	if err := os.WriteFile(file, b, 0600); err != nil {
+		log.WithError(err).Error("Client could not save status")
+	}
+}
+
+// loadStatusCache returns the last known VPN status
+func loadStatusCache() (*statusCache, error) {
+	b, err := os.ReadFile(statusCacheFile())
+	if err != nil {
+		return nil, err
+	}
+	cache := &statusCache{}
+	if err := json.Unmarshal(b, cache); err != nil {
+		return nil, err
+	}
+	if cache.Status == nil {
+		cache.Status = vpnstatus.New()
+	}
+	return cache, nil
+}
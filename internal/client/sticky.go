@@ -0,0 +1,104 @@
+package client
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/godbus/dbus/v5"
+	log "github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/pkg/client"
+	"github.com/telekom-mms/oc-daemon/pkg/xmlprofile"
+)
+
+// stickyServersName is the name of the file with the last used VPN server
+// per network in the user config dir
+const stickyServersName = "servers.json"
+
+// stickyServersFile returns the file with the last used VPN server per
+// network
+func stickyServersFile() string {
+	return filepath.Join(filepath.Dir(client.UserConfig()), stickyServersName)
+}
+
+// currentNetwork returns the name of the primary network connection as
+// reported by NetworkManager, e.g., the SSID of a WiFi network, empty if it
+// is unknown
+func currentNetwork() string {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		log.WithError(err).Debug("Client could not connect to system bus")
+		return ""
+	}
+	defer func() { _ = conn.Close() }()
+
+	var primary dbus.ObjectPath
+	nm := conn.Object("org.freedesktop.NetworkManager",
+		"/org/freedesktop/NetworkManager")
+	if err := nm.StoreProperty("org.freedesktop.NetworkManager.PrimaryConnection",
+		&primary); err != nil || primary == "/" {
+		log.WithError(err).Debug("Client could not get primary network connection")
+		return ""
+	}
+
+	var id string
+	active := conn.Object("org.freedesktop.NetworkManager", primary)
+	if err := active.StoreProperty("org.freedesktop.NetworkManager.Connection.Active.Id",
+		&id); err != nil {
+		log.WithError(err).Debug("Client could not get network connection name")
+		return ""
+	}
+	return id
+}
+
+// loadStickyServers loads the last used VPN server per network
+func loadStickyServers() map[string]string {
+	servers := make(map[string]string)
+	b, err := os.ReadFile(stickyServersFile())
+	if err != nil {
+		return servers
+	}
+	if err := json.Unmarshal(b, &servers); err != nil {
+		log.WithError(err).Warn("Client could not parse last used VPN servers")
+		return make(map[string]string)
+	}
+	return servers
+}
+
+// stickyServer returns the last used VPN server on network if it is still
+// in profile, empty otherwise
+func stickyServer(profile *xmlprofile.Profile, network string) string {
+	server := loadStickyServers()[network]
+	if server == "" {
+		return ""
+	}
+	for _, s := range append(profile.GetVPNServerHostNames(),
+		profile.GetVPNServers()...) {
+		if s == server {
+			return server
+		}
+	}
+	return ""
+}
+
+// saveStickyServer saves server as last used VPN server on network
+func saveStickyServer(network, server string) {
+	servers := loadStickyServers()
+	if servers[network] == server {
+		return
+	}
+	servers[network] = server
+	b, err := json.MarshalIndent(servers, "", "    ")
+	if err != nil {
+		log.WithError(err).Error("Client could not save last used VPN server")
+		return
+	}
+	file := stickyServersFile()
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		log.WithError(err).Error("Client could not create user dir")
+		return
+	}
+	if err := os.WriteFile(file, b, 0600); err != nil {
+		log.WithError(err).Error("Client could not save last used VPN server")
+	}
+}
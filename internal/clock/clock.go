@@ -0,0 +1,77 @@
+// Package clock contains a clock abstraction that allows replacing the real
+// clock and its timers with a fake clock in tests of time-dependent code
+package clock
+
+import (
+	"time"
+)
+
+// Timer is a timer created by a Clock
+type Timer interface {
+	// C returns the channel the current time is sent on when the timer
+	// expires
+	C() <-chan time.Time
+
+	// Stop stops the timer, returns false if the timer already expired
+	// or was stopped
+	Stop() bool
+
+	// Reset changes the timer to expire after duration d, returns true
+	// if the timer had been active
+	Reset(d time.Duration) bool
+}
+
+// Clock is a source of the current time and timers
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+
+	// Since returns the time elapsed since t
+	Since(t time.Time) time.Duration
+
+	// NewTimer returns a new Timer that expires after duration d
+	NewTimer(d time.Duration) Timer
+}
+
+// realTimer is a Timer based on time.Timer
+type realTimer struct {
+	timer *time.Timer
+}
+
+// C returns the channel of the timer
+func (r *realTimer) C() <-chan time.Time {
+	return r.timer.C
+}
+
+// Stop stops the timer
+func (r *realTimer) Stop() bool {
+	return r.timer.Stop()
+}
+
+// Reset resets the timer to duration d
+func (r *realTimer) Reset(d time.Duration) bool {
+	return r.timer.Reset(d)
+}
+
+// realClock is a Clock based on the time package
+type realClock struct{}
+
+// Now returns the current time
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Since returns the time elapsed since t
+func (realClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+// NewTimer returns a new Timer that expires after duration d
+func (realClock) NewTimer(d time.Duration) Timer {
+	return &realTimer{timer: time.NewTimer(d)}
+}
+
+// New returns a new real Clock
+func New() Clock {
+	return realClock{}
+}
@@ -0,0 +1,79 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+// TestClockNewTimer tests NewTimer of the real Clock
+func TestClockNewTimer(t *testing.T) {
+	c := New()
+	timer := c.NewTimer(time.Millisecond)
+	<-timer.C()
+	if timer.Stop() {
+		t.Errorf("expired timer should not be active")
+	}
+	if timer.Reset(time.Hour) {
+		t.Errorf("expired timer should not be active")
+	}
+	if !timer.Stop() {
+		t.Errorf("reset timer should be active")
+	}
+	if c.Since(c.Now()) < 0 {
+		t.Errorf("time should not go backwards")
+	}
+}
+
+// TestFakeAdvance tests Advance of Fake
+func TestFakeAdvance(t *testing.T) {
+	start := time.Unix(0, 0)
+	f := NewFake(start)
+	timer := f.NewTimer(time.Minute)
+
+	// not expired yet
+	f.Advance(59 * time.Second)
+	select {
+	case <-timer.C():
+		t.Errorf("timer should not expire")
+	default:
+	}
+
+	// expired
+	f.Advance(time.Second)
+	want := start.Add(time.Minute)
+	got := <-timer.C()
+	if !got.Equal(want) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if f.Since(start) != time.Minute {
+		t.Errorf("got %s, want %s", f.Since(start), time.Minute)
+	}
+}
+
+// TestFakeTimerStopReset tests Stop and Reset of timers of Fake
+func TestFakeTimerStopReset(t *testing.T) {
+	f := NewFake(time.Unix(0, 0))
+	timer := f.NewTimer(time.Minute)
+
+	// stopped timer does not expire
+	if !timer.Stop() {
+		t.Errorf("new timer should be active")
+	}
+	f.Advance(time.Hour)
+	select {
+	case <-timer.C():
+		t.Errorf("stopped timer should not expire")
+	default:
+	}
+
+	// reset timer expires
+	if timer.Reset(time.Second) {
+		t.Errorf("stopped timer should not be active")
+	}
+	f.Advance(time.Second)
+	<-timer.C()
+
+	// zero duration timer expires immediately
+	timer = f.NewTimer(0)
+	<-timer.C()
+}
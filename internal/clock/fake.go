@@ -0,0 +1,110 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// fakeTimer is a Timer of a Fake clock
+type fakeTimer struct {
+	clock  *Fake
+	c      chan time.Time
+	when   time.Time
+	active bool
+}
+
+// C returns the channel of the timer
+func (f *fakeTimer) C() <-chan time.Time {
+	return f.c
+}
+
+// Stop stops the timer
+func (f *fakeTimer) Stop() bool {
+	f.clock.mutex.Lock()
+	defer f.clock.mutex.Unlock()
+
+	active := f.active
+	f.active = false
+	return active
+}
+
+// Reset resets the timer to duration d
+func (f *fakeTimer) Reset(d time.Duration) bool {
+	f.clock.mutex.Lock()
+	defer f.clock.mutex.Unlock()
+
+	active := f.active
+	f.when = f.clock.now.Add(d)
+	f.active = true
+	f.clock.fire()
+	return active
+}
+
+// Fake is a Clock that only advances when Advance is called, for testing
+type Fake struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// fire sends the current time on the channels of all expired timers,
+// must be called with the mutex held
+func (f *Fake) fire() {
+	for _, t := range f.timers {
+		if !t.active || t.when.After(f.now) {
+			continue
+		}
+		t.active = false
+		select {
+		case t.c <- f.now:
+		default:
+		}
+	}
+}
+
+// Now returns the current time of the fake clock
+func (f *Fake) Now() time.Time {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.now
+}
+
+// Since returns the time elapsed since t on the fake clock
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// NewTimer returns a new Timer that expires after duration d on the fake
+// clock
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	t := &fakeTimer{
+		clock:  f,
+		c:      make(chan time.Time, 1),
+		when:   f.now.Add(d),
+		active: true,
+	}
+	f.timers = append(f.timers, t)
+	f.fire()
+	return t
+}
+
+// Advance advances the fake clock by duration d and fires all timers that
+// expired in the meantime
+func (f *Fake) Advance(d time.Duration) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.now = f.now.Add(d)
+	f.fire()
+}
+
+// NewFake returns a new Fake clock set to time now
+func NewFake(now time.Time) *Fake {
+	return &Fake{
+		now: now,
+	}
+}
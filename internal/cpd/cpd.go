@@ -1,6 +1,7 @@
 package cpd
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"time"
@@ -38,7 +39,8 @@ type Report struct {
 type CPD struct {
 	reports chan *Report
 	probes  chan struct{}
-	done    chan struct{}
+	done    <-chan struct{}
+	cancel  context.CancelFunc
 }
 
 // check probes the http server
@@ -199,14 +201,18 @@ func (c *CPD) start() {
 	}
 }
 
-// Start starts the captive portal detection
-func (c *CPD) Start() {
+// Start starts the captive portal detection, it runs until Stop is called or
+// ctx is canceled
+func (c *CPD) Start(ctx context.Context) error {
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.done = ctx.Done()
 	go c.start()
+	return nil
 }
 
 // Stop stops the captive portal detection
 func (c *CPD) Stop() {
-	close(c.done)
+	c.cancel()
 	for range c.reports {
 		// wait for channel shutdown
 	}
@@ -232,6 +238,5 @@ func NewCPD() *CPD {
 	return &CPD{
 		reports: make(chan *Report),
 		probes:  make(chan struct{}),
-		done:    make(chan struct{}),
 	}
 }
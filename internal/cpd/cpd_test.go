@@ -1,6 +1,7 @@
 package cpd
 
 import (
+	"context"
 	"log"
 	"reflect"
 	"testing"
@@ -9,7 +10,9 @@ import (
 // testCPDStartStop tests Start and Stop of CPD
 func TestCPDStartStop(t *testing.T) {
 	c := NewCPD()
-	c.Start()
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
 	c.Stop()
 }
 
@@ -26,7 +29,9 @@ func TestCPDHosts(t *testing.T) {
 // TestCPDProbe tests Probe of CPD
 func TestCPDProbe(t *testing.T) {
 	c := NewCPD()
-	c.Start()
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
 	c.Probe()
 	log.Println(<-c.Results())
 	c.Stop()
@@ -46,8 +51,7 @@ func TestCPDResults(t *testing.T) {
 func TestNewCPD(t *testing.T) {
 	c := NewCPD()
 	if c.reports == nil ||
-		c.probes == nil ||
-		c.done == nil {
+		c.probes == nil {
 
 		t.Errorf("got nil, want != nil")
 	}
@@ -0,0 +1,63 @@
+package daemon
+
+import (
+	"os"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/audit"
+)
+
+// openAuditLog opens the audit log configured in the daemon config and
+// closes the current audit log
+func (d *Daemon) openAuditLog() {
+	d.closeAuditLog()
+	if d.config.AuditLog == "" {
+		return
+	}
+
+	l, err := audit.NewLogger(d.config.AuditLog)
+	if err != nil {
+		log.WithError(err).WithField("target", d.config.AuditLog).
+			Error("Daemon could not open audit log")
+		return
+	}
+	d.audit = l
+	d.auditTarget = d.config.AuditLog
+}
+
+// closeAuditLog closes the audit log
+func (d *Daemon) closeAuditLog() {
+	if d.audit == nil {
+		return
+	}
+	if err := d.audit.Close(); err != nil {
+		log.WithError(err).Error("Daemon could not close audit log")
+	}
+	d.audit = nil
+	d.auditTarget = ""
+}
+
+// logAudit logs event requested by sender with uid and details to the audit
+// log
+func (d *Daemon) logAudit(event, sender string, uid int64, details string) {
+	if d.audit == nil {
+		return
+	}
+	r := &audit.Record{
+		Time:    time.Now(),
+		Event:   event,
+		Sender:  sender,
+		UID:     uid,
+		Details: details,
+	}
+	if err := d.audit.Log(r); err != nil {
+		log.WithError(err).WithField("record", r).
+			Error("Daemon could not write audit log record")
+	}
+}
+
+// logAuditDaemon logs event caused by the daemon itself with details to the
+// audit log
+func (d *Daemon) logAuditDaemon(event, details string) {
+	d.logAudit(event, audit.SenderDaemon, int64(os.Getuid()), details)
+}
@@ -0,0 +1,140 @@
+package daemon
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/clock"
+)
+
+// backoff schedules retries with exponential backoff and jitter
+type backoff struct {
+	// initialDelay is the delay before the first retry, it doubles with
+	// each retry up to maxDelay
+	initialDelay time.Duration
+	maxDelay     time.Duration
+
+	// maxAttempts is the maximum number of retries, 0 means unlimited
+	maxAttempts int
+
+	// jitter is the fraction of the delay that is randomly added to or
+	// subtracted from the delay, e.g., 0.1 for +/- 10%
+	jitter float64
+
+	clock  clock.Clock
+	random func() float64
+
+	// attempts is the number of scheduled retries since the last reset
+	attempts int
+
+	// timer for the next retry and its expiry time
+	timer clock.Timer
+	next  time.Time
+}
+
+// delay returns the delay before the next retry without jitter
+func (b *backoff) delay() time.Duration {
+	delay := b.initialDelay
+	for i := 0; i < b.attempts; i++ {
+		delay *= 2
+		if delay >= b.maxDelay {
+			return b.maxDelay
+		}
+	}
+	return delay
+}
+
+// jittered returns delay with random jitter applied, limited by the max
+// delay
+func (b *backoff) jittered(delay time.Duration) time.Duration {
+	if b.jitter <= 0 {
+		return delay
+	}
+	delta := float64(delay) * b.jitter * (2*b.random() - 1)
+	delay += time.Duration(delta)
+	if delay > b.maxDelay {
+		delay = b.maxDelay
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	return delay
+}
+
+// schedule schedules the next retry and returns its delay, returns false if
+// the maximum number of attempts is reached
+func (b *backoff) schedule() (time.Duration, bool) {
+	if b.maxAttempts > 0 && b.attempts >= b.maxAttempts {
+		return 0, false
+	}
+
+	b.stop()
+	delay := b.jittered(b.delay())
+	b.timer = b.clock.NewTimer(delay)
+	b.next = b.clock.Now().Add(delay)
+	b.attempts++
+	return delay, true
+}
+
+// pending returns whether a retry is scheduled
+func (b *backoff) pending() bool {
+	return b.timer != nil
+}
+
+// nextRetry returns the time of the scheduled retry, zero if no retry is
+// scheduled
+func (b *backoff) nextRetry() time.Time {
+	if b.timer == nil {
+		return time.Time{}
+	}
+	return b.next
+}
+
+// expired marks the scheduled retry as done after its timer expired
+func (b *backoff) expired() {
+	b.timer = nil
+	b.next = time.Time{}
+}
+
+// stop stops the scheduled retry
+func (b *backoff) stop() {
+	if b.timer == nil {
+		return
+	}
+	if !b.timer.Stop() {
+		select {
+		case <-b.timer.C():
+		default:
+		}
+	}
+	b.timer = nil
+	b.next = time.Time{}
+}
+
+// reset stops the scheduled retry and resets the attempts
+func (b *backoff) reset() {
+	b.stop()
+	b.attempts = 0
+}
+
+// timerC returns the channel of the retry timer or nil if no retry is
+// scheduled
+func (b *backoff) timerC() <-chan time.Time {
+	if b.timer == nil {
+		return nil
+	}
+	return b.timer.C()
+}
+
+// newBackoff returns a new backoff
+func newBackoff(initialDelay, maxDelay time.Duration, maxAttempts int,
+	jitter float64) *backoff {
+	return &backoff{
+		initialDelay: initialDelay,
+		maxDelay:     maxDelay,
+		maxAttempts:  maxAttempts,
+		jitter:       jitter,
+		clock:        clock.New(),
+		random:       rand.Float64,
+	}
+}
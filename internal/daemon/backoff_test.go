@@ -0,0 +1,73 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/clock"
+)
+
+// TestBackoffJittered tests jittered of backoff
+func TestBackoffJittered(t *testing.T) {
+	b := newBackoff(time.Second, 10*time.Second, 0, 0.5)
+
+	// test without jitter
+	b.jitter = 0
+	if got := b.jittered(time.Second); got != time.Second {
+		t.Errorf("got %s, want %s", got, time.Second)
+	}
+
+	// test random values at both ends
+	b.jitter = 0.5
+	for random, want := range map[float64]time.Duration{
+		0:   4 * time.Second,
+		0.5: 8 * time.Second,
+		1:   10 * time.Second,
+	} {
+		b.random = func() float64 { return random }
+		if got := b.jittered(8 * time.Second); got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+}
+
+// TestBackoffSchedule tests schedule of backoff
+func TestBackoffSchedule(t *testing.T) {
+	now := time.Now()
+	fake := clock.NewFake(now)
+	b := newBackoff(time.Second, time.Minute, 2, 0)
+	b.clock = fake
+	defer b.stop()
+
+	// test first attempt
+	delay, ok := b.schedule()
+	if !ok || delay != time.Second {
+		t.Errorf("got %s, %t, want %s, true", delay, ok, time.Second)
+	}
+	if !b.pending() || !b.nextRetry().Equal(now.Add(time.Second)) {
+		t.Errorf("retry should be pending at %s", now.Add(time.Second))
+	}
+	fake.Advance(time.Second)
+	<-b.timerC()
+	b.expired()
+	if b.pending() || !b.nextRetry().IsZero() {
+		t.Errorf("retry should not be pending after expiry")
+	}
+
+	// test second attempt with doubled delay
+	delay, ok = b.schedule()
+	if !ok || delay != 2*time.Second {
+		t.Errorf("got %s, %t, want %s, true", delay, ok, 2*time.Second)
+	}
+
+	// test max attempts
+	if _, ok := b.schedule(); ok {
+		t.Errorf("retry should not be scheduled after max attempts")
+	}
+
+	// test reset
+	b.reset()
+	if b.pending() || b.attempts != 0 {
+		t.Errorf("backoff should be reset")
+	}
+}
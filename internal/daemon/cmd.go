@@ -1,39 +1,111 @@
 package daemon
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"syscall"
+	"time"
 
-	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/ocrunner"
 )
 
 const (
 	// configDir is the directory for the configuration
 	configDir = "/var/lib/oc-daemon"
 
-	// xmlProfile is the AnyConnect Profile
-	xmlProfile = configDir + "/profile.xml"
-
 	// runDir is the directory for runtime files
 	runDir = "/run/oc-daemon"
 
-	// sockFile is the unix socket file
-	sockFile = runDir + "/daemon.sock"
-
 	// vpncScript is the vpnc-script
 	vpncScript = "/usr/bin/oc-daemon-vpncscript"
 
-	// vpnDevice is the vpn network device name
-	vpnDevice = "oc-daemon-tun0"
+	// defaultVPNDevice is the default vpn network device name
+	defaultVPNDevice = "oc-daemon-tun0"
+
+	// shutdownTimeout is the maximum time the daemon waits for its
+	// subsystems to shut down
+	shutdownTimeout = 30 * time.Second
 )
 
 var (
 	// Version is the daemon version, to be set at compile time
 	Version = "unknown"
+
+	// xmlProfile is the AnyConnect Profile
+	xmlProfile = configDir + "/profile.xml"
+
+	// sockFile is the unix socket file
+	sockFile = runDir + "/daemon.sock"
+
+	// grpcSockFile is the unix socket file of the optional gRPC API
+	grpcSockFile = runDir + "/daemon-grpc.sock"
+
+	// vpnDevice is the vpn network device name
+	vpnDevice = defaultVPNDevice
+
+	// noDBus disables the D-Bus API at runtime
+	noDBus = false
+
+	// noTrafPol disables traffic policing at runtime
+	noTrafPol = false
 )
 
+// printVersion prints the version and build information
+func printVersion() {
+	fmt.Println(Version)
+	fmt.Printf("Capabilities: %s\n", strings.Join(Capabilities(), " "))
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	fmt.Printf("Go version: %s\n", info.GoVersion)
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision", "vcs.time", "vcs.modified":
+			fmt.Printf("%s: %s\n", s.Key, s.Value)
+		}
+	}
+}
+
+// printDoctor runs the self-check and prints the problems found, it returns
+// the exit code: 0 without problems, 1 otherwise
+func printDoctor() int {
+	problems := Doctor()
+	if len(problems) == 0 {
+		fmt.Println("No problems found")
+		return 0
+	}
+	for _, p := range problems {
+		fmt.Printf("%s: %s\n", p.Check, p.Message)
+	}
+	return 1
+}
+
+// loadConfig loads the daemon config from file, returns the default config
+// if the file does not exist
+func loadConfig(file string) (*Config, error) {
+	config, err := LoadConfig(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		log.WithField("file", file).
+			Debug("Daemon config file not found, using defaults")
+		return NewConfig(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
 // prepareFolders prepares directories used by the daemon
 func prepareFolders() {
 	if err := os.MkdirAll(configDir, 0755); err != nil {
@@ -42,36 +114,176 @@ func prepareFolders() {
 	if err := os.MkdirAll(runDir, 0755); err != nil {
 		log.WithError(err).Fatal("Daemon could not create run dir")
 	}
+	if err := os.MkdirAll(filepath.Dir(sockFile), 0755); err != nil {
+		log.WithError(err).Fatal("Daemon could not create socket dir")
+	}
+}
+
+// setRuntimePaths sets the paths of runtime files, they are stored in the
+// directory of the socket file to separate them from other daemon instances
+func setRuntimePaths() {
+	dir := filepath.Dir(sockFile)
+	lockFile = filepath.Join(dir, "daemon.pid")
+	ocrunner.PIDFile = filepath.Join(dir, "openconnect.pid")
+	manifestFile = filepath.Join(dir, "manifest.json")
+	secretDir = dir
+	grpcSockFile = filepath.Join(dir, "daemon-grpc.sock")
+}
+
+// setDebugLogging enables debug logging of all components if debug is set,
+// otherwise it restores the logging settings of config
+func setDebugLogging(config *Config, debug bool) error {
+	if !debug {
+		return config.SetLogging()
+	}
+	c := config.Copy()
+	c.LogLevel = logrus.DebugLevel.String()
+	c.ComponentLogLevels = nil
+	return c.SetLogging()
 }
 
 // Run is the main entry point for the daemon
 func Run() {
 	// parse command line arguments
-	verbose := flag.Bool("verbose", false, "enable verbose output")
-	version := flag.Bool("version", false, "print version")
+	cfgFile := flag.String("config", configFile, "set config `file`")
+	logLevel := flag.String("loglevel", "", "set log `level` "+
+		"(panic, fatal, error, warn, info, debug, trace)")
+	logFormat := flag.String("logformat", "", "set log `format` "+
+		"(text, json, journald)")
+	verbose := flag.Bool("verbose", false, "enable verbose output, "+
+		"same as -loglevel debug")
+	version := flag.Bool("version", false, "print version and build "+
+		"information")
+	socket := flag.String("socket", sockFile, "set unix socket `file`, "+
+		"other runtime files are stored in the same directory")
+	device := flag.String("device", vpnDevice, "set vpn network `device` name")
+	profile := flag.String("profile", xmlProfile, "set xml profile `file`")
+	flag.BoolVar(&noTrafPol, "no-trafpol", noTrafPol, "disable traffic "+
+		"policing")
+	flag.BoolVar(&noDBus, "no-dbus", noDBus, "disable D-Bus API")
+	doctor := flag.Bool("doctor", false, "check runtime dependencies "+
+		"and print problems")
 	flag.Parse()
 
+	sockFile = *socket
+	vpnDevice = *device
+	xmlProfile = *profile
+	setRuntimePaths()
+
 	// print version?
 	if *version {
-		fmt.Println(Version)
+		printVersion()
 		os.Exit(0)
 	}
 
-	// set verbose log level
-	if *verbose {
-		log.SetLevel(log.DebugLevel)
+	// run self-check?
+	if *doctor {
+		os.Exit(printDoctor())
+	}
+
+	// getConfig loads the config and overrides settings with command
+	// line arguments
+	getConfig := func() (*Config, error) {
+		config, err := loadConfig(*cfgFile)
+		if err != nil {
+			return nil, err
+		}
+		if *logLevel != "" {
+			config.LogLevel = *logLevel
+		}
+		if *logFormat != "" {
+			config.LogFormat = *logFormat
+		}
+		if *verbose {
+			config.LogLevel = logrus.DebugLevel.String()
+		}
+		if !config.Valid() {
+			return nil, errors.New("invalid config")
+		}
+		if err := config.SetLogging(); err != nil {
+			return nil, err
+		}
+		log.WithField("config", config).Debug("Daemon using config")
+		return config, nil
+	}
+
+	// load config
+	config, err := getConfig()
+	if err != nil {
+		log.WithError(err).WithField("file", *cfgFile).
+			Fatal("Daemon could not load config")
+	}
+
+	// disable d-bus api if configured
+	if config.DBusMode == DBusModeDisabled {
+		noDBus = true
 	}
 
 	// prepare directories
 	prepareFolders()
 
+	// make sure no other daemon instance is running
+	lock, err := acquireRunLock(lockFile)
+	if err != nil {
+		log.WithError(err).Fatal("Daemon could not acquire run lock")
+	}
+	defer lock.release()
+	if !noDBus {
+		if err := checkDBusName(); err != nil {
+			lock.release()
+			log.WithError(err).Fatal("Daemon detected running daemon instance")
+		}
+	}
+
 	// start daemon
-	daemon := NewDaemon()
-	daemon.Start()
-
-	// catch interrupt and clean up
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
-	<-c
-	daemon.Stop()
+	daemon := NewDaemon(config)
+	if err := daemon.Start(context.Background()); err != nil {
+		lock.release()
+		log.WithError(err).Fatal("Daemon could not start")
+	}
+
+	// catch interrupt and terminate and clean up, reload config on
+	// hangup, dump debug information on SIGUSR1 and toggle debug logging
+	// on SIGUSR2
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP,
+		syscall.SIGUSR1, syscall.SIGUSR2)
+	debug := false
+	for s := range signals {
+		if s == os.Interrupt || s == syscall.SIGTERM {
+			log.WithField("signal", s).Info("Daemon got signal, shutting down")
+			break
+		}
+
+		switch s {
+		case syscall.SIGHUP:
+			log.Info("Daemon got SIGHUP, reloading config")
+			c, err := getConfig()
+			if err != nil {
+				log.WithError(err).WithField("file", *cfgFile).
+					Error("Daemon could not reload config, keeping current config")
+				continue
+			}
+			config = c
+			debug = false
+			daemon.Reload(config)
+
+		case syscall.SIGUSR1:
+			log.Info("Daemon got SIGUSR1, dumping debug information")
+			daemon.Dump()
+
+		case syscall.SIGUSR2:
+			debug = !debug
+			log.WithField("debug", debug).
+				Info("Daemon got SIGUSR2, toggling debug logging")
+			if err := setDebugLogging(config, debug); err != nil {
+				log.WithError(err).Error("Daemon could not toggle debug logging")
+			}
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := daemon.Stop(ctx); err != nil {
+		log.WithError(err).Error("Daemon could not shut down cleanly")
+	}
 }
@@ -0,0 +1,38 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestSetDebugLogging tests setDebugLogging
+func TestSetDebugLogging(t *testing.T) {
+	defer logrus.SetLevel(logrus.GetLevel())
+	defer logrus.SetFormatter(logrus.StandardLogger().Formatter)
+
+	c := NewConfig()
+	c.ComponentLogLevels = map[string]string{"daemon": "warn"}
+
+	// enable debug logging of all components
+	if err := setDebugLogging(c, true); err != nil {
+		t.Fatal(err)
+	}
+	if logrus.GetLevel() != logrus.DebugLevel ||
+		log.Logger.GetLevel() != logrus.DebugLevel {
+		t.Errorf("got %s and %s, want %s", logrus.GetLevel(),
+			log.Logger.GetLevel(), logrus.DebugLevel)
+	}
+	if c.LogLevel != logrus.InfoLevel.String() || c.ComponentLogLevels == nil {
+		t.Error("config should not be changed")
+	}
+
+	// restore logging of config
+	c.ComponentLogLevels = nil
+	if err := setDebugLogging(c, false); err != nil {
+		t.Fatal(err)
+	}
+	if logrus.GetLevel() != logrus.InfoLevel {
+		t.Errorf("got %s, want %s", logrus.GetLevel(), logrus.InfoLevel)
+	}
+}
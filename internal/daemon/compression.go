@@ -0,0 +1,43 @@
+package daemon
+
+import (
+	"github.com/godbus/dbus/v5"
+)
+
+// NetworkManager metered states, see NMMetered
+const (
+	nmMeteredYes      = 1
+	nmMeteredGuessYes = 3
+)
+
+// isMetered returns whether the primary network connection is metered as
+// reported by NetworkManager, it can be replaced for testing
+var isMetered = func() bool {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		log.WithError(err).Debug("Daemon could not connect to system bus")
+		return false
+	}
+	defer func() { _ = conn.Close() }()
+
+	var metered uint32
+	nm := conn.Object("org.freedesktop.NetworkManager",
+		"/org/freedesktop/NetworkManager")
+	if err := nm.StoreProperty("org.freedesktop.NetworkManager.Metered",
+		&metered); err != nil {
+		log.WithError(err).Debug("Daemon could not get metered state")
+		return false
+	}
+	return metered == nmMeteredYes || metered == nmMeteredGuessYes
+}
+
+// compressionMode returns the compression mode for a new VPN connection, the
+// mode for metered links is used if the network connection is metered
+func (d *Daemon) compressionMode() string {
+	if d.config.MeteredCompression != "" && isMetered() {
+		log.WithField("compression", d.config.MeteredCompression).
+			Info("Daemon using compression for metered network connection")
+		return d.config.MeteredCompression
+	}
+	return d.config.Compression
+}
@@ -0,0 +1,61 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// TestDaemonCompressionMode tests compressionMode of Daemon
+func TestDaemonCompressionMode(t *testing.T) {
+	oldIsMetered := isMetered
+	defer func() { isMetered = oldIsMetered }()
+
+	d := &Daemon{config: NewConfig()}
+	for _, test := range []struct {
+		compression string
+		metered     string
+		isMetered   bool
+		want        string
+	}{
+		{"", "", false, ""},
+		{"", "", true, ""},
+		{"none", "", true, "none"},
+		{"none", "all", false, "none"},
+		{"none", "all", true, "all"},
+		{"", "stateless", true, "stateless"},
+	} {
+		isMetered = func() bool { return test.isMetered }
+		d.config.Compression = test.compression
+		d.config.MeteredCompression = test.metered
+		if got := d.compressionMode(); got != test.want {
+			t.Errorf("got %s, want %s", got, test.want)
+		}
+	}
+}
+
+// TestDaemonSetStatusCompression tests setStatusCompression of Daemon
+func TestDaemonSetStatusCompression(t *testing.T) {
+	d := &Daemon{
+		dbus:   noDBusService{},
+		status: vpnstatus.New(),
+	}
+
+	// not running
+	d.setStatusCompression("")
+	if d.status.Compression != "" {
+		t.Errorf("got %s, want empty", d.status.Compression)
+	}
+
+	// running with default and explicit mode
+	d.status.OCRunning = vpnstatus.OCRunningRunning
+	d.setStatusCompression("")
+	if d.status.Compression != vpnstatus.CompressionDefault {
+		t.Errorf("got %s, want %s", d.status.Compression,
+			vpnstatus.CompressionDefault)
+	}
+	d.setStatusCompression("stateless")
+	if d.status.Compression != "stateless" {
+		t.Errorf("got %s, want stateless", d.status.Compression)
+	}
+}
@@ -0,0 +1,828 @@
+package daemon
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/audit"
+	"github.com/telekom-mms/oc-daemon/internal/ddns"
+	"github.com/telekom-mms/oc-daemon/internal/logging"
+	"github.com/telekom-mms/oc-daemon/internal/ocrunner"
+	"github.com/telekom-mms/oc-daemon/internal/posture"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
+)
+
+const (
+	// configFile is the default daemon configuration file
+	configFile = configDir + "/oc-daemon.json"
+)
+
+// defaultCPDServers is the default list of CPD servers, e.g., used by
+// browsers
+var defaultCPDServers = []string{
+	"connectivity-check.ubuntu.com", // ubuntu
+	"detectportal.firefox.com",      // firefox
+	"www.gstatic.com",               // chrome
+	"clients3.google.com",           // chromium
+	"nmcheck.gnome.org",             // gnome
+}
+
+// validCPDServers returns whether the CPD server host names are valid
+func validCPDServers(servers []string) bool {
+	for _, s := range servers {
+		if s == "" || strings.ContainsAny(s, " \t/:") {
+			return false
+		}
+	}
+	return true
+}
+
+// Log formats
+const (
+	LogFormatText     = logging.FormatText
+	LogFormatJSON     = logging.FormatJSON
+	LogFormatJournald = logging.FormatJournald
+)
+
+// validLanguageTag returns whether tag looks like a BCP 47 language tag,
+// e.g., "de" or "pt-BR"; empty tags are valid
+func validLanguageTag(tag string) bool {
+	if tag == "" {
+		return true
+	}
+	for _, sub := range strings.Split(tag, "-") {
+		if sub == "" || len(sub) > 8 {
+			return false
+		}
+		for _, r := range sub {
+			if !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') &&
+				!(r >= '0' && r <= '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Domain conflict policies
+const (
+	DomainConflictsWarn     = "warn"
+	DomainConflictsYield    = "yield"
+	DomainConflictsOverride = "override"
+)
+
+// validDomainConflicts returns whether policy is a valid domain conflict
+// policy
+func validDomainConflicts(policy string) bool {
+	switch policy {
+	case DomainConflictsWarn, DomainConflictsYield, DomainConflictsOverride:
+		return true
+	}
+	return false
+}
+
+// ReconnectPolicy is the policy for automatic reconnects after unexpected
+// disconnects of the VPN
+type ReconnectPolicy struct {
+	// Enabled specifies if automatic reconnects are enabled
+	Enabled bool
+
+	// MaxAttempts is the maximum number of reconnect attempts after an
+	// unexpected disconnect, 0 means unlimited
+	MaxAttempts int
+
+	// InitialDelay is the delay before the first reconnect attempt, it
+	// doubles with each attempt
+	InitialDelay time.Duration
+
+	// MaxDelay is the maximum delay between reconnect attempts
+	MaxDelay time.Duration
+
+	// Jitter is the fraction of the delay that is randomly added to or
+	// subtracted from the delay, e.g., 0.1 for +/- 10%
+	Jitter float64
+
+	// TransientOnly specifies if automatic reconnects are only tried
+	// after transient disconnects, i.e., not if the gateway ended the
+	// session with an admin reset or after the max session time
+	TransientOnly bool
+}
+
+// Valid returns if the reconnect policy is valid
+func (r *ReconnectPolicy) Valid() bool {
+	if r.MaxAttempts < 0 ||
+		r.InitialDelay <= 0 ||
+		r.MaxDelay < r.InitialDelay ||
+		r.Jitter < 0 || r.Jitter > 1 {
+		return false
+	}
+	return true
+}
+
+// DNSProxy is the configuration of the DNS-Proxy
+type DNSProxy struct {
+	// Address and Port are the listen address and port of the
+	// DNS-Proxy, changes require a restart of the daemon
+	Address string
+	Port    uint16
+
+	// AllowNonLoopback allows an Address that is not a loopback address
+	AllowNonLoopback bool
+
+	// Fallback is the address of the resolver including the port that is
+	// used for domain names that are not resolved with the VPN DNS
+	// servers, e.g., if the VPN is not connected or uses split DNS
+	Fallback string
+}
+
+// ListenAddress returns the listen address of the DNS-Proxy including the
+// port
+func (p *DNSProxy) ListenAddress() string {
+	return net.JoinHostPort(p.Address, strconv.Itoa(int(p.Port)))
+}
+
+// Valid returns if the DNS-Proxy configuration is valid
+func (p *DNSProxy) Valid() bool {
+	ip := net.ParseIP(p.Address)
+	if ip == nil || p.Port == 0 {
+		return false
+	}
+	if !ip.IsLoopback() && !p.AllowNonLoopback {
+		return false
+	}
+	host, port, err := net.SplitHostPort(p.Fallback)
+	if err != nil || net.ParseIP(host) == nil {
+		return false
+	}
+	if n, err := strconv.ParseUint(port, 10, 16); err != nil || n == 0 {
+		return false
+	}
+	return true
+}
+
+// DNSRegistration is the configuration of the dynamic DNS registration of the
+// VPN IP addresses under the host name of the machine after connect
+type DNSRegistration struct {
+	// Enabled specifies if the dynamic DNS registration is enabled
+	Enabled bool
+
+	// Zone is the zone of the host name, the default domain in the VPN
+	// configuration is used if empty
+	Zone string
+
+	// Server is the address of the DNS server for updates including the
+	// port, the first VPN DNS server is used if empty
+	Server string
+
+	// TTL is the time to live of the registered records
+	TTL time.Duration
+
+	// TSIGKeyName, TSIGAlgorithm and TSIGSecret are the TSIG key used
+	// for signing updates, updates are not signed if TSIGKeyName is empty
+	TSIGKeyName   string
+	TSIGAlgorithm string
+	TSIGSecret    string
+}
+
+// Valid returns if the dynamic DNS registration is valid
+func (r *DNSRegistration) Valid() bool {
+	if r.TTL < time.Second {
+		return false
+	}
+	if r.Server != "" {
+		if _, _, err := net.SplitHostPort(r.Server); err != nil {
+			return false
+		}
+	}
+	if r.TSIGKeyName == "" {
+		return true
+	}
+	if !ddns.ValidAlgorithm(r.TSIGAlgorithm) {
+		return false
+	}
+	if _, err := base64.StdEncoding.DecodeString(r.TSIGSecret); err != nil ||
+		r.TSIGSecret == "" {
+		return false
+	}
+	return true
+}
+
+// DeviceAuth is the configuration of the device authorization grant
+// (RFC 8628) for logins that are approved on another device, e.g., a phone;
+// the access token of the grant is used as the VPN cookie
+type DeviceAuth struct {
+	// AuthorizationURL is the URL of the device authorization endpoint
+	// of the IdP, empty disables the device authorization
+	AuthorizationURL string
+
+	// TokenURL is the URL of the token endpoint of the IdP
+	TokenURL string
+
+	// ClientID is the OAuth client identifier of the daemon and Scope
+	// the requested scope, empty for the default scope of the IdP
+	ClientID string
+	Scope    string
+}
+
+// Enabled returns if the device authorization is enabled
+func (a *DeviceAuth) Enabled() bool {
+	return a.AuthorizationURL != ""
+}
+
+// Valid returns if the device authorization is valid
+func (a *DeviceAuth) Valid() bool {
+	if !a.Enabled() {
+		return true
+	}
+	for _, u := range []string{a.AuthorizationURL, a.TokenURL} {
+		parsed, err := url.Parse(u)
+		if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+			return false
+		}
+	}
+	return a.ClientID != ""
+}
+
+// Posture is the configuration of the posture checks that run before VPN
+// connects
+type Posture struct {
+	// Checks are the built-in checks, "luks", "screenlock" or "kernel"
+	Checks []string
+
+	// MinKernelVersion is the minimum kernel version of the kernel check,
+	// e.g., "6.1"
+	MinKernelVersion string
+
+	// Script is the absolute path of a script that runs as additional
+	// check, the check passes if the script exits with status 0
+	Script string
+
+	// Enforce specifies if failed checks block VPN connects, failed
+	// checks are only logged and reported otherwise
+	Enforce bool
+}
+
+// Enabled returns if posture checks are enabled
+func (p *Posture) Enabled() bool {
+	return len(p.Checks) > 0 || p.Script != ""
+}
+
+// Valid returns if the posture checks are valid
+func (p *Posture) Valid() bool {
+	for _, check := range p.Checks {
+		if !posture.ValidCheck(check) {
+			return false
+		}
+		if check == posture.CheckKernel &&
+			!posture.ValidKernelVersion(p.MinKernelVersion) {
+			return false
+		}
+	}
+	if p.Script != "" && !filepath.IsAbs(p.Script) {
+		return false
+	}
+	return true
+}
+
+// Idle policy actions
+const (
+	IdleActionDisconnect = "disconnect"
+	IdleActionNotify     = "notify"
+)
+
+// IdlePolicy is the policy for idle VPN connections without traffic on the
+// VPN device
+type IdlePolicy struct {
+	// Timeout is the time without traffic after which the connection is
+	// idle, 0 disables the idle policy
+	Timeout time.Duration
+
+	// Action is the action on idle connections, "disconnect" disconnects
+	// the VPN, "notify" only emits the D-Bus signal "IdleTimeout", e.g.,
+	// for a desktop notification
+	Action string
+}
+
+// Enabled returns if the idle policy is enabled
+func (p *IdlePolicy) Enabled() bool {
+	return p.Timeout > 0
+}
+
+// Valid returns if the idle policy is valid
+func (p *IdlePolicy) Valid() bool {
+	if p.Timeout < 0 {
+		return false
+	}
+	switch p.Action {
+	case IdleActionDisconnect, IdleActionNotify:
+		return true
+	}
+	return false
+}
+
+// Session limit actions
+const (
+	SessionActionDisconnect = "disconnect"
+	SessionActionReconnect  = "reconnect"
+)
+
+// SessionLimit is the maximum duration of VPN sessions, e.g., required by
+// a policy that the VPN gateway does not enforce
+type SessionLimit struct {
+	// MaxDuration is the maximum duration of a VPN session, 0 disables
+	// the session limit
+	MaxDuration time.Duration
+
+	// Warnings are the times before the end of the session when the
+	// D-Bus signal "SessionExpiring" is emitted, e.g., 15 and 5 minutes
+	Warnings []time.Duration
+
+	// Action is the action at the end of the session, "disconnect"
+	// disconnects the VPN, "reconnect" reconnects it with the login
+	// information of the session
+	Action string
+}
+
+// Enabled returns if the session limit is enabled
+func (s *SessionLimit) Enabled() bool {
+	return s.MaxDuration > 0
+}
+
+// Valid returns if the session limit is valid
+func (s *SessionLimit) Valid() bool {
+	if s.MaxDuration < 0 {
+		return false
+	}
+	for _, w := range s.Warnings {
+		if w <= 0 || (s.Enabled() && w >= s.MaxDuration) {
+			return false
+		}
+	}
+	switch s.Action {
+	case SessionActionDisconnect, SessionActionReconnect:
+		return true
+	}
+	return false
+}
+
+// AddressFamily is the address family policy of VPN connections, e.g., for
+// networks with broken IPv6 transit
+type AddressFamily struct {
+	// Gateway is the address family of the connection to the VPN
+	// gateway: "ipv4" or "ipv6" forces the family, "prefer-ipv4" or
+	// "prefer-ipv6" prefers it if the gateway has an address in the
+	// family, empty uses any family like openconnect by default
+	Gateway string
+
+	// DisableTunnelIPv6 disables IPv6 in the tunnel: openconnect does not
+	// request IPv6 from the gateway, an IPv6 configuration sent anyway is
+	// ignored and IPv6 traffic is rejected on the VPN device
+	DisableTunnelIPv6 bool
+}
+
+// Valid returns if the address family policy is valid
+func (a *AddressFamily) Valid() bool {
+	return ocrunner.ValidFamily(a.Gateway)
+}
+
+// D-Bus API modes
+const (
+	DBusModeRequired = "required"
+	DBusModeOptional = "optional"
+	DBusModeDisabled = "disabled"
+)
+
+// PrivilegeSeparation is the configuration of the system user openconnect
+// runs as instead of root
+type PrivilegeSeparation struct {
+	// User is the name of the system user, empty runs openconnect as
+	// root
+	User string
+
+	// Group is the name of the group, the primary group of User if
+	// empty
+	Group string
+
+	// Capabilities are the capabilities openconnect keeps, e.g.,
+	// "CAP_NET_ADMIN"
+	Capabilities []string
+}
+
+// Enabled returns if privilege separation is enabled
+func (p *PrivilegeSeparation) Enabled() bool {
+	return p.User != ""
+}
+
+// Valid returns if the privilege separation is valid
+func (p *PrivilegeSeparation) Valid() bool {
+	if p.User == "" && p.Group != "" {
+		return false
+	}
+	for _, c := range p.Capabilities {
+		if !ocrunner.ValidCapability(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// Config is a daemon configuration
+type Config struct {
+	LogLevel  string
+	LogFormat string
+
+	// DBusMode specifies if the D-Bus API is "required", i.e., the daemon
+	// does not start without it, "optional", i.e., the daemon only
+	// uses the socket API if the system D-Bus is not available, e.g., in
+	// containers, or "disabled"; changes require a restart of the daemon
+	DBusMode string
+
+	// ComponentLogLevels overrides LogLevel for individual components,
+	// e.g., "dnsproxy": "debug"
+	ComponentLogLevels map[string]string
+
+	// AutoProxy specifies if a proxy detected on untrusted networks
+	// should be used for the VPN connection
+	AutoProxy bool
+
+	// Compression is the compression mode of openconnect, "none",
+	// "stateless" or "all", the default of openconnect if empty;
+	// MeteredCompression is the mode used instead on metered network
+	// connections as reported by NetworkManager, Compression if empty
+	Compression        string
+	MeteredCompression string
+
+	AddressFamily AddressFamily
+
+	// BannerLanguage is the BCP 47 language tag of the banners of the VPN
+	// gateway, e.g., "de", gateways do not send it; empty if unknown
+	BannerLanguage string
+
+	// CSDWrapper is the absolute path of the trusted CSD wrapper script
+	// that clients pass to openconnect for hostscan during authentication,
+	// empty disables hostscan
+	CSDWrapper string
+
+	// PrivilegeSeparation runs openconnect as a system user with only
+	// the needed capabilities; changes require a restart of the daemon
+	PrivilegeSeparation PrivilegeSeparation
+
+	// SocketGroup is the name of the group of the unix socket, its
+	// members may send VPN config updates besides root and the user of
+	// PrivilegeSeparation, empty allows only them; changes require a
+	// restart of the daemon
+	SocketGroup string
+
+	// GRPCAPI enables the gRPC API on its own unix socket besides the
+	// socket API, e.g., for integrations not written in Go; changes
+	// require a restart of the daemon
+	GRPCAPI bool
+
+	// CPDServers are the host names of captive portal detection servers
+	// that are allowed by traffic policing, CPD servers in the XML
+	// profile are added to them
+	CPDServers []string
+
+	ReconnectPolicy ReconnectPolicy
+
+	// ReconnectOnResume specifies if the VPN should be reconnected
+	// instead of disconnected after resume from suspend
+	ReconnectOnResume bool
+
+	// LockOnDrop specifies if all sessions should be locked with logind
+	// when the VPN connection drops unexpectedly
+	LockOnDrop bool
+
+	// RestrictDisconnect specifies if only the user that initiated the
+	// current connection, root and users authorized by polkit can
+	// disconnect it
+	RestrictDisconnect bool
+
+	// ResolvConfGuard specifies if resolv.conf is watched while connected
+	// and restored together with the DNS settings of the VPN connection
+	// if another tool, e.g., a dhclient hook, overwrites it
+	ResolvConfGuard bool
+
+	// StatsInterval is the interval for updating the traffic statistics
+	// of the VPN device, 0 disables traffic statistics
+	StatsInterval time.Duration
+
+	// SlowRequestDuration is the duration after which the daemon logs API
+	// requests it handles as slow requests with their type and sanitized
+	// parameters, 0 disables logging slow requests
+	SlowRequestDuration time.Duration
+
+	IdlePolicy IdlePolicy
+
+	SessionLimit SessionLimit
+
+	Notifications NotificationPolicy
+
+	// AuditLog is the target of the connection audit log, either
+	// "journald" or an absolute file path, empty disables the audit log
+	AuditLog string
+
+	// DNSTransports maps VPN DNS server IP addresses to the transport
+	// used for DNS queries, "udp", "tcp" or "tls"; transports in the VPN
+	// configuration take precedence
+	DNSTransports map[string]string
+
+	// DomainConflicts is the policy for split DNS routing domains of the
+	// VPN that other links in systemd-resolved also use, e.g., another
+	// VPN: "warn" only logs the conflict, "yield" removes the domains
+	// from the VPN device and "override" removes them from the other link
+	DomainConflicts string
+
+	DNSProxy DNSProxy
+
+	DNSRegistration DNSRegistration
+
+	Schedule Schedule
+
+	DeviceAuth DeviceAuth
+
+	Posture Posture
+
+	// Tunnels are the names of additional VPN tunnels that can be
+	// connected besides the main VPN connection, e.g., "lab"; changes
+	// require a restart of the daemon
+	Tunnels []string
+}
+
+// Copy returns a copy of Config
+func (c *Config) Copy() *Config {
+	if c == nil {
+		return nil
+	}
+	cp := *c
+	if c.ComponentLogLevels != nil {
+		cp.ComponentLogLevels = make(map[string]string)
+		for k, v := range c.ComponentLogLevels {
+			cp.ComponentLogLevels[k] = v
+		}
+	}
+	if c.DNSTransports != nil {
+		cp.DNSTransports = make(map[string]string)
+		for k, v := range c.DNSTransports {
+			cp.DNSTransports[k] = v
+		}
+	}
+	if c.PrivilegeSeparation.Capabilities != nil {
+		cp.PrivilegeSeparation.Capabilities = append([]string{},
+			c.PrivilegeSeparation.Capabilities...)
+	}
+	if c.CPDServers != nil {
+		cp.CPDServers = append([]string{}, c.CPDServers...)
+	}
+	cp.Schedule = c.Schedule.Copy()
+	cp.Notifications = c.Notifications.Copy()
+	if c.Posture.Checks != nil {
+		cp.Posture.Checks = append([]string{}, c.Posture.Checks...)
+	}
+	if c.SessionLimit.Warnings != nil {
+		cp.SessionLimit.Warnings = append([]time.Duration{},
+			c.SessionLimit.Warnings...)
+	}
+	if c.Tunnels != nil {
+		cp.Tunnels = append([]string{}, c.Tunnels...)
+	}
+	return &cp
+}
+
+// Valid returns if the config is valid
+func (c *Config) Valid() bool {
+	return c != nil && len(c.invalidFields()) == 0
+}
+
+// invalidFields returns the names of the invalid settings in the config
+func (c *Config) invalidFields() []string {
+	invalid := []string{}
+
+	// check log level
+	if _, err := logrus.ParseLevel(c.LogLevel); err != nil {
+		invalid = append(invalid, "LogLevel")
+	}
+
+	// check log format
+	switch c.LogFormat {
+	case LogFormatText, LogFormatJSON, LogFormatJournald:
+	default:
+		invalid = append(invalid, "LogFormat")
+	}
+
+	// check d-bus mode
+	switch c.DBusMode {
+	case DBusModeRequired, DBusModeOptional, DBusModeDisabled:
+	default:
+		invalid = append(invalid, "DBusMode")
+	}
+
+	// check component log levels
+	for component, level := range c.ComponentLogLevels {
+		if _, err := logrus.ParseLevel(level); err != nil ||
+			!logging.IsComponent(component) {
+			invalid = append(invalid, "ComponentLogLevels")
+			break
+		}
+	}
+
+	// check compression
+	if !ocrunner.ValidCompression(c.Compression) {
+		invalid = append(invalid, "Compression")
+	}
+	if !ocrunner.ValidCompression(c.MeteredCompression) {
+		invalid = append(invalid, "MeteredCompression")
+	}
+
+	// check address family policy
+	if !c.AddressFamily.Valid() {
+		invalid = append(invalid, "AddressFamily")
+	}
+
+	// check banner language
+	if !validLanguageTag(c.BannerLanguage) {
+		invalid = append(invalid, "BannerLanguage")
+	}
+
+	// check csd wrapper
+	if c.CSDWrapper != "" && !filepath.IsAbs(c.CSDWrapper) {
+		invalid = append(invalid, "CSDWrapper")
+	}
+
+	// check privilege separation
+	if !c.PrivilegeSeparation.Valid() {
+		invalid = append(invalid, "PrivilegeSeparation")
+	}
+
+	// check cpd servers
+	if !validCPDServers(c.CPDServers) {
+		invalid = append(invalid, "CPDServers")
+	}
+
+	// check reconnect policy
+	if !c.ReconnectPolicy.Valid() {
+		invalid = append(invalid, "ReconnectPolicy")
+	}
+
+	// check stats interval
+	if c.StatsInterval < 0 {
+		invalid = append(invalid, "StatsInterval")
+	}
+
+	// check slow request duration
+	if c.SlowRequestDuration < 0 {
+		invalid = append(invalid, "SlowRequestDuration")
+	}
+
+	// check audit log
+	if c.AuditLog != "" &&
+		c.AuditLog != audit.TargetJournald &&
+		!filepath.IsAbs(c.AuditLog) {
+		invalid = append(invalid, "AuditLog")
+	}
+
+	// check dns transports
+	for server, transport := range c.DNSTransports {
+		if net.ParseIP(server) == nil ||
+			!vpnconfig.ValidDNSTransport(transport) {
+			invalid = append(invalid, "DNSTransports")
+			break
+		}
+	}
+
+	// check domain conflict policy
+	if !validDomainConflicts(c.DomainConflicts) {
+		invalid = append(invalid, "DomainConflicts")
+	}
+
+	// check dns-proxy
+	if !c.DNSProxy.Valid() {
+		invalid = append(invalid, "DNSProxy")
+	}
+
+	// check dns registration
+	if !c.DNSRegistration.Valid() {
+		invalid = append(invalid, "DNSRegistration")
+	}
+
+	// check device authorization
+	if !c.DeviceAuth.Valid() {
+		invalid = append(invalid, "DeviceAuth")
+	}
+
+	// check posture checks
+	if !c.Posture.Valid() {
+		invalid = append(invalid, "Posture")
+	}
+
+	// check idle policy
+	if !c.IdlePolicy.Valid() {
+		invalid = append(invalid, "IdlePolicy")
+	}
+
+	// check session limit
+	if !c.SessionLimit.Valid() {
+		invalid = append(invalid, "SessionLimit")
+	}
+
+	// check schedule
+	if !c.Schedule.Valid() {
+		invalid = append(invalid, "Schedule")
+	}
+
+	// check notification policy
+	if !c.Notifications.Valid() {
+		invalid = append(invalid, "Notifications")
+	}
+
+	// check additional tunnels
+	if !validTunnels(c.Tunnels) {
+		invalid = append(invalid, "Tunnels")
+	}
+
+	return invalid
+}
+
+// SetLogging applies the logging settings in config
+func (c *Config) SetLogging() error {
+	level, err := logrus.ParseLevel(c.LogLevel)
+	if err != nil {
+		return err
+	}
+
+	levels := make(map[string]logrus.Level)
+	for component, l := range c.ComponentLogLevels {
+		cl, err := logrus.ParseLevel(l)
+		if err != nil {
+			return fmt.Errorf("invalid log level of %s: %w", component, err)
+		}
+		levels[component] = cl
+	}
+
+	return logging.Configure(c.LogFormat, level, levels)
+}
+
+// NewConfig returns a new Config with default values
+func NewConfig() *Config {
+	return &Config{
+		LogLevel:  logrus.InfoLevel.String(),
+		LogFormat: LogFormatText,
+		DBusMode:  DBusModeRequired,
+		PrivilegeSeparation: PrivilegeSeparation{
+			Capabilities: append([]string{}, ocrunner.DefaultCapabilities...),
+		},
+		CPDServers: append([]string{}, defaultCPDServers...),
+		ReconnectPolicy: ReconnectPolicy{
+			MaxAttempts:  5,
+			InitialDelay: 5 * time.Second,
+			MaxDelay:     5 * time.Minute,
+			Jitter:       0.1,
+		},
+		ResolvConfGuard:     true,
+		StatsInterval:       10 * time.Second,
+		SlowRequestDuration: time.Second,
+		IdlePolicy: IdlePolicy{
+			Action: IdleActionDisconnect,
+		},
+		SessionLimit: SessionLimit{
+			Action: SessionActionDisconnect,
+		},
+		Notifications: NotificationPolicy{
+			MinSeverity: NotificationSeverityInfo,
+		},
+		DomainConflicts: DomainConflictsWarn,
+		DNSProxy: DNSProxy{
+			Address:  "127.0.0.1",
+			Port:     4253,
+			Fallback: "127.0.0.53:53",
+		},
+		DNSRegistration: DNSRegistration{
+			TTL:           5 * time.Minute,
+			TSIGAlgorithm: ddns.AlgorithmHMACSHA256,
+		},
+	}
+}
+
+// LoadConfig loads a Config from file, values not set in file are set to
+// their default values
+func LoadConfig(file string) (*Config, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	conf := NewConfig()
+	if err := json.Unmarshal(b, conf); err != nil {
+		return nil, err
+	}
+
+	return conf, nil
+}
@@ -0,0 +1,524 @@
+package daemon
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestConfigCopy tests Copy of Config
+func TestConfigCopy(t *testing.T) {
+	want := NewConfig()
+	got := want.Copy()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// test with component log levels
+	want.ComponentLogLevels = map[string]string{"dnsproxy": "debug"}
+	got = want.Copy()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	got.ComponentLogLevels["dnsproxy"] = "trace"
+	if want.ComponentLogLevels["dnsproxy"] != "debug" {
+		t.Errorf("copy should not modify original")
+	}
+
+	// test with dns transports
+	want.DNSTransports = map[string]string{"192.168.1.1": "tcp"}
+	got = want.Copy()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	got.DNSTransports["192.168.1.1"] = "tls"
+	if want.DNSTransports["192.168.1.1"] != "tcp" {
+		t.Errorf("copy should not modify original")
+	}
+
+	// test with tunnels
+	want.Tunnels = []string{"lab"}
+	got = want.Copy()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	got.Tunnels[0] = "other"
+	if want.Tunnels[0] != "lab" {
+		t.Errorf("copy should not modify original")
+	}
+
+	// test cpd servers
+	got = want.Copy()
+	got.CPDServers[0] = "other"
+	if want.CPDServers[0] != defaultCPDServers[0] {
+		t.Errorf("copy should not modify original")
+	}
+
+	// test privilege separation capabilities
+	got = want.Copy()
+	got.PrivilegeSeparation.Capabilities[0] = "CAP_NET_RAW"
+	if want.PrivilegeSeparation.Capabilities[0] != "CAP_NET_ADMIN" {
+		t.Errorf("copy should not modify original")
+	}
+
+	// test posture checks
+	want.Posture.Checks = []string{"luks"}
+	got = want.Copy()
+	got.Posture.Checks[0] = "kernel"
+	if want.Posture.Checks[0] != "luks" {
+		t.Errorf("copy should not modify original")
+	}
+
+	// test session limit warnings
+	want.SessionLimit.Warnings = []time.Duration{time.Minute}
+	got = want.Copy()
+	got.SessionLimit.Warnings[0] = time.Hour
+	if want.SessionLimit.Warnings[0] != time.Minute {
+		t.Errorf("copy should not modify original")
+	}
+
+	// test nil
+	var c *Config
+	if c.Copy() != nil {
+		t.Errorf("got %v, want nil", c.Copy())
+	}
+}
+
+// TestConfigValid tests Valid of Config
+func TestConfigValid(t *testing.T) {
+	// test invalid
+	for _, invalid := range []*Config{
+		nil,
+		{},
+		{LogLevel: "invalid", LogFormat: LogFormatText},
+		{LogLevel: "info", LogFormat: "invalid"},
+		{LogLevel: "info", LogFormat: LogFormatText},
+	} {
+		if invalid.Valid() {
+			t.Errorf("config should be invalid: %v", invalid)
+		}
+	}
+
+	// test invalid reconnect policy
+	c := NewConfig()
+	c.ReconnectPolicy.MaxDelay = c.ReconnectPolicy.InitialDelay - 1
+	if c.Valid() {
+		t.Errorf("config should be invalid: %v", c)
+	}
+
+	// test invalid component log levels
+	for _, levels := range []map[string]string{
+		{"invalid": "debug"},
+		{"dnsproxy": "invalid"},
+	} {
+		c = NewConfig()
+		c.ComponentLogLevels = levels
+		if c.Valid() {
+			t.Errorf("config should be invalid: %v", c)
+		}
+	}
+
+	// test invalid slow request duration
+	c = NewConfig()
+	c.SlowRequestDuration = -1
+	if c.Valid() {
+		t.Errorf("config should be invalid: %v", c)
+	}
+
+	// test invalid audit log
+	c = NewConfig()
+	c.AuditLog = "relative/audit.log"
+	if c.Valid() {
+		t.Errorf("config should be invalid: %v", c)
+	}
+
+	// test invalid banner language
+	for _, tag := range []string{"-", "de_DE", "de-", "toolongsubtag"} {
+		c = NewConfig()
+		c.BannerLanguage = tag
+		if c.Valid() {
+			t.Errorf("config should be invalid: %v", c)
+		}
+	}
+
+	// test invalid domain conflict policy
+	c = NewConfig()
+	c.DomainConflicts = "invalid"
+	if c.Valid() {
+		t.Errorf("config should be invalid: %v", c)
+	}
+
+	// test invalid dns transports
+	for _, transports := range []map[string]string{
+		{"invalid": "tcp"},
+		{"192.168.1.1": "invalid"},
+	} {
+		c = NewConfig()
+		c.DNSTransports = transports
+		if c.Valid() {
+			t.Errorf("config should be invalid: %v", c)
+		}
+	}
+
+	// test invalid dns registration
+	for _, registration := range []DNSRegistration{
+		{TTL: 0},
+		{TTL: time.Minute, Server: "192.168.1.1"},
+		{TTL: time.Minute, TSIGKeyName: "key", TSIGAlgorithm: "invalid",
+			TSIGSecret: "c2VjcmV0"},
+		{TTL: time.Minute, TSIGKeyName: "key", TSIGAlgorithm: "hmac-sha256",
+			TSIGSecret: "invalid!"},
+		{TTL: time.Minute, TSIGKeyName: "key", TSIGAlgorithm: "hmac-sha256"},
+	} {
+		c = NewConfig()
+		c.DNSRegistration = registration
+		if c.Valid() {
+			t.Errorf("config should be invalid: %v", c)
+		}
+	}
+
+	// test invalid device authorization
+	for _, auth := range []DeviceAuth{
+		{AuthorizationURL: "https://idp.example.com/device"},
+		{
+			AuthorizationURL: "http://idp.example.com/device",
+			TokenURL:         "https://idp.example.com/token",
+			ClientID:         "oc-daemon",
+		},
+		{
+			AuthorizationURL: "https://idp.example.com/device",
+			TokenURL:         "https://idp.example.com/token",
+		},
+	} {
+		c = NewConfig()
+		c.DeviceAuth = auth
+		if c.Valid() {
+			t.Errorf("config should be invalid: %v", c)
+		}
+	}
+
+	// test invalid schedule
+	c = NewConfig()
+	c.Schedule.Windows = []ScheduleWindow{{Start: "08:00"}}
+	if c.Valid() {
+		t.Errorf("config should be invalid: %v", c)
+	}
+
+	// test invalid compression
+	c = NewConfig()
+	c.Compression = "invalid"
+	if c.Valid() {
+		t.Errorf("config should be invalid: %v", c)
+	}
+	c = NewConfig()
+	c.MeteredCompression = "invalid"
+	if c.Valid() {
+		t.Errorf("config should be invalid: %v", c)
+	}
+
+	// test invalid address family
+	c = NewConfig()
+	c.AddressFamily.Gateway = "invalid"
+	if c.Valid() {
+		t.Errorf("config should be invalid: %v", c)
+	}
+
+	// test invalid dns-proxy
+	for _, proxy := range []DNSProxy{
+		{Address: "", Port: 4253, Fallback: "127.0.0.53:53"},
+		{Address: "127.0.0.1", Port: 0, Fallback: "127.0.0.53:53"},
+		{Address: "192.168.1.1", Port: 4253, Fallback: "127.0.0.53:53"},
+		{Address: "127.0.0.1", Port: 4253, Fallback: ""},
+		{Address: "127.0.0.1", Port: 4253, Fallback: "127.0.0.53"},
+		{Address: "127.0.0.1", Port: 4253, Fallback: "resolver:53"},
+		{Address: "127.0.0.1", Port: 4253, Fallback: "127.0.0.53:0"},
+	} {
+		c = NewConfig()
+		c.DNSProxy = proxy
+		if c.Valid() {
+			t.Errorf("config should be invalid: %v", c)
+		}
+	}
+
+	// test invalid cpd servers
+	for _, servers := range [][]string{
+		{""},
+		{"http://cpd.example.com"},
+		{"cpd.example.com/path"},
+		{"cpd example"},
+	} {
+		c = NewConfig()
+		c.CPDServers = servers
+		if c.Valid() {
+			t.Errorf("config should be invalid: %v", c)
+		}
+	}
+
+	// test invalid csd wrapper
+	c = NewConfig()
+	c.CSDWrapper = "relative/csd-wrapper.sh"
+	if c.Valid() {
+		t.Errorf("config should be invalid: %v", c)
+	}
+
+	// test invalid d-bus mode
+	for _, mode := range []string{"", "invalid"} {
+		c = NewConfig()
+		c.DBusMode = mode
+		if c.Valid() {
+			t.Errorf("config should be invalid: %v", c)
+		}
+	}
+
+	// test invalid privilege separation
+	for _, p := range []PrivilegeSeparation{
+		{Group: "oc-daemon"},
+		{User: "oc-daemon", Capabilities: []string{"CAP_SYS_ADMIN"}},
+	} {
+		c = NewConfig()
+		c.PrivilegeSeparation = p
+		if c.Valid() {
+			t.Errorf("config should be invalid: %v", c)
+		}
+	}
+
+	// test invalid posture checks
+	for _, p := range []Posture{
+		{Checks: []string{"invalid"}},
+		{Checks: []string{"kernel"}},
+		{Checks: []string{"kernel"}, MinKernelVersion: "invalid"},
+		{Script: "relative/check.sh"},
+	} {
+		c = NewConfig()
+		c.Posture = p
+		if c.Valid() {
+			t.Errorf("config should be invalid: %v", c)
+		}
+	}
+
+	// test invalid idle policy
+	for _, p := range []IdlePolicy{
+		{Timeout: -1, Action: IdleActionDisconnect},
+		{Timeout: time.Hour, Action: "invalid"},
+		{Timeout: time.Hour},
+	} {
+		c = NewConfig()
+		c.IdlePolicy = p
+		if c.Valid() {
+			t.Errorf("config should be invalid: %v", c)
+		}
+	}
+
+	// test invalid session limit
+	for _, s := range []SessionLimit{
+		{MaxDuration: -1, Action: SessionActionDisconnect},
+		{MaxDuration: time.Hour, Action: "invalid"},
+		{MaxDuration: time.Hour, Warnings: []time.Duration{0},
+			Action: SessionActionDisconnect},
+		{MaxDuration: time.Hour, Warnings: []time.Duration{time.Hour},
+			Action: SessionActionReconnect},
+	} {
+		c = NewConfig()
+		c.SessionLimit = s
+		if c.Valid() {
+			t.Errorf("config should be invalid: %v", c)
+		}
+	}
+
+	// test invalid tunnels
+	for _, tunnels := range [][]string{
+		{""},
+		{"invalid-name"},
+		{"lab", "lab"},
+		{"t1", "t2", "t3", "t4", "t5", "t6", "t7", "t8", "t9", "t10"},
+	} {
+		c = NewConfig()
+		c.Tunnels = tunnels
+		if c.Valid() {
+			t.Errorf("config should be invalid: %v", c)
+		}
+	}
+
+	// test valid
+	json := NewConfig()
+	json.LogFormat = LogFormatJSON
+	json.DBusMode = DBusModeOptional
+	journald := NewConfig()
+	journald.AuditLog = "journald"
+	file := NewConfig()
+	file.AuditLog = "/var/log/oc-daemon/audit.log"
+	components := NewConfig()
+	components.ComponentLogLevels = map[string]string{
+		"dnsproxy": "debug",
+		"splitrt":  "trace",
+	}
+	transports := NewConfig()
+	transports.DNSTransports = map[string]string{
+		"192.168.1.1": "tcp",
+		"2001:db8::1": "tls",
+	}
+	registration := NewConfig()
+	registration.DNSRegistration = DNSRegistration{
+		Enabled:       true,
+		Zone:          "example.com",
+		Server:        "192.168.1.1:53",
+		TTL:           time.Minute,
+		TSIGKeyName:   "key",
+		TSIGAlgorithm: "hmac-sha512",
+		TSIGSecret:    "c2VjcmV0",
+	}
+	cpd := NewConfig()
+	cpd.CPDServers = []string{"cpd.example.com"}
+	cpd.Compression = "none"
+	cpd.MeteredCompression = "all"
+	cpd.AddressFamily = AddressFamily{
+		Gateway:           "prefer-ipv4",
+		DisableTunnelIPv6: true,
+	}
+	cpd.CSDWrapper = "/usr/libexec/oc-daemon/csd-wrapper.sh"
+	cpd.PrivilegeSeparation = PrivilegeSeparation{
+		User:         "oc-daemon",
+		Group:        "oc-daemon",
+		Capabilities: []string{"CAP_NET_ADMIN", "CAP_NET_RAW"},
+	}
+	proxy := NewConfig()
+	proxy.DNSProxy = DNSProxy{
+		Address:          "192.168.1.1",
+		Port:             53,
+		AllowNonLoopback: true,
+		Fallback:         "[::1]:5353",
+	}
+	tunnels := NewConfig()
+	tunnels.Tunnels = []string{"lab", "test_2"}
+	tunnels.DeviceAuth = DeviceAuth{
+		AuthorizationURL: "https://idp.example.com/device",
+		TokenURL:         "https://idp.example.com/token",
+		ClientID:         "oc-daemon",
+	}
+	posture := NewConfig()
+	posture.Posture = Posture{
+		Checks:           []string{"luks", "screenlock", "kernel"},
+		MinKernelVersion: "6.1",
+		Script:           "/usr/local/bin/posture-check",
+		Enforce:          true,
+	}
+	posture.IdlePolicy = IdlePolicy{
+		Timeout: 30 * time.Minute,
+		Action:  IdleActionNotify,
+	}
+	posture.SessionLimit = SessionLimit{
+		MaxDuration: 10 * time.Hour,
+		Warnings:    []time.Duration{15 * time.Minute, 5 * time.Minute},
+		Action:      SessionActionReconnect,
+	}
+	for _, valid := range []*Config{
+		NewConfig(),
+		cpd,
+		posture,
+		proxy,
+		tunnels,
+		transports,
+		registration,
+		json,
+		components,
+		journald,
+		file,
+	} {
+		if !valid.Valid() {
+			t.Errorf("config should be valid: %v", valid)
+		}
+	}
+}
+
+// TestDNSProxyListenAddress tests ListenAddress of DNSProxy
+func TestDNSProxyListenAddress(t *testing.T) {
+	for _, test := range []struct {
+		proxy *DNSProxy
+		want  string
+	}{
+		{&NewConfig().DNSProxy, "127.0.0.1:4253"},
+		{&DNSProxy{Address: "::1", Port: 53}, "[::1]:53"},
+	} {
+		if got := test.proxy.ListenAddress(); got != test.want {
+			t.Errorf("got %s, want %s", got, test.want)
+		}
+	}
+}
+
+// TestConfigSetLogging tests SetLogging of Config
+func TestConfigSetLogging(t *testing.T) {
+	defer logrus.SetLevel(logrus.GetLevel())
+	defer logrus.SetFormatter(logrus.StandardLogger().Formatter)
+
+	// test invalid
+	c := &Config{LogLevel: "debug", LogFormat: "invalid"}
+	if err := c.SetLogging(); err == nil {
+		t.Errorf("invalid log format should return error")
+	}
+
+	// test valid
+	c = &Config{LogLevel: "trace", LogFormat: LogFormatJSON}
+	if err := c.SetLogging(); err != nil {
+		t.Error(err)
+	}
+	if logrus.GetLevel() != logrus.TraceLevel {
+		t.Errorf("got %s, want %s", logrus.GetLevel(), logrus.TraceLevel)
+	}
+
+	// test component log levels
+	c = &Config{
+		LogLevel:           "info",
+		LogFormat:          LogFormatText,
+		ComponentLogLevels: map[string]string{"daemon": "debug"},
+	}
+	if err := c.SetLogging(); err != nil {
+		t.Error(err)
+	}
+	if log.Logger.GetLevel() != logrus.DebugLevel {
+		t.Errorf("got %s, want %s", log.Logger.GetLevel(), logrus.DebugLevel)
+	}
+
+	// test invalid component log level
+	c.ComponentLogLevels["daemon"] = "invalid"
+	if err := c.SetLogging(); err == nil {
+		t.Errorf("invalid component log level should return error")
+	}
+
+	// reset component log levels
+	c.ComponentLogLevels = nil
+	if err := c.SetLogging(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestLoadConfig tests LoadConfig
+func TestLoadConfig(t *testing.T) {
+	// test not existing file
+	if _, err := LoadConfig("does not exist"); err == nil {
+		t.Errorf("not existing file should return error")
+	}
+
+	// create temporary file
+	f, err := os.CreateTemp("", "oc-daemon-config")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+
+	// test partial config, missing values should be defaults
+	if _, err := f.Write([]byte(`{"LogLevel": "debug"}`)); err != nil {
+		log.Fatal(err)
+	}
+	want := NewConfig()
+	want.LogLevel = "debug"
+	got, err := LoadConfig(f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
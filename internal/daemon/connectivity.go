@@ -0,0 +1,191 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/telekom-mms/oc-daemon/internal/devmon"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// registerRouteUpdates registers for route update events, it can be
+// replaced for testing
+var registerRouteUpdates = func(done <-chan struct{}) (chan netlink.RouteUpdate, error) {
+	updates := make(chan netlink.RouteUpdate)
+	if err := netlink.RouteSubscribeWithOptions(updates, done,
+		netlink.RouteSubscribeOptions{}); err != nil {
+		return nil, err
+	}
+	return updates, nil
+}
+
+// defaultRouteLinks returns the indexes of the devices with a default route
+// in the main routing table, it can be replaced for testing
+var defaultRouteLinks = func() (map[int]bool, error) {
+	routes, err := netlink.RouteListFiltered(netlink.FAMILY_ALL,
+		&netlink.Route{Table: unix.RT_TABLE_MAIN}, netlink.RT_FILTER_TABLE)
+	if err != nil {
+		return nil, err
+	}
+	links := make(map[int]bool)
+	for _, r := range routes {
+		if r.Dst != nil {
+			if ones, _ := r.Dst.Mask.Size(); ones != 0 {
+				continue
+			}
+		}
+		links[r.LinkIndex] = true
+		for _, nh := range r.MultiPath {
+			links[nh.LinkIndex] = true
+		}
+	}
+	return links, nil
+}
+
+// connectivity monitors the network connectivity of the host, the host is
+// online if a device with carrier has a default route in the main routing
+// table; tunnel devices, e.g., of the VPN, are ignored
+type connectivity struct {
+	devmon  *devmon.DevMon
+	devices map[int]*devmon.Update
+	results chan bool
+
+	// online is the last reported connectivity, reported specifies if
+	// it has been reported yet
+	online   bool
+	reported bool
+
+	done   <-chan struct{}
+	cancel context.CancelFunc
+	closed chan struct{}
+}
+
+// isOnline returns whether the host is online
+func (c *connectivity) isOnline() bool {
+	links, err := defaultRouteLinks()
+	if err != nil {
+		log.WithError(err).Error("Daemon could not get default routes")
+		return true
+	}
+	for index := range links {
+		d := c.devices[index]
+		if d == nil || !d.Up {
+			continue
+		}
+		switch d.Type {
+		case "loopback", "tuntap":
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// check checks the connectivity and reports changes
+func (c *connectivity) check() {
+	online := c.isOnline()
+	if c.reported && online == c.online {
+		return
+	}
+	c.online = online
+	c.reported = true
+	select {
+	case c.results <- online:
+	case <-c.done:
+	}
+}
+
+// handleDeviceUpdate handles a device update of the device monitor
+func (c *connectivity) handleDeviceUpdate(u *devmon.Update) {
+	if u.Add {
+		c.devices[u.Index] = u
+	} else {
+		delete(c.devices, u.Index)
+	}
+	c.check()
+}
+
+// run runs the main loop of the connectivity monitor
+func (c *connectivity) run(routes chan netlink.RouteUpdate) {
+	defer close(c.closed)
+	defer c.devmon.Stop()
+
+	c.check()
+	devices := c.devmon.Updates()
+	for {
+		select {
+		case u, ok := <-devices:
+			if !ok {
+				// device monitor stopped, e.g., on shutdown
+				devices = nil
+				break
+			}
+			c.handleDeviceUpdate(u)
+		case _, ok := <-routes:
+			if !ok {
+				log.Debug("Daemon route updates closed")
+				routes = nil
+				break
+			}
+			c.check()
+		case <-c.done:
+			// drain route updates until the channel is closed
+			if routes != nil {
+				go func() {
+					for range routes {
+						// wait for channel shutdown
+					}
+				}()
+			}
+			return
+		}
+	}
+}
+
+// start starts the connectivity monitor
+func (c *connectivity) start(ctx context.Context) error {
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.done = ctx.Done()
+
+	routes, err := registerRouteUpdates(c.done)
+	if err != nil {
+		c.cancel()
+		return fmt.Errorf("could not register route updates: %w", err)
+	}
+	if err := c.devmon.Start(ctx); err != nil {
+		c.cancel()
+		return err
+	}
+	go c.run(routes)
+	return nil
+}
+
+// stop stops the connectivity monitor
+func (c *connectivity) stop() {
+	if c.cancel == nil {
+		return
+	}
+	c.cancel()
+	<-c.closed
+	c.cancel = nil
+}
+
+// resultsC returns the channel for connectivity changes, true means online;
+// it returns nil if the monitor is not running
+func (c *connectivity) resultsC() <-chan bool {
+	if c == nil || c.cancel == nil {
+		return nil
+	}
+	return c.results
+}
+
+// newConnectivity returns a new connectivity monitor
+func newConnectivity() *connectivity {
+	return &connectivity{
+		devmon:  devmon.NewDevMon(),
+		devices: make(map[int]*devmon.Update),
+		results: make(chan bool),
+		closed:  make(chan struct{}),
+	}
+}
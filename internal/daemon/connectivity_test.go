@@ -0,0 +1,110 @@
+package daemon
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/internal/devmon"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// TestConnectivityIsOnline tests isOnline of connectivity
+func TestConnectivityIsOnline(t *testing.T) {
+	oldLinks := defaultRouteLinks
+	defer func() { defaultRouteLinks = oldLinks }()
+	defaultRouteLinks = func() (map[int]bool, error) {
+		return map[int]bool{1: true, 2: true, 3: true}, nil
+	}
+
+	c := newConnectivity()
+	for _, test := range []struct {
+		device *devmon.Update
+		want   bool
+	}{
+		{nil, false},
+		{&devmon.Update{Add: true, Index: 2, Type: "device"}, false},
+		{&devmon.Update{Add: true, Index: 1, Type: "loopback", Up: true}, false},
+		{&devmon.Update{Add: true, Index: 3, Type: "tuntap", Up: true}, false},
+		{&devmon.Update{Add: true, Index: 4, Type: "device", Up: true}, false},
+		{&devmon.Update{Add: true, Index: 2, Type: "device", Up: true}, true},
+		{&devmon.Update{Add: false, Index: 2}, false},
+	} {
+		if test.device != nil {
+			if test.device.Add {
+				c.devices[test.device.Index] = test.device
+			} else {
+				delete(c.devices, test.device.Index)
+			}
+		}
+		if got := c.isOnline(); got != test.want {
+			t.Errorf("%v: got %t, want %t", test.device, got, test.want)
+		}
+	}
+}
+
+// TestConnectivityStartStop tests start and stop of connectivity
+func TestConnectivityStartStop(t *testing.T) {
+	oldLinks := defaultRouteLinks
+	oldRoutes := registerRouteUpdates
+	oldRegister := devmon.RegisterLinkUpdates
+	defer func() {
+		defaultRouteLinks = oldLinks
+		registerRouteUpdates = oldRoutes
+		devmon.RegisterLinkUpdates = oldRegister
+	}()
+
+	defaultRouteLinks = func() (map[int]bool, error) {
+		return map[int]bool{2: true}, nil
+	}
+	routes := make(chan netlink.RouteUpdate)
+	registerRouteUpdates = func(<-chan struct{}) (chan netlink.RouteUpdate, error) {
+		return routes, nil
+	}
+	links := make(chan netlink.LinkUpdate)
+	devmon.RegisterLinkUpdates = func(*devmon.DevMon) (chan netlink.LinkUpdate, error) {
+		return links, nil
+	}
+
+	c := newConnectivity()
+	if c.resultsC() != nil {
+		t.Error("results channel should be nil before start")
+	}
+	if err := c.start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// initially offline without devices
+	if online := <-c.resultsC(); online {
+		t.Error("should be offline")
+	}
+
+	// device with carrier and default route
+	up := netlink.LinkUpdate{}
+	up.Header.Type = unix.RTM_NEWLINK
+	up.Link = &netlink.Device{LinkAttrs: netlink.LinkAttrs{
+		Name:     "oc-daemon-test-eth0",
+		Index:    2,
+		Flags:    net.FlagUp,
+		RawFlags: unix.IFF_UP | unix.IFF_LOWER_UP,
+	}}
+	links <- up
+	if online := <-c.resultsC(); !online {
+		t.Error("should be online")
+	}
+
+	// default route removed
+	defaultRouteLinks = func() (map[int]bool, error) {
+		return map[int]bool{}, nil
+	}
+	routes <- netlink.RouteUpdate{}
+	if online := <-c.resultsC(); online {
+		t.Error("should be offline")
+	}
+
+	c.stop()
+	if c.resultsC() != nil {
+		t.Error("results channel should be nil after stop")
+	}
+}
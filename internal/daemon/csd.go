@@ -0,0 +1,94 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"unicode/utf8"
+
+	"github.com/telekom-mms/oc-daemon/internal/audit"
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+)
+
+var (
+	// hostscanReportFile is the file the trusted CSD wrapper writes its
+	// hostscan report to, it must be owned by the daemon user and must
+	// not be writable by others, so clients cannot forge the report
+	hostscanReportFile = runDir + "/hostscan.report"
+)
+
+// maxHostscanReport is the maximum length of the hostscan report in the
+// audit log, only the end of longer reports is logged
+const maxHostscanReport = 4096
+
+// setStatusCSDWrapper sets the CSD wrapper of the config in status
+func (d *Daemon) setStatusCSDWrapper() {
+	wrapper := d.config.CSDWrapper
+	if d.status.CSDWrapper == wrapper {
+		// status not changed
+		return
+	}
+
+	// status changed
+	d.status.CSDWrapper = wrapper
+	d.setProperty(dbusapi.PropertyCSDWrapper, wrapper)
+}
+
+// readHostscanReport reads and truncates the hostscan report file of the
+// CSD wrapper, it returns only the end of long reports
+func readHostscanReport(file string) (string, error) {
+	f, err := os.OpenFile(file, os.O_RDWR|syscall.O_NOFOLLOW, 0)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	// check owner and permissions of the report file
+	fi, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !fi.Mode().IsRegular() || !ok ||
+		int(st.Uid) != os.Geteuid() || fi.Mode().Perm()&0o022 != 0 {
+		return "", fmt.Errorf("untrusted hostscan report file %s", file)
+	}
+
+	// read end of the report and truncate it for the next report
+	offset := fi.Size() - maxHostscanReport
+	if offset < 0 {
+		offset = 0
+	}
+	b, err := io.ReadAll(io.NewSectionReader(f, offset, maxHostscanReport))
+	if err != nil {
+		return "", err
+	}
+	if err := f.Truncate(0); err != nil {
+		return "", err
+	}
+
+	for len(b) > 0 && !utf8.RuneStart(b[0]) {
+		b = b[1:]
+	}
+	s := strings.ToValidUTF8(string(b), "\uFFFD")
+	return strings.TrimSpace(s), nil
+}
+
+// reportHostscan logs the hostscan report the trusted CSD wrapper wrote
+// during authentication to the audit log, the client only triggers it
+func (d *Daemon) reportHostscan(request *dbusapi.Request) error {
+	if d.config.CSDWrapper == "" {
+		return errors.New("csd wrapper not configured")
+	}
+	output, err := readHostscanReport(hostscanReportFile)
+	if err != nil {
+		return fmt.Errorf("could not read hostscan report: %w", err)
+	}
+
+	log.WithField("output", output).Info("Daemon got hostscan report")
+	d.logAudit(audit.EventHostscan, request.Sender, request.UID, output)
+	return nil
+}
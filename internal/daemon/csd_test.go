@@ -0,0 +1,96 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// TestReadHostscanReport tests readHostscanReport
+func TestReadHostscanReport(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "hostscan.report")
+
+	// missing file
+	if _, err := readHostscanReport(file); err == nil {
+		t.Error("read should fail without report file")
+	}
+
+	// short report, file truncated after read
+	if err := os.WriteFile(file, []byte("hostscan ok\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readHostscanReport(file)
+	if err != nil || got != "hostscan ok" {
+		t.Errorf("got %q, %v, want hostscan ok", got, err)
+	}
+	if b, err := os.ReadFile(file); err != nil || len(b) != 0 {
+		t.Errorf("report not truncated: %q, %v", b, err)
+	}
+
+	// long report, only the end at the start of a rune
+	long := strings.Repeat("ä", maxHostscanReport)
+	if err := os.WriteFile(file, []byte("start"+long), 0600); err != nil {
+		t.Fatal(err)
+	}
+	got, err = readHostscanReport(file)
+	if err != nil || len(got) > maxHostscanReport ||
+		!strings.HasSuffix(long, got) {
+		t.Errorf("invalid report: %d bytes, %v", len(got), err)
+	}
+
+	// writable by others
+	if err := os.Chmod(file, 0666); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readHostscanReport(file); err == nil {
+		t.Error("read should fail with untrusted report file")
+	}
+}
+
+// TestDaemonReportHostscan tests reportHostscan of Daemon
+func TestDaemonReportHostscan(t *testing.T) {
+	d := &Daemon{
+		config: NewConfig(),
+		dbus:   noDBusService{},
+		status: vpnstatus.New(),
+	}
+	request := &dbusapi.Request{
+		Name: dbusapi.RequestReportHostscan,
+	}
+
+	// set report file
+	old := hostscanReportFile
+	hostscanReportFile = filepath.Join(t.TempDir(), "hostscan.report")
+	defer func() { hostscanReportFile = old }()
+	if err := os.WriteFile(hostscanReportFile, []byte("hostscan ok\n"),
+		0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// csd wrapper not configured
+	if err := d.reportHostscan(request); err == nil {
+		t.Error("report should fail without csd wrapper")
+	}
+
+	// csd wrapper configured
+	d.config.CSDWrapper = "/usr/libexec/csd-wrapper"
+	d.setStatusCSDWrapper()
+	if d.status.CSDWrapper != d.config.CSDWrapper {
+		t.Errorf("got %s, want %s", d.status.CSDWrapper, d.config.CSDWrapper)
+	}
+	if err := d.reportHostscan(request); err != nil {
+		t.Error(err)
+	}
+
+	// report file missing
+	if err := os.Remove(hostscanReportFile); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.reportHostscan(request); err == nil {
+		t.Error("report should fail without report file")
+	}
+}
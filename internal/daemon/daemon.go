@@ -1,23 +1,32 @@
 package daemon
 
 import (
-	"crypto/rand"
-	"encoding/base64"
+	"context"
+	"errors"
+	"fmt"
 	"net"
+	"net/url"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"syscall"
 	"time"
 
-	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus"
 	"github.com/telekom-mms/oc-daemon/internal/api"
+	"github.com/telekom-mms/oc-daemon/internal/audit"
 	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
 	"github.com/telekom-mms/oc-daemon/internal/dnsproxy"
+	"github.com/telekom-mms/oc-daemon/internal/eventbus"
+	"github.com/telekom-mms/oc-daemon/internal/grpcapi"
+	"github.com/telekom-mms/oc-daemon/internal/logging"
+	"github.com/telekom-mms/oc-daemon/internal/manifest"
+	"github.com/telekom-mms/oc-daemon/internal/metrics"
 	"github.com/telekom-mms/oc-daemon/internal/ocrunner"
 	"github.com/telekom-mms/oc-daemon/internal/profilemon"
 	"github.com/telekom-mms/oc-daemon/internal/sleepmon"
 	"github.com/telekom-mms/oc-daemon/internal/splitrt"
-	"github.com/telekom-mms/oc-daemon/internal/trafpol"
+	"github.com/telekom-mms/oc-daemon/internal/wpad"
 	"github.com/telekom-mms/oc-daemon/pkg/logininfo"
 	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
 	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
@@ -26,53 +35,169 @@ import (
 	"golang.org/x/sys/unix"
 )
 
-const (
-	// dnsAddr is the DNS listen address
-	dnsAddr = "127.0.0.1:4253" // TODO: change?
-
-	// defaultDNSServer is the default DNS server address
-	// TODO: check if ok. use variable?
-	defaultDNSServer = "127.0.0.53:53"
-)
-
-var (
-	// cpdServers is a list of CPD servers, e.g., used by browsers
-	cpdServers = []string{
-		"connectivity-check.ubuntu.com", // ubuntu
-		"detectportal.firefox.com",      // firefox
-		"www.gstatic.com",               // chrome
-		"clients3.google.com",           // chromium
-		"nmcheck.gnome.org",             // gnome
-	}
-)
+// lockSessions locks all sessions, it can be replaced for testing
+var lockSessions = dbusapi.LockSessions
 
 // Daemon is used to run the daemon
 type Daemon struct {
+	config *Config
+
 	server *api.Server
-	dbus   *dbusapi.Service
+	dbus   dbusService
+
+	// grpc is the optional gRPC API server, nil if disabled
+	grpc *grpcapi.Server
+
+	// events is the event bus of the daemon, locker consumes its
+	// connection events and usage its stats events to track the VPN
+	// usage per month across restarts
+	events *eventbus.Bus
+	locker *sessionLocker
+	usage  *usageTracker
+
+	// dns is the DNS-Proxy and dnsAddr its listen address
+	dns     dnsProxy
+	dnsAddr string
 
-	dns *dnsproxy.Proxy
 	tnd *trustnet.TND
 
 	splitrt *splitrt.SplitRouting
-	trafpol *trafpol.TrafPol
+	trafpol trafPolicer
 
 	sleepmon *sleepmon.SleepMon
 
+	// connectivity monitors the network connectivity, offlineLogin is
+	// the login of a connect rejected while offline in always-on mode,
+	// it is used to connect when connectivity returns
+	connectivity *connectivity
+	offlineLogin *logininfo.LoginInfo
+
+	wpad *wpad.WPAD
+
 	status *vpnstatus.Status
 
+	// state is the connection state machine, its state is mirrored in
+	// status
+	state *stateMachine
+
 	runner *ocrunner.Connect
 
-	// token is used for client authentication
-	token string
+	// tunnels are the additional VPN tunnels and tunnelEvents their
+	// runner events
+	tunnels      []*tunnel
+	tunnelEvents chan *tunnelEvent
+
+	// reconnect handles automatic reconnects after unexpected
+	// disconnects
+	reconnect *reconnect
+
+	// disconnectRequested indicates that the current vpn disconnect is
+	// requested and not unexpected
+	disconnectRequested bool
+
+	// reconnectAfterDisconnect indicates that the vpn should be
+	// reconnected after the current disconnect, e.g., after resume
+	reconnectAfterDisconnect bool
+
+	// reconnectRequests are the D-Bus reconnect requests that are
+	// completed when the reconnect finished
+	reconnectRequests []*pendingRequest
+
+	// deviceAuthCodes and deviceAuthResults receive the device code and
+	// the result of a running device authorization, deviceAuthCancel
+	// cancels it and deviceAuthRequest is the D-Bus request waiting for
+	// the device code
+	deviceAuthCodes   chan *deviceAuthCode
+	deviceAuthResults chan *deviceAuthResult
+	deviceAuthCancel  context.CancelFunc
+	deviceAuthRequest *pendingRequest
+
+	// serverPings receives the results of VPN server probes of D-Bus
+	// list servers requests
+	serverPings chan *serverPing
+
+	// scheduler checks the connection schedule, scheduleEnabled enables
+	// the schedule, it is initialized from the config and can be changed
+	// with D-Bus
+	scheduler       *scheduler
+	scheduleEnabled bool
+
+	// tndToggle disables TND temporarily with D-Bus
+	tndToggle *toggle
+
+	// trafPolToggle disables traffic policing temporarily with D-Bus
+	trafPolToggle *toggle
+
+	// pauseToggle disables split routing and DNS of the VPN connection
+	// temporarily with D-Bus
+	pauseToggle *toggle
+
+	// idle detects idle VPN connections for the idle policy
+	idle *idleMonitor
+
+	// runtimeGC removes stale runtime files
+	runtimeGC *runtimeGC
+
+	// session enforces the maximum session duration of the session
+	// limit
+	session *sessionTimer
+
+	// resolvConf restores resolv.conf changed by other tools while
+	// connected
+	resolvConf *resolvConfGuard
+
+	// domainConflicts are the last routing domain conflicts of the VPN
+	// device with other links in systemd-resolved
+	domainConflicts []*domainConflict
+
+	// secret is used for authentication of vpnc-script calls of the
+	// current connection
+	secret *connSecret
+
+	// notifier applies the notification policy to emitted signals, the
+	// policy is initialized from the config and can be changed with D-Bus
+	notifier *notifier
+
+	// connectLimiter limits the rate of D-Bus connect requests per user,
+	// configUpdateLimiter the rate of socket config updates per connection
+	connectLimiter      *rateLimiter
+	configUpdateLimiter *rateLimiter
+
+	// reloads is used to reload the config
+	reloads chan *Config
+
+	// dumps is used to request a debug dump
+	dumps chan struct{}
+
+	// statsTicker triggers traffic statistics updates while connected
+	statsTicker *time.Ticker
+
+	// metrics are the metrics of the API requests
+	metrics *metrics.Registry
+
+	// gateway tracks the addresses of the VPN gateway that are excluded
+	// from the tunnel
+	gateway *gatewayMonitor
+
+	// audit is the connection audit log and auditTarget its target,
+	// nil if the audit log is disabled
+	audit       audit.Logger
+	auditTarget string
+
+	// ctx is the context for subsystems started while the daemon is
+	// running, e.g., split routing and traffic policing
+	ctx context.Context
 
 	// channels for shutdown
-	done   chan struct{}
+	done   <-chan struct{}
+	cancel context.CancelFunc
 	closed chan struct{}
 
-	// profile is the xml profile
-	profile *xmlprofile.Profile
-	profmon *profilemon.ProfileMon
+	// profile is the selected xml profile and profileName its name,
+	// empty for the default xml profile
+	profile     *xmlprofile.Profile
+	profileName string
+	profmon     *profilemon.ProfileMon
 
 	// disableTrafPol determines if traffic policing should be disabled,
 	// overrides other traffic policing settings
@@ -95,19 +220,21 @@ func (d *Daemon) setStatusTrustedNetwork(trusted bool) {
 
 	// status changed
 	d.status.TrustedNetwork = trustedNetwork
-	d.dbus.SetProperty(dbusapi.PropertyTrustedNetwork, trustedNetwork)
+	d.setProperty(dbusapi.PropertyTrustedNetwork, trustedNetwork)
+	d.logAuditDaemon(audit.EventTrustedNetwork, trustedNetwork.String())
+	d.publish(&eventbus.TrustedNetworkChanged{Trusted: trusted})
 }
 
-// setStatusConnectionState sets the connection state in status
-func (d *Daemon) setStatusConnectionState(connectionState vpnstatus.ConnectionState) {
-	if d.status.ConnectionState == connectionState {
-		// state not changed
-		return
-	}
-
-	// state changed
-	d.status.ConnectionState = connectionState
-	d.dbus.SetProperty(dbusapi.PropertyConnectionState, connectionState)
+// handleStateTransition handles a connection state transition of the
+// state machine and sets the connection state in status
+func (d *Daemon) handleStateTransition(t *StateTransition) {
+	log.WithFields(logrus.Fields{
+		"from": t.From,
+		"to":   t.To,
+	}).Info("Daemon connection state changed")
+	d.status.ConnectionState = t.To
+	d.setProperty(dbusapi.PropertyConnectionState, t.To)
+	d.publish(&eventbus.ConnectionStateChanged{From: t.From, To: t.To})
 }
 
 // setStatusIP sets the IP in status
@@ -119,7 +246,7 @@ func (d *Daemon) setStatusIP(ip string) {
 
 	// ip changed
 	d.status.IP = ip
-	d.dbus.SetProperty(dbusapi.PropertyIP, ip)
+	d.setProperty(dbusapi.PropertyIP, ip)
 }
 
 // setStatusDevice sets the device in status
@@ -131,7 +258,7 @@ func (d *Daemon) setStatusDevice(device string) {
 
 	// device changed
 	d.status.Device = device
-	d.dbus.SetProperty(dbusapi.PropertyDevice, device)
+	d.setProperty(dbusapi.PropertyDevice, device)
 }
 
 // setStatusConnectedAt sets the connection time in status
@@ -143,7 +270,19 @@ func (d *Daemon) setStatusConnectedAt(connectedAt int64) {
 
 	// connection time changed
 	d.status.ConnectedAt = connectedAt
-	d.dbus.SetProperty(dbusapi.PropertyConnectedAt, connectedAt)
+	d.setProperty(dbusapi.PropertyConnectedAt, connectedAt)
+}
+
+// setStatusUptime sets the uptime of the connection in status
+func (d *Daemon) setStatusUptime(uptime uint64) {
+	if d.status.Uptime == uptime {
+		// uptime not changed
+		return
+	}
+
+	// uptime changed
+	d.status.Uptime = uptime
+	d.setProperty(dbusapi.PropertyUptime, uptime)
 }
 
 // setStatusServers sets the vpn servers in status
@@ -155,7 +294,7 @@ func (d *Daemon) setStatusServers(servers []string) {
 
 	// servers changed
 	d.status.Servers = servers
-	d.dbus.SetProperty(dbusapi.PropertyServers, servers)
+	d.setProperty(dbusapi.PropertyServers, servers)
 }
 
 // setStatusOCRunning sets the openconnect running state in status
@@ -171,7 +310,7 @@ func (d *Daemon) setStatusOCRunning(running bool) {
 
 	// OC running state changed
 	d.status.OCRunning = ocrunning
-	d.dbus.SetProperty(dbusapi.PropertyOCRunning, ocrunning)
+	d.setProperty(dbusapi.PropertyOCRunning, ocrunning)
 }
 
 // setStatusVPNConfig sets the VPN config in status
@@ -186,7 +325,7 @@ func (d *Daemon) setStatusVPNConfig(config *vpnconfig.Config) {
 
 	if config == nil {
 		// remove config
-		d.dbus.SetProperty(dbusapi.PropertyVPNConfig, dbusapi.VPNConfigInvalid)
+		d.setProperty(dbusapi.PropertyVPNConfig, dbusapi.VPNConfigInvalid)
 		return
 	}
 
@@ -195,41 +334,365 @@ func (d *Daemon) setStatusVPNConfig(config *vpnconfig.Config) {
 	if err != nil {
 		log.WithError(err).Fatal("Daemon could not convert status to JSON")
 	}
-	d.dbus.SetProperty(dbusapi.PropertyVPNConfig, string(b))
+	d.setProperty(dbusapi.PropertyVPNConfig, string(b))
+}
+
+// setStatusProxy sets the detected proxy in status
+func (d *Daemon) setStatusProxy(proxy string) {
+	if d.status.Proxy == proxy {
+		// proxy not changed
+		return
+	}
+
+	// proxy changed
+	d.status.Proxy = proxy
+	d.setProperty(dbusapi.PropertyProxy, proxy)
+}
+
+// setStatusRetry sets the retry state in status from the scheduled
+// reconnect attempt
+func (d *Daemon) setStatusRetry() {
+	retryAt := dbusapi.RetryAtInvalid
+	retryAttempt := dbusapi.RetryAttemptInvalid
+	if d.reconnect.pending() {
+		retryAt = d.reconnect.nextRetry().Unix()
+		retryAttempt = uint32(d.reconnect.attempts)
+	}
+
+	if d.status.RetryAt != retryAt {
+		d.status.RetryAt = retryAt
+		d.setProperty(dbusapi.PropertyRetryAt, retryAt)
+	}
+	if d.status.RetryAttempt != retryAttempt {
+		d.status.RetryAttempt = retryAttempt
+		d.setProperty(dbusapi.PropertyRetryAttempt, retryAttempt)
+	}
+}
+
+// setStatusTrafficStats sets the traffic statistics in status
+func (d *Daemon) setStatusTrafficStats(stats *trafficStats) {
+	for _, s := range []struct {
+		name string
+		dest *uint64
+		val  uint64
+	}{
+		{dbusapi.PropertyRXBytes, &d.status.RXBytes, stats.RXBytes},
+		{dbusapi.PropertyTXBytes, &d.status.TXBytes, stats.TXBytes},
+		{dbusapi.PropertyRXPackets, &d.status.RXPackets, stats.RXPackets},
+		{dbusapi.PropertyTXPackets, &d.status.TXPackets, stats.TXPackets},
+	} {
+		if *s.dest == s.val {
+			// value not changed
+			continue
+		}
+
+		// value changed
+		*s.dest = s.val
+		d.setProperty(s.name, s.val)
+	}
+}
+
+// setStatusRouteAggregations sets the number of route churn aggregations in
+// status
+func (d *Daemon) setStatusRouteAggregations(aggregations uint64) {
+	if d.status.RouteAggregations == aggregations {
+		// aggregations not changed
+		return
+	}
+
+	// aggregations changed
+	d.status.RouteAggregations = aggregations
+	d.setProperty(dbusapi.PropertyRouteAggregations, aggregations)
+}
+
+// setStatusDNSLeaksBlocked sets the number of blocked DNS leaks in status
+func (d *Daemon) setStatusDNSLeaksBlocked(leaks uint64) {
+	if d.status.DNSLeaksBlocked == leaks {
+		// leaks not changed
+		return
+	}
+
+	// leaks changed
+	d.status.DNSLeaksBlocked = leaks
+	d.setProperty(dbusapi.PropertyDNSLeaksBlocked, leaks)
+}
+
+// setStatusScheduleState sets the schedule state in status
+func (d *Daemon) setStatusScheduleState(state vpnstatus.ScheduleState) {
+	if d.status.ScheduleState == state {
+		// status not changed
+		return
+	}
+
+	// status changed
+	d.status.ScheduleState = state
+	d.setProperty(dbusapi.PropertyScheduleState, state)
+}
+
+// setStatusPreferredServer sets the preferred vpn server in status
+func (d *Daemon) setStatusPreferredServer(server string) {
+	if d.status.PreferredServer == server {
+		// preferred server not changed
+		return
+	}
+
+	// preferred server changed
+	d.status.PreferredServer = server
+	d.setProperty(dbusapi.PropertyPreferredServer, server)
+}
+
+// setStatusTrafPolState sets the traffic policing state in status
+func (d *Daemon) setStatusTrafPolState(state vpnstatus.TrafPolState) {
+	if d.status.TrafPolState == state {
+		// state not changed
+		return
+	}
+
+	// state changed
+	d.status.TrafPolState = state
+	d.setProperty(dbusapi.PropertyTrafPolState, state)
+}
+
+// setStatusTNDState sets the tnd state in status
+func (d *Daemon) setStatusTNDState(state vpnstatus.TNDState) {
+	if d.status.TNDState == state {
+		// status not changed
+		return
+	}
+
+	// status changed
+	d.status.TNDState = state
+	d.setProperty(dbusapi.PropertyTNDState, state)
+}
+
+// setStatusCompression sets the compression mode of the current connection
+// in status, the default mode of openconnect is reported as "default"
+func (d *Daemon) setStatusCompression(compression string) {
+	if compression == "" && d.status.OCRunning.Running() {
+		compression = vpnstatus.CompressionDefault
+	}
+	if d.status.Compression == compression {
+		// status not changed
+		return
+	}
+
+	// status changed
+	d.status.Compression = compression
+	d.setProperty(dbusapi.PropertyCompression, compression)
+}
+
+// setStatusDisconnectReason sets the reason of the last disconnect in status
+func (d *Daemon) setStatusDisconnectReason(reason string) {
+	if d.status.DisconnectReason == reason {
+		// status not changed
+		return
+	}
+
+	// status changed
+	d.status.DisconnectReason = reason
+	d.setProperty(dbusapi.PropertyDisconnectReason, reason)
+}
+
+// setStatusAPIs sets the active APIs of the daemon in status, the socket
+// API is always active, the D-Bus API only if its service is running and
+// the gRPC API only if it is enabled
+func (d *Daemon) setStatusAPIs() {
+	apis := []string{vpnstatus.APISocket}
+	if _, ok := d.dbus.(noDBusService); !ok {
+		apis = append(apis, vpnstatus.APIDBus)
+	}
+	if d.grpc != nil {
+		apis = append(apis, vpnstatus.APIGRPC)
+	}
+	if reflect.DeepEqual(d.status.APIs, apis) {
+		// status not changed
+		return
+	}
+
+	// status changed
+	d.status.APIs = apis
+	d.setProperty(dbusapi.PropertyAPIs, apis)
+}
+
+// setStatusConnectivity sets the network connectivity in status
+func (d *Daemon) setStatusConnectivity(connectivity vpnstatus.Connectivity) {
+	if d.status.Connectivity == connectivity {
+		// status not changed
+		return
+	}
+
+	// status changed
+	d.status.Connectivity = connectivity
+	d.setProperty(dbusapi.PropertyConnectivity, connectivity)
+	d.publish(&eventbus.ConnectivityChanged{
+		Online: connectivity == vpnstatus.ConnectivityOnline,
+	})
+}
+
+// setStatusDNS sets the DNS servers, search domains and split domains in
+// status from config, config nil removes them
+func (d *Daemon) setStatusDNS(config *vpnconfig.Config) {
+	servers := dbusapi.DNSDomainsInvalid
+	search := dbusapi.DNSDomainsInvalid
+	split := dbusapi.DNSDomainsInvalid
+	if config != nil {
+		servers = config.DNS.Servers()
+		if config.DNS.DefaultDomain != "" {
+			search = []string{config.DNS.DefaultDomain}
+		}
+		if config.DNS.SplitDNS() {
+			split = append(split, config.DNS.SplitDomains...)
+		}
+	}
+
+	for _, s := range []struct {
+		name string
+		dest *[]string
+		val  []string
+	}{
+		{dbusapi.PropertyDNSServers, &d.status.DNSServers, servers},
+		{dbusapi.PropertyDNSSearchDomains, &d.status.DNSSearchDomains, search},
+		{dbusapi.PropertyDNSSplitDomains, &d.status.DNSSplitDomains, split},
+	} {
+		if reflect.DeepEqual(*s.dest, s.val) {
+			// value not changed
+			continue
+		}
+
+		// value changed
+		*s.dest = s.val
+		d.setProperty(s.name, s.val)
+	}
 }
 
 // connectVPN connects to the VPN using login info from client request
-func (d *Daemon) connectVPN(login *logininfo.LoginInfo) {
+func (d *Daemon) connectVPN(login *logininfo.LoginInfo) error {
 	// allow only one connection
 	if d.status.OCRunning.Running() {
-		return
+		return errors.New("vpn already running")
+	}
+
+	// reject connect during forbidden schedule windows
+	if d.status.ScheduleState.Forbidden() {
+		return errors.New("vpn connection forbidden by schedule")
 	}
 
-	// ignore invalid login information
+	// reject invalid login information
 	if !login.Valid() {
-		return
+		return errors.New("invalid login information")
+	}
+
+	// reject connect while offline, connect when connectivity returns
+	// in always-on mode
+	if d.status.Connectivity.Offline() {
+		if d.profile.GetAlwaysOn() {
+			d.offlineLogin = login
+		}
+		return dbusapi.ErrOffline
 	}
 
-	// update status
+	// run posture checks, reject connect if enforced checks failed
+	postureEnv, err := d.checkPosture()
+	if err != nil {
+		return err
+	}
+
+	// update state, reject connect in other states than disconnected
+	if err := d.state.transition(vpnstatus.ConnectionStateConnecting); err != nil {
+		return err
+	}
+
+	// create new secret for this connection
+	if err := d.secret.rotate(); err != nil {
+		_ = d.state.transition(vpnstatus.ConnectionStateDisconnected)
+		return err
+	}
+
+	// update status, a scheduled retry is not needed any more
+	d.reconnect.stop()
+	d.setStatusRetry()
 	d.setStatusOCRunning(true)
-	d.setStatusConnectionState(vpnstatus.ConnectionStateConnecting)
+	d.disconnectRequested = false
+
+	// use detected proxy?
+	proxy := ""
+	if d.config.AutoProxy {
+		proxy = d.status.Proxy
+	}
 
 	// connect using runner
-	env := []string{"oc_daemon_token=" + d.token}
-	d.runner.Connect(login, env)
+	env := []string{
+		"oc_daemon_secret_file=" + d.secret.file(),
+		"oc_daemon_socket_file=" + sockFile,
+	}
+	env = append(env, postureEnv...)
+	compression := d.compressionMode()
+	d.setStatusCompression(compression)
+	family := d.config.AddressFamily
+	d.runner.Connect(login, env, proxy, profilePath(d.profileName),
+		compression, family.Gateway, family.DisableTunnelIPv6)
+	return nil
 }
 
 // disconnectVPN disconnects from the VPN
-func (d *Daemon) disconnectVPN() {
-	// update status
-	d.setStatusConnectionState(vpnstatus.ConnectionStateDisconnecting)
+func (d *Daemon) disconnectVPN() error {
+	// this disconnect is expected, do not reconnect
+	d.disconnectRequested = true
+	d.reconnectAfterDisconnect = false
+	d.reconnect.stop()
+	d.setStatusRetry()
+	d.stopDeviceAuth()
+	d.offlineLogin = nil
+	d.finishReconnect(errReconnectAborted)
+
+	// nothing to disconnect, only stop the scheduled retry above
+	if d.state.get() == vpnstatus.ConnectionStateDisconnected {
+		return nil
+	}
+
+	// update state and status
+	if err := d.state.transition(vpnstatus.ConnectionStateDisconnecting); err != nil {
+		return err
+	}
 	d.setStatusOCRunning(false)
 
 	// stop runner
 	if d.runner == nil {
-		return
+		return nil
 	}
 	d.runner.Disconnect()
+	return nil
+}
+
+// cancelVPN aborts a connection attempt that hangs in the connecting state,
+// e.g., in the openconnect handshake, by killing openconnect; the state is
+// reverted to disconnected when the runner reports the exit
+func (d *Daemon) cancelVPN() error {
+	if d.state.get() != vpnstatus.ConnectionStateConnecting {
+		return errors.New("vpn is not connecting")
+	}
+
+	// this disconnect is expected, do not reconnect
+	d.disconnectRequested = true
+	d.reconnectAfterDisconnect = false
+	d.reconnect.stop()
+	d.setStatusRetry()
+	d.stopDeviceAuth()
+	d.offlineLogin = nil
+	d.finishReconnect(errReconnectAborted)
+
+	// update state and status
+	if err := d.state.transition(vpnstatus.ConnectionStateDisconnecting); err != nil {
+		return err
+	}
+	d.setStatusOCRunning(false)
+
+	// kill openconnect
+	if d.runner == nil {
+		return nil
+	}
+	d.runner.Cancel()
+	return nil
 }
 
 // setupRouting sets up routing using config
@@ -238,8 +701,14 @@ func (d *Daemon) setupRouting(config *vpnconfig.Config) {
 	if d.splitrt != nil {
 		return
 	}
-	d.splitrt = splitrt.NewSplitRouting(config)
-	d.splitrt.Start()
+	s := splitrt.NewSplitRouting(config)
+	if err := s.Start(d.ctx); err != nil {
+		log.WithField(logging.CodeField, logging.CodeSplitRoutingFailed).
+			WithError(err).Error("Daemon could not start split routing")
+		return
+	}
+	d.splitrt = s
+	d.updateGatewayExcludes()
 }
 
 // teardownRouting tears down the routing configuration
@@ -249,6 +718,7 @@ func (d *Daemon) teardownRouting() {
 	}
 	d.splitrt.Stop()
 	d.splitrt = nil
+	d.updateGatewayExcludes()
 }
 
 // setupDNS sets up DNS using config
@@ -258,53 +728,68 @@ func (d *Daemon) setupDNS(config *vpnconfig.Config) {
 	// TODO: improve this
 
 	// set remotes
-	remotes := config.DNS.Remotes()
+	remotes := config.DNSRemotes()
 	d.dns.SetRemotes(remotes)
 
+	// do not fall back to the default DNS server in tunnel all DNS mode
+	d.dns.SetTunnelAll(config.DNS.TunnelAll)
+
+	// send queries to the VPN DNS servers over the VPN device only
+	d.dns.SetDevice(config.Device.Name)
+
 	// set watches
 	excludes := config.Split.DNSExcludes()
 	log.WithField("excludes", excludes).Debug("Daemon setting DNS Split Excludes")
 	d.dns.SetWatches(excludes)
+	log.WithField("version", d.dns.Version()).Debug("Daemon set DNS-Proxy config")
 
 	// update dns configuration of host
-	setVPNDNS(config, dnsAddr)
+	setVPNDNS(config, getDNSServers(d.dnsAddr, config))
 }
 
 // teardownDNS tears down the DNS configuration
 func (d *Daemon) teardownDNS() {
-	remotes := map[string][]string{
-		".": {defaultDNSServer},
-	}
-	d.dns.SetRemotes(remotes)
+	d.dns.SetRemotes(map[string][]string{})
+	d.dns.SetTunnelAll(false)
+	d.dns.SetDevice("")
 	d.dns.SetWatches([]string{})
+	log.WithField("version", d.dns.Version()).Debug("Daemon reset DNS-Proxy config")
 	unsetVPNDNS(d.status.VPNConfig)
 }
 
 // updateVPNConfigUp updates the VPN config for VPN connect
-func (d *Daemon) updateVPNConfigUp(config *vpnconfig.Config) {
+func (d *Daemon) updateVPNConfigUp(config *vpnconfig.Config) error {
+	// add dns transports from daemon config
+	addDNSTransports(config, d.config.DNSTransports)
+
+	// ignore ipv6 configuration if ipv6 is disabled in the tunnel
+	if d.config.AddressFamily.DisableTunnelIPv6 && removeTunnelIPv6(config) {
+		log.Info("Daemon ignoring IPv6 configuration of disabled tunnel IPv6")
+	}
+
 	// check if old and new config differ
 	if config.Equal(d.status.VPNConfig) {
-		log.WithField("error", "old and new vpn configs are equal").
-			Error("Daemon config up error")
-		return
+		return errors.New("old and new vpn configs are equal")
 	}
 
 	// check if vpn is flagged as running
 	if !d.status.OCRunning.Running() {
-		log.WithField("error", "vpn not running").
-			Error("Daemon config up error")
-		return
+		return errors.New("vpn not running")
 	}
 
-	// check if we are already connected
-	if d.status.ConnectionState.Connected() {
-		log.WithField("error", "vpn already connected").
-			Error("Daemon config up error")
-		return
+	// check if we are connecting, e.g., not already connected
+	if state := d.state.get(); state != vpnstatus.ConnectionStateConnecting {
+		return &StateError{From: state, To: vpnstatus.ConnectionStateConnected}
+	}
+
+	// set language of the gateway banner
+	if config.Banner.Text != "" && config.Banner.Language == "" {
+		config.Banner.Language = d.config.BannerLanguage
 	}
 
 	// connecting, set up configuration
-	log.Info("Daemon setting up vpn configuration")
+	log.WithField(logging.CodeField, logging.CodeVPNSetup).
+		Info("Daemon setting up vpn configuration")
 	setupVPNDevice(config)
 	d.setupRouting(config)
 	d.setupDNS(config)
@@ -313,10 +798,16 @@ func (d *Daemon) updateVPNConfigUp(config *vpnconfig.Config) {
 	// in configuration
 	d.disableTrafPol = config.Flags.DisableAlwaysOnVPN
 
+	// connection successful, reset reconnect attempts
+	d.reconnect.reset()
+
 	// save config
 	d.setStatusVPNConfig(config)
-	d.setStatusConnectionState(vpnstatus.ConnectionStateConnected)
+	if err := d.state.transition(vpnstatus.ConnectionStateConnected); err != nil {
+		return err
+	}
 	d.setStatusConnectedAt(time.Now().Unix())
+	d.finishReconnect(nil)
 	ip := ""
 	for _, addr := range []net.IP{config.IPv4.Address, config.IPv6.Address} {
 		// this assumes either a single IPv4 or a single IPv6 address
@@ -327,45 +818,220 @@ func (d *Daemon) updateVPNConfigUp(config *vpnconfig.Config) {
 	}
 	d.setStatusIP(ip)
 	d.setStatusDevice(config.Device.Name)
+	d.setStatusDNS(config)
+	d.startGateway(config.Gateway)
+
+	// register vpn ip addresses in dns
+	d.registerHostname(config)
+
+	// start usage tracking, traffic statistics and idle detection
+	stats, _ := readTrafficStats(config.Device.Name)
+	d.publish(&eventbus.TrafficStatsStarted{Stats: stats.event()})
+	d.startStats()
+	d.startIdle()
+	d.startSession()
+	d.startResolvConfGuard()
+	d.checkDomainConflicts()
+	return nil
 }
 
 // updateVPNConfigDown updates the VPN config for VPN disconnect
-func (d *Daemon) updateVPNConfigDown() {
+func (d *Daemon) updateVPNConfigDown() error {
 	// TODO: only call this from Runner Event only and remove down message?
 	// or potentially calling this twice is better than not at all?
 
 	// check if vpn is still flagged as running
 	if d.status.OCRunning.Running() {
-		log.WithField("error", "vpn still running").
-			Error("Daemon config down error")
-		return
+		return errors.New("vpn still running")
 	}
 
-	// check if vpn is still flagged as connected
-	if d.status.ConnectionState.Connected() {
-		log.WithField("error", "vpn still connected").
-			Error("Daemon config down error")
-		return
+	// check if vpn is still connected
+	if state := d.state.get(); state == vpnstatus.ConnectionStateConnected {
+		return &StateError{From: state, To: vpnstatus.ConnectionStateDisconnected}
 	}
 
 	// disconnecting, tear down configuration
-	log.Info("Daemon tearing down vpn configuration")
+	log.WithField(logging.CodeField, logging.CodeVPNTeardown).
+		Info("Daemon tearing down vpn configuration")
+	paused := d.resetPause()
 	if d.status.VPNConfig != nil {
 		teardownVPNDevice(d.status.VPNConfig)
-		d.teardownRouting()
-		d.teardownDNS()
+		if !paused {
+			d.teardownRouting()
+			d.teardownDNS()
+		}
 	}
 
+	// stop usage tracking, traffic statistics, idle detection, session
+	// limit and resolv.conf guard
+	d.publish(&eventbus.TrafficStatsStopped{})
+	d.stopStats()
+	d.stopGateway()
+	d.idle.stop()
+	d.session.stop()
+	d.resolvConf.stop()
+	d.domainConflicts = nil
+
 	// save config
 	d.setStatusVPNConfig(nil)
-	d.setStatusConnectionState(vpnstatus.ConnectionStateDisconnected)
+	if err := d.state.transition(vpnstatus.ConnectionStateDisconnected); err != nil {
+		return err
+	}
 	d.setStatusConnectedAt(0)
 	d.setStatusIP("")
 	d.setStatusDevice("")
+	d.setStatusDNS(nil)
+	return nil
+}
+
+// startStats starts periodic traffic statistics updates if enabled
+func (d *Daemon) startStats() {
+	d.stopStats()
+	if d.config.StatsInterval <= 0 ||
+		!d.status.ConnectionState.Connected() {
+		return
+	}
+	d.statsTicker = time.NewTicker(d.config.StatsInterval)
+	d.updateStats()
+}
+
+// stopStats stops periodic traffic statistics updates and resets the
+// traffic statistics
+func (d *Daemon) stopStats() {
+	if d.statsTicker != nil {
+		d.statsTicker.Stop()
+		d.statsTicker = nil
+	}
+	d.setStatusTrafficStats(&trafficStats{})
+	d.setStatusRouteAggregations(0)
+	d.setStatusUptime(dbusapi.UptimeInvalid)
+}
+
+// updateStats updates the traffic statistics of the vpn device and the
+// uptime of the connection
+func (d *Daemon) updateStats() {
+	if d.status.ConnectedAt > 0 {
+		uptime := time.Now().Unix() - d.status.ConnectedAt
+		if uptime < 0 {
+			uptime = 0
+		}
+		d.setStatusUptime(uint64(uptime))
+	}
+
+	stats, err := readTrafficStats(d.status.Device)
+	d.publish(&eventbus.TrafficStatsUpdated{Stats: stats.event()})
+	if err != nil {
+		log.WithError(err).Debug("Daemon could not read traffic statistics")
+		return
+	}
+	d.setStatusTrafficStats(stats)
+	d.setStatusDNSLeaksBlocked(d.dns.Leaks())
+	if d.splitrt != nil {
+		d.setStatusRouteAggregations(d.splitrt.Aggregations())
+	}
+}
+
+// statsC returns the channel of the traffic statistics ticker or nil if
+// traffic statistics are not running
+func (d *Daemon) statsC() <-chan time.Time {
+	if d.statsTicker == nil {
+		return nil
+	}
+	return d.statsTicker.C
+}
+
+// startIdle starts the idle detection of the vpn device if the idle policy
+// is enabled
+func (d *Daemon) startIdle() {
+	d.idle.stop()
+	if !d.config.IdlePolicy.Enabled() ||
+		!d.status.ConnectionState.Connected() {
+		return
+	}
+	packets := uint64(0)
+	if stats, err := readTrafficStats(d.status.Device); err == nil {
+		packets = stats.RXPackets + stats.TXPackets
+	}
+	d.idle.start(d.config.IdlePolicy.Timeout, packets)
+}
+
+// handleIdleCheck checks if the vpn connection is idle and applies the
+// idle policy
+func (d *Daemon) handleIdleCheck() {
+	stats, err := readTrafficStats(d.status.Device)
+	if err != nil {
+		log.WithError(err).Debug("Daemon could not read traffic statistics")
+		d.idle.schedule()
+		return
+	}
+	if !d.idle.check(stats.RXPackets + stats.TXPackets) {
+		return
+	}
+
+	policy := d.config.IdlePolicy
+	log.WithFields(logrus.Fields{
+		"timeout": policy.Timeout,
+		"action":  policy.Action,
+	}).Info("Daemon detected idle VPN connection")
+	d.emitSignal(dbusapi.SignalIdleTimeout)
+	if policy.Action != IdleActionDisconnect {
+		return
+	}
+	d.logAuditDaemon(audit.EventDisconnect, "idle timeout")
+	if err := d.disconnectVPN(); err != nil {
+		log.WithField(logging.CodeField, logging.CodeIdleDisconnectFailed).
+			WithError(err).Error("Daemon could not disconnect idle VPN")
+	}
+}
+
+// startSession starts enforcing the maximum session duration of the vpn
+// connection if the session limit is enabled, the session starts at the
+// connection time so it is not extended by a reload
+func (d *Daemon) startSession() {
+	d.session.stop()
+	if !d.config.SessionLimit.Enabled() ||
+		!d.status.ConnectionState.Connected() {
+		return
+	}
+	limit := d.config.SessionLimit
+	begin := time.Unix(d.status.ConnectedAt, 0)
+	d.session.start(begin, limit.MaxDuration, limit.Warnings)
+}
+
+// handleSessionTimer warns about the end of the session or applies the
+// session limit at the end of the session
+func (d *Daemon) handleSessionTimer() {
+	remaining, end := d.session.expired()
+	if !end {
+		log.WithField("remaining", remaining.Round(time.Second)).
+			Warn("Daemon VPN session expiring soon")
+		d.emitSignal(dbusapi.SignalSessionExpiring,
+			uint32(remaining.Round(time.Second).Seconds()))
+		return
+	}
+
+	limit := d.config.SessionLimit
+	log.WithFields(logrus.Fields{
+		"maxDuration": limit.MaxDuration,
+		"action":      limit.Action,
+	}).Info("Daemon reached maximum VPN session duration")
+	d.logAuditDaemon(audit.EventDisconnect, "session limit")
+	if err := d.disconnectVPN(); err != nil {
+		log.WithField(logging.CodeField, logging.CodeSessionLimitFailed).
+			WithError(err).Error("Daemon could not disconnect VPN at session limit")
+		return
+	}
+	if limit.Action == SessionActionReconnect &&
+		d.reconnect.getLogin().Valid() {
+		d.reconnectAfterDisconnect = true
+	}
 }
 
 // updateVPNConfig updates the VPN config with config update in client request
 func (d *Daemon) updateVPNConfig(request *api.Request) {
+	// log entries of the request contain its ID
+	log := log.WithField(logging.RequestField, request.ID())
+
 	// parse config
 	configUpdate, err := VPNConfigUpdateFromJSON(request.Data())
 	if err != nil {
@@ -374,33 +1040,117 @@ func (d *Daemon) updateVPNConfig(request *api.Request) {
 		return
 	}
 
+	if err := d.applyVPNConfigUpdate(log, configUpdate,
+		request.Challenge()); err != nil {
+		request.Error(err.Error())
+	}
+}
+
+// applyVPNConfigUpdate checks the config update with the response to
+// challenge and applies it, it is used by the socket and gRPC APIs and logs
+// with log
+func (d *Daemon) applyVPNConfigUpdate(log *logrus.Entry,
+	configUpdate *VPNConfigUpdate, challenge []byte) error {
 	// check if config update is valid
 	if !configUpdate.Valid() {
-		log.Error("Daemon got invalid vpn config update")
-		request.Error("invalid config update in config update message")
-		return
+		log.WithField(logging.CodeField, logging.CodeInvalidConfigUpdate).
+			Error("Daemon got invalid vpn config update")
+		return errors.New("invalid config update in config update message")
 	}
 
-	// check token
-	if configUpdate.Token != d.token {
-		log.Error("Daemon got invalid token in vpn config update")
-		request.Error("invalid token in config update message")
-		return
+	// check rate limit of the connection
+	sender := d.configUpdateSender(challenge, configUpdate.Response)
+	if err := checkRateLimit(d.configUpdateLimiter, sender); err != nil {
+		log.WithError(err).WithField("sender", sender).
+			Error("Daemon rejected vpn config update")
+		return err
+	}
+
+	// check response to the challenge, it is either the response with the
+	// secret of the main vpn connection or of an additional tunnel
+	if !d.secret.check(challenge, configUpdate.Response) {
+		t := d.getTunnelBySecret(challenge, configUpdate.Response)
+		if t == nil {
+			log.WithField(logging.CodeField, logging.CodeInvalidToken).
+				Error("Daemon got invalid challenge response in vpn config update")
+			return errors.New("invalid challenge response in config update message")
+		}
+		if err := d.updateTunnelConfig(t, configUpdate); err != nil {
+			log.WithError(err).WithField("tunnel", t.name).
+				Error("Daemon tunnel config update error")
+			return err
+		}
+		return nil
+	}
+
+	// handle gateway address of reconnect attempt
+	if configUpdate.Reason == "attempt-reconnect" {
+		if !d.status.ConnectionState.Connected() {
+			return errors.New("vpn not connected")
+		}
+		d.handleGatewayUpdate(configUpdate.Config.Gateway)
+		return nil
 	}
 
 	// handle config update for vpn (dis)connect
 	if configUpdate.Reason == "disconnect" {
-		d.updateVPNConfigDown()
-		return
+		if err := d.updateVPNConfigDown(); err != nil {
+			log.WithField(logging.CodeField, logging.CodeVPNConfigDownFailed).
+				WithError(err).Error("Daemon config down error")
+			return err
+		}
+		return nil
+	}
+	if err := d.updateVPNConfigUp(configUpdate.Config); err != nil {
+		log.WithField(logging.CodeField, logging.CodeVPNConfigUpFailed).
+			WithError(err).Error("Daemon config up error")
+		return err
+	}
+	return nil
+}
+
+// configUpdateSender returns the rate limiting sender of config updates with
+// response to challenge: the main vpn connection, an additional tunnel or an
+// invalid response
+func (d *Daemon) configUpdateSender(challenge, response []byte) string {
+	if d.secret.check(challenge, response) {
+		return "vpn"
+	}
+	if t := d.getTunnelBySecret(challenge, response); t != nil {
+		return "tunnel " + t.name
+	}
+	return "invalid"
+}
+
+// checkRateLimit returns an error if sender exceeded the rate limit of limiter
+func checkRateLimit(limiter *rateLimiter, sender string) error {
+	if retryAfter, ok := limiter.allow(sender); !ok {
+		return &dbusapi.TooManyRequestsError{RetryAfter: retryAfter}
 	}
-	d.updateVPNConfigUp(configUpdate.Config)
+	return nil
 }
 
 // handleClientRequest handles a client request
 func (d *Daemon) handleClientRequest(request *api.Request) {
 	defer request.Close()
+	log := log.WithField(logging.RequestField, request.ID())
 	log.Debug("Daemon handling client request")
 
+	// skip requests that the client canceled or that exceeded their
+	// deadline while waiting, e.g., behind a hanging request
+	if err := request.Context().Err(); err != nil {
+		log.WithError(err).Error("Daemon skipped canceled client request")
+		return
+	}
+
+	// record metrics before the request is closed, its data is only
+	// valid until then
+	start := time.Now()
+	defer func() {
+		d.observeRequest(vpnstatus.APISocket, socketRequestName(request.Type()),
+			request.ID(), start, request.Failed(), []any{request.Data()})
+	}()
+
 	switch request.Type() {
 	case api.TypeVPNConfigUpdate:
 		// update VPN config
@@ -410,8 +1160,52 @@ func (d *Daemon) handleClientRequest(request *api.Request) {
 
 // handleDBusRequest handles a D-Bus API client request
 func (d *Daemon) handleDBusRequest(request *dbusapi.Request) {
+	start := time.Now()
+
+	// reconnect requests are completed when the reconnect finished,
+	// device connect requests when the device code is received and list
+	// servers requests with ping when the servers were probed, their
+	// metrics are recorded when they are completed
+	pending := &pendingRequest{request: request, start: start}
+	switch request.Name {
+	case dbusapi.RequestReconnect:
+		d.handleReconnectRequest(pending)
+		return
+	case dbusapi.RequestConnectDevice:
+		d.handleConnectDeviceRequest(pending)
+		return
+	case dbusapi.RequestListServers:
+		if request.Parameters[0].(bool) {
+			d.handleListServersPing(pending)
+			return
+		}
+	}
+	defer func() {
+		d.observeRequest(vpnstatus.APIDBus, request.Name, request.ID, start,
+			request.Error != nil, request.Parameters)
+	}()
+
 	defer request.Close()
-	log.Debug("Daemon handling D-Bus client request")
+	log := log.WithField(logging.RequestField, request.ID)
+	log.WithFields(logrus.Fields{
+		"method": request.Name,
+		"sender": request.Sender,
+		"uid":    request.UID,
+	}).Debug("Daemon handling D-Bus client request")
+
+	// check rate limit of connect requests, the user is the sender
+	// because the bus name of clients changes with every client run
+	switch request.Name {
+	case dbusapi.RequestConnect, dbusapi.RequestConnectTunnel,
+		dbusapi.RequestConnectCached:
+		sender := fmt.Sprintf("uid %d", request.UID)
+		if err := checkRateLimit(d.connectLimiter, sender); err != nil {
+			log.WithError(err).WithField("sender", request.Sender).
+				Error("Daemon rejected connect request")
+			request.Error = err
+			return
+		}
+	}
 
 	switch request.Name {
 	case dbusapi.RequestConnect:
@@ -421,6 +1215,7 @@ func (d *Daemon) handleDBusRequest(request *dbusapi.Request) {
 		connectURL := request.Parameters[2].(string)
 		fingerprint := request.Parameters[3].(string)
 		resolve := request.Parameters[4].(string)
+		profile := request.Parameters[5].(string)
 
 		login := &logininfo.LoginInfo{
 			Cookie:      cookie,
@@ -430,12 +1225,250 @@ func (d *Daemon) handleDBusRequest(request *dbusapi.Request) {
 			Resolve:     resolve,
 		}
 
-		// connect VPN
-		d.connectVPN(login)
+		// select profile if not connected
+		if !d.status.OCRunning.Running() {
+			if err := d.selectProfile(profile); err != nil {
+				log.WithError(err).Error("Daemon could not select XML profile")
+				request.Error = err
+				return
+			}
+		}
+
+		// connect VPN, save login info for reconnects
+		d.logAudit(audit.EventConnect, request.Sender, request.UID, host)
+		d.reconnect.reset()
+		d.reconnect.setLogin(login)
+		d.reconnect.setLoginUID(request.UID)
+		if err := d.connectVPN(login); err != nil {
+			log.WithField(logging.CodeField, logging.CodeConnectFailed).
+				WithError(err).Error("Daemon could not connect VPN")
+			request.Error = err
+			return
+		}
+		d.setStatusOwner(request.UID)
+
+	case dbusapi.RequestConnectCached:
+		// connect VPN with login info of last connection attempt
+		if err := d.connectCached(request); err != nil {
+			log.WithField(logging.CodeField, logging.CodeConnectCachedFailed).
+				WithError(err).Error("Daemon could not connect VPN with cached login")
+			request.Error = err
+		}
+
+	case dbusapi.RequestConnectTunnel:
+		// connect additional tunnel
+		login := &logininfo.LoginInfo{
+			Cookie:      request.Parameters[0].(string),
+			Host:        request.Parameters[1].(string),
+			ConnectURL:  request.Parameters[2].(string),
+			Fingerprint: request.Parameters[3].(string),
+			Resolve:     request.Parameters[4].(string),
+		}
+		profile := request.Parameters[5].(string)
+		name := request.Parameters[6].(string)
+		d.logAudit(audit.EventConnect, request.Sender, request.UID,
+			fmt.Sprintf("%s (tunnel %s)", login.Host, name))
+		if err := d.connectTunnel(name, login, profile); err != nil {
+			log.WithError(err).WithField("tunnel", name).
+				Error("Daemon could not connect tunnel")
+			request.Error = err
+		}
+
+	case dbusapi.RequestDisconnectTunnel:
+		// disconnect additional tunnel
+		name := request.Parameters[0].(string)
+		d.logAudit(audit.EventDisconnect, request.Sender, request.UID,
+			fmt.Sprintf("tunnel %s", name))
+		if err := d.disconnectTunnel(name); err != nil {
+			log.WithError(err).WithField("tunnel", name).
+				Error("Daemon could not disconnect tunnel")
+			request.Error = err
+		}
 
 	case dbusapi.RequestDisconnect:
 		// diconnect VPN
-		d.disconnectVPN()
+		if err := d.checkDisconnect(request); err != nil {
+			request.Error = err
+			return
+		}
+		d.logAudit(audit.EventDisconnect, request.Sender, request.UID, "")
+		if err := d.disconnectVPN(); err != nil {
+			log.WithField(logging.CodeField, logging.CodeDisconnectFailed).
+				WithError(err).Error("Daemon could not disconnect VPN")
+			request.Error = err
+		}
+
+	case dbusapi.RequestCancel:
+		// cancel connection attempt
+		if err := d.checkDisconnect(request); err != nil {
+			request.Error = err
+			return
+		}
+		d.logAudit(audit.EventDisconnect, request.Sender, request.UID, "cancel")
+		if err := d.cancelVPN(); err != nil {
+			log.WithError(err).Error("Daemon could not cancel VPN connection")
+			request.Error = err
+		}
+
+	case dbusapi.RequestPause:
+		// pause VPN connection
+		if err := d.checkDisconnect(request); err != nil {
+			request.Error = err
+			return
+		}
+		timeout := time.Duration(request.Parameters[0].(uint32)) * time.Second
+		if err := d.pauseVPN(timeout); err != nil {
+			log.WithError(err).Error("Daemon could not pause VPN")
+			request.Error = err
+			return
+		}
+		d.logAudit(audit.EventPause, request.Sender, request.UID,
+			fmt.Sprintf("paused (timeout: %s)", timeout))
+
+	case dbusapi.RequestResume:
+		// resume paused VPN connection
+		if err := d.resumeVPN(); err != nil {
+			log.WithError(err).Error("Daemon could not resume VPN")
+			request.Error = err
+			return
+		}
+		d.logAudit(audit.EventPause, request.Sender, request.UID, "resumed")
+
+	case dbusapi.RequestSetSchedule:
+		// enable or disable connection schedule
+		enabled := request.Parameters[0].(bool)
+		log.WithField("enabled", enabled).Info("Daemon setting connection schedule")
+		d.scheduleEnabled = enabled
+		d.checkSchedule()
+
+	case dbusapi.RequestSetTrafPol:
+		// enable or disable traffic policing
+		enabled := request.Parameters[0].(bool)
+		timeout := time.Duration(request.Parameters[1].(uint32)) * time.Second
+		details := "enabled"
+		if !enabled {
+			details = fmt.Sprintf("disabled (timeout: %s)", timeout)
+		}
+		d.logAudit(audit.EventTrafPol, request.Sender, request.UID, details)
+		if err := d.setTrafPol(enabled, timeout); err != nil {
+			log.WithError(err).Error("Daemon could not set traffic policing")
+			request.Error = err
+		}
+
+	case dbusapi.RequestSetNotificationPolicy:
+		// set notification policy until next config reload
+		policy := request.Parameters[0].(string)
+		if err := d.setNotificationPolicy(policy); err != nil {
+			log.WithError(err).Error("Daemon could not set notification policy")
+			request.Error = err
+		}
+
+	case dbusapi.RequestReportHostscan:
+		// log hostscan output of authentication
+		if err := d.reportHostscan(request); err != nil {
+			log.WithError(err).Error("Daemon could not handle hostscan report")
+			request.Error = err
+		}
+
+	case dbusapi.RequestSetTND:
+		// enable or disable trusted network detection
+		enabled := request.Parameters[0].(bool)
+		timeout := time.Duration(request.Parameters[1].(uint32)) * time.Second
+		d.setTND(enabled, timeout)
+
+	case dbusapi.RequestDoctor:
+		// run self-check
+		problems := []dbusapi.Problem{}
+		for _, p := range Doctor() {
+			problems = append(problems, dbusapi.Problem{
+				Check:   p.Check,
+				Message: p.Message,
+			})
+		}
+		request.Results = []any{problems}
+
+	case dbusapi.RequestGetLogs:
+		// get recent log entries
+		lines := request.Parameters[0].(uint32)
+		request.Results = []any{logging.Recent(int(lines))}
+
+	case dbusapi.RequestListServers:
+		// list vpn servers in xml profile without ping
+		request.Results = []any{d.listServers()}
+
+	case dbusapi.RequestGetUsage:
+		// get vpn usage per month
+		request.Results = []any{d.usage.list()}
+
+	case dbusapi.RequestGetMetrics:
+		// get metrics of api requests
+		request.Results = []any{d.metricsList()}
+
+	case dbusapi.RequestSetPreferredServer:
+		// set preferred vpn server in xml profile
+		server := request.Parameters[0].(string)
+		if err := d.setPreferredServer(server); err != nil {
+			log.WithError(err).Error("Daemon could not set preferred VPN server")
+			request.Error = err
+		}
+
+	case dbusapi.RequestAddSplitExclude:
+		// add split exclude to current vpn connection
+		address := request.Parameters[0].(string)
+		if err := d.addSplitExclude(address); err != nil {
+			log.WithError(err).Error("Daemon could not add split exclude")
+			request.Error = err
+			return
+		}
+		d.logAudit(audit.EventSplitExclude, request.Sender, request.UID,
+			"add "+address)
+
+	case dbusapi.RequestRemoveSplitExclude:
+		// remove split exclude from current vpn connection
+		address := request.Parameters[0].(string)
+		if err := d.removeSplitExclude(address); err != nil {
+			log.WithError(err).Error("Daemon could not remove split exclude")
+			request.Error = err
+			return
+		}
+		d.logAudit(audit.EventSplitExclude, request.Sender, request.UID,
+			"remove "+address)
+
+	case dbusapi.RequestListSplitExcludes:
+		// list split excludes of current vpn connection
+		request.Results = []any{d.listSplitExcludes()}
+
+	case dbusapi.RequestValidateConfig:
+		// validate candidate config or xml profile without applying it
+		kind := request.Parameters[0].(string)
+		data := request.Parameters[1].(string)
+		findings, err := d.validate(kind, []byte(data))
+		if err != nil {
+			request.Error = err
+			return
+		}
+		request.Results = []any{findings}
+
+	case dbusapi.RequestGetStatus:
+		// get complete vpn status as json
+		b, err := d.status.JSON()
+		if err != nil {
+			log.WithError(err).Error("Daemon could not convert status to JSON")
+			request.Error = err
+			return
+		}
+		request.Results = []any{string(b)}
+
+	case dbusapi.RequestReloadProfile:
+		// read xml profile again
+		if err := d.reloadProfile(); err != nil {
+			log.WithField(logging.CodeField, logging.CodeProfileReloadFailed).
+				WithError(err).Error("Daemon could not reload XML profile")
+			request.Error = err
+			return
+		}
+		d.logAudit(audit.EventProfileUpdate, request.Sender, request.UID,
+			d.profileName)
 	}
 }
 
@@ -463,17 +1496,77 @@ func (d *Daemon) checkDisconnectVPN() {
 	if d.status.TrustedNetwork.Trusted() && d.status.OCRunning.Running() {
 		// disconnect VPN when switching from untrusted network with
 		// active VPN connection to a trusted network
-		log.Info("Daemon detected trusted network, disconnecting VPN connection")
-		d.disconnectVPN()
+		log.WithField(logging.CodeField, logging.CodeTrustedNetwork).
+			Info("Daemon detected trusted network, disconnecting VPN connection")
+		d.logAuditDaemon(audit.EventDisconnect, "trusted network")
+		if err := d.disconnectVPN(); err != nil {
+			log.WithField(logging.CodeField, logging.CodeDisconnectFailed).
+				WithError(err).Error("Daemon could not disconnect VPN")
+		}
 	}
 }
 
 // handleTNDResult handles a TND result
 func (d *Daemon) handleTNDResult(trusted bool) {
 	log.WithField("trusted", trusted).Debug("Daemon handling TND result")
+	untrusted := !trusted &&
+		d.status.TrustedNetwork != vpnstatus.TrustedNetworkNotTrusted
 	d.setStatusTrustedNetwork(trusted)
 	d.checkDisconnectVPN()
-	d.checkTrafPol()
+	if err := d.checkTrafPol(); err != nil {
+		log.WithField(logging.CodeField, logging.CodeTrafPolFailed).
+			WithError(err).Error("Daemon could not start traffic policing")
+	}
+	d.checkProxy()
+
+	// connect on untrusted network during scheduled connect windows
+	if untrusted && d.status.ScheduleState == vpnstatus.ScheduleStateConnect {
+		d.scheduleConnect()
+	}
+}
+
+// checkProxy checks if proxy auto-detection should run: on untrusted
+// networks it triggers a probe, on trusted networks it resets the proxy
+func (d *Daemon) checkProxy() {
+	if d.status.TrustedNetwork.Trusted() {
+		d.updateProxy("")
+		return
+	}
+	d.wpad.Probe()
+}
+
+// updateProxy updates the detected proxy
+func (d *Daemon) updateProxy(proxy string) {
+	if d.status.Proxy == proxy {
+		return
+	}
+	d.setStatusProxy(proxy)
+
+	// proxy host must be in allowed hosts if it is used for the
+	// vpn connection, so restart traffic policing
+	if d.config.AutoProxy {
+		d.stopTrafPol()
+		if err := d.checkTrafPol(); err != nil {
+			log.WithField(logging.CodeField, logging.CodeTrafPolFailed).
+				WithError(err).Error("Daemon could not start traffic policing")
+		}
+	}
+}
+
+// handleWPADReport handles a proxy auto-detection report
+func (d *Daemon) handleWPADReport(r *wpad.Report) {
+	log.WithField("report", r).Debug("Daemon handling WPAD report")
+	if d.status.TrustedNetwork.Trusted() {
+		// ignore reports on trusted networks
+		return
+	}
+	if r.Detected {
+		log.WithFields(logrus.Fields{
+			"url":   r.URL,
+			"proxy": r.Proxy,
+		}).Info("Daemon detected proxy on untrusted network")
+	}
+	d.updateProxy(r.Proxy)
 }
 
 // handleRunnerDisconnect handles a disconnect event from the OC runner,
@@ -481,11 +1574,43 @@ func (d *Daemon) handleTNDResult(trusted bool) {
 func (d *Daemon) handleRunnerDisconnect() {
 	// make sure running and connected are not set
 	d.setStatusOCRunning(false)
-	d.setStatusConnectionState(vpnstatus.ConnectionStateDisconnected)
+	if err := d.state.transition(vpnstatus.ConnectionStateDisconnected); err != nil {
+		log.WithField(logging.CodeField, logging.CodeRunnerDisconnectFailed).
+			WithError(err).Error("Daemon runner disconnect error")
+	}
 	d.setStatusConnectedAt(0)
+	d.setStatusCompression(dbusapi.CompressionInvalid)
 
 	// make sure the vpn config is not active any more
-	d.updateVPNConfigDown()
+	if err := d.updateVPNConfigDown(); err != nil {
+		log.WithField(logging.CodeField, logging.CodeVPNConfigDownFailed).
+			WithError(err).Error("Daemon config down error")
+	}
+
+	// connection ended, secret of the connection is not valid any more
+	d.secret.invalidate()
+}
+
+// handleRunnerConnectFailure handles a connection attempt that failed
+// before openconnect was started, it does not retry the connection because
+// it would fail again
+func (d *Daemon) handleRunnerConnectFailure(err error) {
+	log.WithField(logging.CodeField, logging.CodeConnectFailed).
+		WithError(err).Error("Daemon could not start VPN connection")
+	d.handleRunnerDisconnect()
+	d.finishReconnect(err)
+	d.setStatusOwner(dbusapi.OwnerUIDInvalid)
+	d.disconnectRequested = false
+	d.reconnectAfterDisconnect = false
+}
+
+// handleConnectionDrop handles an unexpected drop of the VPN connection, it
+// informs desktop components and locks all sessions if configured
+func (d *Daemon) handleConnectionDrop() {
+	log.WithField(logging.CodeField, logging.CodeConnectionDropped).
+		Warn("Daemon detected VPN connection drop")
+	d.emitSignal(dbusapi.SignalConnectionDropped)
+	d.publish(&eventbus.ConnectionDropped{Reason: d.status.DisconnectReason})
 }
 
 // handleRunnerEvent handles a connect event from the OC runner
@@ -493,28 +1618,259 @@ func (d *Daemon) handleRunnerEvent(e *ocrunner.ConnectEvent) {
 	log.WithField("event", e).Debug("Daemon handling Runner event")
 
 	if e.Connect {
-		// make sure running is set
+		// make sure running is set, log exact command line of
+		// openconnect with quoted arguments
 		d.setStatusOCRunning(true)
+		d.logAuditDaemon(audit.EventOpenConnect, fmt.Sprintf("%q", e.Command))
+		return
+	}
+	if e.Err != nil {
+		d.handleRunnerConnectFailure(e.Err)
 		return
 	}
 
-	// clean up after disconnect
+	// clean up after disconnect, check if connection dropped before
+	dropped := !d.disconnectRequested &&
+		d.state.get() == vpnstatus.ConnectionStateConnected
 	d.handleRunnerDisconnect()
+	if e.Reason != ocrunner.ReasonNone {
+		log.WithField(logging.CodeField, logging.CodeGatewayDisconnect).
+			WithField("reason", e.Reason).
+			Info("Daemon got disconnect from VPN gateway")
+	}
+	d.setStatusDisconnectReason(string(e.Reason))
+	if dropped {
+		d.handleConnectionDrop()
+	}
+
+	// reconnect after requested disconnect, e.g., after resume
+	if d.reconnectAfterDisconnect {
+		d.reconnectAfterDisconnect = false
+		d.disconnectRequested = false
+		d.reconnectVPN()
+		return
+	}
+
+	// reconnect requested with D-Bus failed, the login info was
+	// probably rejected, so do not retry with it
+	if len(d.reconnectRequests) > 0 {
+		d.requireReauth()
+		d.disconnectRequested = true
+	}
+
+	// reconnect after unexpected disconnect, connection ended after
+	// requested disconnect
+	if !d.disconnectRequested {
+		d.checkReconnect(e.Reason)
+	} else {
+		d.setStatusOwner(dbusapi.OwnerUIDInvalid)
+	}
+	d.disconnectRequested = false
+}
+
+// reconnectVPN reconnects the VPN with the login info of the last
+// connection, if the network is not trusted
+func (d *Daemon) reconnectVPN() {
+	if d.status.OCRunning.Running() ||
+		d.status.TrustedNetwork.Trusted() {
+		// reconnect not needed
+		d.finishReconnect(errReconnectNotNeeded)
+		return
+	}
+
+	log.WithField(logging.CodeField, logging.CodeReconnecting).
+		Info("Daemon reconnecting VPN")
+	d.reconnect.reset()
+	d.logAuditDaemon(audit.EventConnect, d.reconnect.getLogin().Host)
+	if err := d.connectVPN(d.reconnect.getLogin()); err != nil {
+		log.WithField(logging.CodeField, logging.CodeReconnectFailed).
+			WithError(err).Error("Daemon could not reconnect VPN")
+		d.finishReconnect(err)
+	}
+}
+
+// checkReconnect checks if we should try to reconnect the VPN after an
+// unexpected disconnect with reason and schedules the reconnect attempt
+func (d *Daemon) checkReconnect(reason ocrunner.DisconnectReason) {
+	if d.status.TrustedNetwork.Trusted() {
+		return
+	}
+	if !d.config.ReconnectPolicy.Enabled {
+		log.WithField(logging.CodeField, logging.CodeUnexpectedDisconnect).
+			Info("Daemon detected unexpected VPN disconnect")
+		return
+	}
+	if d.config.ReconnectPolicy.TransientOnly && !reason.Transient() {
+		log.WithField(logging.CodeField, logging.CodeGatewayDisconnect).
+			WithField("reason", reason).
+			Info("Daemon detected VPN disconnect by gateway, not reconnecting")
+		return
+	}
+	delay, ok := d.reconnect.schedule()
+	d.setStatusRetry()
+	if !ok {
+		log.WithField(logging.CodeField, logging.CodeReconnectGivenUp).
+			WithField("attempts", d.reconnect.attempts).
+			Error("Daemon detected unexpected VPN disconnect, " +
+				"giving up reconnecting")
+		return
+	}
+	log.WithFields(logrus.Fields{
+		logging.CodeField: logging.CodeUnexpectedDisconnect,
+		"attempt":         d.reconnect.attempts,
+		"delay":           delay,
+	}).Info("Daemon detected unexpected VPN disconnect, scheduled reconnect")
+}
+
+// handleReconnect handles the reconnect timer and tries to reconnect the VPN
+func (d *Daemon) handleReconnect() {
+	d.reconnect.expired()
+	d.setStatusRetry()
+	if d.status.OCRunning.Running() ||
+		d.status.TrustedNetwork.Trusted() {
+		// reconnect not needed anymore
+		return
+	}
+
+	log.WithField("attempt", d.reconnect.attempts).
+		Info("Daemon trying to reconnect VPN")
+	d.logAuditDaemon(audit.EventConnect, d.reconnect.getLogin().Host)
+	if err := d.connectVPN(d.reconnect.getLogin()); err != nil {
+		log.WithField(logging.CodeField, logging.CodeReconnectFailed).
+			WithError(err).Error("Daemon could not reconnect VPN")
+	}
+}
+
+// handleConnectivity handles a connectivity change, it connects the VPN
+// with the login of a connect rejected while offline in always-on mode when
+// connectivity returns
+func (d *Daemon) handleConnectivity(online bool) {
+	log.WithField(logging.CodeField, logging.CodeConnectivityChanged).
+		WithField("online", online).Info("Daemon got connectivity change")
+	connectivity := vpnstatus.ConnectivityOffline
+	if online {
+		connectivity = vpnstatus.ConnectivityOnline
+	}
+	d.setStatusConnectivity(connectivity)
+
+	login := d.offlineLogin
+	if !online || login == nil {
+		return
+	}
+	d.offlineLogin = nil
+	if d.status.OCRunning.Running() ||
+		d.status.TrustedNetwork.Trusted() {
+		// connect not needed anymore
+		return
+	}
+
+	log.Info("Daemon connecting VPN after connectivity returned")
+	d.logAuditDaemon(audit.EventConnect, login.Host)
+	if err := d.connectVPN(login); err != nil {
+		log.WithField(logging.CodeField, logging.CodeConnectFailed).
+			WithError(err).Error("Daemon could not connect VPN")
+	}
+}
+
+// checkSchedule checks the connection schedule, it connects the VPN when a
+// connect window starts and disconnects it when a forbid window starts
+func (d *Daemon) checkSchedule() {
+	schedule := &Schedule{
+		Enabled: d.scheduleEnabled,
+		Windows: d.config.Schedule.Windows,
+	}
+	state := schedule.state(d.scheduler.now())
+	if state == d.status.ScheduleState {
+		return
+	}
+	log.WithFields(logrus.Fields{
+		"from": d.status.ScheduleState,
+		"to":   state,
+	}).Info("Daemon connection schedule changed")
+	d.setStatusScheduleState(state)
+
+	switch state {
+	case vpnstatus.ScheduleStateConnect:
+		d.scheduleConnect()
+	case vpnstatus.ScheduleStateForbid:
+		d.scheduleDisconnect()
+	}
+}
+
+// scheduleConnect connects the VPN on untrusted networks with the login
+// info of the last connection for the connection schedule
+func (d *Daemon) scheduleConnect() {
+	login := d.reconnect.getLogin()
+	if d.status.OCRunning.Running() ||
+		d.status.TrustedNetwork.Trusted() ||
+		!login.Valid() {
+		// connect not needed or not possible
+		return
+	}
+
+	log.Info("Daemon connecting VPN for connection schedule")
+	d.logAuditDaemon(audit.EventConnect, login.Host)
+	if err := d.connectVPN(login); err != nil {
+		log.WithError(err).Error("Daemon could not connect VPN for connection schedule")
+	}
+}
+
+// scheduleDisconnect disconnects the VPN for the connection schedule
+func (d *Daemon) scheduleDisconnect() {
+	if !d.status.OCRunning.Running() {
+		return
+	}
+
+	log.Info("Daemon disconnecting VPN for connection schedule")
+	d.logAuditDaemon(audit.EventDisconnect, "schedule")
+	if err := d.disconnectVPN(); err != nil {
+		log.WithError(err).Error("Daemon could not disconnect VPN for connection schedule")
+	}
+}
+
+// handleScheduleTimer handles the scheduler timer and checks the connection
+// schedule
+func (d *Daemon) handleScheduleTimer() {
+	d.scheduler.start()
+	d.checkSchedule()
 }
 
 // handleSleepMonEvent handles a suspend/resume event from SleepMon
 func (d *Daemon) handleSleepMonEvent(sleep bool) {
 	log.WithField("sleep", sleep).Debug("Daemon handling SleepMon event")
 
-	// disconnect vpn on resume
-	if !sleep && d.status.OCRunning.Running() {
-		d.disconnectVPN()
+	if sleep || !d.status.OCRunning.Running() {
+		return
+	}
+
+	// disconnect vpn on resume, reconnect it if configured and possible.
+	// If the reconnect fails, the vpn stays disconnected unless the
+	// reconnect policy retries it
+	if d.config.ReconnectOnResume &&
+		!d.status.TrustedNetwork.Trusted() &&
+		d.reconnect.getLogin().Valid() {
+
+		log.WithField(logging.CodeField, logging.CodeResumeReconnect).
+			Info("Daemon detected resume, reconnecting VPN")
+		d.logAuditDaemon(audit.EventDisconnect, "resume")
+		if err := d.disconnectVPN(); err != nil {
+			log.WithField(logging.CodeField, logging.CodeDisconnectFailed).
+				WithError(err).Error("Daemon could not disconnect VPN")
+			return
+		}
+		d.reconnectAfterDisconnect = true
+		return
+	}
+	d.logAuditDaemon(audit.EventDisconnect, "resume")
+	if err := d.disconnectVPN(); err != nil {
+		log.WithField(logging.CodeField, logging.CodeDisconnectFailed).
+			WithError(err).Error("Daemon could not disconnect VPN")
 	}
 }
 
 // readXMLProfile reads the XML profile from file
-func readXMLProfile() *xmlprofile.Profile {
-	profile, err := xmlprofile.LoadProfile(xmlProfile)
+func readXMLProfile(file string) *xmlprofile.Profile {
+	profile, err := xmlprofile.LoadProfile(file)
 	if err != nil {
 		// invalid config, use empty config
 		log.WithError(err).Error("Could not read XML profile")
@@ -526,31 +1882,72 @@ func readXMLProfile() *xmlprofile.Profile {
 // handleProfileUpdate handles a xml profile update
 func (d *Daemon) handleProfileUpdate() {
 	log.Debug("Daemon handling XML profile update")
-	d.profile = readXMLProfile()
+	d.logAuditDaemon(audit.EventProfileUpdate, d.profileName)
+	d.applyProfile(readXMLProfile(profilePath(d.profileName)))
+}
+
+// applyProfile sets the xml profile to profile and applies its settings
+func (d *Daemon) applyProfile(profile *xmlprofile.Profile) {
+	d.profile = profile
 	d.stopTND()
 	d.stopTrafPol()
-	d.checkTrafPol()
+	if err := d.checkTrafPol(); err != nil {
+		log.WithField(logging.CodeField, logging.CodeTrafPolFailed).
+			WithError(err).Error("Daemon could not start traffic policing")
+	}
 	d.checkTND()
 	d.setStatusServers(d.profile.GetVPNServerHostNames())
+	d.setStatusPreferredServer(d.preferredServer())
+	d.publish(&eventbus.ProfileChanged{Name: d.profileName})
+}
+
+// handleReload handles a config reload, it also reloads the xml profile
+func (d *Daemon) handleReload(config *Config) {
+	log.WithField(logging.CodeField, logging.CodeReloading).
+		WithField("config", config).Info("Daemon reloading config and XML profile")
+	d.config = config
+	if config.AuditLog != d.auditTarget {
+		d.openAuditLog()
+	}
+	d.reconnect.setPolicy(&config.ReconnectPolicy)
+	d.dns.SetFallback(dnsProxyFallback(&config.DNSProxy))
+	d.checkSessionLocker()
+	d.scheduleEnabled = config.Schedule.Enabled
+	d.checkSchedule()
+	d.notifier.setPolicy(&config.Notifications)
+	d.startStats()
+	d.startIdle()
+	d.startSession()
+	d.setStatusCSDWrapper()
+	d.setCapabilities()
+	d.handleProfileUpdate()
+	d.checkProxy()
 }
 
-// cleanup cleans up after a failed shutdown
+// cleanup cleans up after a failed shutdown with the manifest of the system
+// changes of the last run; without manifest, e.g., after an update from a
+// version without manifest, it cleans up the default names: split routing
+// and traffic policing are only cleaned up if they are not used by another
+// daemon instance with the default settings
 func (d *Daemon) cleanup() {
 	ocrunner.CleanupConnect()
-	cleanupVPNConfig(vpnDevice)
-	splitrt.Cleanup()
-	trafpol.Cleanup()
-}
+	for _, t := range d.tunnels {
+		ocrunner.CleanupConnectPIDFile(t.pidFile())
+	}
+	if cleanupManifest() {
+		return
+	}
 
-// initToken creates the daemon token for client authentication
-func (d *Daemon) initToken() {
-	// TODO: is this good enough for us?
-	b := make([]byte, 16)
-	_, err := rand.Read(b)
-	if err != nil {
-		log.WithError(err).Fatal("Daemon could not init token")
+	cleanupVPNConfig(vpnDevice)
+	for _, t := range d.tunnels {
+		t.cleanup()
+	}
+	if vpnDevice == defaultVPNDevice {
+		splitrt.Cleanup()
+	}
+	if !noTrafPol {
+		cleanupTrafPol()
 	}
-	d.token = base64.RawURLEncoding.EncodeToString(b)
 }
 
 // getAllowedHosts returns the allowed hosts
@@ -561,12 +1958,21 @@ func (d *Daemon) getAllowedHosts() (hosts []string) {
 	// add tnd servers to allowed hosts
 	hosts = append(hosts, d.profile.GetTNDServers()...)
 
-	// add cpd servers to allowed hosts
-	hosts = append(hosts, cpdServers...)
+	// add cpd servers from config and xml profile to allowed hosts
+	hosts = append(hosts, d.config.CPDServers...)
+	hosts = append(hosts, d.profile.GetCPDServers()...)
 
 	// add allowed hosts from xml profile to allowed hosts
 	hosts = append(hosts, d.profile.GetAllowedHosts()...)
 
+	// add detected proxy to allowed hosts if it is used
+	if d.config.AutoProxy && d.status.Proxy != "" {
+		if u, err := url.Parse(d.status.Proxy); err == nil &&
+			u.Hostname() != "" {
+			hosts = append(hosts, u.Hostname())
+		}
+	}
+
 	return
 }
 
@@ -578,13 +1984,14 @@ func (d *Daemon) initTNDServers() {
 	}
 }
 
-// setTNDDialer sets a custom dialer for TND
-func (d *Daemon) setTNDDialer() {
+// newMarkDialer returns a dialer that sets the firewall mark on its sockets,
+// so its traffic is excluded from the vpn tunnel
+func newMarkDialer() *net.Dialer {
 	// get mark to be set on socket
 	mark, err := strconv.Atoi(splitrt.FWMark)
 	if err != nil {
 		log.WithError(err).Error("Daemon could not convert FWMark to int")
-		return
+		return nil
 	}
 
 	// control function that sets socket option on raw connection
@@ -599,7 +2006,7 @@ func (d *Daemon) setTNDDialer() {
 				mark,
 			)
 			if soerr != nil {
-				log.WithError(soerr).Error("TND could not set SO_MARK")
+				log.WithError(soerr).Error("Daemon could not set SO_MARK")
 			}
 		}
 
@@ -609,10 +2016,18 @@ func (d *Daemon) setTNDDialer() {
 		return soerr
 	}
 
-	// create and set dialer
-	dialer := &net.Dialer{
+	// create dialer
+	return &net.Dialer{
 		Control: control,
 	}
+}
+
+// setTNDDialer sets a custom dialer for TND
+func (d *Daemon) setTNDDialer() {
+	dialer := newMarkDialer()
+	if dialer == nil {
+		return
+	}
 	d.tnd.SetDialer(dialer)
 }
 
@@ -636,8 +2051,40 @@ func (d *Daemon) stopTND() {
 	d.tnd = nil
 }
 
+// setTND enables or disables TND, a disabled TND is enabled again after
+// timeout if timeout is not 0
+func (d *Daemon) setTND(enabled bool, timeout time.Duration) {
+	log.WithFields(logrus.Fields{
+		"enabled": enabled,
+		"timeout": timeout,
+	}).Info("Daemon setting trusted network detection")
+	if enabled {
+		d.tndToggle.enable()
+	} else {
+		d.tndToggle.disable(timeout)
+	}
+	d.checkTND()
+}
+
+// handleTNDTimeout handles the timeout of a disabled TND
+func (d *Daemon) handleTNDTimeout() {
+	log.Info("Daemon enabling trusted network detection after timeout")
+	d.tndToggle.enable()
+	d.checkTND()
+}
+
 // checkTND checks if TND should be running and starts or stops it
 func (d *Daemon) checkTND() {
+	if d.tndToggle.disabled {
+		// without TND, the network is not trusted
+		d.setStatusTNDState(vpnstatus.TNDStateDisabled)
+		d.stopTND()
+		if d.status.TrustedNetwork.Trusted() {
+			d.handleTNDResult(false)
+		}
+		return
+	}
+	d.setStatusTNDState(vpnstatus.TNDStateEnabled)
 	if len(d.profile.GetTNDServers()) == 0 {
 		d.stopTND()
 		return
@@ -655,12 +2102,17 @@ func (d *Daemon) getTNDResults() chan bool {
 }
 
 // startTrafPol starts traffic policing if it's not running
-func (d *Daemon) startTrafPol() {
-	if d.trafpol != nil {
-		return
+func (d *Daemon) startTrafPol() error {
+	if !featureTrafPol || d.trafpol != nil {
+		return nil
 	}
-	d.trafpol = trafpol.NewTrafPol(d.getAllowedHosts())
-	d.trafpol.Start()
+	t := newTrafPol(d.getAllowedHosts())
+	if err := t.Start(d.ctx); err != nil {
+		return err
+	}
+	d.trafpol = t
+	d.logAuditDaemon(audit.EventTrafPol, "started")
+	return nil
 }
 
 // stopTrafPol stops traffic policing if it's running
@@ -670,78 +2122,254 @@ func (d *Daemon) stopTrafPol() {
 	}
 	d.trafpol.Stop()
 	d.trafpol = nil
+	d.logAuditDaemon(audit.EventTrafPol, "stopped")
+}
+
+// setTrafPol enables or disables traffic policing, a disabled traffic
+// policing is enabled again after timeout if timeout is not 0
+func (d *Daemon) setTrafPol(enabled bool, timeout time.Duration) error {
+	log.WithFields(logrus.Fields{
+		"enabled": enabled,
+		"timeout": timeout,
+	}).Info("Daemon setting traffic policing")
+	if enabled {
+		d.trafPolToggle.enable()
+	} else {
+		d.trafPolToggle.disable(timeout)
+	}
+	return d.checkTrafPol()
+}
+
+// handleTrafPolTimeout handles the timeout of a disabled traffic policing
+func (d *Daemon) handleTrafPolTimeout() {
+	log.Info("Daemon enabling traffic policing after timeout")
+	d.trafPolToggle.enable()
+	if err := d.checkTrafPol(); err != nil {
+		log.WithField(logging.CodeField, logging.CodeTrafPolFailed).
+			WithError(err).Error("Daemon could not start traffic policing")
+	}
+}
+
+// trafPolState returns the current state of traffic policing
+func (d *Daemon) trafPolState() vpnstatus.TrafPolState {
+	switch {
+	case d.trafPolToggle.disabled:
+		return vpnstatus.TrafPolStateDisabled
+	case d.trafpol != nil:
+		return vpnstatus.TrafPolStateActive
+	}
+	return vpnstatus.TrafPolStateInactive
 }
 
 // checkTrafPol checks if traffic policing should be running and
 // starts or stops it
-func (d *Daemon) checkTrafPol() {
-	// check if traffic policing is disabled in the daemon
-	if d.disableTrafPol {
+func (d *Daemon) checkTrafPol() error {
+	defer func() { d.setStatusTrafPolState(d.trafPolState()) }()
+
+	// check if traffic policing is disabled in the daemon or
+	// temporarily with D-Bus
+	if noTrafPol || d.disableTrafPol || d.trafPolToggle.disabled {
 		d.stopTrafPol()
-		return
+		return nil
 	}
 
 	// check if traffic policing is enabled in the xml profile
 	if !d.profile.GetAlwaysOn() {
 		d.stopTrafPol()
-		return
+		return nil
 	}
 
 	// check if we are connected to a trusted network
 	if d.status.TrustedNetwork.Trusted() {
 		d.stopTrafPol()
-		return
+		return nil
 	}
 
-	d.startTrafPol()
+	return d.startTrafPol()
 }
 
-// start starts the daemon
-func (d *Daemon) start() {
+// start starts the daemon, it reports the result of the startup to started
+func (d *Daemon) start(started chan<- error) {
 	defer close(d.closed)
 
-	// cleanup after a failed shutdown
+	// report startup errors after cleaning up
+	var err error
+	defer func() {
+		if err != nil {
+			started <- err
+		}
+	}()
+
+	// log features enabled at build time
+	log.WithField(logging.CodeField, logging.CodeDaemonStarting).
+		WithField("capabilities", Capabilities()).Info("Daemon starting")
+
+	// cleanup after a failed shutdown and record new system changes
 	d.cleanup()
+	openManifest()
+	defer manifest.Close()
 
-	// init token
-	d.initToken()
+	// remove stale runtime files now and periodically
+	d.handleRuntimeGC()
+	defer d.runtimeGC.stop()
+
+	// open audit log
+	d.openAuditLog()
+	defer d.closeAuditLog()
+
+	// start event bus consumers, close the event bus after the main loop
+	defer d.events.Close()
+	d.checkSessionLocker()
+	defer d.locker.stop()
+	d.usage.start()
+	defer d.usage.stop()
 
 	// start sleep monitor
-	d.sleepmon.Start()
+	if err = d.sleepmon.Start(d.ctx); err != nil {
+		err = fmt.Errorf("Daemon could not start sleep monitor: %w", err)
+		return
+	}
 	defer d.sleepmon.Stop()
 
+	// start connectivity monitor
+	if err = d.connectivity.start(d.ctx); err != nil {
+		err = fmt.Errorf("Daemon could not start connectivity monitor: %w", err)
+		return
+	}
+	defer d.connectivity.stop()
+
 	// start traffic policing
-	d.checkTrafPol()
 	defer d.stopTrafPol()
+	if err = d.checkTrafPol(); err != nil {
+		err = fmt.Errorf("Daemon could not start traffic policing: %w", err)
+		return
+	}
 
 	// start TND
 	d.checkTND()
 	defer d.stopTND()
 
-	// start DNS-Proxy
-	d.dns.Start()
+	// start DNS-Proxy, use the fallback resolver for names without
+	// remotes, e.g., if the VPN is not connected or uses split DNS
+	d.dns.SetFallback(dnsProxyFallback(&d.config.DNSProxy))
+	if mark, err := strconv.Atoi(splitrt.FWMark); err == nil {
+		// send queries for names without remotes outside the VPN
+		d.dns.SetFallbackMark(mark)
+	}
+	if err = d.dns.Start(d.ctx); err != nil {
+		err = fmt.Errorf("Daemon could not start DNS-Proxy: %w", err)
+		return
+	}
 	defer d.dns.Stop()
 
+	// start proxy auto-detection
+	d.wpad.SetDialer(newMarkDialer())
+	if err = d.wpad.Start(d.ctx); err != nil {
+		err = fmt.Errorf("Daemon could not start proxy auto-detection: %w", err)
+		return
+	}
+	defer d.wpad.Stop()
+
+	// stop pending reconnects and traffic statistics
+	defer d.reconnect.stop()
+	defer d.stopStats()
+
+	// run openconnect without root privileges if configured
+	if err = d.setupPrivilegeSeparation(); err != nil {
+		err = fmt.Errorf("Daemon could not set up privilege separation: %w", err)
+		return
+	}
+
 	// start OC runner
-	d.runner.Start()
+	if err = d.runner.Start(d.ctx); err != nil {
+		err = fmt.Errorf("Daemon could not start OC runner: %w", err)
+		return
+	}
 	defer d.handleRunnerDisconnect() // clean up vpn config
 	defer d.runner.Stop()
 
+	// start additional tunnels
+	for _, t := range d.tunnels {
+		if err = t.start(d.ctx, d.tunnelEvents, d.done); err != nil {
+			err = fmt.Errorf("Daemon could not start tunnel %s: %w", t.name, err)
+			return
+		}
+		defer t.stop()
+	}
+
 	// start unix server
-	d.server.Start()
+	if err = d.setupSocketGroup(); err != nil {
+		err = fmt.Errorf("Daemon could not set up socket group: %w", err)
+		return
+	}
+	if err = d.server.Start(d.ctx); err != nil {
+		err = fmt.Errorf("Daemon could not start unix server: %w", err)
+		return
+	}
 	defer d.server.Stop()
 
-	// start dbus api service
-	d.dbus.Start()
+	// start optional grpc api server
+	if d.grpc != nil {
+		if err = d.grpc.Start(d.ctx); err != nil {
+			err = fmt.Errorf("Daemon could not start gRPC server: %w", err)
+			return
+		}
+		defer d.grpc.Stop()
+	}
+
+	// start dbus api service, only use the socket api if the dbus api
+	// is optional and the service cannot be started, e.g., in containers
+	// without system bus
+	if err = d.dbus.Start(d.ctx); err != nil {
+		if d.config.DBusMode != DBusModeOptional {
+			err = fmt.Errorf("Daemon could not start D-Bus API service: %w", err)
+			return
+		}
+		log.WithField(logging.CodeField, logging.CodeDBusUnavailable).
+			WithError(err).Warn("Daemon could not start D-Bus API service, " +
+			"running without D-Bus API")
+		d.dbus = noDBusService{}
+		err = nil
+	}
 	defer d.dbus.Stop()
+	d.setStatusAPIs()
+	d.setCapabilities()
+	log.WithField(logging.CodeField, logging.CodeDaemonStarted).
+		WithField("apis", d.status.APIs).Info("Daemon started APIs")
 
 	// start xml profile monitor
-	d.profmon.Start()
-	defer d.profmon.Stop()
+	if err = d.profmon.Start(d.ctx); err != nil {
+		err = fmt.Errorf("Daemon could not start XML profile monitor: %w", err)
+		return
+	}
+	defer func() { d.profmon.Stop() }()
 
-	// set initial status
-	d.setStatusConnectionState(vpnstatus.ConnectionStateDisconnected)
+	// set initial state
+	if err = d.state.transition(vpnstatus.ConnectionStateDisconnected); err != nil {
+		return
+	}
 	d.setStatusServers(d.profile.GetVPNServerHostNames())
+	d.setStatusPreferredServer(d.preferredServer())
+	d.setStatusCSDWrapper()
+	d.setStatusPauseState(vpnstatus.PauseStateNotPaused)
+	d.checkProxy()
+
+	// stop device authorization on shutdown
+	defer d.stopDeviceAuth()
+
+	// start connection schedule
+	d.scheduler.start()
+	defer d.scheduler.stop()
+	defer d.tndToggle.stopTimer()
+	defer d.trafPolToggle.stopTimer()
+	defer d.pauseToggle.stopTimer()
+	defer d.idle.stop()
+	defer d.session.stop()
+	defer d.resolvConf.stop()
+	d.checkSchedule()
+
+	// startup complete
+	started <- nil
 
 	// run main loop
 	for {
@@ -752,6 +2380,9 @@ func (d *Daemon) start() {
 		case req := <-d.dbus.Requests():
 			d.handleDBusRequest(req)
 
+		case req := <-d.grpcRequests():
+			d.handleGRPCRequest(req)
+
 		case r := <-d.dns.Reports():
 			d.handleDNSReport(r)
 
@@ -761,48 +2392,198 @@ func (d *Daemon) start() {
 		case e := <-d.runner.Events():
 			d.handleRunnerEvent(e)
 
+		case e := <-d.tunnelEvents:
+			e.tunnel.handleRunnerEvent(e.event)
+
 		case e := <-d.sleepmon.Events():
 			d.handleSleepMonEvent(e)
 
+		case online := <-d.connectivity.resultsC():
+			d.handleConnectivity(online)
+
+		case r := <-d.wpad.Results():
+			d.handleWPADReport(r)
+
+		case <-d.reconnect.timerC():
+			d.handleReconnect()
+
+		case <-d.statsC():
+			d.updateStats()
+
+		case <-d.gateway.timerC():
+			d.handleGatewayTimer()
+
+		case r := <-d.gateway.resultsC():
+			d.handleGatewayResult(r)
+
+		case <-d.idle.timerC():
+			d.handleIdleCheck()
+
+		case <-d.runtimeGC.timerC():
+			d.handleRuntimeGC()
+
+		case <-d.session.timerC():
+			d.handleSessionTimer()
+
+		case <-d.resolvConf.updatesC():
+			d.handleResolvConfUpdate()
+
+		case <-d.tndToggle.timerC():
+			d.handleTNDTimeout()
+
+		case <-d.trafPolToggle.timerC():
+			d.handleTrafPolTimeout()
+
+		case <-d.pauseToggle.timerC():
+			d.handlePauseTimeout()
+
+		case <-d.scheduler.timerC():
+			d.handleScheduleTimer()
+
+		case c := <-d.deviceAuthCodes:
+			d.handleDeviceAuthCode(c)
+
+		case r := <-d.deviceAuthResults:
+			d.handleDeviceAuthResult(r)
+
+		case p := <-d.serverPings:
+			d.handleServerPing(p)
+
 		case <-d.profmon.Updates():
 			d.handleProfileUpdate()
 
+		case c := <-d.reloads:
+			d.handleReload(c)
+
+		case <-d.dumps:
+			d.handleDump()
+
 		case <-d.done:
 			return
 		}
 	}
 }
 
-// Start starts the daemon
-func (d *Daemon) Start() {
-	go d.start()
+// Start starts the daemon, it runs until Stop is called or ctx is canceled.
+// It returns an error if the daemon could not be started
+func (d *Daemon) Start(ctx context.Context) error {
+	d.ctx = ctx
+	ctx, d.cancel = context.WithCancel(ctx)
+	d.done = ctx.Done()
+
+	started := make(chan error)
+	go d.start(started)
+	return <-started
+}
+
+// Reload reloads the daemon with config and rereads the xml profile
+func (d *Daemon) Reload(config *Config) {
+	select {
+	case d.reloads <- config:
+	case <-d.done:
+	}
+}
+
+// Dump logs a debug dump of the daemon with its goroutines, status and
+// active rules
+func (d *Daemon) Dump() {
+	select {
+	case d.dumps <- struct{}{}:
+	case <-d.done:
+	}
 }
 
-// Stop stops the daemon
-func (d *Daemon) Stop() {
+// Stop stops the daemon, it returns an error if the daemon did not
+// shut down before ctx is done
+func (d *Daemon) Stop(ctx context.Context) error {
 	// stop daemon and wait for main loop termination
-	close(d.done)
-	<-d.closed
+	d.cancel()
+	select {
+	case <-d.closed:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("Daemon shutdown error: %w", ctx.Err())
+	}
 }
 
 // NewDaemon returns a new Daemon
-func NewDaemon() *Daemon {
-	return &Daemon{
+func NewDaemon(config *Config) *Daemon {
+	dnsAddr := dnsProxyAddress(&config.DNSProxy)
+	d := &Daemon{
+		config: config,
+
 		server: api.NewServer(sockFile),
-		dbus:   dbusapi.NewService(),
+		dbus:   newDBusService(),
 
 		sleepmon: sleepmon.NewSleepMon(),
 
-		dns: dnsproxy.NewProxy(dnsAddr),
+		connectivity: newConnectivity(),
+
+		wpad: wpad.NewWPAD(),
+
+		dns:     newDNSProxy(dnsAddr),
+		dnsAddr: dnsAddr,
 
 		runner: ocrunner.NewConnect(xmlProfile, vpncScript, vpnDevice),
 
 		status: vpnstatus.New(),
 
-		done:   make(chan struct{}),
+		reconnect: newReconnect(&config.ReconnectPolicy),
+
+		deviceAuthCodes:   make(chan *deviceAuthCode),
+		deviceAuthResults: make(chan *deviceAuthResult),
+		serverPings:       make(chan *serverPing),
+
+		gateway: newGatewayMonitor(),
+
+		scheduler:       newScheduler(),
+		scheduleEnabled: config.Schedule.Enabled,
+
+		tndToggle: newToggle(),
+
+		trafPolToggle: newToggle(),
+
+		pauseToggle: newToggle(),
+
+		idle:       newIdleMonitor(),
+		runtimeGC:  newRuntimeGC(filepath.Dir(sockFile)),
+		session:    newSessionTimer(),
+		resolvConf: newResolvConfGuard(resolvConfFile),
+
+		reloads: make(chan *Config),
+		dumps:   make(chan struct{}),
+		secret:  newConnSecret("vpncscript.secret"),
+
+		notifier: newNotifier(&config.Notifications),
+
+		metrics: metrics.NewRegistry(),
+
+		connectLimiter:      newRateLimiter(connectRateInterval, connectRateBurst),
+		configUpdateLimiter: newRateLimiter(configUpdateRateInterval, configUpdateRateBurst),
+
+		ctx:    context.Background(),
 		closed: make(chan struct{}),
 
-		profile: readXMLProfile(),
+		profile: readXMLProfile(xmlProfile),
 		profmon: profilemon.NewProfileMon(xmlProfile),
 	}
+	if config.GRPCAPI {
+		d.grpc = grpcapi.NewServer(grpcSockFile)
+	}
+	d.state = newStateMachine(d.handleStateTransition)
+	d.events = eventbus.New()
+	d.locker = newSessionLocker(d.events)
+	d.usage = newUsageTracker(d.events)
+
+	// add additional tunnels
+	d.tunnelEvents = make(chan *tunnelEvent)
+	for i, name := range config.Tunnels {
+		name := name
+		d.dbus.AddTunnel(name)
+		d.tunnels = append(d.tunnels, newTunnel(name, i+1,
+			func(prop string, value any) {
+				d.dbus.SetTunnelProperty(name, prop, value)
+			}))
+	}
+	return d
 }
@@ -0,0 +1,126 @@
+package daemon
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+	"github.com/telekom-mms/oc-daemon/internal/eventbus"
+	"github.com/telekom-mms/oc-daemon/pkg/logininfo"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+	"github.com/telekom-mms/oc-daemon/pkg/xmlprofile"
+)
+
+// TestDaemonHandleConnectionDrop tests handleConnectionDrop of Daemon
+func TestDaemonHandleConnectionDrop(t *testing.T) {
+	dbus := &testDBusService{props: make(map[string]any)}
+	d := &Daemon{
+		config: NewConfig(),
+		dbus:   dbus,
+		status: vpnstatus.New(),
+		events: eventbus.New(),
+	}
+	defer d.events.Close()
+	sub := d.events.Subscribe("test", 1, eventbus.TopicConnection)
+
+	d.status.DisconnectReason = "idle-timeout"
+	d.handleConnectionDrop()
+	if len(dbus.signals) != 1 ||
+		dbus.signals[0] != dbusapi.SignalConnectionDropped {
+		t.Errorf("got %v, want connection dropped signal", dbus.signals)
+	}
+	want := &eventbus.ConnectionDropped{Reason: "idle-timeout"}
+	if got := <-sub.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestDaemonConnectOffline tests connectVPN and handleConnectivity of Daemon
+// while offline
+func TestDaemonConnectOffline(t *testing.T) {
+	profile := xmlprofile.NewProfile()
+	profile.AutomaticVPNPolicy.AlwaysOn.Flag = true
+	d := &Daemon{
+		config:  NewConfig(),
+		dbus:    noDBusService{},
+		status:  vpnstatus.New(),
+		profile: profile,
+	}
+	login := &logininfo.LoginInfo{
+		Cookie:      "cookie",
+		Host:        "vpn.example.com",
+		Fingerprint: "fingerprint",
+	}
+
+	// offline, connect rejected and saved for always-on
+	d.handleConnectivity(false)
+	if d.status.Connectivity != vpnstatus.ConnectivityOffline {
+		t.Errorf("got %s, want offline", d.status.Connectivity)
+	}
+	if err := d.connectVPN(login); !errors.Is(err, dbusapi.ErrOffline) {
+		t.Errorf("got %v, want %v", err, dbusapi.ErrOffline)
+	}
+	if d.offlineLogin != login {
+		t.Error("login should be saved in always-on mode")
+	}
+
+	// online, connect not needed because vpn is already running
+	d.status.OCRunning = vpnstatus.OCRunningRunning
+	d.handleConnectivity(true)
+	if d.status.Connectivity != vpnstatus.ConnectivityOnline {
+		t.Errorf("got %s, want online", d.status.Connectivity)
+	}
+	if d.offlineLogin != nil {
+		t.Error("saved login should be removed")
+	}
+
+	// offline without always-on, login not saved
+	d.status.OCRunning = vpnstatus.OCRunningNotRunning
+	profile.AutomaticVPNPolicy.AlwaysOn.Flag = false
+	d.handleConnectivity(false)
+	if err := d.connectVPN(login); !errors.Is(err, dbusapi.ErrOffline) {
+		t.Errorf("got %v, want %v", err, dbusapi.ErrOffline)
+	}
+	if d.offlineLogin != nil {
+		t.Error("login should not be saved without always-on")
+	}
+}
+
+// TestDaemonCancelVPN tests cancelVPN of Daemon
+func TestDaemonCancelVPN(t *testing.T) {
+	config := NewConfig()
+	d := &Daemon{
+		config:    config,
+		dbus:      noDBusService{},
+		status:    vpnstatus.New(),
+		state:     newStateMachine(nil),
+		reconnect: newReconnect(&config.ReconnectPolicy),
+	}
+	if err := d.state.transition(vpnstatus.ConnectionStateDisconnected); err != nil {
+		t.Fatal(err)
+	}
+
+	// not connecting
+	if err := d.cancelVPN(); err == nil {
+		t.Error("cancel should fail when not connecting")
+	}
+
+	// connecting
+	if err := d.state.transition(vpnstatus.ConnectionStateConnecting); err != nil {
+		t.Fatal(err)
+	}
+	d.status.OCRunning = vpnstatus.OCRunningRunning
+	if err := d.cancelVPN(); err != nil {
+		t.Fatal(err)
+	}
+	if got := d.state.get(); got != vpnstatus.ConnectionStateDisconnecting {
+		t.Errorf("got %s, want disconnecting", got)
+	}
+	if d.status.OCRunning.Running() {
+		t.Error("openconnect should not be running")
+	}
+	if !d.disconnectRequested {
+		t.Error("disconnect should be requested")
+	}
+}
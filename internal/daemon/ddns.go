@@ -0,0 +1,106 @@
+package daemon
+
+import (
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/ddns"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
+)
+
+const (
+	// dnsUpdateTimeout is the timeout of dynamic DNS updates
+	dnsUpdateTimeout = 10 * time.Second
+)
+
+// hostname returns the host name of the machine, it is a variable to allow
+// for testing
+var hostname = os.Hostname
+
+// newDNSUpdater returns a new dynamic DNS updater for the VPN configuration
+// config and the dynamic DNS registration r
+func newDNSUpdater(r *DNSRegistration, config *vpnconfig.Config) (*ddns.Updater, error) {
+	// get zone, use default domain if not set
+	zone := r.Zone
+	if zone == "" {
+		zone = config.DNS.DefaultDomain
+	}
+	if zone == "" {
+		return nil, errors.New("no zone for dynamic DNS registration")
+	}
+
+	// get server, use first VPN DNS server if not set
+	server := r.Server
+	if server == "" {
+		servers := []net.IP{}
+		servers = append(servers, config.DNS.ServersIPv4...)
+		servers = append(servers, config.DNS.ServersIPv6...)
+		if len(servers) == 0 {
+			return nil, errors.New("no server for dynamic DNS registration")
+		}
+		server = net.JoinHostPort(servers[0].String(), "53")
+	}
+
+	u := &ddns.Updater{
+		Server:  server,
+		Zone:    zone,
+		TTL:     r.TTL,
+		Timeout: dnsUpdateTimeout,
+	}
+	if r.TSIGKeyName != "" {
+		u.TSIG = &ddns.TSIG{
+			Name:      r.TSIGKeyName,
+			Algorithm: r.TSIGAlgorithm,
+			Secret:    r.TSIGSecret,
+		}
+	}
+	return u, nil
+}
+
+// registerHostname registers the VPN IP addresses in config under the host
+// name of the machine if the dynamic DNS registration is enabled, the update
+// is sent in the background
+func (d *Daemon) registerHostname(config *vpnconfig.Config) {
+	if !d.config.DNSRegistration.Enabled {
+		return
+	}
+
+	u, err := newDNSUpdater(&d.config.DNSRegistration, config)
+	if err != nil {
+		log.WithError(err).Error("Daemon could not register host name in DNS")
+		return
+	}
+
+	// use short host name without domain
+	host, err := hostname()
+	if err != nil {
+		log.WithError(err).Error("Daemon could not get host name for DNS registration")
+		return
+	}
+	host, _, _ = strings.Cut(host, ".")
+
+	ips := []net.IP{}
+	for _, ip := range []net.IP{config.IPv4.Address, config.IPv6.Address} {
+		if ip != nil {
+			ips = append(ips, append(ip[:0:0], ip...))
+		}
+	}
+
+	go func() {
+		fields := logrus.Fields{
+			"host":   host,
+			"zone":   u.Zone,
+			"server": u.Server,
+		}
+		if err := u.Register(host, ips); err != nil {
+			log.WithError(err).WithFields(fields).
+				Error("Daemon could not register host name in DNS")
+			return
+		}
+		log.WithFields(fields).Info("Daemon registered host name in DNS")
+	}()
+}
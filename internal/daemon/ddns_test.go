@@ -0,0 +1,59 @@
+package daemon
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/ddns"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
+)
+
+// TestNewDNSUpdater tests newDNSUpdater
+func TestNewDNSUpdater(t *testing.T) {
+	r := &DNSRegistration{TTL: time.Minute}
+	c := vpnconfig.New()
+
+	// test without zone and server
+	if _, err := newDNSUpdater(r, c); err == nil {
+		t.Error("updater without zone should fail")
+	}
+	c.DNS.DefaultDomain = "example.com"
+	if _, err := newDNSUpdater(r, c); err == nil {
+		t.Error("updater without server should fail")
+	}
+
+	// test defaults from vpn config
+	c.DNS.ServersIPv6 = []net.IP{net.ParseIP("2001:db8::53")}
+	u, err := newDNSUpdater(r, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Zone != "example.com" || u.Server != "[2001:db8::53]:53" ||
+		u.TTL != time.Minute || u.TSIG != nil {
+		t.Errorf("got %v, want defaults from vpn config", u)
+	}
+
+	// test settings from registration
+	r = &DNSRegistration{
+		Zone:          "vpn.example.com",
+		Server:        "192.168.1.1:5353",
+		TTL:           time.Hour,
+		TSIGKeyName:   "key",
+		TSIGAlgorithm: ddns.AlgorithmHMACSHA512,
+		TSIGSecret:    "c2VjcmV0",
+	}
+	u, err = newDNSUpdater(r, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ddns.TSIG{
+		Name:      "key",
+		Algorithm: ddns.AlgorithmHMACSHA512,
+		Secret:    "c2VjcmV0",
+	}
+	if u.Zone != r.Zone || u.Server != r.Server || u.TTL != r.TTL ||
+		*u.TSIG != *want {
+		t.Errorf("got %v, want settings from registration", u)
+	}
+}
@@ -0,0 +1,265 @@
+package daemon
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/audit"
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+	"github.com/telekom-mms/oc-daemon/internal/deviceauth"
+	"github.com/telekom-mms/oc-daemon/internal/logging"
+	"github.com/telekom-mms/oc-daemon/pkg/logininfo"
+	"github.com/telekom-mms/oc-daemon/pkg/xmlprofile"
+)
+
+// deviceAuthHTTPTimeout is the timeout of device authorization HTTP requests
+const deviceAuthHTTPTimeout = 10 * time.Second
+
+// errDeviceAuthAborted is the error of a device authorization request that
+// was aborted before the device code was received
+var errDeviceAuthAborted = errors.New("device authorization aborted")
+
+// deviceAuthCode is the device code of a device authorization for the VPN
+// server address
+type deviceAuthCode struct {
+	ctx     context.Context
+	address string
+	code    *deviceauth.Code
+	err     error
+}
+
+// deviceAuthResult is the result of a device authorization, uid is the user
+// who requested it
+type deviceAuthResult struct {
+	ctx   context.Context
+	uid   int64
+	login *logininfo.LoginInfo
+	err   error
+}
+
+// serverFingerprint returns the fingerprint of the certificate of the VPN
+// server host in the pin format of openconnect, the certificate is verified
+// with the system CAs
+var serverFingerprint = func(ctx context.Context, host string) (string, error) {
+	addr := host
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		addr = net.JoinHostPort(host, "443")
+	}
+	dialer := &tls.Dialer{Config: &tls.Config{MinVersion: tls.VersionTLS12}}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = conn.Close() }()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return "", errors.New("no server certificate")
+	}
+	sum := sha256.Sum256(certs[0].RawSubjectPublicKeyInfo)
+	return "pin-sha256:" + base64.StdEncoding.EncodeToString(sum[:]), nil
+}
+
+// lookupVPNServer returns the address of the VPN server in profile, server
+// is either a host name or address in profile, an address not in profile or
+// empty for the first server in profile
+func lookupVPNServer(profile *xmlprofile.Profile, server string) string {
+	for _, h := range profile.ServerList.HostEntry {
+		if server == "" || server == h.HostName {
+			return h.HostAddress
+		}
+	}
+	return server
+}
+
+// newDeviceAuthClient returns a new device authorization client for config
+func newDeviceAuthClient(config *DeviceAuth) *deviceauth.Client {
+	return &deviceauth.Client{
+		AuthorizationURL: config.AuthorizationURL,
+		TokenURL:         config.TokenURL,
+		ClientID:         config.ClientID,
+		Scope:            config.Scope,
+		HTTPClient:       &http.Client{Timeout: deviceAuthHTTPTimeout},
+	}
+}
+
+// handleConnectDeviceRequest handles a D-Bus connect request with device
+// authorization, the request is completed when the device code is received
+func (d *Daemon) handleConnectDeviceRequest(pending *pendingRequest) {
+	request := pending.request
+	log := log.WithField(logging.RequestField, request.ID)
+	log.WithFields(logrus.Fields{
+		"method": request.Name,
+		"sender": request.Sender,
+		"uid":    request.UID,
+	}).Debug("Daemon handling D-Bus client request")
+
+	err := checkRateLimit(d.connectLimiter, fmt.Sprintf("uid %d", request.UID))
+	if err == nil {
+		err = d.startDeviceAuth(request)
+	}
+	if err != nil {
+		log.WithError(err).Error("Daemon could not start device authorization")
+		request.Error = err
+		d.closeDBusRequest(pending)
+		return
+	}
+	d.deviceAuthRequest = pending
+}
+
+// startDeviceAuth starts a VPN connect with device authorization for the
+// D-Bus request, it requests the device code and polls for the cookie in
+// the background; the request is completed when the device code is received
+// or the device authorization is stopped
+func (d *Daemon) startDeviceAuth(request *dbusapi.Request) error {
+	profile := request.Parameters[0].(string)
+	server := request.Parameters[1].(string)
+
+	// check if connect is possible
+	if !d.config.DeviceAuth.Enabled() {
+		return errors.New("device authorization not configured")
+	}
+	if d.deviceAuthCancel != nil {
+		return errors.New("device authorization already running")
+	}
+	if d.status.OCRunning.Running() {
+		return errors.New("vpn already running")
+	}
+	if d.status.Connectivity.Offline() {
+		return dbusapi.ErrOffline
+	}
+	if err := d.selectProfile(profile); err != nil {
+		return err
+	}
+	if server == "" {
+		server = d.preferredServer()
+	}
+	address := lookupVPNServer(d.profile, server)
+	if address == "" {
+		return errors.New("no vpn server")
+	}
+
+	// request device code, poll for cookie and get server fingerprint
+	client := newDeviceAuthClient(&d.config.DeviceAuth)
+	ctx, cancel := context.WithCancel(d.ctx)
+	d.deviceAuthCancel = cancel
+	uid := request.UID
+	go func() {
+		code, err := client.Authorize(ctx)
+		select {
+		case d.deviceAuthCodes <- &deviceAuthCode{
+			ctx:     ctx,
+			address: address,
+			code:    code,
+			err:     err,
+		}:
+		case <-ctx.Done():
+			return
+		}
+		if err != nil {
+			return
+		}
+
+		r := &deviceAuthResult{ctx: ctx, uid: uid}
+		cookie, err := client.Poll(ctx, code)
+		if err != nil {
+			r.err = err
+		} else {
+			host := strings.SplitN(address, "/", 2)[0]
+			fingerprint, err := serverFingerprint(ctx, host)
+			r.err = err
+			r.login = &logininfo.LoginInfo{
+				Cookie:      cookie,
+				Host:        host,
+				ConnectURL:  "https://" + address,
+				Fingerprint: fingerprint,
+			}
+		}
+		select {
+		case d.deviceAuthResults <- r:
+		case <-ctx.Done():
+		}
+	}()
+	return nil
+}
+
+// finishDeviceAuthRequest completes the pending D-Bus request of the device
+// authorization with err
+func (d *Daemon) finishDeviceAuthRequest(err error) {
+	if d.deviceAuthRequest != nil {
+		d.deviceAuthRequest.request.Error = err
+		d.closeDBusRequest(d.deviceAuthRequest)
+		d.deviceAuthRequest = nil
+	}
+}
+
+// stopDeviceAuth stops a running device authorization
+func (d *Daemon) stopDeviceAuth() {
+	d.finishDeviceAuthRequest(errDeviceAuthAborted)
+	if d.deviceAuthCancel != nil {
+		d.deviceAuthCancel()
+		d.deviceAuthCancel = nil
+	}
+}
+
+// handleDeviceAuthCode handles the device code of a device authorization,
+// it completes the D-Bus request with the user code and verification URIs
+func (d *Daemon) handleDeviceAuthCode(c *deviceAuthCode) {
+	if c.ctx.Err() != nil {
+		// code of stopped device authorization
+		return
+	}
+	if c.err != nil {
+		log.WithError(c.err).Error("Daemon could not start device authorization")
+		d.finishDeviceAuthRequest(c.err)
+		d.stopDeviceAuth()
+		return
+	}
+
+	pending := d.deviceAuthRequest
+	d.deviceAuthRequest = nil
+	request := pending.request
+	request.Results = []any{
+		c.code.UserCode,
+		c.code.VerificationURI,
+		c.code.VerificationURIComplete,
+	}
+	d.closeDBusRequest(pending)
+
+	log.WithField("server", c.address).Info("Daemon waiting for device authorization")
+	d.logAudit(audit.EventConnect, request.Sender, request.UID,
+		c.address+" (device authorization)")
+	d.setStatusOwner(request.UID)
+}
+
+// handleDeviceAuthResult handles the result of a device authorization and
+// connects the VPN
+func (d *Daemon) handleDeviceAuthResult(r *deviceAuthResult) {
+	if r.ctx.Err() != nil {
+		// result of stopped device authorization
+		return
+	}
+	d.stopDeviceAuth()
+	if r.err != nil {
+		log.WithError(r.err).Error("Daemon device authorization failed")
+		return
+	}
+
+	// connect VPN, save login info for reconnects
+	log.Info("Daemon got device authorization, connecting VPN")
+	d.reconnect.reset()
+	d.reconnect.setLogin(r.login)
+	d.reconnect.setLoginUID(r.uid)
+	if err := d.connectVPN(r.login); err != nil {
+		log.WithError(err).Error("Daemon could not connect VPN")
+	}
+}
@@ -0,0 +1,102 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+	"github.com/telekom-mms/oc-daemon/pkg/xmlprofile"
+)
+
+// TestLookupVPNServer tests lookupVPNServer
+func TestLookupVPNServer(t *testing.T) {
+	profile := xmlprofile.NewProfile()
+	profile.ServerList.HostEntry = []xmlprofile.HostEntry{
+		{HostName: "VPN 1", HostAddress: "vpn1.example.com"},
+		{HostName: "VPN 2", HostAddress: "vpn2.example.com/group"},
+	}
+
+	for server, want := range map[string]string{
+		"":                  "vpn1.example.com",
+		"VPN 2":             "vpn2.example.com/group",
+		"vpn3.example.com":  "vpn3.example.com",
+		"vpn1.example.com/": "vpn1.example.com/",
+	} {
+		if got := lookupVPNServer(profile, server); got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+
+	// empty profile
+	if got := lookupVPNServer(xmlprofile.NewProfile(), ""); got != "" {
+		t.Errorf("got %s, want empty", got)
+	}
+}
+
+// TestDaemonStartDeviceAuth tests startDeviceAuth of Daemon
+func TestDaemonStartDeviceAuth(t *testing.T) {
+	d := &Daemon{config: NewConfig()}
+	request := &dbusapi.Request{
+		Name:       dbusapi.RequestConnectDevice,
+		Parameters: []any{"", ""},
+	}
+
+	// not configured
+	if err := d.startDeviceAuth(request); err == nil {
+		t.Error("device authorization should not be configured")
+	}
+
+	// already running
+	d.config.DeviceAuth = DeviceAuth{
+		AuthorizationURL: "https://idp.example.com/device",
+		TokenURL:         "https://idp.example.com/token",
+		ClientID:         "oc-daemon",
+	}
+	canceled := false
+	d.deviceAuthCancel = func() { canceled = true }
+	if err := d.startDeviceAuth(request); err == nil {
+		t.Error("device authorization should be running")
+	}
+
+	// result of stopped device authorization is ignored
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	d.handleDeviceAuthResult(&deviceAuthResult{ctx: ctx, err: errors.New("test error")})
+	if canceled || d.deviceAuthCancel == nil {
+		t.Error("device authorization should not be stopped")
+	}
+
+	// failed result stops device authorization
+	d.handleDeviceAuthResult(&deviceAuthResult{
+		ctx: context.Background(),
+		err: errors.New("test error"),
+	})
+	if !canceled || d.deviceAuthCancel != nil {
+		t.Error("device authorization should be stopped")
+	}
+}
+
+// TestDaemonHandleDeviceAuthCode tests handleDeviceAuthCode of Daemon
+func TestDaemonHandleDeviceAuthCode(t *testing.T) {
+	d := &Daemon{config: NewConfig()}
+
+	// code of stopped device authorization is ignored
+	canceled := false
+	d.deviceAuthCancel = func() { canceled = true }
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	d.handleDeviceAuthCode(&deviceAuthCode{ctx: ctx, err: errors.New("test error")})
+	if canceled || d.deviceAuthCancel == nil {
+		t.Error("device authorization should not be stopped")
+	}
+
+	// failed device code request stops device authorization
+	d.handleDeviceAuthCode(&deviceAuthCode{
+		ctx: context.Background(),
+		err: errors.New("test error"),
+	})
+	if !canceled || d.deviceAuthCancel != nil {
+		t.Error("device authorization should be stopped")
+	}
+}
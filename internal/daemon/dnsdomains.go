@@ -0,0 +1,199 @@
+package daemon
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
+)
+
+// resolvedDomain is a domain of a network link in systemd-resolved
+type resolvedDomain struct {
+	Ifindex   int32
+	Domain    string
+	RouteOnly bool
+}
+
+// getResolvedDomains returns the domains of all network links in
+// systemd-resolved, it can be replaced for testing
+var getResolvedDomains = func() ([]resolvedDomain, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	domains := []resolvedDomain{}
+	resolved := conn.Object("org.freedesktop.resolve1",
+		"/org/freedesktop/resolve1")
+	if err := resolved.StoreProperty("org.freedesktop.resolve1.Manager.Domains",
+		&domains); err != nil {
+		return nil, err
+	}
+	return domains, nil
+}
+
+// linkName returns the name of the network link with index, it can be
+// replaced for testing
+var linkName = func(index int) string {
+	iface, err := net.InterfaceByIndex(index)
+	if err != nil {
+		return fmt.Sprintf("%d", index)
+	}
+	return iface.Name
+}
+
+// domainConflict is a routing domain of the VPN device that overlaps with
+// a domain of another network link
+type domainConflict struct {
+	// Domain is the routing domain of the VPN device
+	Domain string
+
+	// Link is the other network link and Other its domain, it is the
+	// same as or a subdomain of Domain
+	Link  string
+	Other resolvedDomain
+}
+
+// normalizeDomain returns domain in lower case without "~" prefix and
+// trailing dot
+func normalizeDomain(domain string) string {
+	domain = strings.TrimPrefix(domain, "~")
+	return strings.ToLower(strings.TrimSuffix(domain, "."))
+}
+
+// overlaps returns whether the domain of another link takes over the
+// queries of routing domain, i.e., it is the same or more specific
+func overlaps(domain, other string) bool {
+	return other == domain || strings.HasSuffix(other, "."+domain)
+}
+
+// findDomainConflicts returns the routing domains of the VPN device in
+// config that overlap with domains of other links in domains
+func findDomainConflicts(config *vpnconfig.Config, domains []resolvedDomain) []*domainConflict {
+	// only split dns has routing domains that can be lost
+	if !config.DNS.SplitDNS() {
+		return nil
+	}
+
+	device := config.Device.Name
+	conflicts := []*domainConflict{}
+	for _, d := range vpnDNSDomains(config) {
+		if !strings.HasPrefix(d, "~") {
+			continue
+		}
+		domain := normalizeDomain(d)
+		for _, o := range domains {
+			link := linkName(int(o.Ifindex))
+			if link == device ||
+				!overlaps(domain, normalizeDomain(o.Domain)) {
+				continue
+			}
+			conflicts = append(conflicts, &domainConflict{
+				Domain: domain,
+				Link:   link,
+				Other:  o,
+			})
+		}
+	}
+	return conflicts
+}
+
+// yieldDomains removes the routing domains in conflicts from the VPN
+// device in config, so the other links own them
+func yieldDomains(config *vpnconfig.Config, conflicts []*domainConflict) {
+	yielded := make(map[string]bool)
+	for _, c := range conflicts {
+		yielded[c.Domain] = true
+	}
+	domains := []string{}
+	for _, d := range vpnDNSDomains(config) {
+		if strings.HasPrefix(d, "~") && yielded[normalizeDomain(d)] {
+			continue
+		}
+		domains = append(domains, d)
+	}
+	runResolvectl(fmt.Sprintf("domain %s %s", config.Device.Name,
+		strings.Join(domains, " ")))
+}
+
+// overrideDomains removes the domains in conflicts from the other links
+// in domains, so the VPN device owns them
+func overrideDomains(conflicts []*domainConflict, domains []resolvedDomain) {
+	removed := make(map[resolvedDomain]bool)
+	links := []int32{}
+	for _, c := range conflicts {
+		if !removed[c.Other] {
+			removed[c.Other] = true
+			links = append(links, c.Other.Ifindex)
+		}
+	}
+
+	done := make(map[int32]bool)
+	for _, index := range links {
+		if done[index] {
+			continue
+		}
+		done[index] = true
+
+		// set remaining domains of the link
+		remaining := []string{}
+		for _, d := range domains {
+			if d.Ifindex != index || removed[d] {
+				continue
+			}
+			if d.RouteOnly {
+				remaining = append(remaining, "~"+d.Domain)
+				continue
+			}
+			remaining = append(remaining, d.Domain)
+		}
+		arg := `""`
+		if len(remaining) > 0 {
+			arg = strings.Join(remaining, " ")
+		}
+		runResolvectl(fmt.Sprintf("domain %s %s", linkName(int(index)), arg))
+	}
+}
+
+// checkDomainConflicts checks the routing domains of the VPN connection
+// for conflicts with other links in systemd-resolved and applies the
+// domain conflict policy, only changed conflicts are handled
+func (d *Daemon) checkDomainConflicts() {
+	config := d.status.VPNConfig
+	if config == nil {
+		return
+	}
+	domains, err := getResolvedDomains()
+	if err != nil {
+		log.WithError(err).Debug("Daemon could not get domains of systemd-resolved")
+		return
+	}
+	conflicts := findDomainConflicts(config, domains)
+	if len(conflicts) == 0 || reflect.DeepEqual(conflicts, d.domainConflicts) {
+		d.domainConflicts = conflicts
+		return
+	}
+	d.domainConflicts = conflicts
+
+	policy := d.config.DomainConflicts
+	for _, c := range conflicts {
+		log.WithFields(logrus.Fields{
+			"domain": c.Domain,
+			"link":   c.Link,
+			"other":  c.Other.Domain,
+			"policy": policy,
+		}).Warn("Daemon detected routing domain conflict with other link")
+	}
+
+	switch policy {
+	case DomainConflictsYield:
+		yieldDomains(config, conflicts)
+	case DomainConflictsOverride:
+		overrideDomains(conflicts, domains)
+	}
+}
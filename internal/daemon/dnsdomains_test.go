@@ -0,0 +1,151 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// testDomainConfig returns a VPN config with split DNS on tun0 for testing
+func testDomainConfig() *vpnconfig.Config {
+	c := vpnconfig.New()
+	c.Device.Name = "tun0"
+	c.DNS.DefaultDomain = "mycompany.com"
+	c.DNS.SplitDomains = []string{"mycompany.com", "other.com"}
+	return c
+}
+
+// testLinkNames replaces linkName with link names "link<index>" and tun0
+// for index 1 for testing
+func testLinkNames(t *testing.T) {
+	oldLinkName := linkName
+	t.Cleanup(func() { linkName = oldLinkName })
+	linkName = func(index int) string {
+		if index == 1 {
+			return "tun0"
+		}
+		return fmt.Sprintf("link%d", index)
+	}
+}
+
+// TestFindDomainConflicts tests findDomainConflicts
+func TestFindDomainConflicts(t *testing.T) {
+	testLinkNames(t)
+	c := testDomainConfig()
+	domains := []resolvedDomain{
+		{1, "mycompany.com", false},
+		{1, "other.com", true},
+		{2, "other.com", true},
+		{2, "lab.mycompany.com", true},
+		{3, "company.com", true},
+		{3, ".", true},
+	}
+
+	got := findDomainConflicts(c, domains)
+	want := []*domainConflict{
+		{"mycompany.com", "link2", domains[3]},
+		{"other.com", "link2", domains[2]},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// no split dns
+	c.DNS.SplitDomains = nil
+	if got := findDomainConflicts(c, domains); got != nil {
+		t.Errorf("got %v, want nil", got)
+	}
+}
+
+// TestDaemonCheckDomainConflicts tests checkDomainConflicts of Daemon
+func TestDaemonCheckDomainConflicts(t *testing.T) {
+	testLinkNames(t)
+	oldGetResolvedDomains := getResolvedDomains
+	oldRunResolvectl := runResolvectl
+	defer func() {
+		getResolvedDomains = oldGetResolvedDomains
+		runResolvectl = oldRunResolvectl
+	}()
+	domains := []resolvedDomain{
+		{1, "mycompany.com", false},
+		{1, "other.com", true},
+		{2, "home.arpa", false},
+		{2, "other.com", true},
+	}
+	getResolvedDomains = func() ([]resolvedDomain, error) {
+		return domains, nil
+	}
+	got := []string{}
+	runResolvectl = func(cmd string) {
+		got = append(got, cmd)
+	}
+
+	for _, test := range []struct {
+		policy string
+		want   []string
+	}{
+		{DomainConflictsWarn, []string{}},
+		{DomainConflictsYield, []string{
+			"domain tun0 mycompany.com ~mycompany.com",
+		}},
+		{DomainConflictsOverride, []string{
+			"domain link2 home.arpa",
+		}},
+	} {
+		d := &Daemon{
+			config: NewConfig(),
+			status: vpnstatus.New(),
+		}
+		d.config.DomainConflicts = test.policy
+		d.status.VPNConfig = testDomainConfig()
+		got = []string{}
+
+		d.checkDomainConflicts()
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.policy, got, test.want)
+		}
+		if len(d.domainConflicts) != 1 {
+			t.Errorf("%s: got %v, want 1 conflict", test.policy,
+				d.domainConflicts)
+		}
+
+		// same conflicts are handled only once
+		got = []string{}
+		d.checkDomainConflicts()
+		if len(got) != 0 {
+			t.Errorf("%s: got %v, want no commands", test.policy, got)
+		}
+	}
+
+	// override all domains of other link
+	domains = []resolvedDomain{
+		{1, "other.com", true},
+		{2, "other.com", true},
+	}
+	d := &Daemon{
+		config: NewConfig(),
+		status: vpnstatus.New(),
+	}
+	d.config.DomainConflicts = DomainConflictsOverride
+	d.status.VPNConfig = testDomainConfig()
+	got = []string{}
+	d.checkDomainConflicts()
+	want := []string{`domain link2 ""`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// resolved not available
+	getResolvedDomains = func() ([]resolvedDomain, error) {
+		return nil, errors.New("test error")
+	}
+	got = []string{}
+	d.checkDomainConflicts()
+	if len(got) != 0 {
+		t.Errorf("got %v, want no commands", got)
+	}
+}
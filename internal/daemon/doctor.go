@@ -0,0 +1,138 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+)
+
+// Self-check names
+const (
+	CheckPrivileges      = "privileges"
+	CheckOpenConnect     = "openconnect"
+	CheckVPNCScript      = "vpnc-script"
+	CheckIP              = "ip"
+	CheckNft             = "nft"
+	CheckResolvectl      = "resolvectl"
+	CheckDBus            = "dbus"
+	CheckSystemdResolved = "systemd-resolved"
+	CheckSocket          = "socket"
+)
+
+// Problem is a problem with a runtime dependency found by the self-check
+type Problem struct {
+	// Check is the name of the check that found the problem
+	Check string
+
+	// Message describes the problem
+	Message string
+}
+
+// functions used by the self-check, they are variables to allow for testing
+var (
+	doctorGeteuid   = os.Geteuid
+	doctorLookPath  = exec.LookPath
+	doctorStat      = os.Stat
+	doctorNameOwner = dbusapi.SystemBusNameHasOwner
+)
+
+// checkPrivileges checks if the daemon runs with root privileges
+func checkPrivileges() error {
+	if doctorGeteuid() != 0 {
+		return errors.New("daemon does not run as root")
+	}
+	return nil
+}
+
+// checkCommand returns a check if the command is available in PATH
+func checkCommand(command string) func() error {
+	return func() error {
+		if _, err := doctorLookPath(command); err != nil {
+			return fmt.Errorf("command %s not found", command)
+		}
+		return nil
+	}
+}
+
+// checkVPNCScript checks if the vpnc-script exists and is executable
+func checkVPNCScript() error {
+	fi, err := doctorStat(vpncScript)
+	if err != nil {
+		return fmt.Errorf("vpnc-script %s not found", vpncScript)
+	}
+	if fi.IsDir() || fi.Mode().Perm()&0111 == 0 {
+		return fmt.Errorf("vpnc-script %s is not executable", vpncScript)
+	}
+	return nil
+}
+
+// checkDBus checks if the D-Bus system bus is available
+func checkDBus() error {
+	if _, err := doctorNameOwner("org.freedesktop.DBus"); err != nil {
+		return fmt.Errorf("D-Bus system bus not available: %w", err)
+	}
+	return nil
+}
+
+// checkSystemdResolved checks if systemd-resolved is running
+func checkSystemdResolved() error {
+	owned, err := doctorNameOwner("org.freedesktop.resolve1")
+	if err != nil {
+		return fmt.Errorf("could not check systemd-resolved: %w", err)
+	}
+	if !owned {
+		return errors.New("systemd-resolved is not running")
+	}
+	return nil
+}
+
+// checkSocket checks the permissions of the unix socket directory and file,
+// they do not have to exist, they are created when the daemon starts
+func checkSocket() error {
+	for _, f := range []string{filepath.Dir(sockFile), sockFile} {
+		fi, err := doctorStat(f)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not check %s: %w", f, err)
+		}
+		if fi.Mode().Perm()&0022 != 0 {
+			return fmt.Errorf("%s is writable by other users", f)
+		}
+	}
+	return nil
+}
+
+// Doctor runs the self-check of the runtime dependencies and returns the
+// problems found, it returns an empty list if there are no problems
+func Doctor() []Problem {
+	problems := []Problem{}
+	for _, c := range []struct {
+		name  string
+		check func() error
+	}{
+		{CheckPrivileges, checkPrivileges},
+		{CheckOpenConnect, checkCommand("openconnect")},
+		{CheckVPNCScript, checkVPNCScript},
+		{CheckIP, checkCommand("ip")},
+		{CheckNft, checkCommand("nft")},
+		{CheckResolvectl, checkCommand("resolvectl")},
+		{CheckDBus, checkDBus},
+		{CheckSystemdResolved, checkSystemdResolved},
+		{CheckSocket, checkSocket},
+	} {
+		if err := c.check(); err != nil {
+			problems = append(problems, Problem{
+				Check:   c.name,
+				Message: err.Error(),
+			})
+		}
+	}
+	return problems
+}
@@ -0,0 +1,105 @@
+package daemon
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestDoctor tests Doctor
+func TestDoctor(t *testing.T) {
+	// restore self-check functions and paths after test
+	oldGeteuid, oldLookPath, oldStat, oldNameOwner :=
+		doctorGeteuid, doctorLookPath, doctorStat, doctorNameOwner
+	oldSockFile := sockFile
+	defer func() {
+		doctorGeteuid, doctorLookPath, doctorStat, doctorNameOwner =
+			oldGeteuid, oldLookPath, oldStat, oldNameOwner
+		sockFile = oldSockFile
+	}()
+
+	// create test files, use test vpnc-script instead of installed one
+	dir := t.TempDir()
+	script := filepath.Join(dir, "vpncscript")
+	if err := os.WriteFile(script, []byte{}, 0755); err != nil {
+		t.Fatal(err)
+	}
+	sockFile = filepath.Join(dir, "socket", "daemon.sock")
+	stat := func(name string) (fs.FileInfo, error) {
+		if name == vpncScript {
+			return os.Stat(script)
+		}
+		return os.Stat(name)
+	}
+
+	// test without problems
+	doctorGeteuid = func() int { return 0 }
+	doctorLookPath = func(file string) (string, error) { return file, nil }
+	doctorStat = stat
+	doctorNameOwner = func(string) (bool, error) { return true, nil }
+	if got := Doctor(); len(got) != 0 {
+		t.Errorf("got %v, want no problems", got)
+	}
+
+	// test with problems
+	if err := os.Chmod(script, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Dir(sockFile), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Dir(sockFile), 0777); err != nil {
+		t.Fatal(err)
+	}
+	doctorGeteuid = func() int { return 1000 }
+	doctorLookPath = func(file string) (string, error) {
+		if file == "openconnect" || file == "nft" {
+			return "", errors.New("not found")
+		}
+		return file, nil
+	}
+	doctorNameOwner = func(name string) (bool, error) {
+		return name != "org.freedesktop.resolve1", nil
+	}
+	want := []string{
+		CheckPrivileges,
+		CheckOpenConnect,
+		CheckVPNCScript,
+		CheckNft,
+		CheckSystemdResolved,
+		CheckSocket,
+	}
+	got := []string{}
+	for _, p := range Doctor() {
+		got = append(got, p.Check)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// test missing files and D-Bus errors
+	doctorStat = func(string) (fs.FileInfo, error) {
+		return nil, fs.ErrNotExist
+	}
+	doctorNameOwner = func(string) (bool, error) {
+		return false, errors.New("test error")
+	}
+	want = []string{
+		CheckPrivileges,
+		CheckOpenConnect,
+		CheckVPNCScript,
+		CheckNft,
+		CheckDBus,
+		CheckSystemdResolved,
+	}
+	got = []string{}
+	for _, p := range Doctor() {
+		got = append(got, p.Check)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,73 @@
+package daemon
+
+import (
+	"bytes"
+	"os/exec"
+	"runtime/pprof"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/manifest"
+)
+
+// dumpCmds returns the commands that show the active rules of the system
+// changes in entries, e.g., the nftables tables and routing rules
+func dumpCmds(entries []*manifest.Entry) [][]string {
+	cmds := [][]string{}
+	rules := false
+	for _, e := range entries {
+		switch e.Kind {
+		case manifest.KindNftTable:
+			cmd := []string{"nft", "list", "table"}
+			cmds = append(cmds, append(cmd, strings.Fields(e.Name)...))
+		case manifest.KindRouteTable:
+			cmds = append(cmds,
+				[]string{"ip", "-4", "route", "show", "table", e.Name},
+				[]string{"ip", "-6", "route", "show", "table", e.Name})
+		case manifest.KindRule:
+			rules = true
+		}
+	}
+	if rules {
+		cmds = append(cmds,
+			[]string{"ip", "-4", "rule", "show"},
+			[]string{"ip", "-6", "rule", "show"})
+	}
+	return cmds
+}
+
+// runDumpCmd runs the command cmd and returns its output
+var runDumpCmd = func(cmd []string) (string, error) {
+	b, err := exec.Command(cmd[0], cmd[1:]...).CombinedOutput()
+	return string(b), err
+}
+
+// handleDump logs a debug dump with the goroutines, the status and the
+// active rules of the daemon
+func (d *Daemon) handleDump() {
+	// goroutines with their stack traces
+	b := &bytes.Buffer{}
+	if err := pprof.Lookup("goroutine").WriteTo(b, 2); err != nil {
+		log.WithError(err).Error("Daemon could not dump goroutines")
+	}
+	log.WithField("goroutines", b.String()).Info("Daemon debug dump of goroutines")
+
+	// status
+	log.WithField("status", d.status.Copy()).Info("Daemon debug dump of status")
+
+	// system changes and their active rules
+	entries := manifest.Entries()
+	log.WithField("manifest", entries).Info("Daemon debug dump of system changes")
+	for _, cmd := range dumpCmds(entries) {
+		out, err := runDumpCmd(cmd)
+		if err != nil {
+			log.WithError(err).WithField("command", cmd).
+				Error("Daemon could not dump active rules")
+			continue
+		}
+		log.WithFields(logrus.Fields{
+			"command": cmd,
+			"output":  out,
+		}).Info("Daemon debug dump of active rules")
+	}
+}
@@ -0,0 +1,65 @@
+package daemon
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/internal/manifest"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// TestDumpCmds tests dumpCmds
+func TestDumpCmds(t *testing.T) {
+	// no system changes
+	if got := dumpCmds(nil); len(got) != 0 {
+		t.Errorf("got %v, want no commands", got)
+	}
+
+	// system changes
+	entries := []*manifest.Entry{
+		{Kind: manifest.KindDevice, Name: "oc-daemon-tun0"},
+		{Kind: manifest.KindNftTable, Name: "inet oc-daemon-filter"},
+		{Kind: manifest.KindRouteTable, Name: "42111"},
+		{Kind: manifest.KindRule, Name: "2111"},
+		{Kind: manifest.KindRule, Name: "2112"},
+	}
+	want := [][]string{
+		{"nft", "list", "table", "inet", "oc-daemon-filter"},
+		{"ip", "-4", "route", "show", "table", "42111"},
+		{"ip", "-6", "route", "show", "table", "42111"},
+		{"ip", "-4", "rule", "show"},
+		{"ip", "-6", "rule", "show"},
+	}
+	if got := dumpCmds(entries); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestDaemonHandleDump tests handleDump of Daemon
+func TestDaemonHandleDump(t *testing.T) {
+	oldRunDumpCmd := runDumpCmd
+	defer func() { runDumpCmd = oldRunDumpCmd }()
+
+	got := [][]string{}
+	runDumpCmd = func(cmd []string) (string, error) {
+		got = append(got, cmd)
+		if cmd[0] == "ip" {
+			return "", errors.New("test error")
+		}
+		return "table inet oc-daemon-filter {}", nil
+	}
+
+	manifest.Add(manifest.KindNftTable, "inet oc-daemon-test")
+	defer manifest.Remove(manifest.KindNftTable, "inet oc-daemon-test")
+	manifest.Add(manifest.KindRule, "2111")
+	defer manifest.Remove(manifest.KindRule, "2111")
+
+	d := &Daemon{status: vpnstatus.New()}
+	d.handleDump()
+
+	want := dumpCmds(manifest.Entries())
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,42 @@
+package daemon
+
+import (
+	"github.com/telekom-mms/oc-daemon/internal/api"
+	"github.com/telekom-mms/oc-daemon/internal/eventbus"
+)
+
+// setProperty sets the D-Bus property with name to value and sends the
+// change to the subscribers of the socket and gRPC APIs
+func (d *Daemon) setProperty(name string, value any) {
+	d.dbus.SetProperty(name, value)
+	d.publishEvent(&api.Event{Property: name, Value: value})
+}
+
+// emitSignal emits the D-Bus signal with name and values and sends it to
+// the subscribers of the socket and gRPC APIs
+func (d *Daemon) emitSignal(name string, values ...any) {
+	if !d.notifier.allow(name) {
+		log.WithField("signal", name).Debug("Daemon suppressed notification signal")
+		return
+	}
+	d.dbus.EmitSignal(name, values...)
+	d.publishEvent(&api.Event{Signal: name, Values: values})
+}
+
+// publishEvent sends the event e to the subscribers of the socket and gRPC
+// APIs
+func (d *Daemon) publishEvent(e *api.Event) {
+	if d.server != nil {
+		d.server.Publish(e)
+	}
+	if d.grpc != nil {
+		d.grpc.Publish(e)
+	}
+}
+
+// publish publishes the event e on the event bus of the daemon
+func (d *Daemon) publish(e eventbus.Event) {
+	if d.events != nil {
+		d.events.Publish(e)
+	}
+}
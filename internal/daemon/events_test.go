@@ -0,0 +1,73 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/internal/api"
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+)
+
+// TestDaemonSetPropertyEmitSignal tests setProperty and emitSignal of Daemon
+func TestDaemonSetPropertyEmitSignal(t *testing.T) {
+	sockFile := filepath.Join(t.TempDir(), "test.sock")
+	dbus := &testDBusService{props: make(map[string]any)}
+	d := &Daemon{
+		dbus:   dbus,
+		server: api.NewServer(sockFile),
+	}
+	if err := d.server.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer d.server.Stop()
+
+	// subscribe to events
+	conn, err := net.Dial("unix", sockFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	if err := api.WriteMessage(conn, api.NewMessage(api.TypeSubscribe, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.ReadMessage(conn); err != nil {
+		t.Fatal(err)
+	}
+
+	// property
+	d.setProperty(dbusapi.PropertyIP, "192.168.1.1")
+	if dbus.props[dbusapi.PropertyIP] != "192.168.1.1" {
+		t.Errorf("property not set: %v", dbus.props)
+	}
+	msg, err := api.ReadMessage(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &api.Event{}
+	if err := json.Unmarshal(msg.Value, e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Property != dbusapi.PropertyIP || e.Value != "192.168.1.1" {
+		t.Errorf("got %v, want property event", e)
+	}
+
+	// signal
+	d.emitSignal(dbusapi.SignalConnectionDropped)
+	if len(dbus.signals) != 1 {
+		t.Errorf("signal not emitted: %v", dbus.signals)
+	}
+	msg, err = api.ReadMessage(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e = &api.Event{}
+	if err := json.Unmarshal(msg.Value, e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Signal != dbusapi.SignalConnectionDropped {
+		t.Errorf("got %v, want signal event", e)
+	}
+}
@@ -0,0 +1,36 @@
+//go:build !nodbus
+
+package daemon
+
+import (
+	"errors"
+
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+)
+
+// featureDBus indicates if the D-Bus API is enabled
+const featureDBus = true
+
+// newDBusService returns a new D-Bus API service, it does nothing if the
+// D-Bus API is disabled at runtime
+func newDBusService() dbusService {
+	if noDBus {
+		return noDBusService{}
+	}
+	return dbusapi.NewService()
+}
+
+// checkDBusName checks if the D-Bus name of the daemon is already owned by
+// another daemon instance
+func checkDBusName() error {
+	owned, err := dbusapi.NameHasOwner()
+	if err != nil {
+		log.WithError(err).Warn("Daemon could not check D-Bus name owner")
+		return nil
+	}
+	if owned {
+		return errors.New("D-Bus name " + dbusapi.Interface +
+			" is already owned by another daemon instance")
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+//go:build !nodnsproxy
+
+package daemon
+
+import (
+	"github.com/telekom-mms/oc-daemon/internal/dnsproxy"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
+)
+
+// featureDNSProxy indicates if the DNS-Proxy is enabled
+const featureDNSProxy = true
+
+// newDNSProxy returns a new DNS-Proxy listening on address
+func newDNSProxy(address string) dnsProxy {
+	return dnsproxy.NewProxy(address)
+}
+
+// getDNSServers returns the DNS servers for the VPN device, this is the
+// DNS-Proxy listening on address
+func getDNSServers(address string, _ *vpnconfig.Config) string {
+	return address
+}
@@ -0,0 +1,16 @@
+//go:build nodbus
+
+package daemon
+
+// featureDBus indicates if the D-Bus API is enabled
+const featureDBus = false
+
+// newDBusService returns a new D-Bus API service that does nothing
+func newDBusService() dbusService {
+	return noDBusService{}
+}
+
+// checkDBusName does nothing, the D-Bus API is disabled
+func checkDBusName() error {
+	return nil
+}
@@ -0,0 +1,68 @@
+//go:build nodnsproxy
+
+package daemon
+
+import (
+	"context"
+	"strings"
+
+	"github.com/telekom-mms/oc-daemon/internal/dnsproxy"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
+)
+
+// featureDNSProxy indicates if the DNS-Proxy is enabled
+const featureDNSProxy = false
+
+// noDNSProxy is a DNS-Proxy that does nothing
+type noDNSProxy struct{}
+
+// Start starts the DNS-Proxy
+func (noDNSProxy) Start(context.Context) error { return nil }
+
+// Stop stops the DNS-Proxy
+func (noDNSProxy) Stop() {}
+
+// Reports returns the reports channel, it never receives reports
+func (noDNSProxy) Reports() chan *dnsproxy.Report { return nil }
+
+// SetRemotes sets the DNS remotes
+func (noDNSProxy) SetRemotes(map[string][]string) {}
+
+// SetWatches sets the DNS watches
+func (noDNSProxy) SetWatches([]string) {}
+
+// SetFallback sets the DNS fallback servers
+func (noDNSProxy) SetFallback([]string) {}
+
+// SetTunnelAll sets tunnel all DNS mode
+func (noDNSProxy) SetTunnelAll(bool) {}
+
+// SetDevice sets the network device for queries to remote servers
+func (noDNSProxy) SetDevice(string) {}
+
+// SetFallbackMark sets the firewall mark for queries to fallback servers
+func (noDNSProxy) SetFallbackMark(int) {}
+
+// Leaks returns the number of blocked DNS leaks, it is always 0
+func (noDNSProxy) Leaks() uint64 { return 0 }
+
+// Version returns the forwarding config version, it is always 0
+func (noDNSProxy) Version() uint64 { return 0 }
+
+// newDNSProxy returns a new DNS-Proxy that does nothing
+func newDNSProxy(string) dnsProxy {
+	return noDNSProxy{}
+}
+
+// getDNSServers returns the DNS servers for the VPN device, these are the
+// DNS servers in the VPN config because there is no DNS-Proxy
+func getDNSServers(_ string, config *vpnconfig.Config) string {
+	servers := []string{}
+	for _, s := range config.DNS.ServersIPv4 {
+		servers = append(servers, s.String())
+	}
+	for _, s := range config.DNS.ServersIPv6 {
+		servers = append(servers, s.String())
+	}
+	return strings.Join(servers, " ")
+}
@@ -0,0 +1,14 @@
+//go:build notrafpol
+
+package daemon
+
+// featureTrafPol indicates if traffic policing is enabled
+const featureTrafPol = false
+
+// newTrafPol returns nil because traffic policing is disabled
+func newTrafPol([]string) trafPolicer {
+	return nil
+}
+
+// cleanupTrafPol cleans up traffic policing after a failed shutdown
+func cleanupTrafPol() {}
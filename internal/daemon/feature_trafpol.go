@@ -0,0 +1,18 @@
+//go:build !notrafpol
+
+package daemon
+
+import "github.com/telekom-mms/oc-daemon/internal/trafpol"
+
+// featureTrafPol indicates if traffic policing is enabled
+const featureTrafPol = true
+
+// newTrafPol returns a new traffic policing with allowed hosts
+func newTrafPol(allowedHosts []string) trafPolicer {
+	return trafpol.NewTrafPol(allowedHosts)
+}
+
+// cleanupTrafPol cleans up traffic policing after a failed shutdown
+func cleanupTrafPol() {
+	trafpol.Cleanup()
+}
@@ -0,0 +1,124 @@
+package daemon
+
+import (
+	"context"
+
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+	"github.com/telekom-mms/oc-daemon/internal/dnsproxy"
+)
+
+// Features that can be disabled at build time with the build tags nodbus,
+// nodnsproxy and notrafpol
+const (
+	FeatureDBus     = "dbus"
+	FeatureDNSProxy = "dnsproxy"
+	FeatureTrafPol  = "trafpol"
+)
+
+// Capabilities returns the features enabled at build time
+func Capabilities() []string {
+	caps := []string{}
+	if featureDBus {
+		caps = append(caps, FeatureDBus)
+	}
+	if featureDNSProxy {
+		caps = append(caps, FeatureDNSProxy)
+	}
+	if featureTrafPol {
+		caps = append(caps, FeatureTrafPol)
+	}
+	return caps
+}
+
+// apiCapabilities returns the capabilities of the daemon in the D-Bus API,
+// they depend on the build time features and the configuration
+func (d *Daemon) apiCapabilities() []string {
+	caps := []string{
+		dbusapi.CapabilityMultiTunnel,
+		dbusapi.CapabilityPreferredServer,
+		dbusapi.CapabilityCancel,
+		dbusapi.CapabilityConnectCached,
+		dbusapi.CapabilityPause,
+		dbusapi.CapabilityReconnect,
+		dbusapi.CapabilityConnectionObjects,
+		dbusapi.CapabilitySplitExcludes,
+		dbusapi.CapabilityValidateConfig,
+		dbusapi.CapabilityNotificationPolicy,
+		dbusapi.CapabilityUsage,
+		dbusapi.CapabilityMetrics,
+	}
+	if d.config.StatsInterval > 0 {
+		caps = append(caps, dbusapi.CapabilityStats)
+	}
+	if d.config.DeviceAuth.Enabled() {
+		caps = append(caps, dbusapi.CapabilityDeviceAuth)
+	}
+	if featureDNSProxy {
+		caps = append(caps, dbusapi.CapabilityDNSProxy)
+	}
+	if featureTrafPol && !noTrafPol {
+		caps = append(caps, dbusapi.CapabilityTrafPol)
+	}
+	return caps
+}
+
+// setCapabilities sets the capabilities of the daemon in the D-Bus API
+func (d *Daemon) setCapabilities() {
+	d.setProperty(dbusapi.PropertyCapabilities, d.apiCapabilities())
+}
+
+// dbusService is the D-Bus API service used by the daemon
+type dbusService interface {
+	Start(ctx context.Context) error
+	Stop()
+	Requests() chan *dbusapi.Request
+	SetProperty(name string, value any)
+	AddTunnel(name string)
+	SetTunnelProperty(tunnel, name string, value any)
+	EmitSignal(name string, values ...any)
+}
+
+// noDBusService is a D-Bus API service that does nothing
+type noDBusService struct{}
+
+// Start starts the service
+func (noDBusService) Start(context.Context) error { return nil }
+
+// Stop stops the service
+func (noDBusService) Stop() {}
+
+// Requests returns the requests channel, it never receives requests
+func (noDBusService) Requests() chan *dbusapi.Request { return nil }
+
+// SetProperty sets property with name to value
+func (noDBusService) SetProperty(string, any) {}
+
+// AddTunnel adds the additional tunnel with name
+func (noDBusService) AddTunnel(string) {}
+
+// EmitSignal emits the signal with name and values
+func (noDBusService) EmitSignal(string, ...any) {}
+
+// SetTunnelProperty sets property with name of the additional tunnel to value
+func (noDBusService) SetTunnelProperty(string, string, any) {}
+
+// dnsProxy is the DNS-Proxy used by the daemon
+type dnsProxy interface {
+	Start(ctx context.Context) error
+	Stop()
+	Reports() chan *dnsproxy.Report
+	SetRemotes(remotes map[string][]string)
+	SetWatches(watches []string)
+	SetFallback(servers []string)
+	SetTunnelAll(tunnelAll bool)
+	SetDevice(device string)
+	SetFallbackMark(mark int)
+	Leaks() uint64
+	Version() uint64
+}
+
+// trafPolicer is the traffic policing used by the daemon
+type trafPolicer interface {
+	Start(ctx context.Context) error
+	Stop()
+}
@@ -0,0 +1,123 @@
+package daemon
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+	"github.com/telekom-mms/oc-daemon/internal/grpcapi"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// TestCapabilities tests Capabilities
+func TestCapabilities(t *testing.T) {
+	want := []string{}
+	for _, f := range []struct {
+		name    string
+		enabled bool
+	}{
+		{FeatureDBus, featureDBus},
+		{FeatureDNSProxy, featureDNSProxy},
+		{FeatureTrafPol, featureTrafPol},
+	} {
+		if f.enabled {
+			want = append(want, f.name)
+		}
+	}
+	got := Capabilities()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// testDBusService is a D-Bus API service that records property updates
+// and signals
+type testDBusService struct {
+	noDBusService
+	props   map[string]any
+	signals []string
+}
+
+// SetProperty sets property with name to value
+func (t *testDBusService) SetProperty(name string, value any) {
+	t.props[name] = value
+}
+
+// EmitSignal records the signal with name
+func (t *testDBusService) EmitSignal(name string, _ ...any) {
+	t.signals = append(t.signals, name)
+}
+
+// TestDaemonSetStatusAPIs tests setStatusAPIs of Daemon
+func TestDaemonSetStatusAPIs(t *testing.T) {
+	// without d-bus api
+	d := &Daemon{
+		dbus:   noDBusService{},
+		status: vpnstatus.New(),
+	}
+	d.setStatusAPIs()
+	want := []string{vpnstatus.APISocket}
+	if !reflect.DeepEqual(d.status.APIs, want) {
+		t.Errorf("got %v, want %v", d.status.APIs, want)
+	}
+
+	// with d-bus api
+	dbus := &testDBusService{props: make(map[string]any)}
+	d.dbus = dbus
+	d.setStatusAPIs()
+	want = []string{vpnstatus.APISocket, vpnstatus.APIDBus}
+	if !reflect.DeepEqual(d.status.APIs, want) {
+		t.Errorf("got %v, want %v", d.status.APIs, want)
+	}
+	if got := dbus.props[dbusapi.PropertyAPIs]; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// with grpc api
+	d.grpc = grpcapi.NewServer("test.sock")
+	d.setStatusAPIs()
+	want = []string{vpnstatus.APISocket, vpnstatus.APIDBus, vpnstatus.APIGRPC}
+	if !reflect.DeepEqual(d.status.APIs, want) {
+		t.Errorf("got %v, want %v", d.status.APIs, want)
+	}
+}
+
+// TestDaemonSetCapabilities tests setCapabilities of Daemon
+func TestDaemonSetCapabilities(t *testing.T) {
+	dbus := &testDBusService{props: make(map[string]any)}
+	d := &Daemon{
+		config: NewConfig(),
+		dbus:   dbus,
+	}
+	d.config.StatsInterval = 0
+	d.config.DeviceAuth.AuthorizationURL = "https://idp.example.com/device"
+	d.config.DeviceAuth.TokenURL = "https://idp.example.com/token"
+	d.config.DeviceAuth.ClientID = "oc-daemon"
+	d.setCapabilities()
+
+	want := []string{
+		dbusapi.CapabilityMultiTunnel,
+		dbusapi.CapabilityPreferredServer,
+		dbusapi.CapabilityCancel,
+		dbusapi.CapabilityConnectCached,
+		dbusapi.CapabilityPause,
+		dbusapi.CapabilityReconnect,
+		dbusapi.CapabilityConnectionObjects,
+		dbusapi.CapabilitySplitExcludes,
+		dbusapi.CapabilityValidateConfig,
+		dbusapi.CapabilityNotificationPolicy,
+		dbusapi.CapabilityUsage,
+		dbusapi.CapabilityMetrics,
+		dbusapi.CapabilityDeviceAuth,
+	}
+	if featureDNSProxy {
+		want = append(want, dbusapi.CapabilityDNSProxy)
+	}
+	if featureTrafPol {
+		want = append(want, dbusapi.CapabilityTrafPol)
+	}
+	got := dbus.props[dbusapi.PropertyCapabilities]
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
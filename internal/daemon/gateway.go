@@ -0,0 +1,279 @@
+package daemon
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/clock"
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+)
+
+const (
+	// gatewayResolveInterval is the interval of resolving the host name
+	// of the VPN gateway while connected
+	gatewayResolveInterval = time.Minute
+
+	// gatewayResolveTimeout is the timeout of resolving the host name of
+	// the VPN gateway
+	gatewayResolveTimeout = 10 * time.Second
+)
+
+// lookupGateway resolves the host name of the VPN gateway, it can be replaced
+// for testing
+var lookupGateway = func(ctx context.Context, host string) ([]net.IP, error) {
+	return net.DefaultResolver.LookupIP(ctx, "ip", host)
+}
+
+// gatewayHost returns the host name of the VPN gateway in the server address
+// of the login info, it is empty if the address is an IP address
+func gatewayHost(address string) string {
+	if address == "" {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(serverDialAddress(address))
+	if err != nil || net.ParseIP(host) != nil {
+		return ""
+	}
+	return host
+}
+
+// gatewayResult is the result of resolving the host name of the VPN gateway
+type gatewayResult struct {
+	generation uint64
+	host       string
+	ips        []net.IP
+	err        error
+}
+
+// gatewayMonitor tracks the addresses of the VPN gateway during a connection,
+// i.e., the address openconnect connected to, the addresses it roams to on
+// reconnect attempts and the addresses in the DNS answers of the gateway's
+// host name, which is resolved periodically, so the addresses of a new
+// gateway after a failover are excluded from the tunnel before openconnect
+// reconnects to them
+type gatewayMonitor struct {
+	clock   clock.Clock
+	timer   clock.Timer
+	results chan *gatewayResult
+
+	// host is the host name of the gateway, empty if it is not resolved
+	host string
+
+	// addresses are the known addresses of the gateway
+	addresses []net.IP
+
+	// resolving specifies if the host name is being resolved
+	resolving bool
+
+	// generation identifies the tracked connection, so results of
+	// previous connections are ignored
+	generation uint64
+}
+
+// schedule schedules the next resolution of the host name
+func (g *gatewayMonitor) schedule() {
+	if g.timer != nil {
+		g.timer.Stop()
+	}
+	g.timer = g.clock.NewTimer(gatewayResolveInterval)
+}
+
+// start starts tracking the gateway addresses of a new connection with the
+// host name of the gateway, empty if it should not be resolved
+func (g *gatewayMonitor) start(host string) {
+	g.stop()
+	g.host = host
+	if host != "" {
+		g.schedule()
+	}
+}
+
+// stop stops tracking the gateway addresses
+func (g *gatewayMonitor) stop() {
+	if g.timer != nil {
+		g.timer.Stop()
+		g.timer = nil
+	}
+	g.host = ""
+	g.addresses = nil
+	g.resolving = false
+	g.generation++
+}
+
+// add adds ip to the known gateway addresses, it returns false if ip is
+// invalid or already known
+func (g *gatewayMonitor) add(ip net.IP) bool {
+	if len(ip) == 0 || ip.IsUnspecified() {
+		return false
+	}
+	for _, a := range g.addresses {
+		if a.Equal(ip) {
+			return false
+		}
+	}
+	g.addresses = append(g.addresses, ip)
+	return true
+}
+
+// resolve starts resolving the host name in the background unless it is
+// already being resolved, the result is sent to the results channel until
+// done is closed
+func (g *gatewayMonitor) resolve(done <-chan struct{}) {
+	if g.host == "" || g.resolving {
+		return
+	}
+	g.resolving = true
+	generation := g.generation
+	host := g.host
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(),
+			gatewayResolveTimeout)
+		defer cancel()
+		ips, err := lookupGateway(ctx, host)
+		select {
+		case g.results <- &gatewayResult{
+			generation: generation,
+			host:       host,
+			ips:        ips,
+			err:        err,
+		}:
+		case <-done:
+		}
+	}()
+}
+
+// handleResult handles the result r of resolving the host name and
+// schedules the next resolution, it returns false if r is outdated, e.g.,
+// from a previous connection
+func (g *gatewayMonitor) handleResult(r *gatewayResult) bool {
+	if r.generation != g.generation {
+		return false
+	}
+	g.resolving = false
+	if g.host == "" {
+		return false
+	}
+	g.schedule()
+	return true
+}
+
+// timerC returns the channel of the resolve timer or nil if the host name is
+// not resolved
+func (g *gatewayMonitor) timerC() <-chan time.Time {
+	if g.timer == nil {
+		return nil
+	}
+	return g.timer.C()
+}
+
+// resultsC returns the channel of the resolve results
+func (g *gatewayMonitor) resultsC() <-chan *gatewayResult {
+	return g.results
+}
+
+// newGatewayMonitor returns a new gatewayMonitor
+func newGatewayMonitor() *gatewayMonitor {
+	return &gatewayMonitor{
+		clock:   clock.New(),
+		results: make(chan *gatewayResult),
+	}
+}
+
+// setStatusGatewayExcludes sets the gateway excludes in status
+func (d *Daemon) setStatusGatewayExcludes(excludes []string) {
+	if reflect.DeepEqual(d.status.GatewayExcludes, excludes) {
+		// excludes not changed
+		return
+	}
+
+	// excludes changed
+	d.status.GatewayExcludes = excludes
+	d.setProperty(dbusapi.PropertyGatewayExcludes, excludes)
+}
+
+// updateGatewayExcludes adds the known gateway addresses to the split
+// excludes and updates the status, the excludes are kept until split routing
+// stops
+func (d *Daemon) updateGatewayExcludes() {
+	if d.splitrt == nil {
+		d.setStatusGatewayExcludes(dbusapi.GatewayExcludesInvalid)
+		return
+	}
+	excludes := []string{}
+	for _, ip := range d.gateway.addresses {
+		d.splitrt.AddGateway(ip)
+	}
+	for _, e := range d.splitrt.GatewayExcludes() {
+		excludes = append(excludes, e.String())
+	}
+	d.setStatusGatewayExcludes(excludes)
+}
+
+// addGatewayAddresses adds the addresses ips of the VPN gateway to the
+// gateway excludes
+func (d *Daemon) addGatewayAddresses(ips []net.IP, source string) {
+	for _, ip := range ips {
+		if !d.gateway.add(ip) {
+			continue
+		}
+		log.WithFields(logrus.Fields{
+			"address": ip,
+			"source":  source,
+		}).Info("Daemon excluding VPN gateway address from tunnel")
+	}
+	d.updateGatewayExcludes()
+}
+
+// startGateway starts tracking the addresses of the VPN gateway of a new
+// connection, gateway is the address openconnect connected to
+func (d *Daemon) startGateway(gateway net.IP) {
+	host := ""
+	if login := d.reconnect.getLogin(); login != nil {
+		host = gatewayHost(login.Host)
+	}
+	d.gateway.start(host)
+	d.addGatewayAddresses([]net.IP{gateway}, "config")
+	d.gateway.resolve(d.done)
+}
+
+// stopGateway stops tracking the addresses of the VPN gateway
+func (d *Daemon) stopGateway() {
+	d.gateway.stop()
+	d.setStatusGatewayExcludes(dbusapi.GatewayExcludesInvalid)
+}
+
+// handleGatewayTimer resolves the host name of the VPN gateway after the
+// resolve timer expired
+func (d *Daemon) handleGatewayTimer() {
+	d.gateway.resolve(d.done)
+}
+
+// handleGatewayResult handles the result of resolving the host name of the
+// VPN gateway
+func (d *Daemon) handleGatewayResult(r *gatewayResult) {
+	if !d.gateway.handleResult(r) {
+		// outdated result, resolve the host name of the current
+		// connection unless it is already being resolved
+		d.gateway.resolve(d.done)
+		return
+	}
+	if r.err != nil {
+		log.WithError(r.err).WithField("host", r.host).
+			Debug("Daemon could not resolve VPN gateway")
+		return
+	}
+	d.addGatewayAddresses(r.ips, "dns")
+}
+
+// handleGatewayUpdate handles the address of the VPN gateway reported by
+// the vpnc-script when openconnect attempts to reconnect, the gateway may
+// have a new address, e.g., after a failover
+func (d *Daemon) handleGatewayUpdate(gateway net.IP) {
+	d.addGatewayAddresses([]net.IP{gateway}, "reconnect")
+
+	// the DNS answer for the gateway may have changed, too
+	d.gateway.resolve(d.done)
+}
@@ -0,0 +1,167 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/clock"
+	"github.com/telekom-mms/oc-daemon/pkg/logininfo"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// TestGatewayHost tests gatewayHost
+func TestGatewayHost(t *testing.T) {
+	for address, want := range map[string]string{
+		"":                              "",
+		"vpn.example.com":               "vpn.example.com",
+		"vpn.example.com:8443":          "vpn.example.com",
+		"https://vpn.example.com/group": "vpn.example.com",
+		"192.168.1.1":                   "",
+		"[2001::1]:443":                 "",
+	} {
+		if got := gatewayHost(address); got != want {
+			t.Errorf("%q: got %q, want %q", address, got, want)
+		}
+	}
+}
+
+// TestGatewayMonitor tests start, add, resolve, handleResult and stop of
+// gatewayMonitor
+func TestGatewayMonitor(t *testing.T) {
+	old := lookupGateway
+	defer func() { lookupGateway = old }()
+	lookupGateway = func(_ context.Context, host string) ([]net.IP, error) {
+		return []net.IP{net.ParseIP("192.168.1.2")}, nil
+	}
+
+	c := clock.NewFake(time.Now())
+	g := &gatewayMonitor{clock: c, results: make(chan *gatewayResult)}
+	done := make(chan struct{})
+	defer close(done)
+
+	// without host name
+	g.start("")
+	g.resolve(done)
+	if g.timerC() != nil || g.resolving {
+		t.Error("gateway without host name should not be resolved")
+	}
+
+	// addresses
+	g.start("vpn.example.com")
+	for _, invalid := range []net.IP{nil, net.IPv4zero} {
+		if g.add(invalid) {
+			t.Errorf("%s: invalid address should not be added", invalid)
+		}
+	}
+	if !g.add(net.ParseIP("192.168.1.1")) {
+		t.Error("address should be added")
+	}
+	if g.add(net.ParseIP("192.168.1.1")) {
+		t.Error("address should not be added twice")
+	}
+
+	// resolve
+	g.resolve(done)
+	g.resolve(done)
+	r := <-g.resultsC()
+	want := &gatewayResult{
+		generation: g.generation,
+		host:       "vpn.example.com",
+		ips:        []net.IP{net.ParseIP("192.168.1.2")},
+	}
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("got %v, want %v", r, want)
+	}
+	if !g.handleResult(r) {
+		t.Error("result should be handled")
+	}
+	c.Advance(gatewayResolveInterval)
+	<-g.timerC()
+
+	// outdated result
+	g.stop()
+	if g.handleResult(r) || g.timerC() != nil || g.addresses != nil {
+		t.Error("outdated result should not be handled")
+	}
+
+	// new connection while resolving, the pending result is outdated
+	g.start("vpn.example.com")
+	g.resolve(done)
+	r = <-g.resultsC()
+	g.start("vpn2.example.com")
+	g.resolve(done)
+	if !g.resolving {
+		t.Error("new host name should be resolved")
+	}
+	if g.handleResult(r) || !g.resolving {
+		t.Error("outdated result should not be handled")
+	}
+	r = <-g.resultsC()
+	if r.host != "vpn2.example.com" || !g.handleResult(r) {
+		t.Errorf("result of new host should be handled: %v", r)
+	}
+}
+
+// TestDaemonHandleGatewayResult tests startGateway, handleGatewayResult,
+// handleGatewayUpdate and stopGateway of Daemon without split routing
+func TestDaemonHandleGatewayResult(t *testing.T) {
+	old := lookupGateway
+	defer func() { lookupGateway = old }()
+	lookupGateway = func(context.Context, string) ([]net.IP, error) {
+		return nil, errors.New("test error")
+	}
+
+	config := NewConfig()
+	d := &Daemon{
+		config:    config,
+		dbus:      &testDBusService{props: make(map[string]any)},
+		status:    vpnstatus.New(),
+		reconnect: newReconnect(&config.ReconnectPolicy),
+		gateway:   newGatewayMonitor(),
+		done:      make(chan struct{}),
+	}
+	d.reconnect.setLogin(&logininfo.LoginInfo{Host: "vpn.example.com"})
+
+	// connect, resolving fails
+	d.startGateway(net.ParseIP("192.168.1.1"))
+	d.handleGatewayResult(<-d.gateway.resultsC())
+
+	// reconnect attempt to new address, resolving succeeds
+	lookupGateway = func(context.Context, string) ([]net.IP, error) {
+		return []net.IP{net.ParseIP("192.168.1.3")}, nil
+	}
+	d.handleGatewayUpdate(net.ParseIP("192.168.1.2"))
+	d.handleGatewayResult(<-d.gateway.resultsC())
+
+	want := []net.IP{
+		net.ParseIP("192.168.1.1"),
+		net.ParseIP("192.168.1.2"),
+		net.ParseIP("192.168.1.3"),
+	}
+	if !reflect.DeepEqual(d.gateway.addresses, want) {
+		t.Errorf("got %v, want %v", d.gateway.addresses, want)
+	}
+
+	// no split routing, so no excludes in status
+	if d.status.GatewayExcludes != nil {
+		t.Errorf("got %v, want no excludes", d.status.GatewayExcludes)
+	}
+
+	// disconnect
+	d.stopGateway()
+	if d.gateway.addresses != nil || d.gateway.timerC() != nil {
+		t.Error("gateway should not be tracked after disconnect")
+	}
+
+	// outdated result of previous connection, current host is resolved
+	stale := &gatewayResult{generation: d.gateway.generation}
+	d.gateway.start("vpn2.example.com")
+	d.handleGatewayResult(stale)
+	if r := <-d.gateway.resultsC(); r.host != "vpn2.example.com" {
+		t.Errorf("got %s, want vpn2.example.com", r.host)
+	}
+}
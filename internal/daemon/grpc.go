@@ -0,0 +1,53 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/grpcapi"
+	"github.com/telekom-mms/oc-daemon/internal/logging"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// grpcRequests returns the requests channel of the gRPC API server, nil if
+// the gRPC API is disabled
+func (d *Daemon) grpcRequests() chan *grpcapi.Request {
+	if d.grpc == nil {
+		return nil
+	}
+	return d.grpc.Requests()
+}
+
+// handleGRPCRequest handles a gRPC API client request
+func (d *Daemon) handleGRPCRequest(request *grpcapi.Request) {
+	defer request.Close()
+	start := time.Now()
+	defer func() {
+		d.observeRequest(vpnstatus.APIGRPC, request.Name, request.ID, start,
+			request.Error != nil, request.Parameters)
+	}()
+	log := log.WithField(logging.RequestField, request.ID)
+	log.WithField("method", request.Name).
+		Debug("Daemon handling gRPC client request")
+
+	switch request.Name {
+	case grpcapi.RequestGetStatus:
+		// get copy of complete vpn status
+		request.Results = []any{d.status.Copy()}
+
+	case grpcapi.RequestUpdateVPNConfig:
+		// update VPN config like the vpnc-script on the unix socket
+		reason, _ := request.Parameters[0].(string)
+		challenge, _ := request.Parameters[1].([]byte)
+		response, _ := request.Parameters[2].([]byte)
+		config, _ := request.Parameters[3].(*vpnconfig.Config)
+		configUpdate := &VPNConfigUpdate{
+			Reason:   reason,
+			Response: response,
+			Config:   config,
+		}
+		log.WithField("reason", reason).
+			Debug("Daemon handling gRPC vpn config update")
+		request.Error = d.applyVPNConfigUpdate(log, configUpdate, challenge)
+	}
+}
@@ -0,0 +1,69 @@
+package daemon
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/internal/grpcapi"
+	pb "github.com/telekom-mms/oc-daemon/pkg/grpcapi"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// TestDaemonHandleGRPCRequest tests handleGRPCRequest of Daemon
+func TestDaemonHandleGRPCRequest(t *testing.T) {
+	d := &Daemon{
+		config: NewConfig(),
+		dbus:   noDBusService{},
+		status: vpnstatus.New(),
+	}
+
+	// without grpc api
+	if d.grpcRequests() != nil {
+		t.Error("requests should be nil without gRPC API")
+	}
+
+	// with grpc api
+	sockFile := filepath.Join(t.TempDir(), "grpc.sock")
+	d.grpc = grpcapi.NewServer(sockFile)
+	if err := d.grpc.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer d.grpc.Stop()
+	conn, err := grpc.Dial("unix://"+sockFile,
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	client := pb.NewDaemonClient(conn)
+
+	// get status
+	d.status.ConnectionState = vpnstatus.ConnectionStateConnected
+	go func() { d.handleGRPCRequest(<-d.grpcRequests()) }()
+	s, err := client.GetStatus(context.Background(), &pb.GetStatusRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.GetConnectionState() != uint32(vpnstatus.ConnectionStateConnected) {
+		t.Errorf("got %d, want connected", s.GetConnectionState())
+	}
+
+	// invalid config update
+	c, err := client.GetChallenge(context.Background(), &pb.GetChallengeRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { d.handleGRPCRequest(<-d.grpcRequests()) }()
+	_, err = client.UpdateVPNConfig(context.Background(), &pb.VPNConfigUpdate{
+		Reason:    "invalid",
+		Challenge: c.GetChallenge(),
+	})
+	if status.Code(err) != codes.Aborted {
+		t.Errorf("got %v, want %s", err, codes.Aborted)
+	}
+}
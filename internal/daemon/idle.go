@@ -0,0 +1,92 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/clock"
+)
+
+// idleCheckInterval is the maximum interval between idle checks
+const idleCheckInterval = time.Minute
+
+// idleMonitor detects idle VPN connections without traffic for a timeout
+// with the packet counters of the VPN device
+type idleMonitor struct {
+	clock   clock.Clock
+	timer   clock.Timer
+	timeout time.Duration
+
+	// packets is the packet counter of the last check, active is the
+	// time of the last traffic
+	packets uint64
+	active  time.Time
+
+	// idle specifies if the idle timeout has been reported, it is
+	// reported once until there is traffic again
+	idle bool
+}
+
+// interval returns the interval between idle checks
+func (i *idleMonitor) interval() time.Duration {
+	if i.timeout < idleCheckInterval {
+		return i.timeout
+	}
+	return idleCheckInterval
+}
+
+// schedule schedules the next idle check
+func (i *idleMonitor) schedule() {
+	i.timer = i.clock.NewTimer(i.interval())
+}
+
+// start starts monitoring with timeout and the current packet counter
+func (i *idleMonitor) start(timeout time.Duration, packets uint64) {
+	i.stop()
+	i.timeout = timeout
+	i.packets = packets
+	i.active = i.clock.Now()
+	i.idle = false
+	i.schedule()
+}
+
+// stop stops monitoring
+func (i *idleMonitor) stop() {
+	if i.timer != nil {
+		i.timer.Stop()
+		i.timer = nil
+	}
+}
+
+// check checks the current packet counter after the check timer expired
+// and schedules the next check, it returns true once the timeout elapsed
+// without traffic
+func (i *idleMonitor) check(packets uint64) bool {
+	now := i.clock.Now()
+	if packets != i.packets {
+		i.packets = packets
+		i.active = now
+		i.idle = false
+	}
+	i.schedule()
+	if i.idle || now.Sub(i.active) < i.timeout {
+		return false
+	}
+	i.idle = true
+	return true
+}
+
+// timerC returns the channel of the check timer or nil if monitoring is
+// not running
+func (i *idleMonitor) timerC() <-chan time.Time {
+	if i.timer == nil {
+		return nil
+	}
+	return i.timer.C()
+}
+
+// newIdleMonitor returns a new idleMonitor
+func newIdleMonitor() *idleMonitor {
+	return &idleMonitor{
+		clock: clock.New(),
+	}
+}
@@ -0,0 +1,134 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/clock"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// TestIdleMonitor tests idleMonitor
+func TestIdleMonitor(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	i := newIdleMonitor()
+	i.clock = fake
+
+	// not started
+	if i.timerC() != nil {
+		t.Error("timer should not run")
+	}
+
+	// check interval is limited by timeout
+	i.start(30*time.Second, 10)
+	if i.interval() != 30*time.Second {
+		t.Errorf("got %s, want 30s", i.interval())
+	}
+	i.start(10*time.Minute, 10)
+	if i.interval() != idleCheckInterval {
+		t.Errorf("got %s, want %s", i.interval(), idleCheckInterval)
+	}
+
+	// traffic resets idle time
+	for j := 0; j < 10; j++ {
+		fake.Advance(time.Minute)
+		<-i.timerC()
+		if i.check(uint64(11 + j)) {
+			t.Error("connection with traffic should not be idle")
+		}
+	}
+
+	// no traffic
+	for j := 0; j < 9; j++ {
+		fake.Advance(time.Minute)
+		<-i.timerC()
+		if i.check(20) {
+			t.Error("connection should not be idle before timeout")
+		}
+	}
+	fake.Advance(time.Minute)
+	<-i.timerC()
+	if !i.check(20) {
+		t.Error("connection should be idle after timeout")
+	}
+
+	// idle only reported once
+	fake.Advance(time.Minute)
+	<-i.timerC()
+	if i.check(20) {
+		t.Error("idle connection should only be reported once")
+	}
+
+	// stopped
+	i.stop()
+	if i.timerC() != nil {
+		t.Error("timer should not run")
+	}
+}
+
+// TestDaemonHandleIdleCheck tests handleIdleCheck of Daemon
+func TestDaemonHandleIdleCheck(t *testing.T) {
+	dir := t.TempDir()
+	old := sysClassNet
+	sysClassNet = dir
+	defer func() { sysClassNet = old }()
+
+	stats := filepath.Join(dir, "tun0", "statistics")
+	if err := os.MkdirAll(stats, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{
+		"rx_bytes", "tx_bytes", "rx_packets", "tx_packets",
+	} {
+		if err := os.WriteFile(filepath.Join(stats, name),
+			[]byte("10\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fake := clock.NewFake(time.Now())
+	config := NewConfig()
+	config.IdlePolicy = IdlePolicy{
+		Timeout: time.Minute,
+		Action:  IdleActionNotify,
+	}
+	d := &Daemon{
+		config: config,
+		dbus:   noDBusService{},
+		status: vpnstatus.New(),
+		idle:   newIdleMonitor(),
+	}
+	d.idle.clock = fake
+	d.status.Device = "tun0"
+
+	// not connected
+	d.startIdle()
+	if d.idle.timerC() != nil {
+		t.Error("idle detection should not run when not connected")
+	}
+
+	// connected, idle after timeout
+	d.status.ConnectionState = vpnstatus.ConnectionStateConnected
+	d.startIdle()
+	defer d.idle.stop()
+	if d.idle.packets != 20 {
+		t.Errorf("got %d, want 20", d.idle.packets)
+	}
+	fake.Advance(time.Minute)
+	<-d.idle.timerC()
+	d.handleIdleCheck()
+	if !d.idle.idle {
+		t.Error("connection should be idle")
+	}
+
+	// device removed, check is scheduled again
+	if err := os.RemoveAll(filepath.Join(dir, "tun0")); err != nil {
+		t.Fatal(err)
+	}
+	d.handleIdleCheck()
+	if d.idle.timerC() == nil {
+		t.Error("idle check should be scheduled")
+	}
+}
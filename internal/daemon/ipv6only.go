@@ -0,0 +1,89 @@
+package daemon
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// resolvedUpstreamConf is the resolv.conf file of systemd-resolved that
+// contains the upstream DNS servers of the host
+var resolvedUpstreamConf = "/run/systemd/resolve/resolv.conf"
+
+// hasIPv4Loopback returns whether the host has an IPv4 loopback address,
+// hosts without are IPv6-only; it can be replaced for testing
+var hasIPv4Loopback = func() bool {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		log.WithError(err).Error("Daemon could not get interface addresses")
+		return true
+	}
+	for _, a := range addrs {
+		if ipnet, ok := a.(*net.IPNet); ok &&
+			ipnet.IP.To4() != nil && ipnet.IP.IsLoopback() {
+			return true
+		}
+	}
+	return false
+}
+
+// isIPv4Loopback returns whether address is an IPv4 loopback address
+func isIPv4Loopback(address string) bool {
+	ip := net.ParseIP(address)
+	return ip.To4() != nil && ip.IsLoopback()
+}
+
+// dnsProxyAddress returns the listen address of the DNS-Proxy in config
+// including the port; on IPv6-only hosts, an IPv4 loopback address is
+// replaced with ::1
+func dnsProxyAddress(config *DNSProxy) string {
+	if isIPv4Loopback(config.Address) && !hasIPv4Loopback() {
+		log.Info("Daemon using IPv6 loopback address for DNS-Proxy on IPv6-only host")
+		return net.JoinHostPort("::1", strconv.Itoa(int(config.Port)))
+	}
+	return config.ListenAddress()
+}
+
+// readIPv6Nameservers returns the IPv6 name servers in the resolv.conf file
+// including port 53
+func readIPv6Nameservers(file string) []string {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	servers := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "nameserver" {
+			continue
+		}
+		addr := strings.SplitN(fields[1], "%", 2)[0]
+		if ip := net.ParseIP(addr); ip == nil || ip.To4() != nil {
+			continue
+		}
+		servers = append(servers, net.JoinHostPort(fields[1], "53"))
+	}
+	return servers
+}
+
+// dnsProxyFallback returns the fallback resolvers of the DNS-Proxy in
+// config; on IPv6-only hosts, a fallback resolver on an IPv4 loopback
+// address, e.g., the stub resolver of systemd-resolved, is replaced with the
+// IPv6 upstream DNS servers of the host
+func dnsProxyFallback(config *DNSProxy) []string {
+	host, _, err := net.SplitHostPort(config.Fallback)
+	if err != nil || !isIPv4Loopback(host) || hasIPv4Loopback() {
+		return []string{config.Fallback}
+	}
+	servers := readIPv6Nameservers(resolvedUpstreamConf)
+	if len(servers) == 0 {
+		log.Error("Daemon found no IPv6 fallback resolver on IPv6-only host")
+		return []string{config.Fallback}
+	}
+	return servers
+}
@@ -0,0 +1,72 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestDNSProxyAddress tests dnsProxyAddress
+func TestDNSProxyAddress(t *testing.T) {
+	defer func(f func() bool) { hasIPv4Loopback = f }(hasIPv4Loopback)
+	ipv4 := true
+	hasIPv4Loopback = func() bool { return ipv4 }
+
+	for _, test := range []struct {
+		ipv4    bool
+		address string
+		want    string
+	}{
+		{true, "127.0.0.1", "127.0.0.1:4253"},
+		{false, "127.0.0.1", "[::1]:4253"},
+		{false, "::1", "[::1]:4253"},
+		{false, "192.168.1.1", "192.168.1.1:4253"},
+	} {
+		ipv4 = test.ipv4
+		config := &DNSProxy{Address: test.address, Port: 4253}
+		if got := dnsProxyAddress(config); got != test.want {
+			t.Errorf("got %s, want %s", got, test.want)
+		}
+	}
+}
+
+// TestDNSProxyFallback tests dnsProxyFallback
+func TestDNSProxyFallback(t *testing.T) {
+	defer func(f func() bool) { hasIPv4Loopback = f }(hasIPv4Loopback)
+	defer func(file string) { resolvedUpstreamConf = file }(resolvedUpstreamConf)
+	ipv4 := true
+	hasIPv4Loopback = func() bool { return ipv4 }
+	resolvedUpstreamConf = filepath.Join(t.TempDir(), "resolv.conf")
+	config := &NewConfig().DNSProxy
+
+	// host with ipv4
+	want := []string{"127.0.0.53:53"}
+	if got := dnsProxyFallback(config); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// ipv6-only host without upstream servers
+	ipv4 = false
+	if got := dnsProxyFallback(config); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// ipv6-only host with upstream servers
+	conf := "# comment\nnameserver 192.168.1.1\nnameserver 2001:db8::53\n" +
+		"nameserver fe80::1%eth0\nsearch example.com\n"
+	if err := os.WriteFile(resolvedUpstreamConf, []byte(conf), 0644); err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"[2001:db8::53]:53", "[fe80::1%eth0]:53"}
+	if got := dnsProxyFallback(config); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// ipv6-only host with configured ipv6 fallback
+	config.Fallback = "[2001:db8::1]:53"
+	want = []string{"[2001:db8::1]:53"}
+	if got := dnsProxyFallback(config); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
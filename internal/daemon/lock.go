@@ -0,0 +1,63 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+var (
+	// lockFile is the pid file used as run lock of the daemon
+	lockFile = runDir + "/daemon.pid"
+)
+
+// runLock is a run lock that ensures only one daemon instance is running
+type runLock struct {
+	file *os.File
+}
+
+// release releases the run lock
+func (r *runLock) release() {
+	_ = r.file.Truncate(0)
+	_ = unix.Flock(int(r.file.Fd()), unix.LOCK_UN)
+	_ = r.file.Close()
+}
+
+// acquireRunLock acquires the run lock on file and writes the pid of the
+// current process into it, returns an error containing the pid of the
+// running daemon if the lock is held by another process
+func acquireRunLock(file string) (*runLock, error) {
+	f, err := os.OpenFile(file, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := unix.Flock(int(f.Fd()), unix.LOCK_EX|unix.LOCK_NB); err != nil {
+		defer func() { _ = f.Close() }()
+		if !errors.Is(err, unix.EWOULDBLOCK) {
+			return nil, err
+		}
+		b := make([]byte, 32)
+		n, _ := f.Read(b)
+		pid := strings.TrimSpace(string(b[:n]))
+		if pid == "" {
+			pid = "unknown"
+		}
+		return nil, fmt.Errorf("another daemon instance is already "+
+			"running with PID %s (lock file %s)", pid, file)
+	}
+
+	if err := f.Truncate(0); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if _, err := f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &runLock{file: f}, nil
+}
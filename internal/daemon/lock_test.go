@@ -0,0 +1,81 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/internal/ocrunner"
+)
+
+// TestAcquireRunLock tests acquireRunLock
+func TestAcquireRunLock(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "daemon.pid")
+
+	// acquire lock
+	l, err := acquireRunLock(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strconv.Itoa(os.Getpid()) + "\n"
+	if string(b) != want {
+		t.Errorf("got %q, want %q", b, want)
+	}
+
+	// acquire lock again, should fail and report pid
+	if _, err := acquireRunLock(file); err == nil {
+		t.Error("second lock should fail")
+	} else if !strings.Contains(err.Error(), strconv.Itoa(os.Getpid())) {
+		t.Errorf("error should contain pid: %v", err)
+	}
+
+	// release lock and acquire it again
+	l.release()
+	l, err = acquireRunLock(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.release()
+
+	// test invalid file
+	if _, err := acquireRunLock(filepath.Join(file, "invalid")); err == nil {
+		t.Error("invalid file should fail")
+	}
+}
+
+// TestSetRuntimePaths tests setRuntimePaths
+func TestSetRuntimePaths(t *testing.T) {
+	oldSock, oldLock, oldPID := sockFile, lockFile, ocrunner.PIDFile
+	oldManifest, oldSecretDir, oldGRPC := manifestFile, secretDir, grpcSockFile
+	defer func() {
+		sockFile, lockFile, ocrunner.PIDFile = oldSock, oldLock, oldPID
+		manifestFile, secretDir, grpcSockFile = oldManifest, oldSecretDir, oldGRPC
+	}()
+
+	sockFile = "/run/oc-daemon-dev/daemon.sock"
+	setRuntimePaths()
+	if lockFile != "/run/oc-daemon-dev/daemon.pid" {
+		t.Errorf("got %s, want /run/oc-daemon-dev/daemon.pid", lockFile)
+	}
+	if ocrunner.PIDFile != "/run/oc-daemon-dev/openconnect.pid" {
+		t.Errorf("got %s, want /run/oc-daemon-dev/openconnect.pid",
+			ocrunner.PIDFile)
+	}
+	if manifestFile != "/run/oc-daemon-dev/manifest.json" {
+		t.Errorf("got %s, want /run/oc-daemon-dev/manifest.json",
+			manifestFile)
+	}
+	if secretDir != "/run/oc-daemon-dev" {
+		t.Errorf("got %s, want /run/oc-daemon-dev", secretDir)
+	}
+	if grpcSockFile != "/run/oc-daemon-dev/daemon-grpc.sock" {
+		t.Errorf("got %s, want /run/oc-daemon-dev/daemon-grpc.sock",
+			grpcSockFile)
+	}
+}
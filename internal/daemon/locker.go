@@ -0,0 +1,73 @@
+package daemon
+
+import (
+	"github.com/telekom-mms/oc-daemon/internal/eventbus"
+	"github.com/telekom-mms/oc-daemon/internal/logging"
+)
+
+// sessionLocker locks all sessions with logind when the VPN connection
+// dropped, it consumes the connection events of the event bus in its own
+// goroutine, so the main loop does not wait for logind
+type sessionLocker struct {
+	events *eventbus.Bus
+	sub    *eventbus.Subscription
+	done   chan struct{}
+}
+
+// run locks the sessions on connection drop events of sub until the
+// subscription is canceled
+func (s *sessionLocker) run(sub *eventbus.Subscription, done chan struct{}) {
+	defer close(done)
+	for e := range sub.Events() {
+		if _, ok := e.(*eventbus.ConnectionDropped); !ok {
+			continue
+		}
+		log.WithField(logging.CodeField, logging.CodeSessionsLocked).
+			Info("Daemon locking sessions after VPN connection drop")
+		if err := lockSessions(); err != nil {
+			log.WithError(err).Error("Daemon could not lock sessions")
+		}
+	}
+}
+
+// start starts the session locker
+func (s *sessionLocker) start() {
+	if s.running() {
+		return
+	}
+	s.sub = s.events.Subscribe("session locker", 8, eventbus.TopicConnection)
+	s.done = make(chan struct{})
+	go s.run(s.sub, s.done)
+}
+
+// stop stops the session locker, it waits for a running lock
+func (s *sessionLocker) stop() {
+	if !s.running() {
+		return
+	}
+	s.events.Unsubscribe(s.sub)
+	<-s.done
+	s.sub = nil
+}
+
+// running returns whether the session locker is running
+func (s *sessionLocker) running() bool {
+	return s.sub != nil
+}
+
+// newSessionLocker returns a new session locker that consumes events
+func newSessionLocker(events *eventbus.Bus) *sessionLocker {
+	return &sessionLocker{
+		events: events,
+	}
+}
+
+// checkSessionLocker starts or stops the session locker depending on the
+// LockOnDrop setting
+func (d *Daemon) checkSessionLocker() {
+	if d.config.LockOnDrop {
+		d.locker.start()
+		return
+	}
+	d.locker.stop()
+}
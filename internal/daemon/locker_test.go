@@ -0,0 +1,55 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/internal/eventbus"
+)
+
+// TestDaemonCheckSessionLocker tests checkSessionLocker of Daemon
+func TestDaemonCheckSessionLocker(t *testing.T) {
+	oldLockSessions := lockSessions
+	defer func() { lockSessions = oldLockSessions }()
+
+	locked := make(chan struct{}, 1)
+	lockSessions = func() error {
+		locked <- struct{}{}
+		return nil
+	}
+	events := eventbus.New()
+	defer events.Close()
+	d := &Daemon{
+		config: NewConfig(),
+		events: events,
+		locker: newSessionLocker(events),
+	}
+
+	// lock on drop disabled
+	d.checkSessionLocker()
+	if d.locker.running() {
+		t.Error("session locker should not run")
+	}
+
+	// lock on drop enabled, other connection events do not lock sessions
+	d.config.LockOnDrop = true
+	d.checkSessionLocker()
+	d.checkSessionLocker()
+	if !d.locker.running() {
+		t.Fatal("session locker should run")
+	}
+	d.publish(&eventbus.ConnectionStateChanged{})
+	d.publish(&eventbus.ConnectionDropped{})
+	<-locked
+
+	// lock on drop disabled again
+	d.config.LockOnDrop = false
+	d.checkSessionLocker()
+	if d.locker.running() {
+		t.Error("session locker should not run")
+	}
+	select {
+	case <-locked:
+		t.Error("sessions should only be locked once")
+	default:
+	}
+}
@@ -0,0 +1,6 @@
+package daemon
+
+import "github.com/telekom-mms/oc-daemon/internal/logging"
+
+// log is the logger of the daemon
+var log = logging.Component(logging.ComponentDaemon)
@@ -0,0 +1,31 @@
+package daemon
+
+import (
+	"github.com/telekom-mms/oc-daemon/internal/manifest"
+)
+
+var (
+	// manifestFile is the manifest file with the system changes of the
+	// daemon
+	manifestFile = runDir + "/manifest.json"
+)
+
+// cleanupManifest cleans up the system changes in the manifest of the last
+// run after a failed shutdown, it returns false if there is no valid
+// manifest
+func cleanupManifest() bool {
+	ok, err := manifest.Cleanup(manifestFile)
+	if err != nil {
+		log.WithError(err).Error("Daemon could not clean up with manifest")
+		return false
+	}
+	return ok
+}
+
+// openManifest starts recording the system changes of the daemon in the
+// manifest
+func openManifest() {
+	if err := manifest.Open(manifestFile); err != nil {
+		log.WithError(err).Error("Daemon could not open manifest")
+	}
+}
@@ -0,0 +1,27 @@
+package daemon
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/internal/manifest"
+)
+
+// TestCleanupManifest tests cleanupManifest
+func TestCleanupManifest(t *testing.T) {
+	oldFile := manifestFile
+	defer func() { manifestFile = oldFile }()
+	manifestFile = filepath.Join(t.TempDir(), "manifest.json")
+
+	// no manifest
+	if cleanupManifest() {
+		t.Error("cleanup without manifest should return false")
+	}
+
+	// empty manifest
+	openManifest()
+	defer manifest.Close()
+	if !cleanupManifest() {
+		t.Error("cleanup with manifest should return true")
+	}
+}
@@ -0,0 +1,96 @@
+package daemon
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/api"
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+	"github.com/telekom-mms/oc-daemon/internal/logging"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// socketRequestName returns the name of the unix socket API request type t
+func socketRequestName(t uint16) string {
+	switch t {
+	case api.TypeVPNConfigUpdate:
+		return "VPNConfigUpdate"
+	}
+	return fmt.Sprintf("Type%d", t)
+}
+
+// sanitizeParameters returns the request parameters as strings without
+// their contents that may be sensitive, e.g., passwords or cookies: strings
+// and byte slices are replaced by their length, only booleans and numbers
+// are kept
+func sanitizeParameters(parameters []any) []string {
+	sanitized := []string{}
+	for _, p := range parameters {
+		switch p := p.(type) {
+		case nil:
+			sanitized = append(sanitized, "nil")
+		case string:
+			sanitized = append(sanitized, fmt.Sprintf("string(%d)", len(p)))
+		case []byte:
+			sanitized = append(sanitized, fmt.Sprintf("bytes(%d)", len(p)))
+		case bool, int, int32, int64, uint, uint16, uint32, uint64:
+			sanitized = append(sanitized, fmt.Sprint(p))
+		default:
+			sanitized = append(sanitized, fmt.Sprintf("%T", p))
+		}
+	}
+	return sanitized
+}
+
+// pendingRequest is a D-Bus request that is completed after its handler
+// returned, e.g., a reconnect request, start is the start of its handling
+type pendingRequest struct {
+	request *dbusapi.Request
+	start   time.Time
+}
+
+// closeDBusRequest completes the pending D-Bus request r and records its
+// metrics
+func (d *Daemon) closeDBusRequest(r *pendingRequest) {
+	r.request.Close()
+	d.observeRequest(vpnstatus.APIDBus, r.request.Name, r.request.ID, r.start,
+		r.request.Error != nil, r.request.Parameters)
+}
+
+// observeRequest records the metrics of the request with id and name of api
+// that started at start and failed if failed is true, it logs the request
+// with its sanitized parameters if it exceeded the slow request duration
+func (d *Daemon) observeRequest(api, name, id string, start time.Time, failed bool,
+	parameters []any) {
+	duration := time.Since(start)
+	d.metrics.ObserveRequest(api, name, duration, failed)
+
+	slow := d.config.SlowRequestDuration
+	if slow <= 0 || duration <= slow {
+		return
+	}
+	log.WithFields(logrus.Fields{
+		logging.RequestField: id,
+		"api":                api,
+		"method":             name,
+		"duration":           duration,
+		"parameters":         sanitizeParameters(parameters),
+	}).Warn("Daemon handled slow request")
+}
+
+// metricsList returns the metrics of all API requests
+func (d *Daemon) metricsList() []dbusapi.RequestMetrics {
+	list := []dbusapi.RequestMetrics{}
+	for _, m := range d.metrics.Requests() {
+		list = append(list, dbusapi.RequestMetrics{
+			API:                 m.API,
+			Request:             m.Name,
+			Count:               m.Count,
+			Errors:              m.Errors,
+			LatencyMicroseconds: uint64(m.Latency.Microseconds()),
+			LatencyBuckets:      m.Buckets,
+		})
+	}
+	return list
+}
@@ -0,0 +1,75 @@
+package daemon
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/api"
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+	"github.com/telekom-mms/oc-daemon/internal/metrics"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// TestSocketRequestName tests socketRequestName
+func TestSocketRequestName(t *testing.T) {
+	for typ, want := range map[uint16]string{
+		api.TypeVPNConfigUpdate: "VPNConfigUpdate",
+		api.TypeUndefined:       "Type8",
+	} {
+		if got := socketRequestName(typ); got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+}
+
+// TestSanitizeParameters tests sanitizeParameters
+func TestSanitizeParameters(t *testing.T) {
+	params := []any{nil, "secret password", []byte("cookie"), true, 42,
+		uint32(7), []string{"server"}}
+	want := []string{"nil", "string(15)", "bytes(6)", "true", "42", "7",
+		"[]string"}
+	got := sanitizeParameters(params)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestDaemonObserveRequest tests observeRequest and metricsList of Daemon
+func TestDaemonObserveRequest(t *testing.T) {
+	d := &Daemon{
+		config:  NewConfig(),
+		metrics: metrics.NewRegistry(),
+	}
+
+	// fast and slow requests
+	now := time.Now()
+	d.observeRequest(vpnstatus.APIDBus, dbusapi.RequestConnect, "1", now,
+		false, []any{"server", "cookie"})
+	d.config.SlowRequestDuration = time.Nanosecond
+	d.observeRequest(vpnstatus.APIDBus, dbusapi.RequestConnect, "2",
+		now.Add(-2*time.Second), true, []any{"server", "cookie"})
+
+	got := d.metricsList()
+	if len(got) != 1 {
+		t.Fatalf("got %v, want one request type", got)
+	}
+	m := got[0]
+	if m.API != vpnstatus.APIDBus || m.Request != dbusapi.RequestConnect ||
+		m.Count != 2 || m.Errors != 1 ||
+		m.LatencyMicroseconds < uint64(2*time.Second/time.Microsecond) {
+		t.Errorf("got %v, want 2 connect requests with 1 error", m)
+	}
+	if len(m.LatencyBuckets) != len(metrics.LatencyBuckets)+1 ||
+		m.LatencyBuckets[len(metrics.LatencyBuckets)-1] != 1 {
+		t.Errorf("got %v, want request in 1s-10s bucket", m.LatencyBuckets)
+	}
+
+	// without metrics
+	d.metrics = nil
+	d.observeRequest(vpnstatus.APIDBus, dbusapi.RequestConnect, "3", now,
+		false, nil)
+	if got := d.metricsList(); len(got) != 0 {
+		t.Errorf("got %v, want no metrics", got)
+	}
+}
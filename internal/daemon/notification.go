@@ -0,0 +1,174 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/clock"
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+)
+
+// Notification severities
+const (
+	NotificationSeverityInfo     = "info"
+	NotificationSeverityWarning  = "warning"
+	NotificationSeverityCritical = "critical"
+)
+
+// notificationSeverityLevels are the levels of the notification severities
+var notificationSeverityLevels = map[string]int{
+	NotificationSeverityInfo:     0,
+	NotificationSeverityWarning:  1,
+	NotificationSeverityCritical: 2,
+}
+
+// notificationSignals are the severities of the D-Bus signals that desktop
+// clients show as notifications, other signals are always emitted
+var notificationSignals = map[string]string{
+	dbusapi.SignalIdleTimeout:       NotificationSeverityInfo,
+	dbusapi.SignalConnectionDropped: NotificationSeverityWarning,
+	dbusapi.SignalSessionExpiring:   NotificationSeverityWarning,
+	dbusapi.SignalReauthRequired:    NotificationSeverityCritical,
+}
+
+// QuietWindow is a time window of the quiet hours of notifications
+type QuietWindow struct {
+	// Days are the days of the week the window starts on, e.g., "Mon",
+	// every day if empty
+	Days []string
+
+	// Start and End are the local start and end times of the window,
+	// e.g., "22:00"; the window spans midnight if End is before Start
+	Start string
+	End   string
+}
+
+// window returns the quiet window as schedule window
+func (w *QuietWindow) window() *ScheduleWindow {
+	return &ScheduleWindow{
+		Days:   w.Days,
+		Start:  w.Start,
+		End:    w.End,
+		Action: ScheduleActionForbid,
+	}
+}
+
+// NotificationPolicy is the policy for notification signals of the daemon,
+// e.g., "ConnectionDropped", that desktop clients show as notifications;
+// critical signals like "ReauthRequired" are always emitted
+type NotificationPolicy struct {
+	// MinSeverity is the minimum severity of emitted notification
+	// signals, "info", "warning" or "critical"
+	MinSeverity string
+
+	// RateInterval and RateBurst limit the rate of each notification
+	// signal: RateBurst signals at once and one more signal every
+	// RateInterval, 0 disables the rate limit
+	RateInterval time.Duration
+	RateBurst    int
+
+	// QuietHours are the time windows without notification signals
+	QuietHours []QuietWindow
+}
+
+// Copy returns a copy of the notification policy
+func (p *NotificationPolicy) Copy() NotificationPolicy {
+	cp := *p
+	cp.QuietHours = nil
+	for _, w := range p.QuietHours {
+		w.Days = append(w.Days[:0:0], w.Days...)
+		cp.QuietHours = append(cp.QuietHours, w)
+	}
+	return cp
+}
+
+// Valid returns whether the notification policy is valid
+func (p *NotificationPolicy) Valid() bool {
+	if _, ok := notificationSeverityLevels[p.MinSeverity]; !ok {
+		return false
+	}
+	if p.RateInterval < 0 || p.RateBurst < 0 ||
+		(p.RateInterval > 0 && p.RateBurst == 0) {
+		return false
+	}
+	for _, w := range p.QuietHours {
+		if !w.window().Valid() {
+			return false
+		}
+	}
+	return true
+}
+
+// quiet returns whether the local time t is in the quiet hours
+func (p *NotificationPolicy) quiet(t time.Time) bool {
+	for _, w := range p.QuietHours {
+		if w.window().contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// notifier decides which notification signals are emitted according to the
+// notification policy
+type notifier struct {
+	policy  NotificationPolicy
+	limiter *rateLimiter
+	clock   clock.Clock
+}
+
+// setPolicy sets the notification policy and resets the rate limit
+func (n *notifier) setPolicy(policy *NotificationPolicy) {
+	n.policy = policy.Copy()
+	n.limiter = nil
+	if policy.RateInterval > 0 {
+		n.limiter = newRateLimiter(policy.RateInterval, policy.RateBurst)
+		n.limiter.clock = n.clock
+	}
+}
+
+// allow returns whether the signal with name is emitted
+func (n *notifier) allow(name string) bool {
+	if n == nil {
+		return true
+	}
+	severity, ok := notificationSignals[name]
+	if !ok || severity == NotificationSeverityCritical {
+		return true
+	}
+	if notificationSeverityLevels[severity] <
+		notificationSeverityLevels[n.policy.MinSeverity] {
+		return false
+	}
+	if n.policy.quiet(n.clock.Now().Local()) {
+		return false
+	}
+	_, ok = n.limiter.allow(name)
+	return ok
+}
+
+// newNotifier returns a new notifier with policy
+func newNotifier(policy *NotificationPolicy) *notifier {
+	n := &notifier{clock: clock.New()}
+	n.setPolicy(policy)
+	return n
+}
+
+// setNotificationPolicy sets the notification policy to the JSON policy
+// until the next config reload, an empty policy resets it to the config
+func (d *Daemon) setNotificationPolicy(policy string) error {
+	p := d.config.Notifications.Copy()
+	if policy != "" {
+		p = NewConfig().Notifications
+		if err := json.Unmarshal([]byte(policy), &p); err != nil {
+			return err
+		}
+		if !p.Valid() {
+			return errors.New("invalid notification policy")
+		}
+	}
+	log.WithField("policy", p).Info("Daemon setting notification policy")
+	d.notifier.setPolicy(&p)
+	return nil
+}
@@ -0,0 +1,170 @@
+package daemon
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/clock"
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+)
+
+// TestNotificationPolicyValid tests Valid of NotificationPolicy
+func TestNotificationPolicyValid(t *testing.T) {
+	// test invalid
+	for _, invalid := range []*NotificationPolicy{
+		{},
+		{MinSeverity: "invalid"},
+		{MinSeverity: NotificationSeverityInfo, RateInterval: -1},
+		{MinSeverity: NotificationSeverityInfo, RateBurst: -1},
+		{MinSeverity: NotificationSeverityInfo, RateInterval: time.Minute},
+		{MinSeverity: NotificationSeverityInfo, QuietHours: []QuietWindow{
+			{Start: "22:00", End: "22:00"},
+		}},
+		{MinSeverity: NotificationSeverityInfo, QuietHours: []QuietWindow{
+			{Days: []string{"Monday"}, Start: "22:00", End: "07:00"},
+		}},
+	} {
+		if invalid.Valid() {
+			t.Errorf("policy should be invalid: %v", invalid)
+		}
+	}
+
+	// test valid
+	for _, valid := range []*NotificationPolicy{
+		{MinSeverity: NotificationSeverityInfo},
+		{MinSeverity: NotificationSeverityCritical},
+		{MinSeverity: NotificationSeverityWarning, RateInterval: time.Minute, RateBurst: 2},
+		{MinSeverity: NotificationSeverityInfo, QuietHours: []QuietWindow{
+			{Days: []string{"Sat", "Sun"}, Start: "22:00", End: "07:00"},
+		}},
+	} {
+		if !valid.Valid() {
+			t.Errorf("policy should be valid: %v", valid)
+		}
+	}
+}
+
+// TestNotificationPolicyCopy tests Copy of NotificationPolicy
+func TestNotificationPolicyCopy(t *testing.T) {
+	p := &NotificationPolicy{
+		MinSeverity: NotificationSeverityWarning,
+		QuietHours: []QuietWindow{
+			{Days: []string{"Mon"}, Start: "22:00", End: "07:00"},
+		},
+	}
+	cp := p.Copy()
+	if !reflect.DeepEqual(&cp, p) {
+		t.Errorf("got %v, want %v", cp, p)
+	}
+	cp.QuietHours[0].Days[0] = "Tue"
+	if p.QuietHours[0].Days[0] != "Mon" {
+		t.Error("copy should not change original")
+	}
+}
+
+// TestNotifierAllow tests allow of notifier
+func TestNotifierAllow(t *testing.T) {
+	c := clock.NewFake(testScheduleTime(time.Monday, 12, 0))
+	n := &notifier{clock: c}
+
+	// minimum severity, critical and other signals are always allowed
+	n.setPolicy(&NotificationPolicy{MinSeverity: NotificationSeverityCritical})
+	for signal, want := range map[string]bool{
+		dbusapi.SignalIdleTimeout:       false,
+		dbusapi.SignalConnectionDropped: false,
+		dbusapi.SignalSessionExpiring:   false,
+		dbusapi.SignalReauthRequired:    true,
+		"OtherSignal":                   true,
+	} {
+		if got := n.allow(signal); got != want {
+			t.Errorf("%s: got %t, want %t", signal, got, want)
+		}
+	}
+
+	// quiet hours
+	n.setPolicy(&NotificationPolicy{
+		MinSeverity: NotificationSeverityInfo,
+		QuietHours:  []QuietWindow{{Start: "11:00", End: "13:00"}},
+	})
+	if n.allow(dbusapi.SignalConnectionDropped) {
+		t.Error("signal should not be allowed during quiet hours")
+	}
+	if !n.allow(dbusapi.SignalReauthRequired) {
+		t.Error("critical signal should be allowed during quiet hours")
+	}
+	c.Advance(time.Hour)
+	if !n.allow(dbusapi.SignalConnectionDropped) {
+		t.Error("signal should be allowed after quiet hours")
+	}
+
+	// rate limit per signal
+	n.setPolicy(&NotificationPolicy{
+		MinSeverity:  NotificationSeverityInfo,
+		RateInterval: 10 * time.Minute,
+		RateBurst:    2,
+	})
+	for i := 0; i < 2; i++ {
+		if !n.allow(dbusapi.SignalConnectionDropped) {
+			t.Fatalf("signal %d should be allowed", i)
+		}
+	}
+	if n.allow(dbusapi.SignalConnectionDropped) {
+		t.Error("signal should be rate limited")
+	}
+	if !n.allow(dbusapi.SignalIdleTimeout) {
+		t.Error("other signal should not be rate limited")
+	}
+	c.Advance(10 * time.Minute)
+	if !n.allow(dbusapi.SignalConnectionDropped) {
+		t.Error("signal should be allowed after rate interval")
+	}
+
+	// nil notifier allows everything
+	var nn *notifier
+	if !nn.allow(dbusapi.SignalConnectionDropped) {
+		t.Error("nil notifier should allow signals")
+	}
+}
+
+// TestDaemonSetNotificationPolicy tests setNotificationPolicy of Daemon
+func TestDaemonSetNotificationPolicy(t *testing.T) {
+	config := NewConfig()
+	dbus := &testDBusService{props: make(map[string]any)}
+	d := &Daemon{
+		config:   config,
+		dbus:     dbus,
+		notifier: newNotifier(&config.Notifications),
+	}
+
+	// invalid policies
+	for _, invalid := range []string{
+		"{",
+		`{"MinSeverity":"invalid"}`,
+	} {
+		if err := d.setNotificationPolicy(invalid); err == nil {
+			t.Errorf("policy %s should be invalid", invalid)
+		}
+	}
+
+	// suppress signals below critical
+	if err := d.setNotificationPolicy(`{"MinSeverity":"critical"}`); err != nil {
+		t.Fatal(err)
+	}
+	d.emitSignal(dbusapi.SignalConnectionDropped)
+	d.emitSignal(dbusapi.SignalReauthRequired)
+
+	// reset to config
+	if err := d.setNotificationPolicy(""); err != nil {
+		t.Fatal(err)
+	}
+	d.emitSignal(dbusapi.SignalConnectionDropped)
+
+	want := []string{
+		dbusapi.SignalReauthRequired,
+		dbusapi.SignalConnectionDropped,
+	}
+	if !reflect.DeepEqual(dbus.signals, want) {
+		t.Errorf("got %v, want %v", dbus.signals, want)
+	}
+}
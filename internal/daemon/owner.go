@@ -0,0 +1,105 @@
+package daemon
+
+import (
+	"fmt"
+	"os/user"
+	"strconv"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+)
+
+// polkitActionDisconnect is the polkit action that allows users to
+// disconnect VPN connections of other users if RestrictDisconnect is set
+const polkitActionDisconnect = "com.telekom_mms.oc_daemon.disconnect-other"
+
+// lookupUsername returns the name of the user with uid, it can be replaced
+// for testing
+var lookupUsername = func(uid int64) string {
+	u, err := user.LookupId(strconv.FormatInt(uid, 10))
+	if err != nil {
+		return ""
+	}
+	return u.Username
+}
+
+// checkPolkitAuthorization returns whether the D-Bus sender is authorized
+// for the polkit action without user interaction, it can be replaced for
+// testing
+var checkPolkitAuthorization = func(sender, action string) (bool, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	subject := struct {
+		Kind    string
+		Details map[string]dbus.Variant
+	}{
+		Kind: "system-bus-name",
+		Details: map[string]dbus.Variant{
+			"name": dbus.MakeVariant(sender),
+		},
+	}
+	result := struct {
+		IsAuthorized bool
+		IsChallenge  bool
+		Details      map[string]string
+	}{}
+	err = conn.Object("org.freedesktop.PolicyKit1",
+		"/org/freedesktop/PolicyKit1/Authority").
+		Call("org.freedesktop.PolicyKit1.Authority.CheckAuthorization", 0,
+			subject, action, map[string]string{}, uint32(0), "").
+		Store(&result)
+	return result.IsAuthorized, err
+}
+
+// setStatusOwner sets the user that initiated the current connection in
+// status
+func (d *Daemon) setStatusOwner(uid int64) {
+	if d.status.OwnerUID == uid {
+		// owner not changed
+		return
+	}
+
+	// owner changed
+	owner := dbusapi.OwnerInvalid
+	if uid != dbusapi.OwnerUIDInvalid {
+		owner = lookupUsername(uid)
+	}
+	d.status.OwnerUID = uid
+	d.status.Owner = owner
+	d.setProperty(dbusapi.PropertyOwnerUID, uid)
+	d.setProperty(dbusapi.PropertyOwner, owner)
+}
+
+// checkDisconnect checks if the sender of request is allowed to disconnect
+// the current connection: if RestrictDisconnect is set, only the owner of
+// the connection, root and users authorized by polkit are allowed
+func (d *Daemon) checkDisconnect(request *dbusapi.Request) error {
+	owner := d.status.OwnerUID
+	if !d.config.RestrictDisconnect ||
+		owner == dbusapi.OwnerUIDInvalid ||
+		request.UID == owner ||
+		request.UID == 0 {
+		return nil
+	}
+
+	authorized, err := checkPolkitAuthorization(request.Sender,
+		polkitActionDisconnect)
+	if err != nil {
+		log.WithError(err).Error("Daemon could not check polkit authorization")
+	}
+	if authorized {
+		return nil
+	}
+	log.WithFields(logrus.Fields{
+		"sender": request.Sender,
+		"uid":    request.UID,
+		"owner":  owner,
+	}).Warn("Daemon rejected disconnect of VPN connection of other user")
+	return fmt.Errorf("VPN connection of user %s can only be disconnected "+
+		"by the same user or root", d.status.Owner)
+}
@@ -0,0 +1,93 @@
+package daemon
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// TestDaemonSetStatusOwner tests setStatusOwner of Daemon
+func TestDaemonSetStatusOwner(t *testing.T) {
+	oldLookupUsername := lookupUsername
+	defer func() { lookupUsername = oldLookupUsername }()
+	lookupUsername = func(uid int64) string {
+		if uid == 1000 {
+			return "user"
+		}
+		return ""
+	}
+
+	dbus := &testDBusService{props: make(map[string]any)}
+	d := &Daemon{
+		dbus:   dbus,
+		status: vpnstatus.New(),
+	}
+
+	// set owner
+	d.setStatusOwner(1000)
+	if d.status.OwnerUID != 1000 || d.status.Owner != "user" {
+		t.Errorf("got %d %q, want 1000 user", d.status.OwnerUID,
+			d.status.Owner)
+	}
+	if dbus.props[dbusapi.PropertyOwnerUID] != int64(1000) ||
+		dbus.props[dbusapi.PropertyOwner] != "user" {
+		t.Errorf("got %v, want owner properties", dbus.props)
+	}
+
+	// reset owner
+	d.setStatusOwner(dbusapi.OwnerUIDInvalid)
+	if d.status.OwnerUID != dbusapi.OwnerUIDInvalid ||
+		d.status.Owner != dbusapi.OwnerInvalid {
+		t.Errorf("got %d %q, want invalid owner", d.status.OwnerUID,
+			d.status.Owner)
+	}
+}
+
+// TestDaemonCheckDisconnect tests checkDisconnect of Daemon
+func TestDaemonCheckDisconnect(t *testing.T) {
+	oldCheckPolkitAuthorization := checkPolkitAuthorization
+	defer func() { checkPolkitAuthorization = oldCheckPolkitAuthorization }()
+	authorized := map[string]bool{"admin": true}
+	checkPolkitAuthorization = func(sender, action string) (bool, error) {
+		if action != polkitActionDisconnect {
+			return false, errors.New("test error")
+		}
+		return authorized[sender], nil
+	}
+
+	d := &Daemon{
+		config: NewConfig(),
+		status: vpnstatus.New(),
+	}
+	d.status.OwnerUID = 1000
+	d.status.Owner = "user"
+
+	for _, test := range []struct {
+		restrict bool
+		sender   string
+		uid      int64
+		allowed  bool
+	}{
+		{false, "other", 1001, true},
+		{true, "user", 1000, true},
+		{true, "root", 0, true},
+		{true, "admin", 1002, true},
+		{true, "other", 1001, false},
+	} {
+		d.config.RestrictDisconnect = test.restrict
+		r := &dbusapi.Request{Sender: test.sender, UID: test.uid}
+		err := d.checkDisconnect(r)
+		if (err == nil) != test.allowed {
+			t.Errorf("%v: got %v, want allowed %t", test, err, test.allowed)
+		}
+	}
+
+	// no owner
+	d.status.OwnerUID = dbusapi.OwnerUIDInvalid
+	r := &dbusapi.Request{Sender: "other", UID: 1001}
+	if err := d.checkDisconnect(r); err != nil {
+		t.Errorf("got %v, want nil without owner", err)
+	}
+}
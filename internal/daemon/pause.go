@@ -0,0 +1,83 @@
+package daemon
+
+import (
+	"errors"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// setStatusPauseState sets the pause state in status
+func (d *Daemon) setStatusPauseState(state vpnstatus.PauseState) {
+	if d.status.PauseState == state {
+		// state not changed
+		return
+	}
+
+	// state changed
+	d.status.PauseState = state
+	d.setProperty(dbusapi.PropertyPauseState, state)
+}
+
+// pauseVPN pauses the VPN connection: openconnect keeps the connection but
+// split routing and DNS are removed, so all traffic bypasses the VPN; the
+// connection is resumed after timeout if timeout is not 0
+func (d *Daemon) pauseVPN(timeout time.Duration) error {
+	if !d.status.ConnectionState.Connected() {
+		return errors.New("VPN is not connected")
+	}
+	if d.trafpol != nil {
+		return errors.New("VPN cannot be paused while traffic policing is active")
+	}
+	if d.pauseToggle.disabled {
+		// already paused, only update timeout
+		d.pauseToggle.disable(timeout)
+		log.WithField("timeout", timeout).Info("Daemon updated VPN pause")
+		return nil
+	}
+
+	log.WithField("timeout", timeout).Info("Daemon pausing VPN")
+	d.pauseToggle.disable(timeout)
+	d.idle.stop()
+	d.resolvConf.stop()
+	d.teardownRouting()
+	d.teardownDNS()
+	d.setStatusPauseState(vpnstatus.PauseStatePaused)
+	return nil
+}
+
+// resumeVPN resumes the paused VPN connection and restores split routing
+// and DNS
+func (d *Daemon) resumeVPN() error {
+	if !d.pauseToggle.disabled {
+		return errors.New("VPN is not paused")
+	}
+
+	log.Info("Daemon resuming VPN")
+	d.pauseToggle.enable()
+	d.setupRouting(d.status.VPNConfig)
+	d.setupDNS(d.status.VPNConfig)
+	d.startResolvConfGuard()
+	d.startIdle()
+	d.setStatusPauseState(vpnstatus.PauseStateNotPaused)
+	return nil
+}
+
+// handlePauseTimeout handles the timeout of a paused VPN connection
+func (d *Daemon) handlePauseTimeout() {
+	log.Info("Daemon resuming VPN after timeout")
+	if err := d.resumeVPN(); err != nil {
+		log.WithError(err).Error("Daemon could not resume VPN")
+	}
+}
+
+// resetPause resets the pause state on disconnect and returns whether the
+// connection was paused, split routing and DNS of a paused connection are
+// already removed
+func (d *Daemon) resetPause() bool {
+	paused := d.pauseToggle.disabled
+	d.pauseToggle.enable()
+	d.setStatusPauseState(vpnstatus.PauseStateNotPaused)
+	return paused
+}
@@ -0,0 +1,111 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/dnsproxy"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// testDNSProxy is a DNS-Proxy for testing that records the remotes
+type testDNSProxy struct {
+	remotes map[string][]string
+}
+
+func (t *testDNSProxy) Start(context.Context) error            { return nil }
+func (t *testDNSProxy) Stop()                                  {}
+func (t *testDNSProxy) Reports() chan *dnsproxy.Report         { return nil }
+func (t *testDNSProxy) SetRemotes(remotes map[string][]string) { t.remotes = remotes }
+func (t *testDNSProxy) SetWatches([]string)                    {}
+func (t *testDNSProxy) SetFallback([]string)                   {}
+func (t *testDNSProxy) SetTunnelAll(bool)                      {}
+func (t *testDNSProxy) SetDevice(string)                       {}
+func (t *testDNSProxy) SetFallbackMark(int)                    {}
+func (t *testDNSProxy) Leaks() uint64                          { return 0 }
+func (t *testDNSProxy) Version() uint64                        { return 0 }
+
+// TestDaemonPauseVPN tests pauseVPN and resumeVPN of Daemon
+func TestDaemonPauseVPN(t *testing.T) {
+	oldRunResolvectl := runResolvectl
+	defer func() { runResolvectl = oldRunResolvectl }()
+	resolvectl := []string{}
+	runResolvectl = func(cmd string) {
+		resolvectl = append(resolvectl, cmd)
+	}
+
+	dns := &testDNSProxy{remotes: map[string][]string{".": {"10.0.0.1:53"}}}
+	d := &Daemon{
+		config:      NewConfig(),
+		dbus:        noDBusService{},
+		dns:         dns,
+		status:      vpnstatus.New(),
+		pauseToggle: newToggle(),
+		idle:        newIdleMonitor(),
+		resolvConf:  newResolvConfGuard("/does/not/exist"),
+	}
+	defer d.pauseToggle.stopTimer()
+
+	// not connected
+	if err := d.pauseVPN(0); err == nil {
+		t.Error("pause should fail when not connected")
+	}
+
+	// not paused
+	if err := d.resumeVPN(); err == nil {
+		t.Error("resume should fail when not paused")
+	}
+
+	// pause
+	d.status.ConnectionState = vpnstatus.ConnectionStateConnected
+	d.status.VPNConfig = vpnconfig.New()
+	d.status.VPNConfig.Device.Name = "tun0"
+	if err := d.pauseVPN(time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if !d.status.PauseState.Paused() || d.pauseToggle.timerC() == nil {
+		t.Error("should be paused with timeout")
+	}
+	if len(dns.remotes) != 0 || len(resolvectl) == 0 {
+		t.Errorf("dns should be removed, got %v %v", dns.remotes, resolvectl)
+	}
+
+	// pause again without timeout
+	resolvectl = []string{}
+	if err := d.pauseVPN(0); err != nil {
+		t.Fatal(err)
+	}
+	if !d.status.PauseState.Paused() || d.pauseToggle.timerC() != nil {
+		t.Error("should be paused without timeout")
+	}
+	if len(resolvectl) != 0 {
+		t.Errorf("dns should not be changed again, got %v", resolvectl)
+	}
+
+	// reset on disconnect
+	if !d.resetPause() {
+		t.Error("reset should return paused")
+	}
+	if d.status.PauseState != vpnstatus.PauseStateNotPaused {
+		t.Errorf("got %s, want not paused", d.status.PauseState)
+	}
+	if d.resetPause() {
+		t.Error("reset should not return paused")
+	}
+}
+
+// TestDaemonPauseVPNTrafPol tests pauseVPN of Daemon with active traffic
+// policing
+func TestDaemonPauseVPNTrafPol(t *testing.T) {
+	d := &Daemon{
+		status:      vpnstatus.New(),
+		pauseToggle: newToggle(),
+		trafpol:     &testTrafPol{},
+	}
+	d.status.ConnectionState = vpnstatus.ConnectionStateConnected
+	if err := d.pauseVPN(0); err == nil {
+		t.Error("pause should fail with active traffic policing")
+	}
+}
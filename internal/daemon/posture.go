@@ -0,0 +1,61 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/posture"
+)
+
+// postureTimeout is the timeout of all posture checks before a VPN connect
+const postureTimeout = 10 * time.Second
+
+// runPostureChecks runs the posture checks in config, it can be replaced for
+// testing
+var runPostureChecks = func(ctx context.Context, config *Posture) []*posture.Result {
+	checker := &posture.Checker{
+		Checks:           config.Checks,
+		MinKernelVersion: config.MinKernelVersion,
+		Script:           config.Script,
+	}
+	return checker.Run(ctx)
+}
+
+// checkPosture runs the posture checks before a VPN connect, it returns the
+// results as environment variables for openconnect, e.g., for CSD hostscan
+// reports to the gateway, and an error if a check failed and checks are
+// enforced
+func (d *Daemon) checkPosture() ([]string, error) {
+	config := &d.config.Posture
+	if !config.Enabled() {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(d.ctx, postureTimeout)
+	defer cancel()
+
+	env := []string{}
+	failed := []string{}
+	for _, r := range runPostureChecks(ctx, config) {
+		state := "passed"
+		if !r.Passed {
+			state = "failed"
+			failed = append(failed, r.Check)
+		}
+		log.WithFields(logrus.Fields{
+			"check":   r.Check,
+			"passed":  r.Passed,
+			"message": r.Message,
+		}).Info("Daemon ran posture check")
+		env = append(env, "oc_daemon_posture_"+r.Check+"="+state)
+	}
+
+	if len(failed) > 0 && config.Enforce {
+		return nil, fmt.Errorf("posture checks failed: %s",
+			strings.Join(failed, ", "))
+	}
+	return env, nil
+}
@@ -0,0 +1,59 @@
+package daemon
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/internal/posture"
+)
+
+// TestDaemonCheckPosture tests checkPosture of Daemon
+func TestDaemonCheckPosture(t *testing.T) {
+	oldRunPostureChecks := runPostureChecks
+	defer func() { runPostureChecks = oldRunPostureChecks }()
+
+	results := []*posture.Result{
+		{Check: posture.CheckLUKS, Passed: true},
+		{Check: posture.CheckKernel, Passed: false},
+	}
+	runPostureChecks = func(context.Context, *Posture) []*posture.Result {
+		return results
+	}
+	d := &Daemon{
+		config: NewConfig(),
+		ctx:    context.Background(),
+	}
+
+	// disabled
+	env, err := d.checkPosture()
+	if err != nil || env != nil {
+		t.Errorf("got %v, %v, want nil, nil", env, err)
+	}
+
+	// failed check not enforced
+	d.config.Posture.Checks = []string{"luks", "kernel"}
+	env, err = d.checkPosture()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"oc_daemon_posture_luks=passed",
+		"oc_daemon_posture_kernel=failed",
+	}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("got %v, want %v", env, want)
+	}
+
+	// failed check enforced
+	d.config.Posture.Enforce = true
+	if _, err := d.checkPosture(); err == nil {
+		t.Error("posture check should fail")
+	}
+
+	// passed checks enforced
+	results[1].Passed = true
+	if _, err := d.checkPosture(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,63 @@
+package daemon
+
+import (
+	"fmt"
+	"os/user"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/ocrunner"
+)
+
+// setupPrivilegeSeparation runs openconnect of the VPN connection and the
+// additional tunnels as the configured system user if privilege separation
+// is enabled and allows the vpnc-script calls of this user on the unix
+// socket, all other system changes stay in the daemon
+func (d *Daemon) setupPrivilegeSeparation() error {
+	p := d.config.PrivilegeSeparation
+	if !p.Enabled() {
+		return nil
+	}
+	credentials, err := ocrunner.NewCredentials(p.User, p.Group, p.Capabilities)
+	if err != nil {
+		return err
+	}
+	d.runner.SetCredentials(credentials)
+	d.secret.setOwner(int(credentials.UID))
+	for _, t := range d.tunnels {
+		t.runner.SetCredentials(credentials)
+		t.secret.setOwner(int(credentials.UID))
+	}
+	d.server.SetOwner(int(credentials.UID))
+	if d.grpc != nil {
+		d.grpc.SetOwner(int(credentials.UID))
+	}
+	log.WithFields(logrus.Fields{
+		"uid":          credentials.UID,
+		"gid":          credentials.GID,
+		"capabilities": credentials.Capabilities,
+	}).Info("Daemon running openconnect without root privileges")
+	return nil
+}
+
+// setupSocketGroup sets the configured group of the unix socket, so its
+// members can send VPN config updates, e.g., a custom vpnc-script
+func (d *Daemon) setupSocketGroup() error {
+	if d.config.SocketGroup == "" {
+		return nil
+	}
+	group, err := user.LookupGroup(d.config.SocketGroup)
+	if err != nil {
+		return err
+	}
+	gid, err := strconv.Atoi(group.Gid)
+	if err != nil {
+		return fmt.Errorf("invalid gid %s: %w", group.Gid, err)
+	}
+	d.server.SetGroup(gid)
+	if d.grpc != nil {
+		d.grpc.SetGroup(gid)
+	}
+	log.WithField("gid", gid).Info("Daemon allows VPN config updates of socket group")
+	return nil
+}
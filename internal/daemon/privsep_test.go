@@ -0,0 +1,77 @@
+package daemon
+
+import (
+	"os/user"
+	"strconv"
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/internal/api"
+	"github.com/telekom-mms/oc-daemon/internal/ocrunner"
+)
+
+// TestDaemonSetupPrivilegeSeparation tests setupPrivilegeSeparation of Daemon
+func TestDaemonSetupPrivilegeSeparation(t *testing.T) {
+	d := &Daemon{
+		config: NewConfig(),
+		server: api.NewServer("test.sock"),
+		runner: ocrunner.NewConnect("", "", ""),
+		secret: newConnSecret("test.secret"),
+	}
+
+	// disabled
+	if err := d.setupPrivilegeSeparation(); err != nil {
+		t.Error(err)
+	}
+
+	// unknown user
+	d.config.PrivilegeSeparation.User = "oc-daemon-does-not-exist"
+	if err := d.setupPrivilegeSeparation(); err == nil {
+		t.Error("unknown user should fail")
+	}
+
+	// current user
+	u, err := user.Current()
+	if err != nil {
+		t.Skip(err)
+	}
+	d.config.PrivilegeSeparation.User = u.Username
+	if err := d.setupPrivilegeSeparation(); err != nil {
+		t.Error(err)
+	}
+	if strconv.Itoa(d.secret.owner) != u.Uid {
+		t.Errorf("got secret owner %d, want %s", d.secret.owner, u.Uid)
+	}
+}
+
+// TestDaemonSetupSocketGroup tests setupSocketGroup of Daemon
+func TestDaemonSetupSocketGroup(t *testing.T) {
+	d := &Daemon{
+		config: NewConfig(),
+		server: api.NewServer("test.sock"),
+	}
+
+	// disabled
+	if err := d.setupSocketGroup(); err != nil {
+		t.Error(err)
+	}
+
+	// unknown group
+	d.config.SocketGroup = "oc-daemon-does-not-exist"
+	if err := d.setupSocketGroup(); err == nil {
+		t.Error("unknown group should fail")
+	}
+
+	// current group
+	u, err := user.Current()
+	if err != nil {
+		t.Skip(err)
+	}
+	g, err := user.LookupGroupId(u.Gid)
+	if err != nil {
+		t.Skip(err)
+	}
+	d.config.SocketGroup = g.Name
+	if err := d.setupSocketGroup(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,64 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/telekom-mms/oc-daemon/internal/profilemon"
+	"github.com/telekom-mms/oc-daemon/pkg/xmlprofile"
+)
+
+var (
+	// profilesDir is the directory of additional named xml profiles
+	profilesDir = configDir + "/profiles"
+)
+
+// profilePath returns the file path of the xml profile with name, the
+// default xml profile if name is empty
+func profilePath(name string) string {
+	return xmlprofile.ProfilePath(profilesDir, xmlProfile, name)
+}
+
+// selectProfile selects the xml profile with name, the default xml profile
+// if name is empty, and applies its settings
+func (d *Daemon) selectProfile(name string) error {
+	if name == d.profileName {
+		return nil
+	}
+
+	// check named profile
+	file := profilePath(name)
+	if name != "" {
+		if !xmlprofile.ValidProfileName(name) {
+			return fmt.Errorf("invalid profile name: %s", name)
+		}
+		if _, err := os.Stat(file); err != nil {
+			return fmt.Errorf("profile %s not found", name)
+		}
+	}
+
+	// monitor new profile, keep the old profile if this fails, and apply
+	// its settings
+	log.WithField("profile", name).Info("Daemon selecting XML profile")
+	profmon := profilemon.NewProfileMon(file)
+	if err := profmon.Start(d.ctx); err != nil {
+		return fmt.Errorf("could not monitor profile %s: %w", name, err)
+	}
+	d.profmon.Stop()
+	d.profmon = profmon
+	d.profileName = name
+	d.handleProfileUpdate()
+	return nil
+}
+
+// reloadProfile reads the selected xml profile again and applies its
+// settings, the current profile is kept if the file cannot be parsed
+func (d *Daemon) reloadProfile() error {
+	log.WithField("profile", d.profileName).Info("Daemon reloading XML profile")
+	profile, err := xmlprofile.LoadProfile(profilePath(d.profileName))
+	if err != nil {
+		return fmt.Errorf("could not read profile %s: %w", d.profileName, err)
+	}
+	d.applyProfile(profile)
+	return nil
+}
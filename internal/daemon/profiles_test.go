@@ -0,0 +1,73 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/pkg/xmlprofile"
+)
+
+// TestProfilePath tests profilePath
+func TestProfilePath(t *testing.T) {
+	want := xmlProfile
+	got := profilePath("")
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	want = filepath.Join(profilesDir, "test.xml")
+	got = profilePath("test")
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+// TestDaemonSelectProfile tests selectProfile of Daemon
+func TestDaemonSelectProfile(t *testing.T) {
+	oldDir := profilesDir
+	profilesDir = t.TempDir()
+	defer func() { profilesDir = oldDir }()
+
+	d := &Daemon{}
+
+	// test already selected profile
+	if err := d.selectProfile(""); err != nil {
+		t.Error(err)
+	}
+
+	// test invalid and not existing profiles
+	for _, name := range []string{"../test", "test"} {
+		if err := d.selectProfile(name); err == nil {
+			t.Errorf("profile %s should return error", name)
+		}
+		if d.profileName != "" {
+			t.Errorf("profile should not be selected")
+		}
+	}
+}
+
+// TestDaemonReloadProfile tests reloadProfile of Daemon
+func TestDaemonReloadProfile(t *testing.T) {
+	oldDir := profilesDir
+	profilesDir = t.TempDir()
+	defer func() { profilesDir = oldDir }()
+
+	profile := xmlprofile.NewProfile()
+	d := &Daemon{profile: profile, profileName: "test"}
+
+	// test not existing and invalid profiles, current profile is kept
+	if err := d.reloadProfile(); err == nil {
+		t.Error("not existing profile should return error")
+	}
+	file := filepath.Join(profilesDir, "test.xml")
+	if err := os.WriteFile(file, []byte("<invalid"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.reloadProfile(); err == nil {
+		t.Error("invalid profile should return error")
+	}
+	if d.profile != profile {
+		t.Error("profile should not be changed")
+	}
+}
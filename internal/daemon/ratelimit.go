@@ -0,0 +1,91 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/clock"
+)
+
+const (
+	// connectRateInterval and connectRateBurst limit the rate of connect
+	// requests per sender: one request every interval after a burst
+	connectRateInterval = 10 * time.Second
+	connectRateBurst    = 5
+
+	// configUpdateRateInterval and configUpdateRateBurst limit the rate
+	// of config update requests per connection
+	configUpdateRateInterval = time.Second
+	configUpdateRateBurst    = 10
+
+	// maxRateBuckets is the number of senders after which idle senders
+	// are removed from a rate limiter
+	maxRateBuckets = 1024
+)
+
+// rateBucket is the token bucket of a sender
+type rateBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter limits the rate of requests per sender with token buckets,
+// every sender can send burst requests at once and one more request every
+// interval
+type rateLimiter struct {
+	interval time.Duration
+	burst    int
+	clock    clock.Clock
+	buckets  map[string]*rateBucket
+}
+
+// refill adds the tokens of the time elapsed since the last update of b
+func (r *rateLimiter) refill(b *rateBucket, now time.Time) {
+	b.tokens += float64(now.Sub(b.last)) / float64(r.interval)
+	if b.tokens > float64(r.burst) {
+		b.tokens = float64(r.burst)
+	}
+	b.last = now
+}
+
+// prune removes the buckets of idle senders
+func (r *rateLimiter) prune(now time.Time) {
+	for sender, b := range r.buckets {
+		r.refill(b, now)
+		if b.tokens >= float64(r.burst) {
+			delete(r.buckets, sender)
+		}
+	}
+}
+
+// allow returns whether a request of sender is allowed; if not, it also
+// returns the time after which the sender can retry
+func (r *rateLimiter) allow(sender string) (time.Duration, bool) {
+	if r == nil {
+		return 0, true
+	}
+	now := r.clock.Now()
+	b := r.buckets[sender]
+	if b == nil {
+		if len(r.buckets) >= maxRateBuckets {
+			r.prune(now)
+		}
+		b = &rateBucket{tokens: float64(r.burst), last: now}
+		r.buckets[sender] = b
+	}
+	r.refill(b, now)
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+	return time.Duration((1 - b.tokens) * float64(r.interval)), false
+}
+
+// newRateLimiter returns a new rate limiter with interval and burst
+func newRateLimiter(interval time.Duration, burst int) *rateLimiter {
+	return &rateLimiter{
+		interval: interval,
+		burst:    burst,
+		clock:    clock.New(),
+		buckets:  make(map[string]*rateBucket),
+	}
+}
@@ -0,0 +1,84 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/clock"
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+)
+
+// TestRateLimiterAllow tests allow of rateLimiter
+func TestRateLimiterAllow(t *testing.T) {
+	c := clock.NewFake(time.Now())
+	r := newRateLimiter(10*time.Second, 2)
+	r.clock = c
+
+	// burst
+	for i := 0; i < 2; i++ {
+		if _, ok := r.allow("test"); !ok {
+			t.Fatalf("request %d should be allowed", i)
+		}
+	}
+
+	// rejected, other senders not affected
+	retryAfter, ok := r.allow("test")
+	if ok || retryAfter != 10*time.Second {
+		t.Errorf("got %v, %t, want 10s, false", retryAfter, ok)
+	}
+	if _, ok := r.allow("other"); !ok {
+		t.Error("request of other sender should be allowed")
+	}
+
+	// partially refilled
+	c.Advance(4 * time.Second)
+	retryAfter, ok = r.allow("test")
+	if ok || retryAfter != 6*time.Second {
+		t.Errorf("got %v, %t, want 6s, false", retryAfter, ok)
+	}
+
+	// refilled
+	c.Advance(6 * time.Second)
+	if _, ok := r.allow("test"); !ok {
+		t.Error("request should be allowed after refill")
+	}
+
+	// nil rate limiter allows everything
+	var n *rateLimiter
+	if _, ok := n.allow("test"); !ok {
+		t.Error("nil rate limiter should allow requests")
+	}
+}
+
+// TestRateLimiterPrune tests pruning of idle senders in rateLimiter
+func TestRateLimiterPrune(t *testing.T) {
+	c := clock.NewFake(time.Now())
+	r := newRateLimiter(time.Second, 1)
+	r.clock = c
+
+	for i := 0; i < maxRateBuckets; i++ {
+		r.allow(fmt.Sprintf("sender %d", i))
+	}
+	c.Advance(time.Second)
+	r.allow("new")
+	if len(r.buckets) != 1 {
+		t.Errorf("got %d buckets, want 1", len(r.buckets))
+	}
+}
+
+// TestCheckRateLimit tests checkRateLimit
+func TestCheckRateLimit(t *testing.T) {
+	r := newRateLimiter(time.Minute, 1)
+	r.clock = clock.NewFake(time.Now())
+
+	if err := checkRateLimit(r, "test"); err != nil {
+		t.Fatal(err)
+	}
+	err := checkRateLimit(r, "test")
+	tooMany := &dbusapi.TooManyRequestsError{}
+	if !errors.As(err, &tooMany) || tooMany.RetryAfter != time.Minute {
+		t.Errorf("got %v, want too many requests error", err)
+	}
+}
@@ -0,0 +1,179 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/audit"
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+	"github.com/telekom-mms/oc-daemon/internal/logging"
+	"github.com/telekom-mms/oc-daemon/pkg/logininfo"
+)
+
+// reconnect handles automatic reconnects after unexpected disconnects
+type reconnect struct {
+	*backoff
+	policy *ReconnectPolicy
+
+	// login is the login info of the last connection attempt and
+	// loginUID the user who provided it
+	login    *logininfo.LoginInfo
+	loginUID int64
+}
+
+// setLogin sets the login info used for reconnects
+func (r *reconnect) setLogin(login *logininfo.LoginInfo) {
+	r.login = login.Copy()
+}
+
+// setLoginUID sets the user who provided the login info
+func (r *reconnect) setLoginUID(uid int64) {
+	r.loginUID = uid
+}
+
+// getLogin returns the login info used for reconnects
+func (r *reconnect) getLogin() *logininfo.LoginInfo {
+	return r.login.Copy()
+}
+
+// schedule schedules the next reconnect attempt and returns its delay,
+// returns false if reconnects are disabled or the maximum number of attempts
+// is reached
+func (r *reconnect) schedule() (time.Duration, bool) {
+	if !r.policy.Enabled || !r.login.Valid() {
+		return 0, false
+	}
+	return r.backoff.schedule()
+}
+
+// setPolicy sets the reconnect policy, it is used for the next reconnect
+// attempt
+func (r *reconnect) setPolicy(policy *ReconnectPolicy) {
+	r.policy = policy
+	r.initialDelay = policy.InitialDelay
+	r.maxDelay = policy.MaxDelay
+	r.maxAttempts = policy.MaxAttempts
+	r.jitter = policy.Jitter
+}
+
+// newReconnect returns a new reconnect with policy
+func newReconnect(policy *ReconnectPolicy) *reconnect {
+	return &reconnect{
+		backoff: newBackoff(policy.InitialDelay, policy.MaxDelay,
+			policy.MaxAttempts, policy.Jitter),
+		policy:   policy,
+		loginUID: dbusapi.UIDUnknown,
+	}
+}
+
+// connectCached connects the VPN with the login info of the last connection
+// attempt without a new authentication, only the user who provided the login
+// info and root are allowed to use it
+func (d *Daemon) connectCached(request *dbusapi.Request) error {
+	login := d.reconnect.getLogin()
+	if !login.Valid() {
+		return errors.New("no cached login information")
+	}
+	if request.UID == dbusapi.UIDUnknown ||
+		(request.UID != 0 && request.UID != d.reconnect.loginUID) {
+		return errors.New("cached login information of other user")
+	}
+
+	d.logAudit(audit.EventConnect, request.Sender, request.UID,
+		login.Host+" (cached)")
+	d.reconnect.reset()
+	if err := d.connectVPN(login); err != nil {
+		return err
+	}
+	d.setStatusOwner(d.reconnect.loginUID)
+	return nil
+}
+
+var (
+	// errReconnectAborted is the error of reconnect requests aborted by
+	// a disconnect
+	errReconnectAborted = errors.New("reconnect aborted")
+
+	// errReconnectNotNeeded is the error of reconnect requests if the
+	// vpn is not needed or already running again
+	errReconnectNotNeeded = errors.New("reconnect not needed")
+)
+
+// handleReconnectRequest handles the D-Bus reconnect request, it disconnects
+// the VPN and connects again with the login info of the last connection; the
+// request is completed when the VPN is connected or the reconnect failed
+func (d *Daemon) handleReconnectRequest(pending *pendingRequest) {
+	request := pending.request
+	if err := d.startReconnect(request); err != nil {
+		log.WithField(logging.CodeField, logging.CodeReconnectFailed).
+			WithField(logging.RequestField, request.ID).
+			WithError(err).Error("Daemon could not reconnect VPN")
+		request.Error = err
+		d.closeDBusRequest(pending)
+		return
+	}
+	d.reconnectRequests = append(d.reconnectRequests, pending)
+}
+
+// startReconnect starts the reconnect requested with request, the VPN is
+// connected again after the teardown of the current connection in
+// handleRunnerEvent; only the user who provided the login info and root can
+// reconnect without a new authentication
+func (d *Daemon) startReconnect(request *dbusapi.Request) error {
+	if err := d.checkDisconnect(request); err != nil {
+		return err
+	}
+	login := d.reconnect.getLogin()
+	if !login.Valid() {
+		d.emitSignal(dbusapi.SignalReauthRequired)
+		return fmt.Errorf("%w: no cached login information",
+			dbusapi.ErrReauthRequired)
+	}
+	if request.UID == dbusapi.UIDUnknown ||
+		(request.UID != 0 && request.UID != d.reconnect.loginUID) {
+		return fmt.Errorf("%w: cached login information of other user",
+			dbusapi.ErrReauthRequired)
+	}
+	if d.status.TrustedNetwork.Trusted() {
+		return errReconnectNotNeeded
+	}
+
+	d.logAudit(audit.EventConnect, request.Sender, request.UID,
+		login.Host+" (reconnect)")
+
+	// vpn running, disconnect and reconnect after teardown
+	if d.status.OCRunning.Running() {
+		if err := d.disconnectVPN(); err != nil {
+			return err
+		}
+		d.reconnectAfterDisconnect = true
+		return nil
+	}
+
+	// vpn not running, connect right away
+	d.reconnect.reset()
+	if err := d.connectVPN(login); err != nil {
+		return err
+	}
+	d.setStatusOwner(d.reconnect.loginUID)
+	return nil
+}
+
+// finishReconnect completes the pending reconnect requests with err
+func (d *Daemon) finishReconnect(err error) {
+	for _, pending := range d.reconnectRequests {
+		pending.request.Error = err
+		d.closeDBusRequest(pending)
+	}
+	d.reconnectRequests = nil
+}
+
+// requireReauth signals D-Bus clients that the user has to authenticate
+// again and fails the pending reconnect requests
+func (d *Daemon) requireReauth() {
+	log.WithField(logging.CodeField, logging.CodeReauthRequired).
+		Info("Daemon requires new authentication to reconnect VPN")
+	d.emitSignal(dbusapi.SignalReauthRequired)
+	d.finishReconnect(dbusapi.ErrReauthRequired)
+}
@@ -0,0 +1,291 @@
+package daemon
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/clock"
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+	"github.com/telekom-mms/oc-daemon/internal/ocrunner"
+	"github.com/telekom-mms/oc-daemon/pkg/logininfo"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// testLogin returns valid login info for testing
+func testLogin() *logininfo.LoginInfo {
+	return &logininfo.LoginInfo{
+		Cookie:      "cookie",
+		Host:        "host",
+		Fingerprint: "fingerprint",
+	}
+}
+
+// TestReconnectDelay tests delay of reconnect
+func TestReconnectDelay(t *testing.T) {
+	r := newReconnect(&ReconnectPolicy{
+		InitialDelay: time.Second,
+		MaxDelay:     5 * time.Second,
+	})
+	for i, want := range []time.Duration{
+		time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		5 * time.Second,
+		5 * time.Second,
+	} {
+		r.attempts = i
+		got := r.delay()
+		if got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+}
+
+// TestReconnectSchedule tests schedule of reconnect
+func TestReconnectSchedule(t *testing.T) {
+	policy := &ReconnectPolicy{
+		MaxAttempts:  2,
+		InitialDelay: time.Hour,
+		MaxDelay:     time.Hour,
+	}
+	r := newReconnect(policy)
+	defer r.stop()
+
+	// test disabled
+	r.setLogin(testLogin())
+	if _, ok := r.schedule(); ok {
+		t.Errorf("disabled reconnect should not be scheduled")
+	}
+
+	// test invalid login
+	policy.Enabled = true
+	r.setLogin(nil)
+	if _, ok := r.schedule(); ok {
+		t.Errorf("reconnect without login should not be scheduled")
+	}
+
+	// test max attempts
+	r.setLogin(testLogin())
+	for i := 0; i < policy.MaxAttempts; i++ {
+		if _, ok := r.schedule(); !ok {
+			t.Errorf("reconnect should be scheduled")
+		}
+		if !r.pending() || r.timerC() == nil {
+			t.Errorf("reconnect should be pending")
+		}
+	}
+	if _, ok := r.schedule(); ok {
+		t.Errorf("reconnect should not be scheduled after max attempts")
+	}
+
+	// test reset
+	r.reset()
+	if r.pending() || r.timerC() != nil || r.attempts != 0 {
+		t.Errorf("reconnect should be reset")
+	}
+	if _, ok := r.schedule(); !ok {
+		t.Errorf("reconnect should be scheduled after reset")
+	}
+}
+
+// TestReconnectTimer tests the timer of reconnect
+func TestReconnectTimer(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	r := newReconnect(&ReconnectPolicy{
+		Enabled:      true,
+		InitialDelay: time.Second,
+		MaxDelay:     time.Minute,
+	})
+	r.clock = fake
+	r.setLogin(testLogin())
+
+	// first attempt after initial delay
+	if _, ok := r.schedule(); !ok {
+		t.Fatal("reconnect should be scheduled")
+	}
+	fake.Advance(time.Second)
+	<-r.timerC()
+	r.expired()
+
+	// second attempt after doubled delay
+	if _, ok := r.schedule(); !ok {
+		t.Fatal("reconnect should be scheduled")
+	}
+	fake.Advance(time.Second)
+	select {
+	case <-r.timerC():
+		t.Errorf("reconnect timer should not expire")
+	default:
+	}
+	fake.Advance(time.Second)
+	<-r.timerC()
+}
+
+// TestReconnectSetPolicy tests setPolicy of reconnect
+func TestReconnectSetPolicy(t *testing.T) {
+	r := newReconnect(&ReconnectPolicy{
+		InitialDelay: time.Second,
+		MaxDelay:     time.Second,
+	})
+	policy := &ReconnectPolicy{
+		Enabled:      true,
+		MaxAttempts:  3,
+		InitialDelay: 2 * time.Second,
+		MaxDelay:     time.Minute,
+		Jitter:       0.5,
+	}
+	r.setPolicy(policy)
+	if r.policy != policy ||
+		r.initialDelay != policy.InitialDelay ||
+		r.maxDelay != policy.MaxDelay ||
+		r.maxAttempts != policy.MaxAttempts ||
+		r.jitter != policy.Jitter {
+		t.Errorf("policy not set: %v", r)
+	}
+}
+
+// TestDaemonCheckReconnectTransientOnly tests checkReconnect of Daemon with
+// reconnects only after transient disconnects
+func TestDaemonCheckReconnectTransientOnly(t *testing.T) {
+	config := NewConfig()
+	config.ReconnectPolicy.Enabled = true
+	config.ReconnectPolicy.TransientOnly = true
+	d := &Daemon{
+		config:    config,
+		dbus:      noDBusService{},
+		status:    vpnstatus.New(),
+		reconnect: newReconnect(&config.ReconnectPolicy),
+	}
+	d.reconnect.setLogin(testLogin())
+	defer d.reconnect.stop()
+
+	// not transient
+	for _, reason := range []ocrunner.DisconnectReason{
+		ocrunner.ReasonAdminReset,
+		ocrunner.ReasonMaxSession,
+	} {
+		d.checkReconnect(reason)
+		if d.reconnect.pending() {
+			t.Errorf("reconnect should not be scheduled after %s", reason)
+		}
+	}
+
+	// transient
+	d.checkReconnect(ocrunner.ReasonIdleTimeout)
+	if !d.reconnect.pending() {
+		t.Error("reconnect should be scheduled")
+	}
+}
+
+// TestDaemonSetStatusDisconnectReason tests setStatusDisconnectReason of
+// Daemon
+func TestDaemonSetStatusDisconnectReason(t *testing.T) {
+	d := &Daemon{
+		dbus:   noDBusService{},
+		status: vpnstatus.New(),
+	}
+	want := string(ocrunner.ReasonIdleTimeout)
+	d.setStatusDisconnectReason(want)
+	if d.status.DisconnectReason != want {
+		t.Errorf("got %s, want %s", d.status.DisconnectReason, want)
+	}
+}
+
+// TestDaemonConnectCached tests connectCached of Daemon
+func TestDaemonConnectCached(t *testing.T) {
+	config := NewConfig()
+	d := &Daemon{
+		config:    config,
+		dbus:      noDBusService{},
+		status:    vpnstatus.New(),
+		reconnect: newReconnect(&config.ReconnectPolicy),
+	}
+	defer d.reconnect.stop()
+	request := &dbusapi.Request{UID: 1000}
+
+	// no login info
+	if err := d.connectCached(request); err == nil {
+		t.Error("connect without login info should fail")
+	}
+
+	// login info of other or unknown user
+	d.reconnect.setLogin(testLogin())
+	d.reconnect.setLoginUID(1001)
+	for _, uid := range []int64{1000, dbusapi.UIDUnknown} {
+		request.UID = uid
+		if err := d.connectCached(request); err == nil {
+			t.Errorf("connect of uid %d should fail", uid)
+		}
+	}
+
+	// login info of same user and root, connect fails because vpn is
+	// already running
+	d.status.OCRunning = vpnstatus.OCRunningRunning
+	for _, uid := range []int64{1001, 0} {
+		request.UID = uid
+		err := d.connectCached(request)
+		if err == nil || err.Error() != "vpn already running" {
+			t.Errorf("connect of uid %d: got %v, want vpn already running",
+				uid, err)
+		}
+	}
+}
+
+// TestDaemonStartReconnect tests startReconnect of Daemon
+func TestDaemonStartReconnect(t *testing.T) {
+	config := NewConfig()
+	dbus := &testDBusService{props: make(map[string]any)}
+	d := &Daemon{
+		config:    config,
+		dbus:      dbus,
+		status:    vpnstatus.New(),
+		state:     newStateMachine(nil),
+		reconnect: newReconnect(&config.ReconnectPolicy),
+	}
+	defer d.reconnect.stop()
+	request := &dbusapi.Request{UID: 1000}
+
+	// no login info, reauthentication required
+	err := d.startReconnect(request)
+	if !errors.Is(err, dbusapi.ErrReauthRequired) {
+		t.Errorf("got %v, want %v", err, dbusapi.ErrReauthRequired)
+	}
+	if len(dbus.signals) != 1 ||
+		dbus.signals[0] != dbusapi.SignalReauthRequired {
+		t.Errorf("got %v, want reauth required signal", dbus.signals)
+	}
+
+	// login info of other user
+	d.reconnect.setLogin(testLogin())
+	d.reconnect.setLoginUID(1001)
+	err = d.startReconnect(request)
+	if !errors.Is(err, dbusapi.ErrReauthRequired) {
+		t.Errorf("got %v, want %v", err, dbusapi.ErrReauthRequired)
+	}
+
+	// trusted network
+	request.UID = 1001
+	d.status.TrustedNetwork = vpnstatus.TrustedNetworkTrusted
+	if err := d.startReconnect(request); err != errReconnectNotNeeded {
+		t.Errorf("got %v, want %v", err, errReconnectNotNeeded)
+	}
+
+	// connected, disconnect and reconnect after teardown
+	d.status.TrustedNetwork = vpnstatus.TrustedNetworkNotTrusted
+	d.status.OCRunning = vpnstatus.OCRunningRunning
+	d.state.state = vpnstatus.ConnectionStateConnected
+	if err := d.startReconnect(request); err != nil {
+		t.Fatal(err)
+	}
+	if !d.reconnectAfterDisconnect || !d.disconnectRequested ||
+		d.state.get() != vpnstatus.ConnectionStateDisconnecting {
+		t.Error("vpn should be disconnecting and reconnect afterwards")
+	}
+
+	// reconnect finished
+	d.finishReconnect(nil)
+	if d.reconnectRequests != nil {
+		t.Errorf("got %v, want no reconnect requests", d.reconnectRequests)
+	}
+}
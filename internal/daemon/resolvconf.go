@@ -0,0 +1,248 @@
+package daemon
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/dnsmon"
+)
+
+// resolvConfFile is the resolver configuration of the system
+var resolvConfFile = "/etc/resolv.conf"
+
+// maxResolvConfRestores is the maximum number of restores of resolv.conf
+// per connection, it stops fights with other tools that keep overwriting
+// the file
+const maxResolvConfRestores = 10
+
+// resolvConf is the state of a resolv.conf file
+type resolvConf struct {
+	// link is the target if the file is a symlink, e.g., to the stub
+	// resolver configuration of systemd-resolved
+	link string
+
+	// content is the content of the file and nameservers are its name
+	// servers, if the file is no symlink
+	content     []byte
+	nameservers []string
+}
+
+// generator returns the tool that generated the resolv.conf file from its
+// first comment line, e.g., "Generated by NetworkManager"
+func (r *resolvConf) generator() string {
+	if r.link != "" {
+		return r.link
+	}
+	s := bufio.NewScanner(bytes.NewReader(r.content))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+		return strings.TrimSpace(strings.TrimLeft(line, "#"))
+	}
+	return "unknown"
+}
+
+// equal returns whether r and other point to the same name servers; if
+// the file is a symlink, only the target is compared since the target,
+// e.g., of systemd-resolved, changes with the network configuration
+func (r *resolvConf) equal(other *resolvConf) bool {
+	if r.link != "" || other.link != "" {
+		return r.link == other.link
+	}
+	return reflect.DeepEqual(r.nameservers, other.nameservers)
+}
+
+// readNameservers returns the name servers in resolv.conf content
+func readNameservers(content []byte) []string {
+	nameservers := []string{}
+	s := bufio.NewScanner(bytes.NewReader(content))
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 2 || fields[0] != "nameserver" {
+			continue
+		}
+		nameservers = append(nameservers, fields[1])
+	}
+	return nameservers
+}
+
+// readResolvConf reads the resolv.conf file
+func readResolvConf(file string) (*resolvConf, error) {
+	fi, err := os.Lstat(file)
+	if err != nil {
+		return nil, err
+	}
+	if fi.Mode()&os.ModeSymlink != 0 {
+		link, err := os.Readlink(file)
+		if err != nil {
+			return nil, err
+		}
+		return &resolvConf{link: link}, nil
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return &resolvConf{
+		content:     b,
+		nameservers: readNameservers(b),
+	}, nil
+}
+
+// writeResolvConf replaces the resolv.conf file with r
+func writeResolvConf(file string, r *resolvConf) error {
+	tmp := filepath.Join(filepath.Dir(file), ".oc-daemon-resolv.conf")
+	_ = os.Remove(tmp)
+	if r.link != "" {
+		if err := os.Symlink(r.link, tmp); err != nil {
+			return err
+		}
+	} else if err := os.WriteFile(tmp, r.content, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// resolvConfGuard watches resolv.conf while the VPN is connected and
+// detects changes by other tools, e.g., dhclient hooks or NetworkManager,
+// that would bypass the DNS settings of the daemon
+type resolvConfGuard struct {
+	file     string
+	mon      *dnsmon.DNSMon
+	saved    *resolvConf
+	restores int
+}
+
+// start saves the current resolv.conf and starts watching it
+func (r *resolvConfGuard) start(ctx context.Context) error {
+	r.stop()
+	saved, err := readResolvConf(r.file)
+	if err != nil {
+		return err
+	}
+	mon := dnsmon.NewDNSMon()
+	if err := mon.Start(ctx); err != nil {
+		return err
+	}
+	r.mon = mon
+	r.saved = saved
+	r.restores = 0
+	return nil
+}
+
+// stop stops watching resolv.conf
+func (r *resolvConfGuard) stop() {
+	if r.mon != nil {
+		r.mon.Stop()
+		r.mon = nil
+	}
+	r.saved = nil
+}
+
+// check checks resolv.conf after an update and returns its current state
+// if another tool changed it
+func (r *resolvConfGuard) check() (*resolvConf, error) {
+	current, err := readResolvConf(r.file)
+	if err != nil {
+		return nil, err
+	}
+	if r.saved == nil || r.saved.equal(current) {
+		return nil, nil
+	}
+	return current, nil
+}
+
+// restore restores the saved resolv.conf, it returns false if the file
+// was restored too often during this connection
+func (r *resolvConfGuard) restore() (bool, error) {
+	if r.saved == nil || r.restores >= maxResolvConfRestores {
+		return false, nil
+	}
+	r.restores++
+	return true, writeResolvConf(r.file, r.saved)
+}
+
+// updatesC returns the channel of resolv.conf updates or nil if the guard
+// is not running
+func (r *resolvConfGuard) updatesC() <-chan struct{} {
+	if r.mon == nil {
+		return nil
+	}
+	return r.mon.Updates()
+}
+
+// newResolvConfGuard returns a new resolvConfGuard for file
+func newResolvConfGuard(file string) *resolvConfGuard {
+	return &resolvConfGuard{
+		file: file,
+	}
+}
+
+// startResolvConfGuard starts watching resolv.conf and the other files of
+// the resolver configuration
+func (d *Daemon) startResolvConfGuard() {
+	if err := d.resolvConf.start(d.ctx); err != nil {
+		log.WithError(err).Error("Daemon could not start resolv.conf guard")
+	}
+}
+
+// handleResolvConfUpdate handles an update of the resolver configuration,
+// it checks the routing domains for conflicts with other links and, if
+// enabled, restores resolv.conf and the DNS settings of the VPN connection
+// if another tool changed them
+func (d *Daemon) handleResolvConfUpdate() {
+	d.checkDomainConflicts()
+	if !d.config.ResolvConfGuard {
+		return
+	}
+
+	current, err := d.resolvConf.check()
+	if err != nil {
+		log.WithError(err).Error("Daemon could not check resolv.conf")
+		return
+	}
+	if current == nil {
+		return
+	}
+
+	saved := d.resolvConf.saved
+	log.WithFields(logrus.Fields{
+		"generator":   current.generator(),
+		"link":        current.link,
+		"nameservers": current.nameservers,
+		"saved":       saved.nameservers,
+		"savedLink":   saved.link,
+	}).Warn("Daemon detected change of resolv.conf by another tool")
+
+	restored, err := d.resolvConf.restore()
+	if err != nil {
+		log.WithError(err).Error("Daemon could not restore resolv.conf")
+		return
+	}
+	if !restored {
+		log.Error("Daemon restored resolv.conf too often, " +
+			"not restoring it again during this connection")
+		return
+	}
+
+	// re-apply dns settings of the vpn connection
+	if config := d.status.VPNConfig; config != nil {
+		setVPNDNS(config, getDNSServers(d.dnsAddr, config))
+	}
+	log.Info("Daemon restored resolv.conf and DNS settings")
+}
@@ -0,0 +1,233 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// TestReadResolvConf tests readResolvConf
+func TestReadResolvConf(t *testing.T) {
+	dir := t.TempDir()
+
+	// regular file
+	file := filepath.Join(dir, "resolv.conf")
+	content := "# Generated by NetworkManager\n" +
+		"search example.com\n" +
+		"nameserver 192.168.1.1\n" +
+		"nameserver 2001:db8::1\n"
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := readResolvConf(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"192.168.1.1", "2001:db8::1"}
+	if r.link != "" || !reflect.DeepEqual(r.nameservers, want) {
+		t.Errorf("got %v, want %v", r.nameservers, want)
+	}
+	if got := r.generator(); got != "Generated by NetworkManager" {
+		t.Errorf("got %s, want Generated by NetworkManager", got)
+	}
+
+	// symlink
+	link := filepath.Join(dir, "link.conf")
+	if err := os.Symlink(file, link); err != nil {
+		t.Fatal(err)
+	}
+	r, err = readResolvConf(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.link != file || r.generator() != file {
+		t.Errorf("got %s, want %s", r.link, file)
+	}
+
+	// not existing
+	if _, err := readResolvConf(filepath.Join(dir, "missing")); err == nil {
+		t.Error("reading missing file should fail")
+	}
+}
+
+// TestResolvConfEqual tests equal of resolvConf
+func TestResolvConfEqual(t *testing.T) {
+	for _, test := range []struct {
+		a, b *resolvConf
+		want bool
+	}{
+		{&resolvConf{link: "a"}, &resolvConf{link: "a"}, true},
+		{&resolvConf{link: "a"}, &resolvConf{link: "b"}, false},
+		{&resolvConf{link: "a"}, &resolvConf{nameservers: []string{}}, false},
+		{
+			&resolvConf{content: []byte("a"), nameservers: []string{"127.0.0.53"}},
+			&resolvConf{content: []byte("b"), nameservers: []string{"127.0.0.53"}},
+			true,
+		},
+		{
+			&resolvConf{nameservers: []string{"127.0.0.53"}},
+			&resolvConf{nameservers: []string{"192.168.1.1"}},
+			false,
+		},
+	} {
+		if got := test.a.equal(test.b); got != test.want {
+			t.Errorf("%v, %v: got %t, want %t", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+// TestResolvConfGeneratorUnknown tests generator of resolvConf without
+// comment header
+func TestResolvConfGeneratorUnknown(t *testing.T) {
+	r := &resolvConf{content: []byte("\nnameserver 10.0.0.1\n# comment\n")}
+	if got := r.generator(); got != "unknown" {
+		t.Errorf("got %s, want unknown", got)
+	}
+}
+
+// TestResolvConfGuardCheckRestore tests check and restore of
+// resolvConfGuard
+func TestResolvConfGuardCheckRestore(t *testing.T) {
+	dir := t.TempDir()
+	stub := filepath.Join(dir, "stub-resolv.conf")
+	if err := os.WriteFile(stub, []byte("nameserver 127.0.0.53\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "resolv.conf")
+	if err := os.Symlink(stub, file); err != nil {
+		t.Fatal(err)
+	}
+
+	r := newResolvConfGuard(file)
+	saved, err := readResolvConf(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.saved = saved
+
+	// not changed
+	if current, err := r.check(); err != nil || current != nil {
+		t.Errorf("got %v, %v, want nil, nil", current, err)
+	}
+
+	// symlink replaced with file
+	if err := os.Remove(file); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte("# Generated by dhclient\n"+
+		"nameserver 192.168.1.1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	current, err := r.check()
+	if err != nil || current == nil {
+		t.Fatalf("got %v, %v, want change", current, err)
+	}
+	if current.generator() != "Generated by dhclient" {
+		t.Errorf("got %s, want Generated by dhclient", current.generator())
+	}
+
+	// restore
+	if ok, err := r.restore(); !ok || err != nil {
+		t.Errorf("got %t, %v, want true, nil", ok, err)
+	}
+	if current, err := r.check(); err != nil || current != nil {
+		t.Errorf("got %v, %v, want nil, nil", current, err)
+	}
+
+	// too many restores
+	r.restores = maxResolvConfRestores
+	if ok, err := r.restore(); ok || err != nil {
+		t.Errorf("got %t, %v, want false, nil", ok, err)
+	}
+}
+
+// TestResolvConfGuardStartStop tests start and stop of resolvConfGuard
+func TestResolvConfGuardStartStop(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "resolv.conf")
+	r := newResolvConfGuard(file)
+
+	// missing file
+	if err := r.start(context.Background()); err == nil {
+		t.Error("start should fail with missing file")
+	}
+	if r.updatesC() != nil {
+		t.Error("updates channel should be nil")
+	}
+
+	// existing file
+	if err := os.WriteFile(file, []byte("nameserver 127.0.0.53\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if r.updatesC() == nil || r.saved == nil {
+		t.Error("guard should be running")
+	}
+	r.stop()
+	if r.updatesC() != nil || r.saved != nil {
+		t.Error("guard should be stopped")
+	}
+}
+
+// TestDaemonHandleResolvConfUpdate tests handleResolvConfUpdate of Daemon
+func TestDaemonHandleResolvConfUpdate(t *testing.T) {
+	oldRunResolvectl := runResolvectl
+	defer func() { runResolvectl = oldRunResolvectl }()
+	got := []string{}
+	runResolvectl = func(cmd string) {
+		got = append(got, cmd)
+	}
+	oldGetResolvedDomains := getResolvedDomains
+	defer func() { getResolvedDomains = oldGetResolvedDomains }()
+	getResolvedDomains = func() ([]resolvedDomain, error) {
+		return nil, nil
+	}
+
+	file := filepath.Join(t.TempDir(), "resolv.conf")
+	if err := os.WriteFile(file, []byte("nameserver 127.0.0.53\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := vpnconfig.New()
+	config.Device.Name = "tun0"
+	d := &Daemon{
+		config:     NewConfig(),
+		status:     vpnstatus.New(),
+		dnsAddr:    "127.0.0.1:4253",
+		resolvConf: newResolvConfGuard(file),
+	}
+	d.status.VPNConfig = config
+	saved, err := readResolvConf(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.resolvConf.saved = saved
+
+	// not changed
+	d.handleResolvConfUpdate()
+	if len(got) != 0 {
+		t.Errorf("got %v, want no commands", got)
+	}
+
+	// changed by other tool
+	if err := os.WriteFile(file, []byte("nameserver 192.168.1.1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	d.handleResolvConfUpdate()
+	if len(got) == 0 || !strings.HasPrefix(got[0], "dns tun0") {
+		t.Errorf("got %v, want dns settings applied", got)
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "nameserver 127.0.0.53\n" {
+		t.Errorf("got %s, want restored file", b)
+	}
+}
@@ -0,0 +1,230 @@
+package daemon
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/clock"
+	"github.com/telekom-mms/oc-daemon/internal/ocrunner"
+)
+
+const (
+	// runtimeGCInterval is the interval between cleanups of stale
+	// runtime files
+	runtimeGCInterval = time.Hour
+
+	// runtimeGCAge is the minimum age of stale runtime files, younger
+	// files may still be in use, e.g., while they are written
+	runtimeGCAge = time.Hour
+
+	// runtimeGCDialTimeout is the timeout of checking if a socket is in
+	// use
+	runtimeGCDialTimeout = time.Second
+)
+
+// runtimeGCPatterns are the name patterns of runtime files the daemon
+// creates, only these files are removed from the runtime directory
+var runtimeGCPatterns = []string{
+	"daemon.pid",
+	"openconnect.pid",
+	"openconnect-*.pid",
+	"daemon*.sock",
+	"vpncscript*.secret",
+	"vpncscript*.secret.tmp",
+	"manifest.json.tmp",
+}
+
+// runtimeGC removes stale runtime files of previous daemon runs, e.g., pid
+// files, sockets and secret files of removed tunnels, from the runtime
+// directory on startup and periodically
+type runtimeGC struct {
+	clock clock.Clock
+	timer clock.Timer
+
+	// dir is the runtime directory
+	dir string
+}
+
+// schedule schedules the next cleanup
+func (g *runtimeGC) schedule() {
+	g.stop()
+	g.timer = g.clock.NewTimer(runtimeGCInterval)
+}
+
+// stop stops the periodic cleanups
+func (g *runtimeGC) stop() {
+	if g.timer != nil {
+		g.timer.Stop()
+		g.timer = nil
+	}
+}
+
+// timerC returns the channel of the cleanup timer or nil if it is stopped
+func (g *runtimeGC) timerC() <-chan time.Time {
+	if g.timer == nil {
+		return nil
+	}
+	return g.timer.C()
+}
+
+// processRunning returns whether the process with pid is running
+var processRunning = func(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// isRuntimeFileName returns whether name is the name of a runtime file the
+// daemon creates
+func isRuntimeFileName(name string) bool {
+	for _, p := range runtimeGCPatterns {
+		if ok, _ := filepath.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRuntimeDir returns an error if dir is not owned by the daemon user or
+// if other users can create files in it, e.g., /tmp, so it may contain
+// files of others
+func checkRuntimeDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return errors.New("not a directory")
+	}
+	if st, ok := info.Sys().(*syscall.Stat_t); !ok || int(st.Uid) != os.Geteuid() {
+		return errors.New("not owned by daemon user")
+	}
+	if info.Mode().Perm()&0022 != 0 {
+		return errors.New("writable by other users")
+	}
+	return nil
+}
+
+// staleReason returns why the runtime file with info is stale, empty if it
+// is not stale: pid files of processes that are not running, sockets
+// without listener and other regular files, e.g., secret files
+func staleReason(file string, info os.FileInfo) string {
+	switch {
+	case info.Mode()&os.ModeSocket != 0:
+		conn, err := net.DialTimeout("unix", file, runtimeGCDialTimeout)
+		if err == nil {
+			_ = conn.Close()
+			return ""
+		}
+		return "socket without listener"
+
+	case !info.Mode().IsRegular():
+		// keep directories and other special files
+		return ""
+
+	case strings.HasSuffix(file, ".pid"):
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return ""
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+		if err == nil && pid > 0 && processRunning(pid) {
+			return ""
+		}
+		return "pid file without running process"
+	}
+	return "orphaned file"
+}
+
+// collect removes the stale runtime files in the runtime directory that are
+// older than runtimeGCAge and not in keep, it returns the removed files with
+// the reasons they were stale. Other files and runtime directories that may
+// contain files of other users are never touched
+func (g *runtimeGC) collect(keep []string) map[string]string {
+	removed := make(map[string]string)
+	if err := checkRuntimeDir(g.dir); err != nil {
+		log.WithError(err).WithField("dir", g.dir).
+			Debug("Daemon skipped cleanup of runtime dir")
+		return removed
+	}
+	entries, err := os.ReadDir(g.dir)
+	if err != nil {
+		log.WithError(err).WithField("dir", g.dir).
+			Error("Daemon could not read runtime dir for cleanup")
+		return removed
+	}
+
+	known := make(map[string]bool)
+	for _, k := range keep {
+		known[filepath.Clean(k)] = true
+	}
+	now := g.clock.Now()
+	for _, e := range entries {
+		file := filepath.Join(g.dir, e.Name())
+		if known[file] || !isRuntimeFileName(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || now.Sub(info.ModTime()) < runtimeGCAge {
+			continue
+		}
+		reason := staleReason(file, info)
+		if reason == "" {
+			continue
+		}
+		if err := os.Remove(file); err != nil {
+			log.WithError(err).WithField("file", file).
+				Error("Daemon could not remove stale runtime file")
+			continue
+		}
+		removed[file] = reason
+	}
+	return removed
+}
+
+// newRuntimeGC returns a new runtimeGC for the runtime directory dir
+func newRuntimeGC(dir string) *runtimeGC {
+	return &runtimeGC{
+		clock: clock.New(),
+		dir:   dir,
+	}
+}
+
+// runtimeFiles returns the runtime files of the running daemon
+func (d *Daemon) runtimeFiles() []string {
+	files := []string{
+		lockFile,
+		manifestFile,
+		ocrunner.PIDFile,
+		sockFile,
+		grpcSockFile,
+		d.secret.file(),
+	}
+	for _, t := range d.tunnels {
+		files = append(files, t.pidFile(), t.secret.file())
+	}
+	return files
+}
+
+// handleRuntimeGC removes stale runtime files, reports them and schedules
+// the next cleanup
+func (d *Daemon) handleRuntimeGC() {
+	removed := d.runtimeGC.collect(d.runtimeFiles())
+	for file, reason := range removed {
+		log.WithFields(logrus.Fields{
+			"file":   file,
+			"reason": reason,
+		}).Info("Daemon removed stale runtime file")
+	}
+	if len(removed) > 0 {
+		log.WithField("count", len(removed)).
+			Info("Daemon cleaned up stale runtime files")
+	}
+	d.runtimeGC.schedule()
+}
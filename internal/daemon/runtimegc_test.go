@@ -0,0 +1,173 @@
+package daemon
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/clock"
+)
+
+// TestRuntimeGCTimer tests schedule, stop and timerC of runtimeGC
+func TestRuntimeGCTimer(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	g := newRuntimeGC(t.TempDir())
+	g.clock = fake
+
+	if g.timerC() != nil {
+		t.Error("timer should not be running")
+	}
+	g.schedule()
+	fake.Advance(runtimeGCInterval)
+	select {
+	case <-g.timerC():
+	default:
+		t.Error("timer should fire after interval")
+	}
+	g.stop()
+	if g.timerC() != nil {
+		t.Error("timer should be stopped")
+	}
+}
+
+// TestRuntimeGCCollect tests collect of runtimeGC
+func TestRuntimeGCCollect(t *testing.T) {
+	oldProcessRunning := processRunning
+	defer func() { processRunning = oldProcessRunning }()
+	processRunning = func(pid int) bool { return pid == 1234 }
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return file
+	}
+	known := write("daemon.pid", "1")
+	running := write("openconnect-lab.pid", "1234\n")
+	stale := write("openconnect-old.pid", "4321\n")
+	invalid := write("openconnect-invalid.pid", "invalid")
+	orphaned := write("vpncscript-old.secret", "secret")
+	tmp := write("vpncscript-old.secret.tmp", "secret")
+	foreign := write("foreign.txt", "foreign")
+	foreignPID := write("other.pid", "4321\n")
+	if err := os.Mkdir(filepath.Join(dir, "state"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	// sockets with and without listener
+	listening := filepath.Join(dir, "daemon-grpc.sock")
+	l, err := net.Listen("unix", listening)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = l.Close() }()
+	closeSocket := func(name string) string {
+		file := filepath.Join(dir, name)
+		cl, err := net.Listen("unix", file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cl.(*net.UnixListener).SetUnlinkOnClose(false)
+		_ = cl.Close()
+		return file
+	}
+	closed := closeSocket("daemon.sock")
+	foreignSock := closeSocket("other.sock")
+
+	// files are too young
+	fake := clock.NewFake(time.Now())
+	g := newRuntimeGC(dir)
+	g.clock = fake
+	if got := g.collect([]string{known}); len(got) != 0 {
+		t.Errorf("got %v, want no removed files", got)
+	}
+
+	// stale files are removed
+	fake.Advance(runtimeGCAge)
+	want := map[string]string{
+		stale:    "pid file without running process",
+		invalid:  "pid file without running process",
+		orphaned: "orphaned file",
+		tmp:      "orphaned file",
+		closed:   "socket without listener",
+	}
+	if got := g.collect([]string{known}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for _, file := range []string{known, running, listening, foreign,
+		foreignPID, foreignSock, filepath.Join(dir, "state")} {
+		if _, err := os.Stat(file); err != nil {
+			t.Errorf("%s should be kept: %v", file, err)
+		}
+	}
+	for file := range want {
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("%s should be removed: %v", file, err)
+		}
+	}
+
+	// invalid dir
+	g.dir = filepath.Join(dir, "does-not-exist")
+	if got := g.collect(nil); len(got) != 0 {
+		t.Errorf("got %v, want no removed files", got)
+	}
+}
+
+// TestRuntimeGCCollectSharedDir tests collect of runtimeGC in a runtime dir
+// that other users can write to
+func TestRuntimeGCCollectSharedDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0777); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "vpncscript-old.secret")
+	if err := os.WriteFile(file, []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	g := newRuntimeGC(dir)
+	g.clock = clock.NewFake(time.Now().Add(runtimeGCAge))
+	if got := g.collect(nil); len(got) != 0 {
+		t.Errorf("got %v, want no removed files", got)
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("%s should be kept: %v", file, err)
+	}
+}
+
+// TestDaemonHandleRuntimeGC tests handleRuntimeGC of Daemon
+func TestDaemonHandleRuntimeGC(t *testing.T) {
+	dir := t.TempDir()
+	orphaned := filepath.Join(dir, "vpncscript-old.secret")
+	if err := os.WriteFile(orphaned, []byte("test"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	foreign := filepath.Join(dir, "foreign")
+	if err := os.WriteFile(foreign, []byte("test"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := clock.NewFake(time.Now().Add(runtimeGCAge))
+	d := &Daemon{
+		secret:    newConnSecret("vpncscript.secret"),
+		runtimeGC: newRuntimeGC(dir),
+	}
+	d.runtimeGC.clock = fake
+	d.handleRuntimeGC()
+	defer d.runtimeGC.stop()
+
+	if _, err := os.Stat(orphaned); !os.IsNotExist(err) {
+		t.Errorf("orphaned file should be removed: %v", err)
+	}
+	if _, err := os.Stat(foreign); err != nil {
+		t.Errorf("foreign file should be kept: %v", err)
+	}
+	if d.runtimeGC.timerC() == nil {
+		t.Error("next cleanup should be scheduled")
+	}
+}
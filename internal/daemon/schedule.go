@@ -0,0 +1,200 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/clock"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// Schedule window actions
+const (
+	ScheduleActionConnect = "connect"
+	ScheduleActionForbid  = "forbid"
+)
+
+// scheduleTimeFormat is the format of the start and end times of schedule
+// windows
+const scheduleTimeFormat = "15:04"
+
+// scheduleDays are the valid days of schedule windows
+var scheduleDays = map[string]time.Weekday{
+	"Sun": time.Sunday,
+	"Mon": time.Monday,
+	"Tue": time.Tuesday,
+	"Wed": time.Wednesday,
+	"Thu": time.Thursday,
+	"Fri": time.Friday,
+	"Sat": time.Saturday,
+}
+
+// ScheduleWindow is a time window of the connection schedule
+type ScheduleWindow struct {
+	// Days are the days of the week the window starts on, e.g., "Mon",
+	// every day if empty
+	Days []string
+
+	// Start and End are the local start and end times of the window,
+	// e.g., "08:00"; the window spans midnight if End is before Start
+	Start string
+	End   string
+
+	// Action is the action during the window, "connect" or "forbid"
+	Action string
+}
+
+// minutes returns the start and end times of the window in minutes since
+// midnight
+func (w *ScheduleWindow) minutes() (start, end int, err error) {
+	s, err := time.Parse(scheduleTimeFormat, w.Start)
+	if err != nil {
+		return
+	}
+	e, err := time.Parse(scheduleTimeFormat, w.End)
+	if err != nil {
+		return
+	}
+	start = s.Hour()*60 + s.Minute()
+	end = e.Hour()*60 + e.Minute()
+	return
+}
+
+// startsOn returns whether the window starts on day
+func (w *ScheduleWindow) startsOn(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if scheduleDays[d] == day {
+			return true
+		}
+	}
+	return false
+}
+
+// contains returns whether the window contains the local time t
+func (w *ScheduleWindow) contains(t time.Time) bool {
+	start, end, err := w.minutes()
+	if err != nil {
+		return false
+	}
+	now := t.Hour()*60 + t.Minute()
+	if start <= end {
+		return w.startsOn(t.Weekday()) && start <= now && now < end
+	}
+
+	// window spans midnight, it either started today or yesterday
+	yesterday := t.AddDate(0, 0, -1).Weekday()
+	return (w.startsOn(t.Weekday()) && now >= start) ||
+		(w.startsOn(yesterday) && now < end)
+}
+
+// Valid returns whether the schedule window is valid
+func (w *ScheduleWindow) Valid() bool {
+	for _, d := range w.Days {
+		if _, ok := scheduleDays[d]; !ok {
+			return false
+		}
+	}
+	start, end, err := w.minutes()
+	if err != nil || start == end {
+		return false
+	}
+	switch w.Action {
+	case ScheduleActionConnect, ScheduleActionForbid:
+	default:
+		return false
+	}
+	return true
+}
+
+// Schedule is the connection schedule of the daemon, it automatically
+// connects the VPN on untrusted networks or forbids VPN connections during
+// its windows
+type Schedule struct {
+	Enabled bool
+
+	// Windows are the time windows of the schedule, the first window
+	// that contains the current time is used
+	Windows []ScheduleWindow
+}
+
+// Copy returns a copy of the schedule
+func (s *Schedule) Copy() Schedule {
+	cp := Schedule{Enabled: s.Enabled}
+	for _, w := range s.Windows {
+		w.Days = append(w.Days[:0:0], w.Days...)
+		cp.Windows = append(cp.Windows, w)
+	}
+	return cp
+}
+
+// Valid returns whether the schedule is valid
+func (s *Schedule) Valid() bool {
+	for _, w := range s.Windows {
+		if !w.Valid() {
+			return false
+		}
+	}
+	return true
+}
+
+// state returns the schedule state at local time t
+func (s *Schedule) state(t time.Time) vpnstatus.ScheduleState {
+	if !s.Enabled {
+		return vpnstatus.ScheduleStateDisabled
+	}
+	for _, w := range s.Windows {
+		if !w.contains(t) {
+			continue
+		}
+		if w.Action == ScheduleActionConnect {
+			return vpnstatus.ScheduleStateConnect
+		}
+		return vpnstatus.ScheduleStateForbid
+	}
+	return vpnstatus.ScheduleStateInactive
+}
+
+// scheduler checks the connection schedule at the start of every minute
+type scheduler struct {
+	clock clock.Clock
+	timer clock.Timer
+}
+
+// start starts the scheduler timer
+func (s *scheduler) start() {
+	s.stop()
+	now := s.clock.Now()
+	next := now.Truncate(time.Minute).Add(time.Minute)
+	s.timer = s.clock.NewTimer(next.Sub(now))
+}
+
+// stop stops the scheduler timer
+func (s *scheduler) stop() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+}
+
+// now returns the current local time
+func (s *scheduler) now() time.Time {
+	return s.clock.Now().Local()
+}
+
+// timerC returns the channel of the scheduler timer or nil if the scheduler
+// is not running
+func (s *scheduler) timerC() <-chan time.Time {
+	if s.timer == nil {
+		return nil
+	}
+	return s.timer.C()
+}
+
+// newScheduler returns a new scheduler
+func newScheduler() *scheduler {
+	return &scheduler{
+		clock: clock.New(),
+	}
+}
@@ -0,0 +1,161 @@
+package daemon
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/clock"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// testScheduleTime returns the local test time on weekday at hour and min,
+// 2024-01-01 is a monday
+func testScheduleTime(weekday time.Weekday, hour, min int) time.Time {
+	return time.Date(2024, 1, int(weekday), hour, min, 0, 0, time.Local)
+}
+
+// TestScheduleWindowValid tests Valid of ScheduleWindow
+func TestScheduleWindowValid(t *testing.T) {
+	// test invalid
+	for _, invalid := range []*ScheduleWindow{
+		{},
+		{Start: "08:00", End: "18:00"},
+		{Start: "08:00", End: "08:00", Action: ScheduleActionConnect},
+		{Start: "8", End: "18:00", Action: ScheduleActionConnect},
+		{Start: "08:00", End: "25:00", Action: ScheduleActionConnect},
+		{Days: []string{"Monday"}, Start: "08:00", End: "18:00", Action: ScheduleActionConnect},
+		{Start: "08:00", End: "18:00", Action: "invalid"},
+	} {
+		if invalid.Valid() {
+			t.Errorf("window should be invalid: %v", invalid)
+		}
+	}
+
+	// test valid
+	for _, valid := range []*ScheduleWindow{
+		{Start: "08:00", End: "18:00", Action: ScheduleActionConnect},
+		{Start: "22:00", End: "06:00", Action: ScheduleActionForbid},
+		{Days: []string{"Mon", "Fri"}, Start: "08:00", End: "18:00", Action: ScheduleActionConnect},
+	} {
+		if !valid.Valid() {
+			t.Errorf("window should be valid: %v", valid)
+		}
+	}
+}
+
+// TestScheduleWindowContains tests contains of ScheduleWindow
+func TestScheduleWindowContains(t *testing.T) {
+	// work hours on weekdays
+	w := &ScheduleWindow{
+		Days:   []string{"Mon", "Tue", "Wed", "Thu", "Fri"},
+		Start:  "08:00",
+		End:    "18:00",
+		Action: ScheduleActionConnect,
+	}
+	for tm, want := range map[time.Time]bool{
+		testScheduleTime(time.Monday, 7, 59):   false,
+		testScheduleTime(time.Monday, 8, 0):    true,
+		testScheduleTime(time.Friday, 17, 59):  true,
+		testScheduleTime(time.Friday, 18, 0):   false,
+		testScheduleTime(time.Saturday, 12, 0): false,
+	} {
+		if got := w.contains(tm); got != want {
+			t.Errorf("%s: got %t, want %t", tm, got, want)
+		}
+	}
+
+	// nights starting on fridays
+	w = &ScheduleWindow{
+		Days:   []string{"Fri"},
+		Start:  "22:00",
+		End:    "06:00",
+		Action: ScheduleActionForbid,
+	}
+	for tm, want := range map[time.Time]bool{
+		testScheduleTime(time.Friday, 21, 59):  false,
+		testScheduleTime(time.Friday, 22, 0):   true,
+		testScheduleTime(time.Saturday, 5, 59): true,
+		testScheduleTime(time.Saturday, 6, 0):  false,
+		testScheduleTime(time.Saturday, 23, 0): false,
+		testScheduleTime(time.Thursday, 3, 0):  false,
+	} {
+		if got := w.contains(tm); got != want {
+			t.Errorf("%s: got %t, want %t", tm, got, want)
+		}
+	}
+}
+
+// TestScheduleState tests state of Schedule
+func TestScheduleState(t *testing.T) {
+	s := &Schedule{
+		Windows: []ScheduleWindow{
+			{Start: "12:00", End: "13:00", Action: ScheduleActionForbid},
+			{Start: "08:00", End: "18:00", Action: ScheduleActionConnect},
+		},
+	}
+
+	// disabled
+	if got := s.state(testScheduleTime(time.Monday, 9, 0)); got != vpnstatus.ScheduleStateDisabled {
+		t.Errorf("got %s, want disabled", got)
+	}
+
+	// enabled
+	s.Enabled = true
+	for tm, want := range map[time.Time]vpnstatus.ScheduleState{
+		testScheduleTime(time.Monday, 7, 0):   vpnstatus.ScheduleStateInactive,
+		testScheduleTime(time.Monday, 9, 0):   vpnstatus.ScheduleStateConnect,
+		testScheduleTime(time.Monday, 12, 30): vpnstatus.ScheduleStateForbid,
+	} {
+		if got := s.state(tm); got != want {
+			t.Errorf("%s: got %s, want %s", tm, got, want)
+		}
+	}
+}
+
+// TestScheduleCopy tests Copy of Schedule
+func TestScheduleCopy(t *testing.T) {
+	want := Schedule{
+		Enabled: true,
+		Windows: []ScheduleWindow{
+			{Days: []string{"Mon"}, Start: "08:00", End: "18:00", Action: ScheduleActionConnect},
+		},
+	}
+	got := want.Copy()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	got.Windows[0].Days[0] = "Tue"
+	if want.Windows[0].Days[0] != "Mon" {
+		t.Error("copy should not modify original")
+	}
+}
+
+// TestSchedulerTimer tests the timer of scheduler
+func TestSchedulerTimer(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 1, 1, 8, 0, 30, 0, time.UTC))
+	s := newScheduler()
+	s.clock = fake
+
+	// not started
+	if s.timerC() != nil {
+		t.Error("timer should not be running")
+	}
+
+	// timer expires at start of next minute
+	s.start()
+	fake.Advance(29 * time.Second)
+	select {
+	case <-s.timerC():
+		t.Error("timer should not expire")
+	default:
+	}
+	fake.Advance(time.Second)
+	<-s.timerC()
+
+	// stopped
+	s.stop()
+	if s.timerC() != nil {
+		t.Error("timer should not be running")
+	}
+}
@@ -0,0 +1,106 @@
+package daemon
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/telekom-mms/oc-daemon/internal/api"
+)
+
+var (
+	// secretDir is the directory of the secret files for the vpnc-script
+	secretDir = runDir
+)
+
+const (
+	// secretBytes is the number of random bytes in a connection secret
+	secretBytes = 32
+)
+
+// connSecret is the secret used for the challenge-response authentication
+// of the vpnc-script calls of a single VPN connection, it is rotated on each
+// connect and invalidated when the connection ends, so it is valid as long
+// as the connection is up. The vpnc-script reads it from its file, so it
+// never appears in the environment of openconnect and the vpnc-script
+type connSecret struct {
+	value []byte
+
+	// name is the name of the secret file for the vpnc-script in
+	// secretDir and owner is the uid of its owner, e.g., of openconnect
+	// without root privileges, -1 if the file is owned by root
+	name  string
+	owner int
+}
+
+// file returns the secret file
+func (s *connSecret) file() string {
+	return filepath.Join(secretDir, s.name)
+}
+
+// setOwner sets the owner of the secret file to the user with uid, it must be
+// called before rotate
+func (s *connSecret) setOwner(uid int) {
+	s.owner = uid
+}
+
+// rotate creates a new secret for the next connection and writes it to the
+// secret file, the previous secret is not valid any more
+func (s *connSecret) rotate() error {
+	s.invalidate()
+
+	b := make([]byte, secretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Errorf("could not create secret: %w", err)
+	}
+
+	// replace the file atomically, so the vpnc-script never reads a
+	// partial secret
+	tmp := s.file() + ".tmp"
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return fmt.Errorf("could not write secret file: %w", err)
+	}
+	if s.owner >= 0 {
+		if err := os.Chown(tmp, s.owner, -1); err != nil {
+			_ = os.Remove(tmp)
+			return fmt.Errorf("could not set owner of secret file: %w", err)
+		}
+	}
+	if err := os.Rename(tmp, s.file()); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("could not write secret file: %w", err)
+	}
+
+	s.value = b
+	return nil
+}
+
+// check returns whether response is the response to challenge with the
+// current secret, responses are compared in constant time
+func (s *connSecret) check(challenge, response []byte) bool {
+	if s.value == nil || len(challenge) == 0 {
+		return false
+	}
+	return hmac.Equal(response, api.ChallengeResponse(s.value, challenge))
+}
+
+// invalidate invalidates the current secret and removes the secret file
+func (s *connSecret) invalidate() {
+	s.value = nil
+	if err := os.Remove(s.file()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.WithError(err).WithField("file", s.file()).
+			Error("Daemon could not remove secret file")
+	}
+}
+
+// newConnSecret returns a new connection secret with the secret file name in
+// secretDir, it is not valid until it is rotated
+func newConnSecret(name string) *connSecret {
+	return &connSecret{
+		name:  name,
+		owner: -1,
+	}
+}
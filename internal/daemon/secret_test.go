@@ -0,0 +1,157 @@
+package daemon
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/api"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// setTestSecretDir sets secretDir to a temporary directory in test t
+func setTestSecretDir(t *testing.T) {
+	old := secretDir
+	t.Cleanup(func() { secretDir = old })
+	secretDir = t.TempDir()
+}
+
+// testSecretResponse returns the response to challenge with the secret in
+// the secret file of s
+func testSecretResponse(t *testing.T, s *connSecret, challenge []byte) []byte {
+	secret, err := os.ReadFile(s.file())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return api.ChallengeResponse(secret, challenge)
+}
+
+// TestConnSecretRotate tests rotate of connSecret
+func TestConnSecretRotate(t *testing.T) {
+	setTestSecretDir(t)
+	challenge := []byte("challenge")
+	cs := newConnSecret("test.secret")
+	if cs.check(challenge, nil) {
+		t.Error("empty response should not be valid")
+	}
+
+	// first secret
+	if err := cs.rotate(); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(cs.file())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("got mode %o, want 0600", fi.Mode().Perm())
+	}
+	r1 := testSecretResponse(t, cs, challenge)
+	if !cs.check(challenge, r1) {
+		t.Error("response with rotated secret should be valid")
+	}
+
+	// second secret, first secret should not be valid any more
+	if err := cs.rotate(); err != nil {
+		t.Fatal(err)
+	}
+	r2 := testSecretResponse(t, cs, challenge)
+	if bytes.Equal(r1, r2) {
+		t.Error("rotated secrets should differ")
+	}
+	if cs.check(challenge, r1) {
+		t.Error("response with old secret should not be valid")
+	}
+	if !cs.check(challenge, r2) {
+		t.Error("response with new secret should be valid")
+	}
+}
+
+// TestConnSecretCheck tests check of connSecret
+func TestConnSecretCheck(t *testing.T) {
+	setTestSecretDir(t)
+	cs := newConnSecret("test.secret")
+	if err := cs.rotate(); err != nil {
+		t.Fatal(err)
+	}
+	challenge := []byte("challenge")
+	response := testSecretResponse(t, cs, challenge)
+
+	// invalid responses
+	for _, invalid := range [][]byte{
+		nil,
+		[]byte("invalid"),
+		response[1:],
+		append(response, 'x'),
+		testSecretResponse(t, cs, []byte("other challenge")),
+	} {
+		if cs.check(challenge, invalid) {
+			t.Errorf("response %x should not be valid", invalid)
+		}
+	}
+
+	// missing challenge
+	if cs.check(nil, api.ChallengeResponse(cs.value, nil)) {
+		t.Error("response without challenge should not be valid")
+	}
+}
+
+// TestConnSecretInvalidate tests invalidate of connSecret
+func TestConnSecretInvalidate(t *testing.T) {
+	setTestSecretDir(t)
+	cs := newConnSecret("test.secret")
+	if err := cs.rotate(); err != nil {
+		t.Fatal(err)
+	}
+	challenge := []byte("challenge")
+	response := testSecretResponse(t, cs, challenge)
+	cs.invalidate()
+	if cs.check(challenge, response) {
+		t.Error("invalidated secret should not be valid")
+	}
+	if _, err := os.Stat(cs.file()); !os.IsNotExist(err) {
+		t.Errorf("secret file should be removed: %v", err)
+	}
+
+	// invalidate again without file
+	cs.invalidate()
+}
+
+// TestDaemonApplyVPNConfigUpdateLongConnection tests applyVPNConfigUpdate of
+// Daemon with a disconnect update of a connection that is up for more than a
+// day
+func TestDaemonApplyVPNConfigUpdateLongConnection(t *testing.T) {
+	setTestSecretDir(t)
+	config := NewConfig()
+	d := &Daemon{
+		config:              config,
+		dbus:                noDBusService{},
+		status:              vpnstatus.New(),
+		state:               newStateMachine(nil),
+		secret:              newConnSecret("vpncscript.secret"),
+		configUpdateLimiter: newRateLimiter(configUpdateRateInterval, configUpdateRateBurst),
+		gateway:             newGatewayMonitor(),
+		idle:                newIdleMonitor(),
+		session:             newSessionTimer(),
+		resolvConf:          newResolvConfGuard(resolvConfFile),
+		pauseToggle:         newToggle(),
+	}
+	if err := d.secret.rotate(); err != nil {
+		t.Fatal(err)
+	}
+	d.status.ConnectedAt = time.Now().Add(-25 * time.Hour).Unix()
+
+	challenge := []byte("challenge")
+	update := &VPNConfigUpdate{
+		Reason:   "disconnect",
+		Response: testSecretResponse(t, d.secret, challenge),
+	}
+	if err := d.applyVPNConfigUpdate(log.WithField("test", t.Name()),
+		update, challenge); err != nil {
+		t.Errorf("disconnect update should be accepted: %v", err)
+	}
+	if d.status.ConnectedAt != 0 {
+		t.Error("connection should be down")
+	}
+}
@@ -0,0 +1,216 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+	"github.com/telekom-mms/oc-daemon/pkg/xmlprofile"
+)
+
+const (
+	// serverPingTimeout is the timeout of the reachability probes of
+	// VPN servers
+	serverPingTimeout = 2 * time.Second
+
+	// serverPort is the default port of VPN servers
+	serverPort = "443"
+)
+
+var (
+	// preferredServersFile is the file with the preferred VPN server per
+	// XML profile, it is kept across restarts of the daemon
+	preferredServersFile = configDir + "/preferred-servers.json"
+)
+
+// serverDialAddress returns the host and port of the VPN server address in
+// the XML profile, e.g., "vpn.example.com:443" for "vpn.example.com/group"
+func serverDialAddress(address string) string {
+	host := address
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	if i := strings.IndexByte(host, '/'); i >= 0 {
+		host = host[:i]
+	}
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(strings.Trim(host, "[]"), serverPort)
+}
+
+// pingServer probes the reachability of the VPN server address with a TCP
+// connection outside of the VPN, it returns the time to establish the
+// connection
+var pingServer = func(address string) (time.Duration, error) {
+	dialer := newMarkDialer()
+	if dialer == nil {
+		dialer = &net.Dialer{}
+	}
+	dialer.Timeout = serverPingTimeout
+
+	start := time.Now()
+	conn, err := dialer.Dial("tcp", serverDialAddress(address))
+	if err != nil {
+		return 0, err
+	}
+	latency := time.Since(start)
+	_ = conn.Close()
+	return latency, nil
+}
+
+// listServers returns the VPN servers in the XML profile
+func (d *Daemon) listServers() []dbusapi.Server {
+	servers := []dbusapi.Server{}
+	for _, h := range d.profile.GetVPNServerHostEntries() {
+		servers = append(servers, dbusapi.Server{
+			Name:    h.HostName,
+			Address: h.HostAddress,
+		})
+	}
+	return servers
+}
+
+// pingServers probes the reachability of servers in parallel and sets their
+// latency, it blocks until all probes finished
+func pingServers(servers []dbusapi.Server) {
+	var wg sync.WaitGroup
+	for i := range servers {
+		wg.Add(1)
+		go func(s *dbusapi.Server) {
+			defer wg.Done()
+			latency, err := pingServer(s.Address)
+			if err != nil {
+				log.WithError(err).WithField("server", s.Address).
+					Debug("Daemon could not reach VPN server")
+				return
+			}
+			s.Reachable = true
+			s.Latency = latency.Microseconds()
+		}(&servers[i])
+	}
+	wg.Wait()
+}
+
+// serverPing is the result of the reachability probes of servers for the
+// pending D-Bus request
+type serverPing struct {
+	pending *pendingRequest
+	servers []dbusapi.Server
+}
+
+// handleListServersPing handles a D-Bus request that lists the VPN servers
+// with reachability probes, the probes run in the background, so the main
+// loop does not wait for them, and the request is completed when they
+// finished
+func (d *Daemon) handleListServersPing(pending *pendingRequest) {
+	servers := d.listServers()
+	go func() {
+		pingServers(servers)
+		select {
+		case d.serverPings <- &serverPing{pending: pending, servers: servers}:
+		case <-d.done:
+			pending.request.Error = errors.New("daemon stopped")
+			pending.request.Close()
+		}
+	}()
+}
+
+// handleServerPing completes the D-Bus request of the server probes p
+func (d *Daemon) handleServerPing(p *serverPing) {
+	p.pending.request.Results = []any{p.servers}
+	d.closeDBusRequest(p.pending)
+}
+
+// loadPreferredServers loads the preferred VPN server per XML profile, the
+// default XML profile has an empty name
+func loadPreferredServers() map[string]string {
+	servers := make(map[string]string)
+	b, err := os.ReadFile(preferredServersFile)
+	if err != nil {
+		return servers
+	}
+	if err := json.Unmarshal(b, &servers); err != nil {
+		log.WithError(err).Error("Daemon could not parse preferred VPN servers")
+		return make(map[string]string)
+	}
+	return servers
+}
+
+// savePreferredServers saves the preferred VPN server per XML profile, the
+// file is replaced atomically, so it is never written partially
+func savePreferredServers(servers map[string]string) error {
+	b, err := json.Marshal(servers)
+	if err != nil {
+		return err
+	}
+	tmp := preferredServersFile + ".tmp"
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, preferredServersFile)
+}
+
+// lookupServerHostName returns the host name of the VPN server in profile,
+// server is either a host name or address in profile, empty if server is
+// not in profile
+func lookupServerHostName(profile *xmlprofile.Profile, server string) string {
+	for _, h := range profile.GetVPNServerHostEntries() {
+		if server == h.HostName || server == h.HostAddress {
+			return h.HostName
+		}
+	}
+	return ""
+}
+
+// preferredServer returns the host name of the preferred VPN server of the
+// current XML profile, empty if it is not set or not in the profile any more
+func (d *Daemon) preferredServer() string {
+	server := loadPreferredServers()[d.profileName]
+	if server == "" {
+		return ""
+	}
+	name := lookupServerHostName(d.profile, server)
+	if name == "" {
+		log.WithField("server", server).
+			Warn("Daemon ignoring preferred VPN server not in XML profile")
+	}
+	return name
+}
+
+// setPreferredServer sets the preferred VPN server of the current XML
+// profile to server, a host name or address in the profile, and saves it;
+// an empty server resets the preferred server
+func (d *Daemon) setPreferredServer(server string) error {
+	name := ""
+	if server != "" {
+		name = lookupServerHostName(d.profile, server)
+		if name == "" {
+			return fmt.Errorf("vpn server %s not in xml profile", server)
+		}
+	}
+
+	servers := loadPreferredServers()
+	if name == "" {
+		delete(servers, d.profileName)
+	} else {
+		servers[d.profileName] = name
+	}
+	if err := savePreferredServers(servers); err != nil {
+		return fmt.Errorf("could not save preferred vpn server: %w", err)
+	}
+
+	log.WithFields(logrus.Fields{
+		"profile": d.profileName,
+		"server":  name,
+	}).Info("Daemon set preferred VPN server")
+	d.setStatusPreferredServer(name)
+	return nil
+}
@@ -0,0 +1,117 @@
+package daemon
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+	"github.com/telekom-mms/oc-daemon/pkg/xmlprofile"
+)
+
+// TestServerDialAddress tests serverDialAddress
+func TestServerDialAddress(t *testing.T) {
+	for address, want := range map[string]string{
+		"vpn.example.com":                   "vpn.example.com:443",
+		"vpn.example.com:8443":              "vpn.example.com:8443",
+		"vpn.example.com/group":             "vpn.example.com:443",
+		"https://vpn.example.com:8443/path": "vpn.example.com:8443",
+		"192.168.1.1":                       "192.168.1.1:443",
+		"[2001:db8::1]":                     "[2001:db8::1]:443",
+		"[2001:db8::1]:8443":                "[2001:db8::1]:8443",
+	} {
+		if got := serverDialAddress(address); got != want {
+			t.Errorf("%s: got %s, want %s", address, got, want)
+		}
+	}
+}
+
+// TestDaemonListServers tests listServers of Daemon and pingServers
+func TestDaemonListServers(t *testing.T) {
+	old := pingServer
+	defer func() { pingServer = old }()
+	pingServer = func(address string) (time.Duration, error) {
+		if address == "vpn2.example.com" {
+			return 0, errors.New("test error")
+		}
+		return 23 * time.Millisecond, nil
+	}
+
+	d := &Daemon{profile: xmlprofile.NewProfile()}
+	d.profile.ServerList.HostEntry = []xmlprofile.HostEntry{
+		{HostName: "VPN 1", HostAddress: "vpn1.example.com"},
+		{HostName: "VPN 2", HostAddress: "vpn2.example.com"},
+	}
+
+	// without ping
+	want := []dbusapi.Server{
+		{Name: "VPN 1", Address: "vpn1.example.com"},
+		{Name: "VPN 2", Address: "vpn2.example.com"},
+	}
+	got := d.listServers()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// with ping
+	want[0].Reachable = true
+	want[0].Latency = 23000
+	pingServers(got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestDaemonSetPreferredServer tests setPreferredServer of Daemon
+func TestDaemonSetPreferredServer(t *testing.T) {
+	old := preferredServersFile
+	defer func() { preferredServersFile = old }()
+	preferredServersFile = filepath.Join(t.TempDir(), "preferred-servers.json")
+
+	d := &Daemon{
+		dbus:    noDBusService{},
+		status:  vpnstatus.New(),
+		profile: xmlprofile.NewProfile(),
+	}
+	d.profile.ServerList.HostEntry = []xmlprofile.HostEntry{
+		{HostName: "VPN 1", HostAddress: "vpn1.example.com"},
+		{HostName: "VPN 2", HostAddress: "vpn2.example.com"},
+	}
+
+	// server not in profile
+	if err := d.setPreferredServer("vpn3.example.com"); err == nil {
+		t.Error("unknown server should return error")
+	}
+
+	// set by address, host name is stored
+	if err := d.setPreferredServer("vpn2.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if d.status.PreferredServer != "VPN 2" || d.preferredServer() != "VPN 2" {
+		t.Errorf("got %s, want VPN 2", d.status.PreferredServer)
+	}
+
+	// other profile has no preferred server
+	d.profileName = "lab"
+	if got := d.preferredServer(); got != "" {
+		t.Errorf("got %s, want empty", got)
+	}
+
+	// server removed from profile
+	d.profileName = ""
+	d.profile.ServerList.HostEntry = d.profile.ServerList.HostEntry[:1]
+	if got := d.preferredServer(); got != "" {
+		t.Errorf("got %s, want empty", got)
+	}
+
+	// reset
+	if err := d.setPreferredServer(""); err != nil {
+		t.Fatal(err)
+	}
+	if d.status.PreferredServer != "" || len(loadPreferredServers()) != 0 {
+		t.Error("preferred server should be reset")
+	}
+}
@@ -0,0 +1,87 @@
+package daemon
+
+import (
+	"sort"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/clock"
+)
+
+// sessionTimer enforces the maximum duration of a VPN session, it expires
+// at every warning before the end of the session and at the end
+type sessionTimer struct {
+	clock clock.Clock
+	timer clock.Timer
+
+	// end is the end of the session, warnings are the pending warnings
+	// before the end, longest first
+	end      time.Time
+	warnings []time.Duration
+}
+
+// schedule schedules the timer for the next warning or the end
+func (s *sessionTimer) schedule() {
+	next := s.end
+	if len(s.warnings) > 0 {
+		next = s.end.Add(-s.warnings[0])
+	}
+	s.timer = s.clock.NewTimer(next.Sub(s.clock.Now()))
+}
+
+// start starts the timer for a session that started at begin and ends
+// after max, warnings are the times before the end when to warn; warnings
+// that already passed are skipped
+func (s *sessionTimer) start(begin time.Time, max time.Duration, warnings []time.Duration) {
+	s.stop()
+	s.end = begin.Add(max)
+	remaining := s.end.Sub(s.clock.Now())
+	s.warnings = nil
+	for _, w := range warnings {
+		if w < remaining {
+			s.warnings = append(s.warnings, w)
+		}
+	}
+	sort.Slice(s.warnings, func(i, j int) bool {
+		return s.warnings[i] > s.warnings[j]
+	})
+	s.schedule()
+}
+
+// stop stops the timer
+func (s *sessionTimer) stop() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+}
+
+// expired handles the expiry of the timer, it returns the remaining time
+// of the session and false for a warning or true at the end of the session
+func (s *sessionTimer) expired() (time.Duration, bool) {
+	remaining := s.end.Sub(s.clock.Now())
+	for len(s.warnings) > 0 && s.warnings[0] >= remaining {
+		s.warnings = s.warnings[1:]
+	}
+	if remaining <= 0 {
+		s.timer = nil
+		return 0, true
+	}
+	s.schedule()
+	return remaining, false
+}
+
+// timerC returns the channel of the timer or nil if the timer is not
+// running
+func (s *sessionTimer) timerC() <-chan time.Time {
+	if s.timer == nil {
+		return nil
+	}
+	return s.timer.C()
+}
+
+// newSessionTimer returns a new sessionTimer
+func newSessionTimer() *sessionTimer {
+	return &sessionTimer{
+		clock: clock.New(),
+	}
+}
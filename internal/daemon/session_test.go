@@ -0,0 +1,103 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/clock"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// TestSessionTimer tests sessionTimer
+func TestSessionTimer(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	s := newSessionTimer()
+	s.clock = fake
+
+	// not started
+	if s.timerC() != nil {
+		t.Error("timer should not run")
+	}
+
+	// warnings before end, unsorted
+	s.start(fake.Now(), time.Hour, []time.Duration{5 * time.Minute, 15 * time.Minute})
+	fake.Advance(45 * time.Minute)
+	<-s.timerC()
+	if remaining, end := s.expired(); end || remaining != 15*time.Minute {
+		t.Errorf("got %s, %t, want 15m, false", remaining, end)
+	}
+	fake.Advance(10 * time.Minute)
+	<-s.timerC()
+	if remaining, end := s.expired(); end || remaining != 5*time.Minute {
+		t.Errorf("got %s, %t, want 5m, false", remaining, end)
+	}
+	fake.Advance(5 * time.Minute)
+	<-s.timerC()
+	if _, end := s.expired(); !end {
+		t.Error("session should end")
+	}
+	if s.timerC() != nil {
+		t.Error("timer should not run after end")
+	}
+
+	// session started earlier, passed warnings skipped
+	s.start(fake.Now().Add(-50*time.Minute), time.Hour, []time.Duration{15 * time.Minute, 5 * time.Minute})
+	if len(s.warnings) != 1 || s.warnings[0] != 5*time.Minute {
+		t.Errorf("got %v, want [5m]", s.warnings)
+	}
+
+	// stopped
+	s.stop()
+	if s.timerC() != nil {
+		t.Error("timer should not run")
+	}
+}
+
+// TestDaemonStartSession tests startSession of Daemon
+func TestDaemonStartSession(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	config := NewConfig()
+	config.SessionLimit = SessionLimit{
+		MaxDuration: time.Hour,
+		Warnings:    []time.Duration{10 * time.Minute},
+		Action:      SessionActionDisconnect,
+	}
+	d := &Daemon{
+		config:  config,
+		dbus:    noDBusService{},
+		status:  vpnstatus.New(),
+		session: newSessionTimer(),
+	}
+	d.session.clock = fake
+
+	// not connected
+	d.startSession()
+	if d.session.timerC() != nil {
+		t.Error("session limit should not run when not connected")
+	}
+
+	// connected, session starts at connection time
+	d.status.ConnectionState = vpnstatus.ConnectionStateConnected
+	d.status.ConnectedAt = fake.Now().Add(-30 * time.Minute).Unix()
+	d.startSession()
+	defer d.session.stop()
+	want := time.Unix(d.status.ConnectedAt, 0).Add(time.Hour)
+	if !d.session.end.Equal(want) {
+		t.Errorf("got %s, want %s", d.session.end, want)
+	}
+
+	// warning
+	fake.Advance(20 * time.Minute)
+	<-d.session.timerC()
+	d.handleSessionTimer()
+	if d.session.timerC() == nil {
+		t.Error("session timer should run until end")
+	}
+
+	// disabled
+	d.config.SessionLimit.MaxDuration = 0
+	d.startSession()
+	if d.session.timerC() != nil {
+		t.Error("session limit should not run when disabled")
+	}
+}
@@ -0,0 +1,78 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+// parseSplitExclude parses the IP address or network address of a split
+// exclude, IP addresses are excluded as host routes
+func parseSplitExclude(address string) (*net.IPNet, error) {
+	exclude := &net.IPNet{}
+	if _, ipnet, err := net.ParseCIDR(address); err == nil {
+		exclude = ipnet
+	} else if ip := net.ParseIP(address); ip == nil {
+		return nil, fmt.Errorf("invalid split exclude address: %q", address)
+	} else if ip4 := ip.To4(); ip4 != nil {
+		exclude.IP = ip4
+		exclude.Mask = net.CIDRMask(32, 32)
+	} else {
+		exclude.IP = ip
+		exclude.Mask = net.CIDRMask(128, 128)
+	}
+
+	// excluding the default route would bypass the VPN completely
+	if ones, _ := exclude.Mask.Size(); ones == 0 {
+		return nil, fmt.Errorf("split exclude of default route not allowed: %s",
+			exclude)
+	}
+	return exclude, nil
+}
+
+// addSplitExclude adds address to the split excludes of the current VPN
+// connection, it is removed on disconnect
+func (d *Daemon) addSplitExclude(address string) error {
+	if d.splitrt == nil {
+		return errors.New("VPN is not connected")
+	}
+	exclude, err := parseSplitExclude(address)
+	if err != nil {
+		return err
+	}
+	if !d.splitrt.AddExclude(exclude) {
+		return fmt.Errorf("%s is already a static split exclude", exclude)
+	}
+	log.WithField("exclude", exclude).Info("Daemon added split exclude")
+	return nil
+}
+
+// removeSplitExclude removes address added with addSplitExclude from the
+// split excludes of the current VPN connection
+func (d *Daemon) removeSplitExclude(address string) error {
+	if d.splitrt == nil {
+		return errors.New("VPN is not connected")
+	}
+	exclude, err := parseSplitExclude(address)
+	if err != nil {
+		return err
+	}
+	if !d.splitrt.RemoveExclude(exclude) {
+		return fmt.Errorf("%s is not a runtime split exclude", exclude)
+	}
+	log.WithField("exclude", exclude).Info("Daemon removed split exclude")
+	return nil
+}
+
+// listSplitExcludes returns the addresses added with addSplitExclude to the
+// split excludes of the current VPN connection
+func (d *Daemon) listSplitExcludes() []string {
+	excludes := []string{}
+	if d.splitrt == nil {
+		return excludes
+	}
+	for _, e := range d.splitrt.RuntimeExcludes() {
+		excludes = append(excludes, e.String())
+	}
+	return excludes
+}
@@ -0,0 +1,54 @@
+package daemon
+
+import "testing"
+
+// TestParseSplitExclude tests parseSplitExclude
+func TestParseSplitExclude(t *testing.T) {
+	for _, test := range []struct {
+		address string
+		want    string
+	}{
+		{"192.168.1.0/24", "192.168.1.0/24"},
+		{"192.168.1.1/24", "192.168.1.0/24"},
+		{"192.168.1.1", "192.168.1.1/32"},
+		{"2001:db8::/32", "2001:db8::/32"},
+		{"2001:db8::1", "2001:db8::1/128"},
+	} {
+		got, err := parseSplitExclude(test.address)
+		if err != nil {
+			t.Errorf("%s: %v", test.address, err)
+			continue
+		}
+		if got.String() != test.want {
+			t.Errorf("%s: got %s, want %s", test.address, got, test.want)
+		}
+	}
+
+	// invalid addresses
+	for _, address := range []string{
+		"",
+		"invalid",
+		"192.168.1.0/33",
+		"0.0.0.0/0",
+		"::/0",
+	} {
+		if _, err := parseSplitExclude(address); err == nil {
+			t.Errorf("%q: should be invalid", address)
+		}
+	}
+}
+
+// TestDaemonSplitExcludesNotConnected tests the split excludes of Daemon
+// without VPN connection
+func TestDaemonSplitExcludesNotConnected(t *testing.T) {
+	d := &Daemon{}
+	if err := d.addSplitExclude("192.168.1.0/24"); err == nil {
+		t.Error("add should fail when not connected")
+	}
+	if err := d.removeSplitExclude("192.168.1.0/24"); err == nil {
+		t.Error("remove should fail when not connected")
+	}
+	if got := d.listSplitExcludes(); len(got) != 0 {
+		t.Errorf("got %v, want empty", got)
+	}
+}
@@ -0,0 +1,102 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// stateTransitions are the valid connection state transitions, transitions
+// into the same state are always valid and do not emit events
+var stateTransitions = map[vpnstatus.ConnectionState][]vpnstatus.ConnectionState{
+	vpnstatus.ConnectionStateUnknown: {
+		vpnstatus.ConnectionStateDisconnected,
+	},
+	vpnstatus.ConnectionStateDisconnected: {
+		vpnstatus.ConnectionStateConnecting,
+	},
+	vpnstatus.ConnectionStateConnecting: {
+		vpnstatus.ConnectionStateConnected,
+		vpnstatus.ConnectionStateDisconnecting,
+		vpnstatus.ConnectionStateDisconnected,
+	},
+	vpnstatus.ConnectionStateConnected: {
+		vpnstatus.ConnectionStateDisconnecting,
+		vpnstatus.ConnectionStateDisconnected,
+	},
+	vpnstatus.ConnectionStateDisconnecting: {
+		vpnstatus.ConnectionStateDisconnected,
+	},
+}
+
+// StateError is an invalid connection state transition
+type StateError struct {
+	From vpnstatus.ConnectionState
+	To   vpnstatus.ConnectionState
+}
+
+// Error returns the error as string
+func (e *StateError) Error() string {
+	return fmt.Sprintf("invalid connection state transition from %s to %s",
+		e.From, e.To)
+}
+
+// StateTransition is a connection state transition event
+type StateTransition struct {
+	From vpnstatus.ConnectionState
+	To   vpnstatus.ConnectionState
+}
+
+// stateMachine is the connection state machine of the daemon
+type stateMachine struct {
+	state vpnstatus.ConnectionState
+
+	// onTransition is called with every state transition
+	onTransition func(*StateTransition)
+}
+
+// get returns the current state
+func (s *stateMachine) get() vpnstatus.ConnectionState {
+	return s.state
+}
+
+// valid returns whether the transition from the current state to state
+// "to" is valid
+func (s *stateMachine) valid(to vpnstatus.ConnectionState) bool {
+	if s.state == to {
+		return true
+	}
+	for _, state := range stateTransitions[s.state] {
+		if state == to {
+			return true
+		}
+	}
+	return false
+}
+
+// transition changes the current state to state "to", it returns a
+// StateError if the transition is not valid
+func (s *stateMachine) transition(to vpnstatus.ConnectionState) error {
+	if !s.valid(to) {
+		return &StateError{From: s.state, To: to}
+	}
+	if s.state == to {
+		return nil
+	}
+
+	t := &StateTransition{From: s.state, To: to}
+	s.state = to
+	if s.onTransition != nil {
+		s.onTransition(t)
+	}
+	return nil
+}
+
+// newStateMachine returns a new state machine in state unknown that calls
+// onTransition with every state transition
+func newStateMachine(onTransition func(*StateTransition)) *stateMachine {
+	return &stateMachine{
+		state:        vpnstatus.ConnectionStateUnknown,
+		onTransition: onTransition,
+	}
+}
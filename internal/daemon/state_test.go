@@ -0,0 +1,86 @@
+package daemon
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// TestStateMachineTransition tests transition of stateMachine
+func TestStateMachineTransition(t *testing.T) {
+	got := []*StateTransition{}
+	s := newStateMachine(func(t *StateTransition) {
+		got = append(got, t)
+	})
+
+	// test valid transitions
+	for _, to := range []vpnstatus.ConnectionState{
+		vpnstatus.ConnectionStateDisconnected,
+		vpnstatus.ConnectionStateConnecting,
+		vpnstatus.ConnectionStateConnected,
+		vpnstatus.ConnectionStateConnected,
+		vpnstatus.ConnectionStateDisconnecting,
+		vpnstatus.ConnectionStateDisconnected,
+	} {
+		if err := s.transition(to); err != nil {
+			t.Errorf("transition to %s should not fail: %v", to, err)
+		}
+		if s.get() != to {
+			t.Errorf("got %s, want %s", s.get(), to)
+		}
+	}
+
+	// check emitted events, transition into same state is not emitted
+	want := []*StateTransition{
+		{vpnstatus.ConnectionStateUnknown, vpnstatus.ConnectionStateDisconnected},
+		{vpnstatus.ConnectionStateDisconnected, vpnstatus.ConnectionStateConnecting},
+		{vpnstatus.ConnectionStateConnecting, vpnstatus.ConnectionStateConnected},
+		{vpnstatus.ConnectionStateConnected, vpnstatus.ConnectionStateDisconnecting},
+		{vpnstatus.ConnectionStateDisconnecting, vpnstatus.ConnectionStateDisconnected},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// test invalid transitions
+	for _, test := range []struct {
+		from vpnstatus.ConnectionState
+		to   vpnstatus.ConnectionState
+	}{
+		{vpnstatus.ConnectionStateUnknown, vpnstatus.ConnectionStateConnected},
+		{vpnstatus.ConnectionStateDisconnected, vpnstatus.ConnectionStateConnected},
+		{vpnstatus.ConnectionStateDisconnected, vpnstatus.ConnectionStateDisconnecting},
+		{vpnstatus.ConnectionStateConnected, vpnstatus.ConnectionStateConnecting},
+		{vpnstatus.ConnectionStateDisconnecting, vpnstatus.ConnectionStateConnected},
+	} {
+		s := newStateMachine(nil)
+		s.state = test.from
+
+		err := s.transition(test.to)
+		stateErr := &StateError{}
+		if !errors.As(err, &stateErr) {
+			t.Errorf("got %v, want state error", err)
+			continue
+		}
+		if stateErr.From != test.from || stateErr.To != test.to {
+			t.Errorf("got %v, want %s to %s", stateErr, test.from, test.to)
+		}
+		if s.get() != test.from {
+			t.Errorf("got %s, want %s", s.get(), test.from)
+		}
+	}
+}
+
+// TestStateErrorError tests Error of StateError
+func TestStateErrorError(t *testing.T) {
+	err := &StateError{
+		From: vpnstatus.ConnectionStateConnected,
+		To:   vpnstatus.ConnectionStateConnecting,
+	}
+	want := "invalid connection state transition from connected to connecting"
+	if err.Error() != want {
+		t.Errorf("got %s, want %s", err.Error(), want)
+	}
+}
@@ -0,0 +1,62 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/telekom-mms/oc-daemon/internal/eventbus"
+)
+
+var (
+	// sysClassNet is the sysfs directory of network devices
+	sysClassNet = "/sys/class/net"
+)
+
+// trafficStats are the traffic statistics of a network device
+type trafficStats struct {
+	RXBytes   uint64
+	TXBytes   uint64
+	RXPackets uint64
+	TXPackets uint64
+}
+
+// event returns the traffic statistics for stats events on the event bus,
+// nil if s is nil
+func (s *trafficStats) event() *eventbus.TrafficStats {
+	if s == nil {
+		return nil
+	}
+	return &eventbus.TrafficStats{RXBytes: s.RXBytes, TXBytes: s.TXBytes}
+}
+
+// readTrafficStats reads the traffic statistics of device from sysfs
+func readTrafficStats(device string) (*trafficStats, error) {
+	dir := filepath.Join(sysClassNet, device, "statistics")
+	read := func(name string) (uint64, error) {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return 0, err
+		}
+		return strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+	}
+
+	stats := &trafficStats{}
+	for _, s := range []struct {
+		name string
+		dest *uint64
+	}{
+		{"rx_bytes", &stats.RXBytes},
+		{"tx_bytes", &stats.TXBytes},
+		{"rx_packets", &stats.RXPackets},
+		{"tx_packets", &stats.TXPackets},
+	} {
+		v, err := read(s.name)
+		if err != nil {
+			return nil, err
+		}
+		*s.dest = v
+	}
+	return stats, nil
+}
@@ -0,0 +1,86 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// TestReadTrafficStats tests readTrafficStats
+func TestReadTrafficStats(t *testing.T) {
+	dir := t.TempDir()
+	old := sysClassNet
+	sysClassNet = dir
+	defer func() { sysClassNet = old }()
+
+	// test not existing device
+	if _, err := readTrafficStats("tun0"); err == nil {
+		t.Errorf("not existing device should return error")
+	}
+
+	// test existing device
+	stats := filepath.Join(dir, "tun0", "statistics")
+	if err := os.MkdirAll(stats, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range map[string]string{
+		"rx_bytes":   "1000\n",
+		"tx_bytes":   "2000\n",
+		"rx_packets": "10\n",
+		"tx_packets": "20\n",
+	} {
+		if err := os.WriteFile(filepath.Join(stats, name),
+			[]byte(value), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := &trafficStats{
+		RXBytes:   1000,
+		TXBytes:   2000,
+		RXPackets: 10,
+		TXPackets: 20,
+	}
+	got, err := readTrafficStats("tun0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestDaemonUpdateStatsUptime tests the uptime of updateStats and
+// stopStats of Daemon
+func TestDaemonUpdateStatsUptime(t *testing.T) {
+	old := sysClassNet
+	sysClassNet = t.TempDir()
+	defer func() { sysClassNet = old }()
+
+	d := &Daemon{
+		dbus:   noDBusService{},
+		status: vpnstatus.New(),
+	}
+
+	// not connected
+	d.updateStats()
+	if d.status.Uptime != 0 {
+		t.Errorf("got %d, want 0", d.status.Uptime)
+	}
+
+	// connected
+	d.status.ConnectedAt = time.Now().Add(-90 * time.Second).Unix()
+	d.updateStats()
+	if d.status.Uptime < 90 || d.status.Uptime > 100 {
+		t.Errorf("got %d, want 90", d.status.Uptime)
+	}
+
+	// stopped
+	d.stopStats()
+	if d.status.Uptime != 0 {
+		t.Errorf("got %d, want 0", d.status.Uptime)
+	}
+}
@@ -0,0 +1,55 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/clock"
+)
+
+// toggle disables a feature temporarily at runtime, e.g., the trusted
+// network detection if its probes misbehave; a timeout enables it again
+type toggle struct {
+	clock    clock.Clock
+	timer    clock.Timer
+	disabled bool
+}
+
+// stopTimer stops the timeout timer
+func (t *toggle) stopTimer() {
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+}
+
+// disable disables the feature, it is enabled again after timeout if timeout is
+// not 0
+func (t *toggle) disable(timeout time.Duration) {
+	t.stopTimer()
+	t.disabled = true
+	if timeout > 0 {
+		t.timer = t.clock.NewTimer(timeout)
+	}
+}
+
+// enable enables the feature
+func (t *toggle) enable() {
+	t.stopTimer()
+	t.disabled = false
+}
+
+// timerC returns the channel of the timeout timer or nil if no timeout is
+// pending
+func (t *toggle) timerC() <-chan time.Time {
+	if t.timer == nil {
+		return nil
+	}
+	return t.timer.C()
+}
+
+// newToggle returns a new toggle with the feature enabled
+func newToggle() *toggle {
+	return &toggle{
+		clock: clock.New(),
+	}
+}
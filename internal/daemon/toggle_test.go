@@ -0,0 +1,127 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/clock"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+	"github.com/telekom-mms/oc-daemon/pkg/xmlprofile"
+)
+
+// TestToggle tests toggle
+func TestToggle(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	tt := newToggle()
+	tt.clock = fake
+
+	// disabled without timeout
+	tt.disable(0)
+	if !tt.disabled || tt.timerC() != nil {
+		t.Error("tnd should be disabled without timeout")
+	}
+
+	// disabled with timeout
+	tt.disable(time.Minute)
+	fake.Advance(59 * time.Second)
+	select {
+	case <-tt.timerC():
+		t.Error("timer should not expire")
+	default:
+	}
+	fake.Advance(time.Second)
+	<-tt.timerC()
+
+	// enabled
+	tt.enable()
+	if tt.disabled || tt.timerC() != nil {
+		t.Error("tnd should be enabled")
+	}
+}
+
+// TestDaemonSetTND tests setTND and handleTNDTimeout of Daemon
+func TestDaemonSetTND(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	d := &Daemon{
+		config:    NewConfig(),
+		dbus:      noDBusService{},
+		status:    vpnstatus.New(),
+		profile:   xmlprofile.NewProfile(),
+		tndToggle: newToggle(),
+	}
+	d.tndToggle.clock = fake
+
+	// enabled
+	d.setTND(true, 0)
+	if d.status.TNDState != vpnstatus.TNDStateEnabled {
+		t.Errorf("got %s, want enabled", d.status.TNDState)
+	}
+
+	// disabled with timeout
+	d.setTND(false, time.Minute)
+	if d.status.TNDState != vpnstatus.TNDStateDisabled {
+		t.Errorf("got %s, want disabled", d.status.TNDState)
+	}
+	fake.Advance(time.Minute)
+	<-d.tndToggle.timerC()
+	d.handleTNDTimeout()
+	if d.status.TNDState != vpnstatus.TNDStateEnabled {
+		t.Errorf("got %s, want enabled", d.status.TNDState)
+	}
+}
+
+// testTrafPol is a traffic policing for testing
+type testTrafPol struct {
+	stopped bool
+}
+
+// Start starts the traffic policing
+func (t *testTrafPol) Start(context.Context) error { return nil }
+
+// Stop stops the traffic policing
+func (t *testTrafPol) Stop() { t.stopped = true }
+
+// TestDaemonSetTrafPol tests setTrafPol and handleTrafPolTimeout of Daemon
+func TestDaemonSetTrafPol(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	tp := &testTrafPol{}
+	d := &Daemon{
+		config:        NewConfig(),
+		dbus:          noDBusService{},
+		status:        vpnstatus.New(),
+		profile:       xmlprofile.NewProfile(),
+		trafpol:       tp,
+		trafPolToggle: newToggle(),
+	}
+	d.trafPolToggle.clock = fake
+
+	// disabled with timeout, running traffic policing is stopped
+	if err := d.setTrafPol(false, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if !tp.stopped || d.trafpol != nil {
+		t.Error("traffic policing should be stopped")
+	}
+	if d.status.TrafPolState != vpnstatus.TrafPolStateDisabled {
+		t.Errorf("got %s, want disabled", d.status.TrafPolState)
+	}
+
+	// enabled after timeout, not active without always-on in profile
+	fake.Advance(time.Minute)
+	<-d.trafPolToggle.timerC()
+	d.handleTrafPolTimeout()
+	if d.status.TrafPolState != vpnstatus.TrafPolStateInactive {
+		t.Errorf("got %s, want inactive", d.status.TrafPolState)
+	}
+
+	// disabled and enabled again
+	_ = d.setTrafPol(false, 0)
+	if d.trafPolToggle.timerC() != nil {
+		t.Error("timer should not be set without timeout")
+	}
+	_ = d.setTrafPol(true, 0)
+	if d.status.TrafPolState != vpnstatus.TrafPolStateInactive {
+		t.Errorf("got %s, want inactive", d.status.TrafPolState)
+	}
+}
@@ -0,0 +1,486 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+	"github.com/telekom-mms/oc-daemon/internal/manifest"
+	"github.com/telekom-mms/oc-daemon/internal/ocrunner"
+	"github.com/telekom-mms/oc-daemon/pkg/logininfo"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+	"github.com/telekom-mms/oc-daemon/pkg/xmlprofile"
+)
+
+const (
+	// maxTunnels is the maximum number of additional tunnels
+	maxTunnels = 9
+
+	// tunnelTableBase and tunnelRulePrefBase are the bases of the routing
+	// table and the routing rule preference of additional tunnels, the
+	// index of the tunnel is added to them; the rules of additional
+	// tunnels are evaluated before the rules of split routing
+	tunnelTableBase    = 42111
+	tunnelRulePrefBase = 2100
+)
+
+// tunnelNameRegexp matches valid names of additional tunnels, the names are
+// used in D-Bus object paths
+var tunnelNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_]{1,32}$`)
+
+// validTunnels returns whether the names of the additional tunnels are valid
+func validTunnels(names []string) bool {
+	if len(names) > maxTunnels {
+		return false
+	}
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if !tunnelNameRegexp.MatchString(name) || seen[name] {
+			return false
+		}
+		seen[name] = true
+	}
+	return true
+}
+
+// tunnelDevice returns the device name of the additional tunnel with index,
+// it is the vpn device name with the index instead of its number suffix,
+// e.g., "oc-daemon-tun1"
+func tunnelDevice(index int) string {
+	return strings.TrimRight(vpnDevice, "0123456789") + strconv.Itoa(index)
+}
+
+// runTunnelCmd runs the routing command cmd of an additional tunnel
+var runTunnelCmd = func(cmd string) {
+	log.WithField("command", cmd).Debug("Daemon executing tunnel command")
+	c := exec.Command("bash", "-c", cmd)
+	if err := c.Run(); err != nil {
+		log.WithFields(logrus.Fields{
+			"command": cmd,
+			"error":   err,
+		}).Error("Daemon tunnel command execution error")
+	}
+}
+
+// tunnel is an additional VPN tunnel that is connected besides the main VPN
+// connection of the daemon, e.g., to a lab network. It only routes its split
+// include networks and only resolves its DNS domains, traffic policing, split
+// excludes, trusted network detection and reconnects only apply to the main
+// VPN connection
+type tunnel struct {
+	name   string
+	index  int
+	device string
+
+	runner *ocrunner.Connect
+	secret *connSecret
+	state  *stateMachine
+
+	// running indicates a running openconnect process
+	running bool
+
+	// config is the VPN configuration of the connected tunnel
+	config *vpnconfig.Config
+
+	// setProperty sets the D-Bus property of the tunnel with name to value
+	setProperty func(name string, value any)
+}
+
+// table returns the routing table of the tunnel
+func (t *tunnel) table() string {
+	return strconv.Itoa(tunnelTableBase + t.index)
+}
+
+// rulePref returns the routing rule preference of the tunnel
+func (t *tunnel) rulePref() string {
+	return strconv.Itoa(tunnelRulePrefBase + t.index)
+}
+
+// handleStateTransition handles a state transition of the tunnel
+func (t *tunnel) handleStateTransition(s *StateTransition) {
+	log.WithFields(logrus.Fields{
+		"tunnel": t.name,
+		"from":   s.From,
+		"to":     s.To,
+	}).Info("Daemon tunnel connection state changed")
+	t.setProperty(dbusapi.PropertyConnectionState, s.To)
+}
+
+// setRunning sets the openconnect running state of the tunnel
+func (t *tunnel) setRunning(running bool) {
+	if t.running == running {
+		return
+	}
+	t.running = running
+	ocrunning := vpnstatus.OCRunningNotRunning
+	if running {
+		ocrunning = vpnstatus.OCRunningRunning
+	}
+	t.setProperty(dbusapi.PropertyOCRunning, ocrunning)
+}
+
+// setConfig sets the VPN configuration of the tunnel and the properties
+// derived from it, config nil removes them
+func (t *tunnel) setConfig(config *vpnconfig.Config) {
+	t.config = config
+	if config == nil {
+		t.setProperty(dbusapi.PropertyVPNConfig, dbusapi.VPNConfigInvalid)
+		t.setProperty(dbusapi.PropertyIP, dbusapi.IPInvalid)
+		t.setProperty(dbusapi.PropertyDevice, dbusapi.DeviceInvalid)
+		t.setProperty(dbusapi.PropertyConnectedAt, dbusapi.ConnectedAtInvalid)
+		return
+	}
+
+	b, err := config.JSON()
+	if err != nil {
+		log.WithError(err).Error("Daemon could not convert tunnel config to JSON")
+	} else {
+		t.setProperty(dbusapi.PropertyVPNConfig, string(b))
+	}
+	ip := ""
+	for _, addr := range []net.IP{config.IPv4.Address, config.IPv6.Address} {
+		if addr != nil {
+			ip = addr.String()
+			break
+		}
+	}
+	t.setProperty(dbusapi.PropertyIP, ip)
+	t.setProperty(dbusapi.PropertyDevice, config.Device.Name)
+	t.setProperty(dbusapi.PropertyConnectedAt, time.Now().Unix())
+}
+
+// connect connects the tunnel with login info, proxy, xml profile and
+// address family policy
+func (t *tunnel) connect(login *logininfo.LoginInfo, proxy, profile string, family AddressFamily) error {
+	if t.running {
+		return fmt.Errorf("tunnel %s already running", t.name)
+	}
+	if !login.Valid() {
+		return errors.New("invalid login information")
+	}
+	if err := t.state.transition(vpnstatus.ConnectionStateConnecting); err != nil {
+		return err
+	}
+
+	// create new secret for this connection
+	if err := t.secret.rotate(); err != nil {
+		_ = t.state.transition(vpnstatus.ConnectionStateDisconnected)
+		return err
+	}
+
+	t.setRunning(true)
+	env := []string{
+		"oc_daemon_secret_file=" + t.secret.file(),
+		"oc_daemon_socket_file=" + sockFile,
+	}
+	t.runner.Connect(login, env, proxy, profile, "", family.Gateway,
+		family.DisableTunnelIPv6)
+	return nil
+}
+
+// disconnect disconnects the tunnel
+func (t *tunnel) disconnect() error {
+	if t.state.get() == vpnstatus.ConnectionStateDisconnected {
+		return nil
+	}
+	if err := t.state.transition(vpnstatus.ConnectionStateDisconnecting); err != nil {
+		return err
+	}
+	t.setRunning(false)
+	t.runner.Disconnect()
+	return nil
+}
+
+// setupRouting routes the split include networks and the DNS servers in
+// config over the tunnel device with a routing rule and a routing table of
+// the tunnel
+func (t *tunnel) setupRouting(config *vpnconfig.Config) {
+	routes := func(family string, nets []*net.IPNet, servers []net.IP) {
+		for _, n := range nets {
+			runTunnelCmd(fmt.Sprintf("ip %s route add %s dev %s table %s",
+				family, n, t.device, t.table()))
+		}
+		for _, s := range servers {
+			runTunnelCmd(fmt.Sprintf("ip %s route add %s dev %s table %s",
+				family, s, t.device, t.table()))
+		}
+		runTunnelCmd(fmt.Sprintf("ip %s rule add table %s pref %s",
+			family, t.table(), t.rulePref()))
+	}
+	manifest.Add(manifest.KindRouteTable, t.table())
+	manifest.Add(manifest.KindRule, t.rulePref())
+	routes("-4", config.Split.IncludeIPv4, config.DNS.ServersIPv4)
+	routes("-6", config.Split.IncludeIPv6, config.DNS.ServersIPv6)
+}
+
+// teardownRoutingCmds returns the commands that remove the routing of the
+// tunnel
+func (t *tunnel) teardownRoutingCmds() []string {
+	return []string{
+		fmt.Sprintf("ip -4 rule delete table %s pref %s", t.table(), t.rulePref()),
+		fmt.Sprintf("ip -6 rule delete table %s pref %s", t.table(), t.rulePref()),
+		fmt.Sprintf("ip -4 route flush table %s", t.table()),
+		fmt.Sprintf("ip -6 route flush table %s", t.table()),
+	}
+}
+
+// teardownRouting removes the routing of the tunnel
+func (t *tunnel) teardownRouting() {
+	for _, cmd := range t.teardownRoutingCmds() {
+		runTunnelCmd(cmd)
+	}
+	manifest.Remove(manifest.KindRule, t.rulePref())
+	manifest.Remove(manifest.KindRouteTable, t.table())
+}
+
+// setupDNS configures the DNS servers in config directly on the tunnel
+// device in systemd-resolved, only for the default domain, the split domains
+// and the reverse zones of the split include networks
+func (t *tunnel) setupDNS(config *vpnconfig.Config) {
+	servers := []string{}
+	for _, s := range config.DNS.ServersIPv4 {
+		servers = append(servers, s.String())
+	}
+	for _, s := range config.DNS.ServersIPv6 {
+		servers = append(servers, s.String())
+	}
+	if len(servers) == 0 {
+		return
+	}
+
+	domains := []string{}
+	if config.DNS.DefaultDomain != "" {
+		domains = append(domains, config.DNS.DefaultDomain)
+	}
+	for _, d := range config.DNS.SplitDomains {
+		domains = append(domains, "~"+d)
+	}
+	for _, z := range config.Split.ReverseZones() {
+		domains = append(domains, "~"+strings.TrimSuffix(z, "."))
+	}
+
+	manifest.Add(manifest.KindResolved, t.device)
+	runResolvectl(fmt.Sprintf("dns %s %s", t.device, strings.Join(servers, " ")))
+	if len(domains) > 0 {
+		runResolvectl(fmt.Sprintf("domain %s %s", t.device,
+			strings.Join(domains, " ")))
+	}
+	runResolvectl(fmt.Sprintf("default-route %s no", t.device))
+	runResolvectl("flush-caches")
+}
+
+// configUp sets up the tunnel with config after connect
+func (t *tunnel) configUp(config *vpnconfig.Config) error {
+	if !t.running {
+		return fmt.Errorf("tunnel %s not running", t.name)
+	}
+	if state := t.state.get(); state != vpnstatus.ConnectionStateConnecting {
+		return &StateError{From: state, To: vpnstatus.ConnectionStateConnected}
+	}
+	if config.Device.Name != t.device {
+		return fmt.Errorf("invalid device %s for tunnel %s",
+			config.Device.Name, t.name)
+	}
+
+	log.WithField("tunnel", t.name).Info("Daemon setting up tunnel configuration")
+	setupVPNDevice(config)
+	t.setupRouting(config)
+	t.setupDNS(config)
+	t.setConfig(config)
+	return t.state.transition(vpnstatus.ConnectionStateConnected)
+}
+
+// configDown tears down the tunnel configuration after disconnect
+func (t *tunnel) configDown() error {
+	if t.running {
+		return fmt.Errorf("tunnel %s still running", t.name)
+	}
+	if state := t.state.get(); state == vpnstatus.ConnectionStateConnected {
+		return &StateError{From: state, To: vpnstatus.ConnectionStateDisconnected}
+	}
+
+	if t.config != nil {
+		log.WithField("tunnel", t.name).Info("Daemon tearing down tunnel configuration")
+		teardownVPNDevice(t.config)
+		t.teardownRouting()
+		unsetVPNDNS(t.config)
+	}
+	t.setConfig(nil)
+	return t.state.transition(vpnstatus.ConnectionStateDisconnected)
+}
+
+// handleRunnerDisconnect cleans up after the openconnect process of the
+// tunnel terminated
+func (t *tunnel) handleRunnerDisconnect() {
+	t.setRunning(false)
+	if err := t.state.transition(vpnstatus.ConnectionStateDisconnected); err != nil {
+		log.WithError(err).WithField("tunnel", t.name).
+			Error("Daemon tunnel runner disconnect error")
+	}
+	if err := t.configDown(); err != nil {
+		log.WithError(err).WithField("tunnel", t.name).
+			Error("Daemon tunnel config down error")
+	}
+	t.secret.invalidate()
+}
+
+// handleRunnerEvent handles a connect event from the runner of the tunnel
+func (t *tunnel) handleRunnerEvent(e *ocrunner.ConnectEvent) {
+	log.WithFields(logrus.Fields{
+		"tunnel": t.name,
+		"event":  e,
+	}).Debug("Daemon handling tunnel Runner event")
+
+	if e.Connect {
+		t.setRunning(true)
+		return
+	}
+	if e.Err != nil {
+		log.WithError(e.Err).WithField("tunnel", t.name).
+			Error("Daemon could not start tunnel connection")
+	}
+	t.handleRunnerDisconnect()
+}
+
+// start starts the tunnel, it forwards the runner events of the tunnel to
+// events until done is closed
+func (t *tunnel) start(ctx context.Context, events chan<- *tunnelEvent, done <-chan struct{}) error {
+	if err := t.state.transition(vpnstatus.ConnectionStateDisconnected); err != nil {
+		return err
+	}
+	if err := t.runner.Start(ctx); err != nil {
+		return err
+	}
+	go func() {
+		for e := range t.runner.Events() {
+			select {
+			case events <- &tunnelEvent{tunnel: t, event: e}:
+			case <-done:
+			}
+		}
+	}()
+	return nil
+}
+
+// stop stops the tunnel and cleans up its configuration
+func (t *tunnel) stop() {
+	t.runner.Stop()
+	t.handleRunnerDisconnect()
+}
+
+// cleanup cleans up the tunnel after a failed shutdown without manifest
+func (t *tunnel) cleanup() {
+	cleanupVPNConfig(t.device)
+	for _, cmd := range t.teardownRoutingCmds() {
+		runCleanupCmd(cmd)
+	}
+}
+
+// pidFile returns the openconnect pid file of the tunnel
+func (t *tunnel) pidFile() string {
+	return filepath.Join(filepath.Dir(ocrunner.PIDFile),
+		fmt.Sprintf("openconnect-%s.pid", t.name))
+}
+
+// getTunnel returns the additional tunnel with name, nil if it does not exist
+func (d *Daemon) getTunnel(name string) *tunnel {
+	for _, t := range d.tunnels {
+		if t.name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// getTunnelBySecret returns the additional tunnel whose connection secret
+// matches the response to challenge, nil if there is no such tunnel
+func (d *Daemon) getTunnelBySecret(challenge, response []byte) *tunnel {
+	for _, t := range d.tunnels {
+		if t.secret.check(challenge, response) {
+			return t
+		}
+	}
+	return nil
+}
+
+// connectTunnel connects the additional tunnel with name using login info
+// and the xml profile with name profile
+func (d *Daemon) connectTunnel(name string, login *logininfo.LoginInfo, profile string) error {
+	t := d.getTunnel(name)
+	if t == nil {
+		return fmt.Errorf("unknown tunnel %s", name)
+	}
+	if d.status.Connectivity.Offline() {
+		return dbusapi.ErrOffline
+	}
+	if profile != "" {
+		if !xmlprofile.ValidProfileName(profile) {
+			return fmt.Errorf("invalid profile name: %s", profile)
+		}
+		if _, err := os.Stat(profilePath(profile)); err != nil {
+			return fmt.Errorf("profile %s not found", profile)
+		}
+	}
+
+	// use detected proxy?
+	proxy := ""
+	if d.config.AutoProxy {
+		proxy = d.status.Proxy
+	}
+	return t.connect(login, proxy, profilePath(profile), d.config.AddressFamily)
+}
+
+// disconnectTunnel disconnects the additional tunnel with name
+func (d *Daemon) disconnectTunnel(name string) error {
+	t := d.getTunnel(name)
+	if t == nil {
+		return fmt.Errorf("unknown tunnel %s", name)
+	}
+	return t.disconnect()
+}
+
+// updateTunnelConfig updates the configuration of the additional tunnel t
+// with the config update from its vpnc-script
+func (d *Daemon) updateTunnelConfig(t *tunnel, update *VPNConfigUpdate) error {
+	switch update.Reason {
+	case "disconnect":
+		return t.configDown()
+	case "attempt-reconnect":
+		// tunnels do not exclude their gateways from the main vpn
+		return nil
+	}
+	return t.configUp(update.Config)
+}
+
+// tunnelEvent is a runner event of a tunnel
+type tunnelEvent struct {
+	tunnel *tunnel
+	event  *ocrunner.ConnectEvent
+}
+
+// newTunnel returns a new additional tunnel with name and index that sets
+// its D-Bus properties with setProperty
+func newTunnel(name string, index int, setProperty func(name string, value any)) *tunnel {
+	t := &tunnel{
+		name:        name,
+		index:       index,
+		device:      tunnelDevice(index),
+		secret:      newConnSecret("vpncscript-" + name + ".secret"),
+		setProperty: setProperty,
+	}
+	t.runner = ocrunner.NewConnect(xmlProfile, vpncScript, t.device)
+	t.runner.SetPIDFile(t.pidFile())
+	t.state = newStateMachine(t.handleStateTransition)
+	return t
+}
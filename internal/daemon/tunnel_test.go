@@ -0,0 +1,186 @@
+package daemon
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+	"github.com/telekom-mms/oc-daemon/internal/ocrunner"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+	"github.com/vishvananda/netlink"
+)
+
+// TestTunnelDevice tests tunnelDevice
+func TestTunnelDevice(t *testing.T) {
+	old := vpnDevice
+	defer func() { vpnDevice = old }()
+
+	for device, want := range map[string]string{
+		"oc-daemon-tun0": "oc-daemon-tun3",
+		"tun":            "tun3",
+	} {
+		vpnDevice = device
+		if got := tunnelDevice(3); got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+}
+
+// newTestTunnel returns a new tunnel for testing that records its commands
+// and properties
+func newTestTunnel(cmds *[]string, props map[string]any) *tunnel {
+	runTunnelCmd = func(cmd string) {
+		*cmds = append(*cmds, cmd)
+	}
+	runResolvectl = func(cmd string) {
+		*cmds = append(*cmds, "resolvectl "+cmd)
+	}
+	runLinkByName = func(string) (netlink.Link, error) {
+		return &netlink.Device{}, nil
+	}
+	runLinkSetMTU = func(netlink.Link, int) error { return nil }
+	runLinkSetUp = func(netlink.Link) error { return nil }
+	runLinkSetDown = func(netlink.Link) error { return nil }
+	runAddrAdd = func(netlink.Link, *netlink.Addr) error { return nil }
+
+	return newTunnel("lab", 1, func(name string, value any) {
+		props[name] = value
+	})
+}
+
+// TestTunnelConfigUpDown tests configUp and configDown of tunnel
+func TestTunnelConfigUpDown(t *testing.T) {
+	cmds := []string{}
+	props := make(map[string]any)
+	tun := newTestTunnel(&cmds, props)
+
+	config := vpnconfig.New()
+	config.Device.Name = tun.device
+	config.IPv4.Address = net.ParseIP("10.0.0.2")
+	config.IPv4.Netmask = net.CIDRMask(24, 32)
+	config.DNS.ServersIPv4 = []net.IP{net.ParseIP("10.0.1.1")}
+	config.DNS.DefaultDomain = "lab.example.com"
+	_, include, _ := net.ParseCIDR("10.1.0.0/16")
+	config.Split.IncludeIPv4 = []*net.IPNet{include}
+
+	// not running
+	if err := tun.configUp(config); err == nil {
+		t.Error("config up should fail when not running")
+	}
+
+	// connecting
+	_ = tun.state.transition(vpnstatus.ConnectionStateDisconnected)
+	_ = tun.state.transition(vpnstatus.ConnectionStateConnecting)
+	tun.setRunning(true)
+
+	// invalid device
+	config.Device.Name = "other"
+	if err := tun.configUp(config); err == nil {
+		t.Error("config up should fail with invalid device")
+	}
+	config.Device.Name = tun.device
+
+	// valid config
+	if err := tun.configUp(config); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"ip -4 route add 10.1.0.0/16 dev oc-daemon-tun1 table 42112",
+		"ip -4 route add 10.0.1.1 dev oc-daemon-tun1 table 42112",
+		"ip -4 rule add table 42112 pref 2101",
+		"ip -6 rule add table 42112 pref 2101",
+		"resolvectl dns oc-daemon-tun1 10.0.1.1",
+		"resolvectl domain oc-daemon-tun1 lab.example.com ~1.10.in-addr.arpa",
+		"resolvectl default-route oc-daemon-tun1 no",
+		"resolvectl flush-caches",
+	}
+	if !reflect.DeepEqual(cmds, want) {
+		t.Errorf("got %v, want %v", cmds, want)
+	}
+	if props[dbusapi.PropertyConnectionState] != vpnstatus.ConnectionStateConnected ||
+		props[dbusapi.PropertyIP] != "10.0.0.2" ||
+		props[dbusapi.PropertyDevice] != tun.device {
+		t.Errorf("got %v, want connected properties", props)
+	}
+
+	// still running
+	if err := tun.configDown(); err == nil {
+		t.Error("config down should fail when running")
+	}
+
+	// disconnect
+	cmds = cmds[:0]
+	tun.handleRunnerDisconnect()
+	want = []string{
+		"ip -4 rule delete table 42112 pref 2101",
+		"ip -6 rule delete table 42112 pref 2101",
+		"ip -4 route flush table 42112",
+		"ip -6 route flush table 42112",
+		"resolvectl revert oc-daemon-tun1",
+		"resolvectl flush-caches",
+		"resolvectl reset-server-features",
+	}
+	if !reflect.DeepEqual(cmds, want) {
+		t.Errorf("got %v, want %v", cmds, want)
+	}
+	if tun.state.get() != vpnstatus.ConnectionStateDisconnected ||
+		props[dbusapi.PropertyOCRunning] != vpnstatus.OCRunningNotRunning ||
+		props[dbusapi.PropertyIP] != dbusapi.IPInvalid {
+		t.Errorf("got %v, want disconnected properties", props)
+	}
+}
+
+// TestDaemonTunnels tests the additional tunnels of Daemon
+func TestDaemonTunnels(t *testing.T) {
+	cmds := []string{}
+	props := make(map[string]any)
+	d := &Daemon{
+		tunnels: []*tunnel{
+			newTestTunnel(&cmds, props),
+			newTunnel("test", 2, func(string, any) {}),
+		},
+	}
+
+	// tunnels
+	lab := d.getTunnel("lab")
+	if lab == nil || lab.index != 1 || lab.table() != "42112" ||
+		lab.rulePref() != "2101" {
+		t.Errorf("got %v, want tunnel lab", lab)
+	}
+	if lab.pidFile() == ocrunner.PIDFile {
+		t.Errorf("got %s, want tunnel pid file", lab.pidFile())
+	}
+	if d.getTunnel("unknown") != nil {
+		t.Error("unknown tunnel should not exist")
+	}
+
+	// secrets
+	setTestSecretDir(t)
+	if err := lab.secret.rotate(); err != nil {
+		t.Fatal(err)
+	}
+	challenge := []byte("challenge")
+	response := testSecretResponse(t, lab.secret, challenge)
+	if got := d.getTunnelBySecret(challenge, response); got != lab {
+		t.Errorf("got %v, want %v", got, lab)
+	}
+	if got := d.getTunnelBySecret(challenge, []byte("invalid")); got != nil {
+		t.Errorf("got %v, want nil", got)
+	}
+
+	// unknown tunnels
+	if err := d.connectTunnel("unknown", nil, ""); err == nil {
+		t.Error("connect of unknown tunnel should fail")
+	}
+	if err := d.disconnectTunnel("unknown"); err == nil {
+		t.Error("disconnect of unknown tunnel should fail")
+	}
+
+	// disconnect of disconnected tunnel
+	_ = lab.state.transition(vpnstatus.ConnectionStateDisconnected)
+	if err := d.disconnectTunnel("lab"); err != nil {
+		t.Error(err)
+	}
+}
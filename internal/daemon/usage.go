@@ -0,0 +1,337 @@
+package daemon
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/clock"
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+	"github.com/telekom-mms/oc-daemon/internal/eventbus"
+)
+
+var (
+	// usageDir is the directory with the VPN usage statistics per month,
+	// they are kept across restarts of the daemon
+	usageDir = configDir + "/stats"
+)
+
+const (
+	// usageMonthFormat is the time format of months in usage statistics
+	// and their file names, e.g., "2026-10"
+	usageMonthFormat = "2006-01"
+
+	// usageMonths is the number of months kept in usageDir
+	usageMonths = 12
+
+	// usageSaveInterval is the interval of saving the usage statistics
+	// while connected, so a crash of the daemon loses at most this interval
+	usageSaveInterval = 5 * time.Minute
+)
+
+// usageMonth is the cumulative VPN usage in a month
+type usageMonth struct {
+	Month            string
+	RXBytes          uint64
+	TXBytes          uint64
+	Connections      uint64
+	ConnectedSeconds uint64
+}
+
+// usageFile returns the file of the usage statistics of month
+func usageFile(month string) string {
+	return filepath.Join(usageDir, month+".json")
+}
+
+// loadUsageMonth loads the usage statistics of month, they are empty if the
+// file does not exist or is invalid
+func loadUsageMonth(month string) *usageMonth {
+	u := &usageMonth{}
+	b, err := os.ReadFile(usageFile(month))
+	if err == nil {
+		if err := json.Unmarshal(b, u); err != nil {
+			log.WithError(err).WithField("month", month).
+				Error("Daemon could not parse usage statistics")
+			u = &usageMonth{}
+		}
+	}
+	u.Month = month
+	return u
+}
+
+// saveUsageMonth saves the usage statistics of a month, the file is
+// replaced atomically, so it is never written partially
+func saveUsageMonth(u *usageMonth) error {
+	if err := os.MkdirAll(usageDir, 0700); err != nil {
+		return err
+	}
+	b, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	file := usageFile(u.Month)
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// usageMonthFiles returns the months of the usage statistics files in
+// usageDir in ascending order
+func usageMonthFiles() []string {
+	entries, err := os.ReadDir(usageDir)
+	if err != nil {
+		return nil
+	}
+	months := []string{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		month := strings.TrimSuffix(e.Name(), ".json")
+		if _, err := time.Parse(usageMonthFormat, month); err != nil {
+			continue
+		}
+		months = append(months, month)
+	}
+	sort.Strings(months)
+	return months
+}
+
+// pruneUsageMonths removes the usage statistics files of all but the last
+// usageMonths months
+func pruneUsageMonths() {
+	months := usageMonthFiles()
+	for len(months) > usageMonths {
+		if err := os.Remove(usageFile(months[0])); err != nil {
+			log.WithError(err).WithField("month", months[0]).
+				Error("Daemon could not remove usage statistics")
+		}
+		months = months[1:]
+	}
+}
+
+// usageTracker accumulates the traffic and connection counters of VPN
+// connections per month and persists them in usageDir, it consumes the
+// stats events of the event bus in its own goroutine, so the main loop does
+// not wait for saving the usage statistics
+type usageTracker struct {
+	events *eventbus.Bus
+	sub    *eventbus.Subscription
+	done   chan struct{}
+
+	// mutex protects the usage below, it is updated by the consumer
+	// goroutine and listed by the main loop
+	mutex sync.Mutex
+	clock clock.Clock
+
+	// current is the usage of the current month, loaded on first use
+	current *usageMonth
+
+	// last are the traffic statistics of the vpn device at the last
+	// update and lastTime is the time of the last update, last is nil
+	// if not connected
+	last     *trafficStats
+	lastTime time.Time
+
+	// saved is the time the current month was saved
+	saved time.Time
+}
+
+// month returns the usage of the current month, on a new month it saves the
+// usage of the previous month and removes the oldest months
+func (u *usageTracker) month(now time.Time) *usageMonth {
+	month := now.Local().Format(usageMonthFormat)
+	if u.current != nil && u.current.Month == month {
+		return u.current
+	}
+	if u.current == nil {
+		u.current = loadUsageMonth(month)
+		return u.current
+	}
+	log.WithField("month", month).Info("Daemon starting new usage statistics month")
+	u.save()
+	u.current = loadUsageMonth(month)
+	u.save()
+	pruneUsageMonths()
+	return u.current
+}
+
+// save saves the usage of the current month
+func (u *usageTracker) save() {
+	if u.current == nil {
+		return
+	}
+	if err := saveUsageMonth(u.current); err != nil {
+		log.WithError(err).Error("Daemon could not save usage statistics")
+		return
+	}
+	u.saved = u.clock.Now()
+}
+
+// add adds the traffic and connected time since the last update to the
+// current month
+func (u *usageTracker) add(now time.Time, stats *trafficStats) {
+	m := u.month(now)
+	if stats != nil {
+		// counters of a new vpn device start at zero again
+		rx, tx := stats.RXBytes, stats.TXBytes
+		if rx >= u.last.RXBytes && tx >= u.last.TXBytes {
+			rx -= u.last.RXBytes
+			tx -= u.last.TXBytes
+		}
+		m.RXBytes += rx
+		m.TXBytes += tx
+		u.last = stats
+	}
+	if elapsed := now.Sub(u.lastTime).Truncate(time.Second); elapsed > 0 {
+		m.ConnectedSeconds += uint64(elapsed / time.Second)
+		u.lastTime = u.lastTime.Add(elapsed)
+	}
+}
+
+// connect starts tracking a new VPN connection, stats are the initial
+// traffic statistics of the vpn device or nil if unknown
+func (u *usageTracker) connect(stats *trafficStats) {
+	if u == nil {
+		return
+	}
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if stats == nil {
+		stats = &trafficStats{}
+	}
+	now := u.clock.Now()
+	u.month(now).Connections++
+	u.last = stats
+	u.lastTime = now
+	u.save()
+}
+
+// update updates the usage of the current VPN connection with the traffic
+// statistics of the vpn device, stats is nil if they are unknown
+func (u *usageTracker) update(stats *trafficStats) {
+	if u == nil {
+		return
+	}
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if u.last == nil {
+		return
+	}
+	now := u.clock.Now()
+	u.add(now, stats)
+	if now.Sub(u.saved) >= usageSaveInterval {
+		u.save()
+	}
+}
+
+// disconnect stops tracking the current VPN connection and saves the usage
+func (u *usageTracker) disconnect() {
+	if u == nil {
+		return
+	}
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if u.last == nil {
+		return
+	}
+	u.add(u.clock.Now(), nil)
+	u.last = nil
+	u.save()
+}
+
+// list returns the usage of the kept months in ascending order including the
+// current month
+func (u *usageTracker) list() []dbusapi.Usage {
+	list := []dbusapi.Usage{}
+	if u == nil {
+		return list
+	}
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	current := u.month(u.clock.Now())
+	months := usageMonthFiles()
+	if i := sort.SearchStrings(months, current.Month); i == len(months) ||
+		months[i] != current.Month {
+		months = append(months, current.Month)
+		sort.Strings(months)
+	}
+	for _, month := range months {
+		m := current
+		if month != current.Month {
+			m = loadUsageMonth(month)
+		}
+		list = append(list, dbusapi.Usage{
+			Month:            m.Month,
+			RXBytes:          m.RXBytes,
+			TXBytes:          m.TXBytes,
+			Connections:      m.Connections,
+			ConnectedSeconds: m.ConnectedSeconds,
+		})
+	}
+	return list
+}
+
+// usageStats returns the traffic statistics of the stats event s as usage
+// statistics, nil if they are unknown
+func usageStats(s *eventbus.TrafficStats) *trafficStats {
+	if s == nil {
+		return nil
+	}
+	return &trafficStats{RXBytes: s.RXBytes, TXBytes: s.TXBytes}
+}
+
+// run tracks the usage with the stats events of sub until the subscription
+// is canceled
+func (u *usageTracker) run(sub *eventbus.Subscription, done chan struct{}) {
+	defer close(done)
+	for e := range sub.Events() {
+		switch e := e.(type) {
+		case *eventbus.TrafficStatsStarted:
+			u.connect(usageStats(e.Stats))
+		case *eventbus.TrafficStatsUpdated:
+			u.update(usageStats(e.Stats))
+		case *eventbus.TrafficStatsStopped:
+			u.disconnect()
+		}
+	}
+}
+
+// start starts consuming the stats events of the event bus
+func (u *usageTracker) start() {
+	if u.sub != nil {
+		return
+	}
+	u.sub = u.events.Subscribe("usage tracker", 32, eventbus.TopicStats)
+	u.done = make(chan struct{})
+	go u.run(u.sub, u.done)
+}
+
+// stop stops consuming events, it waits until the pending events are
+// handled and the usage is saved
+func (u *usageTracker) stop() {
+	if u.sub == nil {
+		return
+	}
+	u.events.Unsubscribe(u.sub)
+	<-u.done
+	u.sub = nil
+}
+
+// newUsageTracker returns a new usage tracker that consumes events
+func newUsageTracker(events *eventbus.Bus) *usageTracker {
+	return &usageTracker{
+		events: events,
+		clock:  clock.New(),
+	}
+}
@@ -0,0 +1,148 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/clock"
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+	"github.com/telekom-mms/oc-daemon/internal/eventbus"
+)
+
+// setTestUsageDir sets usageDir to a temporary directory in test t
+func setTestUsageDir(t *testing.T) {
+	old := usageDir
+	t.Cleanup(func() { usageDir = old })
+	usageDir = t.TempDir()
+}
+
+// TestUsageTracker tests connect, update and disconnect of usageTracker
+func TestUsageTracker(t *testing.T) {
+	setTestUsageDir(t)
+	start := time.Date(2026, time.October, 17, 12, 0, 0, 0, time.Local)
+	c := clock.NewFake(start)
+	u := &usageTracker{clock: c}
+
+	// not connected
+	u.update(&trafficStats{RXBytes: 100})
+	u.disconnect()
+
+	// first connection
+	u.connect(&trafficStats{RXBytes: 10, TXBytes: 20})
+	c.Advance(time.Minute)
+	u.update(&trafficStats{RXBytes: 110, TXBytes: 70})
+	c.Advance(30 * time.Second)
+	u.update(nil)
+	c.Advance(30 * time.Second)
+	u.disconnect()
+
+	// second connection with new vpn device
+	u.connect(nil)
+	c.Advance(time.Minute)
+	u.update(&trafficStats{RXBytes: 5, TXBytes: 5})
+	u.disconnect()
+
+	want := &usageMonth{
+		Month:            "2026-10",
+		RXBytes:          105,
+		TXBytes:          55,
+		Connections:      2,
+		ConnectedSeconds: 180,
+	}
+	if !reflect.DeepEqual(u.current, want) {
+		t.Errorf("got %v, want %v", u.current, want)
+	}
+
+	// persisted across restarts
+	if got := loadUsageMonth("2026-10"); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// nil tracker
+	var nu *usageTracker
+	nu.connect(nil)
+	nu.update(nil)
+	nu.disconnect()
+	if got := nu.list(); len(got) != 0 {
+		t.Errorf("got %v, want empty list", got)
+	}
+}
+
+// TestUsageTrackerEvents tests start, stop and the stats events of
+// usageTracker
+func TestUsageTrackerEvents(t *testing.T) {
+	setTestUsageDir(t)
+	events := eventbus.New()
+	defer events.Close()
+	c := clock.NewFake(time.Date(2026, time.October, 17, 12, 0, 0, 0, time.Local))
+	u := newUsageTracker(events)
+	u.clock = c
+
+	u.start()
+	u.start()
+	events.Publish(&eventbus.TrafficStatsStarted{
+		Stats: &eventbus.TrafficStats{RXBytes: 10, TXBytes: 20},
+	})
+	events.Publish(&eventbus.TrafficStatsUpdated{
+		Stats: &eventbus.TrafficStats{RXBytes: 30, TXBytes: 50},
+	})
+	events.Publish(&eventbus.TrafficStatsUpdated{})
+	events.Publish(&eventbus.TrafficStatsStopped{})
+	u.stop()
+	u.stop()
+
+	want := []dbusapi.Usage{
+		{Month: "2026-10", RXBytes: 20, TXBytes: 30, Connections: 1},
+	}
+	if got := u.list(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestUsageTrackerRollover tests the month rollover of usageTracker
+func TestUsageTrackerRollover(t *testing.T) {
+	setTestUsageDir(t)
+
+	// create old months
+	for i := 1; i <= usageMonths; i++ {
+		if err := saveUsageMonth(&usageMonth{
+			Month:       fmt.Sprintf("2025-%02d", i),
+			Connections: 1,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(usageFile("invalid"), []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// connect at the end of the month and disconnect in the next month
+	c := clock.NewFake(time.Date(2026, time.January, 31, 23, 59, 0, 0, time.Local))
+	u := &usageTracker{clock: c}
+	u.connect(&trafficStats{})
+	c.Advance(2 * time.Minute)
+	u.update(&trafficStats{RXBytes: 10})
+	u.disconnect()
+
+	got := u.list()
+	if len(got) != usageMonths {
+		t.Fatalf("got %d months, want %d", len(got), usageMonths)
+	}
+	if got[0].Month != "2025-03" {
+		t.Errorf("got first month %s, want 2025-03", got[0].Month)
+	}
+	for i, want := range []dbusapi.Usage{
+		{Month: "2026-01", Connections: 1},
+		{Month: "2026-02", RXBytes: 10, ConnectedSeconds: 120},
+	} {
+		if g := got[len(got)-2+i]; g != want {
+			t.Errorf("got %v, want %v", g, want)
+		}
+	}
+	if _, err := os.Stat(usageFile("invalid")); err != nil {
+		t.Errorf("other files should not be removed: %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package daemon
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+	"github.com/telekom-mms/oc-daemon/pkg/xmlprofile"
+)
+
+const (
+	// restartMessage is the message of findings about settings that
+	// require a restart of the daemon
+	restartMessage = "changes require a restart of the daemon"
+
+	// dnsProxyMessage is the message of findings about settings that
+	// require the DNS-Proxy
+	dnsProxyMessage = "the DNS-Proxy is disabled at build time, the setting has no effect"
+)
+
+// findingError returns an error finding of field with msg
+func findingError(field, msg string) dbusapi.Finding {
+	return dbusapi.Finding{
+		Severity: dbusapi.SeverityError,
+		Field:    field,
+		Message:  msg,
+	}
+}
+
+// findingWarning returns a warning finding of field with msg
+func findingWarning(field, msg string) dbusapi.Finding {
+	return dbusapi.Finding{
+		Severity: dbusapi.SeverityWarning,
+		Field:    field,
+		Message:  msg,
+	}
+}
+
+// validateConfig validates the candidate daemon configuration in b against
+// the running configuration and the features of the daemon
+func (d *Daemon) validateConfig(b []byte) []dbusapi.Finding {
+	findings := []dbusapi.Finding{}
+
+	// parse config like LoadConfig, report unknown settings, e.g., typos
+	// or settings of newer daemon versions
+	c := NewConfig()
+	if err := json.Unmarshal(b, c); err != nil {
+		return append(findings, findingError("", err.Error()))
+	}
+	strict := json.NewDecoder(bytes.NewReader(b))
+	strict.DisallowUnknownFields()
+	if err := strict.Decode(NewConfig()); err != nil {
+		findings = append(findings, findingWarning("", err.Error()))
+	}
+
+	// check settings
+	for _, field := range c.invalidFields() {
+		findings = append(findings, findingError(field, "invalid setting"))
+	}
+
+	// check settings that are only applied on restart
+	if c.DBusMode != d.config.DBusMode {
+		findings = append(findings, findingWarning("DBusMode", restartMessage))
+	}
+	if !reflect.DeepEqual(c.PrivilegeSeparation, d.config.PrivilegeSeparation) {
+		findings = append(findings,
+			findingWarning("PrivilegeSeparation", restartMessage))
+	}
+	if c.SocketGroup != d.config.SocketGroup {
+		findings = append(findings, findingWarning("SocketGroup", restartMessage))
+	}
+	if c.GRPCAPI != d.config.GRPCAPI {
+		findings = append(findings, findingWarning("GRPCAPI", restartMessage))
+	}
+	if !reflect.DeepEqual(c.Tunnels, d.config.Tunnels) {
+		findings = append(findings, findingWarning("Tunnels", restartMessage))
+	}
+
+	// check settings of features disabled at build time
+	if !featureDNSProxy {
+		if len(c.DNSTransports) > 0 {
+			findings = append(findings,
+				findingWarning("DNSTransports", dnsProxyMessage))
+		}
+		if c.DNSProxy != NewConfig().DNSProxy {
+			findings = append(findings,
+				findingWarning("DNSProxy", dnsProxyMessage))
+		}
+	}
+
+	return findings
+}
+
+// validateProfile validates the candidate XML profile in b against the
+// features of the daemon
+func (d *Daemon) validateProfile(b []byte) []dbusapi.Finding {
+	findings := []dbusapi.Finding{}
+
+	// parse profile like LoadProfile
+	p := xmlprofile.NewProfile()
+	if err := xml.Unmarshal(b, p); err != nil {
+		return append(findings, findingError("", err.Error()))
+	}
+
+	// check vpn servers
+	if len(p.GetVPNServerHostEntries()) == 0 {
+		findings = append(findings,
+			findingWarning("ServerList", "no VPN servers"))
+	}
+	for _, h := range p.GetVPNServerHostEntries() {
+		if h.HostAddress == "" {
+			findings = append(findings, findingError("ServerList",
+				fmt.Sprintf("VPN server %s without address", h.HostName)))
+		}
+	}
+
+	// check tnd servers
+	for _, s := range p.AutomaticVPNPolicy.TrustedHTTPSServerList {
+		if s.Address == "" || s.CertificateHash == "" {
+			findings = append(findings, findingError("TrustedHttpsServerList",
+				"trusted HTTPS server without address or certificate hash"))
+		}
+	}
+
+	// check always on, it requires traffic policing
+	if p.GetAlwaysOn() && (!featureTrafPol || noTrafPol) {
+		findings = append(findings, findingWarning("AlwaysOn",
+			"traffic policing is disabled, always on has no effect"))
+	}
+
+	return findings
+}
+
+// validate validates the candidate configuration of kind in b without
+// applying it and returns the findings
+func (d *Daemon) validate(kind string, b []byte) ([]dbusapi.Finding, error) {
+	switch kind {
+	case dbusapi.ValidateKindConfig:
+		return d.validateConfig(b), nil
+	case dbusapi.ValidateKindProfile:
+		return d.validateProfile(b), nil
+	}
+	return nil, fmt.Errorf("invalid kind: %s", kind)
+}
@@ -0,0 +1,89 @@
+package daemon
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+)
+
+// TestDaemonValidateConfig tests validateConfig of Daemon
+func TestDaemonValidateConfig(t *testing.T) {
+	d := &Daemon{config: NewConfig()}
+
+	// valid config
+	if got := d.validateConfig([]byte(`{"LogLevel":"debug"}`)); len(got) != 0 {
+		t.Errorf("got %v, want no findings", got)
+	}
+
+	// invalid json
+	got := d.validateConfig([]byte(`{"LogLevel":`))
+	if len(got) != 1 || got[0].Severity != dbusapi.SeverityError {
+		t.Errorf("got %v, want error", got)
+	}
+
+	// unknown setting, invalid settings and restart
+	got = d.validateConfig([]byte(`{"LogLeve":"debug","LogFormat":"xml",` +
+		`"StatsInterval":-1,"Tunnels":["lab"]}`))
+	want := []dbusapi.Finding{
+		findingWarning("", `json: unknown field "LogLeve"`),
+		findingError("LogFormat", "invalid setting"),
+		findingError("StatsInterval", "invalid setting"),
+		findingWarning("Tunnels", restartMessage),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestDaemonValidateProfile tests validateProfile of Daemon
+func TestDaemonValidateProfile(t *testing.T) {
+	d := &Daemon{config: NewConfig()}
+
+	// valid profile
+	valid := `<AnyConnectProfile><ServerList><HostEntry>` +
+		`<HostName>VPN</HostName><HostAddress>vpn.example.com</HostAddress>` +
+		`</HostEntry></ServerList></AnyConnectProfile>`
+	if got := d.validateProfile([]byte(valid)); len(got) != 0 {
+		t.Errorf("got %v, want no findings", got)
+	}
+
+	// invalid xml
+	got := d.validateProfile([]byte(`<AnyConnectProfile>`))
+	if len(got) != 1 || got[0].Severity != dbusapi.SeverityError {
+		t.Errorf("got %v, want error", got)
+	}
+
+	// missing servers and invalid tnd server
+	got = d.validateProfile([]byte(`<AnyConnectProfile>` +
+		`<ClientInitialization><AutomaticVPNPolicy>` +
+		`<TrustedHttpsServerList><TrustedHttpsServer>` +
+		`<Address>tnd.example.com</Address>` +
+		`</TrustedHttpsServer></TrustedHttpsServerList>` +
+		`</AutomaticVPNPolicy></ClientInitialization>` +
+		`</AnyConnectProfile>`))
+	want := []dbusapi.Finding{
+		findingWarning("ServerList", "no VPN servers"),
+		findingError("TrustedHttpsServerList",
+			"trusted HTTPS server without address or certificate hash"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestDaemonValidate tests validate of Daemon
+func TestDaemonValidate(t *testing.T) {
+	d := &Daemon{config: NewConfig()}
+	for _, kind := range []string{
+		dbusapi.ValidateKindConfig,
+		dbusapi.ValidateKindProfile,
+	} {
+		if _, err := d.validate(kind, []byte("{}")); err != nil {
+			t.Errorf("%s: %v", kind, err)
+		}
+	}
+	if _, err := d.validate("other", nil); err == nil {
+		t.Error("invalid kind should fail")
+	}
+}
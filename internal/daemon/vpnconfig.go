@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"net"
 	"os/exec"
+	"strings"
 
-	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/manifest"
 	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
 	"github.com/vishvananda/netlink"
 )
@@ -44,6 +46,7 @@ func setupVPNDevice(c *vpnconfig.Config) {
 			Error("Daemon could not find device")
 		return
 	}
+	manifest.Add(manifest.KindDevice, c.Device.Name)
 
 	// set mtu on device
 	if err := runLinkSetMTU(link, c.Device.MTU); err != nil {
@@ -69,7 +72,7 @@ func setupVPNDevice(c *vpnconfig.Config) {
 			IPNet: ipnet,
 		}
 		if err := runAddrAdd(link, addr); err != nil {
-			log.WithFields(log.Fields{
+			log.WithFields(logrus.Fields{
 				"device": c.Device.Name,
 				"ip":     ip,
 			}).Error("Daemon could net set ip on device")
@@ -101,7 +104,7 @@ func teardownVPNDevice(c *vpnconfig.Config) {
 			Error("Daemon could not set device down")
 		return
 	}
-
+	manifest.Remove(manifest.KindDevice, c.Device.Name)
 }
 
 // runResolvctl runs the resolvectl cmd
@@ -109,28 +112,88 @@ var runResolvectl = func(cmd string) {
 	log.WithField("command", cmd).Debug("Daemon executing resolvectl command")
 	c := exec.Command("bash", "-c", "resolvectl "+cmd)
 	if err := c.Run(); err != nil {
-		log.WithFields(log.Fields{
+		log.WithFields(logrus.Fields{
 			"command": cmd,
 			"error":   err,
 		}).Error("Daemon resolvectl command execution error")
 	}
 }
 
+// addDNSTransports adds transports to the DNS configuration in c for DNS
+// servers in c without transport
+func addDNSTransports(c *vpnconfig.Config, transports map[string]string) {
+	servers := append(c.DNS.ServersIPv4[:0:0], c.DNS.ServersIPv4...)
+	servers = append(servers, c.DNS.ServersIPv6...)
+	for _, s := range servers {
+		server := s.String()
+		transport, ok := transports[server]
+		if !ok {
+			continue
+		}
+		if _, ok := c.DNS.Transports[server]; ok {
+			continue
+		}
+		if c.DNS.Transports == nil {
+			c.DNS.Transports = make(map[string]string)
+		}
+		c.DNS.Transports[server] = transport
+	}
+}
+
+// removeTunnelIPv6 removes the IPv6 configuration of the tunnel from c, i.e.,
+// the IPv6 address, DNS servers and split includes, so IPv6 traffic is
+// rejected on the VPN device; it returns false if c has no IPv6 configuration
+func removeTunnelIPv6(c *vpnconfig.Config) bool {
+	if len(c.IPv6.Address) == 0 && len(c.DNS.ServersIPv6) == 0 &&
+		len(c.Split.IncludeIPv6) == 0 {
+		return false
+	}
+	c.IPv6 = vpnconfig.Address{}
+	for _, s := range c.DNS.ServersIPv6 {
+		delete(c.DNS.Transports, s.String())
+	}
+	c.DNS.ServersIPv6 = nil
+	c.Split.IncludeIPv6 = nil
+	return true
+}
+
+// vpnDNSDomains returns the domains of the VPN device in systemd-resolved
+// with the DNS configuration in c, routing domains start with "~"
+func vpnDNSDomains(c *vpnconfig.Config) []string {
+	domains := []string{c.DNS.DefaultDomain}
+	if !c.DNS.SplitDNS() {
+		// use this device for all domains
+		return append(domains, "~.")
+	}
+
+	// split dns, use this device only for the split domains and the
+	// reverse zones of the tunneled networks
+	for _, d := range c.DNS.SplitDomains {
+		domains = append(domains, "~"+d)
+	}
+	for _, z := range c.Split.ReverseZones() {
+		domains = append(domains, "~"+strings.TrimSuffix(z, "."))
+	}
+	return domains
+}
+
 // setVPNDNS applies the DNS configuration and sets dns server address;
 // the server address should be the local DNS-Proxy
 func setVPNDNS(c *vpnconfig.Config, server string) {
 	device := c.Device.Name
-	search := c.DNS.DefaultDomain
 
 	// set dns server for device
+	manifest.Add(manifest.KindResolved, device)
 	runResolvectl(fmt.Sprintf("dns %s %s", device, server))
 
-	// set domains for device
-	// this includes "~." to use this device for all domains
-	runResolvectl(fmt.Sprintf("domain %s %s ~.", device, search))
-
-	// set default route for device
-	runResolvectl(fmt.Sprintf("default-route %s yes", device))
+	// set domains and default route for device
+	runResolvectl(fmt.Sprintf("domain %s %s", device,
+		strings.Join(vpnDNSDomains(c), " ")))
+	if c.DNS.SplitDNS() {
+		runResolvectl(fmt.Sprintf("default-route %s no", device))
+	} else {
+		runResolvectl(fmt.Sprintf("default-route %s yes", device))
+	}
 
 	// flush dns caches
 	runResolvectl("flush-caches")
@@ -145,6 +208,7 @@ func unsetVPNDNS(c *vpnconfig.Config) {
 
 	// undo device dns configuration
 	runResolvectl(fmt.Sprintf("revert %s", device))
+	manifest.Remove(manifest.KindResolved, device)
 
 	// flush dns caches
 	runResolvectl("flush-caches")
@@ -91,6 +91,78 @@ func TestTeardownVPNDevice(t *testing.T) {
 	}
 }
 
+// TestAddDNSTransports tests addDNSTransports
+func TestAddDNSTransports(t *testing.T) {
+	c := vpnconfig.New()
+	c.DNS.ServersIPv4 = []net.IP{
+		net.ParseIP("192.168.1.1"),
+		net.ParseIP("192.168.1.2"),
+	}
+	c.DNS.ServersIPv6 = []net.IP{net.ParseIP("2001:db8::1")}
+
+	// test empty
+	addDNSTransports(c, nil)
+	if c.DNS.Transports != nil {
+		t.Errorf("got %v, want nil", c.DNS.Transports)
+	}
+
+	// test filled, existing transports should not be changed
+	c.DNS.Transports = map[string]string{"192.168.1.2": "udp"}
+	addDNSTransports(c, map[string]string{
+		"192.168.1.1": "tcp",
+		"192.168.1.2": "tcp",
+		"2001:db8::1": "tls",
+		"10.0.0.1":    "tcp",
+	})
+	want := map[string]string{
+		"192.168.1.1": "tcp",
+		"192.168.1.2": "udp",
+		"2001:db8::1": "tls",
+	}
+	if !reflect.DeepEqual(c.DNS.Transports, want) {
+		t.Errorf("got %v, want %v", c.DNS.Transports, want)
+	}
+}
+
+// TestRemoveTunnelIPv6 tests removeTunnelIPv6
+func TestRemoveTunnelIPv6(t *testing.T) {
+	c := vpnconfig.New()
+	c.IPv4.Address = net.ParseIP("192.168.0.123")
+	c.DNS.ServersIPv4 = []net.IP{net.ParseIP("192.168.1.1")}
+	_, exclude, _ := net.ParseCIDR("2001:db8:1::/48")
+	c.Split.ExcludeIPv6 = []*net.IPNet{exclude}
+
+	// test without ipv6
+	if removeTunnelIPv6(c) {
+		t.Error("config without ipv6 should not be changed")
+	}
+
+	// test with ipv6, excludes are kept
+	c.IPv6.Address = net.ParseIP("2001:db8::123")
+	c.IPv6.Netmask = net.CIDRMask(64, 128)
+	c.DNS.ServersIPv6 = []net.IP{net.ParseIP("2001:db8::1")}
+	c.DNS.Transports = map[string]string{
+		"192.168.1.1": "tcp",
+		"2001:db8::1": "tls",
+	}
+	_, include, _ := net.ParseCIDR("2001:db8:2::/48")
+	c.Split.IncludeIPv6 = []*net.IPNet{include}
+	if !removeTunnelIPv6(c) {
+		t.Error("config with ipv6 should be changed")
+	}
+	if len(c.IPv6.Address) != 0 || len(c.IPv6.Netmask) != 0 ||
+		len(c.DNS.ServersIPv6) != 0 || len(c.Split.IncludeIPv6) != 0 {
+		t.Errorf("ipv6 configuration should be removed: %v", c)
+	}
+	if !reflect.DeepEqual(c.Split.ExcludeIPv6, []*net.IPNet{exclude}) {
+		t.Errorf("got %v, want %v", c.Split.ExcludeIPv6, exclude)
+	}
+	want := map[string]string{"192.168.1.1": "tcp"}
+	if !reflect.DeepEqual(c.DNS.Transports, want) {
+		t.Errorf("got %v, want %v", c.DNS.Transports, want)
+	}
+}
+
 // TestSetVPNDNS tests setVPNDNS
 func TestSetVPNDNS(t *testing.T) {
 	c := vpnconfig.New()
@@ -113,6 +185,39 @@ func TestSetVPNDNS(t *testing.T) {
 	if !reflect.DeepEqual(got, want) {
 		t.Errorf("got %v, want %v", got, want)
 	}
+
+	// test split dns
+	c.DNS.SplitDomains = []string{"mycompany.com", "other.com"}
+	got = []string{}
+	setVPNDNS(c, "127.0.0.1:4253")
+
+	want = []string{
+		"dns tun0 127.0.0.1:4253",
+		"domain tun0 mycompany.com ~mycompany.com ~other.com",
+		"default-route tun0 no",
+		"flush-caches",
+		"reset-server-features",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// test split dns with reverse zones
+	_, include, _ := net.ParseCIDR("10.0.0.0/8")
+	c.Split.IncludeIPv4 = []*net.IPNet{include}
+	got = []string{}
+	setVPNDNS(c, "127.0.0.1:4253")
+
+	want = []string{
+		"dns tun0 127.0.0.1:4253",
+		"domain tun0 mycompany.com ~mycompany.com ~other.com ~10.in-addr.arpa",
+		"default-route tun0 no",
+		"flush-caches",
+		"reset-server-features",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
 }
 
 // TestUnsetVPNDNS tests unsetVPNDNS
@@ -6,24 +6,32 @@ import (
 	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
 )
 
-// VPNConfigUpdate is a VPN configuration update
+// VPNConfigUpdate is a VPN configuration update, Response is the response
+// to the challenge of the daemon with the secret of the VPN connection
 type VPNConfigUpdate struct {
-	Reason string
-	Token  string
-	Config *vpnconfig.Config
+	Reason   string
+	Response []byte
+	Config   *vpnconfig.Config
 }
 
 // Valid returns if the config update is valid
 func (c *VPNConfigUpdate) Valid() bool {
 	switch c.Reason {
 	case "disconnect":
-		// token must be valid and config nil
-		if c.Token == "" || c.Config != nil {
+		// response must be set and config nil
+		if len(c.Response) == 0 || c.Config != nil {
+			return false
+		}
+	case "attempt-reconnect":
+		// response must be set and config must contain the gateway
+		// address openconnect reconnects to
+		if len(c.Response) == 0 || c.Config == nil ||
+			len(c.Config.Gateway) == 0 {
 			return false
 		}
 	case "connect":
-		// token and config must be valid
-		if c.Token == "" || c.Config == nil {
+		// response must be set and config valid
+		if len(c.Response) == 0 || c.Config == nil {
 			return false
 		}
 		if !c.Config.Valid() {
@@ -1,7 +1,7 @@
 package daemon
 
 import (
-	"log"
+	"net"
 	"reflect"
 	"testing"
 
@@ -21,7 +21,7 @@ func TestVPNConfigUpdateValid(t *testing.T) {
 	// test valid disconnect
 	u = NewVPNConfigUpdate()
 	u.Reason = "disconnect"
-	u.Token = "some test token"
+	u.Response = []byte("some test response")
 
 	got = u.Valid()
 	want = true
@@ -32,7 +32,7 @@ func TestVPNConfigUpdateValid(t *testing.T) {
 	// test valid connect
 	u = NewVPNConfigUpdate()
 	u.Reason = "connect"
-	u.Token = "some test token"
+	u.Response = []byte("some test response")
 	u.Config = vpnconfig.New()
 
 	got = u.Valid()
@@ -40,6 +40,19 @@ func TestVPNConfigUpdateValid(t *testing.T) {
 	if got != want {
 		t.Errorf("got %t, want %t", got, want)
 	}
+
+	// test attempt reconnect without and with gateway
+	u = NewVPNConfigUpdate()
+	u.Reason = "attempt-reconnect"
+	u.Response = []byte("some test response")
+	u.Config = vpnconfig.New()
+	if u.Valid() {
+		t.Error("attempt reconnect without gateway should be invalid")
+	}
+	u.Config.Gateway = net.ParseIP("192.168.1.1")
+	if !u.Valid() {
+		t.Error("attempt reconnect with gateway should be valid")
+	}
 }
 
 // TestVPNConfigUpdateJSON tests JSON and VPNConfigUpdateFromJSON of VPNConfigUpdate
@@ -53,13 +66,13 @@ func TestVPNConfigUpdateJSON(t *testing.T) {
 	// valid disconnect
 	u = NewVPNConfigUpdate()
 	u.Reason = "disconnect"
-	u.Token = "some test token"
+	u.Response = []byte("some test response")
 	updates = append(updates, u)
 
 	// valid connect
 	u = NewVPNConfigUpdate()
 	u.Reason = "connect"
-	u.Token = "some test token"
+	u.Response = []byte("some test response")
 	u.Config = vpnconfig.New()
 	updates = append(updates, u)
 
@@ -0,0 +1,100 @@
+package dbusapi
+
+import (
+	"github.com/godbus/dbus/v5/introspect"
+)
+
+// methodArgs are the names of the input arguments followed by the output
+// arguments of the D-Bus methods, they complete the introspection data of
+// the methods that only contains the argument types
+var methodArgs = map[string][]string{
+	// daemon
+	"Connect":               {"cookie", "host", "connectURL", "fingerprint", "resolve"},
+	"ConnectProfile":        {"profile", "cookie", "host", "connectURL", "fingerprint", "resolve"},
+	"ConnectCached":         {},
+	"Reconnect":             {},
+	"ConnectTunnel":         {"tunnel", "profile", "cookie", "host", "connectURL", "fingerprint", "resolve"},
+	"Disconnect":            {},
+	"DisconnectTunnel":      {"tunnel"},
+	"Cancel":                {},
+	"Pause":                 {"timeout"},
+	"Resume":                {},
+	"Doctor":                {"problems"},
+	"ConnectDevice":         {"profile", "server", "userCode", "verificationURI", "verificationURIComplete"},
+	"SetSchedule":           {"enabled"},
+	"SetTND":                {"enabled", "timeout"},
+	"SetTrafPol":            {"enabled", "timeout"},
+	"ReportHostscan":        {},
+	"GetLogs":               {"lines", "logs"},
+	"ListServers":           {"ping", "servers"},
+	"GetUsage":              {"usage"},
+	"GetMetrics":            {"metrics"},
+	"SetPreferredServer":    {"server"},
+	"AddSplitExclude":       {"address"},
+	"RemoveSplitExclude":    {"address"},
+	"ListSplitExcludes":     {"excludes"},
+	"ValidateConfig":        {"kind", "data", "findings"},
+	"SetNotificationPolicy": {"policy"},
+	"ReloadProfile":         {},
+	"GetStatus":             {"status"},
+
+	// object manager
+	"GetManagedObjects": {"objects"},
+}
+
+// introspectMethods returns the introspection data of the D-Bus methods of
+// v with the argument names in methodArgs
+func introspectMethods(v any) []introspect.Method {
+	methods := introspect.Methods(v)
+	for _, m := range methods {
+		names := methodArgs[m.Name]
+		for i := range m.Args {
+			if i < len(names) {
+				m.Args[i].Name = names[i]
+			}
+		}
+	}
+	return methods
+}
+
+// daemonSignals is the introspection data of the D-Bus signals of the
+// daemon interface
+var daemonSignals = []introspect.Signal{
+	{Name: "ConnectionDropped"},
+	{Name: "IdleTimeout"},
+	{
+		Name: "SessionExpiring",
+		Args: []introspect.Arg{
+			{Name: "remaining", Type: "u"},
+		},
+	},
+	{Name: "ReauthRequired"},
+}
+
+// objectManagerSignals is the introspection data of the D-Bus signals of
+// the ObjectManager interface
+var objectManagerSignals = []introspect.Signal{
+	{
+		Name: "InterfacesAdded",
+		Args: []introspect.Arg{
+			{Name: "object_path", Type: "o"},
+			{Name: "interfaces_and_properties", Type: "a{sa{sv}}"},
+		},
+	},
+	{
+		Name: "InterfacesRemoved",
+		Args: []introspect.Arg{
+			{Name: "object_path", Type: "o"},
+			{Name: "interfaces", Type: "as"},
+		},
+	},
+}
+
+// childNodes returns the introspection nodes of the child objects with names
+func childNodes(names ...string) []introspect.Node {
+	nodes := []introspect.Node{}
+	for _, name := range names {
+		nodes = append(nodes, introspect.Node{Name: name})
+	}
+	return nodes
+}
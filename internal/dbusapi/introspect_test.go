@@ -0,0 +1,42 @@
+package dbusapi
+
+import "testing"
+
+// TestIntrospectMethods tests introspectMethods
+func TestIntrospectMethods(t *testing.T) {
+	for _, v := range []any{daemon{}, &objectManager{}} {
+		methods := introspectMethods(v)
+		if len(methods) == 0 {
+			t.Errorf("%T: no methods", v)
+		}
+		for _, m := range methods {
+			names, ok := methodArgs[m.Name]
+			if !ok {
+				t.Errorf("%s: no argument names", m.Name)
+				continue
+			}
+			if len(names) != len(m.Args) {
+				t.Errorf("%s: got %d argument names, want %d", m.Name,
+					len(names), len(m.Args))
+				continue
+			}
+			for i, a := range m.Args {
+				if a.Name != names[i] {
+					t.Errorf("%s: got %s, want %s", m.Name, a.Name,
+						names[i])
+				}
+			}
+		}
+	}
+}
+
+// TestChildNodes tests childNodes
+func TestChildNodes(t *testing.T) {
+	if got := childNodes(); len(got) != 0 {
+		t.Errorf("got %v, want empty", got)
+	}
+	got := childNodes("Daemon", "Connections")
+	if len(got) != 2 || got[0].Name != "Daemon" || got[1].Name != "Connections" {
+		t.Errorf("got %v", got)
+	}
+}
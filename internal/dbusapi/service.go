@@ -1,12 +1,17 @@
 package dbusapi
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/godbus/dbus/v5"
 	"github.com/godbus/dbus/v5/introspect"
 	"github.com/godbus/dbus/v5/prop"
 	log "github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/charset"
+	"github.com/telekom-mms/oc-daemon/internal/logging"
 )
 
 // D-Bus object path and interface
@@ -15,16 +20,67 @@ const (
 	Interface = "com.telekom_mms.oc_daemon.Daemon"
 )
 
+// D-Bus root object path, interface of the ObjectManager at the root object
+// and interface of the VPN connection objects
+const (
+	RootPath               = "/com/telekom_mms/oc_daemon"
+	ObjectManagerInterface = "org.freedesktop.DBus.ObjectManager"
+	ConnectionInterface    = "com.telekom_mms.oc_daemon.Connection"
+)
+
+// ConnectionPath returns the D-Bus object path of the VPN connection with
+// number n, the object has the properties of ConnectionInterface; the VPN
+// connection of the daemon has number 0, the additional tunnels follow in
+// the order they were added
+func ConnectionPath(n int) dbus.ObjectPath {
+	return dbus.ObjectPath(fmt.Sprintf("%s/Connections/%d", RootPath, n))
+}
+
+// TunnelPath returns the D-Bus object path of the additional tunnel with
+// name, the object has the tunnel properties of Interface
+func TunnelPath(name string) dbus.ObjectPath {
+	return dbus.ObjectPath(Path + "/Tunnels/" + name)
+}
+
 // Properties
 const (
-	PropertyTrustedNetwork  = "TrustedNetwork"
-	PropertyConnectionState = "ConnectionState"
-	PropertyIP              = "IP"
-	PropertyDevice          = "Device"
-	PropertyConnectedAt     = "ConnectedAt"
-	PropertyServers         = "Servers"
-	PropertyOCRunning       = "OCRunning"
-	PropertyVPNConfig       = "VPNConfig"
+	PropertyTrustedNetwork    = "TrustedNetwork"
+	PropertyConnectionState   = "ConnectionState"
+	PropertyIP                = "IP"
+	PropertyDevice            = "Device"
+	PropertyConnectedAt       = "ConnectedAt"
+	PropertyServers           = "Servers"
+	PropertyOCRunning         = "OCRunning"
+	PropertyVPNConfig         = "VPNConfig"
+	PropertyProxy             = "Proxy"
+	PropertyRetryAt           = "RetryAt"
+	PropertyRetryAttempt      = "RetryAttempt"
+	PropertyRXBytes           = "RXBytes"
+	PropertyTXBytes           = "TXBytes"
+	PropertyRXPackets         = "RXPackets"
+	PropertyTXPackets         = "TXPackets"
+	PropertyDNSLeaksBlocked   = "DNSLeaksBlocked"
+	PropertyDNSServers        = "DNSServers"
+	PropertyDNSSearchDomains  = "DNSSearchDomains"
+	PropertyDNSSplitDomains   = "DNSSplitDomains"
+	PropertyScheduleState     = "ScheduleState"
+	PropertyConnectivity      = "Connectivity"
+	PropertyCompression       = "Compression"
+	PropertyTNDState          = "TNDState"
+	PropertyCSDWrapper        = "CSDWrapper"
+	PropertyDisconnectReason  = "DisconnectReason"
+	PropertyAPIs              = "APIs"
+	PropertyRouteAggregations = "RouteAggregations"
+	PropertyGatewayExcludes   = "GatewayExcludes"
+	PropertyTrafPolState      = "TrafPolState"
+	PropertyPreferredServer   = "PreferredServer"
+	PropertyVersion           = "Version"
+	PropertyCapabilities      = "Capabilities"
+	PropertyUptime            = "Uptime"
+	PropertyName              = "Name"
+	PropertyOwnerUID          = "OwnerUID"
+	PropertyOwner             = "Owner"
+	PropertyPauseState        = "PauseState"
 )
 
 // Property "Trusted Network" states
@@ -75,27 +131,362 @@ const (
 	VPNConfigInvalid = ""
 )
 
+// Property "Proxy" values
+const (
+	ProxyInvalid = ""
+)
+
+// Property "Retry At" values
+const (
+	RetryAtInvalid int64 = -1
+)
+
+// Property "Retry Attempt" values
+const (
+	RetryAttemptInvalid uint32 = 0
+)
+
+// Property "RX Bytes", "TX Bytes", "RX Packets", "TX Packets" values
+const (
+	TrafficStatsInvalid uint64 = 0
+)
+
+// Property "DNS Leaks Blocked" values
+const (
+	DNSLeaksBlockedInvalid uint64 = 0
+)
+
+// Property "DNS Servers", "DNS Search Domains", "DNS Split Domains" values
+var (
+	DNSDomainsInvalid []string
+)
+
+// Property "Schedule State" states
+const (
+	ScheduleStateUnknown uint32 = iota
+	ScheduleStateDisabled
+	ScheduleStateInactive
+	ScheduleStateConnect
+	ScheduleStateForbid
+)
+
+// Property "Connectivity" states
+const (
+	ConnectivityUnknown uint32 = iota
+	ConnectivityOffline
+	ConnectivityOnline
+)
+
+// Property "Compression" values
+const (
+	CompressionInvalid = ""
+)
+
+// Property "TND State" states
+const (
+	TNDStateUnknown uint32 = iota
+	TNDStateEnabled
+	TNDStateDisabled
+)
+
+// Property "CSD Wrapper" values
+const (
+	CSDWrapperInvalid = ""
+)
+
+// Property "Disconnect Reason" values
+const (
+	DisconnectReasonInvalid = ""
+)
+
+// Property "APIs" values
+var (
+	APIsInvalid []string
+)
+
+// Property "Route Aggregations" values
+const (
+	RouteAggregationsInvalid uint64 = 0
+)
+
+// Property "Gateway Excludes" values
+var (
+	GatewayExcludesInvalid []string
+)
+
+// Property "TrafPol State" states
+const (
+	TrafPolStateUnknown uint32 = iota
+	TrafPolStateActive
+	TrafPolStateInactive
+	TrafPolStateDisabled
+)
+
+// Property "Preferred Server" values
+const (
+	PreferredServerUnset = ""
+)
+
+// APIVersion is the version of the D-Bus API in property "Version", it is
+// increased on incompatible changes of the API
+const APIVersion uint32 = 1
+
+// Property "Capabilities" values, they are the optional features of the
+// daemon that clients can detect instead of guessing from daemon versions
+const (
+	// CapabilityMultiTunnel is the support of additional tunnels
+	CapabilityMultiTunnel = "multi-tunnel"
+
+	// CapabilityStats is the support of periodic traffic statistics
+	CapabilityStats = "stats"
+
+	// CapabilityDeviceAuth is the support of connects with device
+	// authorization with "ConnectDevice"
+	CapabilityDeviceAuth = "device-auth"
+
+	// CapabilityDNSProxy is the support of the DNS-Proxy and split DNS
+	CapabilityDNSProxy = "dnsproxy"
+
+	// CapabilityTrafPol is the support of traffic policing
+	CapabilityTrafPol = "trafpol"
+
+	// CapabilityPreferredServer is the support of "SetPreferredServer"
+	CapabilityPreferredServer = "preferred-server"
+
+	// CapabilityCancel is the support of "Cancel"
+	CapabilityCancel = "cancel"
+
+	// CapabilitySplitExcludes is the support of "AddSplitExclude",
+	// "RemoveSplitExclude" and "ListSplitExcludes"
+	CapabilitySplitExcludes = "split-excludes"
+
+	// CapabilityConnectionObjects is the support of the VPN connection
+	// objects at ConnectionPath and the ObjectManager at RootPath
+	CapabilityConnectionObjects = "connection-objects"
+
+	// CapabilityConnectCached is the support of "ConnectCached"
+	CapabilityConnectCached = "connect-cached"
+
+	// CapabilityPause is the support of "Pause" and "Resume"
+	CapabilityPause = "pause"
+
+	// CapabilityReconnect is the support of "Reconnect" and the
+	// "ReauthRequired" signal
+	CapabilityReconnect = "reconnect"
+
+	// CapabilityValidateConfig is the support of "ValidateConfig"
+	CapabilityValidateConfig = "validate-config"
+
+	// CapabilityNotificationPolicy is the support of
+	// "SetNotificationPolicy"
+	CapabilityNotificationPolicy = "notification-policy"
+
+	// CapabilityUsage is the support of "GetUsage"
+	CapabilityUsage = "usage"
+
+	// CapabilityMetrics is the support of "GetMetrics"
+	CapabilityMetrics = "metrics"
+)
+
+// Property "Capabilities" values
+var (
+	CapabilitiesInvalid []string
+)
+
+// Property "Uptime" values
+const (
+	UptimeInvalid uint64 = 0
+)
+
+// Property "OwnerUID" values
+const (
+	OwnerUIDInvalid int64 = -1
+)
+
+// Property "Owner" values
+const (
+	OwnerInvalid = ""
+)
+
+// Property "Pause State" states
+const (
+	PauseStateUnknown uint32 = iota
+	PauseStateNotPaused
+	PauseStatePaused
+)
+
+// TunnelProperties are the properties of the additional tunnel objects
+var TunnelProperties = []string{
+	PropertyConnectionState,
+	PropertyIP,
+	PropertyDevice,
+	PropertyConnectedAt,
+	PropertyOCRunning,
+	PropertyVPNConfig,
+}
+
+// ConnectionProperties are the properties of the VPN connection objects
+var ConnectionProperties = append([]string{PropertyName}, TunnelProperties...)
+
+// Property "Name" values, the VPN connection of the daemon has no name
+const (
+	NameDefault = ""
+)
+
 // Methods
 const (
-	MethodConnect    = Interface + ".Connect"
-	MethodDisconnect = Interface + ".Disconnect"
+	MethodConnect               = Interface + ".Connect"
+	MethodConnectProfile        = Interface + ".ConnectProfile"
+	MethodConnectTunnel         = Interface + ".ConnectTunnel"
+	MethodConnectCached         = Interface + ".ConnectCached"
+	MethodReconnect             = Interface + ".Reconnect"
+	MethodDisconnect            = Interface + ".Disconnect"
+	MethodDisconnectTunnel      = Interface + ".DisconnectTunnel"
+	MethodCancel                = Interface + ".Cancel"
+	MethodPause                 = Interface + ".Pause"
+	MethodResume                = Interface + ".Resume"
+	MethodDoctor                = Interface + ".Doctor"
+	MethodSetSchedule           = Interface + ".SetSchedule"
+	MethodConnectDevice         = Interface + ".ConnectDevice"
+	MethodSetTND                = Interface + ".SetTND"
+	MethodReportHostscan        = Interface + ".ReportHostscan"
+	MethodGetLogs               = Interface + ".GetLogs"
+	MethodListServers           = Interface + ".ListServers"
+	MethodReloadProfile         = Interface + ".ReloadProfile"
+	MethodGetStatus             = Interface + ".GetStatus"
+	MethodSetTrafPol            = Interface + ".SetTrafPol"
+	MethodSetPreferredServer    = Interface + ".SetPreferredServer"
+	MethodAddSplitExclude       = Interface + ".AddSplitExclude"
+	MethodRemoveSplitExclude    = Interface + ".RemoveSplitExclude"
+	MethodListSplitExcludes     = Interface + ".ListSplitExcludes"
+	MethodValidateConfig        = Interface + ".ValidateConfig"
+	MethodSetNotificationPolicy = Interface + ".SetNotificationPolicy"
+	MethodGetUsage              = Interface + ".GetUsage"
+	MethodGetMetrics            = Interface + ".GetMetrics"
+)
+
+// Signals
+const (
+	SignalConnectionDropped = Interface + ".ConnectionDropped"
+	SignalIdleTimeout       = Interface + ".IdleTimeout"
+	SignalSessionExpiring   = Interface + ".SessionExpiring"
+	SignalReauthRequired    = Interface + ".ReauthRequired"
 )
 
 // Request Names
 const (
-	RequestConnect    = "Connect"
-	RequestDisconnect = "Disconnect"
+	RequestConnect               = "Connect"
+	RequestConnectTunnel         = "ConnectTunnel"
+	RequestConnectCached         = "ConnectCached"
+	RequestReconnect             = "Reconnect"
+	RequestDisconnect            = "Disconnect"
+	RequestDisconnectTunnel      = "DisconnectTunnel"
+	RequestCancel                = "Cancel"
+	RequestPause                 = "Pause"
+	RequestResume                = "Resume"
+	RequestDoctor                = "Doctor"
+	RequestSetSchedule           = "SetSchedule"
+	RequestConnectDevice         = "ConnectDevice"
+	RequestSetTND                = "SetTND"
+	RequestReportHostscan        = "ReportHostscan"
+	RequestGetLogs               = "GetLogs"
+	RequestListServers           = "ListServers"
+	RequestReloadProfile         = "ReloadProfile"
+	RequestGetStatus             = "GetStatus"
+	RequestSetTrafPol            = "SetTrafPol"
+	RequestSetPreferredServer    = "SetPreferredServer"
+	RequestAddSplitExclude       = "AddSplitExclude"
+	RequestRemoveSplitExclude    = "RemoveSplitExclude"
+	RequestListSplitExcludes     = "ListSplitExcludes"
+	RequestValidateConfig        = "ValidateConfig"
+	RequestSetNotificationPolicy = "SetNotificationPolicy"
+	RequestGetUsage              = "GetUsage"
+	RequestGetMetrics            = "GetMetrics"
 )
 
+// Kinds of candidate configurations of the "ValidateConfig" method
+const (
+	ValidateKindConfig  = "config"
+	ValidateKindProfile = "profile"
+)
+
+// Severities of findings of the "ValidateConfig" method
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Finding is a finding of the "ValidateConfig" method: an error that makes
+// the candidate configuration invalid or a warning, e.g., about a setting
+// that requires a restart, Field is the affected setting if known
+type Finding struct {
+	Severity string
+	Field    string
+	Message  string
+}
+
+// Problem is a problem with a runtime dependency of the daemon found by the
+// "Doctor" method
+type Problem struct {
+	Check   string
+	Message string
+}
+
+// Server is a VPN server in the XML profile returned by the "ListServers"
+// method, Reachable and Latency are the result of the reachability probe in
+// microseconds if the server was pinged
+type Server struct {
+	Name      string
+	Address   string
+	Reachable bool
+	Latency   int64
+}
+
+// Usage is the cumulative VPN usage in a month returned by the "GetUsage"
+// method, Month is the month in local time, e.g., "2026-10", and
+// ConnectedSeconds the connected time in seconds
+type Usage struct {
+	Month            string
+	RXBytes          uint64
+	TXBytes          uint64
+	Connections      uint64
+	ConnectedSeconds uint64
+}
+
+// RequestMetrics are the metrics of a request type of an API returned by
+// the "GetMetrics" method, e.g., of "Connect" of the "dbus" API: the number
+// of requests and failed requests, the sum of their latencies in
+// microseconds and the latency histogram with the number of requests per
+// latency bucket
+type RequestMetrics struct {
+	API                 string
+	Request             string
+	Count               uint64
+	Errors              uint64
+	LatencyMicroseconds uint64
+	LatencyBuckets      []uint64
+}
+
+// UIDUnknown is the UID of a request sender that could not be determined
+const UIDUnknown int64 = -1
+
 // Request is a D-Bus client request
 type Request struct {
-	Name       string
+	Name string
+
+	// ID identifies the request in log entries and error messages
+	ID string
+
 	Parameters []any
 	Results    []any
 	Error      error
 
+	// Sender is the D-Bus sender of the request and UID its unix user ID
+	Sender string
+	UID    int64
+
 	wait chan struct{}
-	done chan struct{}
+	done <-chan struct{}
 }
 
 // Close completes the request handling
@@ -112,67 +503,884 @@ func (r *Request) Wait() {
 	}
 }
 
+// ErrorTooManyRequests is the name of the D-Bus error returned for requests
+// rejected by rate limiting, its body contains the error message and the
+// number of seconds after which the client can retry
+const ErrorTooManyRequests = Interface + ".TooManyRequests"
+
+// ErrorOffline is the name of the D-Bus error returned for connect requests
+// rejected because the host is offline
+const ErrorOffline = Interface + ".Offline"
+
+// ErrOffline is the error of connect requests rejected because the host is
+// offline, i.e., there is no device with carrier and default route
+var ErrOffline = errors.New("no network connectivity")
+
+// ErrorReauthRequired is the name of the D-Bus error returned for reconnect
+// requests that need a new authentication of the user
+const ErrorReauthRequired = Interface + ".ReauthRequired"
+
+// ErrReauthRequired is the error of reconnect requests that need a new
+// authentication, e.g., because the VPN server rejected the cached login info
+var ErrReauthRequired = errors.New("reauthentication required")
+
+// TooManyRequestsError is the error of a request rejected by rate limiting
+type TooManyRequestsError struct {
+	RetryAfter time.Duration
+}
+
+// seconds returns the retry after time in full seconds, rounded up
+func (e *TooManyRequestsError) seconds() uint32 {
+	return uint32((e.RetryAfter + time.Second - 1) / time.Second)
+}
+
+// Error returns the error as string
+func (e *TooManyRequestsError) Error() string {
+	return fmt.Sprintf("too many requests, retry after %ds", e.seconds())
+}
+
+// errorMessage returns the message of err for D-Bus errors as UTF-8, D-Bus
+// only allows valid UTF-8 strings and errors can contain gateway messages in
+// legacy charsets
+func errorMessage(err error) string {
+	return charset.ToUTF8(err.Error())
+}
+
+// errorMessage returns the message of the error of the request for D-Bus
+// errors with the request ID
+func (r *Request) errorMessage() string {
+	return fmt.Sprintf("%s (request %s)", errorMessage(r.Error), r.ID)
+}
+
+// connectError returns the D-Bus error for the failed connect request r
+func connectError(r *Request) *dbus.Error {
+	var tooMany *TooManyRequestsError
+	if errors.As(r.Error, &tooMany) {
+		return dbus.NewError(ErrorTooManyRequests, []any{r.errorMessage(), tooMany.seconds()})
+	}
+	if errors.Is(r.Error, ErrOffline) {
+		return dbus.NewError(ErrorOffline, []any{r.errorMessage()})
+	}
+	if errors.Is(r.Error, ErrReauthRequired) {
+		return dbus.NewError(ErrorReauthRequired, []any{r.errorMessage()})
+	}
+	return dbus.NewError(Interface+".ConnectAborted", []any{r.errorMessage()})
+}
+
 // daemon defines daemon interface methods
 type daemon struct {
+	conn     dbusConn
 	requests chan *Request
-	done     chan struct{}
+	done     <-chan struct{}
+}
+
+// getSenderUID returns the unix user ID of sender on conn
+var getSenderUID = func(conn dbusConn, sender dbus.Sender) int64 {
+	c, ok := conn.(*dbus.Conn)
+	if !ok {
+		return UIDUnknown
+	}
+	var uid uint32
+	if err := c.BusObject().Call("org.freedesktop.DBus.GetConnectionUnixUser",
+		0, string(sender)).Store(&uid); err != nil {
+		log.WithError(err).WithField("sender", sender).
+			Error("Could not get UID of D-Bus sender")
+		return UIDUnknown
+	}
+	return int64(uid)
+}
+
+// Connect is the "Connect" method of the D-Bus interface, it connects with
+// the default profile
+func (d daemon) Connect(sender dbus.Sender, cookie, host, connectURL, fingerprint, resolve string) *dbus.Error {
+	log.WithField("sender", sender).Debug("Received D-Bus Connect() call")
+	return d.connect(sender, "", cookie, host, connectURL, fingerprint, resolve)
+}
+
+// ConnectProfile is the "ConnectProfile" method of the D-Bus interface, it
+// connects with the named profile, the default profile if profile is empty
+func (d daemon) ConnectProfile(sender dbus.Sender, profile, cookie, host, connectURL, fingerprint, resolve string) *dbus.Error {
+	log.WithFields(log.Fields{
+		"sender":  sender,
+		"profile": profile,
+	}).Debug("Received D-Bus ConnectProfile() call")
+	return d.connect(sender, profile, cookie, host, connectURL, fingerprint, resolve)
+}
+
+// connect sends a connect request with profile and login info to the daemon
+func (d daemon) connect(sender dbus.Sender, profile, cookie, host, connectURL, fingerprint, resolve string) *dbus.Error {
+	request := &Request{
+		Name:       RequestConnect,
+		ID:         logging.NewRequestID(),
+		Parameters: []any{cookie, host, connectURL, fingerprint, resolve, profile},
+		Sender:     string(sender),
+		UID:        getSenderUID(d.conn, sender),
+		wait:       make(chan struct{}),
+		done:       d.done,
+	}
+	select {
+	case d.requests <- request:
+	case <-d.done:
+		return dbus.NewError(Interface+".ConnectAborted", []any{"Connect aborted"})
+	}
+
+	request.Wait()
+	if request.Error != nil {
+		return connectError(request)
+	}
+	return nil
+}
+
+// ConnectCached is the "ConnectCached" method of the D-Bus interface, it
+// connects with the login info of the last connection of the same user
+// without a new authentication
+func (d daemon) ConnectCached(sender dbus.Sender) *dbus.Error {
+	log.WithField("sender", sender).Debug("Received D-Bus ConnectCached() call")
+	request := &Request{
+		Name:   RequestConnectCached,
+		ID:     logging.NewRequestID(),
+		Sender: string(sender),
+		UID:    getSenderUID(d.conn, sender),
+		wait:   make(chan struct{}),
+		done:   d.done,
+	}
+	select {
+	case d.requests <- request:
+	case <-d.done:
+		return dbus.NewError(Interface+".ConnectAborted", []any{"Connect aborted"})
+	}
+
+	request.Wait()
+	if request.Error != nil {
+		return connectError(request)
+	}
+	return nil
+}
+
+// Reconnect is the "Reconnect" method of the D-Bus interface, it
+// disconnects the VPN, waits for the teardown and connects again with the
+// login info of the last connection, it returns when the VPN is connected or
+// the reconnect failed
+func (d daemon) Reconnect(sender dbus.Sender) *dbus.Error {
+	log.WithField("sender", sender).Debug("Received D-Bus Reconnect() call")
+	request := &Request{
+		Name:   RequestReconnect,
+		ID:     logging.NewRequestID(),
+		Sender: string(sender),
+		UID:    getSenderUID(d.conn, sender),
+		wait:   make(chan struct{}),
+		done:   d.done,
+	}
+	select {
+	case d.requests <- request:
+	case <-d.done:
+		return dbus.NewError(Interface+".ConnectAborted", []any{"Connect aborted"})
+	}
+
+	request.Wait()
+	if request.Error != nil {
+		return connectError(request)
+	}
+	return nil
+}
+
+// ConnectTunnel is the "ConnectTunnel" method of the D-Bus interface, it
+// connects the additional tunnel with the named profile, the default profile
+// if profile is empty
+func (d daemon) ConnectTunnel(sender dbus.Sender, tunnel, profile, cookie, host, connectURL, fingerprint, resolve string) *dbus.Error {
+	log.WithFields(log.Fields{
+		"sender":  sender,
+		"tunnel":  tunnel,
+		"profile": profile,
+	}).Debug("Received D-Bus ConnectTunnel() call")
+	request := &Request{
+		Name:       RequestConnectTunnel,
+		ID:         logging.NewRequestID(),
+		Parameters: []any{cookie, host, connectURL, fingerprint, resolve, profile, tunnel},
+		Sender:     string(sender),
+		UID:        getSenderUID(d.conn, sender),
+		wait:       make(chan struct{}),
+		done:       d.done,
+	}
+	select {
+	case d.requests <- request:
+	case <-d.done:
+		return dbus.NewError(Interface+".ConnectAborted", []any{"Connect aborted"})
+	}
+
+	request.Wait()
+	if request.Error != nil {
+		return connectError(request)
+	}
+	return nil
+}
+
+// Disconnect is the "Disconnect" method of the D-Bus interface
+func (d daemon) Disconnect(sender dbus.Sender) *dbus.Error {
+	log.WithField("sender", sender).Debug("Received D-Bus Connect() call")
+	return d.disconnect(sender, RequestDisconnect, nil)
+}
+
+// DisconnectTunnel is the "DisconnectTunnel" method of the D-Bus interface,
+// it disconnects the additional tunnel
+func (d daemon) DisconnectTunnel(sender dbus.Sender, tunnel string) *dbus.Error {
+	log.WithFields(log.Fields{
+		"sender": sender,
+		"tunnel": tunnel,
+	}).Debug("Received D-Bus DisconnectTunnel() call")
+	return d.disconnect(sender, RequestDisconnectTunnel, []any{tunnel})
+}
+
+// disconnect sends a disconnect request with name and parameters to the
+// daemon
+func (d daemon) disconnect(sender dbus.Sender, name string, parameters []any) *dbus.Error {
+	request := &Request{
+		Name:       name,
+		ID:         logging.NewRequestID(),
+		Parameters: parameters,
+		Sender:     string(sender),
+		UID:        getSenderUID(d.conn, sender),
+		wait:       make(chan struct{}),
+		done:       d.done,
+	}
+	select {
+	case d.requests <- request:
+	case <-d.done:
+		return dbus.NewError(Interface+".DisconnectAborted", []any{"Disconnect aborted"})
+	}
+
+	request.Wait()
+	if request.Error != nil {
+		return dbus.NewError(Interface+".DisconnectAborted", []any{request.errorMessage()})
+	}
+	return nil
+}
+
+// Cancel is the "Cancel" method of the D-Bus interface, it aborts a
+// connection attempt that is still connecting
+func (d daemon) Cancel(sender dbus.Sender) *dbus.Error {
+	log.WithField("sender", sender).Debug("Received D-Bus Cancel() call")
+	request := &Request{
+		Name:   RequestCancel,
+		ID:     logging.NewRequestID(),
+		Sender: string(sender),
+		UID:    getSenderUID(d.conn, sender),
+		wait:   make(chan struct{}),
+		done:   d.done,
+	}
+	select {
+	case d.requests <- request:
+	case <-d.done:
+		return dbus.NewError(Interface+".CancelAborted", []any{"Cancel aborted"})
+	}
+
+	request.Wait()
+	if request.Error != nil {
+		return dbus.NewError(Interface+".CancelAborted", []any{request.errorMessage()})
+	}
+	return nil
+}
+
+// Pause is the "Pause" method of the D-Bus interface, it pauses the VPN
+// connection: the connection stays established but split routing and DNS
+// are removed; if timeout is not 0, the connection is resumed after timeout
+// seconds
+func (d daemon) Pause(sender dbus.Sender, timeout uint32) *dbus.Error {
+	log.WithFields(log.Fields{
+		"sender":  sender,
+		"timeout": timeout,
+	}).Debug("Received D-Bus Pause() call")
+	return d.pause(sender, RequestPause, []any{timeout})
+}
+
+// Resume is the "Resume" method of the D-Bus interface, it resumes the
+// paused VPN connection and restores split routing and DNS
+func (d daemon) Resume(sender dbus.Sender) *dbus.Error {
+	log.WithField("sender", sender).Debug("Received D-Bus Resume() call")
+	return d.pause(sender, RequestResume, nil)
+}
+
+// pause sends a pause or resume request with name and parameters to the
+// daemon
+func (d daemon) pause(sender dbus.Sender, name string, parameters []any) *dbus.Error {
+	request := &Request{
+		Name:       name,
+		ID:         logging.NewRequestID(),
+		Parameters: parameters,
+		Sender:     string(sender),
+		UID:        getSenderUID(d.conn, sender),
+		wait:       make(chan struct{}),
+		done:       d.done,
+	}
+	select {
+	case d.requests <- request:
+	case <-d.done:
+		return dbus.NewError(Interface+"."+name+"Aborted", []any{name + " aborted"})
+	}
+
+	request.Wait()
+	if request.Error != nil {
+		return dbus.NewError(Interface+"."+name+"Aborted", []any{request.errorMessage()})
+	}
+	return nil
+}
+
+// Doctor is the "Doctor" method of the D-Bus interface, it returns the
+// problems with runtime dependencies found by the daemon's self-check
+func (d daemon) Doctor(sender dbus.Sender) ([]Problem, *dbus.Error) {
+	log.WithField("sender", sender).Debug("Received D-Bus Doctor() call")
+	request := &Request{
+		Name:   RequestDoctor,
+		ID:     logging.NewRequestID(),
+		Sender: string(sender),
+		UID:    getSenderUID(d.conn, sender),
+		wait:   make(chan struct{}),
+		done:   d.done,
+	}
+	select {
+	case d.requests <- request:
+	case <-d.done:
+		return nil, dbus.NewError(Interface+".DoctorAborted", []any{"Doctor aborted"})
+	}
+
+	request.Wait()
+	if request.Error != nil {
+		return nil, dbus.NewError(Interface+".DoctorAborted", []any{request.errorMessage()})
+	}
+	problems := []Problem{}
+	if len(request.Results) > 0 {
+		if p, ok := request.Results[0].([]Problem); ok {
+			problems = p
+		}
+	}
+	return problems, nil
+}
+
+// ConnectDevice is the "ConnectDevice" method of the D-Bus interface, it
+// starts a VPN connect to server with the device authorization of the
+// daemon and returns the user code and verification URIs that the user
+// needs to approve the login on another device
+func (d daemon) ConnectDevice(sender dbus.Sender, profile, server string) (string, string, string, *dbus.Error) {
+	log.WithFields(log.Fields{
+		"sender":  sender,
+		"profile": profile,
+		"server":  server,
+	}).Debug("Received D-Bus ConnectDevice() call")
+	request := &Request{
+		Name:       RequestConnectDevice,
+		ID:         logging.NewRequestID(),
+		Parameters: []any{profile, server},
+		Sender:     string(sender),
+		UID:        getSenderUID(d.conn, sender),
+		wait:       make(chan struct{}),
+		done:       d.done,
+	}
+	select {
+	case d.requests <- request:
+	case <-d.done:
+		return "", "", "", dbus.NewError(Interface+".ConnectAborted", []any{"Connect aborted"})
+	}
+
+	request.Wait()
+	if request.Error != nil {
+		return "", "", "", connectError(request)
+	}
+	if len(request.Results) < 3 {
+		return "", "", "", dbus.NewError(Interface+".ConnectAborted", []any{"Invalid results"})
+	}
+	userCode, _ := request.Results[0].(string)
+	uri, _ := request.Results[1].(string)
+	uriComplete, _ := request.Results[2].(string)
+	return userCode, uri, uriComplete, nil
+}
+
+// SetSchedule is the "SetSchedule" method of the D-Bus interface, it
+// enables or disables the connection schedule of the daemon
+func (d daemon) SetSchedule(sender dbus.Sender, enabled bool) *dbus.Error {
+	log.WithFields(log.Fields{
+		"sender":  sender,
+		"enabled": enabled,
+	}).Debug("Received D-Bus SetSchedule() call")
+	request := &Request{
+		Name:       RequestSetSchedule,
+		ID:         logging.NewRequestID(),
+		Parameters: []any{enabled},
+		Sender:     string(sender),
+		UID:        getSenderUID(d.conn, sender),
+		wait:       make(chan struct{}),
+		done:       d.done,
+	}
+	select {
+	case d.requests <- request:
+	case <-d.done:
+		return dbus.NewError(Interface+".SetScheduleAborted", []any{"SetSchedule aborted"})
+	}
+
+	request.Wait()
+	if request.Error != nil {
+		return dbus.NewError(Interface+".SetScheduleAborted", []any{request.errorMessage()})
+	}
+	return nil
+}
+
+// SetTND is the "SetTND" method of the D-Bus interface, it enables or
+// disables the trusted network detection of the daemon; if timeout is not 0,
+// a disabled trusted network detection is enabled again after timeout
+// seconds
+func (d daemon) SetTND(sender dbus.Sender, enabled bool, timeout uint32) *dbus.Error {
+	log.WithFields(log.Fields{
+		"sender":  sender,
+		"enabled": enabled,
+		"timeout": timeout,
+	}).Debug("Received D-Bus SetTND() call")
+	request := &Request{
+		Name:       RequestSetTND,
+		ID:         logging.NewRequestID(),
+		Parameters: []any{enabled, timeout},
+		Sender:     string(sender),
+		UID:        getSenderUID(d.conn, sender),
+		wait:       make(chan struct{}),
+		done:       d.done,
+	}
+	select {
+	case d.requests <- request:
+	case <-d.done:
+		return dbus.NewError(Interface+".SetTNDAborted", []any{"SetTND aborted"})
+	}
+
+	request.Wait()
+	if request.Error != nil {
+		return dbus.NewError(Interface+".SetTNDAborted", []any{request.errorMessage()})
+	}
+	return nil
+}
+
+// SetTrafPol is the "SetTrafPol" method of the D-Bus interface, it enables
+// or disables the traffic policing of the daemon; if timeout is not 0, a
+// disabled traffic policing is enabled again after timeout seconds
+func (d daemon) SetTrafPol(sender dbus.Sender, enabled bool, timeout uint32) *dbus.Error {
+	log.WithFields(log.Fields{
+		"sender":  sender,
+		"enabled": enabled,
+		"timeout": timeout,
+	}).Debug("Received D-Bus SetTrafPol() call")
+	request := &Request{
+		Name:       RequestSetTrafPol,
+		ID:         logging.NewRequestID(),
+		Parameters: []any{enabled, timeout},
+		Sender:     string(sender),
+		UID:        getSenderUID(d.conn, sender),
+		wait:       make(chan struct{}),
+		done:       d.done,
+	}
+	select {
+	case d.requests <- request:
+	case <-d.done:
+		return dbus.NewError(Interface+".SetTrafPolAborted", []any{"SetTrafPol aborted"})
+	}
+
+	request.Wait()
+	if request.Error != nil {
+		return dbus.NewError(Interface+".SetTrafPolAborted", []any{request.errorMessage()})
+	}
+	return nil
+}
+
+// SetNotificationPolicy is the "SetNotificationPolicy" method of the D-Bus
+// interface, it sets the notification policy of the daemon to the JSON
+// policy until the next config reload; an empty policy resets it to the
+// daemon configuration
+func (d daemon) SetNotificationPolicy(sender dbus.Sender, policy string) *dbus.Error {
+	log.WithFields(log.Fields{
+		"sender": sender,
+		"policy": policy,
+	}).Debug("Received D-Bus SetNotificationPolicy() call")
+	request := &Request{
+		Name:       RequestSetNotificationPolicy,
+		ID:         logging.NewRequestID(),
+		Parameters: []any{policy},
+		Sender:     string(sender),
+		UID:        getSenderUID(d.conn, sender),
+		wait:       make(chan struct{}),
+		done:       d.done,
+	}
+	select {
+	case d.requests <- request:
+	case <-d.done:
+		return dbus.NewError(Interface+".SetNotificationPolicyAborted", []any{"SetNotificationPolicy aborted"})
+	}
+
+	request.Wait()
+	if request.Error != nil {
+		return dbus.NewError(Interface+".SetNotificationPolicyAborted", []any{request.errorMessage()})
+	}
+	return nil
+}
+
+// ReportHostscan is the "ReportHostscan" method of the D-Bus interface, it
+// reports that the CSD wrapper script ran during authentication, so the
+// daemon logs the hostscan report of the wrapper to the audit log
+func (d daemon) ReportHostscan(sender dbus.Sender) *dbus.Error {
+	log.WithField("sender", sender).Debug("Received D-Bus ReportHostscan() call")
+	request := &Request{
+		Name:   RequestReportHostscan,
+		ID:     logging.NewRequestID(),
+		Sender: string(sender),
+		UID:    getSenderUID(d.conn, sender),
+		wait:   make(chan struct{}),
+		done:   d.done,
+	}
+	select {
+	case d.requests <- request:
+	case <-d.done:
+		return dbus.NewError(Interface+".ReportHostscanAborted", []any{"ReportHostscan aborted"})
+	}
+
+	request.Wait()
+	if request.Error != nil {
+		return dbus.NewError(Interface+".ReportHostscanAborted", []any{request.errorMessage()})
+	}
+	return nil
+}
+
+// GetLogs is the "GetLogs" method of the D-Bus interface, it returns the
+// last lines entries of the recent daemon log, all recent entries if lines
+// is 0
+func (d daemon) GetLogs(sender dbus.Sender, lines uint32) ([]string, *dbus.Error) {
+	log.WithField("sender", sender).Debug("Received D-Bus GetLogs() call")
+	request := &Request{
+		Name:       RequestGetLogs,
+		ID:         logging.NewRequestID(),
+		Parameters: []any{lines},
+		Sender:     string(sender),
+		UID:        getSenderUID(d.conn, sender),
+		wait:       make(chan struct{}),
+		done:       d.done,
+	}
+	select {
+	case d.requests <- request:
+	case <-d.done:
+		return nil, dbus.NewError(Interface+".GetLogsAborted", []any{"GetLogs aborted"})
+	}
+
+	request.Wait()
+	if request.Error != nil {
+		return nil, dbus.NewError(Interface+".GetLogsAborted", []any{request.errorMessage()})
+	}
+	logs := []string{}
+	if len(request.Results) > 0 {
+		if l, ok := request.Results[0].([]string); ok {
+			logs = l
+		}
+	}
+	for i, l := range logs {
+		logs[i] = charset.ToUTF8(l)
+	}
+	return logs, nil
+}
+
+// ListServers is the "ListServers" method of the D-Bus interface, it
+// returns the VPN servers in the XML profile, they are probed for
+// reachability and latency if ping is set
+func (d daemon) ListServers(sender dbus.Sender, ping bool) ([]Server, *dbus.Error) {
+	log.WithField("sender", sender).Debug("Received D-Bus ListServers() call")
+	request := &Request{
+		Name:       RequestListServers,
+		ID:         logging.NewRequestID(),
+		Parameters: []any{ping},
+		Sender:     string(sender),
+		UID:        getSenderUID(d.conn, sender),
+		wait:       make(chan struct{}),
+		done:       d.done,
+	}
+	select {
+	case d.requests <- request:
+	case <-d.done:
+		return nil, dbus.NewError(Interface+".ListServersAborted", []any{"ListServers aborted"})
+	}
+
+	request.Wait()
+	if request.Error != nil {
+		return nil, dbus.NewError(Interface+".ListServersAborted", []any{request.errorMessage()})
+	}
+	servers := []Server{}
+	if len(request.Results) > 0 {
+		if s, ok := request.Results[0].([]Server); ok {
+			servers = s
+		}
+	}
+	return servers, nil
+}
+
+// GetUsage is the "GetUsage" method of the D-Bus interface, it returns the
+// VPN usage of the recent months in ascending order
+func (d daemon) GetUsage(sender dbus.Sender) ([]Usage, *dbus.Error) {
+	log.WithField("sender", sender).Debug("Received D-Bus GetUsage() call")
+	request := &Request{
+		Name:   RequestGetUsage,
+		ID:     logging.NewRequestID(),
+		Sender: string(sender),
+		UID:    getSenderUID(d.conn, sender),
+		wait:   make(chan struct{}),
+		done:   d.done,
+	}
+	select {
+	case d.requests <- request:
+	case <-d.done:
+		return nil, dbus.NewError(Interface+".GetUsageAborted", []any{"GetUsage aborted"})
+	}
+
+	request.Wait()
+	if request.Error != nil {
+		return nil, dbus.NewError(Interface+".GetUsageAborted", []any{request.errorMessage()})
+	}
+	usage := []Usage{}
+	if len(request.Results) > 0 {
+		if u, ok := request.Results[0].([]Usage); ok {
+			usage = u
+		}
+	}
+	return usage, nil
+}
+
+// GetMetrics is the "GetMetrics" method of the D-Bus interface, it returns
+// the metrics of the requests of all APIs of the daemon
+func (d daemon) GetMetrics(sender dbus.Sender) ([]RequestMetrics, *dbus.Error) {
+	log.WithField("sender", sender).Debug("Received D-Bus GetMetrics() call")
+	request := &Request{
+		Name:   RequestGetMetrics,
+		ID:     logging.NewRequestID(),
+		Sender: string(sender),
+		UID:    getSenderUID(d.conn, sender),
+		wait:   make(chan struct{}),
+		done:   d.done,
+	}
+	select {
+	case d.requests <- request:
+	case <-d.done:
+		return nil, dbus.NewError(Interface+".GetMetricsAborted", []any{"GetMetrics aborted"})
+	}
+
+	request.Wait()
+	if request.Error != nil {
+		return nil, dbus.NewError(Interface+".GetMetricsAborted", []any{request.errorMessage()})
+	}
+	metrics := []RequestMetrics{}
+	if len(request.Results) > 0 {
+		if m, ok := request.Results[0].([]RequestMetrics); ok {
+			metrics = m
+		}
+	}
+	return metrics, nil
+}
+
+// ValidateConfig is the "ValidateConfig" method of the D-Bus interface, it
+// validates the candidate daemon configuration or XML profile in data,
+// depending on kind, against the running daemon without applying it and
+// returns the findings
+func (d daemon) ValidateConfig(sender dbus.Sender, kind, data string) ([]Finding, *dbus.Error) {
+	log.WithField("sender", sender).Debug("Received D-Bus ValidateConfig() call")
+	request := &Request{
+		Name:       RequestValidateConfig,
+		ID:         logging.NewRequestID(),
+		Parameters: []any{kind, data},
+		Sender:     string(sender),
+		UID:        getSenderUID(d.conn, sender),
+		wait:       make(chan struct{}),
+		done:       d.done,
+	}
+	select {
+	case d.requests <- request:
+	case <-d.done:
+		return nil, dbus.NewError(Interface+".ValidateConfigAborted", []any{"ValidateConfig aborted"})
+	}
+
+	request.Wait()
+	if request.Error != nil {
+		return nil, dbus.NewError(Interface+".ValidateConfigAborted", []any{request.errorMessage()})
+	}
+	findings := []Finding{}
+	if len(request.Results) > 0 {
+		if f, ok := request.Results[0].([]Finding); ok {
+			findings = f
+		}
+	}
+	return findings, nil
+}
+
+// SetPreferredServer is the "SetPreferredServer" method of the D-Bus
+// interface, it sets the preferred VPN server in the XML profile to the host
+// name or address server, an empty server resets it
+func (d daemon) SetPreferredServer(sender dbus.Sender, server string) *dbus.Error {
+	log.WithField("sender", sender).Debug("Received D-Bus SetPreferredServer() call")
+	request := &Request{
+		Name:       RequestSetPreferredServer,
+		ID:         logging.NewRequestID(),
+		Parameters: []any{server},
+		Sender:     string(sender),
+		UID:        getSenderUID(d.conn, sender),
+		wait:       make(chan struct{}),
+		done:       d.done,
+	}
+	select {
+	case d.requests <- request:
+	case <-d.done:
+		return dbus.NewError(Interface+".SetPreferredServerAborted", []any{"SetPreferredServer aborted"})
+	}
+
+	request.Wait()
+	if request.Error != nil {
+		return dbus.NewError(Interface+".SetPreferredServerAborted", []any{request.errorMessage()})
+	}
+	return nil
+}
+
+// AddSplitExclude is the "AddSplitExclude" method of the D-Bus interface,
+// it adds the IP address or network address to the split excludes of the
+// current VPN connection
+func (d daemon) AddSplitExclude(sender dbus.Sender, address string) *dbus.Error {
+	log.WithFields(log.Fields{
+		"sender":  sender,
+		"address": address,
+	}).Debug("Received D-Bus AddSplitExclude() call")
+	_, err := d.splitExcludes(sender, RequestAddSplitExclude, []any{address})
+	return err
+}
+
+// RemoveSplitExclude is the "RemoveSplitExclude" method of the D-Bus
+// interface, it removes the address added with "AddSplitExclude" from the
+// split excludes of the current VPN connection
+func (d daemon) RemoveSplitExclude(sender dbus.Sender, address string) *dbus.Error {
+	log.WithFields(log.Fields{
+		"sender":  sender,
+		"address": address,
+	}).Debug("Received D-Bus RemoveSplitExclude() call")
+	_, err := d.splitExcludes(sender, RequestRemoveSplitExclude, []any{address})
+	return err
+}
+
+// ListSplitExcludes is the "ListSplitExcludes" method of the D-Bus
+// interface, it returns the addresses added with "AddSplitExclude" to the
+// split excludes of the current VPN connection
+func (d daemon) ListSplitExcludes(sender dbus.Sender) ([]string, *dbus.Error) {
+	log.WithField("sender", sender).Debug("Received D-Bus ListSplitExcludes() call")
+	return d.splitExcludes(sender, RequestListSplitExcludes, nil)
 }
 
-// Connect is the "Connect" method of the D-Bus interface
-func (d daemon) Connect(sender dbus.Sender, cookie, host, connectURL, fingerprint, resolve string) *dbus.Error {
-	log.WithField("sender", sender).Debug("Received D-Bus Connect() call")
+// splitExcludes sends a split excludes request with name and parameters to
+// the daemon and returns the split excludes in the results
+func (d daemon) splitExcludes(sender dbus.Sender, name string, parameters []any) ([]string, *dbus.Error) {
 	request := &Request{
-		Name:       RequestConnect,
-		Parameters: []any{cookie, host, connectURL, fingerprint, resolve},
+		Name:       name,
+		ID:         logging.NewRequestID(),
+		Parameters: parameters,
+		Sender:     string(sender),
+		UID:        getSenderUID(d.conn, sender),
 		wait:       make(chan struct{}),
 		done:       d.done,
 	}
 	select {
 	case d.requests <- request:
 	case <-d.done:
-		return dbus.NewError(Interface+".ConnectAborted", []any{"Connect aborted"})
+		return nil, dbus.NewError(Interface+"."+name+"Aborted", []any{name + " aborted"})
 	}
 
 	request.Wait()
 	if request.Error != nil {
-		return dbus.NewError(Interface+".ConnectAborted", []any{request.Error.Error()})
+		return nil, dbus.NewError(Interface+"."+name+"Aborted", []any{request.errorMessage()})
 	}
-	return nil
+	excludes := []string{}
+	if len(request.Results) > 0 {
+		if e, ok := request.Results[0].([]string); ok {
+			excludes = e
+		}
+	}
+	return excludes, nil
 }
 
-// Disconnect is the "Disconnect" method of the D-Bus interface
-func (d daemon) Disconnect(sender dbus.Sender) *dbus.Error {
-	log.WithField("sender", sender).Debug("Received D-Bus Connect() call")
+// ReloadProfile is the "ReloadProfile" method of the D-Bus interface, it
+// makes the daemon read the XML profile again immediately and returns an
+// error if the profile cannot be parsed
+func (d daemon) ReloadProfile(sender dbus.Sender) *dbus.Error {
+	log.WithField("sender", sender).Debug("Received D-Bus ReloadProfile() call")
 	request := &Request{
-		Name: RequestDisconnect,
-		wait: make(chan struct{}),
-		done: d.done,
+		Name:   RequestReloadProfile,
+		ID:     logging.NewRequestID(),
+		Sender: string(sender),
+		UID:    getSenderUID(d.conn, sender),
+		wait:   make(chan struct{}),
+		done:   d.done,
 	}
 	select {
 	case d.requests <- request:
 	case <-d.done:
-		return dbus.NewError(Interface+".DisconnectAborted", []any{"Disconnect aborted"})
+		return dbus.NewError(Interface+".ReloadProfileAborted", []any{"ReloadProfile aborted"})
 	}
 
 	request.Wait()
 	if request.Error != nil {
-		return dbus.NewError(Interface+".DisconnectAborted", []any{request.Error.Error()})
+		return dbus.NewError(Interface+".ReloadProfileAborted", []any{request.errorMessage()})
 	}
 	return nil
 }
 
-// propertyUpdate is an update of a property
+// GetStatus is the "GetStatus" method of the D-Bus interface, it returns
+// the complete VPN status of the daemon as JSON, so clients get a
+// consistent snapshot of all properties in one call
+func (d daemon) GetStatus(sender dbus.Sender) (string, *dbus.Error) {
+	log.WithField("sender", sender).Debug("Received D-Bus GetStatus() call")
+	request := &Request{
+		Name:   RequestGetStatus,
+		ID:     logging.NewRequestID(),
+		Sender: string(sender),
+		UID:    getSenderUID(d.conn, sender),
+		wait:   make(chan struct{}),
+		done:   d.done,
+	}
+	select {
+	case d.requests <- request:
+	case <-d.done:
+		return "", dbus.NewError(Interface+".GetStatusAborted", []any{"GetStatus aborted"})
+	}
+
+	request.Wait()
+	if request.Error != nil {
+		return "", dbus.NewError(Interface+".GetStatusAborted", []any{request.errorMessage()})
+	}
+	status := ""
+	if len(request.Results) > 0 {
+		if s, ok := request.Results[0].(string); ok {
+			status = s
+		}
+	}
+	return status, nil
+}
+
+// propertyUpdate is an update of a property, of an additional tunnel if
+// tunnel is not empty
 type propertyUpdate struct {
-	name  string
-	value any
+	tunnel string
+	name   string
+	value  any
+}
+
+// signal is a D-Bus signal emitted by the service
+type signal struct {
+	name   string
+	values []any
 }
 
 // Service is a D-Bus Service
 type Service struct {
 	requests chan *Request
 	propUps  chan *propertyUpdate
-	done     chan struct{}
+	signals  chan *signal
+	done     <-chan struct{}
+	cancel   context.CancelFunc
 	closed   chan struct{}
+
+	// tunnels are the names of the additional tunnels
+	tunnels []string
 }
 
 // dbusConn is an interface for dbus.Conn to allow for testing
@@ -180,6 +1388,7 @@ type dbusConn interface {
 	Close() error
 	Export(v any, path dbus.ObjectPath, iface string) error
 	RequestName(name string, flags dbus.RequestNameFlags) (dbus.RequestNameReply, error)
+	Emit(path dbus.ObjectPath, name string, values ...any) error
 }
 
 // dbusConnectSystemBus encapsulates dbus.ConnectSystemBus to allow for testing
@@ -189,6 +1398,7 @@ var dbusConnectSystemBus = func(opts ...dbus.ConnOption) (dbusConn, error) {
 
 // propProperties is an interface for prop.Properties to allow for testing
 type propProperties interface {
+	GetAll(iface string) (map[string]dbus.Variant, *dbus.Error)
 	Introspection(iface string) []introspect.Property
 	SetMust(iface, property string, v any)
 }
@@ -198,36 +1408,296 @@ var propExport = func(conn dbusConn, path dbus.ObjectPath, props prop.Map) (prop
 	return prop.Export(conn.(*dbus.Conn), path, props)
 }
 
-// start starts the service
-func (s *Service) start() {
-	defer close(s.closed)
+// NameHasOwner returns whether the D-Bus name of the service is already
+// owned on the system bus, e.g., by another daemon instance
+func NameHasOwner() (bool, error) {
+	return SystemBusNameHasOwner(Interface)
+}
 
-	// connect to session bus
-	conn, err := dbusConnectSystemBus()
+// SystemBusNameHasOwner returns whether the D-Bus name is owned on the
+// system bus, e.g., by a running service
+func SystemBusNameHasOwner(name string) (bool, error) {
+	conn, err := dbus.ConnectSystemBus()
 	if err != nil {
-		log.WithError(err).Fatal("Could not connect to D-Bus session bus")
+		return false, err
 	}
 	defer func() { _ = conn.Close() }()
 
+	owned := false
+	err = conn.BusObject().Call("org.freedesktop.DBus.NameHasOwner", 0,
+		name).Store(&owned)
+	return owned, err
+}
+
+// LockSessions locks all sessions with logind, screen lockers of the
+// sessions handle the lock request
+func LockSessions() error {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	return conn.Object("org.freedesktop.login1", "/org/freedesktop/login1").
+		Call("org.freedesktop.login1.Manager.LockSessions", 0).Err
+}
+
+// tunnelPropsSpec returns the properties spec of additional tunnels with
+// iface
+func tunnelPropsSpec(iface string) prop.Map {
+	props := prop.Map{iface: {}}
+	for _, name := range TunnelProperties {
+		props[iface][name] = &prop.Prop{
+			Value:    invalidProperties[name],
+			Writable: false,
+			Emit:     prop.EmitTrue,
+			Callback: nil,
+		}
+	}
+	return props
+}
+
+// connectionPropsSpec returns the properties spec of the VPN connection
+// with name
+func connectionPropsSpec(name string) prop.Map {
+	props := tunnelPropsSpec(ConnectionInterface)
+	props[ConnectionInterface][PropertyName] = &prop.Prop{
+		Value:    name,
+		Writable: false,
+		Emit:     prop.EmitConst,
+		Callback: nil,
+	}
+	return props
+}
+
+// invalidProperties are the invalid values of the tunnel properties
+var invalidProperties = map[string]any{
+	PropertyConnectionState: ConnectionStateUnknown,
+	PropertyIP:              IPInvalid,
+	PropertyDevice:          DeviceInvalid,
+	PropertyConnectedAt:     ConnectedAtInvalid,
+	PropertyOCRunning:       OCRunningUnknown,
+	PropertyVPNConfig:       VPNConfigInvalid,
+}
+
+// exportTunnels exports the properties and introspection of the additional
+// tunnels
+func (s *Service) exportTunnels(conn dbusConn) (map[string]propProperties, error) {
+	tunnels := make(map[string]propProperties)
+	for _, tunnel := range s.tunnels {
+		path := TunnelPath(tunnel)
+		props, err := propExport(conn, path, tunnelPropsSpec(Interface))
+		if err != nil {
+			return nil, fmt.Errorf("Could not export D-Bus properties of tunnel %s: %w",
+				tunnel, err)
+		}
+		n := &introspect.Node{
+			Name: string(path),
+			Interfaces: []introspect.Interface{
+				introspect.IntrospectData,
+				prop.IntrospectData,
+				{
+					Name:       Interface,
+					Properties: props.Introspection(Interface),
+				},
+			},
+		}
+		err = conn.Export(introspect.NewIntrospectable(n), path,
+			"org.freedesktop.DBus.Introspectable")
+		if err != nil {
+			return nil, fmt.Errorf("Could not export D-Bus introspection of tunnel %s: %w",
+				tunnel, err)
+		}
+		tunnels[tunnel] = props
+	}
+	if len(s.tunnels) == 0 {
+		return tunnels, nil
+	}
+
+	// introspection of the tunnels parent object
+	path := dbus.ObjectPath(Path + "/Tunnels")
+	n := &introspect.Node{
+		Name:       string(path),
+		Interfaces: []introspect.Interface{introspect.IntrospectData},
+		Children:   childNodes(s.tunnels...),
+	}
+	err := conn.Export(introspect.NewIntrospectable(n), path,
+		"org.freedesktop.DBus.Introspectable")
+	if err != nil {
+		return nil, fmt.Errorf("Could not export D-Bus introspection of tunnels: %w",
+			err)
+	}
+	return tunnels, nil
+}
+
+// objectManager implements org.freedesktop.DBus.ObjectManager for the
+// objects of the daemon below RootPath
+type objectManager struct {
+	// objects are the properties of the managed objects by object path
+	// and interface
+	objects map[dbus.ObjectPath]map[string]propProperties
+}
+
+// add adds the object at path with the properties of iface
+func (o *objectManager) add(path dbus.ObjectPath, iface string, props propProperties) {
+	if o.objects == nil {
+		o.objects = make(map[dbus.ObjectPath]map[string]propProperties)
+	}
+	if o.objects[path] == nil {
+		o.objects[path] = make(map[string]propProperties)
+	}
+	o.objects[path][iface] = props
+}
+
+// GetManagedObjects is the D-Bus method call that returns the daemon object,
+// the tunnel objects and the VPN connection objects with their interfaces
+// and properties
+func (o *objectManager) GetManagedObjects() (map[dbus.ObjectPath]map[string]map[string]dbus.Variant, *dbus.Error) {
+	objects := make(map[dbus.ObjectPath]map[string]map[string]dbus.Variant)
+	for path, ifaces := range o.objects {
+		objects[path] = make(map[string]map[string]dbus.Variant)
+		for iface, props := range ifaces {
+			values, err := props.GetAll(iface)
+			if err != nil {
+				return nil, err
+			}
+			objects[path][iface] = values
+		}
+	}
+	return objects, nil
+}
+
+// exportConnections exports the properties and introspection of the VPN
+// connection objects, it returns the properties of the connections by
+// tunnel name
+func (s *Service) exportConnections(conn dbusConn) (map[string]propProperties, error) {
+	connections := make(map[string]propProperties)
+	children := []introspect.Node{}
+	for n, name := range append([]string{NameDefault}, s.tunnels...) {
+		path := ConnectionPath(n)
+		props, err := propExport(conn, path, connectionPropsSpec(name))
+		if err != nil {
+			return nil, fmt.Errorf("Could not export D-Bus properties of connection %d: %w",
+				n, err)
+		}
+		node := &introspect.Node{
+			Name: string(path),
+			Interfaces: []introspect.Interface{
+				introspect.IntrospectData,
+				prop.IntrospectData,
+				{
+					Name:       ConnectionInterface,
+					Properties: props.Introspection(ConnectionInterface),
+				},
+			},
+		}
+		err = conn.Export(introspect.NewIntrospectable(node), path,
+			"org.freedesktop.DBus.Introspectable")
+		if err != nil {
+			return nil, fmt.Errorf("Could not export D-Bus introspection of connection %d: %w",
+				n, err)
+		}
+		connections[name] = props
+		children = append(children, introspect.Node{
+			Name: fmt.Sprintf("%d", n),
+		})
+	}
+
+	// introspection of the connections parent object
+	path := dbus.ObjectPath(RootPath + "/Connections")
+	n := &introspect.Node{
+		Name:       string(path),
+		Interfaces: []introspect.Interface{introspect.IntrospectData},
+		Children:   children,
+	}
+	err := conn.Export(introspect.NewIntrospectable(n), path,
+		"org.freedesktop.DBus.Introspectable")
+	if err != nil {
+		return nil, fmt.Errorf("Could not export D-Bus introspection of connections: %w",
+			err)
+	}
+	return connections, nil
+}
+
+// exportObjectManager exports the ObjectManager with the daemon object
+// props, the tunnel objects and the VPN connection objects at RootPath
+func (s *Service) exportObjectManager(conn dbusConn, props propProperties, tunnels, connections map[string]propProperties) error {
+	om := &objectManager{}
+	om.add(Path, Interface, props)
+	for name, p := range tunnels {
+		om.add(TunnelPath(name), Interface, p)
+	}
+	for n, name := range append([]string{NameDefault}, s.tunnels...) {
+		om.add(ConnectionPath(n), ConnectionInterface, connections[name])
+	}
+
+	if err := conn.Export(om, RootPath, ObjectManagerInterface); err != nil {
+		return fmt.Errorf("Could not export D-Bus object manager: %w", err)
+	}
+	n := &introspect.Node{
+		Name: RootPath,
+		Interfaces: []introspect.Interface{
+			introspect.IntrospectData,
+			{
+				Name:    ObjectManagerInterface,
+				Methods: introspectMethods(om),
+				Signals: objectManagerSignals,
+			},
+		},
+		Children: childNodes("Daemon", "Connections"),
+	}
+	err := conn.Export(introspect.NewIntrospectable(n), RootPath,
+		"org.freedesktop.DBus.Introspectable")
+	if err != nil {
+		return fmt.Errorf("Could not export D-Bus introspection of object manager: %w",
+			err)
+	}
+	return nil
+}
+
+// export connects to the system bus, requests the service name and exports
+// the methods, properties and introspection of the service
+func (s *Service) export() (dbusConn, propProperties, error) {
+	// connect to system bus
+	conn, err := dbusConnectSystemBus()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Could not connect to D-Bus system bus: %w", err)
+	}
+
 	// request name
 	reply, err := conn.RequestName(Interface, dbus.NameFlagDoNotQueue)
 	if err != nil {
-		log.WithError(err).Fatal("Could not request D-Bus name")
+		_ = conn.Close()
+		return nil, nil, fmt.Errorf("Could not request D-Bus name: %w", err)
 	}
 	if reply != dbus.RequestNameReplyPrimaryOwner {
-		log.Fatal("Requested D-Bus name is already taken")
+		_ = conn.Close()
+		return nil, nil, errors.New("Requested D-Bus name is already taken")
 	}
 
 	// methods
-	meths := daemon{s.requests, s.done}
+	meths := daemon{conn, s.requests, s.done}
 	err = conn.Export(meths, Path, Interface)
 	if err != nil {
-		log.WithError(err).Fatal("Could not export D-Bus methods")
+		_ = conn.Close()
+		return nil, nil, fmt.Errorf("Could not export D-Bus methods: %w", err)
 	}
 
 	// properties
 	propsSpec := prop.Map{
 		Interface: {
+			PropertyVersion: {
+				Value:    APIVersion,
+				Writable: false,
+				Emit:     prop.EmitConst,
+				Callback: nil,
+			},
+			PropertyCapabilities: {
+				Value:    CapabilitiesInvalid,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
 			PropertyTrustedNetwork: {
 				Value:    TrustedNetworkUnknown,
 				Writable: false,
@@ -276,11 +1746,168 @@ func (s *Service) start() {
 				Emit:     prop.EmitTrue,
 				Callback: nil,
 			},
+			PropertyProxy: {
+				Value:    ProxyInvalid,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
+			PropertyRetryAt: {
+				Value:    RetryAtInvalid,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
+			PropertyRetryAttempt: {
+				Value:    RetryAttemptInvalid,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
+			PropertyRXBytes: {
+				Value:    TrafficStatsInvalid,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
+			PropertyTXBytes: {
+				Value:    TrafficStatsInvalid,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
+			PropertyRXPackets: {
+				Value:    TrafficStatsInvalid,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
+			PropertyTXPackets: {
+				Value:    TrafficStatsInvalid,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
+			PropertyDNSLeaksBlocked: {
+				Value:    DNSLeaksBlockedInvalid,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
+			PropertyDNSServers: {
+				Value:    DNSDomainsInvalid,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
+			PropertyDNSSearchDomains: {
+				Value:    DNSDomainsInvalid,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
+			PropertyDNSSplitDomains: {
+				Value:    DNSDomainsInvalid,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
+			PropertyScheduleState: {
+				Value:    ScheduleStateUnknown,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
+			PropertyConnectivity: {
+				Value:    ConnectivityUnknown,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
+			PropertyCompression: {
+				Value:    CompressionInvalid,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
+			PropertyTNDState: {
+				Value:    TNDStateUnknown,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
+			PropertyCSDWrapper: {
+				Value:    CSDWrapperInvalid,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
+			PropertyDisconnectReason: {
+				Value:    DisconnectReasonInvalid,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
+			PropertyAPIs: {
+				Value:    APIsInvalid,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
+			PropertyRouteAggregations: {
+				Value:    RouteAggregationsInvalid,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
+			PropertyGatewayExcludes: {
+				Value:    GatewayExcludesInvalid,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
+			PropertyTrafPolState: {
+				Value:    TrafPolStateUnknown,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
+			PropertyPreferredServer: {
+				Value:    PreferredServerUnset,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
+			PropertyUptime: {
+				Value:    UptimeInvalid,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
+			PropertyOwnerUID: {
+				Value:    OwnerUIDInvalid,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
+			PropertyOwner: {
+				Value:    OwnerInvalid,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
+			PropertyPauseState: {
+				Value:    PauseStateUnknown,
+				Writable: false,
+				Emit:     prop.EmitTrue,
+				Callback: nil,
+			},
 		},
 	}
 	props, err := propExport(conn, Path, propsSpec)
 	if err != nil {
-		log.WithError(err).Fatal("Could not export D-Bus properties spec")
+		_ = conn.Close()
+		return nil, nil, fmt.Errorf("Could not export D-Bus properties spec: %w", err)
 	}
 
 	// introspection
@@ -291,16 +1918,54 @@ func (s *Service) start() {
 			prop.IntrospectData,
 			{
 				Name:       Interface,
-				Methods:    introspect.Methods(meths),
+				Methods:    introspectMethods(meths),
 				Properties: props.Introspection(Interface),
+				Signals:    daemonSignals,
 			},
 		},
 	}
+	if len(s.tunnels) > 0 {
+		n.Children = childNodes("Tunnels")
+	}
 	err = conn.Export(introspect.NewIntrospectable(n), Path,
 		"org.freedesktop.DBus.Introspectable")
 	if err != nil {
-		log.WithError(err).Fatal("Could not export D-Bus introspection")
+		_ = conn.Close()
+		return nil, nil, fmt.Errorf("Could not export D-Bus introspection: %w", err)
+	}
+
+	return conn, props, nil
+}
+
+// setTunnelProperties sets the properties of iface of all additional tunnels
+// or connections to the values returned by value
+func setTunnelProperties(tunnels map[string]propProperties, iface string, value func(name string) any) {
+	for _, props := range tunnels {
+		for _, name := range TunnelProperties {
+			props.SetMust(iface, name, value(name))
+		}
 	}
+}
+
+// setConnectionProperty sets the property with name of the VPN connection
+// of tunnel to value, if it is a property of the connection objects
+func setConnectionProperty(connections map[string]propProperties, tunnel, name string, value any) {
+	props, ok := connections[tunnel]
+	if !ok {
+		return
+	}
+	for _, n := range TunnelProperties {
+		if n == name {
+			props.SetMust(ConnectionInterface, name, value)
+			return
+		}
+	}
+}
+
+// start starts the service
+func (s *Service) start(conn dbusConn, props propProperties, tunnels, connections map[string]propProperties) {
+	defer close(s.closed)
+	defer func() { _ = conn.Close() }()
 
 	// set properties values to emit properties changed signal and make
 	// sure existing clients get updated values after restart
@@ -312,6 +1977,44 @@ func (s *Service) start() {
 	props.SetMust(Interface, PropertyServers, ServersInvalid)
 	props.SetMust(Interface, PropertyOCRunning, OCRunningNotRunning)
 	props.SetMust(Interface, PropertyVPNConfig, VPNConfigInvalid)
+	props.SetMust(Interface, PropertyProxy, ProxyInvalid)
+	props.SetMust(Interface, PropertyRetryAt, RetryAtInvalid)
+	props.SetMust(Interface, PropertyRetryAttempt, RetryAttemptInvalid)
+	props.SetMust(Interface, PropertyRXBytes, TrafficStatsInvalid)
+	props.SetMust(Interface, PropertyTXBytes, TrafficStatsInvalid)
+	props.SetMust(Interface, PropertyRXPackets, TrafficStatsInvalid)
+	props.SetMust(Interface, PropertyTXPackets, TrafficStatsInvalid)
+	props.SetMust(Interface, PropertyDNSLeaksBlocked, DNSLeaksBlockedInvalid)
+	props.SetMust(Interface, PropertyDNSServers, DNSDomainsInvalid)
+	props.SetMust(Interface, PropertyDNSSearchDomains, DNSDomainsInvalid)
+	props.SetMust(Interface, PropertyDNSSplitDomains, DNSDomainsInvalid)
+	props.SetMust(Interface, PropertyScheduleState, ScheduleStateUnknown)
+	props.SetMust(Interface, PropertyConnectivity, ConnectivityUnknown)
+	props.SetMust(Interface, PropertyCompression, CompressionInvalid)
+	props.SetMust(Interface, PropertyTNDState, TNDStateUnknown)
+	props.SetMust(Interface, PropertyCSDWrapper, CSDWrapperInvalid)
+	props.SetMust(Interface, PropertyDisconnectReason, DisconnectReasonInvalid)
+	props.SetMust(Interface, PropertyAPIs, APIsInvalid)
+	props.SetMust(Interface, PropertyRouteAggregations, RouteAggregationsInvalid)
+	props.SetMust(Interface, PropertyGatewayExcludes, GatewayExcludesInvalid)
+	props.SetMust(Interface, PropertyTrafPolState, TrafPolStateUnknown)
+	props.SetMust(Interface, PropertyPreferredServer, PreferredServerUnset)
+	props.SetMust(Interface, PropertyCapabilities, CapabilitiesInvalid)
+	props.SetMust(Interface, PropertyUptime, UptimeInvalid)
+	props.SetMust(Interface, PropertyOwnerUID, OwnerUIDInvalid)
+	props.SetMust(Interface, PropertyOwner, OwnerInvalid)
+	props.SetMust(Interface, PropertyPauseState, PauseStateNotPaused)
+	started := func(name string) any {
+		switch name {
+		case PropertyConnectionState:
+			return ConnectionStateDisconnected
+		case PropertyOCRunning:
+			return OCRunningNotRunning
+		}
+		return invalidProperties[name]
+	}
+	setTunnelProperties(tunnels, Interface, started)
+	setTunnelProperties(connections, ConnectionInterface, started)
 
 	// main loop
 	for {
@@ -319,10 +2022,25 @@ func (s *Service) start() {
 		case u := <-s.propUps:
 			// update property
 			log.WithFields(log.Fields{
-				"name":  u.name,
-				"value": u.value,
+				"tunnel": u.tunnel,
+				"name":   u.name,
+				"value":  u.value,
 			}).Debug("D-Bus updating property")
-			props.SetMust(Interface, u.name, u.value)
+			setConnectionProperty(connections, u.tunnel, u.name, u.value)
+			if u.tunnel == "" {
+				props.SetMust(Interface, u.name, u.value)
+				break
+			}
+			if t, ok := tunnels[u.tunnel]; ok {
+				t.SetMust(Interface, u.name, u.value)
+			}
+
+		case sig := <-s.signals:
+			// emit signal
+			log.WithField("name", sig.name).Debug("D-Bus emitting signal")
+			if err := conn.Emit(Path, sig.name, sig.values...); err != nil {
+				log.WithError(err).Error("D-Bus could not emit signal")
+			}
 
 		case <-s.done:
 			log.Debug("D-Bus service stopping")
@@ -336,19 +2054,78 @@ func (s *Service) start() {
 			props.SetMust(Interface, PropertyServers, ServersInvalid)
 			props.SetMust(Interface, PropertyOCRunning, OCRunningUnknown)
 			props.SetMust(Interface, PropertyVPNConfig, VPNConfigInvalid)
+			props.SetMust(Interface, PropertyProxy, ProxyInvalid)
+			props.SetMust(Interface, PropertyRetryAt, RetryAtInvalid)
+			props.SetMust(Interface, PropertyRetryAttempt, RetryAttemptInvalid)
+			props.SetMust(Interface, PropertyRXBytes, TrafficStatsInvalid)
+			props.SetMust(Interface, PropertyTXBytes, TrafficStatsInvalid)
+			props.SetMust(Interface, PropertyRXPackets, TrafficStatsInvalid)
+			props.SetMust(Interface, PropertyTXPackets, TrafficStatsInvalid)
+			props.SetMust(Interface, PropertyDNSLeaksBlocked, DNSLeaksBlockedInvalid)
+			props.SetMust(Interface, PropertyDNSServers, DNSDomainsInvalid)
+			props.SetMust(Interface, PropertyDNSSearchDomains, DNSDomainsInvalid)
+			props.SetMust(Interface, PropertyDNSSplitDomains, DNSDomainsInvalid)
+			props.SetMust(Interface, PropertyScheduleState, ScheduleStateUnknown)
+			props.SetMust(Interface, PropertyConnectivity, ConnectivityUnknown)
+			props.SetMust(Interface, PropertyCompression, CompressionInvalid)
+			props.SetMust(Interface, PropertyTNDState, TNDStateUnknown)
+			props.SetMust(Interface, PropertyCSDWrapper, CSDWrapperInvalid)
+			props.SetMust(Interface, PropertyDisconnectReason, DisconnectReasonInvalid)
+			props.SetMust(Interface, PropertyAPIs, APIsInvalid)
+			props.SetMust(Interface, PropertyRouteAggregations, RouteAggregationsInvalid)
+			props.SetMust(Interface, PropertyGatewayExcludes, GatewayExcludesInvalid)
+			props.SetMust(Interface, PropertyTrafPolState, TrafPolStateUnknown)
+			props.SetMust(Interface, PropertyPreferredServer, PreferredServerUnset)
+			props.SetMust(Interface, PropertyCapabilities, CapabilitiesInvalid)
+			props.SetMust(Interface, PropertyUptime, UptimeInvalid)
+			props.SetMust(Interface, PropertyOwnerUID, OwnerUIDInvalid)
+			props.SetMust(Interface, PropertyOwner, OwnerInvalid)
+			props.SetMust(Interface, PropertyPauseState, PauseStateUnknown)
+			stopped := func(name string) any {
+				return invalidProperties[name]
+			}
+			setTunnelProperties(tunnels, Interface, stopped)
+			setTunnelProperties(connections, ConnectionInterface, stopped)
 			return
 		}
 	}
 }
 
-// Start starts the service
-func (s *Service) Start() {
-	go s.start()
+// Start starts the service, it runs until Stop is called or ctx is canceled
+func (s *Service) Start(ctx context.Context) error {
+	// cancel ctx on errors, so property updates do not block
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = ctx.Done()
+	conn, props, err := s.export()
+	if err != nil {
+		s.cancel()
+		return err
+	}
+	tunnels, err := s.exportTunnels(conn)
+	if err != nil {
+		_ = conn.Close()
+		s.cancel()
+		return err
+	}
+	connections, err := s.exportConnections(conn)
+	if err != nil {
+		_ = conn.Close()
+		s.cancel()
+		return err
+	}
+	if err := s.exportObjectManager(conn, props, tunnels, connections); err != nil {
+		_ = conn.Close()
+		s.cancel()
+		return err
+	}
+
+	go s.start(conn, props, tunnels, connections)
+	return nil
 }
 
 // Stop stops the service
 func (s *Service) Stop() {
-	close(s.done)
+	s.cancel()
 	<-s.closed
 }
 
@@ -359,8 +2136,29 @@ func (s *Service) Requests() chan *Request {
 
 // SetProperty sets property with name to value
 func (s *Service) SetProperty(name string, value any) {
+	s.SetTunnelProperty("", name, value)
+}
+
+// AddTunnel adds the additional tunnel with name, its properties are exported
+// at TunnelPath(name) and ConnectionPath with the number of the tunnel;
+// tunnels must be added before Start
+func (s *Service) AddTunnel(name string) {
+	s.tunnels = append(s.tunnels, name)
+}
+
+// SetTunnelProperty sets property with name of the additional tunnel to
+// value, an empty tunnel sets the property of the daemon
+func (s *Service) SetTunnelProperty(tunnel, name string, value any) {
+	select {
+	case s.propUps <- &propertyUpdate{tunnel, name, value}:
+	case <-s.done:
+	}
+}
+
+// EmitSignal emits the signal with name and values
+func (s *Service) EmitSignal(name string, values ...any) {
 	select {
-	case s.propUps <- &propertyUpdate{name, value}:
+	case s.signals <- &signal{name, values}:
 	case <-s.done:
 	}
 }
@@ -370,7 +2168,7 @@ func NewService() *Service {
 	return &Service{
 		requests: make(chan *Request),
 		propUps:  make(chan *propertyUpdate),
-		done:     make(chan struct{}),
+		signals:  make(chan *signal),
 		closed:   make(chan struct{}),
 	}
 }
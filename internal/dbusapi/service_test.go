@@ -1,8 +1,12 @@
 package dbusapi
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/godbus/dbus/v5"
 	"github.com/godbus/dbus/v5/introspect"
@@ -50,7 +54,9 @@ func TestDaemonConnect(t *testing.T) {
 		"cookie", "host", "connectURL", "fingerprint", "resolve"
 	want := &Request{
 		Name:       RequestConnect,
-		Parameters: []any{cookie, host, connectURL, fingerprint, resolve},
+		Parameters: []any{cookie, host, connectURL, fingerprint, resolve, ""},
+		Sender:     "sender",
+		UID:        UIDUnknown,
 		done:       done,
 	}
 	got := &Request{}
@@ -69,6 +75,150 @@ func TestDaemonConnect(t *testing.T) {
 		!reflect.DeepEqual(got.Parameters, want.Parameters) ||
 		!reflect.DeepEqual(got.Results, want.Results) ||
 		got.Error != want.Error ||
+		got.Sender != want.Sender ||
+		got.UID != want.UID ||
+		got.done != want.done {
+		// not equal
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestDaemonConnectTooManyRequests tests Connect of daemon, rejected by rate
+// limiting
+func TestDaemonConnectTooManyRequests(t *testing.T) {
+	requests := make(chan *Request)
+	daemon := daemon{
+		requests: requests,
+		done:     make(chan struct{}),
+	}
+	id := ""
+	go func() {
+		r := <-requests
+		id = r.ID
+		r.Error = fmt.Errorf("rejected: %w",
+			&TooManyRequestsError{RetryAfter: 1500 * time.Millisecond})
+		r.Close()
+	}()
+
+	err := daemon.Connect("sender", "", "", "", "", "")
+	want := dbus.NewError(ErrorTooManyRequests, []any{
+		"rejected: too many requests, retry after 2s (request " + id + ")",
+		uint32(2),
+	})
+	if !reflect.DeepEqual(err, want) {
+		t.Errorf("got %v, want %v", err, want)
+	}
+}
+
+// TestConnectError tests connectError
+func TestConnectError(t *testing.T) {
+	for _, test := range []struct {
+		err  error
+		want *dbus.Error
+	}{
+		{errors.New("test error"),
+			dbus.NewError(Interface+".ConnectAborted",
+				[]any{"test error (request 1234abcd)"})},
+		{errors.New("Zugriff verweigert: Gr\xfc\xdfe"),
+			dbus.NewError(Interface+".ConnectAborted",
+				[]any{"Zugriff verweigert: Grüße (request 1234abcd)"})},
+		{ErrOffline, dbus.NewError(ErrorOffline,
+			[]any{ErrOffline.Error() + " (request 1234abcd)"})},
+		{&TooManyRequestsError{RetryAfter: time.Second},
+			dbus.NewError(ErrorTooManyRequests, []any{
+				"too many requests, retry after 1s (request 1234abcd)",
+				uint32(1),
+			})},
+	} {
+		r := &Request{ID: "1234abcd", Error: test.err}
+		if got := connectError(r); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("got %v, want %v", got, test.want)
+		}
+	}
+}
+
+// TestDaemonConnectProfile tests ConnectProfile of daemon
+func TestDaemonConnectProfile(t *testing.T) {
+	// create daemon
+	requests := make(chan *Request)
+	done := make(chan struct{})
+	daemon := daemon{
+		requests: requests,
+		done:     done,
+	}
+
+	// run connect and get results
+	cookie, host, connectURL, fingerprint, resolve :=
+		"cookie", "host", "connectURL", "fingerprint", "resolve"
+	want := &Request{
+		Name:       RequestConnect,
+		Parameters: []any{cookie, host, connectURL, fingerprint, resolve, "profile"},
+		Sender:     "sender",
+		UID:        UIDUnknown,
+		done:       done,
+	}
+	got := &Request{}
+	go func() {
+		r := <-requests
+		got = r
+		r.Close()
+	}()
+	err := daemon.ConnectProfile("sender", "profile", cookie, host, connectURL, fingerprint, resolve)
+	if err != nil {
+		t.Error(err)
+	}
+
+	// check results
+	if got.Name != want.Name ||
+		!reflect.DeepEqual(got.Parameters, want.Parameters) ||
+		!reflect.DeepEqual(got.Results, want.Results) ||
+		got.Error != want.Error ||
+		got.Sender != want.Sender ||
+		got.UID != want.UID ||
+		got.done != want.done {
+		// not equal
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestDaemonConnectTunnel tests ConnectTunnel of daemon
+func TestDaemonConnectTunnel(t *testing.T) {
+	// create daemon
+	requests := make(chan *Request)
+	done := make(chan struct{})
+	daemon := daemon{
+		requests: requests,
+		done:     done,
+	}
+
+	// run connect and get results
+	cookie, host, connectURL, fingerprint, resolve :=
+		"cookie", "host", "connectURL", "fingerprint", "resolve"
+	want := &Request{
+		Name:       RequestConnectTunnel,
+		Parameters: []any{cookie, host, connectURL, fingerprint, resolve, "profile", "lab"},
+		Sender:     "sender",
+		UID:        UIDUnknown,
+		done:       done,
+	}
+	got := &Request{}
+	go func() {
+		r := <-requests
+		got = r
+		r.Close()
+	}()
+	err := daemon.ConnectTunnel("sender", "lab", "profile", cookie, host, connectURL, fingerprint, resolve)
+	if err != nil {
+		t.Error(err)
+	}
+
+	// check results
+	if got.Name != want.Name ||
+		!reflect.DeepEqual(got.Parameters, want.Parameters) ||
+		!reflect.DeepEqual(got.Results, want.Results) ||
+		got.Error != want.Error ||
+		got.Sender != want.Sender ||
+		got.UID != want.UID ||
 		got.done != want.done {
 		// not equal
 		t.Errorf("got %v, want %v", got, want)
@@ -85,69 +235,1042 @@ func TestDaemonDisconnect(t *testing.T) {
 		done:     done,
 	}
 
-	// run disconnect and get results
-	want := &Request{
-		Name: RequestDisconnect,
-		done: done,
-	}
-	got := &Request{}
+	// run disconnect and get results
+	want := &Request{
+		Name:   RequestDisconnect,
+		Sender: "sender",
+		UID:    UIDUnknown,
+		done:   done,
+	}
+	got := &Request{}
+	go func() {
+		r := <-requests
+		got = r
+		r.Close()
+	}()
+	err := daemon.Disconnect("sender")
+	if err != nil {
+		t.Error(err)
+	}
+
+	// check results
+	if got.Name != want.Name ||
+		!reflect.DeepEqual(got.Parameters, want.Parameters) ||
+		!reflect.DeepEqual(got.Results, want.Results) ||
+		got.Error != want.Error ||
+		got.Sender != want.Sender ||
+		got.UID != want.UID ||
+		got.done != want.done {
+		// not equal
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestDaemonDisconnectTunnel tests DisconnectTunnel of daemon
+func TestDaemonDisconnectTunnel(t *testing.T) {
+	// create daemon
+	requests := make(chan *Request)
+	done := make(chan struct{})
+	daemon := daemon{
+		requests: requests,
+		done:     done,
+	}
+
+	// run disconnect and get results
+	want := &Request{
+		Name:       RequestDisconnectTunnel,
+		Parameters: []any{"lab"},
+		Sender:     "sender",
+		UID:        UIDUnknown,
+		done:       done,
+	}
+	got := &Request{}
+	go func() {
+		r := <-requests
+		got = r
+		r.Close()
+	}()
+	err := daemon.DisconnectTunnel("sender", "lab")
+	if err != nil {
+		t.Error(err)
+	}
+
+	// check results
+	if got.Name != want.Name ||
+		!reflect.DeepEqual(got.Parameters, want.Parameters) ||
+		got.Sender != want.Sender ||
+		got.done != want.done {
+		// not equal
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestDaemonConnectCached tests ConnectCached of daemon
+func TestDaemonConnectCached(t *testing.T) {
+	// create daemon
+	requests := make(chan *Request)
+	done := make(chan struct{})
+	daemon := daemon{
+		requests: requests,
+		done:     done,
+	}
+
+	// run connect cached and get results
+	want := &Request{
+		Name:   RequestConnectCached,
+		Sender: "sender",
+		UID:    UIDUnknown,
+		done:   done,
+	}
+	got := &Request{}
+	go func() {
+		r := <-requests
+		got = r
+		r.Close()
+	}()
+	if err := daemon.ConnectCached("sender"); err != nil {
+		t.Error(err)
+	}
+
+	// check results
+	if got.Name != want.Name ||
+		got.Parameters != nil ||
+		got.Sender != want.Sender ||
+		got.UID != want.UID ||
+		got.done != want.done {
+		// not equal
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// error
+	go func() {
+		r := <-requests
+		r.Error = errors.New("test error")
+		r.Close()
+	}()
+	if err := daemon.ConnectCached("sender"); err == nil {
+		t.Error("connect cached should return error")
+	}
+}
+
+// TestDaemonReconnect tests Reconnect of daemon
+func TestDaemonReconnect(t *testing.T) {
+	// create daemon
+	requests := make(chan *Request)
+	done := make(chan struct{})
+	daemon := daemon{
+		requests: requests,
+		done:     done,
+	}
+
+	// run reconnect and get results
+	want := &Request{
+		Name:   RequestReconnect,
+		Sender: "sender",
+		UID:    UIDUnknown,
+		done:   done,
+	}
+	got := &Request{}
+	go func() {
+		r := <-requests
+		got = r
+		r.Close()
+	}()
+	if err := daemon.Reconnect("sender"); err != nil {
+		t.Error(err)
+	}
+
+	// check results
+	if got.Name != want.Name ||
+		got.Parameters != nil ||
+		got.Sender != want.Sender ||
+		got.UID != want.UID ||
+		got.done != want.done {
+		// not equal
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// reauthentication required
+	go func() {
+		r := <-requests
+		r.Error = ErrReauthRequired
+		r.Close()
+	}()
+	err := daemon.Reconnect("sender")
+	if err == nil || err.Name != ErrorReauthRequired {
+		t.Errorf("got %v, want %s", err, ErrorReauthRequired)
+	}
+}
+
+// TestDaemonPauseResume tests Pause and Resume of daemon
+func TestDaemonPauseResume(t *testing.T) {
+	// create daemon
+	requests := make(chan *Request)
+	done := make(chan struct{})
+	daemon := daemon{
+		requests: requests,
+		done:     done,
+	}
+
+	// run pause and resume and get results
+	for _, test := range []struct {
+		name   string
+		params []any
+		call   func() *dbus.Error
+	}{
+		{RequestPause, []any{uint32(60)}, func() *dbus.Error {
+			return daemon.Pause("sender", 60)
+		}},
+		{RequestResume, nil, func() *dbus.Error {
+			return daemon.Resume("sender")
+		}},
+	} {
+		got := &Request{}
+		go func() {
+			r := <-requests
+			got = r
+			r.Close()
+		}()
+		if err := test.call(); err != nil {
+			t.Error(err)
+		}
+		if got.Name != test.name ||
+			!reflect.DeepEqual(got.Parameters, test.params) ||
+			got.Sender != "sender" ||
+			got.UID != UIDUnknown ||
+			got.done != done {
+			t.Errorf("got %v, want %s", got, test.name)
+		}
+
+		// error
+		go func() {
+			r := <-requests
+			r.Error = errors.New("test error")
+			r.Close()
+		}()
+		err := test.call()
+		if err == nil || err.Name != Interface+"."+test.name+"Aborted" {
+			t.Errorf("%s should return error, got %v", test.name, err)
+		}
+	}
+}
+
+// TestDaemonCancel tests Cancel of daemon
+func TestDaemonCancel(t *testing.T) {
+	// create daemon
+	requests := make(chan *Request)
+	done := make(chan struct{})
+	daemon := daemon{
+		requests: requests,
+		done:     done,
+	}
+
+	// run cancel and get results
+	want := &Request{
+		Name:   RequestCancel,
+		Sender: "sender",
+		UID:    UIDUnknown,
+		done:   done,
+	}
+	got := &Request{}
+	go func() {
+		r := <-requests
+		got = r
+		r.Close()
+	}()
+	if err := daemon.Cancel("sender"); err != nil {
+		t.Error(err)
+	}
+
+	// check results
+	if got.Name != want.Name ||
+		got.Parameters != nil ||
+		got.Sender != want.Sender ||
+		got.UID != want.UID ||
+		got.done != want.done {
+		// not equal
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// error
+	go func() {
+		r := <-requests
+		r.Error = errors.New("test error")
+		r.Close()
+	}()
+	if err := daemon.Cancel("sender"); err == nil {
+		t.Error("cancel should return error")
+	}
+}
+
+// TestDaemonConnectDevice tests ConnectDevice of daemon
+func TestDaemonConnectDevice(t *testing.T) {
+	// create daemon
+	requests := make(chan *Request)
+	done := make(chan struct{})
+	daemon := daemon{
+		requests: requests,
+		done:     done,
+	}
+
+	// run connect device and get results
+	want := []any{"profile", "server"}
+	got := &Request{}
+	go func() {
+		r := <-requests
+		got = r
+		r.Results = []any{"code", "uri", "complete"}
+		r.Close()
+	}()
+	code, uri, complete, err := daemon.ConnectDevice("sender", "profile", "server")
+	if err != nil {
+		t.Error(err)
+	}
+
+	// check results
+	if got.Name != RequestConnectDevice ||
+		!reflect.DeepEqual(got.Parameters, want) {
+		t.Errorf("got %v, want %v", got.Parameters, want)
+	}
+	if code != "code" || uri != "uri" || complete != "complete" {
+		t.Errorf("got %s %s %s, want code uri complete", code, uri, complete)
+	}
+}
+
+// TestDaemonSetSchedule tests SetSchedule of daemon
+func TestDaemonSetSchedule(t *testing.T) {
+	// create daemon
+	requests := make(chan *Request)
+	done := make(chan struct{})
+	daemon := daemon{
+		requests: requests,
+		done:     done,
+	}
+
+	// run set schedule and get results
+	want := &Request{
+		Name:       RequestSetSchedule,
+		Parameters: []any{true},
+		Sender:     "sender",
+		UID:        UIDUnknown,
+		done:       done,
+	}
+	got := &Request{}
+	go func() {
+		r := <-requests
+		got = r
+		r.Close()
+	}()
+	err := daemon.SetSchedule("sender", true)
+	if err != nil {
+		t.Error(err)
+	}
+
+	// check results
+	if got.Name != want.Name ||
+		!reflect.DeepEqual(got.Parameters, want.Parameters) ||
+		got.Sender != want.Sender ||
+		got.done != want.done {
+		// not equal
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestDaemonSetTND tests SetTND of daemon
+func TestDaemonSetTND(t *testing.T) {
+	// create daemon
+	requests := make(chan *Request)
+	done := make(chan struct{})
+	daemon := daemon{
+		requests: requests,
+		done:     done,
+	}
+
+	// run set tnd and get results
+	want := &Request{
+		Name:       RequestSetTND,
+		Parameters: []any{false, uint32(600)},
+		Sender:     "sender",
+		UID:        UIDUnknown,
+		done:       done,
+	}
+	got := &Request{}
+	go func() {
+		r := <-requests
+		got = r
+		r.Error = errors.New("test error")
+		r.Close()
+	}()
+	if err := daemon.SetTND("sender", false, 600); err == nil {
+		t.Error("set tnd should return error")
+	}
+
+	// check results
+	if got.Name != want.Name ||
+		!reflect.DeepEqual(got.Parameters, want.Parameters) ||
+		got.Sender != want.Sender ||
+		got.done != want.done {
+		// not equal
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestDaemonSetTrafPol tests SetTrafPol of daemon
+func TestDaemonSetTrafPol(t *testing.T) {
+	// create daemon
+	requests := make(chan *Request)
+	done := make(chan struct{})
+	daemon := daemon{
+		requests: requests,
+		done:     done,
+	}
+
+	// run set trafpol and get results
+	want := &Request{
+		Name:       RequestSetTrafPol,
+		Parameters: []any{false, uint32(600)},
+		Sender:     "sender",
+		done:       done,
+	}
+	got := &Request{}
+	go func() {
+		r := <-requests
+		got = r
+		r.Error = errors.New("test error")
+		r.Close()
+	}()
+	if err := daemon.SetTrafPol("sender", false, 600); err == nil {
+		t.Error("set trafpol should return error")
+	}
+
+	// check results
+	if got.Name != want.Name ||
+		!reflect.DeepEqual(got.Parameters, want.Parameters) ||
+		got.Sender != want.Sender ||
+		got.done != want.done {
+		// not equal
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// daemon stopped
+	close(done)
+	if err := daemon.SetTrafPol("sender", true, 0); err == nil {
+		t.Error("set trafpol should return error")
+	}
+}
+
+// TestDaemonSetNotificationPolicy tests SetNotificationPolicy of daemon
+func TestDaemonSetNotificationPolicy(t *testing.T) {
+	// create daemon
+	requests := make(chan *Request)
+	done := make(chan struct{})
+	daemon := daemon{
+		requests: requests,
+		done:     done,
+	}
+
+	// run set notification policy and get results
+	policy := `{"MinSeverity":"warning"}`
+	got := &Request{}
+	go func() {
+		r := <-requests
+		got = r
+		r.Close()
+	}()
+	if err := daemon.SetNotificationPolicy("sender", policy); err != nil {
+		t.Error(err)
+	}
+	if got.Name != RequestSetNotificationPolicy || got.Sender != "sender" ||
+		!reflect.DeepEqual(got.Parameters, []any{policy}) {
+		t.Errorf("got %v, want set notification policy request", got)
+	}
+
+	// test error
+	go func() {
+		r := <-requests
+		r.Error = errors.New("test error")
+		r.Close()
+	}()
+	if err := daemon.SetNotificationPolicy("sender", "{"); err == nil ||
+		err.Name != Interface+".SetNotificationPolicyAborted" {
+		t.Errorf("got %v, want aborted error", err)
+	}
+
+	// daemon stopped
+	close(done)
+	if err := daemon.SetNotificationPolicy("sender", ""); err == nil {
+		t.Error("set notification policy should return error")
+	}
+}
+
+// TestDaemonReportHostscan tests ReportHostscan of daemon
+func TestDaemonReportHostscan(t *testing.T) {
+	// create daemon
+	requests := make(chan *Request)
+	done := make(chan struct{})
+	daemon := daemon{
+		requests: requests,
+		done:     done,
+	}
+
+	// run report hostscan and get results
+	got := &Request{}
+	go func() {
+		r := <-requests
+		got = r
+		r.Close()
+	}()
+	if err := daemon.ReportHostscan("sender"); err != nil {
+		t.Error(err)
+	}
+	if got.Name != RequestReportHostscan ||
+		got.Parameters != nil ||
+		got.Sender != "sender" {
+		t.Errorf("invalid request: %v", got)
+	}
+
+	// aborted
+	close(done)
+	if err := daemon.ReportHostscan("sender"); err == nil {
+		t.Error("report hostscan should be aborted")
+	}
+}
+
+// testConn implements the dbusConn interface for testing
+type testConn struct {
+	signals []string
+}
+
+func (tc *testConn) Close() error {
+	return nil
+}
+
+func (tc *testConn) Export(any, dbus.ObjectPath, string) error {
+	return nil
+}
+
+func (tc *testConn) RequestName(string, dbus.RequestNameFlags) (dbus.RequestNameReply, error) {
+	return dbus.RequestNameReplyPrimaryOwner, nil
+}
+
+func (tc *testConn) Emit(_ dbus.ObjectPath, name string, _ ...any) error {
+	tc.signals = append(tc.signals, name)
+	return nil
+}
+
+// testProperties implements the propProperties interface for testing
+type testProperties struct {
+	props map[string]any
+}
+
+func (tp *testProperties) GetAll(string) (map[string]dbus.Variant, *dbus.Error) {
+	values := make(map[string]dbus.Variant)
+	for name, value := range tp.props {
+		values[name] = dbus.MakeVariant(value)
+	}
+	return values, nil
+}
+
+func (tp *testProperties) Introspection(string) []introspect.Property {
+	return nil
+}
+
+func (tp *testProperties) SetMust(_, property string, v any) {
+	if tp.props == nil {
+		// props not set, skip
+		return
+	}
+
+	// ignore iface, map property to value
+	tp.props[property] = v
+}
+
+// TestDaemonDoctor tests Doctor of daemon
+func TestDaemonDoctor(t *testing.T) {
+	// create daemon
+	requests := make(chan *Request)
+	done := make(chan struct{})
+	daemon := daemon{
+		requests: requests,
+		done:     done,
+	}
+
+	// run doctor and get results
+	want := []Problem{{Check: "openconnect", Message: "not found"}}
+	var got *Request
+	go func() {
+		r := <-requests
+		got = r
+		r.Results = []any{want}
+		r.Close()
+	}()
+	problems, err := daemon.Doctor("sender")
+	if err != nil {
+		t.Error(err)
+	}
+
+	// check results
+	if got.Name != RequestDoctor || got.Sender != "sender" {
+		t.Errorf("got %v, want doctor request", got)
+	}
+	if !reflect.DeepEqual(problems, want) {
+		t.Errorf("got %v, want %v", problems, want)
+	}
+
+	// test aborted
+	close(done)
+	if _, err := daemon.Doctor("sender"); err == nil {
+		t.Error("aborted doctor should fail")
+	}
+}
+
+// TestDaemonGetLogs tests GetLogs of daemon
+func TestDaemonGetLogs(t *testing.T) {
+	// create daemon
+	requests := make(chan *Request)
+	done := make(chan struct{})
+	daemon := daemon{
+		requests: requests,
+		done:     done,
+	}
+
+	// run get logs and get results
+	want := []string{"level=info msg=test"}
+	var got *Request
+	go func() {
+		r := <-requests
+		got = r
+		r.Results = []any{want}
+		r.Close()
+	}()
+	logs, err := daemon.GetLogs("sender", 10)
+	if err != nil {
+		t.Error(err)
+	}
+
+	// check results
+	if got.Name != RequestGetLogs || got.Sender != "sender" ||
+		!reflect.DeepEqual(got.Parameters, []any{uint32(10)}) {
+		t.Errorf("got %v, want get logs request", got)
+	}
+	if !reflect.DeepEqual(logs, want) {
+		t.Errorf("got %v, want %v", logs, want)
+	}
+
+	// test aborted
+	close(done)
+	if _, err := daemon.GetLogs("sender", 0); err == nil {
+		t.Error("aborted get logs should fail")
+	}
+}
+
+// TestDaemonListServers tests ListServers of daemon
+func TestDaemonListServers(t *testing.T) {
+	// create daemon
+	requests := make(chan *Request)
+	done := make(chan struct{})
+	daemon := daemon{
+		requests: requests,
+		done:     done,
+	}
+
+	// run list servers and get results
+	want := []Server{{
+		Name:      "VPN",
+		Address:   "vpn.example.com",
+		Reachable: true,
+		Latency:   23000,
+	}}
+	var got *Request
+	go func() {
+		r := <-requests
+		got = r
+		r.Results = []any{want}
+		r.Close()
+	}()
+	servers, err := daemon.ListServers("sender", true)
+	if err != nil {
+		t.Error(err)
+	}
+
+	// check results
+	if got.Name != RequestListServers || got.Sender != "sender" ||
+		!reflect.DeepEqual(got.Parameters, []any{true}) {
+		t.Errorf("got %v, want list servers request", got)
+	}
+	if !reflect.DeepEqual(servers, want) {
+		t.Errorf("got %v, want %v", servers, want)
+	}
+
+	// test aborted
+	close(done)
+	if _, err := daemon.ListServers("sender", false); err == nil {
+		t.Error("aborted list servers should fail")
+	}
+}
+
+// TestDaemonGetUsage tests GetUsage of daemon
+func TestDaemonGetUsage(t *testing.T) {
+	// create daemon
+	requests := make(chan *Request)
+	done := make(chan struct{})
+	daemon := daemon{
+		requests: requests,
+		done:     done,
+	}
+
+	// run get usage and get results
+	want := []Usage{{
+		Month:            "2026-10",
+		RXBytes:          2048,
+		TXBytes:          1024,
+		Connections:      3,
+		ConnectedSeconds: 90,
+	}}
+	var got *Request
+	go func() {
+		r := <-requests
+		got = r
+		r.Results = []any{want}
+		r.Close()
+	}()
+	usage, err := daemon.GetUsage("sender")
+	if err != nil {
+		t.Error(err)
+	}
+
+	// check results
+	if got.Name != RequestGetUsage || got.Sender != "sender" {
+		t.Errorf("got %v, want get usage request", got)
+	}
+	if !reflect.DeepEqual(usage, want) {
+		t.Errorf("got %v, want %v", usage, want)
+	}
+
+	// test error
+	go func() {
+		r := <-requests
+		r.Error = errors.New("test error")
+		r.Close()
+	}()
+	if _, err := daemon.GetUsage("sender"); err == nil ||
+		err.Name != Interface+".GetUsageAborted" {
+		t.Errorf("got %v, want aborted error", err)
+	}
+
+	// test aborted
+	close(done)
+	if _, err := daemon.GetUsage("sender"); err == nil {
+		t.Error("aborted get usage should fail")
+	}
+}
+
+// TestDaemonGetMetrics tests GetMetrics of daemon
+func TestDaemonGetMetrics(t *testing.T) {
+	// create daemon
+	requests := make(chan *Request)
+	done := make(chan struct{})
+	daemon := daemon{
+		requests: requests,
+		done:     done,
+	}
+
+	// run get metrics and get results
+	want := []RequestMetrics{{
+		API:                 "dbus",
+		Request:             RequestConnect,
+		Count:               2,
+		Errors:              1,
+		LatencyMicroseconds: 1500,
+		LatencyBuckets:      []uint64{1, 1, 0, 0, 0, 0},
+	}}
+	var got *Request
 	go func() {
 		r := <-requests
 		got = r
+		r.Results = []any{want}
 		r.Close()
 	}()
-	err := daemon.Disconnect("sender")
+	metrics, err := daemon.GetMetrics("sender")
 	if err != nil {
 		t.Error(err)
 	}
 
 	// check results
-	if got.Name != want.Name ||
-		!reflect.DeepEqual(got.Parameters, want.Parameters) ||
-		!reflect.DeepEqual(got.Results, want.Results) ||
-		got.Error != want.Error ||
-		got.done != want.done {
-		// not equal
-		t.Errorf("got %v, want %v", got, want)
+	if got.Name != RequestGetMetrics || got.Sender != "sender" {
+		t.Errorf("got %v, want get metrics request", got)
+	}
+	if !reflect.DeepEqual(metrics, want) {
+		t.Errorf("got %v, want %v", metrics, want)
 	}
-}
 
-// testConn implements the dbusConn interface for testing
-type testConn struct{}
+	// test error
+	go func() {
+		r := <-requests
+		r.Error = errors.New("test error")
+		r.Close()
+	}()
+	if _, err := daemon.GetMetrics("sender"); err == nil ||
+		err.Name != Interface+".GetMetricsAborted" {
+		t.Errorf("got %v, want aborted error", err)
+	}
 
-func (tc *testConn) Close() error {
-	return nil
+	// test aborted
+	close(done)
+	if _, err := daemon.GetMetrics("sender"); err == nil {
+		t.Error("aborted get metrics should fail")
+	}
 }
 
-func (tc *testConn) Export(any, dbus.ObjectPath, string) error {
-	return nil
+// TestDaemonValidateConfig tests ValidateConfig of daemon
+func TestDaemonValidateConfig(t *testing.T) {
+	// create daemon
+	requests := make(chan *Request)
+	done := make(chan struct{})
+	daemon := daemon{
+		requests: requests,
+		done:     done,
+	}
+
+	// run validate config and get results
+	want := []Finding{{
+		Severity: SeverityWarning,
+		Field:    "Tunnels",
+		Message:  "changes require a restart of the daemon",
+	}}
+	var got *Request
+	go func() {
+		r := <-requests
+		got = r
+		r.Results = []any{want}
+		r.Close()
+	}()
+	findings, err := daemon.ValidateConfig("sender", ValidateKindConfig, "{}")
+	if err != nil {
+		t.Error(err)
+	}
+
+	// check results
+	if got.Name != RequestValidateConfig || got.Sender != "sender" ||
+		!reflect.DeepEqual(got.Parameters, []any{ValidateKindConfig, "{}"}) {
+		t.Errorf("got %v, want validate config request", got)
+	}
+	if !reflect.DeepEqual(findings, want) {
+		t.Errorf("got %v, want %v", findings, want)
+	}
+
+	// test error
+	go func() {
+		r := <-requests
+		r.Error = errors.New("test error")
+		r.Close()
+	}()
+	if _, err := daemon.ValidateConfig("sender", "other", ""); err == nil ||
+		err.Name != Interface+".ValidateConfigAborted" {
+		t.Errorf("got %v, want aborted error", err)
+	}
+
+	// test aborted
+	close(done)
+	if _, err := daemon.ValidateConfig("sender", ValidateKindProfile, ""); err == nil {
+		t.Error("aborted validate config should fail")
+	}
 }
 
-func (tc *testConn) RequestName(string, dbus.RequestNameFlags) (dbus.RequestNameReply, error) {
-	return dbus.RequestNameReplyPrimaryOwner, nil
+// TestDaemonSplitExcludes tests AddSplitExclude, RemoveSplitExclude and
+// ListSplitExcludes of daemon
+func TestDaemonSplitExcludes(t *testing.T) {
+	// create daemon
+	requests := make(chan *Request)
+	done := make(chan struct{})
+	daemon := daemon{
+		requests: requests,
+		done:     done,
+	}
+
+	// handle requests
+	want := []string{"192.168.1.0/24"}
+	got := []*Request{}
+	handled := make(chan struct{})
+	go func() {
+		defer close(handled)
+		for i := 0; i < 3; i++ {
+			r := <-requests
+			got = append(got, r)
+			switch r.Name {
+			case RequestListSplitExcludes:
+				r.Results = []any{want}
+			case RequestRemoveSplitExclude:
+				r.Error = errors.New("not a runtime exclude")
+			}
+			r.Close()
+		}
+	}()
+
+	if err := daemon.AddSplitExclude("sender", "192.168.1.0/24"); err != nil {
+		t.Error(err)
+	}
+	if err := daemon.RemoveSplitExclude("sender", "10.0.0.0/8"); err == nil ||
+		err.Name != Interface+".RemoveSplitExcludeAborted" {
+		t.Errorf("got %v, want remove error", err)
+	}
+	excludes, err := daemon.ListSplitExcludes("sender")
+	if err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(excludes, want) {
+		t.Errorf("got %v, want %v", excludes, want)
+	}
+	<-handled
+
+	// check requests
+	for i, w := range []struct {
+		name       string
+		parameters []any
+	}{
+		{RequestAddSplitExclude, []any{"192.168.1.0/24"}},
+		{RequestRemoveSplitExclude, []any{"10.0.0.0/8"}},
+		{RequestListSplitExcludes, nil},
+	} {
+		if got[i].Name != w.name || got[i].Sender != "sender" ||
+			!reflect.DeepEqual(got[i].Parameters, w.parameters) {
+			t.Errorf("got %v, want %s request", got[i], w.name)
+		}
+	}
+
+	// test aborted
+	close(done)
+	if _, err := daemon.ListSplitExcludes("sender"); err == nil {
+		t.Error("aborted list split excludes should fail")
+	}
 }
 
-// testProperties implements the propProperties interface for testing
-type testProperties struct {
-	props map[string]any
+// TestDaemonReloadProfile tests ReloadProfile of daemon
+func TestDaemonReloadProfile(t *testing.T) {
+	// create daemon
+	requests := make(chan *Request)
+	done := make(chan struct{})
+	daemon := daemon{
+		requests: requests,
+		done:     done,
+	}
+
+	// run reload profile without error
+	got := &Request{}
+	go func() {
+		r := <-requests
+		got = r
+		r.Close()
+	}()
+	if err := daemon.ReloadProfile("sender"); err != nil {
+		t.Error(err)
+	}
+	if got.Name != RequestReloadProfile || got.Sender != "sender" {
+		t.Errorf("got %v", got)
+	}
+
+	// run reload profile with error
+	go func() {
+		r := <-requests
+		r.Error = errors.New("test error")
+		r.Close()
+	}()
+	if err := daemon.ReloadProfile("sender"); err == nil {
+		t.Error("reload profile should return error")
+	}
+
+	// daemon stopped
+	close(done)
+	if err := daemon.ReloadProfile("sender"); err == nil {
+		t.Error("reload profile should return error")
+	}
 }
 
-func (tp *testProperties) Introspection(string) []introspect.Property {
-	return nil
+// TestDaemonSetPreferredServer tests SetPreferredServer of daemon
+func TestDaemonSetPreferredServer(t *testing.T) {
+	// create daemon
+	requests := make(chan *Request)
+	done := make(chan struct{})
+	daemon := daemon{
+		requests: requests,
+		done:     done,
+	}
+
+	// run set preferred server without error
+	got := &Request{}
+	go func() {
+		r := <-requests
+		got = r
+		r.Close()
+	}()
+	if err := daemon.SetPreferredServer("sender", "vpn.example.com"); err != nil {
+		t.Error(err)
+	}
+	if got.Name != RequestSetPreferredServer ||
+		got.Parameters[0].(string) != "vpn.example.com" {
+		t.Errorf("got %v", got)
+	}
+
+	// run set preferred server with error
+	go func() {
+		r := <-requests
+		r.Error = errors.New("test error")
+		r.Close()
+	}()
+	if err := daemon.SetPreferredServer("sender", "invalid"); err == nil {
+		t.Error("set preferred server should return error")
+	}
+
+	// daemon stopped
+	close(done)
+	if err := daemon.SetPreferredServer("sender", ""); err == nil {
+		t.Error("set preferred server should return error")
+	}
 }
 
-func (tp *testProperties) SetMust(_, property string, v any) {
-	if tp.props == nil {
-		// props not set, skip
-		return
+// TestDaemonGetStatus tests GetStatus of daemon
+func TestDaemonGetStatus(t *testing.T) {
+	// create daemon
+	requests := make(chan *Request)
+	done := make(chan struct{})
+	daemon := daemon{
+		requests: requests,
+		done:     done,
 	}
 
-	// ignore iface, map property to value
-	tp.props[property] = v
+	// run get status and get results
+	want := `{"TrustedNetwork":0}`
+	got := &Request{}
+	go func() {
+		r := <-requests
+		got = r
+		r.Results = []any{want}
+		r.Close()
+	}()
+	status, err := daemon.GetStatus("sender")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != RequestGetStatus || got.Sender != "sender" {
+		t.Errorf("got %v", got)
+	}
+	if status != want {
+		t.Errorf("got %s, want %s", status, want)
+	}
+
+	// run get status with error
+	go func() {
+		r := <-requests
+		r.Error = errors.New("test error")
+		r.Close()
+	}()
+	if _, err := daemon.GetStatus("sender"); err == nil {
+		t.Error("get status should return error")
+	}
 }
 
 // TestServiceStartStop tests Start and Stop of Service
-func TestServiceStartStop(_ *testing.T) {
+func TestServiceStartStop(t *testing.T) {
 	dbusConnectSystemBus = func(opts ...dbus.ConnOption) (dbusConn, error) {
 		return &testConn{}, nil
 	}
@@ -155,10 +1278,36 @@ func TestServiceStartStop(_ *testing.T) {
 		return &testProperties{}, nil
 	}
 	s := NewService()
-	s.Start()
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
 	s.Stop()
 }
 
+// TestServiceStartError tests Start of Service with errors
+func TestServiceStartError(t *testing.T) {
+	// connect error
+	dbusConnectSystemBus = func(opts ...dbus.ConnOption) (dbusConn, error) {
+		return nil, errors.New("test error")
+	}
+	s := NewService()
+	if err := s.Start(context.Background()); err == nil {
+		t.Error("start should fail with connect error")
+	}
+
+	// properties export error
+	dbusConnectSystemBus = func(opts ...dbus.ConnOption) (dbusConn, error) {
+		return &testConn{}, nil
+	}
+	propExport = func(conn dbusConn, path dbus.ObjectPath, props prop.Map) (propProperties, error) {
+		return nil, errors.New("test error")
+	}
+	s = NewService()
+	if err := s.Start(context.Background()); err == nil {
+		t.Error("start should fail with properties export error")
+	}
+}
+
 // TestServiceRequests tests Requests of Service
 func TestServiceRequests(t *testing.T) {
 	s := NewService()
@@ -179,7 +1328,9 @@ func TestServiceSetProperty(t *testing.T) {
 		return properties, nil
 	}
 	s := NewService()
-	s.Start()
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
 
 	propName := "test-property"
 	want := "test-value"
@@ -193,6 +1344,130 @@ func TestServiceSetProperty(t *testing.T) {
 	}
 }
 
+// TestServiceSetTunnelProperty tests SetTunnelProperty of Service
+func TestServiceSetTunnelProperty(t *testing.T) {
+	dbusConnectSystemBus = func(opts ...dbus.ConnOption) (dbusConn, error) {
+		return &testConn{}, nil
+	}
+	exported := make(map[dbus.ObjectPath]*testProperties)
+	propExport = func(conn dbusConn, path dbus.ObjectPath, props prop.Map) (propProperties, error) {
+		properties := &testProperties{props: make(map[string]any)}
+		exported[path] = properties
+		return properties, nil
+	}
+	s := NewService()
+	s.AddTunnel("lab")
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	s.SetTunnelProperty("lab", PropertyDevice, "oc-daemon-tun1")
+	s.SetProperty(PropertyDevice, "oc-daemon-tun0")
+	s.Stop()
+
+	if got := exported[TunnelPath("lab")].props[PropertyDevice]; got != DeviceInvalid {
+		t.Errorf("got %v, want %v after stop", got, DeviceInvalid)
+	}
+	if got := exported[Path].props[PropertyDevice]; got != DeviceInvalid {
+		t.Errorf("got %v, want %v after stop", got, DeviceInvalid)
+	}
+}
+
+// TestServiceConnections tests the VPN connection objects of Service
+func TestServiceConnections(t *testing.T) {
+	dbusConnectSystemBus = func(opts ...dbus.ConnOption) (dbusConn, error) {
+		return &testConn{}, nil
+	}
+	exported := make(map[dbus.ObjectPath]*testProperties)
+	propExport = func(conn dbusConn, path dbus.ObjectPath, props prop.Map) (propProperties, error) {
+		properties := &testProperties{props: make(map[string]any)}
+		exported[path] = properties
+		return properties, nil
+	}
+	s := NewService()
+	s.AddTunnel("lab")
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	s.SetProperty(PropertyDevice, "oc-daemon-tun0")
+	s.SetProperty(PropertyTrustedNetwork, TrustedNetworkTrusted)
+	s.SetTunnelProperty("lab", PropertyDevice, "oc-daemon-tun1")
+
+	// get connections with object manager, sync with property updates
+	// by setting another property
+	s.SetProperty(PropertyUptime, UptimeInvalid)
+	om := &objectManager{}
+	om.add(Path, Interface, exported[Path])
+	om.add(ConnectionPath(0), ConnectionInterface, exported[ConnectionPath(0)])
+	om.add(ConnectionPath(1), ConnectionInterface, exported[ConnectionPath(1)])
+	objects, err := om.GetManagedObjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := objects[Path][Interface]
+	if got := values[PropertyTrustedNetwork].Value(); got != TrustedNetworkTrusted {
+		t.Errorf("daemon: got %v, want %v", got, TrustedNetworkTrusted)
+	}
+	for n, want := range []string{"oc-daemon-tun0", "oc-daemon-tun1"} {
+		values := objects[ConnectionPath(n)][ConnectionInterface]
+		if got := values[PropertyDevice].Value(); got != want {
+			t.Errorf("connection %d: got %v, want %v", n, got, want)
+		}
+		if _, ok := values[PropertyTrustedNetwork]; ok {
+			t.Errorf("connection %d: got daemon property", n)
+		}
+	}
+
+	s.Stop()
+	for n := 0; n < 2; n++ {
+		got := exported[ConnectionPath(n)].props[PropertyDevice]
+		if got != DeviceInvalid {
+			t.Errorf("connection %d: got %v, want %v after stop", n, got,
+				DeviceInvalid)
+		}
+	}
+}
+
+// TestServiceEmitSignal tests EmitSignal of Service
+func TestServiceEmitSignal(t *testing.T) {
+	conn := &testConn{}
+	dbusConnectSystemBus = func(opts ...dbus.ConnOption) (dbusConn, error) {
+		return conn, nil
+	}
+	propExport = func(conn dbusConn, path dbus.ObjectPath, props prop.Map) (propProperties, error) {
+		return &testProperties{props: make(map[string]any)}, nil
+	}
+	s := NewService()
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	s.EmitSignal(SignalConnectionDropped)
+	s.Stop()
+
+	want := []string{SignalConnectionDropped}
+	if !reflect.DeepEqual(conn.signals, want) {
+		t.Errorf("got %v, want %v", conn.signals, want)
+	}
+}
+
+// TestTunnelPath tests TunnelPath
+func TestTunnelPath(t *testing.T) {
+	want := dbus.ObjectPath("/com/telekom_mms/oc_daemon/Daemon/Tunnels/lab")
+	if got := TunnelPath("lab"); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+// TestConnectionPath tests ConnectionPath
+func TestConnectionPath(t *testing.T) {
+	want := dbus.ObjectPath("/com/telekom_mms/oc_daemon/Connections/1")
+	if got := ConnectionPath(1); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
 // TestNewService tests NewService
 func TestNewService(t *testing.T) {
 	s := NewService()
@@ -0,0 +1,130 @@
+// Package ddns registers host names with dynamic DNS updates (RFC 2136)
+package ddns
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// TSIG algorithms
+const (
+	AlgorithmHMACSHA1   = "hmac-sha1"
+	AlgorithmHMACSHA224 = "hmac-sha224"
+	AlgorithmHMACSHA256 = "hmac-sha256"
+	AlgorithmHMACSHA384 = "hmac-sha384"
+	AlgorithmHMACSHA512 = "hmac-sha512"
+)
+
+// ValidAlgorithm returns whether algorithm is a supported TSIG algorithm
+func ValidAlgorithm(algorithm string) bool {
+	switch algorithm {
+	case AlgorithmHMACSHA1, AlgorithmHMACSHA224, AlgorithmHMACSHA256,
+		AlgorithmHMACSHA384, AlgorithmHMACSHA512:
+		return true
+	}
+	return false
+}
+
+// TSIG is a TSIG key for signing updates
+type TSIG struct {
+	// Name is the name of the key
+	Name string
+
+	// Algorithm is the TSIG algorithm, e.g., "hmac-sha256"
+	Algorithm string
+
+	// Secret is the base64 encoded secret of the key
+	Secret string
+}
+
+// Updater sends dynamic DNS updates for a zone to a DNS server
+type Updater struct {
+	// Server is the address of the DNS server including the port
+	Server string
+
+	// Zone is the zone that is updated
+	Zone string
+
+	// TTL is the time to live of the registered records
+	TTL time.Duration
+
+	// TSIG is the key for signing updates, nil disables TSIG
+	TSIG *TSIG
+
+	// Timeout is the timeout of the update
+	Timeout time.Duration
+}
+
+// records returns the A and AAAA records that map name to ips
+func (u *Updater) records(name string, ips []net.IP) []dns.RR {
+	rrs := []dns.RR{}
+	ttl := uint32(u.TTL.Seconds())
+	for _, ip := range ips {
+		if ip4 := ip.To4(); ip4 != nil {
+			rrs = append(rrs, &dns.A{
+				Hdr: dns.RR_Header{
+					Name:   name,
+					Rrtype: dns.TypeA,
+					Class:  dns.ClassINET,
+					Ttl:    ttl,
+				},
+				A: ip4,
+			})
+			continue
+		}
+		rrs = append(rrs, &dns.AAAA{
+			Hdr: dns.RR_Header{
+				Name:   name,
+				Rrtype: dns.TypeAAAA,
+				Class:  dns.ClassINET,
+				Ttl:    ttl,
+			},
+			AAAA: ip,
+		})
+	}
+	return rrs
+}
+
+// Register registers ips under host name host in the zone. Existing A and
+// AAAA records of the host name are replaced
+func (u *Updater) Register(host string, ips []net.IP) error {
+	if host == "" || strings.Contains(host, ".") {
+		return fmt.Errorf("invalid host name: %q", host)
+	}
+	if len(ips) == 0 {
+		return errors.New("no IP addresses to register")
+	}
+
+	// create update message that replaces all address records
+	zone := dns.Fqdn(u.Zone)
+	name := host + "." + zone
+	m := new(dns.Msg)
+	m.SetUpdate(zone)
+	m.RemoveRRset([]dns.RR{
+		&dns.ANY{Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeA}},
+		&dns.ANY{Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeAAAA}},
+	})
+	m.Insert(u.records(name, ips))
+
+	// sign and send update
+	c := &dns.Client{Timeout: u.Timeout}
+	if u.TSIG != nil {
+		key := dns.Fqdn(strings.ToLower(u.TSIG.Name))
+		c.TsigSecret = map[string]string{key: u.TSIG.Secret}
+		m.SetTsig(key, dns.Fqdn(u.TSIG.Algorithm), 300,
+			time.Now().Unix())
+	}
+	r, _, err := c.Exchange(m, u.Server)
+	if err != nil {
+		return fmt.Errorf("could not send DNS update: %w", err)
+	}
+	if r.Rcode != dns.RcodeSuccess {
+		return fmt.Errorf("DNS update failed: %s", dns.RcodeToString[r.Rcode])
+	}
+	return nil
+}
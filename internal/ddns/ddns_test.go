@@ -0,0 +1,153 @@
+package ddns
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// TestValidAlgorithm tests ValidAlgorithm
+func TestValidAlgorithm(t *testing.T) {
+	for _, a := range []string{
+		AlgorithmHMACSHA1,
+		AlgorithmHMACSHA224,
+		AlgorithmHMACSHA256,
+		AlgorithmHMACSHA384,
+		AlgorithmHMACSHA512,
+	} {
+		if !ValidAlgorithm(a) {
+			t.Errorf("%s should be valid", a)
+		}
+	}
+	for _, a := range []string{"", "gss-tsig", "hmac-md5"} {
+		if ValidAlgorithm(a) {
+			t.Errorf("%s should not be valid", a)
+		}
+	}
+}
+
+// startTestServer starts a DNS server for testing that handles updates with
+// handler, it verifies TSIG signatures with secrets
+func startTestServer(t *testing.T, secrets map[string]string,
+	handler func(w dns.ResponseWriter, r *dns.Msg)) string {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	server := &dns.Server{
+		PacketConn:        pc,
+		Handler:           dns.HandlerFunc(handler),
+		TsigSecret:        secrets,
+		NotifyStartedFunc: func() { close(started) },
+		MsgAcceptFunc: func(dns.Header) dns.MsgAcceptAction {
+			return dns.MsgAccept
+		},
+	}
+	go func() { _ = server.ActivateAndServe() }()
+	t.Cleanup(func() { _ = server.Shutdown() })
+	<-started
+	return pc.LocalAddr().String()
+}
+
+// TestUpdaterRegister tests Register of Updater
+func TestUpdaterRegister(t *testing.T) {
+	secret := "c2VjcmV0c2VjcmV0c2VjcmV0c2VjcmV0"
+	var got *dns.Msg
+	var tsigErr error
+	addr := startTestServer(t, map[string]string{"key.": secret},
+		func(w dns.ResponseWriter, r *dns.Msg) {
+			got = r
+			tsigErr = w.TsigStatus()
+			m := new(dns.Msg)
+			m.SetReply(r)
+			if r.IsTsig() != nil {
+				m.SetTsig("key.", dns.HmacSHA256, 300, time.Now().Unix())
+			}
+			_ = w.WriteMsg(m)
+		})
+
+	u := &Updater{
+		Server:  addr,
+		Zone:    "example.com",
+		TTL:     5 * time.Minute,
+		Timeout: time.Second,
+		TSIG: &TSIG{
+			Name:      "key",
+			Algorithm: AlgorithmHMACSHA256,
+			Secret:    secret,
+		},
+	}
+	ips := []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("2001:db8::1")}
+	if err := u.Register("host", ips); err != nil {
+		t.Fatal(err)
+	}
+
+	// check update message
+	if got.Opcode != dns.OpcodeUpdate {
+		t.Errorf("got %d, want %d", got.Opcode, dns.OpcodeUpdate)
+	}
+	if got.Question[0].Name != "example.com." {
+		t.Errorf("got %s, want example.com.", got.Question[0].Name)
+	}
+	if got.IsTsig() == nil || tsigErr != nil {
+		t.Errorf("update should be signed, got error %v", tsigErr)
+	}
+	want := []string{
+		"host.example.com.\t0\tCLASS255\tA\t",
+		"host.example.com.\t0\tCLASS255\tAAAA\t",
+		"host.example.com.\t300\tIN\tA\t10.0.0.1",
+		"host.example.com.\t300\tIN\tAAAA\t2001:db8::1",
+	}
+	if len(got.Ns) != len(want) {
+		t.Fatalf("got %v, want %v", got.Ns, want)
+	}
+	for i, rr := range got.Ns {
+		if rr.String() != want[i] {
+			t.Errorf("got %q, want %q", rr.String(), want[i])
+		}
+	}
+
+	// test without tsig
+	u.TSIG = nil
+	if err := u.Register("host", ips); err != nil {
+		t.Fatal(err)
+	}
+	if got.IsTsig() != nil {
+		t.Error("update should not be signed")
+	}
+}
+
+// TestUpdaterRegisterError tests Register of Updater with errors
+func TestUpdaterRegisterError(t *testing.T) {
+	addr := startTestServer(t, nil, func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetRcode(r, dns.RcodeRefused)
+		_ = w.WriteMsg(m)
+	})
+	u := &Updater{
+		Server:  addr,
+		Zone:    "example.com.",
+		Timeout: time.Second,
+	}
+	ips := []net.IP{net.ParseIP("10.0.0.1")}
+
+	// test invalid host names
+	for _, host := range []string{"", "host.example.com"} {
+		if err := u.Register(host, ips); err == nil {
+			t.Errorf("register of %q should fail", host)
+		}
+	}
+
+	// test no ips
+	if err := u.Register("host", nil); err == nil {
+		t.Error("register without ips should fail")
+	}
+
+	// test refused update
+	if err := u.Register("host", ips); err == nil {
+		t.Error("refused register should fail")
+	}
+}
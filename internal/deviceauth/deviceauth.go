@@ -0,0 +1,188 @@
+// Package deviceauth implements the OAuth 2.0 device authorization grant
+// (RFC 8628), used for logins that are approved on another device, e.g., a
+// phone
+package deviceauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// grantType is the grant type of device access token requests
+	grantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+	// defaultInterval is the default polling interval in seconds
+	defaultInterval = 5
+
+	// slowDownInterval is the polling interval increase in seconds after
+	// a slow down error
+	slowDownInterval = 5
+
+	// maxResponseSize is the maximum size of responses in bytes
+	maxResponseSize = 64 * 1024
+)
+
+// intervalUnit is the unit of polling intervals and expiry times, it can be
+// replaced for testing
+var intervalUnit = time.Second
+
+// Errors of the token endpoint
+var (
+	ErrAccessDenied = errors.New("device authorization denied")
+	ErrExpiredToken = errors.New("device code expired")
+)
+
+// Code is the response of the device authorization endpoint
+type Code struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// tokenResponse is the response of the token endpoint
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	Error       string `json:"error"`
+}
+
+// Client is a device authorization client
+type Client struct {
+	// AuthorizationURL is the URL of the device authorization endpoint
+	AuthorizationURL string
+
+	// TokenURL is the URL of the token endpoint
+	TokenURL string
+
+	// ClientID is the OAuth client identifier
+	ClientID string
+
+	// Scope is the requested scope, empty for the default scope
+	Scope string
+
+	// HTTPClient is the HTTP client used for requests
+	HTTPClient *http.Client
+}
+
+// post sends a POST request with form to u and returns the status code and
+// body of the response
+func (c *Client) post(ctx context.Context, u string, form url.Values) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u,
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, body, nil
+}
+
+// Authorize requests a new device code and user code
+func (c *Client) Authorize(ctx context.Context) (*Code, error) {
+	form := url.Values{"client_id": {c.ClientID}}
+	if c.Scope != "" {
+		form.Set("scope", c.Scope)
+	}
+	status, body, err := c.post(ctx, c.AuthorizationURL, form)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("device authorization failed with status %d", status)
+	}
+
+	code := &Code{}
+	if err := json.Unmarshal(body, code); err != nil {
+		return nil, err
+	}
+	if code.DeviceCode == "" || code.UserCode == "" ||
+		code.VerificationURI == "" {
+		return nil, errors.New("invalid device authorization response")
+	}
+	if code.Interval <= 0 {
+		code.Interval = defaultInterval
+	}
+	return code, nil
+}
+
+// pollError returns ErrExpiredToken if the deadline of ctx is exceeded,
+// err otherwise
+func pollError(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrExpiredToken
+	}
+	return err
+}
+
+// Poll polls the token endpoint until the user approved the device code and
+// returns the access token; it returns an error if the user denied the
+// request, the code expired or ctx is canceled
+func (c *Client) Poll(ctx context.Context, code *Code) (string, error) {
+	if code.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx,
+			time.Duration(code.ExpiresIn)*intervalUnit)
+		defer cancel()
+	}
+
+	form := url.Values{
+		"grant_type":  {grantType},
+		"device_code": {code.DeviceCode},
+		"client_id":   {c.ClientID},
+	}
+	interval := code.Interval
+	for {
+		select {
+		case <-time.After(time.Duration(interval) * intervalUnit):
+		case <-ctx.Done():
+			return "", pollError(ctx, ctx.Err())
+		}
+
+		_, body, err := c.post(ctx, c.TokenURL, form)
+		if err != nil {
+			return "", pollError(ctx, err)
+		}
+		token := &tokenResponse{}
+		if err := json.Unmarshal(body, token); err != nil {
+			return "", err
+		}
+
+		switch token.Error {
+		case "":
+			if token.AccessToken == "" {
+				return "", errors.New("invalid token response")
+			}
+			return token.AccessToken, nil
+		case "authorization_pending":
+		case "slow_down":
+			interval += slowDownInterval
+		case "access_denied":
+			return "", ErrAccessDenied
+		case "expired_token":
+			return "", ErrExpiredToken
+		default:
+			return "", fmt.Errorf("device authorization failed: %s", token.Error)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package deviceauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testServer returns a test server with device authorization endpoint
+// "/device" and token endpoint "/token" that returns the token errors in
+// order, followed by the access token "cookie"
+func testServer(t *testing.T, errs ...string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		switch r.URL.Path {
+		case "/device":
+			if r.Form.Get("client_id") != "oc-daemon" ||
+				r.Form.Get("scope") != "vpn" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"device_code":"device","user_code":"USER-CODE",` +
+				`"verification_uri":"https://idp.example.com/device",` +
+				`"expires_in":60}`))
+		case "/token":
+			if r.Form.Get("grant_type") != grantType ||
+				r.Form.Get("device_code") != "device" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if len(errs) > 0 {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"` + errs[0] + `"}`))
+				errs = errs[1:]
+				return
+			}
+			_, _ = w.Write([]byte(`{"access_token":"cookie"}`))
+		}
+	}))
+}
+
+// testClient returns a client for the test server ts
+func testClient(ts *httptest.Server) *Client {
+	return &Client{
+		AuthorizationURL: ts.URL + "/device",
+		TokenURL:         ts.URL + "/token",
+		ClientID:         "oc-daemon",
+		Scope:            "vpn",
+		HTTPClient:       ts.Client(),
+	}
+}
+
+// TestClientAuthorize tests Authorize of Client
+func TestClientAuthorize(t *testing.T) {
+	ts := testServer(t)
+	defer ts.Close()
+	c := testClient(ts)
+
+	// valid
+	got, err := c.Authorize(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Code{
+		DeviceCode:      "device",
+		UserCode:        "USER-CODE",
+		VerificationURI: "https://idp.example.com/device",
+		ExpiresIn:       60,
+		Interval:        defaultInterval,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// invalid client
+	c.ClientID = "invalid"
+	if _, err := c.Authorize(context.Background()); err == nil {
+		t.Error("authorize should fail")
+	}
+}
+
+// TestClientPoll tests Poll of Client
+func TestClientPoll(t *testing.T) {
+	defer func(unit time.Duration) { intervalUnit = unit }(intervalUnit)
+	intervalUnit = time.Millisecond
+	code := &Code{DeviceCode: "device", Interval: 1, ExpiresIn: 1000}
+
+	// approved
+	ts := testServer(t, "authorization_pending", "slow_down")
+	defer ts.Close()
+	got, err := testClient(ts).Poll(context.Background(), code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "cookie" {
+		t.Errorf("got %s, want cookie", got)
+	}
+
+	// errors
+	for _, test := range []struct {
+		err  string
+		want error
+	}{
+		{"access_denied", ErrAccessDenied},
+		{"expired_token", ErrExpiredToken},
+	} {
+		ts := testServer(t, test.err)
+		defer ts.Close()
+		if _, err := testClient(ts).Poll(context.Background(), code); !errors.Is(err, test.want) {
+			t.Errorf("got %v, want %v", err, test.want)
+		}
+	}
+
+	// other error
+	ts = testServer(t, "invalid_client")
+	defer ts.Close()
+	if _, err := testClient(ts).Poll(context.Background(), code); err == nil {
+		t.Error("poll should fail")
+	}
+
+	// code expires
+	ts = testServer(t, "authorization_pending", "authorization_pending",
+		"authorization_pending", "authorization_pending")
+	defer ts.Close()
+	expiring := &Code{DeviceCode: "device", Interval: 20, ExpiresIn: 30}
+	if _, err := testClient(ts).Poll(context.Background(), expiring); !errors.Is(err, ErrExpiredToken) {
+		t.Errorf("got %v, want %v", err, ErrExpiredToken)
+	}
+}
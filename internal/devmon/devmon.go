@@ -1,6 +1,8 @@
 package devmon
 
 import (
+	"context"
+	"fmt"
 	"net"
 	"path/filepath"
 
@@ -15,13 +17,17 @@ type Update struct {
 	Device string
 	Type   string
 	Index  int
+
+	// Up indicates if the device is up and has a carrier
+	Up bool
 }
 
 // DevMon is a device monitor
 type DevMon struct {
 	updates chan *Update
 	upsDone chan struct{}
-	done    chan struct{}
+	done    <-chan struct{}
+	cancel  context.CancelFunc
 }
 
 // sendUpdate sends update over the update channel
@@ -70,32 +76,31 @@ func (d *DevMon) handleLink(add bool, link netlink.Link) {
 		Device: attrs.Name,
 		Type:   typ,
 		Index:  attrs.Index,
+		Up: attrs.Flags&net.FlagUp != 0 &&
+			attrs.RawFlags&unix.IFF_LOWER_UP != 0,
 	}
 	d.sendUpdate(update)
 }
 
 // RegisterLinkUpdates registers for link update events
-var RegisterLinkUpdates = func(d *DevMon) chan netlink.LinkUpdate {
+var RegisterLinkUpdates = func(d *DevMon) (chan netlink.LinkUpdate, error) {
 	// register for link update events
 	events := make(chan netlink.LinkUpdate)
 	options := netlink.LinkSubscribeOptions{
 		ListExisting: true,
 	}
 	if err := netlink.LinkSubscribeWithOptions(events, d.upsDone, options); err != nil {
-		log.WithError(err).Fatal("DevMon link update subscribe error")
+		return nil, err
 	}
 
-	return events
+	return events, nil
 }
 
 // start starts the device monitor
-func (d *DevMon) start() {
+func (d *DevMon) start(events chan netlink.LinkUpdate) {
 	defer close(d.updates)
 	defer close(d.upsDone)
 
-	// register for link update events
-	events := RegisterLinkUpdates(d)
-
 	// handle link update events
 	for {
 		select {
@@ -103,7 +108,12 @@ func (d *DevMon) start() {
 			if !ok {
 				// unexpected close of events, try to re-open
 				log.Error("DevMon got unexpected close of link events")
-				events = RegisterLinkUpdates(d)
+				var err error
+				events, err = RegisterLinkUpdates(d)
+				if err != nil {
+					log.WithError(err).Error("DevMon link update subscribe error")
+					return
+				}
 				break
 			}
 			switch e.Header.Type {
@@ -128,14 +138,24 @@ func (d *DevMon) start() {
 	}
 }
 
-// Start starts the device monitor
-func (d *DevMon) Start() {
-	go d.start()
+// Start starts the device monitor, it runs until Stop is called or ctx is
+// canceled
+func (d *DevMon) Start(ctx context.Context) error {
+	// register for link update events
+	events, err := RegisterLinkUpdates(d)
+	if err != nil {
+		return fmt.Errorf("DevMon link update subscribe error: %w", err)
+	}
+
+	ctx, d.cancel = context.WithCancel(ctx)
+	d.done = ctx.Done()
+	go d.start(events)
+	return nil
 }
 
 // Stop stops the device monitor
 func (d *DevMon) Stop() {
-	close(d.done)
+	d.cancel()
 	for range d.updates {
 		// wait for channel shutdown
 	}
@@ -151,6 +171,5 @@ func NewDevMon() *DevMon {
 	return &DevMon{
 		updates: make(chan *Update),
 		upsDone: make(chan struct{}),
-		done:    make(chan struct{}),
 	}
 }
@@ -1,7 +1,10 @@
 package devmon
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net"
 	"testing"
 
 	"github.com/vishvananda/netlink"
@@ -13,10 +16,12 @@ func TestDevMonStartStop(t *testing.T) {
 	devMon := NewDevMon()
 
 	// test without LinkUpdates
-	RegisterLinkUpdates = func(d *DevMon) chan netlink.LinkUpdate {
-		return nil
+	RegisterLinkUpdates = func(d *DevMon) (chan netlink.LinkUpdate, error) {
+		return nil, nil
+	}
+	if err := devMon.Start(context.Background()); err != nil {
+		t.Fatal(err)
 	}
-	devMon.Start()
 	devMon.Stop()
 
 	// helper function for LinkUpdates
@@ -36,12 +41,14 @@ func TestDevMonStartStop(t *testing.T) {
 
 	// test with LinkUpdates
 	devMon = NewDevMon()
-	RegisterLinkUpdates = func(d *DevMon) chan netlink.LinkUpdate {
+	RegisterLinkUpdates = func(d *DevMon) (chan netlink.LinkUpdate, error) {
 		updates := make(chan netlink.LinkUpdate)
 		go linkUpdates(updates, d.upsDone)
-		return updates
+		return updates, nil
+	}
+	if err := devMon.Start(context.Background()); err != nil {
+		t.Fatal(err)
 	}
-	devMon.Start()
 	for i := 0; i < 3; i++ {
 		log.Println(<-devMon.Updates())
 	}
@@ -50,7 +57,7 @@ func TestDevMonStartStop(t *testing.T) {
 	// test with unexpected close and LinkUpdates
 	devMon = NewDevMon()
 	runOnce := false
-	RegisterLinkUpdates = func(d *DevMon) chan netlink.LinkUpdate {
+	RegisterLinkUpdates = func(d *DevMon) (chan netlink.LinkUpdate, error) {
 		updates := make(chan netlink.LinkUpdate)
 		if !runOnce {
 			runOnce = true
@@ -58,13 +65,26 @@ func TestDevMonStartStop(t *testing.T) {
 		} else {
 			go linkUpdates(updates, d.upsDone)
 		}
-		return updates
+		return updates, nil
+	}
+	if err := devMon.Start(context.Background()); err != nil {
+		t.Fatal(err)
 	}
-	devMon.Start()
 	log.Println(<-devMon.Updates())
 	devMon.Stop()
 }
 
+// TestDevMonStartError tests Start of DevMon with register error
+func TestDevMonStartError(t *testing.T) {
+	RegisterLinkUpdates = func(d *DevMon) (chan netlink.LinkUpdate, error) {
+		return nil, errors.New("test error")
+	}
+	devMon := NewDevMon()
+	if err := devMon.Start(context.Background()); err == nil {
+		t.Error("start should fail")
+	}
+}
+
 // TestDevMonUpdates tests Updates of DevMon
 func TestDevMonUpdates(t *testing.T) {
 	devMon := NewDevMon()
@@ -79,9 +99,34 @@ func TestDevMonUpdates(t *testing.T) {
 func TestNewDevMon(t *testing.T) {
 	devMon := NewDevMon()
 	if devMon.updates == nil ||
-		devMon.upsDone == nil ||
-		devMon.done == nil {
+		devMon.upsDone == nil {
 
 		t.Errorf("got nil, want != nil")
 	}
 }
+
+// TestDevMonHandleLink tests handleLink of DevMon
+func TestDevMonHandleLink(t *testing.T) {
+	d := NewDevMon()
+	d.done = make(chan struct{})
+	for _, test := range []struct {
+		flags    net.Flags
+		rawFlags uint32
+		want     bool
+	}{
+		{0, 0, false},
+		{net.FlagUp, unix.IFF_UP, false},
+		{net.FlagUp, unix.IFF_UP | unix.IFF_LOWER_UP, true},
+	} {
+		link := &netlink.Device{LinkAttrs: netlink.LinkAttrs{
+			Name:     "oc-daemon-test",
+			Index:    2,
+			Flags:    test.flags,
+			RawFlags: test.rawFlags,
+		}}
+		go d.handleLink(true, link)
+		if u := <-d.Updates(); u.Up != test.want {
+			t.Errorf("got %t, want %t", u.Up, test.want)
+		}
+	}
+}
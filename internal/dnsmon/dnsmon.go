@@ -1,6 +1,9 @@
 package dnsmon
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/fsnotify/fsnotify"
 	log "github.com/sirupsen/logrus"
 )
@@ -17,7 +20,8 @@ const (
 // DNSMon is a DNS monitor
 type DNSMon struct {
 	updates chan struct{}
-	done    chan struct{}
+	done    <-chan struct{}
+	cancel  context.CancelFunc
 }
 
 // isResolvConfEvent checks if event is a resolv.conf file event
@@ -43,14 +47,8 @@ func (d *DNSMon) sendUpdate() {
 }
 
 // start starts the DNSMon
-func (d *DNSMon) start() {
+func (d *DNSMon) start(watcher *fsnotify.Watcher) {
 	defer close(d.updates)
-
-	// create watcher
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		log.WithError(err).Fatal("DNSMon file watcher error")
-	}
 	defer func() {
 		if err := watcher.Close(); err != nil {
 			log.WithError(err).Error("DNSMon file watcher close error")
@@ -97,14 +95,23 @@ func (d *DNSMon) start() {
 	}
 }
 
-// Start starts the DNSMon
-func (d *DNSMon) Start() {
-	go d.start()
+// Start starts the DNSMon, it runs until Stop is called or ctx is canceled
+func (d *DNSMon) Start(ctx context.Context) error {
+	// create watcher
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("DNSMon file watcher error: %w", err)
+	}
+
+	ctx, d.cancel = context.WithCancel(ctx)
+	d.done = ctx.Done()
+	go d.start(watcher)
+	return nil
 }
 
 // Stop stops the DNSMon
 func (d *DNSMon) Stop() {
-	close(d.done)
+	d.cancel()
 	for range d.updates {
 		// wait for channel shutdown
 	}
@@ -119,6 +126,5 @@ func (d *DNSMon) Updates() chan struct{} {
 func NewDNSMon() *DNSMon {
 	return &DNSMon{
 		updates: make(chan struct{}),
-		done:    make(chan struct{}),
 	}
 }
@@ -1,11 +1,16 @@
 package dnsmon
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 // TestDNSMonStartStop tests Start and Stop of DNSMon
 func TestDNSMonStartStop(t *testing.T) {
 	dnsMon := NewDNSMon()
-	dnsMon.Start()
+	if err := dnsMon.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
 	dnsMon.Stop()
 }
 
@@ -22,9 +27,7 @@ func TestDNSMonUpdates(t *testing.T) {
 // TestNewDNSMon tests NewDNSMon
 func TestNewDNSMon(t *testing.T) {
 	dnsMon := NewDNSMon()
-	if dnsMon.updates == nil ||
-		dnsMon.done == nil {
-
+	if dnsMon.updates == nil {
 		t.Errorf("got nil, want != nil")
 	}
 }
@@ -0,0 +1,63 @@
+package dnsproxy
+
+import (
+	"crypto/tls"
+	"net"
+	"syscall"
+
+	"github.com/miekg/dns"
+	"golang.org/x/sys/unix"
+)
+
+// newClients returns the clients for exchanges with remote servers by
+// transport, the clients use dialer if it is not nil
+func newClients(dialer *net.Dialer) map[string]*dns.Client {
+	return map[string]*dns.Client{
+		TransportUDP: {Net: "udp", Dialer: dialer},
+		TransportTCP: {Net: "tcp", Dialer: dialer},
+		TransportTLS: {
+			Net:       "tcp-tls",
+			Dialer:    dialer,
+			TLSConfig: &tls.Config{MinVersion: tls.VersionTLS12},
+		},
+	}
+}
+
+// newSockoptDialer returns a dialer that calls setsockopt on the file
+// descriptor of its sockets
+func newSockoptDialer(setsockopt func(fd int) error) *net.Dialer {
+	control := func(network, address string, c syscall.RawConn) error {
+		var soerr error
+		if err := c.Control(func(fd uintptr) {
+			soerr = setsockopt(int(fd))
+		}); err != nil {
+			return err
+		}
+		return soerr
+	}
+	return &net.Dialer{
+		Control: control,
+	}
+}
+
+// newDeviceDialer returns a dialer that binds its sockets to network device
+// with SO_BINDTODEVICE, it returns nil if device is empty
+func newDeviceDialer(device string) *net.Dialer {
+	if device == "" {
+		return nil
+	}
+	return newSockoptDialer(func(fd int) error {
+		return unix.BindToDevice(fd, device)
+	})
+}
+
+// newMarkDialer returns a dialer that sets the firewall mark on its sockets
+// with SO_MARK, it returns nil if mark is 0
+func newMarkDialer(mark int) *net.Dialer {
+	if mark == 0 {
+		return nil
+	}
+	return newSockoptDialer(func(fd int) error {
+		return unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_MARK, mark)
+	})
+}
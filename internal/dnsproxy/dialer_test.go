@@ -0,0 +1,58 @@
+package dnsproxy
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+// TestNewClients tests newClients
+func TestNewClients(t *testing.T) {
+	dialer := newMarkDialer(1)
+	for transport, client := range newClients(dialer) {
+		if client.Dialer != dialer {
+			t.Errorf("%s: got %p, want %p", transport, client.Dialer, dialer)
+		}
+	}
+}
+
+// TestNewDeviceDialer tests newDeviceDialer
+func TestNewDeviceDialer(t *testing.T) {
+	if d := newDeviceDialer(""); d != nil {
+		t.Errorf("got %v, want nil", d)
+	}
+
+	remote, stop := startTestRemote(t)
+	defer stop()
+
+	// bind to loopback device
+	c := &dns.Client{Net: "udp", Dialer: newDeviceDialer("lo")}
+	q := new(dns.Msg)
+	q.SetQuestion("example.com.", dns.TypeA)
+	if _, _, err := c.Exchange(q, remote); err != nil {
+		t.Skipf("could not bind to device: %v", err)
+	}
+
+	// bind to device that does not exist
+	c.Dialer = newDeviceDialer("does-not-exist")
+	if _, _, err := c.Exchange(q, remote); err == nil {
+		t.Error("exchange with invalid device should fail")
+	}
+}
+
+// TestNewMarkDialer tests newMarkDialer
+func TestNewMarkDialer(t *testing.T) {
+	if d := newMarkDialer(0); d != nil {
+		t.Errorf("got %v, want nil", d)
+	}
+
+	remote, stop := startTestRemote(t)
+	defer stop()
+
+	c := &dns.Client{Net: "udp", Dialer: newMarkDialer(42)}
+	q := new(dns.Msg)
+	q.SetQuestion("example.com.", dns.TypeA)
+	if _, _, err := c.Exchange(q, remote); err != nil {
+		t.Skipf("could not set mark: %v", err)
+	}
+}
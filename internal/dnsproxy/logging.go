@@ -0,0 +1,6 @@
+package dnsproxy
+
+import "github.com/telekom-mms/oc-daemon/internal/logging"
+
+// log is the logger of the DNS-Proxy
+var log = logging.Component(logging.ComponentDNSProxy)
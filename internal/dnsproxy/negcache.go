@@ -0,0 +1,170 @@
+package dnsproxy
+
+import (
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/telekom-mms/oc-daemon/internal/clock"
+)
+
+const (
+	// negativeCacheMaxTTL is the maximum time in seconds that negative
+	// answers are cached, regardless of their SOA record
+	negativeCacheMaxTTL = 900
+
+	// negativeCacheSize is the maximum number of cached negative answers
+	negativeCacheSize = 4096
+)
+
+// negativeKey is the key of a negative answer in the negative cache
+type negativeKey struct {
+	name   string
+	qtype  uint16
+	qclass uint16
+}
+
+// negativeEntry is a negative answer in the negative cache, it is only valid
+// for the forwarding configuration version it was received with
+type negativeEntry struct {
+	reply   *dns.Msg
+	version uint64
+	stored  time.Time
+	expires time.Time
+}
+
+// negativeCache caches negative answers of remote servers, NXDOMAIN and
+// NODATA, as specified in RFC 2308, so repeated queries for nonexistent
+// names, e.g., with search domain expansion, are not forwarded again
+type negativeCache struct {
+	mutex   sync.Mutex
+	clock   clock.Clock
+	entries map[negativeKey]*negativeEntry
+}
+
+// newNegativeKey returns the negative cache key of question q
+func newNegativeKey(q dns.Question) negativeKey {
+	return negativeKey{
+		name:   dns.CanonicalName(q.Name),
+		qtype:  q.Qtype,
+		qclass: q.Qclass,
+	}
+}
+
+// negativeTTL returns the time in seconds that reply can be cached as
+// negative answer: the minimum of the TTL and the minimum TTL field of the
+// SOA record in the authority section, limited to negativeCacheMaxTTL; it
+// returns false if reply is not a cacheable negative answer
+func negativeTTL(reply *dns.Msg) (uint32, bool) {
+	if reply.Truncated || len(reply.Answer) != 0 {
+		return 0, false
+	}
+	if reply.Rcode != dns.RcodeNameError && reply.Rcode != dns.RcodeSuccess {
+		return 0, false
+	}
+	for _, rr := range reply.Ns {
+		soa, ok := rr.(*dns.SOA)
+		if !ok {
+			continue
+		}
+		ttl := soa.Hdr.Ttl
+		if soa.Minttl < ttl {
+			ttl = soa.Minttl
+		}
+		if ttl > negativeCacheMaxTTL {
+			ttl = negativeCacheMaxTTL
+		}
+		return ttl, ttl > 0
+	}
+	return 0, false
+}
+
+// removeStale removes expired entries and entries of other versions than
+// version, it must be called with the mutex held
+func (c *negativeCache) removeStale(now time.Time, version uint64) {
+	for k, e := range c.entries {
+		if e.version != version || !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// add adds reply to request r that was received with the forwarding
+// configuration version if it is a cacheable negative answer
+func (c *negativeCache) add(r, reply *dns.Msg, version uint64) {
+	ttl, ok := negativeTTL(reply)
+	if !ok {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := newNegativeKey(r.Question[0])
+	if e, ok := c.entries[key]; ok && e.version > version {
+		// keep answer of newer config
+		return
+	}
+	now := c.clock.Now()
+	if len(c.entries) >= negativeCacheSize {
+		c.removeStale(now, version)
+		if len(c.entries) >= negativeCacheSize {
+			return
+		}
+	}
+	c.entries[key] = &negativeEntry{
+		reply:   reply.Copy(),
+		version: version,
+		stored:  now,
+		expires: now.Add(time.Duration(ttl) * time.Second),
+	}
+}
+
+// get returns the cached negative answer for request r with the forwarding
+// configuration version as reply to r, nil if there is none; the TTLs in the
+// reply are reduced by the time the answer has been cached
+func (c *negativeCache) get(r *dns.Msg, version uint64) *dns.Msg {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := newNegativeKey(r.Question[0])
+	e := c.entries[key]
+	if e == nil {
+		return nil
+	}
+	now := c.clock.Now()
+	if e.version != version || !now.Before(e.expires) {
+		delete(c.entries, key)
+		return nil
+	}
+
+	reply := e.reply.Copy()
+	reply.Id = r.Id
+	reply.Question = append([]dns.Question{}, r.Question...)
+	elapsed := uint32(now.Sub(e.stored) / time.Second)
+	for _, rr := range reply.Ns {
+		h := rr.Header()
+		if h.Ttl > elapsed {
+			h.Ttl -= elapsed
+		} else {
+			h.Ttl = 0
+		}
+	}
+	return reply
+}
+
+// flush removes all entries
+func (c *negativeCache) flush() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries = make(map[negativeKey]*negativeEntry)
+}
+
+// newNegativeCache returns a new negativeCache
+func newNegativeCache() *negativeCache {
+	return &negativeCache{
+		clock:   clock.New(),
+		entries: make(map[negativeKey]*negativeEntry),
+	}
+}
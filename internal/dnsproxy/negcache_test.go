@@ -0,0 +1,151 @@
+package dnsproxy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/telekom-mms/oc-daemon/internal/clock"
+)
+
+// testNegativeReply returns a negative reply with rcode to request r with a
+// SOA record with ttl and minimum ttl minttl
+func testNegativeReply(r *dns.Msg, rcode int, ttl, minttl uint32) *dns.Msg {
+	reply := new(dns.Msg)
+	reply.SetRcode(r, rcode)
+	reply.Ns = append(reply.Ns, &dns.SOA{
+		Hdr: dns.RR_Header{
+			Name:   "example.com.",
+			Rrtype: dns.TypeSOA,
+			Class:  dns.ClassINET,
+			Ttl:    ttl,
+		},
+		Ns:     "ns.example.com.",
+		Mbox:   "admin.example.com.",
+		Minttl: minttl,
+	})
+	return reply
+}
+
+// TestNegativeTTL tests negativeTTL
+func TestNegativeTTL(t *testing.T) {
+	r := new(dns.Msg)
+	r.SetQuestion("nonexistent.example.com.", dns.TypeA)
+
+	// negative answers
+	for _, test := range []struct {
+		rcode       int
+		ttl, minttl uint32
+		want        uint32
+	}{
+		{dns.RcodeNameError, 3600, 60, 60},
+		{dns.RcodeNameError, 30, 60, 30},
+		{dns.RcodeSuccess, 300, 600, 300},
+		{dns.RcodeNameError, 86400, 86400, negativeCacheMaxTTL},
+	} {
+		reply := testNegativeReply(r, test.rcode, test.ttl, test.minttl)
+		got, ok := negativeTTL(reply)
+		if !ok || got != test.want {
+			t.Errorf("%v: got %d, %t, want %d", test, got, ok, test.want)
+		}
+	}
+
+	// not cacheable
+	answer := testNegativeReply(r, dns.RcodeSuccess, 300, 300)
+	answer.Answer = append(answer.Answer, &dns.CNAME{
+		Hdr:    dns.RR_Header{Name: r.Question[0].Name, Rrtype: dns.TypeCNAME},
+		Target: "other.example.com.",
+	})
+	truncated := testNegativeReply(r, dns.RcodeNameError, 300, 300)
+	truncated.Truncated = true
+	noSOA := testNegativeReply(r, dns.RcodeNameError, 300, 300)
+	noSOA.Ns = nil
+	for _, reply := range []*dns.Msg{
+		answer,
+		truncated,
+		noSOA,
+		testNegativeReply(r, dns.RcodeServerFailure, 300, 300),
+		testNegativeReply(r, dns.RcodeNameError, 0, 300),
+	} {
+		if _, ok := negativeTTL(reply); ok {
+			t.Errorf("%v should not be cacheable", reply)
+		}
+	}
+}
+
+// TestNegativeCache tests add, get and flush of negativeCache
+func TestNegativeCache(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	c := newNegativeCache()
+	c.clock = fake
+
+	r := new(dns.Msg)
+	r.SetQuestion("nonexistent.example.com.", dns.TypeA)
+	if c.get(r, 1) != nil {
+		t.Error("empty cache should not return reply")
+	}
+
+	// cached reply, question name is case-insensitive, TTL is reduced
+	c.add(r, testNegativeReply(r, dns.RcodeNameError, 3600, 60), 1)
+	fake.Advance(20 * time.Second)
+	q := new(dns.Msg)
+	q.SetQuestion("NonExistent.Example.com.", dns.TypeA)
+	got := c.get(q, 1)
+	if got == nil {
+		t.Fatal("cache should return reply")
+	}
+	if got.Id != q.Id || got.Question[0].Name != q.Question[0].Name ||
+		got.Rcode != dns.RcodeNameError || got.Ns[0].Header().Ttl != 3580 {
+		t.Errorf("got invalid reply %v", got)
+	}
+
+	// other type and other version
+	q.SetQuestion("nonexistent.example.com.", dns.TypeAAAA)
+	if c.get(q, 1) != nil {
+		t.Error("cache should not return reply for other type")
+	}
+	if c.get(r, 2) != nil {
+		t.Error("cache should not return reply for other version")
+	}
+	if len(c.entries) != 0 {
+		t.Error("entry of other version should be removed")
+	}
+
+	// expired reply
+	c.add(r, testNegativeReply(r, dns.RcodeNameError, 3600, 60), 1)
+	fake.Advance(60 * time.Second)
+	if c.get(r, 1) != nil {
+		t.Error("cache should not return expired reply")
+	}
+
+	// reply of older version does not replace reply of newer version
+	c.add(r, testNegativeReply(r, dns.RcodeNameError, 3600, 60), 3)
+	c.add(r, testNegativeReply(r, dns.RcodeNameError, 3600, 60), 2)
+	if c.get(r, 3) == nil {
+		t.Error("cache should return reply of newer version")
+	}
+
+	// flush
+	c.add(r, testNegativeReply(r, dns.RcodeNameError, 3600, 60), 1)
+	c.flush()
+	if c.get(r, 1) != nil {
+		t.Error("cache should not return reply after flush")
+	}
+
+	// full cache, stale entries are removed, no new entries are added
+	// without stale entries
+	for i := 0; i < negativeCacheSize; i++ {
+		c.entries[negativeKey{name: string(rune(i))}] = &negativeEntry{
+			version: 1,
+			expires: fake.Now().Add(time.Minute),
+		}
+	}
+	c.add(r, testNegativeReply(r, dns.RcodeNameError, 3600, 60), 1)
+	if c.get(r, 1) != nil {
+		t.Error("full cache should not add reply")
+	}
+	c.add(r, testNegativeReply(r, dns.RcodeNameError, 3600, 60), 2)
+	if c.get(r, 2) == nil || len(c.entries) != 1 {
+		t.Error("stale entries should be replaced")
+	}
+}
@@ -1,11 +1,17 @@
 package dnsproxy
 
 import (
+	"context"
+	"fmt"
 	"math/rand"
+	"net"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/miekg/dns"
-	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/clock"
 )
 
 const (
@@ -16,16 +22,89 @@ const (
 
 // Proxy is a DNS proxy
 type Proxy struct {
+	// leaks is the number of queries blocked in tunnel all DNS mode,
+	// first field for 64-bit alignment of atomic operations
+	leaks uint64
+
 	udp     *dns.Server
 	tcp     *dns.Server
+	reports chan *Report
+	done    <-chan struct{}
+	cancel  context.CancelFunc
+	clock   clock.Clock
+
+	// forwarding configuration with remotes and watches, both are
+	// replaced as a whole on updates and the version is incremented, so
+	// a request always uses one consistent configuration
+	config  sync.RWMutex
+	version uint64
 	remotes *Remotes
 	watches *Watches
-	reports chan *Report
-	done    chan struct{}
+
+	// clients for exchanges with remote servers and with fallback
+	// servers by transport, see SetDevice and SetFallbackMark
+	clients         map[string]*dns.Client
+	fallbackClients map[string]*dns.Client
+
+	// negative caches negative answers of remote and fallback servers
+	negative *negativeCache
 
 	// channels for temp watch cleaning goroutine
 	stopClean chan struct{}
 	doneClean chan struct{}
+
+	// fallback servers for names without remotes, e.g., the local
+	// resolver, and tunnel all DNS mode that disables them
+	mutex     sync.Mutex
+	fallback  []string
+	tunnelAll bool
+}
+
+// getConfig returns the current forwarding configuration
+func (p *Proxy) getConfig() (remotes *Remotes, watches *Watches, version uint64) {
+	p.config.RLock()
+	defer p.config.RUnlock()
+
+	return p.remotes, p.watches, p.version
+}
+
+// getClients returns the current clients for remote servers and for
+// fallback servers
+func (p *Proxy) getClients() (clients, fallbackClients map[string]*dns.Client) {
+	p.config.RLock()
+	defer p.config.RUnlock()
+
+	return p.clients, p.fallbackClients
+}
+
+// getWatches returns the current watches
+func (p *Proxy) getWatches() *Watches {
+	_, watches, _ := p.getConfig()
+	return watches
+}
+
+// getFallback returns the fallback servers for name, returns nil and counts
+// the leak if fallback servers are not allowed in tunnel all DNS mode
+func (p *Proxy) getFallback(name string) []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.tunnelAll {
+		atomic.AddUint64(&p.leaks, 1)
+		log.WithField("name", name).
+			Warn("DNS-Proxy blocked query to fallback servers in tunnel all DNS mode")
+		return nil
+	}
+	return p.fallback
+}
+
+// refuse sends a refused reply for request r to the client
+func refuse(w dns.ResponseWriter, r *dns.Msg) {
+	reply := new(dns.Msg)
+	reply.SetRcode(r, dns.RcodeRefused)
+	if err := w.WriteMsg(reply); err != nil {
+		log.WithError(err).Error("DNS-Proxy could not send refused reply")
+	}
 }
 
 // handleRequest handles a dns client request
@@ -37,28 +116,50 @@ func (p *Proxy) handleRequest(w dns.ResponseWriter, r *dns.Msg) {
 		return
 	}
 
+	// use the same forwarding configuration for the whole request, even
+	// if it is replaced while the request is in flight
+	remoteMap, watches, version := p.getConfig()
+	clients, fallbackClients := p.getClients()
+
 	// forward request to remote server and get reply
-	remotes := p.remotes.Get(r.Question[0].Name)
+	remotes := remoteMap.Get(r.Question[0].Name)
+	if len(remotes) == 0 {
+		remotes = p.getFallback(r.Question[0].Name)
+		clients = fallbackClients
+	}
 	if len(remotes) == 0 {
 		log.WithField("name", r.Question[0].Name).
 			Error("DNS-Proxy has no remotes for question name")
-		// TODO: send error reply to client?
+		refuse(w, r)
 		return
 	}
+	// answer from negative cache, e.g., for nonexistent names queried
+	// with search domain expansion
+	if reply := p.negative.get(r, version); reply != nil {
+		log.WithField("name", r.Question[0].Name).
+			Debug("DNS-Proxy answering from negative cache")
+		if err := w.WriteMsg(reply); err != nil {
+			log.WithError(err).Error("DNS-Proxy could not send cached reply")
+		}
+		return
+	}
+
 	// pick random remote server
 	// TODO: query all servers and take fastest reply?
 	remote := remotes[rand.Intn(len(remotes))]
-	reply, err := dns.Exchange(r, remote)
+	reply, err := exchange(clients, r, remote)
 	if err != nil {
-		log.WithError(err).Debug("DNS-Proxy DNS exchange error")
+		log.WithError(err).WithField("version", version).
+			Debug("DNS-Proxy DNS exchange error")
 		return
 	}
+	p.negative.add(r, reply, version)
 
 	// parse answers in reply from remote server
 	for _, a := range reply.Answer {
 		name := a.Header().Name
-		if !p.watches.Contains(r.Question[0].Name) &&
-			!p.watches.Contains(name) {
+		if !watches.Contains(r.Question[0].Name) &&
+			!watches.Contains(name) {
 			// not on watch list, ignore answer
 			continue
 		}
@@ -96,11 +197,11 @@ func (p *Proxy) handleRequest(w dns.ResponseWriter, r *dns.Msg) {
 				log.Error("DNS-Proxy received invalid CNAME record in reply")
 				continue
 			}
-			log.WithFields(log.Fields{
+			log.WithFields(logrus.Fields{
 				"target": rr.Target,
 				"ttl":    ttl,
 			}).Debug("DNS-Proxy received CNAME in reply")
-			p.watches.AddTemp(rr.Target, ttl)
+			watches.AddTemp(rr.Target, ttl)
 		}
 	}
 
@@ -110,24 +211,37 @@ func (p *Proxy) handleRequest(w dns.ResponseWriter, r *dns.Msg) {
 	}
 }
 
+// exchange sends request r to remote server remote using the client for the
+// remote's transport in clients and returns the reply
+func exchange(clients map[string]*dns.Client, r *dns.Msg, remote string) (*dns.Msg, error) {
+	transport, address := ParseRemote(remote)
+	client, ok := clients[transport]
+	if !ok {
+		return nil, fmt.Errorf("unknown transport %s of remote %s",
+			transport, remote)
+	}
+	reply, _, err := client.Exchange(r, address)
+	return reply, err
+}
+
 // cleanTempWatches cleans temporary watches
 func (p *Proxy) cleanTempWatches() {
 	defer close(p.doneClean)
 
-	timer := time.NewTimer(tempWatchCleanInterval * time.Second)
+	timer := p.clock.NewTimer(tempWatchCleanInterval * time.Second)
 	for {
 		select {
-		case <-timer.C:
+		case <-timer.C():
 			// reset timer
 			timer.Reset(tempWatchCleanInterval * time.Second)
 
 			// clean temporary watches
-			p.watches.CleanTemp(tempWatchCleanInterval)
+			p.getWatches().CleanTemp(tempWatchCleanInterval)
 
 		case <-p.stopClean:
 			// stop timer
 			if !timer.Stop() {
-				<-timer.C
+				<-timer.C()
 			}
 			return
 		}
@@ -136,11 +250,11 @@ func (p *Proxy) cleanTempWatches() {
 
 // startDNSServer starts the dns server
 func (p *Proxy) startDNSServer(server *dns.Server) {
-	log.WithFields(log.Fields{
+	log.WithFields(logrus.Fields{
 		"addr": server.Addr,
 		"net":  server.Net,
 	}).Debug("DNS-Proxy starting server")
-	err := server.ListenAndServe()
+	err := server.ActivateAndServe()
 	if err != nil {
 		log.WithError(err).Error("DNS-Proxy DNS server stopped")
 	}
@@ -150,7 +264,7 @@ func (p *Proxy) startDNSServer(server *dns.Server) {
 func (p *Proxy) stopDNSServer(server *dns.Server) {
 	err := server.Shutdown()
 	if err != nil {
-		log.WithFields(log.Fields{
+		log.WithFields(logrus.Fields{
 			"addr":  server.Addr,
 			"net":   server.Net,
 			"error": err,
@@ -184,14 +298,38 @@ func (p *Proxy) start() {
 	close(p.reports)
 }
 
-// Start starts running the proxy
-func (p *Proxy) Start() {
+// listen creates the listeners of the dns servers
+func (p *Proxy) listen() error {
+	pc, err := net.ListenPacket("udp", p.udp.Addr)
+	if err != nil {
+		return fmt.Errorf("DNS-Proxy could not listen on udp: %w", err)
+	}
+	l, err := net.Listen("tcp", p.tcp.Addr)
+	if err != nil {
+		_ = pc.Close()
+		return fmt.Errorf("DNS-Proxy could not listen on tcp: %w", err)
+	}
+	p.udp.PacketConn = pc
+	p.tcp.Listener = l
+	return nil
+}
+
+// Start starts running the proxy, it runs until Stop is called or ctx is
+// canceled
+func (p *Proxy) Start(ctx context.Context) error {
+	if err := p.listen(); err != nil {
+		return err
+	}
+
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.done = ctx.Done()
 	go p.start()
+	return nil
 }
 
 // Stop stops running the proxy
 func (p *Proxy) Stop() {
-	close(p.done)
+	p.cancel()
 	for range p.reports {
 		// wait for channel shutdown
 	}
@@ -202,20 +340,100 @@ func (p *Proxy) Reports() chan *Report {
 	return p.reports
 }
 
-// SetRemotes sets the mapping from domain names to remote server addresses
+// SetRemotes sets the mapping from domain names to remote server addresses,
+// it replaces all remotes at once and increments the config version
 func (p *Proxy) SetRemotes(remotes map[string][]string) {
-	p.remotes.Flush()
+	r := NewRemotes()
 	for d, s := range remotes {
-		p.remotes.Add(d, s)
+		r.Add(d, append(s[:0:0], s...))
 	}
+
+	p.config.Lock()
+	defer p.config.Unlock()
+
+	p.remotes = r
+	p.version++
+	p.negative.flush()
+	log.WithField("version", p.version).Debug("DNS-Proxy set remotes")
+}
+
+// SetFallback sets the fallback servers used for domain names without
+// remotes, it increments the config version and removes the cached negative
+// answers, so replies of the old fallback servers in flight are not cached
+func (p *Proxy) SetFallback(servers []string) {
+	p.mutex.Lock()
+	p.fallback = append(servers[:0:0], servers...)
+	p.mutex.Unlock()
+
+	p.config.Lock()
+	defer p.config.Unlock()
+
+	p.version++
+	p.negative.flush()
+	log.WithField("version", p.version).Debug("DNS-Proxy set fallback")
+}
+
+// SetTunnelAll sets tunnel all DNS mode; if enabled, the fallback servers are
+// not used and queries for domain names without remotes are refused
+func (p *Proxy) SetTunnelAll(tunnelAll bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.tunnelAll = tunnelAll
 }
 
-// SetWatches sets the domains watched for A and AAAA record updates
+// Leaks returns the number of queries blocked in tunnel all DNS mode that
+// would otherwise have been sent to the fallback servers
+func (p *Proxy) Leaks() uint64 {
+	return atomic.LoadUint64(&p.leaks)
+}
+
+// SetWatches sets the domains watched for A and AAAA record updates, it
+// replaces all watches including temporary watches at once and increments
+// the config version
 func (p *Proxy) SetWatches(watches []string) {
-	p.watches.Flush()
+	w := NewWatches()
 	for _, d := range watches {
-		p.watches.Add(d)
+		w.Add(d)
 	}
+
+	p.config.Lock()
+	defer p.config.Unlock()
+
+	p.watches = w
+	p.version++
+	log.WithField("version", p.version).Debug("DNS-Proxy set watches")
+}
+
+// SetDevice sets the network device, e.g., the VPN tunnel device, that
+// queries to remote servers are bound to with SO_BINDTODEVICE, so they do not
+// depend on the routing configuration; an empty device removes the binding
+func (p *Proxy) SetDevice(device string) {
+	clients := newClients(newDeviceDialer(device))
+
+	p.config.Lock()
+	defer p.config.Unlock()
+
+	p.clients = clients
+}
+
+// SetFallbackMark sets the firewall mark set with SO_MARK on queries to the
+// fallback servers, so they are routed over the physical network interface
+// and not over the VPN tunnel; mark 0 removes the mark
+func (p *Proxy) SetFallbackMark(mark int) {
+	clients := newClients(newMarkDialer(mark))
+
+	p.config.Lock()
+	defer p.config.Unlock()
+
+	p.fallbackClients = clients
+}
+
+// Version returns the version of the active forwarding configuration, it is
+// incremented every time remotes, fallback servers or watches are set
+func (p *Proxy) Version() uint64 {
+	_, _, version := p.getConfig()
+	return version
 }
 
 // NewProxy returns a new Proxy that listens on address
@@ -232,7 +450,12 @@ func NewProxy(address string) *Proxy {
 		remotes: NewRemotes(),
 		watches: NewWatches(),
 		reports: make(chan *Report),
-		done:    make(chan struct{}),
+		clock:   clock.New(),
+
+		negative: newNegativeCache(),
+
+		clients:         newClients(nil),
+		fallbackClients: newClients(nil),
 
 		stopClean: make(chan struct{}),
 		doneClean: make(chan struct{}),
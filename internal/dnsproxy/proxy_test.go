@@ -1,17 +1,38 @@
 package dnsproxy
 
 import (
+	"context"
+	"net"
+	"sync/atomic"
 	"testing"
+
+	"github.com/miekg/dns"
 )
 
 // TestProxyStartStop tests Start and Stop of Proxy
 func TestProxyStartStop(t *testing.T) {
 	p := NewProxy("127.0.0.1:4254")
-	p.Start()
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
 	p.Stop()
 	<-p.Reports()
 }
 
+// TestProxyStartError tests Start of Proxy with address in use
+func TestProxyStartError(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:4255")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = l.Close() }()
+
+	p := NewProxy("127.0.0.1:4255")
+	if err := p.Start(context.Background()); err == nil {
+		t.Error("start should fail")
+	}
+}
+
 // TestProxyReports tests Reports of Proxy
 func TestProxyReports(t *testing.T) {
 	p := NewProxy("127.0.0.1:4254")
@@ -27,6 +48,199 @@ func TestProxySetRemotes(t *testing.T) {
 	p := NewProxy("127.0.0.1:4254")
 	remotes := getTestRemotes()
 	p.SetRemotes(remotes)
+	if p.Version() != 1 {
+		t.Errorf("got %d, want 1", p.Version())
+	}
+
+	// changing the map after setting it should not change the remotes
+	old := p.remotes
+	remotes["."] = []string{"192.168.1.2:53"}
+	if got := p.remotes.Get("."); got[0] == "192.168.1.2:53" {
+		t.Errorf("got %v, want unchanged remotes", got)
+	}
+
+	// setting remotes should replace them and increment the version
+	p.SetRemotes(remotes)
+	if p.remotes == old {
+		t.Error("remotes should be replaced")
+	}
+	if p.Version() != 2 {
+		t.Errorf("got %d, want 2", p.Version())
+	}
+}
+
+// TestProxyFallback tests fallback servers and tunnel all DNS mode of Proxy
+func TestProxyFallback(t *testing.T) {
+	remote, stop := startTestRemote(t)
+	defer stop()
+
+	p := NewProxy("127.0.0.1:4254")
+	p.SetRemotes(map[string][]string{"example.com.": {remote}})
+	p.SetFallback([]string{remote})
+
+	query := func(name string) *dns.Msg {
+		q := new(dns.Msg)
+		q.SetQuestion(name, dns.TypeA)
+		w := &testResponseWriter{}
+		p.handleRequest(w, q)
+		if w.msg == nil {
+			t.Fatal("got no reply")
+		}
+		return w.msg
+	}
+
+	// test without tunnel all, fallback should be used
+	for _, name := range []string{"example.com.", "other.com."} {
+		if got := query(name); got.Rcode != dns.RcodeSuccess {
+			t.Errorf("got %d, want %d", got.Rcode, dns.RcodeSuccess)
+		}
+	}
+	if p.Leaks() != 0 {
+		t.Errorf("got %d, want 0", p.Leaks())
+	}
+
+	// test with tunnel all, fallback should be refused
+	p.SetTunnelAll(true)
+	if got := query("example.com."); got.Rcode != dns.RcodeSuccess {
+		t.Errorf("got %d, want %d", got.Rcode, dns.RcodeSuccess)
+	}
+	if got := query("other.com."); got.Rcode != dns.RcodeRefused {
+		t.Errorf("got %d, want %d", got.Rcode, dns.RcodeRefused)
+	}
+	if p.Leaks() != 1 {
+		t.Errorf("got %d, want 1", p.Leaks())
+	}
+
+	// test disabling tunnel all again
+	p.SetTunnelAll(false)
+	if got := query("other.com."); got.Rcode != dns.RcodeSuccess {
+		t.Errorf("got %d, want %d", got.Rcode, dns.RcodeSuccess)
+	}
+	if p.Leaks() != 1 {
+		t.Errorf("got %d, want 1", p.Leaks())
+	}
+}
+
+// TestProxyNegativeCache tests caching of negative answers of Proxy
+func TestProxyNegativeCache(t *testing.T) {
+	var queries uint64
+	remote := startTestNegativeRemote(t, &queries, nil)
+
+	p := NewProxy("127.0.0.1:4254")
+	p.SetRemotes(map[string][]string{".": {remote}})
+	query := func() { testNegativeQuery(t, p) }
+
+	// second query is answered from cache
+	query()
+	query()
+	if n := atomic.LoadUint64(&queries); n != 1 {
+		t.Errorf("got %d queries, want 1", n)
+	}
+
+	// setting remotes removes cached answers
+	p.SetRemotes(map[string][]string{".": {remote}})
+	query()
+	if n := atomic.LoadUint64(&queries); n != 2 {
+		t.Errorf("got %d queries, want 2", n)
+	}
+}
+
+// TestProxyNegativeCacheSetFallback tests that replies of old fallback
+// servers in flight while the fallback servers are set are not cached
+func TestProxyNegativeCacheSetFallback(t *testing.T) {
+	var oldQueries, newQueries uint64
+	received := make(chan struct{}, 1)
+	release := make(chan struct{})
+	oldRemote := startTestNegativeRemote(t, &oldQueries, func() {
+		received <- struct{}{}
+		<-release
+	})
+	newRemote := startTestNegativeRemote(t, &newQueries, nil)
+
+	p := NewProxy("127.0.0.1:4254")
+	p.SetFallback([]string{oldRemote})
+
+	// set fallback while query to old fallback server is in flight
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		testNegativeQuery(t, p)
+	}()
+	<-received
+	p.SetFallback([]string{newRemote})
+	close(release)
+	<-done
+
+	// reply of old fallback server is not used for new queries
+	testNegativeQuery(t, p)
+	if n := atomic.LoadUint64(&newQueries); n != 1 {
+		t.Errorf("got %d queries, want 1", n)
+	}
+}
+
+// startTestNegativeRemote starts a remote server in test t that answers all
+// queries with NXDOMAIN, counts them in queries and calls wait before it
+// replies if it is set; it returns the address of the server
+func startTestNegativeRemote(t *testing.T, queries *uint64, wait func()) string {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	server := &dns.Server{
+		PacketConn: pc,
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			atomic.AddUint64(queries, 1)
+			if wait != nil {
+				wait()
+			}
+			_ = w.WriteMsg(testNegativeReply(r, dns.RcodeNameError, 300, 300))
+		}),
+		NotifyStartedFunc: func() { close(started) },
+	}
+	go func() { _ = server.ActivateAndServe() }()
+	<-started
+	t.Cleanup(func() { _ = server.Shutdown() })
+	return pc.LocalAddr().String()
+}
+
+// testNegativeQuery sends a query for a nonexistent name to p in test t and
+// checks the NXDOMAIN reply
+func testNegativeQuery(t *testing.T, p *Proxy) {
+	q := new(dns.Msg)
+	q.SetQuestion("nonexistent.example.com.", dns.TypeA)
+	w := &testResponseWriter{}
+	p.handleRequest(w, q)
+	if w.msg == nil || w.msg.Rcode != dns.RcodeNameError ||
+		w.msg.Id != q.Id {
+		t.Errorf("got %v, want NXDOMAIN reply", w.msg)
+	}
+}
+
+// TestProxyTransport tests remote transports of Proxy
+func TestProxyTransport(t *testing.T) {
+	remote, stop := startTestRemoteTCP(t)
+	defer stop()
+
+	p := NewProxy("127.0.0.1:4254")
+	q := new(dns.Msg)
+	q.SetQuestion("example.com.", dns.TypeA)
+
+	// test tcp
+	p.SetRemotes(map[string][]string{".": {"tcp://" + remote}})
+	w := &testResponseWriter{}
+	p.handleRequest(w, q)
+	if w.msg == nil || len(w.msg.Answer) != 1 {
+		t.Errorf("got %v, want reply with answer", w.msg)
+	}
+
+	// test unknown transport
+	p.SetRemotes(map[string][]string{".": {"unknown://" + remote}})
+	w = &testResponseWriter{}
+	p.handleRequest(w, q)
+	if w.msg != nil {
+		t.Errorf("got %v, want nil", w.msg)
+	}
 }
 
 // TestProxySetWatches tests SetWatches of Proxy
@@ -34,6 +248,53 @@ func TestProxySetWatches(t *testing.T) {
 	p := NewProxy("127.0.0.1:4254")
 	watches := []string{"example.com."}
 	p.SetWatches(watches)
+	if !p.watches.Contains("example.com.") {
+		t.Error("watches should contain example.com.")
+	}
+	if p.Version() != 1 {
+		t.Errorf("got %d, want 1", p.Version())
+	}
+
+	// old watches of in-flight requests should not be modified
+	old := p.watches
+	p.SetWatches([]string{"other.com."})
+	if !old.Contains("example.com.") || old.Contains("other.com.") {
+		t.Error("old watches should not be modified")
+	}
+	if p.watches.Contains("example.com.") {
+		t.Error("watches should not contain example.com.")
+	}
+	if p.Version() != 2 {
+		t.Errorf("got %d, want 2", p.Version())
+	}
+}
+
+// TestProxyConcurrentSet tests concurrent SetRemotes, SetWatches and
+// requests of Proxy
+func TestProxyConcurrentSet(t *testing.T) {
+	remote, stop := startTestRemote(t)
+	defer stop()
+
+	p := NewProxy("127.0.0.1:4254")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			p.SetRemotes(map[string][]string{".": {remote}})
+			p.SetWatches([]string{"example.com."})
+		}
+	}()
+
+	q := new(dns.Msg)
+	q.SetQuestion("other.com.", dns.TypeA)
+	for i := 0; i < 100; i++ {
+		p.handleRequest(&testResponseWriter{}, q)
+	}
+	<-done
+
+	if p.Version() != 200 {
+		t.Errorf("got %d, want 200", p.Version())
+	}
 }
 
 // TestNewProxy tests NewProxy
@@ -44,10 +305,98 @@ func TestNewProxy(t *testing.T) {
 		p.remotes == nil ||
 		p.watches == nil ||
 		p.reports == nil ||
-		p.done == nil ||
 		p.stopClean == nil ||
 		p.doneClean == nil {
 
 		t.Errorf("got nil, want != nil")
 	}
 }
+
+// testResponseWriter is a dns.ResponseWriter for testing
+type testResponseWriter struct {
+	msg *dns.Msg
+}
+
+func (t *testResponseWriter) LocalAddr() net.Addr       { return &net.UDPAddr{} }
+func (t *testResponseWriter) RemoteAddr() net.Addr      { return &net.UDPAddr{} }
+func (t *testResponseWriter) WriteMsg(m *dns.Msg) error { t.msg = m; return nil }
+func (t *testResponseWriter) Write([]byte) (int, error) { return 0, nil }
+func (t *testResponseWriter) Close() error              { return nil }
+func (t *testResponseWriter) TsigStatus() error         { return nil }
+func (t *testResponseWriter) TsigTimersOnly(bool)       {}
+func (t *testResponseWriter) Hijack()                   {}
+
+// testRemoteHandler answers all A queries with 192.168.1.1
+var testRemoteHandler = dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+	m := new(dns.Msg)
+	m.SetReply(r)
+	m.Answer = append(m.Answer, &dns.A{
+		Hdr: dns.RR_Header{
+			Name:   r.Question[0].Name,
+			Rrtype: dns.TypeA,
+			Class:  dns.ClassINET,
+			Ttl:    300,
+		},
+		A: net.IPv4(192, 168, 1, 1),
+	})
+	_ = w.WriteMsg(m)
+})
+
+// startTestRemoteTCP starts a remote DNS server for testing that only
+// listens on TCP, returns its address and a stop function
+func startTestRemoteTCP(tb testing.TB) (string, func()) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	started := make(chan struct{})
+	server := &dns.Server{
+		Listener:          l,
+		Handler:           testRemoteHandler,
+		NotifyStartedFunc: func() { close(started) },
+	}
+	go func() { _ = server.ActivateAndServe() }()
+	<-started
+	return l.Addr().String(), func() { _ = server.Shutdown() }
+}
+
+// startTestRemote starts a remote DNS server for testing that answers all
+// A queries with 192.168.1.1, returns its address and a stop function
+func startTestRemote(tb testing.TB) (string, func()) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	started := make(chan struct{})
+	server := &dns.Server{
+		PacketConn:        pc,
+		Handler:           testRemoteHandler,
+		NotifyStartedFunc: func() { close(started) },
+	}
+	go func() { _ = server.ActivateAndServe() }()
+	<-started
+	return pc.LocalAddr().String(), func() { _ = server.Shutdown() }
+}
+
+// BenchmarkProxyHandleRequest benchmarks handleRequest of Proxy
+func BenchmarkProxyHandleRequest(b *testing.B) {
+	remote, stop := startTestRemote(b)
+	defer stop()
+
+	p := NewProxy("127.0.0.1:4254")
+	p.SetRemotes(map[string][]string{".": {remote}})
+
+	q := new(dns.Msg)
+	q.SetQuestion("example.com.", dns.TypeA)
+	w := &testResponseWriter{}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.handleRequest(w, q)
+	}
+	b.StopTimer()
+	if w.msg == nil {
+		b.Error("got no reply")
+	}
+}
@@ -1,11 +1,30 @@
 package dnsproxy
 
 import (
+	"strings"
 	"sync"
 
 	"github.com/miekg/dns"
 )
 
+// Remote server transports
+const (
+	TransportUDP = "udp"
+	TransportTCP = "tcp"
+	TransportTLS = "tls"
+)
+
+// ParseRemote parses the remote server address remote in the format
+// "[transport://]address" and returns its transport and address; the
+// transport defaults to UDP
+func ParseRemote(remote string) (transport, address string) {
+	t, a, ok := strings.Cut(remote, "://")
+	if !ok {
+		return TransportUDP, remote
+	}
+	return t, a
+}
+
 // Remotes contains a mapping from domain names to remote DNS servers
 type Remotes struct {
 	sync.RWMutex
@@ -14,6 +14,22 @@ func getTestRemotes() map[string][]string {
 	}
 }
 
+// TestParseRemote tests ParseRemote
+func TestParseRemote(t *testing.T) {
+	for remote, want := range map[string][2]string{
+		"192.168.1.1:53":       {TransportUDP, "192.168.1.1:53"},
+		"udp://192.168.1.1:53": {TransportUDP, "192.168.1.1:53"},
+		"tcp://192.168.1.1:53": {TransportTCP, "192.168.1.1:53"},
+		"tls://[::1]:853":      {TransportTLS, "[::1]:853"},
+	} {
+		transport, address := ParseRemote(remote)
+		got := [2]string{transport, address}
+		if got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+}
+
 // TestRemotesAdd tests Add of Remotes
 func TestRemotesAdd(t *testing.T) {
 	r := NewRemotes()
@@ -110,3 +126,17 @@ func TestNewRemotes(t *testing.T) {
 		t.Errorf("got nil, want != nil")
 	}
 }
+
+// BenchmarkRemotesGet benchmarks Get of Remotes
+func BenchmarkRemotesGet(b *testing.B) {
+	r := NewRemotes()
+	for k, v := range getTestRemotes() {
+		r.Add(k+".", v)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Get("www.some.example.com.")
+	}
+}
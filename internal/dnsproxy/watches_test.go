@@ -139,3 +139,17 @@ func TestNewWatches(t *testing.T) {
 		t.Errorf("got nil, want != nil")
 	}
 }
+
+// BenchmarkWatchesContains benchmarks Contains of Watches
+func BenchmarkWatchesContains(b *testing.B) {
+	w := NewWatches()
+	w.Add("example.com.")
+	w.AddTemp("cname.example.net.", 300)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w.Contains("www.sub.example.com.")
+		w.Contains("www.example.org.")
+	}
+}
@@ -0,0 +1,129 @@
+// Package eventbus contains an event bus inside the daemon: the main loop
+// publishes events and subsystems consume them independently in their own
+// goroutines
+package eventbus
+
+import (
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Subscription is a subscription of events with topics on the bus
+type Subscription struct {
+	name   string
+	topics map[Topic]bool
+	events chan Event
+
+	// dropped is the number of events dropped because the subscriber
+	// did not keep up, protected by the mutex of the bus
+	dropped uint64
+}
+
+// Events returns the channel of the subscribed events, it is closed when
+// the subscription is canceled or the bus is closed
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// wants returns whether the subscriber wants events with topic
+func (s *Subscription) wants(topic Topic) bool {
+	return len(s.topics) == 0 || s.topics[topic]
+}
+
+// Bus is an event bus
+type Bus struct {
+	mutex         sync.Mutex
+	subscriptions map[*Subscription]struct{}
+	closed        bool
+}
+
+// Subscribe subscribes the subscriber name to events with topics, to all
+// events if topics is empty; the subscription buffers up to size events,
+// newer events are dropped while the buffer is full
+func (b *Bus) Subscribe(name string, size int, topics ...Topic) *Subscription {
+	s := &Subscription{
+		name:   name,
+		topics: make(map[Topic]bool),
+		events: make(chan Event, size),
+	}
+	for _, t := range topics {
+		s.topics[t] = true
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		close(s.events)
+		return s
+	}
+	b.subscriptions[s] = struct{}{}
+	return s
+}
+
+// Unsubscribe cancels the subscription s
+func (b *Bus) Unsubscribe(s *Subscription) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if _, ok := b.subscriptions[s]; !ok {
+		// already canceled
+		return
+	}
+	delete(b.subscriptions, s)
+	close(s.events)
+}
+
+// Publish publishes the event e to all subscribers of its topic, it does
+// not block
+func (b *Bus) Publish(e Event) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for s := range b.subscriptions {
+		if !s.wants(e.Topic()) {
+			continue
+		}
+		select {
+		case s.events <- e:
+		default:
+			s.dropped++
+			log.WithFields(log.Fields{
+				"subscriber": s.name,
+				"topic":      e.Topic(),
+				"dropped":    s.dropped,
+			}).Warn("Event bus dropped event for slow subscriber")
+		}
+	}
+}
+
+// Dropped returns the number of events dropped for subscription s
+func (b *Bus) Dropped(s *Subscription) uint64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return s.dropped
+}
+
+// Close closes the bus and cancels all subscriptions
+func (b *Bus) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for s := range b.subscriptions {
+		delete(b.subscriptions, s)
+		close(s.events)
+	}
+}
+
+// New returns a new event bus
+func New() *Bus {
+	return &Bus{
+		subscriptions: make(map[*Subscription]struct{}),
+	}
+}
@@ -0,0 +1,100 @@
+package eventbus
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// TestBusPublish tests Publish of Bus
+func TestBusPublish(t *testing.T) {
+	b := New()
+	defer b.Close()
+
+	all := b.Subscribe("all", 10)
+	conn := b.Subscribe("connection", 10, TopicConnection)
+	tnd := b.Subscribe("tnd", 10, TopicTND, TopicNetwork)
+
+	events := []Event{
+		&ConnectionStateChanged{
+			From: vpnstatus.ConnectionStateDisconnected,
+			To:   vpnstatus.ConnectionStateConnecting,
+		},
+		&TrustedNetworkChanged{Trusted: true},
+		&ProfileChanged{Name: "lab"},
+		&ConnectivityChanged{Online: true},
+		&ConnectionDropped{Reason: "idle-timeout"},
+	}
+	for _, e := range events {
+		b.Publish(e)
+	}
+
+	// get events of subscriptions
+	get := func(s *Subscription) []Event {
+		got := []Event{}
+		for len(s.Events()) > 0 {
+			got = append(got, <-s.Events())
+		}
+		return got
+	}
+	if got := get(all); !reflect.DeepEqual(got, events) {
+		t.Errorf("got %v, want %v", got, events)
+	}
+	want := []Event{events[0], events[4]}
+	if got := get(conn); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	want = []Event{events[1], events[3]}
+	if got := get(tnd); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestBusPublishSlow tests Publish of Bus with a slow subscriber
+func TestBusPublishSlow(t *testing.T) {
+	b := New()
+	defer b.Close()
+
+	s := b.Subscribe("slow", 1)
+	b.Publish(&ConnectivityChanged{Online: true})
+	b.Publish(&ConnectivityChanged{Online: false})
+	if d := b.Dropped(s); d != 1 {
+		t.Errorf("got %d, want 1", d)
+	}
+	if e := <-s.Events(); !e.(*ConnectivityChanged).Online {
+		t.Error("first event should be kept")
+	}
+}
+
+// TestBusUnsubscribe tests Unsubscribe of Bus
+func TestBusUnsubscribe(t *testing.T) {
+	b := New()
+	defer b.Close()
+
+	s := b.Subscribe("test", 1)
+	b.Unsubscribe(s)
+	b.Unsubscribe(s)
+	if _, ok := <-s.Events(); ok {
+		t.Error("events should be closed")
+	}
+	b.Publish(&ConnectivityChanged{})
+}
+
+// TestBusClose tests Close of Bus
+func TestBusClose(t *testing.T) {
+	b := New()
+	s := b.Subscribe("test", 1)
+	b.Close()
+	b.Close()
+	if _, ok := <-s.Events(); ok {
+		t.Error("events should be closed")
+	}
+
+	// subscribe after close
+	s = b.Subscribe("test", 1)
+	if _, ok := <-s.Events(); ok {
+		t.Error("events should be closed")
+	}
+	b.Publish(&ConnectivityChanged{})
+}
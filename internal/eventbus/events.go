@@ -0,0 +1,101 @@
+package eventbus
+
+import "github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+
+// Topic is the topic of an event, subscribers select events by topic
+type Topic string
+
+// Topics
+const (
+	TopicConnection Topic = "connection"
+	TopicTND        Topic = "tnd"
+	TopicProfile    Topic = "profile"
+	TopicNetwork    Topic = "network"
+	TopicStats      Topic = "stats"
+)
+
+// Event is an event on the event bus, the subscribers get the concrete
+// event types below
+type Event interface {
+	Topic() Topic
+}
+
+// ConnectionStateChanged is published on every transition of the VPN
+// connection state
+type ConnectionStateChanged struct {
+	From vpnstatus.ConnectionState
+	To   vpnstatus.ConnectionState
+}
+
+// Topic returns the topic of the event
+func (*ConnectionStateChanged) Topic() Topic { return TopicConnection }
+
+// ConnectionDropped is published when an established VPN connection ended
+// without a disconnect request, Reason is the disconnect reason of the VPN
+// gateway if it is known
+type ConnectionDropped struct {
+	Reason string
+}
+
+// Topic returns the topic of the event
+func (*ConnectionDropped) Topic() Topic { return TopicConnection }
+
+// TrustedNetworkChanged is published when trusted network detection
+// detected a change of the trusted network
+type TrustedNetworkChanged struct {
+	Trusted bool
+}
+
+// Topic returns the topic of the event
+func (*TrustedNetworkChanged) Topic() Topic { return TopicTND }
+
+// ProfileChanged is published when the settings of an XML profile are
+// applied, Name is empty for the default profile
+type ProfileChanged struct {
+	Name string
+}
+
+// Topic returns the topic of the event
+func (*ProfileChanged) Topic() Topic { return TopicProfile }
+
+// ConnectivityChanged is published when the network connectivity of the
+// host changed
+type ConnectivityChanged struct {
+	Online bool
+}
+
+// Topic returns the topic of the event
+func (*ConnectivityChanged) Topic() Topic { return TopicNetwork }
+
+// TrafficStats are the traffic statistics of the VPN device
+type TrafficStats struct {
+	RXBytes uint64
+	TXBytes uint64
+}
+
+// TrafficStatsStarted is published when the VPN device is configured and
+// traffic statistics start, Stats are the initial traffic statistics, nil if
+// they are unknown
+type TrafficStatsStarted struct {
+	Stats *TrafficStats
+}
+
+// Topic returns the topic of the event
+func (*TrafficStatsStarted) Topic() Topic { return TopicStats }
+
+// TrafficStatsUpdated is published on every update of the traffic
+// statistics while the VPN device is configured, Stats is nil if they are
+// unknown
+type TrafficStatsUpdated struct {
+	Stats *TrafficStats
+}
+
+// Topic returns the topic of the event
+func (*TrafficStatsUpdated) Topic() Topic { return TopicStats }
+
+// TrafficStatsStopped is published when the VPN device is torn down and
+// traffic statistics stop
+type TrafficStatsStopped struct{}
+
+// Topic returns the topic of the event
+func (*TrafficStatsStopped) Topic() Topic { return TopicStats }
@@ -0,0 +1,492 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/api"
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+	"github.com/telekom-mms/oc-daemon/internal/logging"
+	pb "github.com/telekom-mms/oc-daemon/pkg/grpcapi"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+	"golang.org/x/sys/unix"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// challengeLength is the length of challenges for config updates
+	challengeLength = 32
+
+	// challengeLifetime is the time after which an unused challenge
+	// expires
+	challengeLifetime = 30 * time.Second
+
+	// maxChallenges is the maximum number of unused challenges
+	maxChallenges = 16
+
+	// maxSubscribers is the maximum number of concurrent subscribers
+	maxSubscribers = 16
+
+	// subscriberBuffer is the number of events buffered per subscriber,
+	// a subscriber that falls further behind is disconnected
+	subscriberBuffer = 64
+)
+
+// Request names
+const (
+	RequestGetStatus       = "GetStatus"
+	RequestUpdateVPNConfig = "UpdateVPNConfig"
+)
+
+// Request is a request from a gRPC API client: GetStatus has no parameters
+// and returns the *vpnstatus.Status as result; UpdateVPNConfig has the
+// reason, challenge, response and *vpnconfig.Config as parameters
+type Request struct {
+	Name string
+
+	// ID identifies the request in log entries and error messages
+	ID string
+
+	Parameters []any
+	Results    []any
+	Error      error
+
+	wait chan struct{}
+	done <-chan struct{}
+}
+
+// Close completes the request handling
+func (r *Request) Close() {
+	close(r.wait)
+}
+
+// Wait waits for the completion of request handling. If the client aborts
+// the call first, it returns an error and the request must not be used
+// anymore, because the handler may still be writing its results
+func (r *Request) Wait() error {
+	select {
+	case <-r.wait:
+		return nil
+	case <-r.done:
+		return errors.New("Request aborted")
+	}
+}
+
+// statusError returns the gRPC status error of the failed request r
+func (r *Request) statusError() error {
+	code := codes.Aborted
+	var tooMany *dbusapi.TooManyRequestsError
+	if errors.As(r.Error, &tooMany) {
+		code = codes.ResourceExhausted
+	}
+	return status.Errorf(code, "%s (request %s)", r.Error, r.ID)
+}
+
+// peerInfo is the authentication info of a client on the unix socket, the
+// credentials of the client process
+type peerInfo struct {
+	credentials.CommonAuthInfo
+	cred *unix.Ucred
+}
+
+// AuthType returns the authentication type
+func (peerInfo) AuthType() string {
+	return "peercred"
+}
+
+// peerCredentials are the transport credentials of the server, they get
+// the credentials of client processes on the unix socket
+type peerCredentials struct{}
+
+// ClientHandshake does nothing, the credentials are only used by the server
+func (peerCredentials) ClientHandshake(_ context.Context, _ string, conn net.Conn) (
+	net.Conn, credentials.AuthInfo, error) {
+	return conn, peerInfo{}, nil
+}
+
+// ServerHandshake gets the credentials of the client process on conn
+func (peerCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	cred, err := api.PeerCredentials(conn)
+	if err != nil {
+		return nil, nil, err
+	}
+	info := peerInfo{cred: cred}
+	info.SecurityLevel = credentials.NoSecurity
+	return conn, info, nil
+}
+
+// Info returns the protocol info
+func (peerCredentials) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{SecurityProtocol: "peercred"}
+}
+
+// Clone returns a copy of the credentials
+func (peerCredentials) Clone() credentials.TransportCredentials {
+	return peerCredentials{}
+}
+
+// OverrideServerName does nothing
+func (peerCredentials) OverrideServerName(string) error {
+	return nil
+}
+
+// Server is a gRPC API server on a unix socket
+type Server struct {
+	pb.UnimplementedDaemonServer
+
+	sockFile string
+	owner    int
+	group    int
+	listen   net.Listener
+	server   *grpc.Server
+	requests chan *Request
+
+	mutex       sync.Mutex
+	challenges  map[string]time.Time
+	subscribers map[chan *pb.Event]struct{}
+}
+
+// request sends the request with name and parameters to the daemon and
+// waits for the result until ctx is done
+func (s *Server) request(ctx context.Context, id, name string, parameters ...any) (*Request, error) {
+	r := &Request{
+		Name:       name,
+		ID:         id,
+		Parameters: parameters,
+		wait:       make(chan struct{}),
+		done:       ctx.Done(),
+	}
+	select {
+	case s.requests <- r:
+	case <-ctx.Done():
+		return nil, status.Errorf(codes.Aborted, "%s aborted (request %s)", name, id)
+	}
+
+	if err := r.Wait(); err != nil {
+		return nil, status.Errorf(codes.Aborted, "%s (request %s)", err, id)
+	}
+	if r.Error != nil {
+		return nil, r.statusError()
+	}
+	return r, nil
+}
+
+// GetStatus returns the status of the daemon
+func (s *Server) GetStatus(ctx context.Context, _ *pb.GetStatusRequest) (*pb.Status, error) {
+	id := logging.NewRequestID()
+	log.WithField(logging.RequestField, id).Debug("Received gRPC GetStatus() call")
+	r, err := s.request(ctx, id, RequestGetStatus)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.Results) > 0 {
+		if st, ok := r.Results[0].(*vpnstatus.Status); ok {
+			return pb.NewStatus(st), nil
+		}
+	}
+	return pb.NewStatus(vpnstatus.New()), nil
+}
+
+// checkConfigUpdate returns an error if the client of the call with ctx and
+// request id may not send VPN config updates
+func (s *Server) checkConfigUpdate(ctx context.Context, id string) error {
+	p, ok := peer.FromContext(ctx)
+	if ok {
+		if info, ok := p.AuthInfo.(peerInfo); ok && info.cred != nil {
+			if api.AllowConfigUpdate(info.cred, s.owner, s.group) {
+				return nil
+			}
+			log.WithField(logging.CodeField, logging.CodeConfigUpdateDenied).
+				WithFields(log.Fields{
+					logging.RequestField: id,
+					"uid":                info.cred.Uid,
+					"gid":                info.cred.Gid,
+					"pid":                info.cred.Pid,
+				}).Error("Daemon rejected gRPC VPN config update of unauthorized client")
+			return status.Errorf(codes.PermissionDenied,
+				"permission denied (request %s)", id)
+		}
+	}
+	log.WithField(logging.RequestField, id).
+		Error("Daemon could not get gRPC client credentials")
+	return status.Errorf(codes.PermissionDenied, "permission denied (request %s)", id)
+}
+
+// newChallenge returns a new challenge and its expiry time, expired
+// challenges are removed and the oldest challenge is replaced if there are
+// too many unused challenges
+func (s *Server) newChallenge() ([]byte, time.Time, error) {
+	challenge := make([]byte, challengeLength)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, time.Time{}, fmt.Errorf("could not create challenge: %w", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	oldest := ""
+	for c, expires := range s.challenges {
+		if !now.Before(expires) {
+			delete(s.challenges, c)
+			continue
+		}
+		if oldest == "" || expires.Before(s.challenges[oldest]) {
+			oldest = c
+		}
+	}
+	if len(s.challenges) >= maxChallenges {
+		delete(s.challenges, oldest)
+	}
+	expires := now.Add(challengeLifetime)
+	s.challenges[hex.EncodeToString(challenge)] = expires
+	return challenge, expires, nil
+}
+
+// useChallenge returns whether challenge was issued by the server and is
+// not expired, the challenge cannot be used again
+func (s *Server) useChallenge(challenge []byte) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	c := hex.EncodeToString(challenge)
+	expires, ok := s.challenges[c]
+	if !ok {
+		return false
+	}
+	delete(s.challenges, c)
+	return time.Now().Before(expires)
+}
+
+// GetChallenge returns a new challenge for a VPN config update
+func (s *Server) GetChallenge(ctx context.Context, _ *pb.GetChallengeRequest) (*pb.Challenge, error) {
+	id := logging.NewRequestID()
+	if err := s.checkConfigUpdate(ctx, id); err != nil {
+		return nil, err
+	}
+	challenge, expires, err := s.newChallenge()
+	if err != nil {
+		log.WithError(err).WithField(logging.RequestField, id).
+			Error("Daemon could not create gRPC challenge")
+		return nil, status.Errorf(codes.Internal, "%s (request %s)", err, id)
+	}
+	return &pb.Challenge{Challenge: challenge, Expires: expires.Unix()}, nil
+}
+
+// UpdateVPNConfig updates the VPN configuration of the daemon
+func (s *Server) UpdateVPNConfig(ctx context.Context, u *pb.VPNConfigUpdate) (
+	*pb.UpdateVPNConfigResponse, error) {
+	id := logging.NewRequestID()
+	log.WithField(logging.RequestField, id).Debug("Received gRPC UpdateVPNConfig() call")
+	if err := s.checkConfigUpdate(ctx, id); err != nil {
+		return nil, err
+	}
+	if !s.useChallenge(u.GetChallenge()) {
+		return nil, status.Errorf(codes.PermissionDenied,
+			"invalid challenge in config update (request %s)", id)
+	}
+	config, err := pb.ParseVPNConfig(u.GetConfig())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"invalid config in config update: %s (request %s)", err, id)
+	}
+	if _, err := s.request(ctx, id, RequestUpdateVPNConfig, u.GetReason(),
+		u.GetChallenge(), u.GetResponse(), config); err != nil {
+		return nil, err
+	}
+	return &pb.UpdateVPNConfigResponse{}, nil
+}
+
+// subscribe adds a new subscriber and returns its events channel
+func (s *Server) subscribe() (chan *pb.Event, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.subscribers) >= maxSubscribers {
+		return nil, false
+	}
+	events := make(chan *pb.Event, subscriberBuffer)
+	s.subscribers[events] = struct{}{}
+	return events, true
+}
+
+// unsubscribe removes the subscriber with events
+func (s *Server) unsubscribe(events chan *pb.Event) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.subscribers[events]; !ok {
+		// already removed
+		return
+	}
+	delete(s.subscribers, events)
+	close(events)
+}
+
+// Subscribe sends events to the client until it cancels the call
+func (s *Server) Subscribe(_ *pb.SubscribeRequest, stream pb.Daemon_SubscribeServer) error {
+	events, ok := s.subscribe()
+	if !ok {
+		return status.Error(codes.ResourceExhausted, "too many subscribers")
+	}
+	defer s.unsubscribe(events)
+	log.Debug("Daemon added gRPC subscriber")
+
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return status.Error(codes.ResourceExhausted,
+					"subscriber too slow")
+			}
+			if err := stream.Send(e); err != nil {
+				log.WithError(err).Debug("Daemon could not send gRPC event")
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// newEvent returns e as gRPC event
+func newEvent(e *api.Event) (*pb.Event, error) {
+	event := &pb.Event{
+		Property: e.Property,
+		Signal:   e.Signal,
+	}
+	if e.Signal != "" {
+		b, err := json.Marshal(e.Values)
+		if err != nil {
+			return nil, err
+		}
+		event.Values = string(b)
+		return event, nil
+	}
+	b, err := json.Marshal(e.Value)
+	if err != nil {
+		return nil, err
+	}
+	event.Value = string(b)
+	return event, nil
+}
+
+// Publish sends the event e to all subscribers, subscribers that cannot
+// keep up with the events are disconnected
+func (s *Server) Publish(e *api.Event) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.subscribers) == 0 {
+		return
+	}
+	event, err := newEvent(e)
+	if err != nil {
+		log.WithError(err).Error("Daemon could not convert gRPC event to JSON")
+		return
+	}
+	for events := range s.subscribers {
+		select {
+		case events <- event:
+		default:
+			log.Warn("Daemon disconnected slow gRPC subscriber")
+			delete(s.subscribers, events)
+			close(events)
+		}
+	}
+}
+
+// Start starts the gRPC API server, it runs until Stop is called or ctx is
+// canceled
+func (s *Server) Start(ctx context.Context) error {
+	// cleanup existing sock file, this should normally fail
+	if err := os.Remove(s.sockFile); err == nil {
+		log.Warn("Removed existing gRPC unix socket file")
+	}
+
+	// start listener
+	listen, err := net.Listen("unix", s.sockFile)
+	if err != nil {
+		return fmt.Errorf("Daemon could not start gRPC unix listener: %w", err)
+	}
+	s.listen = listen
+
+	// make sure only we, the owner and the group can access the sock file
+	mode := os.FileMode(0700)
+	if s.group >= 0 {
+		mode = 0770
+	}
+	if err := os.Chmod(s.sockFile, mode); err != nil {
+		log.WithError(err).Error("Daemon could not set permissions of gRPC sock file")
+	}
+	if s.owner >= 0 || s.group >= 0 {
+		if err := os.Chown(s.sockFile, s.owner, s.group); err != nil {
+			log.WithError(err).Error("Daemon could not set owner of gRPC sock file")
+		}
+	}
+
+	// handle client connections
+	s.server = grpc.NewServer(grpc.Creds(peerCredentials{}))
+	pb.RegisterDaemonServer(s.server, s)
+	go func() {
+		if err := s.server.Serve(listen); err != nil {
+			log.WithError(err).Error("Daemon gRPC server error")
+		}
+	}()
+
+	// stop server when ctx is canceled
+	go func() {
+		<-ctx.Done()
+		s.server.Stop()
+	}()
+
+	return nil
+}
+
+// Stop stops the gRPC API server
+func (s *Server) Stop() {
+	s.server.Stop()
+	_ = os.Remove(s.sockFile)
+}
+
+// SetOwner sets the owner of the sock file to the user with uid and allows
+// its VPN config updates; it must be called before Start
+func (s *Server) SetOwner(uid int) {
+	s.owner = uid
+}
+
+// SetGroup sets the group of the sock file to the group with gid and allows
+// VPN config updates of its members; it must be called before Start
+func (s *Server) SetGroup(gid int) {
+	s.group = gid
+}
+
+// Requests returns the requests channel
+func (s *Server) Requests() chan *Request {
+	return s.requests
+}
+
+// NewServer returns a new gRPC API server on the unix socket sockFile
+func NewServer(sockFile string) *Server {
+	return &Server{
+		sockFile:    sockFile,
+		owner:       -1,
+		group:       -1,
+		requests:    make(chan *Request),
+		challenges:  make(map[string]time.Time),
+		subscribers: make(map[chan *pb.Event]struct{}),
+	}
+}
@@ -0,0 +1,321 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/api"
+	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+	pb "github.com/telekom-mms/oc-daemon/pkg/grpcapi"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+	"golang.org/x/sys/unix"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// startTestServer starts a server in test t that allows config updates of
+// the current user and returns it with a client connected to it
+func startTestServer(t *testing.T) (*Server, pb.DaemonClient) {
+	sockFile := filepath.Join(t.TempDir(), "test.sock")
+	server := NewServer(sockFile)
+	server.SetOwner(os.Getuid())
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("unix://"+sockFile,
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return server, pb.NewDaemonClient(conn)
+}
+
+// TestServerStartStop tests Start and Stop of Server
+func TestServerStartStop(t *testing.T) {
+	server := NewServer(filepath.Join(t.TempDir(), "test.sock"))
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	server.Stop()
+	if _, err := os.Stat(server.sockFile); !os.IsNotExist(err) {
+		t.Errorf("sock file should be removed: %v", err)
+	}
+}
+
+// TestServerStartError tests Start of Server with listener error
+func TestServerStartError(t *testing.T) {
+	sockFile := filepath.Join(t.TempDir(), "does-not-exist", "test.sock")
+	server := NewServer(sockFile)
+	if err := server.Start(context.Background()); err == nil {
+		t.Error("start should fail")
+	}
+}
+
+// TestServerGetStatus tests GetStatus of Server
+func TestServerGetStatus(t *testing.T) {
+	server, client := startTestServer(t)
+
+	// daemon returns status
+	go func() {
+		r := <-server.Requests()
+		if r.Name != RequestGetStatus {
+			t.Errorf("got %s, want %s", r.Name, RequestGetStatus)
+		}
+		s := vpnstatus.New()
+		s.ConnectionState = vpnstatus.ConnectionStateConnected
+		s.IP = "192.168.0.123"
+		r.Results = []any{s}
+		r.Close()
+	}()
+	s, err := client.GetStatus(context.Background(), &pb.GetStatusRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.GetConnectionState() != uint32(vpnstatus.ConnectionStateConnected) ||
+		s.GetIp() != "192.168.0.123" {
+		t.Errorf("got %v, want connected status", s)
+	}
+
+	// daemon returns error
+	go func() {
+		r := <-server.Requests()
+		r.Error = &dbusapi.TooManyRequestsError{RetryAfter: time.Second}
+		r.Close()
+	}()
+	_, err = client.GetStatus(context.Background(), &pb.GetStatusRequest{})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("got %v, want %s", err, codes.ResourceExhausted)
+	}
+}
+
+// TestServerRequestAborted tests an aborted call while the daemon is still
+// handling the request
+func TestServerRequestAborted(t *testing.T) {
+	server, client := startTestServer(t)
+
+	// daemon handles request after the call is aborted
+	ctx, cancel := context.WithCancel(context.Background())
+	handled := make(chan struct{})
+	go func() {
+		defer close(handled)
+		r := <-server.Requests()
+		cancel()
+		time.Sleep(10 * time.Millisecond)
+		r.Error = errors.New("test error")
+		r.Close()
+	}()
+	_, err := client.GetStatus(ctx, &pb.GetStatusRequest{})
+	if status.Code(err) != codes.Canceled && status.Code(err) != codes.Aborted {
+		t.Errorf("got %v, want aborted call", err)
+	}
+	<-handled
+}
+
+// TestServerUpdateVPNConfig tests GetChallenge and UpdateVPNConfig of Server
+func TestServerUpdateVPNConfig(t *testing.T) {
+	server, client := startTestServer(t)
+	ctx := context.Background()
+
+	// valid config update
+	c, err := client.GetChallenge(ctx, &pb.GetChallengeRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.GetChallenge()) != challengeLength ||
+		c.GetExpires() <= time.Now().Unix() {
+		t.Errorf("got invalid challenge %v", c)
+	}
+	u := &pb.VPNConfigUpdate{
+		Reason:    "connect",
+		Challenge: c.GetChallenge(),
+		Response:  []byte("response"),
+		Config:    &pb.VPNConfig{Gateway: "192.168.0.1"},
+	}
+	go func() {
+		r := <-server.Requests()
+		if r.Name != RequestUpdateVPNConfig || len(r.Parameters) != 4 {
+			t.Errorf("got invalid request %v", r)
+		}
+		if config, ok := r.Parameters[3].(*vpnconfig.Config); !ok ||
+			!config.Gateway.Equal(net.ParseIP("192.168.0.1")) {
+			t.Errorf("got invalid config %v", r.Parameters[3])
+		}
+		r.Close()
+	}()
+	if _, err := client.UpdateVPNConfig(ctx, u); err != nil {
+		t.Fatal(err)
+	}
+
+	// challenge cannot be used again
+	_, err = client.UpdateVPNConfig(ctx, u)
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("got %v, want %s", err, codes.PermissionDenied)
+	}
+
+	// invalid config
+	c, err = client.GetChallenge(ctx, &pb.GetChallengeRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.Challenge = c.GetChallenge()
+	u.Config.Gateway = "invalid"
+	_, err = client.UpdateVPNConfig(ctx, u)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("got %v, want %s", err, codes.InvalidArgument)
+	}
+}
+
+// TestServerCheckConfigUpdate tests checkConfigUpdate of Server
+func TestServerCheckConfigUpdate(t *testing.T) {
+	server := NewServer("test.sock")
+	server.SetOwner(1000)
+
+	// without credentials
+	if err := server.checkConfigUpdate(context.Background(), "test"); err == nil {
+		t.Error("client without credentials should not be allowed")
+	}
+
+	// other user, owner and root
+	for _, test := range []struct {
+		uid  uint32
+		want bool
+	}{
+		{12345, false},
+		{1000, true},
+		{0, true},
+	} {
+		info := peerInfo{cred: &unix.Ucred{Uid: test.uid, Gid: 12345}}
+		ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: info})
+		err := server.checkConfigUpdate(ctx, "test")
+		if (err == nil) != test.want {
+			t.Errorf("uid %d: got %v, want allowed %t", test.uid, err, test.want)
+		}
+	}
+}
+
+// TestServerChallenges tests newChallenge and useChallenge of Server
+func TestServerChallenges(t *testing.T) {
+	server := NewServer("test.sock")
+
+	// unknown challenge
+	if server.useChallenge([]byte("unknown")) {
+		t.Error("unknown challenge should not be valid")
+	}
+
+	// too many challenges, oldest is replaced
+	challenges := [][]byte{}
+	for i := 0; i <= maxChallenges; i++ {
+		c, _, err := server.newChallenge()
+		if err != nil {
+			t.Fatal(err)
+		}
+		challenges = append(challenges, c)
+		time.Sleep(time.Millisecond)
+	}
+	if len(server.challenges) != maxChallenges {
+		t.Errorf("got %d, want %d", len(server.challenges), maxChallenges)
+	}
+	if server.useChallenge(challenges[0]) {
+		t.Error("oldest challenge should be replaced")
+	}
+	if !server.useChallenge(challenges[maxChallenges]) {
+		t.Error("newest challenge should be valid")
+	}
+
+	// expired challenge
+	c, _, err := server.newChallenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k := range server.challenges {
+		server.challenges[k] = time.Now()
+	}
+	if server.useChallenge(c) {
+		t.Error("expired challenge should not be valid")
+	}
+}
+
+// TestServerSubscribe tests Subscribe and Publish of Server
+func TestServerSubscribe(t *testing.T) {
+	server, client := startTestServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.Subscribe(ctx, &pb.SubscribeRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// wait for subscriber and publish events
+	for {
+		server.mutex.Lock()
+		n := len(server.subscribers)
+		server.mutex.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	server.Publish(&api.Event{Property: "IP", Value: "192.168.0.123"})
+	server.Publish(&api.Event{Signal: "Disconnected", Values: []any{"test"}})
+
+	for _, want := range []*pb.Event{
+		{Property: "IP", Value: `"192.168.0.123"`},
+		{Signal: "Disconnected", Values: `["test"]`},
+	} {
+		e, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.GetProperty() != want.GetProperty() ||
+			e.GetValue() != want.GetValue() ||
+			e.GetSignal() != want.GetSignal() ||
+			e.GetValues() != want.GetValues() {
+			t.Errorf("got %v, want %v", e, want)
+		}
+	}
+}
+
+// TestServerPublishSlowSubscriber tests Publish of Server with a slow
+// subscriber
+func TestServerPublishSlowSubscriber(t *testing.T) {
+	server := NewServer("test.sock")
+	events, ok := server.subscribe()
+	if !ok {
+		t.Fatal("subscriber should be added")
+	}
+	for i := 0; i <= subscriberBuffer; i++ {
+		server.Publish(&api.Event{Property: "IP", Value: "192.168.0.123"})
+	}
+	if len(server.subscribers) != 0 {
+		t.Error("slow subscriber should be removed")
+	}
+	n := 0
+	for range events {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Errorf("got %d, want %d", n, subscriberBuffer)
+	}
+	server.unsubscribe(events)
+
+	// too many subscribers
+	for i := 0; i < maxSubscribers; i++ {
+		server.subscribe()
+	}
+	if _, ok := server.subscribe(); ok {
+		t.Error("too many subscribers should not be added")
+	}
+}
@@ -0,0 +1,82 @@
+// Package journal contains a client for the native protocol of the systemd
+// journal
+package journal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"strings"
+)
+
+var (
+	// Socket is the socket of the journald native protocol
+	Socket = "/run/systemd/journal/socket"
+)
+
+// AppendField appends the field with key and value to b using the journald
+// native protocol
+func AppendField(b *bytes.Buffer, key, value string) {
+	b.WriteString(key)
+	if !strings.Contains(value, "\n") {
+		b.WriteByte('=')
+		b.WriteString(value)
+		b.WriteByte('\n')
+		return
+	}
+
+	// values containing newlines are sent with their length
+	b.WriteByte('\n')
+	_ = binary.Write(b, binary.LittleEndian, uint64(len(value)))
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
+
+// FieldName converts name to a valid journald field name: uppercase letters,
+// digits and underscores, not starting with an underscore or a digit
+func FieldName(name string) string {
+	b := strings.Builder{}
+	for _, r := range strings.ToUpper(name) {
+		switch {
+		case r >= 'A' && r <= 'Z', r == '_':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if b.Len() == 0 {
+				b.WriteByte('X')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return strings.TrimLeft(b.String(), "_")
+}
+
+// Conn is a connection to the journal
+type Conn struct {
+	conn *net.UnixConn
+	addr *net.UnixAddr
+}
+
+// Write sends the entry in b encoded with the journald native protocol to
+// the journal
+func (c *Conn) Write(b []byte) (int, error) {
+	return c.conn.WriteToUnix(b, c.addr)
+}
+
+// Close closes the connection to the journal
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// Dial returns a new connection to the journal
+func Dial() (*Conn, error) {
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{
+		conn: conn,
+		addr: &net.UnixAddr{Name: Socket, Net: "unixgram"},
+	}, nil
+}
@@ -0,0 +1,81 @@
+package journal
+
+import (
+	"bytes"
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+// TestAppendField tests AppendField
+func TestAppendField(t *testing.T) {
+	// test without newline
+	b := new(bytes.Buffer)
+	AppendField(b, "KEY", "value")
+	want := []byte("KEY=value\n")
+	if !bytes.Equal(b.Bytes(), want) {
+		t.Errorf("got %q, want %q", b.Bytes(), want)
+	}
+
+	// test with newline
+	b = new(bytes.Buffer)
+	AppendField(b, "KEY", "a\nb")
+	want = []byte("KEY\n\x03\x00\x00\x00\x00\x00\x00\x00a\nb\n")
+	if !bytes.Equal(b.Bytes(), want) {
+		t.Errorf("got %q, want %q", b.Bytes(), want)
+	}
+}
+
+// TestFieldName tests FieldName
+func TestFieldName(t *testing.T) {
+	for name, want := range map[string]string{
+		"component": "COMPONENT",
+		"error":     "ERROR",
+		"vpn-dev":   "VPN_DEV",
+		"_private":  "PRIVATE",
+		"2fa":       "X2FA",
+		"a.b.c":     "A_B_C",
+	} {
+		got := FieldName(name)
+		if got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+}
+
+// TestConn tests Conn
+func TestConn(t *testing.T) {
+	// create test journal socket
+	old := Socket
+	Socket = filepath.Join(t.TempDir(), "socket")
+	defer func() { Socket = old }()
+	server, err := net.ListenUnixgram("unixgram", &net.UnixAddr{
+		Name: Socket,
+		Net:  "unixgram",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = server.Close() }()
+
+	// send entry
+	c, err := Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = c.Close() }()
+	want := []byte("MESSAGE=test\n")
+	if _, err := c.Write(want); err != nil {
+		t.Fatal(err)
+	}
+
+	// check received entry
+	buf := make([]byte, 4096)
+	n, err := server.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf[:n], want) {
+		t.Errorf("got %q, want %q", buf[:n], want)
+	}
+}
@@ -0,0 +1,299 @@
+// Package jsonschema generates JSON Schema definitions of the JSON payloads
+// of the daemon from their Go types, so clients in other languages can
+// generate typed code for them
+package jsonschema
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema draft of the generated schemas
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// maxEnumValues is the maximum number of values of integer types with
+// String method that are listed as enum
+const maxEnumValues = 64
+
+// Property is a property of an object in a schema
+type Property struct {
+	Name   string
+	Schema *Schema
+}
+
+// Properties are the properties of an object in a schema, they are encoded
+// in the order of the struct fields
+type Properties []*Property
+
+// MarshalJSON returns the properties as JSON object in their order
+func (p Properties) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, prop := range p {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, err := json.Marshal(prop.Name)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := json.Marshal(prop.Schema)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(name)
+		b.WriteByte(':')
+		b.Write(schema)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// Schema is a JSON Schema
+type Schema struct {
+	Schema      string `json:"$schema,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Ref         string `json:"$ref,omitempty"`
+
+	// Type is the type name or a list of type names if the value can also
+	// be null
+	Type any `json:"type,omitempty"`
+
+	Format          string             `json:"format,omitempty"`
+	ContentEncoding string             `json:"contentEncoding,omitempty"`
+	Enum            []any              `json:"enum,omitempty"`
+	AnyOf           []*Schema          `json:"anyOf,omitempty"`
+	Items           *Schema            `json:"items,omitempty"`
+	Properties      Properties         `json:"properties,omitempty"`
+	Required        []string           `json:"required,omitempty"`
+	Additional      *Schema            `json:"additionalProperties,omitempty"`
+	Defs            map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// JSON returns the schema as indented JSON
+func (s *Schema) JSON() ([]byte, error) {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// nullable returns schema s that also allows null
+func nullable(s *Schema) *Schema {
+	if t, ok := s.Type.(string); ok {
+		s.Type = []string{t, "null"}
+		return s
+	}
+	return &Schema{AnyOf: []*Schema{s, {Type: "null"}}}
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	stringerType      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// Generator generates JSON Schemas from Go types with the rules of the
+// encoding/json package
+type Generator struct {
+	// Versions are the versions of the types whose JSON encoding starts
+	// with a "Version" field followed by the fields of the type, e.g.,
+	// vpnstatus.Status
+	Versions map[reflect.Type]int
+
+	defs map[string]*Schema
+}
+
+// defName returns the name of struct type t in the definitions
+func defName(t reflect.Type) string {
+	if t.PkgPath() == "" {
+		return t.Name()
+	}
+	return path.Base(t.PkgPath()) + "." + t.Name()
+}
+
+// enum sets the values of integer type t with String method as enum of
+// schema s, the values are counted from 0 until String returns an empty
+// string; types without such a value, e.g., time.Duration, are no enums
+func enum(t reflect.Type, s *Schema) {
+	if !t.Implements(stringerType) {
+		return
+	}
+	v := reflect.New(t).Elem()
+	values := []any{}
+	names := []string{}
+	for i := 0; ; i++ {
+		if i == maxEnumValues {
+			return
+		}
+		switch t.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+			reflect.Uint64:
+			v.SetUint(uint64(i))
+		default:
+			v.SetInt(int64(i))
+		}
+		name := v.Interface().(fmt.Stringer).String()
+		if name == "" {
+			break
+		}
+		values = append(values, i)
+		names = append(names, fmt.Sprintf("%d: %s", i, name))
+	}
+	if len(values) > 0 {
+		s.Enum = values
+		s.Description = strings.Join(names, ", ")
+	}
+}
+
+// fields returns the properties and required property names of the fields
+// of struct type t, embedded structs without name are inlined
+func (g *Generator) fields(t reflect.Type) (props Properties, required []string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		// inline embedded structs
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				p, r := g.fields(ft)
+				props = append(props, p...)
+				required = append(required, r...)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		props = append(props, &Property{Name: name, Schema: g.schema(ft)})
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+	return
+}
+
+// object returns the object schema of struct type t
+func (g *Generator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object"}
+	if v, ok := g.Versions[t]; ok {
+		s.Properties = Properties{{
+			Name: "Version",
+			Schema: &Schema{
+				Type:        "integer",
+				Description: fmt.Sprintf("version of the encoding, %d", v),
+			},
+		}}
+		s.Required = []string{"Version"}
+	}
+	props, required := g.fields(t)
+	s.Properties = append(s.Properties, props...)
+	s.Required = append(s.Required, required...)
+	return s
+}
+
+// schema returns the schema of type t, named struct types are added to the
+// definitions and referenced
+func (g *Generator) schema(t reflect.Type) *Schema {
+	switch {
+	case t.Kind() == reflect.Pointer:
+		return nullable(g.schema(t.Elem()))
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Implements(textMarshalerType):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64:
+		s := &Schema{Type: "integer"}
+		enum(t, s)
+		return s
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{
+				Type:            []string{"string", "null"},
+				ContentEncoding: "base64",
+			}
+		}
+		return nullable(&Schema{Type: "array", Items: g.schema(t.Elem())})
+	case reflect.Array:
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return nullable(&Schema{
+			Type:       "object",
+			Additional: g.schema(t.Elem()),
+		})
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		name := defName(t)
+		if _, ok := g.defs[name]; !ok {
+			// add placeholder first for recursive types
+			g.defs[name] = nil
+			g.defs[name] = g.object(t)
+		}
+		return &Schema{Ref: "#/$defs/" + name}
+	}
+
+	// any value, e.g., interfaces
+	return &Schema{}
+}
+
+// Generate returns the schema with title of the JSON encoding of v
+func (g *Generator) Generate(v any, title string) *Schema {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	g.defs = make(map[string]*Schema)
+	var s *Schema
+	if t.Kind() == reflect.Struct {
+		s = g.object(t)
+	} else {
+		s = g.schema(t)
+	}
+	s.Schema = Draft
+	s.Title = title
+	if len(g.defs) > 0 {
+		s.Defs = g.defs
+	}
+	return s
+}
+
+// NewGenerator returns a new Generator
+func NewGenerator() *Generator {
+	return &Generator{
+		Versions: make(map[reflect.Type]int),
+	}
+}
@@ -0,0 +1,179 @@
+package jsonschema
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testState is an enum type for testing
+type testState uint32
+
+// String returns the state as string
+func (s testState) String() string {
+	switch s {
+	case 0:
+		return "off"
+	case 1:
+		return "on"
+	}
+	return ""
+}
+
+// testInner is a nested struct type for testing
+type testInner struct {
+	Name string
+}
+
+// testEmbedded is an embedded struct type for testing
+type testEmbedded struct {
+	Embedded bool
+}
+
+// testPayload is a payload type for testing
+type testPayload struct {
+	testEmbedded
+
+	State    testState
+	Timeout  time.Duration
+	Time     time.Time
+	IP       net.IP
+	Data     []byte
+	Names    []string
+	Labels   map[string]int
+	Inner    testInner
+	Optional *testInner
+	Note     string `json:",omitempty"`
+	Renamed  string `json:"renamed"`
+	Ignored  string `json:"-"`
+	private  string
+}
+
+// TestGeneratorGenerate tests Generate of Generator
+func TestGeneratorGenerate(t *testing.T) {
+	g := NewGenerator()
+	g.Versions[reflect.TypeOf(testPayload{})] = 3
+	b, err := g.Generate(&testPayload{private: "x"}, "Test").JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Test",
+  "type": "object",
+  "properties": {
+    "Version": {
+      "description": "version of the encoding, 3",
+      "type": "integer"
+    },
+    "Embedded": {
+      "type": "boolean"
+    },
+    "State": {
+      "description": "0: off, 1: on",
+      "type": "integer",
+      "enum": [
+        0,
+        1
+      ]
+    },
+    "Timeout": {
+      "type": "integer"
+    },
+    "Time": {
+      "type": "string",
+      "format": "date-time"
+    },
+    "IP": {
+      "type": "string"
+    },
+    "Data": {
+      "type": [
+        "string",
+        "null"
+      ],
+      "contentEncoding": "base64"
+    },
+    "Names": {
+      "type": [
+        "array",
+        "null"
+      ],
+      "items": {
+        "type": "string"
+      }
+    },
+    "Labels": {
+      "type": [
+        "object",
+        "null"
+      ],
+      "additionalProperties": {
+        "type": "integer"
+      }
+    },
+    "Inner": {
+      "$ref": "#/$defs/jsonschema.testInner"
+    },
+    "Optional": {
+      "anyOf": [
+        {
+          "$ref": "#/$defs/jsonschema.testInner"
+        },
+        {
+          "type": "null"
+        }
+      ]
+    },
+    "Note": {
+      "type": "string"
+    },
+    "renamed": {
+      "type": "string"
+    }
+  },
+  "required": [
+    "Version",
+    "Embedded",
+    "State",
+    "Timeout",
+    "Time",
+    "IP",
+    "Data",
+    "Names",
+    "Labels",
+    "Inner",
+    "Optional",
+    "renamed"
+  ],
+  "$defs": {
+    "jsonschema.testInner": {
+      "type": "object",
+      "properties": {
+        "Name": {
+          "type": "string"
+        }
+      },
+      "required": [
+        "Name"
+      ]
+    }
+  }
+}
+`
+	if string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+}
+
+// TestGeneratorGenerateNoStruct tests Generate of Generator with a type
+// that is not a struct
+func TestGeneratorGenerateNoStruct(t *testing.T) {
+	s := NewGenerator().Generate([]string{}, "Names")
+	if s.Title != "Names" || s.Schema != Draft || s.Items == nil ||
+		s.Defs != nil {
+		t.Errorf("got %+v", s)
+	}
+}
@@ -0,0 +1,99 @@
+package logging
+
+// CodeField is the name of the log field that contains the event code
+const CodeField = "code"
+
+// Event codes of log messages, unlike the messages they are stable across
+// releases: codes are never changed or reused, new codes are added at the
+// end of their group
+const (
+	// daemon
+	CodeDaemonStarting      = "OCD-0001"
+	CodeDaemonStarted       = "OCD-0002"
+	CodeDBusUnavailable     = "OCD-0003"
+	CodeReloading           = "OCD-0004"
+	CodeProfileReloadFailed = "OCD-0005"
+
+	// vpn connection
+	CodeConnectFailed          = "OCD-0101"
+	CodeConnectCachedFailed    = "OCD-0102"
+	CodeReconnecting           = "OCD-0103"
+	CodeReconnectFailed        = "OCD-0104"
+	CodeReconnectGivenUp       = "OCD-0105"
+	CodeVPNSetup               = "OCD-0106"
+	CodeVPNTeardown            = "OCD-0107"
+	CodeDisconnectFailed       = "OCD-0108"
+	CodeUnexpectedDisconnect   = "OCD-0109"
+	CodeConnectionDropped      = "OCD-0110"
+	CodeSessionsLocked         = "OCD-0111"
+	CodeIdleDisconnectFailed   = "OCD-0112"
+	CodeSessionLimitFailed     = "OCD-0113"
+	CodeReauthRequired         = "OCD-0114"
+	CodeGatewayDisconnect      = "OCD-0115"
+	CodeRunnerDisconnectFailed = "OCD-0116"
+
+	// network
+	CodeTrustedNetwork      = "OCD-0201"
+	CodeConnectivityChanged = "OCD-0202"
+	CodeResumeReconnect     = "OCD-0203"
+
+	// vpn configuration and security
+	CodeInvalidToken        = "OCD-0301"
+	CodeInvalidConfigUpdate = "OCD-0302"
+	CodeTrafPolFailed       = "OCD-0303"
+	CodeSplitRoutingFailed  = "OCD-0304"
+	CodeVPNConfigUpFailed   = "OCD-0305"
+	CodeVPNConfigDownFailed = "OCD-0306"
+	CodeConfigUpdateDenied  = "OCD-0307"
+)
+
+// EventCode is an event code with its log level and a description of the
+// event that does not change with the log message
+type EventCode struct {
+	Code        string
+	Level       string
+	Description string
+}
+
+// eventCodes are all event codes
+var eventCodes = []EventCode{
+	{CodeDaemonStarting, "info", "daemon is starting"},
+	{CodeDaemonStarted, "info", "daemon started its APIs"},
+	{CodeDBusUnavailable, "warning", "D-Bus API is not available, daemon runs with socket API only"},
+	{CodeReloading, "info", "daemon is reloading its configuration and XML profile"},
+	{CodeProfileReloadFailed, "error", "XML profile could not be reloaded"},
+
+	{CodeConnectFailed, "error", "VPN connection could not be started"},
+	{CodeConnectCachedFailed, "error", "VPN connection with cached login could not be started"},
+	{CodeReconnecting, "info", "daemon is reconnecting the VPN"},
+	{CodeReconnectFailed, "error", "VPN could not be reconnected"},
+	{CodeReconnectGivenUp, "error", "daemon gave up reconnecting after too many attempts"},
+	{CodeVPNSetup, "info", "VPN connection is established, configuration is set up"},
+	{CodeVPNTeardown, "info", "VPN connection ended, configuration is removed"},
+	{CodeDisconnectFailed, "error", "VPN could not be disconnected"},
+	{CodeUnexpectedDisconnect, "info", "VPN disconnected without disconnect request"},
+	{CodeConnectionDropped, "warning", "established VPN connection dropped"},
+	{CodeSessionsLocked, "info", "sessions are locked after VPN connection drop"},
+	{CodeIdleDisconnectFailed, "error", "idle VPN connection could not be disconnected"},
+	{CodeSessionLimitFailed, "error", "VPN could not be disconnected at the session limit"},
+	{CodeReauthRequired, "info", "user has to authenticate again to reconnect the VPN"},
+	{CodeGatewayDisconnect, "info", "VPN gateway ended the connection"},
+	{CodeRunnerDisconnectFailed, "error", "openconnect could not be stopped"},
+
+	{CodeTrustedNetwork, "info", "trusted network detected, VPN is disconnected"},
+	{CodeConnectivityChanged, "info", "network connectivity changed"},
+	{CodeResumeReconnect, "info", "system resumed, VPN is reconnected"},
+
+	{CodeInvalidToken, "error", "VPN configuration update with invalid challenge response rejected"},
+	{CodeInvalidConfigUpdate, "error", "invalid VPN configuration update rejected"},
+	{CodeTrafPolFailed, "error", "traffic policing could not be started"},
+	{CodeSplitRoutingFailed, "error", "split routing could not be started"},
+	{CodeVPNConfigUpFailed, "error", "VPN configuration could not be set up"},
+	{CodeVPNConfigDownFailed, "error", "VPN configuration could not be removed"},
+	{CodeConfigUpdateDenied, "error", "VPN configuration update of unauthorized client rejected"},
+}
+
+// Codes returns all event codes
+func Codes() []EventCode {
+	return append([]EventCode{}, eventCodes...)
+}
@@ -0,0 +1,36 @@
+package logging
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestCodes tests Codes
+func TestCodes(t *testing.T) {
+	format := regexp.MustCompile(`^OCD-\d{4}$`)
+	seen := make(map[string]bool)
+	for _, c := range Codes() {
+		if !format.MatchString(c.Code) {
+			t.Errorf("invalid code format: %s", c.Code)
+		}
+		if seen[c.Code] {
+			t.Errorf("duplicate code: %s", c.Code)
+		}
+		seen[c.Code] = true
+		if _, err := logrus.ParseLevel(c.Level); err != nil {
+			t.Errorf("invalid level of %s: %v", c.Code, err)
+		}
+		if c.Description == "" {
+			t.Errorf("missing description of %s", c.Code)
+		}
+	}
+
+	// codes must not be modified by callers
+	codes := Codes()
+	codes[0].Code = "modified"
+	if Codes()[0].Code == "modified" {
+		t.Error("codes should be copied")
+	}
+}
@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/journal"
+)
+
+// journalIdentifier is the syslog identifier of log entries in the journal
+const journalIdentifier = "oc-daemon"
+
+// journalPriority returns the syslog priority of level
+func journalPriority(level logrus.Level) int {
+	switch level {
+	case logrus.PanicLevel, logrus.FatalLevel:
+		return 2 // critical
+	case logrus.ErrorLevel:
+		return 3 // error
+	case logrus.WarnLevel:
+		return 4 // warning
+	case logrus.InfoLevel:
+		return 6 // informational
+	}
+	return 7 // debug
+}
+
+// JournalFormatter formats log entries with the journald native protocol,
+// log fields are converted to journal fields
+type JournalFormatter struct{}
+
+// Format formats log entry e
+func (f *JournalFormatter) Format(e *logrus.Entry) ([]byte, error) {
+	b := new(bytes.Buffer)
+	journal.AppendField(b, "MESSAGE", e.Message)
+	journal.AppendField(b, "PRIORITY", strconv.Itoa(journalPriority(e.Level)))
+	journal.AppendField(b, "SYSLOG_IDENTIFIER", journalIdentifier)
+
+	// add fields sorted by key
+	keys := make([]string, 0, len(e.Data))
+	for k := range e.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		name := journal.FieldName(k)
+		if name == "" {
+			continue
+		}
+		value := e.Data[k]
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		journal.AppendField(b, name, fmt.Sprint(value))
+	}
+	return b.Bytes(), nil
+}
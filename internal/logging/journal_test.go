@@ -0,0 +1,51 @@
+package logging
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestJournalFormatterFormat tests Format of JournalFormatter
+func TestJournalFormatterFormat(t *testing.T) {
+	e := logrus.NewEntry(logrus.New()).WithFields(logrus.Fields{
+		ComponentField: ComponentDaemon,
+		"error":        errors.New("test error"),
+		"vpn-device":   "tun0",
+	})
+	e.Level = logrus.WarnLevel
+	e.Message = "test message"
+
+	want := "MESSAGE=test message\n" +
+		"PRIORITY=4\n" +
+		"SYSLOG_IDENTIFIER=oc-daemon\n" +
+		"COMPONENT=daemon\n" +
+		"ERROR=test error\n" +
+		"VPN_DEVICE=tun0\n"
+	b, err := (&JournalFormatter{}).Format(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != want {
+		t.Errorf("got %q, want %q", b, want)
+	}
+}
+
+// TestJournalPriority tests journalPriority
+func TestJournalPriority(t *testing.T) {
+	for level, want := range map[logrus.Level]int{
+		logrus.PanicLevel: 2,
+		logrus.FatalLevel: 2,
+		logrus.ErrorLevel: 3,
+		logrus.WarnLevel:  4,
+		logrus.InfoLevel:  6,
+		logrus.DebugLevel: 7,
+		logrus.TraceLevel: 7,
+	} {
+		got := journalPriority(level)
+		if got != want {
+			t.Errorf("got %d, want %d", got, want)
+		}
+	}
+}
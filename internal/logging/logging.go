@@ -0,0 +1,151 @@
+// Package logging contains the logging of the daemon and its components
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/journal"
+)
+
+// Components
+const (
+	ComponentDaemon   = "daemon"
+	ComponentDNSProxy = "dnsproxy"
+	ComponentSplitRt  = "splitrt"
+	ComponentTrafPol  = "trafpol"
+	ComponentOCRunner = "ocrunner"
+)
+
+// ComponentField is the name of the log field that contains the component
+const ComponentField = "component"
+
+// Log formats
+const (
+	FormatText     = "text"
+	FormatJSON     = "json"
+	FormatJournald = "journald"
+)
+
+var (
+	// mutex protects the loggers and settings below
+	mutex sync.Mutex
+
+	// components are the loggers of the components
+	components = make(map[string]*logrus.Logger)
+
+	// levels are the log levels of the components
+	levels = make(map[string]logrus.Level)
+
+	// journalConn is the connection to the journal in journald format
+	journalConn *journal.Conn
+)
+
+// Components returns the names of all components
+func Components() []string {
+	return []string{
+		ComponentDaemon,
+		ComponentDNSProxy,
+		ComponentSplitRt,
+		ComponentTrafPol,
+		ComponentOCRunner,
+	}
+}
+
+// IsComponent returns whether name is a valid component name
+func IsComponent(name string) bool {
+	for _, c := range Components() {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// configureLogger applies the settings of the standard logger and the level
+// of component name to logger l
+func configureLogger(name string, l *logrus.Logger) {
+	std := logrus.StandardLogger()
+	l.SetOutput(std.Out)
+	l.SetFormatter(std.Formatter)
+	level := std.GetLevel()
+	if cl, ok := levels[name]; ok {
+		level = cl
+	}
+	l.SetLevel(level)
+}
+
+// Component returns the logger of the component name, its log entries
+// contain the component name in the component field
+func Component(name string) *logrus.Entry {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	l, ok := components[name]
+	if !ok {
+		l = logrus.New()
+		configureLogger(name, l)
+		l.AddHook(recent)
+		components[name] = l
+	}
+	return l.WithField(ComponentField, name)
+}
+
+// getOutput returns the log formatter and output for format
+func getOutput(format string) (logrus.Formatter, io.Writer, error) {
+	switch format {
+	case FormatText:
+		return &logrus.TextFormatter{}, os.Stderr, nil
+	case FormatJSON:
+		return &logrus.JSONFormatter{}, os.Stderr, nil
+	case FormatJournald:
+		if journalConn == nil {
+			conn, err := journal.Dial()
+			if err != nil {
+				return nil, nil, err
+			}
+			journalConn = conn
+		}
+		return &JournalFormatter{}, journalConn, nil
+	}
+	return nil, nil, fmt.Errorf("invalid log format: %s", format)
+}
+
+// Configure sets the log format and log level of the standard logger and
+// all components, componentLevels overrides the log level of individual
+// components
+func Configure(format string, level logrus.Level,
+	componentLevels map[string]logrus.Level) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	formatter, out, err := getOutput(format)
+	if err != nil {
+		return err
+	}
+	if format != FormatJournald && journalConn != nil {
+		defer func(c *journal.Conn) { _ = c.Close() }(journalConn)
+		journalConn = nil
+	}
+
+	std := logrus.StandardLogger()
+	std.SetFormatter(formatter)
+	std.SetOutput(out)
+	std.SetLevel(level)
+	if !recentHooked {
+		std.AddHook(recent)
+		recentHooked = true
+	}
+
+	levels = make(map[string]logrus.Level)
+	for name, l := range componentLevels {
+		levels[name] = l
+	}
+	for name, l := range components {
+		configureLogger(name, l)
+	}
+	return nil
+}
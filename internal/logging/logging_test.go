@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestIsComponent tests IsComponent
+func TestIsComponent(t *testing.T) {
+	for _, c := range Components() {
+		if !IsComponent(c) {
+			t.Errorf("%s should be a component", c)
+		}
+	}
+	if IsComponent("invalid") {
+		t.Errorf("invalid should not be a component")
+	}
+}
+
+// TestComponent tests Component
+func TestComponent(t *testing.T) {
+	std := logrus.StandardLogger()
+	defer func(level logrus.Level, f logrus.Formatter) {
+		_ = Configure(FormatText, level, nil)
+		std.SetFormatter(f)
+	}(std.GetLevel(), std.Formatter)
+
+	// configure json format, debug level only for dnsproxy
+	if err := Configure(FormatJSON, logrus.InfoLevel, map[string]logrus.Level{
+		ComponentDNSProxy: logrus.DebugLevel,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// check component field
+	b := &bytes.Buffer{}
+	l := Component(ComponentDNSProxy)
+	l.Logger.SetOutput(b)
+	l.Debug("test")
+	m := map[string]string{}
+	if err := json.Unmarshal(b.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m[ComponentField] != ComponentDNSProxy || m["msg"] != "test" {
+		t.Errorf("got %v", m)
+	}
+
+	// check level of other component
+	b.Reset()
+	l = Component(ComponentSplitRt)
+	l.Logger.SetOutput(b)
+	l.Debug("test")
+	if b.Len() != 0 {
+		t.Errorf("debug message should not be logged: %s", b)
+	}
+
+	// check runtime changes
+	if err := Configure(FormatText, logrus.DebugLevel, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !l.Logger.IsLevelEnabled(logrus.DebugLevel) {
+		t.Errorf("debug level should be enabled")
+	}
+	if _, ok := l.Logger.Formatter.(*logrus.TextFormatter); !ok {
+		t.Errorf("got %T, want text formatter", l.Logger.Formatter)
+	}
+}
+
+// TestConfigureInvalid tests Configure with invalid format
+func TestConfigureInvalid(t *testing.T) {
+	if err := Configure("invalid", logrus.InfoLevel, nil); err == nil {
+		t.Errorf("invalid format should return error")
+	}
+}
@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RecentSize is the number of recent log entries kept in memory
+const RecentSize = 1000
+
+// recentLog is a ring buffer of recent log entries, it is a logrus hook
+// that formats the log entries as text
+type recentLog struct {
+	mutex     sync.Mutex
+	formatter logrus.Formatter
+	entries   []string
+	next      int
+	full      bool
+}
+
+// Levels returns the log levels of the hook
+func (r *recentLog) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire adds the log entry to the ring buffer
+func (r *recentLog) Fire(entry *logrus.Entry) error {
+	b, err := r.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.entries[r.next] = strings.TrimSuffix(string(b), "\n")
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+	return nil
+}
+
+// get returns the last n log entries, oldest first; all entries if n is 0
+func (r *recentLog) get(n int) []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entries := append([]string{}, r.entries[:r.next]...)
+	if r.full {
+		entries = append(append([]string{}, r.entries[r.next:]...), entries...)
+	}
+	if n > 0 && n < len(entries) {
+		entries = entries[len(entries)-n:]
+	}
+	return entries
+}
+
+// newRecentLog returns a new recent log with size entries
+func newRecentLog(size int) *recentLog {
+	return &recentLog{
+		formatter: &logrus.TextFormatter{
+			DisableColors: true,
+			FullTimestamp: true,
+		},
+		entries: make([]string, size),
+	}
+}
+
+var (
+	// recent contains the recent log entries of the standard logger and
+	// all components
+	recent = newRecentLog(RecentSize)
+
+	// recentHooked specifies if recent is added to the standard logger
+	recentHooked bool
+)
+
+// Recent returns the last n log entries of the standard logger and all
+// components as text, oldest first; all kept entries if n is 0
+func Recent(n int) []string {
+	return recent.get(n)
+}
@@ -0,0 +1,71 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestRecentLog tests recentLog
+func TestRecentLog(t *testing.T) {
+	r := newRecentLog(3)
+	l := logrus.New()
+	l.SetOutput(io.Discard)
+	l.AddHook(r)
+
+	// empty
+	if got := r.get(0); len(got) != 0 {
+		t.Errorf("got %v, want empty", got)
+	}
+
+	// not full
+	l.Info("test 0")
+	l.Info("test 1")
+	got := r.get(0)
+	if len(got) != 2 ||
+		!strings.Contains(got[0], "msg=\"test 0\"") ||
+		!strings.Contains(got[1], "msg=\"test 1\"") {
+		t.Errorf("got %v", got)
+	}
+
+	// full, oldest entries overwritten
+	for i := 2; i < 5; i++ {
+		l.Info(fmt.Sprintf("test %d", i))
+	}
+	msgs := func(entries []string) []string {
+		m := []string{}
+		for _, e := range entries {
+			i := strings.Index(e, "msg=")
+			m = append(m, e[i:])
+		}
+		return m
+	}
+	want := []string{`msg="test 2"`, `msg="test 3"`, `msg="test 4"`}
+	if got := msgs(r.get(0)); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// last n entries
+	want = []string{`msg="test 3"`, `msg="test 4"`}
+	if got := msgs(r.get(2)); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestRecent tests Recent
+func TestRecent(t *testing.T) {
+	l := Component(ComponentTrafPol)
+	l.Logger.SetOutput(io.Discard)
+	l.Error("recent test")
+
+	got := Recent(1)
+	if len(got) != 1 ||
+		!strings.Contains(got[0], "msg=\"recent test\"") ||
+		!strings.Contains(got[0], "component=trafpol") {
+		t.Errorf("got %v", got)
+	}
+}
@@ -0,0 +1,21 @@
+package logging
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// RequestField is the name of the log field that contains the ID of the
+// client request that caused the log entry
+const RequestField = "request"
+
+// NewRequestID returns a new random ID of a client request, e.g., of a D-Bus
+// method call, that is returned in error replies and logged, so failed client
+// commands can be correlated with log entries
+func NewRequestID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "00000000"
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,18 @@
+package logging
+
+import "testing"
+
+// TestNewRequestID tests NewRequestID
+func TestNewRequestID(t *testing.T) {
+	ids := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id := NewRequestID()
+		if len(id) != 8 {
+			t.Errorf("got %q, want 8 hex digits", id)
+		}
+		ids[id] = true
+	}
+	if len(ids) < 99 {
+		t.Errorf("got %d different IDs, want at least 99", len(ids))
+	}
+}
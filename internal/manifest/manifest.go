@@ -0,0 +1,244 @@
+// Package manifest contains the manifest of the system changes made by the
+// daemon, e.g., routing rules and nftables tables, it is used to clean up
+// after a failed shutdown
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Kinds of system changes
+const (
+	// KindDevice is a network device, the name is the device name
+	KindDevice = "device"
+
+	// KindResolved is a DNS configuration of a device in
+	// systemd-resolved, the name is the device name
+	KindResolved = "resolved"
+
+	// KindNftTable is a nftables table, the name is the family and
+	// table name, e.g., "inet oc-daemon-filter"
+	KindNftTable = "nft-table"
+
+	// KindRouteTable is an IPv4 and IPv6 routing table, the name is the
+	// table number
+	KindRouteTable = "route-table"
+
+	// KindRule is an IPv4 and IPv6 routing rule, the name is the rule
+	// preference
+	KindRule = "rule"
+)
+
+// Entry is a system change in the manifest
+type Entry struct {
+	Kind string
+	Name string
+}
+
+// cleanupCmds returns the commands that undo the change of the entry
+func (e *Entry) cleanupCmds() [][]string {
+	switch e.Kind {
+	case KindDevice:
+		return [][]string{{"ip", "link", "delete", e.Name}}
+	case KindResolved:
+		return [][]string{{"resolvectl", "revert", e.Name}}
+	case KindNftTable:
+		cmd := []string{"nft", "delete", "table"}
+		return [][]string{append(cmd, strings.Fields(e.Name)...)}
+	case KindRouteTable:
+		return [][]string{
+			{"ip", "-4", "route", "flush", "table", e.Name},
+			{"ip", "-6", "route", "flush", "table", e.Name},
+		}
+	case KindRule:
+		return [][]string{
+			{"ip", "-4", "rule", "delete", "pref", e.Name},
+			{"ip", "-6", "rule", "delete", "pref", e.Name},
+		}
+	}
+	return nil
+}
+
+// Manifest records the system changes made by the daemon in a file
+type Manifest struct {
+	mutex   sync.Mutex
+	file    string
+	entries []*Entry
+}
+
+// save saves the manifest to its file, the file is replaced atomically, so
+// it is never written partially
+func (m *Manifest) save() error {
+	if m.file == "" {
+		return nil
+	}
+	b, err := json.Marshal(m.entries)
+	if err != nil {
+		return err
+	}
+	tmp := m.file + ".tmp"
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, m.file)
+}
+
+// index returns the index of the entry with kind and name, -1 if it does
+// not exist
+func (m *Manifest) index(kind, name string) int {
+	for i, e := range m.entries {
+		if e.Kind == kind && e.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Add records the system change kind with name in the manifest
+func (m *Manifest) Add(kind, name string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.index(kind, name) >= 0 {
+		return
+	}
+	m.entries = append(m.entries, &Entry{Kind: kind, Name: name})
+	if err := m.save(); err != nil {
+		log.WithError(err).Error("Manifest could not be saved")
+	}
+}
+
+// Remove removes the system change kind with name from the manifest after
+// it has been undone
+func (m *Manifest) Remove(kind, name string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	i := m.index(kind, name)
+	if i < 0 {
+		return
+	}
+	m.entries = append(m.entries[:i], m.entries[i+1:]...)
+	if err := m.save(); err != nil {
+		log.WithError(err).Error("Manifest could not be saved")
+	}
+}
+
+// Entries returns the entries of the manifest in the order they were added
+func (m *Manifest) Entries() []*Entry {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	entries := []*Entry{}
+	for _, e := range m.entries {
+		entries = append(entries, &Entry{Kind: e.Kind, Name: e.Name})
+	}
+	return entries
+}
+
+// New returns a new empty Manifest that is saved to file, it is only kept
+// in memory if file is empty
+func New(file string) *Manifest {
+	return &Manifest{file: file}
+}
+
+// Load loads the manifest from file
+func Load(file string) (*Manifest, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	m := New(file)
+	if err := json.Unmarshal(b, &m.entries); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// runCleanupCmd runs the cleanup command cmd
+var runCleanupCmd = func(cmd []string) {
+	log.WithField("command", cmd).Debug("Manifest executing cleanup command")
+	c := exec.Command(cmd[0], cmd[1:]...)
+	if err := c.Run(); err == nil {
+		log.WithField("command", cmd).Warn("Manifest cleaned up system change")
+	}
+}
+
+// Cleanup undoes all system changes recorded in the manifest file after a
+// failed shutdown in reverse order and removes the file, it returns false
+// if the manifest file does not exist
+func Cleanup(file string) (bool, error) {
+	m, err := Load(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return true, err
+	}
+	for i := len(m.entries) - 1; i >= 0; i-- {
+		for _, cmd := range m.entries[i].cleanupCmds() {
+			runCleanupCmd(cmd)
+		}
+	}
+	return true, os.Remove(file)
+}
+
+var (
+	// mutex protects current
+	mutex sync.Mutex
+
+	// current is the manifest of the running daemon, changes are only
+	// kept in memory until Open is called
+	current = New("")
+)
+
+// Open starts recording system changes in a new manifest in file, an
+// existing file is replaced
+func Open(file string) error {
+	m := New(file)
+	if err := m.save(); err != nil {
+		return err
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	current = m
+	return nil
+}
+
+// Close stops recording system changes in the manifest file, the file is
+// kept so that the remaining changes can be cleaned up later
+func Close() {
+	mutex.Lock()
+	defer mutex.Unlock()
+	current = New("")
+}
+
+// get returns the current manifest
+func get() *Manifest {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return current
+}
+
+// Add records the system change kind with name in the current manifest
+func Add(kind, name string) {
+	get().Add(kind, name)
+}
+
+// Remove removes the system change kind with name from the current manifest
+func Remove(kind, name string) {
+	get().Remove(kind, name)
+}
+
+// Entries returns the entries of the current manifest
+func Entries() []*Entry {
+	return get().Entries()
+}
@@ -0,0 +1,160 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestManifestAddRemove tests Add and Remove of Manifest
+func TestManifestAddRemove(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "manifest.json")
+	m := New(file)
+
+	// add entries, duplicates are ignored
+	m.Add(KindNftTable, "inet oc-daemon-filter")
+	m.Add(KindDevice, "tun0")
+	m.Add(KindDevice, "tun0")
+	want := []*Entry{
+		{Kind: KindNftTable, Name: "inet oc-daemon-filter"},
+		{Kind: KindDevice, Name: "tun0"},
+	}
+	if got := m.Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// check saved file
+	l, err := Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := l.Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// remove entries, unknown entries are ignored
+	m.Remove(KindNftTable, "inet oc-daemon-filter")
+	m.Remove(KindRule, "2111")
+	want = want[1:]
+	if got := m.Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	l, err = Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := l.Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestLoad tests Load
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	// not existing
+	if _, err := Load(filepath.Join(dir, "does-not-exist")); err == nil {
+		t.Error("not existing manifest should return error")
+	}
+
+	// invalid
+	file := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(file, []byte("invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(file); err == nil {
+		t.Error("invalid manifest should return error")
+	}
+}
+
+// TestCleanup tests Cleanup
+func TestCleanup(t *testing.T) {
+	oldRunCleanupCmd := runCleanupCmd
+	defer func() { runCleanupCmd = oldRunCleanupCmd }()
+
+	got := [][]string{}
+	runCleanupCmd = func(cmd []string) {
+		got = append(got, cmd)
+	}
+
+	// no manifest
+	file := filepath.Join(t.TempDir(), "manifest.json")
+	if ok, err := Cleanup(file); ok || err != nil {
+		t.Errorf("got %t %v, want false nil", ok, err)
+	}
+
+	// manifest, cleaned up in reverse order
+	m := New(file)
+	m.Add(KindDevice, "tun0")
+	m.Add(KindResolved, "tun0")
+	m.Add(KindNftTable, "inet oc-daemon-routing")
+	m.Add(KindRouteTable, "42111")
+	m.Add(KindRule, "2111")
+	m.Add("unknown", "test")
+	if ok, err := Cleanup(file); !ok || err != nil {
+		t.Errorf("got %t %v, want true nil", ok, err)
+	}
+	want := [][]string{
+		{"ip", "-4", "rule", "delete", "pref", "2111"},
+		{"ip", "-6", "rule", "delete", "pref", "2111"},
+		{"ip", "-4", "route", "flush", "table", "42111"},
+		{"ip", "-6", "route", "flush", "table", "42111"},
+		{"nft", "delete", "table", "inet", "oc-daemon-routing"},
+		{"resolvectl", "revert", "tun0"},
+		{"ip", "link", "delete", "tun0"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// manifest file removed
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("manifest should be removed: %v", err)
+	}
+}
+
+// TestOpenClose tests Open and Close
+func TestOpenClose(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "manifest.json")
+
+	// not open, only in memory
+	Add(KindDevice, "tun0")
+	Remove(KindDevice, "tun0")
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("manifest should not exist: %v", err)
+	}
+
+	// open
+	if err := Open(file); err != nil {
+		t.Fatal(err)
+	}
+	Add(KindDevice, "tun0")
+	m, err := Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Entry{{Kind: KindDevice, Name: "tun0"}}
+	if got := m.Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// close, file is kept
+	Close()
+	Remove(KindDevice, "tun0")
+	m, err = Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// open in invalid directory
+	if err := Open(filepath.Join(file, "invalid")); err == nil {
+		t.Error("open in invalid directory should return error")
+	}
+}
@@ -0,0 +1,100 @@
+// Package metrics contains the metrics of the daemon: the number of requests,
+// their errors and their latency histograms per API and request type
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds of the latency histogram buckets of
+// requests, the last bucket of a histogram counts the requests above the
+// last bound
+var LatencyBuckets = []time.Duration{
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+	10 * time.Second,
+}
+
+// Request are the metrics of a request type of an API, e.g., "Connect" of
+// the D-Bus API: the number of requests, the number of failed requests, the
+// sum of their latencies and the latency histogram with the number of
+// requests per bucket in LatencyBuckets
+type Request struct {
+	API     string
+	Name    string
+	Count   uint64
+	Errors  uint64
+	Latency time.Duration
+	Buckets []uint64
+}
+
+// Registry records the metrics of requests
+type Registry struct {
+	mutex    sync.Mutex
+	requests map[[2]string]*Request
+}
+
+// ObserveRequest records the request with name of api that took latency and
+// failed if failed is true
+func (r *Registry) ObserveRequest(api, name string, latency time.Duration, failed bool) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := [2]string{api, name}
+	m := r.requests[key]
+	if m == nil {
+		m = &Request{
+			API:     api,
+			Name:    name,
+			Buckets: make([]uint64, len(LatencyBuckets)+1),
+		}
+		r.requests[key] = m
+	}
+	m.Count++
+	if failed {
+		m.Errors++
+	}
+	m.Latency += latency
+	i := sort.Search(len(LatencyBuckets), func(i int) bool {
+		return latency <= LatencyBuckets[i]
+	})
+	m.Buckets[i]++
+}
+
+// Requests returns a copy of the metrics of all requests sorted by API and
+// request type
+func (r *Registry) Requests() []Request {
+	requests := []Request{}
+	if r == nil {
+		return requests
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, m := range r.requests {
+		c := *m
+		c.Buckets = append([]uint64{}, m.Buckets...)
+		requests = append(requests, c)
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		if requests[i].API != requests[j].API {
+			return requests[i].API < requests[j].API
+		}
+		return requests[i].Name < requests[j].Name
+	})
+	return requests
+}
+
+// NewRegistry returns a new metrics registry
+func NewRegistry() *Registry {
+	return &Registry{
+		requests: make(map[[2]string]*Request),
+	}
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestRegistryObserveRequest tests ObserveRequest and Requests of Registry
+func TestRegistryObserveRequest(t *testing.T) {
+	r := NewRegistry()
+	if got := r.Requests(); len(got) != 0 {
+		t.Errorf("got %v, want no requests", got)
+	}
+
+	r.ObserveRequest("socket", "VPNConfigUpdate", 5*time.Millisecond, false)
+	r.ObserveRequest("dbus", "GetStatus", time.Millisecond, false)
+	r.ObserveRequest("dbus", "Connect", 2*time.Second, false)
+	r.ObserveRequest("dbus", "Connect", time.Minute, true)
+
+	want := []Request{
+		{
+			API:     "dbus",
+			Name:    "Connect",
+			Count:   2,
+			Errors:  1,
+			Latency: time.Minute + 2*time.Second,
+			Buckets: []uint64{0, 0, 0, 0, 1, 1},
+		},
+		{
+			API:     "dbus",
+			Name:    "GetStatus",
+			Count:   1,
+			Latency: time.Millisecond,
+			Buckets: []uint64{1, 0, 0, 0, 0, 0},
+		},
+		{
+			API:     "socket",
+			Name:    "VPNConfigUpdate",
+			Count:   1,
+			Latency: 5 * time.Millisecond,
+			Buckets: []uint64{0, 1, 0, 0, 0, 0},
+		},
+	}
+	got := r.Requests()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// returned metrics are copies
+	got[0].Buckets[0] = 42
+	if r.Requests()[0].Buckets[0] != 0 {
+		t.Error("metrics should not be changed by caller")
+	}
+
+	// nil registry
+	r = nil
+	r.ObserveRequest("dbus", "GetStatus", time.Millisecond, false)
+	if got := r.Requests(); len(got) != 0 {
+		t.Errorf("got %v, want no requests", got)
+	}
+}
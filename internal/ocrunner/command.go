@@ -0,0 +1,126 @@
+package ocrunner
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// commandBuilder builds the argument list of an openconnect command from
+// validated flags, options and arguments, so values cannot inject other
+// options or break the command line; it keeps a copy of the arguments with
+// secrets redacted for logging
+type commandBuilder struct {
+	args     []string
+	redacted []string
+	err      error
+}
+
+// validOptionName returns whether name is a valid option name, e.g., "-4"
+// or "--no-proxy"
+func validOptionName(name string) bool {
+	n := strings.TrimPrefix(strings.TrimPrefix(name, "-"), "-")
+	if n == name || n == "" || n[0] == '-' {
+		return false
+	}
+	for _, r := range n {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// validValue returns whether value is a valid value of an option or
+// argument: not empty and without control characters
+func validValue(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, r := range value {
+		if r < ' ' || r == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// add adds arg and its redacted version to the command, it records err if
+// it is the first error
+func (b *commandBuilder) add(arg, redacted string, err error) *commandBuilder {
+	if b.err != nil {
+		return b
+	}
+	if err != nil {
+		b.err = err
+		return b
+	}
+	b.args = append(b.args, arg)
+	b.redacted = append(b.redacted, redacted)
+	return b
+}
+
+// flag adds the option name without value, e.g., "--cookie-on-stdin"
+func (b *commandBuilder) flag(name string) *commandBuilder {
+	var err error
+	if !validOptionName(name) {
+		err = fmt.Errorf("invalid option name %q", name)
+	}
+	return b.add(name, name, err)
+}
+
+// redactedOption adds the option name with value as "name=value", it is
+// logged with redacted as value
+func (b *commandBuilder) redactedOption(name, value, redacted string) *commandBuilder {
+	var err error
+	switch {
+	case !validOptionName(name) || !strings.HasPrefix(name, "--"):
+		err = fmt.Errorf("invalid option name %q", name)
+	case !validValue(value):
+		err = fmt.Errorf("invalid value of option %s", name)
+	}
+	return b.add(name+"="+value, name+"="+redacted, err)
+}
+
+// option adds the option name with value as "name=value"
+func (b *commandBuilder) option(name, value string) *commandBuilder {
+	return b.redactedOption(name, value, value)
+}
+
+// argument adds the positional argument value, it must not start with "-"
+// so openconnect cannot parse it as an option
+func (b *commandBuilder) argument(value string) *commandBuilder {
+	var err error
+	if !validValue(value) || strings.HasPrefix(value, "-") {
+		err = fmt.Errorf("invalid argument %q", value)
+	}
+	return b.add(value, value, err)
+}
+
+// build returns the arguments of the command or the first error while
+// adding them
+func (b *commandBuilder) build() ([]string, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if len(b.args) == 0 {
+		return nil, errors.New("no arguments")
+	}
+	return append([]string{}, b.args...), nil
+}
+
+// redactedArgs returns the arguments of the command with secrets redacted
+func (b *commandBuilder) redactedArgs() []string {
+	return append([]string{}, b.redacted...)
+}
+
+// redactURL returns the URL in s with its password redacted, s if it is not
+// a valid URL
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return s
+	}
+	return u.Redacted()
+}
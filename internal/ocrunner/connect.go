@@ -2,19 +2,22 @@ package ocrunner
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"net"
 	"os"
 	"os/exec"
 	"strconv"
 	"strings"
+	"time"
 
-	log "github.com/sirupsen/logrus"
 	"github.com/telekom-mms/oc-daemon/pkg/logininfo"
 )
 
-const (
-	// pidFile is the pid file for openconnect
-	pidFile = "/run/oc-daemon/openconnect.pid"
+var (
+	// PIDFile is the pid file for openconnect
+	PIDFile = "/run/oc-daemon/openconnect.pid"
 )
 
 // ConnectEvent is a connect runner event
@@ -23,11 +26,150 @@ type ConnectEvent struct {
 	// TODO: use a Type with more values?
 	Connect bool
 
+	// Reason is the reason of a gateway-initiated disconnect
+	Reason DisconnectReason
+
+	// Command is the command line of openconnect with secrets redacted
+	// on connect
+	Command []string
+
+	// Err is the error of a failed connection attempt, openconnect was
+	// not started
+	Err error
+
+	// cancel indicates a cancel of a connection attempt
+	cancel bool
+
 	// login info for connect
 	login *logininfo.LoginInfo
 
 	// Env are extra environment variables set during execution
 	env []string
+
+	// proxy is the proxy used for the connection, no proxy if empty
+	proxy string
+
+	// profile is the xml profile used for the connection, the default
+	// xml profile if empty
+	profile string
+
+	// compression is the compression mode of the connection, the
+	// default of openconnect if empty
+	compression string
+
+	// family is the address family of the connection to the gateway,
+	// any family if empty; disableIPv6 disables IPv6 in the tunnel
+	family      string
+	disableIPv6 bool
+}
+
+// Compression modes of openconnect
+const (
+	CompressionNone      = "none"
+	CompressionStateless = "stateless"
+	CompressionAll       = "all"
+)
+
+// ValidCompression returns whether the compression mode is valid, empty is
+// valid and means the default of openconnect
+func ValidCompression(mode string) bool {
+	switch mode {
+	case "", CompressionNone, CompressionStateless, CompressionAll:
+		return true
+	}
+	return false
+}
+
+// Address families of the connection to the VPN gateway
+const (
+	FamilyIPv4       = "ipv4"
+	FamilyIPv6       = "ipv6"
+	FamilyPreferIPv4 = "prefer-ipv4"
+	FamilyPreferIPv6 = "prefer-ipv6"
+)
+
+// ValidFamily returns whether the address family is valid, empty is valid
+// and means any address family
+func ValidFamily(family string) bool {
+	switch family {
+	case "", FamilyIPv4, FamilyIPv6, FamilyPreferIPv4, FamilyPreferIPv6:
+		return true
+	}
+	return false
+}
+
+// familyResolveTimeout is the timeout of resolving the gateway for a
+// preferred address family
+const familyResolveTimeout = 5 * time.Second
+
+// lookupIP resolves host, it can be replaced for testing
+var lookupIP = func(ctx context.Context, host string) ([]net.IP, error) {
+	return net.DefaultResolver.LookupIP(ctx, "ip", host)
+}
+
+// gatewayHostName returns the host name in the gateway address, e.g., in
+// "https://vpn.example.com:8443/group", empty if it is an IP address
+func gatewayHostName(address string) string {
+	host := address
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	if i := strings.IndexByte(host, '/'); i >= 0 {
+		host = host[:i]
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if net.ParseIP(host) != nil {
+		return ""
+	}
+	return host
+}
+
+// preferredResolve returns the openconnect resolve option HOST:IP for the
+// first address of the gateway in the preferred address family, so
+// openconnect connects to it; it is empty if the gateway has no address in
+// this family or cannot be resolved
+func preferredResolve(gateway string, ipv6 bool) string {
+	host := gatewayHostName(gateway)
+	if host == "" {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), familyResolveTimeout)
+	defer cancel()
+	ips, err := lookupIP(ctx, host)
+	if err != nil {
+		log.WithError(err).WithField("host", host).
+			Error("OC-Runner could not resolve gateway for preferred address family")
+		return ""
+	}
+	for _, ip := range ips {
+		if (ip.To4() == nil) == ipv6 {
+			return host + ":" + ip.String()
+		}
+	}
+	return ""
+}
+
+// family adds the openconnect options for the address family of the
+// connection to the gateway and IPv6 in the tunnel, preferred is the
+// resolve option for a preferred family or empty
+func (b *commandBuilder) family(family string, disableIPv6 bool, preferred string) *commandBuilder {
+	switch family {
+	case FamilyIPv4:
+		b.flag("-4")
+	case FamilyIPv6:
+		b.flag("-6")
+	case FamilyPreferIPv4, FamilyPreferIPv6:
+		if preferred != "" {
+			b.option("--resolve", preferred)
+		}
+	}
+	if disableIPv6 {
+		b.flag("--disable-ipv6")
+	}
+	return b
 }
 
 // Connect is a openconnect connection runner
@@ -38,6 +180,11 @@ type Connect struct {
 	// channel for openconnect exits
 	exits chan struct{}
 
+	// connection attempt waiting for the gateway address in the
+	// preferred family and channel for the resolve results
+	resolving *ConnectEvent
+	resolves  chan *familyResolve
+
 	// xml profile and vpnc-script paths
 	profile string
 	script  string
@@ -45,9 +192,19 @@ type Connect struct {
 	// tunnel device name
 	device string
 
+	// output scans the openconnect output for disconnect reasons
+	output *reasonWriter
+
+	// pidFile is the pid file for openconnect
+	pidFile string
+
+	// credentials of openconnect, root if nil
+	credentials *Credentials
+
 	// channels for commands from user
 	commands chan *ConnectEvent
-	done     chan struct{}
+	done     <-chan struct{}
+	cancel   context.CancelFunc
 
 	// channel for user facing events
 	events chan *ConnectEvent
@@ -59,61 +216,162 @@ func (c *Connect) savePidFile() {
 		return
 	}
 	pid := fmt.Sprintf("%d\n", c.command.Process.Pid)
-	err := os.WriteFile(pidFile, []byte(pid), 0600)
+	err := os.WriteFile(c.pidFile, []byte(pid), 0600)
 	if err != nil {
 		log.WithError(err).Error("OC-Runner writing pid error")
 	}
 }
 
-// handleConnect establishes the connection by starting openconnect
+// gateway returns the gateway address in the login info of the connect event
+func (e *ConnectEvent) gateway() string {
+	if e.login.ConnectURL != "" {
+		return e.login.ConnectURL
+	}
+	return e.login.Host
+}
+
+// needsResolve returns whether the gateway must be resolved for the
+// preferred address family before connecting, the resolve option of the
+// login info overrides a preferred family
+func (e *ConnectEvent) needsResolve() bool {
+	if e.family != FamilyPreferIPv4 && e.family != FamilyPreferIPv6 {
+		return false
+	}
+	return e.login.Resolve == "" && gatewayHostName(e.gateway()) != ""
+}
+
+// familyResolve is the result of resolving the gateway of a connection
+// attempt for the preferred address family
+type familyResolve struct {
+	event   *ConnectEvent
+	resolve string
+}
+
+// connectCommand returns the openconnect command for the connect event e,
+// preferred is the resolve option for a preferred family or empty
+func (c *Connect) connectCommand(e *ConnectEvent, preferred string) *commandBuilder {
+	profile := c.profile
+	if e.profile != "" {
+		profile = e.profile
+	}
+	host := e.gateway()
+	b := &commandBuilder{}
+	if profile != "" {
+		b.option("--xmlconfig", profile)
+	}
+	b.option("--script", c.script).
+		flag("--cookie-on-stdin").
+		argument(host)
+	if e.login.Fingerprint != "" {
+		b.option("--servercert", e.login.Fingerprint)
+	}
+	if e.proxy != "" {
+		b.redactedOption("--proxy", e.proxy, redactURL(e.proxy))
+	} else {
+		b.flag("--no-proxy")
+	}
+	if e.login.Resolve != "" {
+		b.option("--resolve", e.login.Resolve)
+	}
+	if c.device != "" {
+		b.option("--interface", c.device)
+	}
+	if e.compression != "" {
+		b.option("--compression", e.compression)
+	}
+	return b.family(e.family, e.disableIPv6, preferred)
+}
+
+// resolveFamily resolves the gateway of the connection attempt e for the
+// preferred address family and sends the result to the runner
+func (c *Connect) resolveFamily(e *ConnectEvent) {
+	r := &familyResolve{
+		event:   e,
+		resolve: preferredResolve(e.gateway(), e.family == FamilyPreferIPv6),
+	}
+	select {
+	case c.resolves <- r:
+	case <-c.done:
+	}
+}
+
+// handleConnect establishes the connection by starting openconnect, it
+// resolves the gateway for a preferred address family in the background
+// first, so the runner can still handle disconnects and cancels
 func (c *Connect) handleConnect(e *ConnectEvent) {
-	if c.command != nil {
+	if c.command != nil || c.resolving != nil {
 		// command seems to be running, stop here
 		log.WithField("error", "openconnect process already running").
 			Error("OC-Runner connect error")
 		return
 	}
 
+	if e.needsResolve() {
+		c.resolving = e
+		go c.resolveFamily(e)
+		return
+	}
+	c.startCommand(e, "")
+}
+
+// handleResolve continues the connection attempt waiting for the gateway
+// address in the preferred family, results of aborted attempts are ignored
+func (c *Connect) handleResolve(r *familyResolve) {
+	if r.event != c.resolving {
+		return
+	}
+	c.resolving = nil
+	c.startCommand(r.event, r.resolve)
+}
+
+// abortResolve aborts the connection attempt waiting for the gateway address
+// in the preferred family and signals the disconnect to the user, it returns
+// false if there is no such connection attempt
+func (c *Connect) abortResolve() bool {
+	if c.resolving == nil {
+		return false
+	}
+	log.Info("OC-Runner aborted connection attempt while resolving gateway")
+	c.resolving = nil
+	c.events <- &ConnectEvent{}
+	return true
+}
+
+// startCommand starts openconnect for the connect event e, preferred is the
+// resolve option for a preferred family or empty
+func (c *Connect) startCommand(e *ConnectEvent, preferred string) {
 	// create openconnect command and
 	// use login information from Authenticate():
 	//
 	// openconnect --cookie-on-stdin $HOST --servercert $FINGERPRINT
 	//
-	serverCert := fmt.Sprintf("--servercert=%s", e.login.Fingerprint)
-	xmlConfig := fmt.Sprintf("--xmlconfig=%s", c.profile)
-	script := fmt.Sprintf("--script=%s", c.script)
-	host := e.login.Host
-	if e.login.ConnectURL != "" {
-		host = e.login.ConnectURL
-	}
-	parameters := []string{
-		xmlConfig,
-		script,
-		"--cookie-on-stdin",
-		host,
-		serverCert,
-		"--no-proxy",
-	}
-	if e.login.Resolve != "" {
-		resolve := fmt.Sprintf("--resolve=%s", e.login.Resolve)
-		parameters = append(parameters, resolve)
-	}
-	if c.device != "" {
-		device := fmt.Sprintf("--interface=%s", c.device)
-		parameters = append(parameters, device)
+	command := c.connectCommand(e, preferred)
+	parameters, err := command.build()
+	if err != nil {
+		log.WithError(err).Error("OC-Runner invalid connect command error")
+		c.events <- &ConnectEvent{Err: err}
+		return
 	}
+	redacted := append([]string{"openconnect"}, command.redactedArgs()...)
+	log.WithField("command", redacted).Info("OC-Runner starting openconnect")
 	c.command = exec.Command("openconnect", parameters...)
 
 	// run command, pass login info to stdin
 	b := bytes.NewBufferString(e.login.Cookie)
 	c.command.Stdin = b
 	c.command.Stdout = os.Stdout
-	c.command.Stderr = os.Stderr
+	c.output = &reasonWriter{}
+	c.command.Stderr = io.MultiWriter(os.Stderr, c.output)
 	c.command.Env = append(os.Environ(), e.env...)
+	if c.credentials != nil {
+		c.command.SysProcAttr = c.credentials.sysProcAttr()
+	}
 
 	if err := c.command.Start(); err != nil {
 		log.WithError(err).Error("OC-Runner executing connect error")
-		c.exits <- struct{}{}
+		c.command = nil
+		c.output = nil
+		c.events <- &ConnectEvent{Err: err}
 		return
 	}
 
@@ -123,6 +381,7 @@ func (c *Connect) handleConnect(e *ConnectEvent) {
 	// signal connect to user
 	c.events <- &ConnectEvent{
 		Connect: true,
+		Command: redacted,
 	}
 
 	// wait for program termination and signal disconnect
@@ -138,6 +397,9 @@ func (c *Connect) handleConnect(e *ConnectEvent) {
 
 // handleDisconnect tears down the connection by stopping openconnect
 func (c *Connect) handleDisconnect() {
+	if c.abortResolve() {
+		return
+	}
 	if c.command == nil || c.command.Process == nil {
 		log.WithField("error", "no openconnect process running").
 			Error("OC-Runner disconnect error")
@@ -149,13 +411,38 @@ func (c *Connect) handleDisconnect() {
 	}
 }
 
+// handleCancel aborts a hanging connection attempt by killing openconnect,
+// openconnect cannot clean up in this case
+func (c *Connect) handleCancel() {
+	if c.abortResolve() {
+		return
+	}
+	if c.command == nil || c.command.Process == nil {
+		log.WithField("error", "no openconnect process running").
+			Error("OC-Runner cancel error")
+		return
+	}
+	if err := c.command.Process.Kill(); err != nil {
+		log.WithError(err).Error("OC-Runner killing openconnect for cancel error")
+	}
+}
+
 // handleOCExit handles openconnect program terminations
 func (c *Connect) handleOCExit() {
 	// clear command
 	c.command = nil
 
+	// get reason of gateway-initiated disconnect
+	reason := ReasonNone
+	if c.output != nil {
+		reason = c.output.get()
+		c.output = nil
+	}
+
 	// signal disconnect to user
-	c.events <- &ConnectEvent{}
+	c.events <- &ConnectEvent{
+		Reason: reason,
+	}
 }
 
 // handleStop handles stopping the runner
@@ -178,11 +465,18 @@ func (c *Connect) start() {
 				c.handleConnect(cmd)
 				break
 			}
+			if cmd.cancel {
+				c.handleCancel()
+				break
+			}
 			c.handleDisconnect()
 
 		case <-c.exits:
 			c.handleOCExit()
 
+		case r := <-c.resolves:
+			c.handleResolve(r)
+
 		case <-c.done:
 			c.handleStop()
 			return
@@ -190,25 +484,39 @@ func (c *Connect) start() {
 	}
 }
 
-// Start starts the connect runner
-func (c *Connect) Start() {
+// Start starts the connect runner, it runs until Stop is called or ctx is
+// canceled
+func (c *Connect) Start(ctx context.Context) error {
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.done = ctx.Done()
 	go c.start()
+	return nil
 }
 
 // Stop stops the connect runner
 func (c *Connect) Stop() {
-	close(c.done)
+	c.cancel()
 	for range c.events {
 		// wait for event channel close
 	}
 }
 
-// Connect connects the vpn by starting openconnect
-func (c *Connect) Connect(login *logininfo.LoginInfo, env []string) {
+// Connect connects the vpn by starting openconnect, proxy is the proxy used
+// for the connection or empty for no proxy, profile is the xml profile used
+// for the connection or empty for the default xml profile, compression is
+// the compression mode or empty for the default of openconnect, family is the
+// address family of the connection to the gateway or empty for any family and
+// disableIPv6 disables IPv6 in the tunnel
+func (c *Connect) Connect(login *logininfo.LoginInfo, env []string, proxy, profile, compression, family string, disableIPv6 bool) {
 	e := &ConnectEvent{
-		Connect: true,
-		login:   login,
-		env:     env,
+		Connect:     true,
+		login:       login,
+		env:         env,
+		proxy:       proxy,
+		profile:     profile,
+		compression: compression,
+		family:      family,
+		disableIPv6: disableIPv6,
 	}
 	c.commands <- e
 }
@@ -219,6 +527,24 @@ func (c *Connect) Disconnect() {
 	c.commands <- e
 }
 
+// Cancel aborts a connection attempt by killing openconnect
+func (c *Connect) Cancel() {
+	e := &ConnectEvent{cancel: true}
+	c.commands <- e
+}
+
+// SetPIDFile sets the pid file for openconnect, PIDFile is used by default;
+// it must be called before Start
+func (c *Connect) SetPIDFile(file string) {
+	c.pidFile = file
+}
+
+// SetCredentials sets the credentials openconnect runs with, openconnect
+// runs as root by default; it must be called before Start
+func (c *Connect) SetCredentials(credentials *Credentials) {
+	c.credentials = credentials
+}
+
 // Events returns the connect events channel
 func (c *Connect) Events() chan *ConnectEvent {
 	return c.events
@@ -230,11 +556,12 @@ func NewConnect(xmlProfile, vpncScript, device string) *Connect {
 		profile: xmlProfile,
 		script:  vpncScript,
 		device:  device,
+		pidFile: PIDFile,
 
-		exits: make(chan struct{}),
+		exits:    make(chan struct{}),
+		resolves: make(chan *familyResolve),
 
 		commands: make(chan *ConnectEvent),
-		done:     make(chan struct{}),
 
 		events: make(chan *ConnectEvent),
 	}
@@ -242,6 +569,12 @@ func NewConnect(xmlProfile, vpncScript, device string) *Connect {
 
 // CleanupConnect cleans up connect after a failed shutdown
 func CleanupConnect() {
+	CleanupConnectPIDFile(PIDFile)
+}
+
+// CleanupConnectPIDFile cleans up connect with pid file after a failed
+// shutdown
+func CleanupConnectPIDFile(pidFile string) {
 	// get pid from file
 	b, err := os.ReadFile(pidFile)
 	if err != nil {
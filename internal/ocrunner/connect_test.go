@@ -1,22 +1,47 @@
 package ocrunner
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/pkg/logininfo"
+)
 
 // TestConnectStartStop tests Start and Stop of Connect
 func TestConnectStartStop(t *testing.T) {
 	c := NewConnect("", "", "")
-	c.Start()
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
 	c.Stop()
 }
 
 // TestConnectDisconnect tests Disconnect of Connect
 func TestConnectDisconnect(t *testing.T) {
 	c := NewConnect("", "", "")
-	c.Start()
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
 	c.Disconnect()
 	c.Stop()
 }
 
+// TestConnectCancel tests Cancel of Connect
+func TestConnectCancel(t *testing.T) {
+	c := NewConnect("", "", "")
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	c.Cancel()
+	c.Stop()
+}
+
 // TestConnectEvents tests Events of Connect
 func TestConnectEvents(t *testing.T) {
 	c := NewConnect("", "", "")
@@ -43,11 +68,226 @@ func TestNewConnect(t *testing.T) {
 	if c.device != device {
 		t.Errorf("got %s, want %s", c.script, script)
 	}
+	if c.pidFile != PIDFile {
+		t.Errorf("got %s, want %s", c.pidFile, PIDFile)
+	}
 	if c.exits == nil ||
 		c.commands == nil ||
-		c.done == nil ||
 		c.events == nil {
 
 		t.Errorf("got nil, want != nil")
 	}
 }
+
+// TestConnectSetPIDFile tests SetPIDFile of Connect
+func TestConnectSetPIDFile(t *testing.T) {
+	c := NewConnect("", "", "")
+	want := "/some/pid/file"
+	c.SetPIDFile(want)
+	if c.pidFile != want {
+		t.Errorf("got %s, want %s", c.pidFile, want)
+	}
+}
+
+// TestValidCompression tests ValidCompression
+func TestValidCompression(t *testing.T) {
+	for _, valid := range []string{"", "none", "stateless", "all"} {
+		if !ValidCompression(valid) {
+			t.Errorf("%s should be valid", valid)
+		}
+	}
+	for _, invalid := range []string{"invalid", "None", " "} {
+		if ValidCompression(invalid) {
+			t.Errorf("%s should be invalid", invalid)
+		}
+	}
+}
+
+// TestValidFamily tests ValidFamily
+func TestValidFamily(t *testing.T) {
+	for _, valid := range []string{"", "ipv4", "ipv6", "prefer-ipv4", "prefer-ipv6"} {
+		if !ValidFamily(valid) {
+			t.Errorf("%s should be valid", valid)
+		}
+	}
+	for _, invalid := range []string{"invalid", "IPv4", "prefer", " "} {
+		if ValidFamily(invalid) {
+			t.Errorf("%s should be invalid", invalid)
+		}
+	}
+}
+
+// TestGatewayHostName tests gatewayHostName
+func TestGatewayHostName(t *testing.T) {
+	for address, want := range map[string]string{
+		"vpn.example.com":                    "vpn.example.com",
+		"vpn.example.com:8443":               "vpn.example.com",
+		"https://vpn.example.com:8443/group": "vpn.example.com",
+		"192.168.1.1":                        "",
+		"[2001:db8::1]:443":                  "",
+		"2001:db8::1":                        "",
+	} {
+		if got := gatewayHostName(address); got != want {
+			t.Errorf("%s: got %q, want %q", address, got, want)
+		}
+	}
+}
+
+// TestCommandBuilderFamily tests family of commandBuilder
+func TestCommandBuilderFamily(t *testing.T) {
+	for _, test := range []struct {
+		family      string
+		disableIPv6 bool
+		preferred   string
+		want        []string
+	}{
+		{"", false, "", nil},
+		{"", true, "", []string{"--disable-ipv6"}},
+		{"ipv4", false, "", []string{"-4"}},
+		{"ipv6", true, "", []string{"-6", "--disable-ipv6"}},
+		{"prefer-ipv4", false, "vpn.example.com:192.168.1.1", []string{"--resolve=vpn.example.com:192.168.1.1"}},
+		{"prefer-ipv6", false, "", nil},
+	} {
+		got := (&commandBuilder{}).family(test.family, test.disableIPv6,
+			test.preferred).args
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%v: got %v, want %v", test, got, test.want)
+		}
+	}
+}
+
+// TestPreferredResolve tests preferredResolve
+func TestPreferredResolve(t *testing.T) {
+	old := lookupIP
+	defer func() { lookupIP = old }()
+	lookupIP = func(context.Context, string) ([]net.IP, error) {
+		return []net.IP{
+			net.ParseIP("2001:db8::1"),
+			net.ParseIP("192.168.1.1"),
+		}, nil
+	}
+
+	gateway := "https://vpn.example.com/group"
+	if got := preferredResolve(gateway, false); got != "vpn.example.com:192.168.1.1" {
+		t.Errorf("got %s, want vpn.example.com:192.168.1.1", got)
+	}
+	if got := preferredResolve(gateway, true); got != "vpn.example.com:2001:db8::1" {
+		t.Errorf("got %s, want vpn.example.com:2001:db8::1", got)
+	}
+
+	// no address in preferred family
+	lookupIP = func(context.Context, string) ([]net.IP, error) {
+		return []net.IP{net.ParseIP("192.168.1.1")}, nil
+	}
+	if got := preferredResolve(gateway, true); got != "" {
+		t.Errorf("got %s, want empty", got)
+	}
+
+	// resolve error
+	lookupIP = func(context.Context, string) ([]net.IP, error) {
+		return nil, errors.New("test error")
+	}
+	if got := preferredResolve(gateway, false); got != "" {
+		t.Errorf("got %s, want empty", got)
+	}
+}
+
+// TestConnectEventNeedsResolve tests needsResolve of ConnectEvent
+func TestConnectEventNeedsResolve(t *testing.T) {
+	for _, test := range []struct {
+		family  string
+		host    string
+		resolve string
+		want    bool
+	}{
+		{"", "vpn.example.com", "", false},
+		{"ipv4", "vpn.example.com", "", false},
+		{"prefer-ipv4", "vpn.example.com", "", true},
+		{"prefer-ipv6", "vpn.example.com", "", true},
+		{"prefer-ipv4", "vpn.example.com", "vpn.example.com:10.0.0.1", false},
+		{"prefer-ipv6", "192.168.1.1", "", false},
+	} {
+		e := &ConnectEvent{
+			family: test.family,
+			login: &logininfo.LoginInfo{
+				Host:    test.host,
+				Resolve: test.resolve,
+			},
+		}
+		if got := e.needsResolve(); got != test.want {
+			t.Errorf("%v: got %t, want %t", test, got, test.want)
+		}
+	}
+}
+
+// TestConnectDisconnectResolving tests Disconnect of Connect while resolving
+// the gateway for the preferred address family
+func TestConnectDisconnectResolving(t *testing.T) {
+	old := lookupIP
+	defer func() { lookupIP = old }()
+	release := make(chan struct{})
+	resolved := make(chan struct{})
+	lookupIP = func(context.Context, string) ([]net.IP, error) {
+		defer close(resolved)
+		<-release
+		return []net.IP{net.ParseIP("192.168.1.1")}, nil
+	}
+
+	c := NewConnect("", "", "")
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	login := &logininfo.LoginInfo{Host: "vpn.example.com"}
+	c.Connect(login, nil, "", "", "", FamilyPreferIPv4, false)
+
+	// disconnect is handled while resolving
+	c.Disconnect()
+	if e := <-c.Events(); e.Connect || e.Err != nil {
+		t.Errorf("got %v, want disconnect", e)
+	}
+
+	// late resolve result is ignored
+	close(release)
+	<-resolved
+	c.Stop()
+	if c.command != nil || c.resolving != nil {
+		t.Error("connection attempt should be aborted")
+	}
+}
+
+// TestConnectSetCredentials tests SetCredentials of Connect
+func TestConnectSetCredentials(t *testing.T) {
+	c := NewConnect("", "", "")
+	want := &Credentials{UID: 1000, GID: 1000}
+	c.SetCredentials(want)
+	if c.credentials != want {
+		t.Errorf("got %p, want %p", c.credentials, want)
+	}
+}
+
+// TestConnectStartError tests Connect of Connect when openconnect cannot be
+// started with invalid credentials
+func TestConnectStartError(t *testing.T) {
+	// fake openconnect, so only the credentials fail
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "openconnect"),
+		[]byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	c := NewConnect("", "/some/script", "")
+	c.SetCredentials(&Credentials{UID: math.MaxUint32, GID: math.MaxUint32})
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	login := &logininfo.LoginInfo{Host: "192.168.1.1", Cookie: "cookie"}
+	c.Connect(login, nil, "", "", "", "", false)
+	if e := <-c.Events(); e.Connect || e.Err == nil {
+		t.Errorf("got %v, want connect error", e)
+	}
+	c.Stop()
+	if c.command != nil {
+		t.Error("command should be reset")
+	}
+}
@@ -0,0 +1,92 @@
+package ocrunner
+
+import (
+	"fmt"
+	"os/user"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// capabilities maps the names of the capabilities openconnect can be run
+// with to their values
+var capabilities = map[string]uintptr{
+	"CAP_NET_ADMIN":        unix.CAP_NET_ADMIN,
+	"CAP_NET_RAW":          unix.CAP_NET_RAW,
+	"CAP_NET_BIND_SERVICE": unix.CAP_NET_BIND_SERVICE,
+}
+
+// DefaultCapabilities are the capabilities openconnect needs to create
+// and configure the tunnel device
+var DefaultCapabilities = []string{"CAP_NET_ADMIN"}
+
+// ValidCapability returns whether the capability name is valid
+func ValidCapability(name string) bool {
+	_, ok := capabilities[name]
+	return ok
+}
+
+// Credentials are the user, group and capabilities openconnect runs with
+// instead of root
+type Credentials struct {
+	UID          uint32
+	GID          uint32
+	Capabilities []string
+}
+
+// sysProcAttr returns the process attributes that run the openconnect
+// command with the credentials, the capabilities are kept as ambient
+// capabilities after switching the user
+func (c *Credentials) sysProcAttr() *syscall.SysProcAttr {
+	caps := []uintptr{}
+	for _, name := range c.Capabilities {
+		caps = append(caps, capabilities[name])
+	}
+	return &syscall.SysProcAttr{
+		Credential: &syscall.Credential{
+			Uid:         c.UID,
+			Gid:         c.GID,
+			NoSetGroups: true,
+		},
+		AmbientCaps: caps,
+	}
+}
+
+// NewCredentials returns new Credentials for the system user with name,
+// the group with name or the primary group of the user if empty, and the
+// capabilities caps
+func NewCredentials(name, group string, caps []string) (*Credentials, error) {
+	for _, c := range caps {
+		if !ValidCapability(c) {
+			return nil, fmt.Errorf("invalid capability %s", c)
+		}
+	}
+
+	u, err := user.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	gid := u.Gid
+	if group != "" {
+		g, err := user.LookupGroup(group)
+		if err != nil {
+			return nil, err
+		}
+		gid = g.Gid
+	}
+
+	uidNum, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	gidNum, err := strconv.ParseUint(gid, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	return &Credentials{
+		UID:          uint32(uidNum),
+		GID:          uint32(gidNum),
+		Capabilities: append([]string{}, caps...),
+	}, nil
+}
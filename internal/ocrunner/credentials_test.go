@@ -0,0 +1,80 @@
+package ocrunner
+
+import (
+	"os"
+	"os/user"
+	"reflect"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// TestValidCapability tests ValidCapability
+func TestValidCapability(t *testing.T) {
+	for _, valid := range []string{"CAP_NET_ADMIN", "CAP_NET_RAW", "CAP_NET_BIND_SERVICE"} {
+		if !ValidCapability(valid) {
+			t.Errorf("%s should be valid", valid)
+		}
+	}
+	for _, invalid := range []string{"", "cap_net_admin", "CAP_SYS_ADMIN"} {
+		if ValidCapability(invalid) {
+			t.Errorf("%s should not be valid", invalid)
+		}
+	}
+}
+
+// TestNewCredentials tests NewCredentials
+func TestNewCredentials(t *testing.T) {
+	u, err := user.Current()
+	if err != nil {
+		t.Skip(err)
+	}
+	g, err := user.LookupGroupId(u.Gid)
+	if err != nil {
+		t.Skip(err)
+	}
+
+	// primary group of user
+	c, err := NewCredentials(u.Username, "", DefaultCapabilities)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int(c.UID) != os.Getuid() || int(c.GID) != os.Getgid() {
+		t.Errorf("got %d:%d, want %d:%d", c.UID, c.GID, os.Getuid(), os.Getgid())
+	}
+
+	// group
+	if _, err := NewCredentials(u.Username, g.Name, nil); err != nil {
+		t.Error(err)
+	}
+
+	// invalid
+	for _, invalid := range []struct{ user, group, cap string }{
+		{"oc-daemon-does-not-exist", "", "CAP_NET_ADMIN"},
+		{u.Username, "oc-daemon-does-not-exist", "CAP_NET_ADMIN"},
+		{u.Username, "", "CAP_SYS_ADMIN"},
+	} {
+		if _, err := NewCredentials(invalid.user, invalid.group,
+			[]string{invalid.cap}); err == nil {
+			t.Errorf("%v should not be valid", invalid)
+		}
+	}
+}
+
+// TestCredentialsSysProcAttr tests sysProcAttr of Credentials
+func TestCredentialsSysProcAttr(t *testing.T) {
+	c := &Credentials{
+		UID:          1000,
+		GID:          1001,
+		Capabilities: []string{"CAP_NET_ADMIN", "CAP_NET_RAW"},
+	}
+	attr := c.sysProcAttr()
+	if attr.Credential.Uid != 1000 || attr.Credential.Gid != 1001 {
+		t.Errorf("got %d:%d, want 1000:1001",
+			attr.Credential.Uid, attr.Credential.Gid)
+	}
+	want := []uintptr{unix.CAP_NET_ADMIN, unix.CAP_NET_RAW}
+	if !reflect.DeepEqual(attr.AmbientCaps, want) {
+		t.Errorf("got %v, want %v", attr.AmbientCaps, want)
+	}
+}
@@ -0,0 +1,6 @@
+package ocrunner
+
+import "github.com/telekom-mms/oc-daemon/internal/logging"
+
+// log is the logger of the OC runner
+var log = logging.Component(logging.ComponentOCRunner)
@@ -0,0 +1,104 @@
+package ocrunner
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+)
+
+// DisconnectReason is the reason of a gateway-initiated disconnect
+type DisconnectReason string
+
+// Disconnect reasons, empty if the gateway did not send a known reason
+const (
+	ReasonNone        DisconnectReason = ""
+	ReasonIdleTimeout DisconnectReason = "idle-timeout"
+	ReasonAdminReset  DisconnectReason = "admin-reset"
+	ReasonMaxSession  DisconnectReason = "max-session"
+)
+
+// Transient returns whether the disconnect reason is transient, i.e., a
+// reconnect can succeed; the session ended by the gateway on admin reset
+// or after the max session time is not transient
+func (r DisconnectReason) Transient() bool {
+	switch r {
+	case ReasonAdminReset, ReasonMaxSession:
+		return false
+	}
+	return true
+}
+
+// disconnectMessages maps messages of the gateway in the openconnect
+// output to disconnect reasons
+var disconnectMessages = []struct {
+	message string
+	reason  DisconnectReason
+}{
+	{"idle timeout", ReasonIdleTimeout},
+	{"idle-timeout", ReasonIdleTimeout},
+	{"administrator reset", ReasonAdminReset},
+	{"admin reset", ReasonAdminReset},
+	{"terminated by administrator", ReasonAdminReset},
+	{"max time exceeded", ReasonMaxSession},
+	{"maximum session time", ReasonMaxSession},
+	{"max session", ReasonMaxSession},
+}
+
+// parseDisconnectReason returns the disconnect reason in line of the
+// openconnect output, ReasonNone if there is no reason in line
+func parseDisconnectReason(line string) DisconnectReason {
+	line = strings.ToLower(line)
+	for _, m := range disconnectMessages {
+		if strings.Contains(line, m.message) {
+			return m.reason
+		}
+	}
+	return ReasonNone
+}
+
+// maxReasonLine is the maximum length of a buffered output line
+const maxReasonLine = 4096
+
+// reasonWriter scans the openconnect output written to it line by line for
+// disconnect reasons of the gateway
+type reasonWriter struct {
+	mutex  sync.Mutex
+	line   []byte
+	reason DisconnectReason
+}
+
+// Write writes p to the reasonWriter
+func (r *reasonWriter) Write(p []byte) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			r.line = append(r.line, p...)
+			if len(r.line) > maxReasonLine {
+				r.line = r.line[len(r.line)-maxReasonLine:]
+			}
+			break
+		}
+		r.line = append(r.line, p[:i]...)
+		if reason := parseDisconnectReason(string(r.line)); reason != ReasonNone {
+			r.reason = reason
+		}
+		r.line = r.line[:0]
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// get returns the last disconnect reason found in the output
+func (r *reasonWriter) get() DisconnectReason {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if reason := parseDisconnectReason(string(r.line)); reason != ReasonNone {
+		return reason
+	}
+	return r.reason
+}
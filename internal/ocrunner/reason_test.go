@@ -0,0 +1,65 @@
+package ocrunner
+
+import "testing"
+
+// TestDisconnectReasonTransient tests Transient of DisconnectReason
+func TestDisconnectReasonTransient(t *testing.T) {
+	for _, r := range []DisconnectReason{ReasonNone, ReasonIdleTimeout} {
+		if !r.Transient() {
+			t.Errorf("%q should be transient", r)
+		}
+	}
+	for _, r := range []DisconnectReason{ReasonAdminReset, ReasonMaxSession} {
+		if r.Transient() {
+			t.Errorf("%q should not be transient", r)
+		}
+	}
+}
+
+// TestParseDisconnectReason tests parseDisconnectReason
+func TestParseDisconnectReason(t *testing.T) {
+	for line, want := range map[string]DisconnectReason{
+		"":                                    ReasonNone,
+		"Connected as 192.168.1.2, using SSL": ReasonNone,
+		"Received server disconnect: b0 'Idle Timeout'":        ReasonIdleTimeout,
+		"Received server disconnect: b0 'Administrator Reset'": ReasonAdminReset,
+		"Session terminated by administrator":                  ReasonAdminReset,
+		"Received server disconnect: b0 'Max time exceeded'":   ReasonMaxSession,
+	} {
+		got := parseDisconnectReason(line)
+		if got != want {
+			t.Errorf("%q: got %q, want %q", line, got, want)
+		}
+	}
+}
+
+// TestReasonWriter tests reasonWriter
+func TestReasonWriter(t *testing.T) {
+	r := &reasonWriter{}
+	if got := r.get(); got != ReasonNone {
+		t.Errorf("got %q, want none", got)
+	}
+
+	// reason split across writes
+	for _, s := range []string{
+		"Got CONNECT response: HTTP/1.1 200 OK\nReceived server ",
+		"disconnect: b0 'Idle ",
+		"Timeout'\nUnrecoverable I/O error; exiting.\n",
+	} {
+		n, err := r.Write([]byte(s))
+		if err != nil || n != len(s) {
+			t.Fatalf("got %d, %v", n, err)
+		}
+	}
+	if got := r.get(); got != ReasonIdleTimeout {
+		t.Errorf("got %q, want %q", got, ReasonIdleTimeout)
+	}
+
+	// last reason without line break
+	if _, err := r.Write([]byte("Session terminated by administrator")); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.get(); got != ReasonAdminReset {
+		t.Errorf("got %q, want %q", got, ReasonAdminReset)
+	}
+}
@@ -0,0 +1,253 @@
+// Package posture contains the posture checks of the host that run before a
+// VPN connect, e.g., if disk encryption is active
+package posture
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// Built-in checks
+const (
+	CheckLUKS       = "luks"
+	CheckScreenLock = "screenlock"
+	CheckKernel     = "kernel"
+)
+
+// CheckScript is the name of the check that runs a script
+const CheckScript = "script"
+
+// ValidCheck returns whether name is a built-in check
+func ValidCheck(name string) bool {
+	switch name {
+	case CheckLUKS, CheckScreenLock, CheckKernel:
+		return true
+	}
+	return false
+}
+
+// ValidKernelVersion returns whether version is a valid kernel version for
+// the kernel check, e.g., "6.1"
+func ValidKernelVersion(version string) bool {
+	_, err := parseVersion(version)
+	return err == nil
+}
+
+// Result is the result of a posture check
+type Result struct {
+	// Check is the name of the check
+	Check string
+
+	// Passed specifies if the check passed
+	Passed bool
+
+	// Message describes the result
+	Message string
+}
+
+// String returns the result as string
+func (r *Result) String() string {
+	state := "failed"
+	if r.Passed {
+		state = "passed"
+	}
+	return fmt.Sprintf("%s %s: %s", r.Check, state, r.Message)
+}
+
+var (
+	// sysBlock is the sysfs directory of block devices
+	sysBlock = "/sys/block"
+
+	// uname returns the kernel release
+	uname = func() (string, error) {
+		var u unix.Utsname
+		if err := unix.Uname(&u); err != nil {
+			return "", err
+		}
+		return unix.ByteSliceToString(u.Release[:]), nil
+	}
+
+	// runCommand runs the command name with args and returns its output
+	runCommand = func(ctx context.Context, name string, args ...string) (string, error) {
+		var stdout bytes.Buffer
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Stdout = &stdout
+		err := cmd.Run()
+		return strings.TrimSpace(stdout.String()), err
+	}
+)
+
+// parseVersion parses the numeric components of a kernel version, e.g.,
+// "6.1.0-13-amd64" returns [6 1 0]
+func parseVersion(version string) ([]int, error) {
+	parts := []int{}
+	for _, p := range strings.Split(version, ".") {
+		end := 0
+		for end < len(p) && p[end] >= '0' && p[end] <= '9' {
+			end++
+		}
+		if end == 0 {
+			break
+		}
+		n, err := strconv.Atoi(p[:end])
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, n)
+		if end < len(p) {
+			break
+		}
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("invalid version: %s", version)
+	}
+	return parts, nil
+}
+
+// versionAtLeast returns whether version is at least minimum
+func versionAtLeast(version, minimum []int) bool {
+	for i, m := range minimum {
+		v := 0
+		if i < len(version) {
+			v = version[i]
+		}
+		if v != m {
+			return v > m
+		}
+	}
+	return true
+}
+
+// LUKS checks if a LUKS encrypted device is active
+func LUKS() *Result {
+	r := &Result{Check: CheckLUKS}
+	uuids, _ := filepath.Glob(filepath.Join(sysBlock, "dm-*", "dm", "uuid"))
+	for _, file := range uuids {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		if strings.HasPrefix(string(b), "CRYPT-LUKS") {
+			r.Passed = true
+			r.Message = "encrypted device " +
+				filepath.Base(filepath.Dir(filepath.Dir(file))) + " active"
+			return r
+		}
+	}
+	r.Message = "no encrypted device active"
+	return r
+}
+
+// Kernel checks if the kernel version is at least minimum
+func Kernel(minimum string) *Result {
+	r := &Result{Check: CheckKernel}
+	minVersion, err := parseVersion(minimum)
+	if err != nil {
+		r.Message = err.Error()
+		return r
+	}
+	release, err := uname()
+	if err != nil {
+		r.Message = err.Error()
+		return r
+	}
+	version, err := parseVersion(release)
+	if err != nil {
+		r.Message = err.Error()
+		return r
+	}
+	r.Passed = versionAtLeast(version, minVersion)
+	r.Message = fmt.Sprintf("kernel %s, required %s", release, minimum)
+	return r
+}
+
+// ScreenLock checks if the screen lock of the user of the active graphical
+// session is enabled, only GNOME is supported
+func ScreenLock(ctx context.Context) *Result {
+	r := &Result{Check: CheckScreenLock}
+
+	// get user of active session
+	session, err := runCommand(ctx, "loginctl", "show-seat", "seat0",
+		"--property=ActiveSession", "--value")
+	if err != nil || session == "" {
+		r.Message = "no active session"
+		return r
+	}
+	user, err := runCommand(ctx, "loginctl", "show-session", session,
+		"--property=Name", "--value")
+	if err != nil || user == "" {
+		r.Message = "no user of active session"
+		return r
+	}
+	uid, err := runCommand(ctx, "loginctl", "show-session", session,
+		"--property=User", "--value")
+	if err != nil || uid == "" {
+		r.Message = "no user of active session"
+		return r
+	}
+
+	// get screen lock setting of user
+	enabled, err := runCommand(ctx, "runuser", "-u", user, "--", "env",
+		"DBUS_SESSION_BUS_ADDRESS=unix:path=/run/user/"+uid+"/bus",
+		"gsettings", "get", "org.gnome.desktop.screensaver", "lock-enabled")
+	if err != nil {
+		r.Message = "could not get screen lock setting"
+		return r
+	}
+	r.Passed = enabled == "true"
+	r.Message = "screen lock of user " + user + " enabled: " + enabled
+	return r
+}
+
+// Script runs the script file as check, the check passes if the script
+// exits with status 0; the first line of its output is the message
+func Script(ctx context.Context, file string) *Result {
+	r := &Result{Check: CheckScript}
+	out, err := runCommand(ctx, file)
+	r.Passed = err == nil
+	r.Message = strings.SplitN(out, "\n", 2)[0]
+	if err != nil && r.Message == "" {
+		r.Message = err.Error()
+	}
+	return r
+}
+
+// Checker runs posture checks
+type Checker struct {
+	// Checks are the names of the built-in checks
+	Checks []string
+
+	// MinKernelVersion is the minimum kernel version of the kernel check
+	MinKernelVersion string
+
+	// Script is the file of a script that runs as additional check,
+	// no script is run if empty
+	Script string
+}
+
+// Run runs all checks and returns their results
+func (c *Checker) Run(ctx context.Context) []*Result {
+	results := []*Result{}
+	for _, check := range c.Checks {
+		switch check {
+		case CheckLUKS:
+			results = append(results, LUKS())
+		case CheckScreenLock:
+			results = append(results, ScreenLock(ctx))
+		case CheckKernel:
+			results = append(results, Kernel(c.MinKernelVersion))
+		}
+	}
+	if c.Script != "" {
+		results = append(results, Script(ctx, c.Script))
+	}
+	return results
+}
@@ -0,0 +1,220 @@
+package posture
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestValidCheck tests ValidCheck
+func TestValidCheck(t *testing.T) {
+	for _, valid := range []string{CheckLUKS, CheckScreenLock, CheckKernel} {
+		if !ValidCheck(valid) {
+			t.Errorf("%s should be valid", valid)
+		}
+	}
+	for _, invalid := range []string{"", CheckScript, "invalid"} {
+		if ValidCheck(invalid) {
+			t.Errorf("%s should be invalid", invalid)
+		}
+	}
+}
+
+// TestParseVersion tests parseVersion
+func TestParseVersion(t *testing.T) {
+	for _, test := range []struct {
+		version string
+		want    []int
+	}{
+		{"6", []int{6}},
+		{"6.1", []int{6, 1}},
+		{"6.1.0-13-amd64", []int{6, 1, 0}},
+		{"5.15.0rc1", []int{5, 15, 0}},
+	} {
+		got, err := parseVersion(test.version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("got %v, want %v", got, test.want)
+		}
+	}
+
+	for _, invalid := range []string{"", "invalid", ".1"} {
+		if _, err := parseVersion(invalid); err == nil {
+			t.Errorf("%s should be invalid", invalid)
+		}
+	}
+}
+
+// TestVersionAtLeast tests versionAtLeast
+func TestVersionAtLeast(t *testing.T) {
+	for _, test := range []struct {
+		version []int
+		minimum []int
+		want    bool
+	}{
+		{[]int{6, 1, 0}, []int{6, 1}, true},
+		{[]int{6, 1}, []int{6, 1, 0}, true},
+		{[]int{6, 2}, []int{6, 10}, false},
+		{[]int{5, 15, 0}, []int{6}, false},
+		{[]int{6, 1}, []int{6, 1, 1}, false},
+	} {
+		got := versionAtLeast(test.version, test.minimum)
+		if got != test.want {
+			t.Errorf("%v >= %v: got %t, want %t",
+				test.version, test.minimum, got, test.want)
+		}
+	}
+}
+
+// TestLUKS tests LUKS
+func TestLUKS(t *testing.T) {
+	defer func(dir string) { sysBlock = dir }(sysBlock)
+	sysBlock = t.TempDir()
+
+	// writeUUID writes uuid of device
+	writeUUID := func(device, uuid string) {
+		dir := filepath.Join(sysBlock, device, "dm")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "uuid"),
+			[]byte(uuid+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// no encrypted device
+	writeUUID("dm-0", "LVM-abc")
+	if r := LUKS(); r.Passed {
+		t.Errorf("check should fail: %s", r)
+	}
+
+	// encrypted device
+	writeUUID("dm-1", "CRYPT-LUKS2-abc-sda3_crypt")
+	if r := LUKS(); !r.Passed {
+		t.Errorf("check should pass: %s", r)
+	}
+}
+
+// TestKernel tests Kernel
+func TestKernel(t *testing.T) {
+	defer func(f func() (string, error)) { uname = f }(uname)
+	uname = func() (string, error) {
+		return "6.1.0-13-amd64", nil
+	}
+
+	for _, test := range []struct {
+		minimum string
+		want    bool
+	}{
+		{"5.10", true},
+		{"6.1", true},
+		{"6.5", false},
+		{"invalid", false},
+	} {
+		if r := Kernel(test.minimum); r.Passed != test.want {
+			t.Errorf("%s: got %t, want %t", test.minimum, r.Passed, test.want)
+		}
+	}
+
+	// uname error
+	uname = func() (string, error) {
+		return "", errors.New("test error")
+	}
+	if r := Kernel("5.10"); r.Passed {
+		t.Errorf("check should fail: %s", r)
+	}
+}
+
+// TestScreenLock tests ScreenLock
+func TestScreenLock(t *testing.T) {
+	defer func(f func(context.Context, string, ...string) (string, error)) {
+		runCommand = f
+	}(runCommand)
+
+	for _, test := range []struct {
+		session string
+		enabled string
+		want    bool
+	}{
+		{"", "true", false},
+		{"2", "false", false},
+		{"2", "true", true},
+	} {
+		runCommand = func(_ context.Context, name string, args ...string) (string, error) {
+			cmd := name + " " + strings.Join(args, " ")
+			switch {
+			case strings.HasPrefix(cmd, "loginctl show-seat"):
+				return test.session, nil
+			case strings.Contains(cmd, "--property=Name"):
+				return "user", nil
+			case strings.Contains(cmd, "--property=User"):
+				return "1000", nil
+			case strings.HasPrefix(cmd, "runuser -u user"):
+				return test.enabled, nil
+			}
+			return "", errors.New("unexpected command: " + cmd)
+		}
+		if r := ScreenLock(context.Background()); r.Passed != test.want {
+			t.Errorf("got %t, want %t: %s", r.Passed, test.want, r)
+		}
+	}
+}
+
+// TestScript tests Script
+func TestScript(t *testing.T) {
+	dir := t.TempDir()
+	for _, test := range []struct {
+		script  string
+		want    bool
+		message string
+	}{
+		{"echo ok\necho more", true, "ok"},
+		{"echo not ok\nexit 1", false, "not ok"},
+		{"exit 2", false, "exit status 2"},
+	} {
+		file := filepath.Join(dir, "check.sh")
+		if err := os.WriteFile(file, []byte("#!/bin/sh\n"+test.script+"\n"),
+			0755); err != nil {
+			t.Fatal(err)
+		}
+		r := Script(context.Background(), file)
+		if r.Passed != test.want || r.Message != test.message {
+			t.Errorf("got %s, want %t, %s", r, test.want, test.message)
+		}
+	}
+}
+
+// TestCheckerRun tests Run of Checker
+func TestCheckerRun(t *testing.T) {
+	defer func(f func() (string, error)) { uname = f }(uname)
+	uname = func() (string, error) {
+		return "6.1.0", nil
+	}
+	defer func(dir string) { sysBlock = dir }(sysBlock)
+	sysBlock = t.TempDir()
+
+	c := &Checker{
+		Checks:           []string{CheckLUKS, CheckKernel},
+		MinKernelVersion: "6.1",
+		Script:           "/bin/true",
+	}
+	got := map[string]bool{}
+	for _, r := range c.Run(context.Background()) {
+		got[r.Check] = r.Passed
+	}
+	want := map[string]bool{
+		CheckLUKS:   false,
+		CheckKernel: true,
+		CheckScript: true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
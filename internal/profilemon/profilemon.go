@@ -2,7 +2,9 @@ package profilemon
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -14,7 +16,8 @@ import (
 type ProfileMon struct {
 	file    string
 	updates chan struct{}
-	done    chan struct{}
+	done    <-chan struct{}
+	cancel  context.CancelFunc
 	hash    [sha256.Size]byte
 }
 
@@ -46,14 +49,8 @@ func (p *ProfileMon) handleEvent() {
 }
 
 // start starts the profile monitor
-func (p *ProfileMon) start() {
+func (p *ProfileMon) start(watcher *fsnotify.Watcher) {
 	defer close(p.updates)
-
-	// create watcher
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		log.WithError(err).Fatal("XML Profile watcher create error")
-	}
 	defer func() {
 		if err := watcher.Close(); err != nil {
 			log.WithError(err).Error("XML Profile watcher close error")
@@ -97,14 +94,24 @@ func (p *ProfileMon) start() {
 	}
 }
 
-// Start starts the profile monitor
-func (p *ProfileMon) Start() {
-	go p.start()
+// Start starts the profile monitor, it runs until Stop is called or ctx is
+// canceled
+func (p *ProfileMon) Start(ctx context.Context) error {
+	// create watcher
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("XML Profile watcher create error: %w", err)
+	}
+
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.done = ctx.Done()
+	go p.start(watcher)
+	return nil
 }
 
 // Stop stops the profile monitor
 func (p *ProfileMon) Stop() {
-	close(p.done)
+	p.cancel()
 	for range p.updates {
 		// wait for channel shutdown
 	}
@@ -120,6 +127,5 @@ func NewProfileMon(file string) *ProfileMon {
 	return &ProfileMon{
 		file:    file,
 		updates: make(chan struct{}),
-		done:    make(chan struct{}),
 	}
 }
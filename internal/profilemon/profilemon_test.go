@@ -2,6 +2,7 @@ package profilemon
 
 import (
 	"bytes"
+	"context"
 	"log"
 	"os"
 	"testing"
@@ -45,7 +46,9 @@ func TestProfileMonStartStop(t *testing.T) {
 	defer os.Remove(f)
 
 	p := NewProfileMon(f)
-	p.Start()
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
 	p.Stop()
 }
 
@@ -56,9 +59,7 @@ func TestNewProfileMon(t *testing.T) {
 	if p.file != f {
 		t.Errorf("got %s, want %s", p.file, f)
 	}
-	if p.updates == nil ||
-		p.done == nil {
-
+	if p.updates == nil {
 		t.Errorf("got nil, want != nil")
 	}
 }
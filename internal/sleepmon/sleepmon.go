@@ -1,6 +1,9 @@
 package sleepmon
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/godbus/dbus/v5"
 	log "github.com/sirupsen/logrus"
 )
@@ -16,7 +19,8 @@ const (
 // SleepMon is a suspend/hibernate monitor
 type SleepMon struct {
 	events chan bool
-	done   chan struct{}
+	done   <-chan struct{}
+	cancel context.CancelFunc
 }
 
 // sendEvent sends sleep over the event channel
@@ -52,29 +56,16 @@ func (s *SleepMon) handleSignal(signal *dbus.Signal) {
 
 }
 
+// connectSystemBus connects to the D-Bus system bus
+var connectSystemBus = dbus.ConnectSystemBus
+
 // start starts the sleep monitor
-func (s *SleepMon) start() {
+func (s *SleepMon) start(conn *dbus.Conn) {
 	defer close(s.events)
-
-	// connect to system bus
-	conn, err := dbus.ConnectSystemBus()
-	if err != nil {
-		log.WithError(err).Error("SleepMon could not connect to system bus")
-		return
-	}
 	defer func() {
 		_ = conn.Close()
 	}()
 
-	// subscribe to login signals
-	if err = conn.AddMatchSignal(
-		dbus.WithMatchObjectPath(path),
-		dbus.WithMatchInterface(iface),
-	); err != nil {
-		log.WithError(err).Error("SleepMon could not subscribe to login signals")
-		return
-	}
-
 	// create channel for signals
 	c := make(chan *dbus.Signal, 10)
 	conn.Signal(c)
@@ -94,14 +85,33 @@ func (s *SleepMon) start() {
 	}
 }
 
-// Start starts the sleep monitor
-func (s *SleepMon) Start() {
-	go s.start()
+// Start starts the sleep monitor, it runs until Stop is called or ctx is
+// canceled
+func (s *SleepMon) Start(ctx context.Context) error {
+	// connect to system bus
+	conn, err := connectSystemBus()
+	if err != nil {
+		return fmt.Errorf("SleepMon could not connect to system bus: %w", err)
+	}
+
+	// subscribe to login signals
+	if err = conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(path),
+		dbus.WithMatchInterface(iface),
+	); err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("SleepMon could not subscribe to login signals: %w", err)
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = ctx.Done()
+	go s.start(conn)
+	return nil
 }
 
 // Stop stops the sleep monitor
 func (s *SleepMon) Stop() {
-	close(s.done)
+	s.cancel()
 	for range s.events {
 		// wait for channel termination
 	}
@@ -116,6 +126,5 @@ func (s *SleepMon) Events() chan bool {
 func NewSleepMon() *SleepMon {
 	return &SleepMon{
 		events: make(chan bool),
-		done:   make(chan struct{}),
 	}
 }
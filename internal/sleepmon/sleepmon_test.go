@@ -1,6 +1,8 @@
 package sleepmon
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/godbus/dbus/v5"
@@ -36,10 +38,28 @@ func TestSleepMonHandleSignal(t *testing.T) {
 // TestSleepMonStartStop tests Start and Stop of SleepMon
 func TestSleepMonStartStop(t *testing.T) {
 	s := NewSleepMon()
-	s.Start()
+	if err := s.Start(context.Background()); err != nil {
+		// system bus not available
+		t.Log(err)
+		return
+	}
 	s.Stop()
 }
 
+// TestSleepMonStartError tests Start of SleepMon with system bus error
+func TestSleepMonStartError(t *testing.T) {
+	old := connectSystemBus
+	defer func() { connectSystemBus = old }()
+	connectSystemBus = func(...dbus.ConnOption) (*dbus.Conn, error) {
+		return nil, errors.New("test error")
+	}
+
+	s := NewSleepMon()
+	if err := s.Start(context.Background()); err == nil {
+		t.Error("start should fail")
+	}
+}
+
 // TestSleepMonEvents tests Events of SleepMon
 func TestSleepMonEvents(t *testing.T) {
 	s := NewSleepMon()
@@ -53,9 +73,7 @@ func TestSleepMonEvents(t *testing.T) {
 // TestNewSleepMon tests NewSleepMon
 func TestNewSleepMon(t *testing.T) {
 	s := NewSleepMon()
-	if s.events == nil ||
-		s.done == nil {
-
+	if s.events == nil {
 		t.Errorf("got nil, want != nil")
 	}
 }
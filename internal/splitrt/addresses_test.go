@@ -1,7 +1,6 @@
 package splitrt
 
 import (
-	"log"
 	"net"
 	"reflect"
 	"testing"
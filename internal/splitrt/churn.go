@@ -0,0 +1,121 @@
+package splitrt
+
+import (
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/clock"
+)
+
+const (
+	// churnMaxAddresses is the number of distinct addresses of a domain
+	// within churnWindow after which its DNS-based split excludes are
+	// aggregated into covering prefixes
+	churnMaxAddresses = 16
+
+	// churnWindow is the time window for counting the distinct addresses
+	// of a domain
+	churnWindow = excludesTimer * time.Second
+
+	// churnPrefixIPv4 and churnPrefixIPv6 are the prefix lengths of the
+	// covering prefixes of aggregated addresses
+	churnPrefixIPv4 = 24
+	churnPrefixIPv6 = 64
+)
+
+// domainChurn contains the distinct addresses of a domain in the current
+// time window
+type domainChurn struct {
+	start      time.Time
+	addrs      map[string]*net.IPNet
+	aggregated bool
+}
+
+// churn protects against DNS-based split excludes with frequently changing
+// addresses, e.g., domains with short TTLs resolving to new addresses each
+// time: if a domain exceeds churnMaxAddresses distinct addresses within
+// churnWindow, its addresses are aggregated into covering prefixes
+type churn struct {
+	clock        clock.Clock
+	domains      map[string]*domainChurn
+	lastCleanup  time.Time
+	aggregations uint64
+}
+
+// coveringPrefix returns the covering prefix of address for aggregation
+func coveringPrefix(address *net.IPNet) *net.IPNet {
+	mask := net.CIDRMask(churnPrefixIPv4, 32)
+	if address.IP.To4() == nil {
+		mask = net.CIDRMask(churnPrefixIPv6, 128)
+	}
+	return &net.IPNet{
+		IP:   address.IP.Mask(mask),
+		Mask: mask,
+	}
+}
+
+// cleanup removes domains with expired time windows
+func (c *churn) cleanup(now time.Time) {
+	if now.Sub(c.lastCleanup) < churnWindow {
+		return
+	}
+	c.lastCleanup = now
+	for name, d := range c.domains {
+		if now.Sub(d.start) >= churnWindow {
+			delete(c.domains, name)
+		}
+	}
+}
+
+// add adds address of the domain name, it returns the exclude that should
+// be added for address, i.e., address itself or its covering prefix if the
+// domain is aggregated; if the domain just exceeded the threshold, it also
+// returns the previously added addresses of the domain that are replaced by
+// their covering prefixes
+func (c *churn) add(name string, address *net.IPNet) (exclude *net.IPNet, replaced []*net.IPNet) {
+	now := c.clock.Now()
+	c.cleanup(now)
+
+	d := c.domains[name]
+	if d == nil || now.Sub(d.start) >= churnWindow {
+		d = &domainChurn{
+			start: now,
+			addrs: make(map[string]*net.IPNet),
+		}
+		c.domains[name] = d
+	}
+
+	// domain already aggregated
+	if d.aggregated {
+		return coveringPrefix(address), nil
+	}
+
+	// domain below threshold
+	d.addrs[address.String()] = address
+	if len(d.addrs) <= churnMaxAddresses {
+		return address, nil
+	}
+
+	// domain exceeded threshold, aggregate its addresses
+	atomic.AddUint64(&c.aggregations, 1)
+	d.aggregated = true
+	for _, a := range d.addrs {
+		replaced = append(replaced, a)
+	}
+	d.addrs = nil
+	return coveringPrefix(address), replaced
+}
+
+// getAggregations returns the number of aggregation events
+func (c *churn) getAggregations() uint64 {
+	return atomic.LoadUint64(&c.aggregations)
+}
+
+// newChurn returns a new churn
+func newChurn() *churn {
+	return &churn{
+		clock:   clock.New(),
+		domains: make(map[string]*domainChurn),
+	}
+}
@@ -0,0 +1,79 @@
+package splitrt
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/clock"
+)
+
+// TestCoveringPrefix tests coveringPrefix
+func TestCoveringPrefix(t *testing.T) {
+	for address, want := range map[string]string{
+		"192.168.1.1/32":  "192.168.1.0/24",
+		"2001:db8::1/128": "2001:db8::/64",
+	} {
+		_, a, err := net.ParseCIDR(address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := coveringPrefix(a).String(); got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+}
+
+// TestChurnAdd tests add of churn
+func TestChurnAdd(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	c := newChurn()
+	c.clock = fake
+
+	address := func(i int) *net.IPNet {
+		return &net.IPNet{
+			IP:   net.ParseIP(fmt.Sprintf("192.168.%d.1", i)).To4(),
+			Mask: net.CIDRMask(32, 32),
+		}
+	}
+
+	// addresses below threshold, also on other domain
+	for i := 0; i < churnMaxAddresses; i++ {
+		exclude, replaced := c.add("example.com", address(i))
+		if exclude.String() != address(i).String() || replaced != nil {
+			t.Errorf("got %s %v, want %s", exclude, replaced, address(i))
+		}
+		c.add("other.example.com", address(i))
+	}
+
+	// same address again
+	if exclude, replaced := c.add("example.com", address(0)); exclude.String() != "192.168.0.1/32" || replaced != nil {
+		t.Errorf("got %s %v", exclude, replaced)
+	}
+
+	// threshold exceeded
+	exclude, replaced := c.add("example.com", address(churnMaxAddresses))
+	if exclude.String() != "192.168.16.0/24" || len(replaced) != churnMaxAddresses+1 {
+		t.Errorf("got %s %v", exclude, replaced)
+	}
+	if c.getAggregations() != 1 {
+		t.Errorf("got %d aggregations, want 1", c.getAggregations())
+	}
+
+	// domain aggregated
+	exclude, replaced = c.add("example.com", address(100))
+	if exclude.String() != "192.168.100.0/24" || replaced != nil {
+		t.Errorf("got %s %v", exclude, replaced)
+	}
+
+	// time window expired
+	fake.Advance(churnWindow)
+	exclude, replaced = c.add("example.com", address(101))
+	if exclude.String() != "192.168.101.1/32" || replaced != nil {
+		t.Errorf("got %s %v", exclude, replaced)
+	}
+	if len(c.domains) != 1 {
+		t.Errorf("got %d domains, want 1", len(c.domains))
+	}
+}
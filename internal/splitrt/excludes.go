@@ -1,11 +1,14 @@
 package splitrt
 
 import (
+	"context"
 	"net"
+	"sort"
 	"sync"
 	"time"
 
-	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/clock"
 )
 
 const (
@@ -17,6 +20,8 @@ const (
 type exclude struct {
 	net     *net.IPNet
 	static  bool
+	runtime bool
+	gateway bool
 	ttl     uint32
 	updated bool
 }
@@ -24,8 +29,10 @@ type exclude struct {
 // Excludes contains split Excludes
 type Excludes struct {
 	sync.Mutex
+	clock  clock.Clock
 	m      map[string]*exclude
-	done   chan struct{}
+	done   <-chan struct{}
+	cancel context.CancelFunc
 	closed chan struct{}
 }
 
@@ -81,9 +88,62 @@ func (e *Excludes) AddStatic(address *net.IPNet) {
 	})
 }
 
+// AddGateway adds a static entry for an address of the VPN gateway to the
+// split excludes, gateway entries are never removed while split routing is
+// running, so traffic to the gateway never enters the tunnel; it returns
+// false if address is already a gateway entry
+func (e *Excludes) AddGateway(address *net.IPNet) bool {
+	log.WithField("address", address).Debug("SplitRouting adding gateway exclude")
+
+	e.Lock()
+	defer e.Unlock()
+
+	key := address.String()
+	old := e.m[key]
+	if old == nil {
+		exclude := &exclude{
+			net:     address,
+			static:  true,
+			gateway: true,
+		}
+		e.m[key] = exclude
+		e.addFilter(exclude)
+		return true
+	}
+	if old.gateway {
+		return false
+	}
+
+	// existing entry becomes gateway entry
+	old.static = true
+	old.gateway = true
+	return true
+}
+
+// Gateways returns the gateway entries of the split excludes sorted by
+// address
+func (e *Excludes) Gateways() []*net.IPNet {
+	e.Lock()
+	defer e.Unlock()
+
+	keys := []string{}
+	for k, v := range e.m {
+		if v.gateway {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	addresses := []*net.IPNet{}
+	for _, k := range keys {
+		addresses = append(addresses, e.m[k].net)
+	}
+	return addresses
+}
+
 // AddDynamic adds a dynamic entry to the split excludes
 func (e *Excludes) AddDynamic(address *net.IPNet, ttl uint32) {
-	log.WithFields(log.Fields{
+	log.WithFields(logrus.Fields{
 		"address": address,
 		"ttl":     ttl,
 	}).Debug("SplitRouting adding dynamic exclude")
@@ -94,12 +154,128 @@ func (e *Excludes) AddDynamic(address *net.IPNet, ttl uint32) {
 	})
 }
 
-// Remove removes an entry from the split excludes
+// AggregateDynamic replaces the dynamic entries of addresses in the split
+// excludes with dynamic entries of the covering prefixes
+func (e *Excludes) AggregateDynamic(addresses, prefixes []*net.IPNet, ttl uint32) {
+	log.WithFields(logrus.Fields{
+		"addresses": addresses,
+		"prefixes":  prefixes,
+		"ttl":       ttl,
+	}).Debug("SplitRouting aggregating dynamic excludes")
+
+	e.Lock()
+	defer e.Unlock()
+
+	for _, a := range addresses {
+		key := a.String()
+		if old := e.m[key]; old != nil && !old.static {
+			delete(e.m, key)
+		}
+	}
+	for _, p := range prefixes {
+		key := p.String()
+		if old := e.m[key]; old != nil {
+			if !old.static {
+				old.ttl = ttl
+				old.updated = true
+			}
+			continue
+		}
+		e.m[key] = &exclude{
+			net:     p,
+			ttl:     ttl,
+			updated: true,
+		}
+	}
+	e.setFilter()
+}
+
+// AddRuntime adds a static entry to the split excludes at runtime, e.g., by
+// an administrator over D-Bus; it returns false if a static entry for the
+// address already exists
+func (e *Excludes) AddRuntime(address *net.IPNet) bool {
+	log.WithField("address", address).Debug("SplitRouting adding runtime exclude")
+
+	e.Lock()
+	defer e.Unlock()
+
+	key := address.String()
+	old := e.m[key]
+	if old == nil {
+		exclude := &exclude{
+			net:     address,
+			static:  true,
+			runtime: true,
+		}
+		e.m[key] = exclude
+		e.addFilter(exclude)
+		return true
+	}
+	if old.static {
+		return false
+	}
+
+	// dynamic entry becomes runtime entry
+	old.static = true
+	old.runtime = true
+	return true
+}
+
+// RemoveRuntime removes a runtime entry from the split excludes, it returns
+// false if there is no runtime entry for the address
+func (e *Excludes) RemoveRuntime(address *net.IPNet) bool {
+	log.WithField("address", address).Debug("SplitRouting removing runtime exclude")
+
+	e.Lock()
+	defer e.Unlock()
+
+	key := address.String()
+	old := e.m[key]
+	if old == nil || !old.runtime {
+		return false
+	}
+	if old.gateway {
+		// keep gateway entry
+		old.runtime = false
+		return true
+	}
+	delete(e.m, key)
+	e.setFilter()
+	return true
+}
+
+// Runtime returns the runtime entries of the split excludes sorted by
+// address
+func (e *Excludes) Runtime() []*net.IPNet {
+	e.Lock()
+	defer e.Unlock()
+
+	keys := []string{}
+	for k, v := range e.m {
+		if v.runtime {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	addresses := []*net.IPNet{}
+	for _, k := range keys {
+		addresses = append(addresses, e.m[k].net)
+	}
+	return addresses
+}
+
+// Remove removes an entry from the split excludes, gateway entries are not
+// removed
 func (e *Excludes) Remove(address *net.IPNet) {
 	e.Lock()
 	defer e.Unlock()
 
-	delete(e.m, address.String())
+	key := address.String()
+	if old := e.m[key]; old != nil && old.gateway {
+		return
+	}
+	delete(e.m, key)
 	e.setFilter()
 }
 
@@ -142,31 +318,35 @@ func (e *Excludes) cleanup() {
 func (e *Excludes) start() {
 	defer close(e.closed)
 
-	timer := time.NewTimer(excludesTimer * time.Second)
+	timer := e.clock.NewTimer(excludesTimer * time.Second)
 	for {
 		select {
-		case <-timer.C:
+		case <-timer.C():
 			e.cleanup()
 			timer.Reset(excludesTimer * time.Second)
 
 		case <-e.done:
 			if !timer.Stop() {
-				<-timer.C
+				<-timer.C()
 			}
 			return
 		}
 	}
 }
 
-// Start starts periodic cleanup of the split excludes
-func (e *Excludes) Start() {
+// Start starts periodic cleanup of the split excludes, it runs until Stop is
+// called or ctx is canceled
+func (e *Excludes) Start(ctx context.Context) error {
 	log.Debug("SplitRouting starting periodic cleanup of excludes")
+	ctx, e.cancel = context.WithCancel(ctx)
+	e.done = ctx.Done()
 	go e.start()
+	return nil
 }
 
 // Stop stops periodic cleanup of the split excludes
 func (e *Excludes) Stop() {
-	close(e.done)
+	e.cancel()
 	<-e.closed
 	log.Debug("SplitRouting stopped periodic cleanup of excludes")
 }
@@ -174,8 +354,8 @@ func (e *Excludes) Stop() {
 // NewExcludes returns new split excludes
 func NewExcludes() *Excludes {
 	return &Excludes{
+		clock:  clock.New(),
 		m:      make(map[string]*exclude),
-		done:   make(chan struct{}),
 		closed: make(chan struct{}),
 	}
 }
@@ -1,10 +1,14 @@
 package splitrt
 
 import (
-	"log"
+	"context"
 	"net"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/internal/clock"
 )
 
 // getTestExcludes returns excludes for testing
@@ -147,6 +151,145 @@ func TestExcludesRemove(t *testing.T) {
 	}
 }
 
+// TestExcludesRuntime tests AddRuntime, RemoveRuntime and Runtime of Excludes
+func TestExcludesRuntime(t *testing.T) {
+	e := NewExcludes()
+	excludes := getTestExcludes()
+
+	// set testing runNft function
+	got := []string{}
+	runNft = func(s string) {
+		got = append(got, s)
+	}
+
+	// test adding runtime excludes, static and dynamic entries
+	_, static, _ := net.ParseCIDR("10.0.0.0/8")
+	_, dynamic, _ := net.ParseCIDR("172.16.0.0/12")
+	e.AddStatic(static)
+	e.AddDynamic(dynamic, 300)
+	for _, exclude := range excludes {
+		if !e.AddRuntime(exclude) {
+			t.Errorf("%s: runtime exclude not added", exclude)
+		}
+	}
+	if e.AddRuntime(static) {
+		t.Error("static exclude should not be added as runtime exclude")
+	}
+	if !e.AddRuntime(dynamic) {
+		t.Error("dynamic exclude should be added as runtime exclude")
+	}
+	want := []*net.IPNet{dynamic, excludes[0], excludes[1]}
+	if got := e.Runtime(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// test cleanup, runtime excludes are not removed
+	e.cleanup()
+	e.cleanup()
+	if got := e.Runtime(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// test removing runtime excludes
+	got = []string{}
+	if e.RemoveRuntime(static) {
+		t.Error("static exclude should not be removed")
+	}
+	if !e.RemoveRuntime(excludes[0]) {
+		t.Error("runtime exclude should be removed")
+	}
+	if e.RemoveRuntime(excludes[0]) {
+		t.Error("removed runtime exclude should not be removed again")
+	}
+	if len(got) != 1 ||
+		strings.Contains(got[0], "192.168.1.0/24") ||
+		!strings.Contains(got[0], "2001::/64") {
+		t.Errorf("got %v, want reset of excludes without removed exclude", got)
+	}
+	want = []*net.IPNet{dynamic, excludes[1]}
+	if got := e.Runtime(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestExcludesGateway tests AddGateway and Gateways of Excludes
+func TestExcludesGateway(t *testing.T) {
+	e := NewExcludes()
+
+	// set testing runNft function
+	runNft = func(string) {}
+
+	// test adding gateway excludes, also over existing entries
+	_, gw1, _ := net.ParseCIDR("192.168.1.1/32")
+	_, gw2, _ := net.ParseCIDR("2001::1/128")
+	_, gw3, _ := net.ParseCIDR("10.0.0.1/32")
+	e.AddDynamic(gw2, 10)
+	e.AddRuntime(gw3)
+	for _, gw := range []*net.IPNet{gw1, gw2, gw3} {
+		if !e.AddGateway(gw) {
+			t.Errorf("%s: gateway exclude not added", gw)
+		}
+		if e.AddGateway(gw) {
+			t.Errorf("%s: gateway exclude added twice", gw)
+		}
+	}
+	want := []*net.IPNet{gw3, gw1, gw2}
+	if got := e.Gateways(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// test that gateway excludes are not removed
+	e.cleanup()
+	e.cleanup()
+	e.Remove(gw1)
+	e.AggregateDynamic([]*net.IPNet{gw2}, nil, 300)
+	if !e.RemoveRuntime(gw3) {
+		t.Error("runtime exclude should be removed")
+	}
+	if got := e.Gateways(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := e.Runtime(); len(got) != 0 {
+		t.Errorf("got %v, want no runtime excludes", got)
+	}
+}
+
+// TestExcludesAggregateDynamic tests AggregateDynamic of Excludes
+func TestExcludesAggregateDynamic(t *testing.T) {
+	e := NewExcludes()
+
+	got := []string{}
+	runNft = func(s string) {
+		got = append(got, s)
+	}
+
+	// add static and dynamic excludes
+	_, static, _ := net.ParseCIDR("192.168.1.1/32")
+	_, dynamic, _ := net.ParseCIDR("192.168.1.2/32")
+	_, prefix, _ := net.ParseCIDR("192.168.1.0/24")
+	e.AddStatic(static)
+	e.AddDynamic(dynamic, 300)
+
+	// aggregate, static exclude is kept
+	got = []string{}
+	e.AggregateDynamic([]*net.IPNet{static, dynamic}, []*net.IPNet{prefix}, 30)
+	if len(got) != 1 ||
+		!strings.Contains(got[0], "{ 192.168.1.1/32 }") ||
+		!strings.Contains(got[0], "{ 192.168.1.0/24 }") ||
+		strings.Contains(got[0], "192.168.1.2/32") {
+		t.Errorf("got %v", got)
+	}
+	if e.m[prefix.String()].static || e.m[prefix.String()].ttl != 30 {
+		t.Errorf("got %v", e.m[prefix.String()])
+	}
+
+	// aggregate again, updates ttl
+	e.AggregateDynamic(nil, []*net.IPNet{prefix}, 60)
+	if e.m[prefix.String()].ttl != 60 {
+		t.Errorf("got %v", e.m[prefix.String()])
+	}
+}
+
 // TestExcludesCleanup tests cleanup of Excludes
 func TestExcludesCleanup(t *testing.T) {
 	e := NewExcludes()
@@ -199,10 +342,47 @@ func TestExcludesCleanup(t *testing.T) {
 	}
 }
 
+// TestExcludesPeriodicCleanup tests periodic cleanup of Excludes
+func TestExcludesPeriodicCleanup(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	e := NewExcludes()
+	e.clock = fake
+
+	// set testing runNft function
+	flushed := make(chan struct{}, 10)
+	runNft = func(s string) {
+		if strings.HasPrefix(s, "flush") {
+			flushed <- struct{}{}
+		}
+	}
+
+	// add expired dynamic excludes
+	for _, exclude := range getTestExcludes() {
+		e.AddDynamic(exclude, 0)
+	}
+
+	// advance clock until cleanup removes expired excludes
+	if err := e.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Stop()
+	for i := 0; i < 100; i++ {
+		fake.Advance(excludesTimer * time.Second)
+		select {
+		case <-flushed:
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	t.Errorf("expired excludes not cleaned up")
+}
+
 // TestExcludesStartStop tests Start and Stop of Excludes
 func TestExcludesStartStop(t *testing.T) {
 	e := NewExcludes()
-	e.Start()
+	if err := e.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
 	e.Stop()
 }
 
@@ -210,7 +390,6 @@ func TestExcludesStartStop(t *testing.T) {
 func TestNewExcludes(t *testing.T) {
 	e := NewExcludes()
 	if e.m == nil ||
-		e.done == nil ||
 		e.closed == nil {
 
 		t.Errorf("got nil, want != nil")
@@ -7,7 +7,7 @@ import (
 	"os/exec"
 	"strings"
 
-	log "github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/manifest"
 )
 
 const (
@@ -118,12 +118,14 @@ table inet oc-daemon-routing {
 `
 	r := strings.NewReplacer("$FWMARK", FWMark)
 	rules := r.Replace(routeRules)
+	manifest.Add(manifest.KindNftTable, "inet oc-daemon-routing")
 	runNft(rules)
 }
 
 // unsetRoutingRules removes the nftables rules for routing
 func unsetRoutingRules() {
 	runNft("delete table inet oc-daemon-routing")
+	manifest.Remove(manifest.KindNftTable, "inet oc-daemon-routing")
 }
 
 // addLocalAddresses adds rules for device and its family (ip, ip6) addresses,
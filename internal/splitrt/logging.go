@@ -0,0 +1,6 @@
+package splitrt
+
+import "github.com/telekom-mms/oc-daemon/internal/logging"
+
+// log is the logger of the split routing
+var log = logging.Component(logging.ComponentSplitRt)
@@ -0,0 +1,227 @@
+package splitrt
+
+import (
+	"bytes"
+	"net"
+	"sort"
+
+	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
+)
+
+// Route actions of the effective split routing decisions
+const (
+	// ActionTunnel routes traffic over the tunnel
+	ActionTunnel = "tunnel"
+
+	// ActionBypass routes traffic outside the tunnel
+	ActionBypass = "bypass"
+)
+
+// Decision is the effective split routing decision for a prefix in the
+// split includes and excludes
+type Decision struct {
+	// Prefix is the configured prefix
+	Prefix *net.IPNet
+
+	// Action is the action for traffic to Prefix
+	Action string
+
+	// Parent is the longest configured prefix containing Prefix, nil if
+	// Prefix is only contained in the default route over the tunnel
+	Parent *net.IPNet
+
+	// ParentAction is the action of Parent or of the default route
+	ParentAction string
+}
+
+// Overrides returns whether the decision overrides the action of its parent
+func (d *Decision) Overrides() bool {
+	return d.Action != d.ParentAction
+}
+
+// rule is a configured split include or exclude
+type rule struct {
+	prefix *net.IPNet
+	action string
+}
+
+// Resolver resolves conflicts between split includes and excludes with
+// longest prefix match: traffic is handled according to the most specific
+// configured prefix, an include and an exclude of the same prefix resolve
+// to the include; traffic not matching any prefix is routed over the
+// tunnel
+type Resolver struct {
+	rules []*rule
+}
+
+// contains returns whether network a contains network b
+func contains(a, b *net.IPNet) bool {
+	aOnes, aBits := a.Mask.Size()
+	bOnes, bBits := b.Mask.Size()
+	return aBits == bBits && aOnes <= bOnes && a.Contains(b.IP)
+}
+
+// halves splits network n into its two halves
+func halves(n *net.IPNet) (*net.IPNet, *net.IPNet) {
+	ones, bits := n.Mask.Size()
+	mask := net.CIDRMask(ones+1, bits)
+	low := n.IP.Mask(n.Mask)
+	high := append(net.IP{}, low...)
+	high[ones/8] |= 0x80 >> (ones % 8)
+	return &net.IPNet{IP: low, Mask: mask}, &net.IPNet{IP: high, Mask: mask}
+}
+
+// subtract returns network n without the networks in subs as list of
+// non-overlapping networks
+func subtract(n *net.IPNet, subs []*net.IPNet) []*net.IPNet {
+	overlap := false
+	for _, s := range subs {
+		if contains(s, n) {
+			return nil
+		}
+		if contains(n, s) {
+			overlap = true
+		}
+	}
+	if !overlap {
+		return []*net.IPNet{n}
+	}
+	low, high := halves(n)
+	return append(subtract(low, subs), subtract(high, subs)...)
+}
+
+// parent returns the rule with the longest prefix strictly containing the
+// prefix of r, nil if there is none
+func (r *Resolver) parent(c *rule) *rule {
+	var p *rule
+	for _, o := range r.rules {
+		if o == c || !contains(o.prefix, c.prefix) {
+			continue
+		}
+		if p == nil || contains(p.prefix, o.prefix) {
+			p = o
+		}
+	}
+	return p
+}
+
+// Decisions returns the effective decisions for all configured prefixes
+func (r *Resolver) Decisions() []*Decision {
+	decisions := []*Decision{}
+	for _, c := range r.rules {
+		d := &Decision{
+			Prefix:       c.prefix,
+			Action:       c.action,
+			ParentAction: ActionTunnel,
+		}
+		if p := r.parent(c); p != nil {
+			d.Parent = p.prefix
+			d.ParentAction = p.action
+		}
+		decisions = append(decisions, d)
+	}
+	return decisions
+}
+
+// Lookup returns the effective action for traffic to ip
+func (r *Resolver) Lookup(ip net.IP) string {
+	action := ActionTunnel
+	longest := -1
+	for _, c := range r.rules {
+		ones, _ := c.prefix.Mask.Size()
+		if ones > longest && c.prefix.Contains(ip) {
+			action = c.action
+			longest = ones
+		}
+	}
+	return action
+}
+
+// Excludes returns the effective split excludes as non-overlapping
+// networks, i.e., the configured excludes without the more specific
+// includes nested in them
+func (r *Resolver) Excludes() []*net.IPNet {
+	excludes := []*net.IPNet{}
+	for _, c := range r.rules {
+		if c.action != ActionBypass {
+			continue
+		}
+		nested := []*net.IPNet{}
+		for _, o := range r.rules {
+			if o != c && contains(c.prefix, o.prefix) {
+				nested = append(nested, o.prefix)
+			}
+		}
+		excludes = append(excludes, subtract(c.prefix, nested)...)
+	}
+	return excludes
+}
+
+// add adds prefix with action to the rules of the resolver, an existing
+// exclude of the same prefix is replaced by an include
+func (r *Resolver) add(prefix *net.IPNet, action string) {
+	prefix = &net.IPNet{
+		IP:   prefix.IP.Mask(prefix.Mask),
+		Mask: prefix.Mask,
+	}
+	if prefix.IP == nil {
+		// invalid prefix
+		return
+	}
+	for _, c := range r.rules {
+		if c.prefix.String() == prefix.String() {
+			if action == ActionTunnel {
+				c.action = action
+			}
+			return
+		}
+	}
+	r.rules = append(r.rules, &rule{prefix: prefix, action: action})
+}
+
+// NewResolver returns a new Resolver for the split includes and excludes
+func NewResolver(includes, excludes []*net.IPNet) *Resolver {
+	r := &Resolver{}
+	for _, i := range includes {
+		r.add(i, ActionTunnel)
+	}
+	for _, e := range excludes {
+		r.add(e, ActionBypass)
+	}
+
+	// sort rules by address family, address and prefix length
+	sort.Slice(r.rules, func(i, j int) bool {
+		a, b := r.rules[i].prefix, r.rules[j].prefix
+		if len(a.IP) != len(b.IP) {
+			return len(a.IP) < len(b.IP)
+		}
+		if c := bytes.Compare(a.IP, b.IP); c != 0 {
+			return c < 0
+		}
+		aOnes, _ := a.Mask.Size()
+		bOnes, _ := b.Mask.Size()
+		return aOnes < bOnes
+	})
+	return r
+}
+
+// NewConfigResolver returns a new Resolver for the split includes and
+// excludes in config, the local network excludes 0.0.0.0/32 and ::/128 are
+// ignored
+func NewConfigResolver(config *vpnconfig.Config) *Resolver {
+	includes := append(config.Split.IncludeIPv4[:0:0],
+		config.Split.IncludeIPv4...)
+	includes = append(includes, config.Split.IncludeIPv6...)
+	excludes := []*net.IPNet{}
+	for _, e := range config.Split.ExcludeIPv4 {
+		if e.String() != "0.0.0.0/32" {
+			excludes = append(excludes, e)
+		}
+	}
+	for _, e := range config.Split.ExcludeIPv6 {
+		if e.String() != "::/128" {
+			excludes = append(excludes, e)
+		}
+	}
+	return NewResolver(includes, excludes)
+}
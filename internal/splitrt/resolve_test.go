@@ -0,0 +1,135 @@
+package splitrt
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
+)
+
+// parseNets parses the networks in cidrs
+func parseNets(t *testing.T, cidrs ...string) []*net.IPNet {
+	nets := []*net.IPNet{}
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+// netStrings returns nets as strings
+func netStrings(nets []*net.IPNet) []string {
+	s := []string{}
+	for _, n := range nets {
+		s = append(s, n.String())
+	}
+	return s
+}
+
+// TestResolverDecisions tests Decisions of Resolver
+func TestResolverDecisions(t *testing.T) {
+	r := NewResolver(
+		parseNets(t, "10.0.0.0/8", "10.1.2.0/24", "2001:db8::/32"),
+		parseNets(t, "10.1.0.0/16", "192.168.0.0/16", "10.0.0.0/8"),
+	)
+
+	type decision struct {
+		prefix, action, parent, parentAction string
+	}
+	want := []decision{
+		{"10.0.0.0/8", ActionTunnel, "<nil>", ActionTunnel},
+		{"10.1.0.0/16", ActionBypass, "10.0.0.0/8", ActionTunnel},
+		{"10.1.2.0/24", ActionTunnel, "10.1.0.0/16", ActionBypass},
+		{"192.168.0.0/16", ActionBypass, "<nil>", ActionTunnel},
+		{"2001:db8::/32", ActionTunnel, "<nil>", ActionTunnel},
+	}
+	got := []decision{}
+	for _, d := range r.Decisions() {
+		got = append(got, decision{d.Prefix.String(), d.Action,
+			d.Parent.String(), d.ParentAction})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// overrides
+	for i, d := range r.Decisions() {
+		if d.Overrides() != (i == 1 || i == 2 || i == 3) {
+			t.Errorf("%s: unexpected overrides %t", d.Prefix, d.Overrides())
+		}
+	}
+}
+
+// TestResolverLookup tests Lookup of Resolver
+func TestResolverLookup(t *testing.T) {
+	r := NewResolver(
+		parseNets(t, "10.1.2.0/24"),
+		parseNets(t, "10.0.0.0/8", "10.1.2.128/25", "2001:db8::/32"),
+	)
+	for ip, want := range map[string]string{
+		"192.168.1.1":     ActionTunnel,
+		"10.0.0.1":        ActionBypass,
+		"10.1.2.1":        ActionTunnel,
+		"10.1.2.200":      ActionBypass,
+		"2001:db8::1":     ActionBypass,
+		"2001:db9::1":     ActionTunnel,
+		"::ffff:10.0.0.1": ActionBypass,
+	} {
+		if got := r.Lookup(net.ParseIP(ip)); got != want {
+			t.Errorf("%s: got %s, want %s", ip, got, want)
+		}
+	}
+}
+
+// TestResolverExcludes tests Excludes of Resolver
+func TestResolverExcludes(t *testing.T) {
+	// no conflicts
+	r := NewResolver(nil, parseNets(t, "10.0.0.0/8", "2001:db8::/32"))
+	want := []string{"10.0.0.0/8", "2001:db8::/32"}
+	if got := netStrings(r.Excludes()); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// include inside exclude
+	r = NewResolver(parseNets(t, "10.0.0.0/10"), parseNets(t, "10.0.0.0/8"))
+	want = []string{"10.64.0.0/10", "10.128.0.0/9"}
+	if got := netStrings(r.Excludes()); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// exclude inside include inside exclude
+	r = NewResolver(parseNets(t, "10.0.0.0/9"),
+		parseNets(t, "10.0.0.0/8", "10.0.0.0/10"))
+	want = []string{"10.128.0.0/9", "10.0.0.0/10"}
+	if got := netStrings(r.Excludes()); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// same prefix in include and exclude
+	r = NewResolver(parseNets(t, "10.0.0.0/8"), parseNets(t, "10.0.0.0/8"))
+	want = []string{}
+	if got := netStrings(r.Excludes()); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestNewConfigResolver tests NewConfigResolver
+func TestNewConfigResolver(t *testing.T) {
+	c := vpnconfig.New()
+	c.Split.IncludeIPv4 = parseNets(t, "10.1.0.0/16")
+	c.Split.ExcludeIPv4 = parseNets(t, "0.0.0.0/32", "10.0.0.0/8")
+	c.Split.ExcludeIPv6 = parseNets(t, "::/128", "2001:db8::/32")
+
+	want := []string{"10.0.0.0/8", "10.1.0.0/16", "2001:db8::/32"}
+	got := []string{}
+	for _, d := range NewConfigResolver(c).Decisions() {
+		got = append(got, d.Prefix.String())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"os/exec"
 
-	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus"
 )
 
 const (
@@ -21,7 +21,7 @@ var runCmd = func(cmd string) {
 	log.WithField("command", cmd).Debug("Daemon executing command")
 	c := exec.Command("bash", "-c", cmd)
 	if err := c.Run(); err != nil {
-		log.WithFields(log.Fields{
+		log.WithFields(logrus.Fields{
 			"command": cmd,
 			"error":   err,
 		}).Error("Daemon command execution error")
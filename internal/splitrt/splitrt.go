@@ -1,12 +1,14 @@
 package splitrt
 
 import (
+	"context"
 	"net"
 
-	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus"
 	"github.com/telekom-mms/oc-daemon/internal/addrmon"
 	"github.com/telekom-mms/oc-daemon/internal/devmon"
 	"github.com/telekom-mms/oc-daemon/internal/dnsproxy"
+	"github.com/telekom-mms/oc-daemon/internal/manifest"
 	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
 )
 
@@ -19,13 +21,15 @@ type SplitRouting struct {
 	addrs    *Addresses
 	locals   []*net.IPNet
 	excludes *Excludes
+	churn    *churn
 	dnsreps  chan *dnsproxy.Report
-	done     chan struct{}
+	done     <-chan struct{}
+	cancel   context.CancelFunc
 	closed   chan struct{}
 }
 
 // setupRouting sets up routing using config
-func (s *SplitRouting) setupRouting() {
+func (s *SplitRouting) setupRouting(ctx context.Context) error {
 	// get vpn network addresses
 	ipnet4 := &net.IPNet{
 		IP:   s.config.IPv4.Address,
@@ -52,59 +56,86 @@ func (s *SplitRouting) setupRouting() {
 	}
 
 	// add excludes
-	s.excludes.Start()
-
-	// add gateway to static excludes
-	gateway := &net.IPNet{
-		IP:   s.config.Gateway,
-		Mask: net.CIDRMask(32, 32),
-	}
-	if gateway.IP.To4() == nil {
-		gateway.Mask = net.CIDRMask(128, 128)
+	if err := s.excludes.Start(ctx); err != nil {
+		unsetRoutingRules()
+		return err
 	}
-	s.excludes.AddStatic(gateway)
 
-	// add static IPv4 excludes
-	for _, e := range s.config.Split.ExcludeIPv4 {
-		if e.String() == "0.0.0.0/32" {
+	// add gateway to gateway excludes
+	s.AddGateway(s.config.Gateway)
+
+	// resolve conflicts between split includes and excludes and add
+	// effective static excludes
+	resolver := NewConfigResolver(s.config)
+	for _, d := range resolver.Decisions() {
+		l := log.WithFields(logrus.Fields{
+			"prefix":       d.Prefix,
+			"action":       d.Action,
+			"parent":       d.Parent,
+			"parentAction": d.ParentAction,
+		})
+		if d.Overrides() {
+			l.Info("SplitRouting effective decision overrides parent prefix")
 			continue
 		}
-		s.excludes.AddStatic(e)
+		l.Debug("SplitRouting effective decision")
 	}
-
-	// add static IPv6 excludes
-	for _, e := range s.config.Split.ExcludeIPv6 {
-		// TODO: does ::/128 exist?
-		if e.String() == "::/128" {
-			continue
-		}
+	for _, e := range resolver.Excludes() {
 		s.excludes.AddStatic(e)
 	}
 
-	// setup routing
+	// setup routing, record it for cleanups
 	// TODO: add netlink variant?
+	manifest.Add(manifest.KindRouteTable, rtTable)
+	manifest.Add(manifest.KindRule, rulePref1)
+	manifest.Add(manifest.KindRule, rulePref2)
 	addDefaultRouteIPv4(s.config.Device.Name)
 	addDefaultRouteIPv6(s.config.Device.Name)
 
+	return nil
 }
 
 // teardownRouting tears down the routing configuration
 func (s *SplitRouting) teardownRouting() {
 	deleteDefaultRouteIPv4(s.config.Device.Name)
 	deleteDefaultRouteIPv6(s.config.Device.Name)
+	manifest.Remove(manifest.KindRule, rulePref2)
+	manifest.Remove(manifest.KindRule, rulePref1)
+	manifest.Remove(manifest.KindRouteTable, rtTable)
 	unsetRoutingRules()
 
 	// remove excludes
 	s.excludes.Stop()
 }
 
-// excludeSettings returns if local (virtual) networks should be excluded
+// hostPrefix returns the host prefix of ip, i.e., ip/32 or ip/128
+func hostPrefix(ip net.IP) *net.IPNet {
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{
+			IP:   ip4,
+			Mask: net.CIDRMask(32, 32),
+		}
+	}
+	return &net.IPNet{
+		IP:   ip,
+		Mask: net.CIDRMask(128, 128),
+	}
+}
+
+// excludeSettings returns if local (virtual) networks should be excluded,
+// the exclude 0.0.0.0/32 or ::/128 enables local network excludes, so they
+// also work on IPv6-only hosts and VPNs
 func (s *SplitRouting) excludeLocalNetworks() (exclude bool, virtual bool) {
 	for _, e := range s.config.Split.ExcludeIPv4 {
 		if e.String() == "0.0.0.0/32" {
 			exclude = true
 		}
 	}
+	for _, e := range s.config.Split.ExcludeIPv6 {
+		if e.String() == "::/128" {
+			exclude = true
+		}
+	}
 	if s.config.Split.ExcludeVirtualSubnetsOnlyIPv4 {
 		virtual = true
 	}
@@ -200,34 +231,41 @@ func (s *SplitRouting) handleDNSReport(r *dnsproxy.Report) {
 	defer r.Done()
 	log.WithField("report", r).Debug("SplitRouting handling DNS report")
 
-	if r.IP.To4() != nil {
-		s.excludes.AddDynamic(&net.IPNet{
-			IP:   r.IP,
-			Mask: net.CIDRMask(32, 32),
-		}, r.TTL)
+	address := hostPrefix(r.IP)
+
+	// aggregate addresses of domains with route churn
+	exclude, replaced := s.churn.add(r.Name, address)
+	if replaced == nil {
+		s.excludes.AddDynamic(exclude, r.TTL)
 		return
 	}
-	s.excludes.AddDynamic(&net.IPNet{
-		IP:   r.IP,
-		Mask: net.CIDRMask(128, 128),
-	}, r.TTL)
+	prefixes := []*net.IPNet{exclude}
+	seen := map[string]bool{exclude.String(): true}
+	for _, a := range replaced {
+		p := coveringPrefix(a)
+		if !seen[p.String()] {
+			seen[p.String()] = true
+			prefixes = append(prefixes, p)
+		}
+	}
+	log.WithFields(logrus.Fields{
+		"domain":   r.Name,
+		"prefixes": prefixes,
+	}).Info("SplitRouting aggregating DNS-based excludes of domain with route churn")
+	s.excludes.AggregateDynamic(replaced, prefixes, r.TTL)
+}
+
+// Aggregations returns the number of domains whose DNS-based split excludes
+// were aggregated into covering prefixes because of route churn
+func (s *SplitRouting) Aggregations() uint64 {
+	return s.churn.getAggregations()
 }
 
 // start starts split routing
 func (s *SplitRouting) start() {
-	log.Debug("SplitRouting starting")
 	defer close(s.closed)
-
-	// configure routing
-	s.setupRouting()
 	defer s.teardownRouting()
-
-	// start device monitor
-	s.devmon.Start()
 	defer s.devmon.Stop()
-
-	// start address monitor
-	s.addrmon.Start()
 	defer s.addrmon.Stop()
 
 	// main loop
@@ -245,18 +283,79 @@ func (s *SplitRouting) start() {
 	}
 }
 
-// Start starts split routing
-func (s *SplitRouting) Start() {
+// Start starts split routing, it runs until Stop is called or ctx is canceled
+func (s *SplitRouting) Start(ctx context.Context) error {
+	log.Debug("SplitRouting starting")
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = ctx.Done()
+
+	// configure routing
+	if err := s.setupRouting(ctx); err != nil {
+		s.cancel()
+		return err
+	}
+
+	// start device monitor
+	if err := s.devmon.Start(ctx); err != nil {
+		s.teardownRouting()
+		s.cancel()
+		return err
+	}
+
+	// start address monitor
+	if err := s.addrmon.Start(ctx); err != nil {
+		s.devmon.Stop()
+		s.teardownRouting()
+		s.cancel()
+		return err
+	}
+
 	go s.start()
+	return nil
 }
 
 // Stop stops split routing
 func (s *SplitRouting) Stop() {
-	close(s.done)
+	s.cancel()
 	<-s.closed
 	log.Debug("SplitRouting stopped")
 }
 
+// AddExclude adds address to the split excludes while split routing is
+// running, it returns false if address is already a static exclude
+func (s *SplitRouting) AddExclude(address *net.IPNet) bool {
+	return s.excludes.AddRuntime(address)
+}
+
+// RemoveExclude removes address added with AddExclude from the split
+// excludes, it returns false if address was not added with AddExclude
+func (s *SplitRouting) RemoveExclude(address *net.IPNet) bool {
+	return s.excludes.RemoveRuntime(address)
+}
+
+// RuntimeExcludes returns the split excludes added with AddExclude
+func (s *SplitRouting) RuntimeExcludes() []*net.IPNet {
+	return s.excludes.Runtime()
+}
+
+// AddGateway adds the address ip of the VPN gateway to the split excludes,
+// e.g., an alternative address the gateway roams to after a failover. The
+// exclude is kept until split routing stops, so traffic to the gateway never
+// enters the tunnel. It returns false if ip is invalid or already excluded as
+// gateway address
+func (s *SplitRouting) AddGateway(ip net.IP) bool {
+	if len(ip) == 0 || ip.IsUnspecified() {
+		return false
+	}
+	return s.excludes.AddGateway(hostPrefix(ip))
+}
+
+// GatewayExcludes returns the addresses of the VPN gateway in the split
+// excludes
+func (s *SplitRouting) GatewayExcludes() []*net.IPNet {
+	return s.excludes.Gateways()
+}
+
 // DNSReports returns the channel for dns reports
 func (s *SplitRouting) DNSReports() chan *dnsproxy.Report {
 	return s.dnsreps
@@ -271,8 +370,8 @@ func NewSplitRouting(config *vpnconfig.Config) *SplitRouting {
 		devices:  NewDevices(),
 		addrs:    NewAddresses(),
 		excludes: NewExcludes(),
+		churn:    newChurn(),
 		dnsreps:  make(chan *dnsproxy.Report),
-		done:     make(chan struct{}),
 		closed:   make(chan struct{}),
 	}
 }
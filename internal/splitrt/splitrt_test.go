@@ -1,6 +1,9 @@
 package splitrt
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net"
 	"reflect"
 	"testing"
@@ -78,6 +81,34 @@ func TestSplitRoutingHandleAddressUpdate(t *testing.T) {
 	}
 }
 
+// TestSplitRoutingHandleAddressUpdateIPv6 tests handleAddressUpdate of
+// SplitRouting with IPv6 local network excludes
+func TestSplitRoutingHandleAddressUpdateIPv6(t *testing.T) {
+	config := vpnconfig.New()
+	config.Split.ExcludeIPv6 = []*net.IPNet{
+		{
+			IP:   net.IPv6zero,
+			Mask: net.CIDRMask(128, 128),
+		},
+	}
+	s := NewSplitRouting(config)
+	s.devices.Add(getTestDevMonUpdate())
+
+	got := []string{}
+	runNft = func(s string) {
+		got = append(got, s)
+	}
+
+	want := []string{
+		"add element inet oc-daemon-routing excludes6 { 2001:db8::1/128 }",
+	}
+	update := getTestAddrMonUpdate("2001:db8::1/128")
+	s.handleAddressUpdate(update)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
 // TestSplitRoutingHandleDNSReport tests handleDNSReport of SplitRouting
 func TestSplitRoutingHandleDNSReport(t *testing.T) {
 	config := vpnconfig.New()
@@ -107,6 +138,87 @@ func TestSplitRoutingHandleDNSReport(t *testing.T) {
 	}
 }
 
+// TestSplitRoutingHandleDNSReportChurn tests handleDNSReport of
+// SplitRouting with route churn
+func TestSplitRoutingHandleDNSReportChurn(t *testing.T) {
+	config := vpnconfig.New()
+	s := NewSplitRouting(config)
+
+	got := []string{}
+	runNft = func(s string) {
+		got = append(got, s)
+	}
+
+	// exceed threshold with addresses of domain
+	for i := 0; i <= churnMaxAddresses; i++ {
+		ip := net.ParseIP(fmt.Sprintf("192.168.1.%d", i+1))
+		report := dnsproxy.NewReport("churn.example.com", ip, 5)
+		go s.handleDNSReport(report)
+		report.Wait()
+	}
+
+	// addresses are replaced by covering prefix
+	want := "flush set inet oc-daemon-routing excludes4\n" +
+		"flush set inet oc-daemon-routing excludes6\n" +
+		"add element inet oc-daemon-routing excludes4 { 192.168.1.0/24 }\n"
+	if len(got) != churnMaxAddresses+1 || got[churnMaxAddresses] != want {
+		t.Errorf("got %v", got)
+	}
+	if s.Aggregations() != 1 {
+		t.Errorf("got %d aggregations, want 1", s.Aggregations())
+	}
+}
+
+// TestSplitRoutingAddGateway tests AddGateway and GatewayExcludes of
+// SplitRouting
+func TestSplitRoutingAddGateway(t *testing.T) {
+	config := vpnconfig.New()
+	s := NewSplitRouting(config)
+
+	got := []string{}
+	runNft = func(s string) {
+		got = append(got, s)
+	}
+
+	// invalid addresses
+	for _, invalid := range []net.IP{nil, net.IPv4zero, net.IPv6unspecified} {
+		if s.AddGateway(invalid) {
+			t.Errorf("%s: gateway should not be added", invalid)
+		}
+	}
+
+	// valid addresses
+	for _, ip := range []string{"192.168.1.1", "2001::1"} {
+		if !s.AddGateway(net.ParseIP(ip)) {
+			t.Errorf("%s: gateway should be added", ip)
+		}
+	}
+	if s.AddGateway(net.ParseIP("192.168.1.1")) {
+		t.Error("gateway should not be added twice")
+	}
+
+	// dns reports for gateway do not change gateway excludes
+	report := dnsproxy.NewReport("vpn.example.com", net.ParseIP("192.168.1.1"), 0)
+	go s.handleDNSReport(report)
+	report.Wait()
+	s.excludes.cleanup()
+
+	want := []string{
+		"add element inet oc-daemon-routing excludes4 { 192.168.1.1/32 }",
+		"add element inet oc-daemon-routing excludes6 { 2001::1/128 }",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	gws := []string{}
+	for _, gw := range s.GatewayExcludes() {
+		gws = append(gws, gw.String())
+	}
+	if !reflect.DeepEqual(gws, []string{"192.168.1.1/32", "2001::1/128"}) {
+		t.Errorf("got %v", gws)
+	}
+}
+
 // TestSplitRoutingStartStop tests Start and Stop of SplitRouting
 func TestSplitRoutingStartStop(t *testing.T) {
 	config := vpnconfig.New()
@@ -116,15 +228,26 @@ func TestSplitRoutingStartStop(t *testing.T) {
 	cmd := func(s string) {}
 	runNft = cmd
 	runCmd = cmd
-	addrmon.RegisterAddrUpdates = func(*addrmon.AddrMon) chan netlink.AddrUpdate {
-		return nil
+	addrmon.RegisterAddrUpdates = func(*addrmon.AddrMon) (chan netlink.AddrUpdate, error) {
+		return nil, nil
 	}
-	devmon.RegisterLinkUpdates = func(*devmon.DevMon) chan netlink.LinkUpdate {
-		return nil
+	devmon.RegisterLinkUpdates = func(*devmon.DevMon) (chan netlink.LinkUpdate, error) {
+		return nil, nil
 	}
 
-	s.Start()
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
 	s.Stop()
+
+	// test with address monitor error
+	s = NewSplitRouting(config)
+	addrmon.RegisterAddrUpdates = func(*addrmon.AddrMon) (chan netlink.AddrUpdate, error) {
+		return nil, errors.New("test error")
+	}
+	if err := s.Start(context.Background()); err == nil {
+		t.Error("start should fail")
+	}
 }
 
 // TestSplitRoutingDNSReports tests DNSReports of SplitRouting
@@ -151,7 +274,6 @@ func TestNewSplitRouting(t *testing.T) {
 		s.addrs == nil ||
 		s.excludes == nil ||
 		s.dnsreps == nil ||
-		s.done == nil ||
 		s.closed == nil {
 
 		t.Errorf("got nil, want != nil")
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -35,10 +36,30 @@ type allowHost struct {
 	lastUpdate time.Time
 }
 
+// parseIPNet returns host as network address if it is a network address or
+// an IPv4 or IPv6 address, e.g., "2001:db8::1" or "[2001:db8::1]", and nil
+// otherwise
+func parseIPNet(host string) *net.IPNet {
+	if _, ipnet, err := net.ParseCIDR(host); err == nil {
+		return ipnet
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
+
 // resolve resolves the allowed host to its IP addresses
 func (a *allowHost) resolve() {
-	// check if host is a network address
-	if _, ipnet, err := net.ParseCIDR(a.host); err == nil {
+	// check if host is an IP address, also IPv6 addresses in brackets,
+	// or network address
+	ipnet := parseIPNet(a.host)
+	if ipnet != nil {
 		a.lastUpdate = time.Now()
 
 		// check if address already exists
@@ -119,7 +140,8 @@ type AllowHosts struct {
 	m map[string]*allowHost
 
 	updates chan struct{}
-	done    chan struct{}
+	done    <-chan struct{}
+	cancel  context.CancelFunc
 	closed  chan struct{}
 }
 
@@ -263,14 +285,18 @@ func (a *AllowHosts) start() {
 	}
 }
 
-// Start starts the allowed hosts
-func (a *AllowHosts) Start() {
+// Start starts the allowed hosts, it runs until Stop is called or ctx is
+// canceled
+func (a *AllowHosts) Start(ctx context.Context) error {
+	ctx, a.cancel = context.WithCancel(ctx)
+	a.done = ctx.Done()
 	go a.start()
+	return nil
 }
 
 // Stop stops the allowed hosts
 func (a *AllowHosts) Stop() {
-	close(a.done)
+	a.cancel()
 
 	// wait for shutdown
 	<-a.closed
@@ -287,7 +313,6 @@ func NewAllowHosts() *AllowHosts {
 		m: make(map[string]*allowHost),
 
 		updates: make(chan struct{}),
-		done:    make(chan struct{}),
 		closed:  make(chan struct{}),
 	}
 }
@@ -1,6 +1,9 @@
 package trafpol
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 // TestAllowHostsAdd tests Add of AllowHosts
 func TestAllowHostsAdd(t *testing.T) {
@@ -13,6 +16,30 @@ func TestAllowHostsAdd(t *testing.T) {
 	}
 }
 
+// TestParseIPNet tests parseIPNet
+func TestParseIPNet(t *testing.T) {
+	for _, test := range []struct {
+		host string
+		want string
+	}{
+		{"192.168.1.1", "192.168.1.1/32"},
+		{"192.168.1.0/24", "192.168.1.0/24"},
+		{"2001:db8::1", "2001:db8::1/128"},
+		{"[2001:db8::1]", "2001:db8::1/128"},
+		{"2001:db8::/32", "2001:db8::/32"},
+	} {
+		got := parseIPNet(test.host)
+		if got == nil || got.String() != test.want {
+			t.Errorf("got %v, want %s", got, test.want)
+		}
+	}
+
+	// host name
+	if got := parseIPNet("example.com"); got != nil {
+		t.Errorf("got %v, want nil", got)
+	}
+}
+
 // TestAllowHostsRemove tests Add of AllowHosts
 func TestAllowHostsRemove(t *testing.T) {
 	a := NewAllowHosts()
@@ -27,7 +54,9 @@ func TestAllowHostsRemove(t *testing.T) {
 // TestAllowHostsStartStop tests Start and Stop of AllowHosts
 func TestAllowHostsStartStop(t *testing.T) {
 	a := NewAllowHosts()
-	a.Start()
+	if err := a.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
 	a.Stop()
 }
 
@@ -36,7 +65,9 @@ func TestAllowHostsUpdate(t *testing.T) {
 	a := NewAllowHosts()
 	host := "example.com"
 	a.Add(host)
-	a.Start()
+	if err := a.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
 	a.Update()
 	a.Stop()
 }
@@ -46,7 +77,6 @@ func TestNewAllowHosts(t *testing.T) {
 	a := NewAllowHosts()
 	if a.m == nil ||
 		a.updates == nil ||
-		a.done == nil ||
 		a.closed == nil {
 
 		t.Errorf("got nil, want != nil")
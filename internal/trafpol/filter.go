@@ -7,7 +7,7 @@ import (
 	"os/exec"
 	"strings"
 
-	log "github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/manifest"
 	"github.com/telekom-mms/oc-daemon/internal/splitrt"
 )
 
@@ -165,12 +165,14 @@ table inet oc-daemon-filter {
 `
 	r := strings.NewReplacer("$FWMARK", splitrt.FWMark)
 	rules := r.Replace(filterRules)
+	manifest.Add(manifest.KindNftTable, "inet oc-daemon-filter")
 	runNft(rules)
 }
 
 // unsetFilterRules unsets the filter rules
 func unsetFilterRules() {
 	runNft("delete table inet oc-daemon-filter")
+	manifest.Remove(manifest.KindNftTable, "inet oc-daemon-filter")
 }
 
 // addAllowedDevice adds device to the allowed devices
@@ -0,0 +1,6 @@
+package trafpol
+
+import "github.com/telekom-mms/oc-daemon/internal/logging"
+
+// log is the logger of the traffic policing
+var log = logging.Component(logging.ComponentTrafPol)
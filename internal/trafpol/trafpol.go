@@ -1,7 +1,8 @@
 package trafpol
 
 import (
-	log "github.com/sirupsen/logrus"
+	"context"
+
 	"github.com/telekom-mms/oc-daemon/internal/cpd"
 	"github.com/telekom-mms/oc-daemon/internal/devmon"
 	"github.com/telekom-mms/oc-daemon/internal/dnsmon"
@@ -20,7 +21,11 @@ type TrafPol struct {
 	allowHosts *AllowHosts
 
 	loopDone chan struct{}
-	done     chan struct{}
+	done     <-chan struct{}
+	cancel   context.CancelFunc
+
+	// stops are the stop functions of the started subcomponents
+	stops []func()
 }
 
 // handleDeviceUpdate handles a device update
@@ -71,35 +76,18 @@ func (t *TrafPol) handleCPDReport(report *cpd.Report) {
 	}
 }
 
+// stop stops all started subcomponents in reverse order
+func (t *TrafPol) stop() {
+	for i := len(t.stops) - 1; i >= 0; i-- {
+		t.stops[i]()
+	}
+	t.stops = nil
+}
+
 // start starts the traffic policing component
 func (t *TrafPol) start() {
-	log.Debug("TrafPol starting")
 	defer close(t.loopDone)
-
-	// set firewall config
-	setFilterRules()
-	defer unsetFilterRules()
-
-	// add CPD hosts to allowed hosts
-	for _, h := range t.cpd.Hosts() {
-		t.allowHosts.Add(h)
-	}
-
-	// start allowed hosts
-	t.allowHosts.Start()
-	defer t.allowHosts.Stop()
-
-	// start captive portal detection
-	t.cpd.Start()
-	defer t.cpd.Stop()
-
-	// start device monitor
-	t.devmon.Start()
-	defer t.devmon.Stop()
-
-	// start dns monitor
-	t.dnsmon.Start()
-	defer t.dnsmon.Stop()
+	defer t.stop()
 
 	// enter main loop
 	for {
@@ -126,14 +114,48 @@ func (t *TrafPol) start() {
 	}
 }
 
-// Start starts the traffic policing component
-func (t *TrafPol) Start() {
+// Start starts the traffic policing component, it runs until Stop is called
+// or ctx is canceled
+func (t *TrafPol) Start(ctx context.Context) error {
+	log.Debug("TrafPol starting")
+	ctx, t.cancel = context.WithCancel(ctx)
+	t.done = ctx.Done()
+
+	// set firewall config
+	setFilterRules()
+	t.stops = append(t.stops, unsetFilterRules)
+
+	// add CPD hosts to allowed hosts
+	for _, h := range t.cpd.Hosts() {
+		t.allowHosts.Add(h)
+	}
+
+	// start allowed hosts, captive portal detection, device monitor and
+	// dns monitor
+	for _, c := range []struct {
+		start func(context.Context) error
+		stop  func()
+	}{
+		{t.allowHosts.Start, t.allowHosts.Stop},
+		{t.cpd.Start, t.cpd.Stop},
+		{t.devmon.Start, t.devmon.Stop},
+		{t.dnsmon.Start, t.dnsmon.Stop},
+	} {
+		if err := c.start(ctx); err != nil {
+			t.stop()
+			t.cancel()
+			return err
+		}
+		t.stops = append(t.stops, c.stop)
+	}
+
 	go t.start()
+	return nil
 }
 
 // Stop stops the traffic policing component
 func (t *TrafPol) Stop() {
-	close(t.done)
+	t.cancel()
 
 	// wait for everything
 	<-t.loopDone
@@ -155,7 +177,6 @@ func NewTrafPol(allowedHosts []string) *TrafPol {
 		allowHosts: allowHosts,
 
 		loopDone: make(chan struct{}),
-		done:     make(chan struct{}),
 	}
 }
 
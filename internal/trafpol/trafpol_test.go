@@ -1,6 +1,8 @@
 package trafpol
 
 import (
+	"context"
+	"errors"
 	"reflect"
 	"sync"
 	"testing"
@@ -31,9 +33,13 @@ func TestTrafPolHandleDNSUpdate(t *testing.T) {
 	allowedHosts := []string{"example.com"}
 	tp := NewTrafPol(allowedHosts)
 
-	tp.allowHosts.Start()
+	if err := tp.allowHosts.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
 	defer tp.allowHosts.Stop()
-	tp.cpd.Start()
+	if err := tp.cpd.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
 	defer tp.cpd.Stop()
 
 	tp.handleDNSUpdate()
@@ -44,7 +50,9 @@ func TestTrafPolHandleCPDReport(t *testing.T) {
 	allowedHosts := []string{"example.com"}
 	tp := NewTrafPol(allowedHosts)
 
-	tp.allowHosts.Start()
+	if err := tp.allowHosts.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
 	defer tp.allowHosts.Stop()
 
 	var nftMutex sync.Mutex
@@ -102,12 +110,23 @@ func TestTrafPolStartStop(t *testing.T) {
 	tp := NewTrafPol(allowedHosts)
 
 	// set dummy low level function for devmon
-	devmon.RegisterLinkUpdates = func(*devmon.DevMon) chan netlink.LinkUpdate {
-		return nil
+	devmon.RegisterLinkUpdates = func(*devmon.DevMon) (chan netlink.LinkUpdate, error) {
+		return nil, nil
 	}
 
-	tp.Start()
+	if err := tp.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
 	tp.Stop()
+
+	// test with device monitor error
+	tp = NewTrafPol(allowedHosts)
+	devmon.RegisterLinkUpdates = func(*devmon.DevMon) (chan netlink.LinkUpdate, error) {
+		return nil, errors.New("test error")
+	}
+	if err := tp.Start(context.Background()); err == nil {
+		t.Error("start should fail")
+	}
 }
 
 // TestNewTrafPol tests NewTrafPol
@@ -119,8 +138,7 @@ func TestNewTrafPol(t *testing.T) {
 		tp.cpd == nil ||
 		tp.allowDevs == nil ||
 		tp.allowHosts == nil ||
-		tp.loopDone == nil ||
-		tp.done == nil {
+		tp.loopDone == nil {
 
 		t.Errorf("got nil, want != nil")
 	}
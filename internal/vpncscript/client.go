@@ -2,6 +2,9 @@ package vpncscript
 
 import (
 	"net"
+	"os"
+	"path/filepath"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/telekom-mms/oc-daemon/internal/api"
@@ -11,12 +14,41 @@ import (
 const (
 	runDir   = "/run/oc-daemon"
 	sockFile = runDir + "/daemon.sock"
+
+	// secretFileName is the name of the secret file of the daemon in the
+	// directory of the socket file
+	secretFileName = "vpncscript.secret"
+
+	// requestTimeout is the timeout of the config update request, the
+	// daemon aborts the request if it cannot handle it in time
+	requestTimeout = 20 * time.Second
+
+	// replyTimeout is the additional time the script waits for the reply
+	// after requestTimeout, e.g., for the error reply of an aborted request
+	replyTimeout = 5 * time.Second
 )
 
-// runClient interacts with the daemon over the api
-func runClient(configUpdate *daemon.VPNConfigUpdate) {
+// runClient interacts with the daemon listening on socketFile over the api,
+// uses the default socket file if socketFile is empty; it authenticates the
+// config update with the response to the challenge of the daemon with the
+// connection secret in secretFile, uses the secret file in the directory of
+// the socket file if secretFile is empty
+func runClient(socketFile, secretFile string, configUpdate *daemon.VPNConfigUpdate) {
+	if socketFile == "" {
+		socketFile = sockFile
+	}
+	if secretFile == "" {
+		secretFile = filepath.Join(filepath.Dir(socketFile), secretFileName)
+	}
+
+	// read secret of the connection
+	secret, err := os.ReadFile(secretFile)
+	if err != nil {
+		log.WithError(err).Fatal("VPNCScript could not read connection secret")
+	}
+
 	// connect to daemon
-	conn, err := net.Dial("unix", sockFile)
+	conn, err := net.Dial("unix", socketFile)
 	if err != nil {
 		log.WithError(err).Fatal("VPNCScript could not connect to Daemon")
 	}
@@ -24,12 +56,48 @@ func runClient(configUpdate *daemon.VPNConfigUpdate) {
 		_ = conn.Close()
 	}()
 
+	// do not wait forever for the daemon, e.g., if it hangs in a system
+	// change
+	if err := conn.SetDeadline(time.Now().
+		Add(requestTimeout + replyTimeout)); err != nil {
+		log.WithError(err).Fatal("VPNCScript could not set deadline")
+	}
+
+	// negotiate protocol version and get challenge, the handshake is
+	// required for the challenge-response authentication
+	hello, err := api.Handshake(conn, api.Capabilities, requestTimeout)
+	if err != nil {
+		log.WithError(err).Fatal("VPNCScript handshake with Daemon failed")
+	}
+	log.WithField("version", hello.Version).
+		Debug("VPNCScript negotiated protocol version with Daemon")
+	compress := hello.Capabilities&api.CapabilityCompression != 0
+	chunking := hello.Capabilities&api.CapabilityChunking != 0
+	if len(hello.Challenge) == 0 {
+		log.Fatal("VPNCScript got no challenge from Daemon")
+	}
+	configUpdate.Response = api.ChallengeResponse(secret, hello.Challenge)
+
 	// send message to daemon
 	b, err := configUpdate.JSON()
 	if err != nil {
 		log.WithError(err).Fatal("VPNCScript could not convert config update to JSON")
 	}
 	msg := api.NewMessage(api.TypeVPNConfigUpdate, b)
+	if compress {
+		msg = api.NewCompressedMessage(api.TypeVPNConfigUpdate, b)
+	}
+	if msg == nil && chunking {
+		// config update too long for a single message, e.g., with many
+		// split excludes
+		msg = api.NewChunkedMessage(api.TypeVPNConfigUpdate, b)
+	}
+	if msg == nil {
+		log.Fatal("VPNCScript could not create message, config update too long")
+	}
+	if compress {
+		msg.Flags |= api.FlagAcceptCompression
+	}
 	err = api.WriteMessage(conn, msg)
 	if err != nil {
 		log.WithError(err).Fatal("VPNCScript could not send message to Daemon")
@@ -38,8 +106,14 @@ func runClient(configUpdate *daemon.VPNConfigUpdate) {
 	// receive reply
 	reply, err := api.ReadMessage(conn)
 	if err != nil {
+		// cancel request, so the daemon does not apply it later
+		_ = conn.SetWriteDeadline(time.Now().Add(time.Second))
+		_ = api.WriteMessage(conn, api.NewCancelMessage())
 		log.WithError(err).Fatal("VPNCScript could not receive reply from Daemon")
 	}
+	if err := reply.Decompress(); err != nil {
+		log.WithError(err).Fatal("VPNCScript could not decompress reply from Daemon")
+	}
 	switch reply.Type {
 	case api.TypeOK:
 		log.WithField("reply", reply.Value).
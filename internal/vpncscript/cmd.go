@@ -36,12 +36,10 @@ func Run() {
 	switch e.reason {
 	case "pre-init":
 		return
-	case "connect", "disconnect":
+	case "connect", "disconnect", "attempt-reconnect":
 		c := createConfigUpdate(e)
 		log.WithField("update", c).Debug("VPNCScript created config update")
-		runClient(c)
-	case "attempt-reconnect":
-		return
+		runClient(e.socketFile, e.secretFile, c)
 	case "reconnect":
 		return
 	default:
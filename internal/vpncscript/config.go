@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/charset"
 	"github.com/telekom-mms/oc-daemon/internal/daemon"
 	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
 )
@@ -118,23 +119,39 @@ func createConfigDNS(env *env, config *vpnconfig.Config) {
 	if env.internalIP6DNS != "" {
 		config.DNS.ServersIPv6 = parse(env.internalIP6DNS)
 	}
+
+	// set split dns domains and tunnel all dns setting
+	for _, d := range strings.Split(env.ciscoSplitDNS, ",") {
+		d = strings.TrimSpace(d)
+		if d == "" {
+			continue
+		}
+		config.DNS.SplitDomains = append(config.DNS.SplitDomains, d)
+	}
+	config.DNS.TunnelAll = env.tunnelAllDNS
 }
 
 // createConfigSplit creates the split routing configuration in config from env
 func createConfigSplit(env *env, config *vpnconfig.Config) {
-	// set ipv4 and ipv6 excludes
+	// set ipv4 and ipv6 includes and excludes
 	parse := func(list []string) []*net.IPNet {
 		ipnets := []*net.IPNet{}
 		for _, e := range list {
 			_, ipnet, err := net.ParseCIDR(e)
 			if err != nil {
 				log.WithError(err).
-					Fatal("VPNCScript could not parse exclude IP address")
+					Fatal("VPNCScript could not parse split IP address")
 			}
 			ipnets = append(ipnets, ipnet)
 		}
 		return ipnets
 	}
+	if len(env.ciscoSplitInc) != 0 {
+		config.Split.IncludeIPv4 = parse(env.ciscoSplitInc)
+	}
+	if len(env.ciscoIPv6SplitInc) != 0 {
+		config.Split.IncludeIPv6 = parse(env.ciscoIPv6SplitInc)
+	}
 	if len(env.ciscoSplitExc) != 0 {
 		config.Split.ExcludeIPv4 = parse(env.ciscoSplitExc)
 	}
@@ -155,6 +172,12 @@ func createConfigFlags(env *env, config *vpnconfig.Config) {
 	config.Flags.DisableAlwaysOnVPN = env.disableAlwaysOnVPN
 }
 
+// createConfigBanner creates the banner configuration in config from env,
+// banners of older gateways in legacy charsets are converted to UTF-8
+func createConfigBanner(env *env, config *vpnconfig.Config) {
+	config.Banner.Text = charset.ToUTF8(env.ciscoBanner)
+}
+
 // createConfig creates a VPN configuration from env
 func createConfig(env *env) *vpnconfig.Config {
 	config := vpnconfig.New()
@@ -185,6 +208,9 @@ func createConfig(env *env) *vpnconfig.Config {
 	// set flags configuration
 	createConfigFlags(env, config)
 
+	// set banner configuration
+	createConfigBanner(env, config)
+
 	return config
 }
 
@@ -192,9 +218,13 @@ func createConfig(env *env) *vpnconfig.Config {
 func createConfigUpdate(env *env) *daemon.VPNConfigUpdate {
 	update := daemon.NewVPNConfigUpdate()
 	update.Reason = env.reason
-	update.Token = env.token
-	if env.reason == "connect" {
+	switch env.reason {
+	case "connect":
 		update.Config = createConfig(env)
+	case "attempt-reconnect":
+		// only report the gateway address openconnect reconnects to
+		update.Config = vpnconfig.New()
+		update.Config.Gateway = net.ParseIP(env.vpnGateway)
 	}
 	return update
 }
@@ -8,19 +8,49 @@ import (
 	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
 )
 
+// TestCreateConfigDNS tests createConfigDNS
+func TestCreateConfigDNS(t *testing.T) {
+	// create test environment
+	env := &env{
+		internalIP4DNS: "192.168.1.1",
+		ciscoDefDomain: "example.com",
+		ciscoSplitDNS:  "example.com, other.example.com,",
+		tunnelAllDNS:   true,
+	}
+
+	// create expected values
+	want := vpnconfig.DNS{
+		DefaultDomain: "example.com",
+		ServersIPv4:   []net.IP{net.ParseIP("192.168.1.1")},
+		SplitDomains:  []string{"example.com", "other.example.com"},
+		TunnelAll:     true,
+	}
+	got := vpnconfig.New()
+
+	// update config from test environment
+	createConfigDNS(env, got)
+
+	// check results
+	if !reflect.DeepEqual(got.DNS, want) {
+		t.Errorf("got %v, want %v", got.DNS, want)
+	}
+}
+
 // TestCreateConfigSplit tests createConfigSplit
 func TestCreateConfigSplit(t *testing.T) {
 	// create test environment
 	env := &env{
-		ciscoSplitInc:              []string{},
+		ciscoSplitInc:              []string{"10.0.0.0/8"},
 		ciscoSplitExc:              []string{"172.16.0.0/16"},
-		ciscoIPv6SplitInc:          []string{},
+		ciscoIPv6SplitInc:          []string{"2001:db8::/32"},
 		ciscoIPv6SplitExc:          []string{},
 		dnsSplitExc:                []string{"some.example.com", "other.example.com", "www.example.com"},
 		bypassVirtualSubnetsOnlyV4: true,
 	}
 
 	// create expected values
+	incIPv4 := "10.0.0.0/8"
+	incIPv6 := "2001:db8::/32"
 	ipv4 := []*net.IPNet{
 		{
 			IP:   net.IPv4(172, 16, 0, 0),
@@ -35,6 +65,14 @@ func TestCreateConfigSplit(t *testing.T) {
 	createConfigSplit(env, got)
 
 	// check results
+	if len(got.Split.IncludeIPv4) != 1 ||
+		got.Split.IncludeIPv4[0].String() != incIPv4 {
+		t.Errorf("got %v, want %s", got.Split.IncludeIPv4, incIPv4)
+	}
+	if len(got.Split.IncludeIPv6) != 1 ||
+		got.Split.IncludeIPv6[0].String() != incIPv6 {
+		t.Errorf("got %v, want %s", got.Split.IncludeIPv6, incIPv6)
+	}
 	if len(got.Split.ExcludeIPv4) != len(ipv4) {
 		t.Errorf("got %v, want %v", got.Split.ExcludeIPv4, ipv4)
 	} else {
@@ -69,7 +107,7 @@ func TestCreateConfigUpdate(t *testing.T) {
 		internalIP4DNS:             "192.168.1.1",
 		internalIP4NBNS:            "192.168.1.1",
 		ciscoDefDomain:             "example.com",
-		ciscoBanner:                "some banner",
+		ciscoBanner:                "some banner \xe0 la carte",
 		ciscoSplitInc:              []string{}, // splits are tested in TestCreateConfigSplit
 		ciscoSplitExc:              []string{},
 		ciscoIPv6SplitInc:          []string{},
@@ -77,12 +115,11 @@ func TestCreateConfigUpdate(t *testing.T) {
 		dnsSplitExc:                []string{"some.example.com", "other.example.com", "www.example.com"},
 		bypassVirtualSubnetsOnlyV4: true,
 		disableAlwaysOnVPN:         true,
-		token:                      "some token",
+		secretFile:                 "/run/oc-daemon/vpncscript.secret",
 	}
 
 	// create expected values based on test environment
 	reason := "connect"
-	token := "some token"
 	config := &vpnconfig.Config{
 		Gateway: net.IPv4(10, 1, 1, 1),
 		PID:     12345,
@@ -107,6 +144,9 @@ func TestCreateConfigUpdate(t *testing.T) {
 		Flags: vpnconfig.Flags{
 			DisableAlwaysOnVPN: true,
 		},
+		Banner: vpnconfig.Banner{
+			Text: "some banner à la carte",
+		},
 	}
 
 	// pare environment and get update
@@ -116,8 +156,21 @@ func TestCreateConfigUpdate(t *testing.T) {
 	if got.Reason != reason {
 		t.Errorf("got %s, want %s", got.Reason, reason)
 	}
-	if got.Token != token {
-		t.Errorf("got %s, want %s", got.Token, token)
+	if got.Response != nil {
+		t.Errorf("got %x, want no response", got.Response)
+	}
+	if !reflect.DeepEqual(got.Config, config) {
+		t.Errorf("got:\n%#v\nwant:\n%#v", got.Config, config)
+	}
+
+	// attempt reconnect, only gateway address is set
+	env.reason = "attempt-reconnect"
+	env.vpnGateway = "10.1.1.2"
+	got = createConfigUpdate(env)
+	config = vpnconfig.New()
+	config.Gateway = net.ParseIP("10.1.1.2")
+	if got.Reason != "attempt-reconnect" {
+		t.Errorf("got %s, want attempt-reconnect", got.Reason)
 	}
 	if !reflect.DeepEqual(got.Config, config) {
 		t.Errorf("got:\n%#v\nwant:\n%#v", got.Config, config)
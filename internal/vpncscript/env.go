@@ -117,9 +117,13 @@ type env struct {
 
 	bypassVirtualSubnetsOnlyV4 bool
 	disableAlwaysOnVPN         bool
+	tunnelAllDNS               bool
 
-	// openconnect daemon token
-	token string
+	// openconnect daemon secret file of the connection
+	secretFile string
+
+	// openconnect daemon socket file, default socket file if empty
+	socketFile string
 }
 
 // parseEnvironmentSplit parses split include/exclude parameters identified by
@@ -253,6 +257,23 @@ func parseDisableAlwaysOnVPN(ciscoCSTPOptions []string) bool {
 	return false
 }
 
+// parseTunnelAllDNS parses the tunnel all dns setting in ciscoCSTPOptions
+func parseTunnelAllDNS(ciscoCSTPOptions []string) bool {
+	for _, opt := range ciscoCSTPOptions {
+		pair := strings.SplitN(opt, "=", 2)
+		key := pair[0]
+		if key != "X-CSTP-Tunnel-All-DNS" {
+			continue
+		}
+		if len(pair) != 2 || pair[1] != "true" {
+			return false
+		}
+		return true
+	}
+
+	return false
+}
+
 // parseEnvironment parses environment variables and collects
 // openconnect settings
 func parseEnvironment() *env {
@@ -375,8 +396,14 @@ func parseEnvironment() *env {
 	// parse Disable Always On VPN
 	e.disableAlwaysOnVPN = parseDisableAlwaysOnVPN(e.ciscoCSTPOptions)
 
-	// parse openconnect daemon token
-	e.token = os.Getenv("oc_daemon_token")
+	// parse Tunnel All DNS
+	e.tunnelAllDNS = parseTunnelAllDNS(e.ciscoCSTPOptions)
+
+	// parse openconnect daemon secret file
+	e.secretFile = os.Getenv("oc_daemon_secret_file")
+
+	// parse openconnect daemon socket file
+	e.socketFile = os.Getenv("oc_daemon_socket_file")
 
 	return e
 }
@@ -307,6 +307,32 @@ func TestParseDisableAlwaysOnVPN(t *testing.T) {
 	test(true, parseDisableAlwaysOnVPN(opts))
 }
 
+// TestParseTunnelAllDNS tests parseTunnelAllDNS
+func TestParseTunnelAllDNS(t *testing.T) {
+	test := func(want, got bool) {
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %t, want %t", got, want)
+		}
+	}
+
+	// test empty opts
+	opts := []string{}
+	test(false, parseTunnelAllDNS(opts))
+
+	// test incomplete or false opts
+	opts = []string{
+		"",
+		"X-CSTP-Tunnel-All-DNS",
+		"X-CSTP-Tunnel-All-DNS=",
+		"X-CSTP-Tunnel-All-DNS=false",
+	}
+	test(false, parseTunnelAllDNS(opts))
+
+	// test complete
+	opts = []string{"X-CSTP-Tunnel-All-DNS=true"}
+	test(true, parseTunnelAllDNS(opts))
+}
+
 // TestParseEnvironment tests parseEnvironment
 func TestParseEnvironment(t *testing.T) {
 	// setup test environment
@@ -342,7 +368,8 @@ func TestParseEnvironment(t *testing.T) {
 		"CISCO_IPV6_SPLITEXCC":       "0",
 		"CISCO_CSTP_OPTIONS": `X-CSTP-Post-Auth-XML=<?xml version="1.0" encoding="UTF-8"?><config-auth client="vpn" type="complete" aggregate-auth-version="2"><config client="vpn" type="private"><opaque is-for="vpn-client"><custom-attr><dynamic-split-exclude-domains><![CDATA[some.example.com,other.example.com,www.example.com]]></dynamic-split-exclude-domains><BypassVirtualSubnetsOnlyV4><![CDATA[true]]></BypassVirtualSubnetsOnlyV4></custom-attr></opaque></config></config-auth>
 X-CSTP-Disable-Always-On-VPN=true`,
-		"oc_daemon_token": "some token",
+		"oc_daemon_secret_file": "/run/oc-daemon-dev/vpncscript.secret",
+		"oc_daemon_socket_file": "/run/oc-daemon-dev/daemon.sock",
 	} {
 		os.Setenv(k, v)
 	}
@@ -374,7 +401,8 @@ X-CSTP-Disable-Always-On-VPN=true`,
 		dnsSplitExc:                []string{"some.example.com", "other.example.com", "www.example.com"},
 		bypassVirtualSubnetsOnlyV4: true,
 		disableAlwaysOnVPN:         true,
-		token:                      "some token",
+		secretFile:                 "/run/oc-daemon-dev/vpncscript.secret",
+		socketFile:                 "/run/oc-daemon-dev/daemon.sock",
 	}
 
 	// run test
@@ -0,0 +1,310 @@
+package wpad
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// httpTimeout is the timeout for http requests in seconds
+	httpTimeout = 5
+
+	// maxPACSize is the maximum size of a PAC file in bytes
+	maxPACSize = 1024 * 1024
+
+	// wpadFile is the file name of the PAC file on WPAD servers
+	wpadFile = "wpad.dat"
+)
+
+var (
+	// resolvConf is the resolv.conf file used to get the search domains
+	resolvConf = "/etc/resolv.conf"
+
+	// proxyRegexp matches PROXY directives in PAC files
+	proxyRegexp = regexp.MustCompile(`PROXY\s+([^\s;"']+)`)
+)
+
+// Report is a proxy auto-detection report
+type Report struct {
+	// Detected indicates if a proxy has been detected
+	Detected bool
+
+	// URL is the URL of the PAC file the proxy has been detected with
+	URL string
+
+	// Proxy is the detected proxy, e.g., "http://proxy.example.com:8080"
+	Proxy string
+}
+
+// WPAD is a web proxy auto-discovery instance
+type WPAD struct {
+	dialer  *net.Dialer
+	reports chan *Report
+	probes  chan struct{}
+	done    <-chan struct{}
+	cancel  context.CancelFunc
+}
+
+// getSearchDomains returns the search domains in the resolv.conf file
+var getSearchDomains = func() []string {
+	f, err := os.Open(resolvConf)
+	if err != nil {
+		log.WithError(err).Debug("WPAD could not open resolv.conf")
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	domains := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "domain", "search":
+			domains = append(domains, fields[1:]...)
+		}
+	}
+	return domains
+}
+
+// getDHCPURL returns the WPAD URL from DHCP option 252 as reported by
+// NetworkManager
+var getDHCPURL = func() string {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		log.WithError(err).Debug("WPAD could not connect to system bus")
+		return ""
+	}
+	defer func() { _ = conn.Close() }()
+
+	// get primary connection
+	nm := conn.Object("org.freedesktop.NetworkManager",
+		"/org/freedesktop/NetworkManager")
+	var primary dbus.ObjectPath
+	if err := nm.StoreProperty("org.freedesktop.NetworkManager."+
+		"PrimaryConnection", &primary); err != nil || primary == "/" {
+		return ""
+	}
+
+	// get dhcp4 config of primary connection
+	active := conn.Object("org.freedesktop.NetworkManager", primary)
+	var dhcp4 dbus.ObjectPath
+	if err := active.StoreProperty("org.freedesktop.NetworkManager."+
+		"Connection.Active.Dhcp4Config", &dhcp4); err != nil || dhcp4 == "/" {
+		return ""
+	}
+
+	// get wpad option
+	config := conn.Object("org.freedesktop.NetworkManager", dhcp4)
+	options := map[string]dbus.Variant{}
+	if err := config.StoreProperty("org.freedesktop.NetworkManager."+
+		"DHCP4Config.Options", &options); err != nil {
+		return ""
+	}
+	wpad, ok := options["wpad"].Value().(string)
+	if !ok {
+		return ""
+	}
+	return wpad
+}
+
+// getURLs returns the URLs of possible PAC files
+func getURLs() (urls []string) {
+	// try DHCP option first
+	if url := getDHCPURL(); url != "" {
+		urls = append(urls, url)
+	}
+
+	// try wpad host in search domains, from most to least specific
+	seen := make(map[string]bool)
+	for _, domain := range getSearchDomains() {
+		labels := strings.Split(strings.Trim(domain, "."), ".")
+		for i := 0; i < len(labels)-1; i++ {
+			host := "wpad." + strings.Join(labels[i:], ".")
+			if seen[host] {
+				continue
+			}
+			seen[host] = true
+			urls = append(urls, "http://"+host+"/"+wpadFile)
+		}
+	}
+	return
+}
+
+// parsePAC returns the first proxy in the PAC file contents in b
+func parsePAC(b []byte) string {
+	m := proxyRegexp.FindSubmatch(b)
+	if m == nil {
+		return ""
+	}
+	return "http://" + string(m[1])
+}
+
+// fetch retrieves the PAC file at url
+func (w *WPAD) fetch(url string) ([]byte, error) {
+	transport := &http.Transport{
+		Proxy: nil,
+	}
+	if w.dialer != nil {
+		transport.DialContext = w.dialer.DialContext
+	}
+	client := &http.Client{
+		Timeout:   httpTimeout * time.Second,
+		Transport: transport,
+	}
+	defer client.CloseIdleConnections()
+
+	ctx, cancel := context.WithTimeout(context.Background(),
+		httpTimeout*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &http.ProtocolError{ErrorString: resp.Status}
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxPACSize))
+}
+
+// check runs proxy auto-detection
+func (w *WPAD) check() *Report {
+	for _, url := range getURLs() {
+		b, err := w.fetch(url)
+		if err != nil {
+			log.WithError(err).WithField("url", url).
+				Debug("WPAD could not get PAC file")
+			continue
+		}
+		proxy := parsePAC(b)
+		if proxy == "" {
+			continue
+		}
+		return &Report{
+			Detected: true,
+			URL:      url,
+			Proxy:    proxy,
+		}
+	}
+	return &Report{}
+}
+
+// start starts the proxy auto-detection
+func (w *WPAD) start() {
+	defer close(w.reports)
+
+	// probe channels and function
+	probeReports := make(chan *Report)
+	probesDone := make(chan struct{})
+	probeFunc := func() {
+		r := w.check()
+		select {
+		case probeReports <- r:
+		case <-probesDone:
+		}
+	}
+
+	// are probes currently running or have to run again?
+	running := false
+	runAgain := false
+
+	for {
+		select {
+		case <-w.probes:
+			if running {
+				runAgain = true
+				break
+			}
+			running = true
+			go probeFunc()
+
+		case r := <-probeReports:
+			// send report, in the meantime read incoming probe
+			// requests and set the runAgain flag accordingly
+			sent := false
+			for !sent {
+				select {
+				case w.reports <- r:
+					sent = true
+				case <-w.probes:
+					runAgain = true
+				case <-w.done:
+					close(probesDone)
+					return
+				}
+			}
+
+			// trigger another probe if requested in the meantime
+			running = false
+			if runAgain {
+				runAgain = false
+				running = true
+				go probeFunc()
+			}
+
+		case <-w.done:
+			close(probesDone)
+			return
+		}
+	}
+}
+
+// Start starts the proxy auto-detection, it runs until Stop is called or ctx
+// is canceled
+func (w *WPAD) Start(ctx context.Context) error {
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.done = ctx.Done()
+	go w.start()
+	return nil
+}
+
+// Stop stops the proxy auto-detection
+func (w *WPAD) Stop() {
+	w.cancel()
+	for range w.reports {
+		// wait for channel shutdown
+	}
+}
+
+// SetDialer sets a custom dialer for the http requests
+func (w *WPAD) SetDialer(dialer *net.Dialer) {
+	w.dialer = dialer
+}
+
+// Probe triggers the proxy auto-detection
+func (w *WPAD) Probe() {
+	select {
+	case w.probes <- struct{}{}:
+	case <-w.done:
+	}
+}
+
+// Results returns the results channel
+func (w *WPAD) Results() chan *Report {
+	return w.reports
+}
+
+// NewWPAD returns a new WPAD
+func NewWPAD() *WPAD {
+	return &WPAD{
+		reports: make(chan *Report),
+		probes:  make(chan struct{}),
+	}
+}
@@ -0,0 +1,127 @@
+package wpad
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// TestGetSearchDomains tests getSearchDomains
+func TestGetSearchDomains(t *testing.T) {
+	// create temporary resolv.conf
+	f, err := os.CreateTemp("", "wpad-resolv-conf")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+	if _, err := f.WriteString("nameserver 127.0.0.53\n" +
+		"search a.example.com b.example.com\n"); err != nil {
+		log.Fatal(err)
+	}
+	old := resolvConf
+	resolvConf = f.Name()
+	defer func() { resolvConf = old }()
+
+	want := []string{"a.example.com", "b.example.com"}
+	got := getSearchDomains()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestGetURLs tests getURLs
+func TestGetURLs(t *testing.T) {
+	oldDHCP, oldSearch := getDHCPURL, getSearchDomains
+	defer func() { getDHCPURL, getSearchDomains = oldDHCP, oldSearch }()
+
+	getDHCPURL = func() string { return "http://dhcp.example.com/proxy.pac" }
+	getSearchDomains = func() []string {
+		return []string{"a.b.example.com", "b.example.com."}
+	}
+	want := []string{
+		"http://dhcp.example.com/proxy.pac",
+		"http://wpad.a.b.example.com/wpad.dat",
+		"http://wpad.b.example.com/wpad.dat",
+		"http://wpad.example.com/wpad.dat",
+	}
+	got := getURLs()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestParsePAC tests parsePAC
+func TestParsePAC(t *testing.T) {
+	for pac, want := range map[string]string{
+		"":                               "",
+		`return "DIRECT";`:               "",
+		`return "PROXY p:8080";`:         "http://p:8080",
+		`return "PROXY p:3128; DIRECT";`: "http://p:3128",
+	} {
+		got := parsePAC([]byte(pac))
+		if got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+}
+
+// TestWPADCheck tests check of WPAD
+func TestWPADCheck(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`function FindProxyForURL(url, host) {
+	return "PROXY proxy.example.com:8080";
+}`))
+	}))
+	defer ts.Close()
+
+	oldDHCP, oldSearch := getDHCPURL, getSearchDomains
+	defer func() { getDHCPURL, getSearchDomains = oldDHCP, oldSearch }()
+	getDHCPURL = func() string { return ts.URL }
+	getSearchDomains = func() []string { return nil }
+
+	w := NewWPAD()
+	want := &Report{
+		Detected: true,
+		URL:      ts.URL,
+		Proxy:    "http://proxy.example.com:8080",
+	}
+	got := w.check()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestWPADProbe tests Probe of WPAD
+func TestWPADProbe(t *testing.T) {
+	oldDHCP, oldSearch := getDHCPURL, getSearchDomains
+	defer func() { getDHCPURL, getSearchDomains = oldDHCP, oldSearch }()
+	getDHCPURL = func() string { return "" }
+	getSearchDomains = func() []string { return nil }
+
+	w := NewWPAD()
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	w.Probe()
+	want := &Report{}
+	got := <-w.Results()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	w.Stop()
+}
+
+// TestNewWPAD tests NewWPAD
+func TestNewWPAD(t *testing.T) {
+	w := NewWPAD()
+	if w.reports == nil ||
+		w.probes == nil {
+
+		t.Errorf("got nil, want != nil")
+	}
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Authentication failures of OpenConnect returned by Authenticate, use
+// errors.Is to check for them
+var (
+	ErrAuthCredentials       = errors.New("wrong username or password")
+	ErrAuthCertificate       = errors.New("client certificate rejected")
+	ErrAuthServerCertificate = errors.New("server certificate not trusted")
+	ErrAuthForm              = errors.New("could not parse login form")
+)
+
+// authFailures maps messages in the output of OpenConnect to authentication
+// failures, the server certificate messages are checked before the client
+// certificate messages since both mention certificates
+var authFailures = []struct {
+	err      error
+	messages []string
+}{
+	{ErrAuthServerCertificate, []string{
+		"server certificate verify failed",
+		"failed verification",
+		"certificate from vpn server",
+	}},
+	{ErrAuthCertificate, []string{
+		"client certificate",
+		"loading certificate failed",
+		"failed to load certificate",
+		"failed to load x509 certificate",
+		"failed to load private key",
+		"failed to open key",
+		"failed to open certificate",
+		"peer did not send any certificate",
+	}},
+	{ErrAuthCredentials, []string{
+		"login failed",
+		"authentication failed",
+		"invalid username or password",
+		"password incorrect",
+	}},
+	{ErrAuthForm, []string{
+		"failed to parse server response",
+		"failed to parse xml",
+		"unknown form",
+		"failed to handle form",
+		"no \"auth\" node",
+	}},
+}
+
+// AuthError is an authentication failure of OpenConnect, it wraps one of
+// the ErrAuth errors if the failure is known
+type AuthError struct {
+	// Err is the authentication failure, nil if it is unknown
+	Err error
+
+	// Message is the line in the output of OpenConnect that indicates
+	// the failure
+	Message string
+
+	// ExitErr is the error of the OpenConnect command
+	ExitErr error
+}
+
+// Error returns the error as string
+func (e *AuthError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("authentication failed: %v", e.ExitErr)
+	}
+	return fmt.Sprintf("authentication failed: %v: %s", e.Err, e.Message)
+}
+
+// Unwrap returns the authentication failure or the error of the OpenConnect
+// command if the failure is unknown
+func (e *AuthError) Unwrap() error {
+	if e.Err == nil {
+		return e.ExitErr
+	}
+	return e.Err
+}
+
+// classifyAuthError returns the AuthError for the error err of OpenConnect
+// with output, the last matching line of the output determines the failure
+func classifyAuthError(output string, err error) *AuthError {
+	authErr := &AuthError{ExitErr: err}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		lower := strings.ToLower(line)
+	failures:
+		for _, f := range authFailures {
+			for _, m := range f.messages {
+				if strings.Contains(lower, m) {
+					authErr.Err = f.err
+					authErr.Message = line
+					break failures
+				}
+			}
+		}
+	}
+	return authErr
+}
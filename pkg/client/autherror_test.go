@@ -0,0 +1,43 @@
+package client
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestClassifyAuthError tests classifyAuthError
+func TestClassifyAuthError(t *testing.T) {
+	exitErr := errors.New("exit status 1")
+	for _, test := range []struct {
+		output string
+		want   error
+	}{
+		{"", nil},
+		{"Connected to 10.0.0.1:443\nSomething went wrong\n", nil},
+		{"POST https://vpn.example.com/\nLogin failed.\n", ErrAuthCredentials},
+		{"Server certificate verify failed: signer not found\n",
+			ErrAuthServerCertificate},
+		{"Certificate from VPN server \"vpn.example.com\" failed verification.\n",
+			ErrAuthServerCertificate},
+		{"Loading certificate failed. Aborting.\n", ErrAuthCertificate},
+		{"SSL connection failure: The peer did not send any certificate.\n",
+			ErrAuthCertificate},
+		{"Failed to parse server response\n", ErrAuthForm},
+		// last matching line wins
+		{"Login failed.\nFailed to parse XML server response\n", ErrAuthForm},
+	} {
+		got := classifyAuthError(test.output, exitErr)
+		if got.Err != test.want {
+			t.Errorf("%q: got %v, want %v", test.output, got.Err, test.want)
+		}
+		if test.want != nil && !errors.Is(got, test.want) {
+			t.Errorf("%q: error should be %v", test.output, test.want)
+		}
+		if test.want == nil && !errors.Is(got, exitErr) {
+			t.Errorf("%q: error should be exit error", test.output)
+		}
+		if got.Error() == "" {
+			t.Errorf("%q: error message should not be empty", test.output)
+		}
+	}
+}
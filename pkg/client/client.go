@@ -3,17 +3,25 @@ package client
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"os/exec"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/godbus/dbus/v5"
+	log "github.com/sirupsen/logrus"
 	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
+	"github.com/telekom-mms/oc-daemon/internal/metrics"
 	"github.com/telekom-mms/oc-daemon/pkg/logininfo"
 	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
 	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+	"github.com/telekom-mms/oc-daemon/pkg/xmlprofile"
 )
 
 // Client is an OC-Daemon client
@@ -33,7 +41,29 @@ type Client interface {
 
 	Authenticate() error
 	Connect() error
+	ConnectCached() error
+	Reconnect() error
+	ConnectDevice() (*DeviceCode, error)
 	Disconnect() error
+	Cancel() error
+	Pause(timeout time.Duration) error
+	Resume() error
+
+	SetTND(enabled bool, timeout time.Duration) error
+	SetTrafPol(enabled bool, timeout time.Duration) error
+	GetLogs(lines int) ([]string, error)
+	ListServers(ping bool) ([]*Server, error)
+	ReloadProfile() error
+	SetPreferredServer(server string) error
+	AddSplitExclude(address string) error
+	RemoveSplitExclude(address string) error
+	ListSplitExcludes() ([]string, error)
+	ValidateConfig(kind string, data []byte) ([]*Finding, error)
+	SetNotificationPolicy(policy *NotificationPolicy) error
+	GetUsage() ([]*Usage, error)
+	GetMetrics() ([]*Metrics, error)
+	GetStatus() (*vpnstatus.Status, error)
+	GetCapabilities() (*Capabilities, error)
 
 	Close() error
 }
@@ -56,6 +86,14 @@ type DBusClient struct {
 	// by successful authentication
 	login *logininfo.LoginInfo
 
+	// proxy is the proxy used during authentication, detected by the
+	// daemon
+	proxy string
+
+	// csdWrapper is the CSD wrapper used for hostscan during
+	// authentication, configured in the daemon
+	csdWrapper string
+
 	// subscribed specifies whether the client is subscribed to
 	// PropertiesChanged D-Bus signals
 	subscribed bool
@@ -115,6 +153,38 @@ func (d *DBusClient) GetLogin() *logininfo.LoginInfo {
 	return d.login.Copy()
 }
 
+// setProxy sets the proxy used during authentication
+func (d *DBusClient) setProxy(proxy string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.proxy = proxy
+}
+
+// getProxy returns the proxy used during authentication
+func (d *DBusClient) getProxy() string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.proxy
+}
+
+// setCSDWrapper sets the CSD wrapper used during authentication
+func (d *DBusClient) setCSDWrapper(wrapper string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.csdWrapper = wrapper
+}
+
+// getCSDWrapper returns the CSD wrapper used during authentication
+func (d *DBusClient) getCSDWrapper() string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.csdWrapper
+}
+
 // dbusConnectSystemBus calls dbus.ConnectSystemBus
 var dbusConnectSystemBus = func() (*dbus.Conn, error) {
 	return dbus.ConnectSystemBus()
@@ -144,6 +214,58 @@ func updateStatusFromProperties(status *vpnstatus.Status, props map[string]dbus.
 				err = v.Store(&dest.Servers)
 			case dbusapi.PropertyOCRunning:
 				err = v.Store(&dest.OCRunning)
+			case dbusapi.PropertyProxy:
+				err = v.Store(&dest.Proxy)
+			case dbusapi.PropertyRetryAt:
+				err = v.Store(&dest.RetryAt)
+			case dbusapi.PropertyRetryAttempt:
+				err = v.Store(&dest.RetryAttempt)
+			case dbusapi.PropertyRXBytes:
+				err = v.Store(&dest.RXBytes)
+			case dbusapi.PropertyTXBytes:
+				err = v.Store(&dest.TXBytes)
+			case dbusapi.PropertyRXPackets:
+				err = v.Store(&dest.RXPackets)
+			case dbusapi.PropertyTXPackets:
+				err = v.Store(&dest.TXPackets)
+			case dbusapi.PropertyDNSLeaksBlocked:
+				err = v.Store(&dest.DNSLeaksBlocked)
+			case dbusapi.PropertyDNSServers:
+				err = v.Store(&dest.DNSServers)
+			case dbusapi.PropertyDNSSearchDomains:
+				err = v.Store(&dest.DNSSearchDomains)
+			case dbusapi.PropertyDNSSplitDomains:
+				err = v.Store(&dest.DNSSplitDomains)
+			case dbusapi.PropertyScheduleState:
+				err = v.Store(&dest.ScheduleState)
+			case dbusapi.PropertyConnectivity:
+				err = v.Store(&dest.Connectivity)
+			case dbusapi.PropertyCompression:
+				err = v.Store(&dest.Compression)
+			case dbusapi.PropertyTNDState:
+				err = v.Store(&dest.TNDState)
+			case dbusapi.PropertyCSDWrapper:
+				err = v.Store(&dest.CSDWrapper)
+			case dbusapi.PropertyDisconnectReason:
+				err = v.Store(&dest.DisconnectReason)
+			case dbusapi.PropertyAPIs:
+				err = v.Store(&dest.APIs)
+			case dbusapi.PropertyRouteAggregations:
+				err = v.Store(&dest.RouteAggregations)
+			case dbusapi.PropertyGatewayExcludes:
+				err = v.Store(&dest.GatewayExcludes)
+			case dbusapi.PropertyTrafPolState:
+				err = v.Store(&dest.TrafPolState)
+			case dbusapi.PropertyPreferredServer:
+				err = v.Store(&dest.PreferredServer)
+			case dbusapi.PropertyUptime:
+				err = v.Store(&dest.Uptime)
+			case dbusapi.PropertyOwnerUID:
+				err = v.Store(&dest.OwnerUID)
+			case dbusapi.PropertyOwner:
+				err = v.Store(&dest.Owner)
+			case dbusapi.PropertyPauseState:
+				err = v.Store(&dest.PauseState)
 			case dbusapi.PropertyVPNConfig:
 				s := dbusapi.VPNConfigInvalid
 				if err := v.Store(&s); err != nil {
@@ -193,7 +315,48 @@ var query = func(d *DBusClient) (map[string]dbus.Variant, error) {
 	return props, nil
 }
 
-// Query retrieves the VPN status
+// queryTunnel retrieves the D-Bus properties of the additional tunnel from
+// the daemon
+var queryTunnel = func(d *DBusClient, tunnel string) (map[string]dbus.Variant, error) {
+	props := make(map[string]dbus.Variant)
+	if err := d.conn.Object(dbusapi.Interface, dbusapi.TunnelPath(tunnel)).
+		Call("org.freedesktop.DBus.Properties.GetAll", 0, dbusapi.Interface).
+		Store(props); err != nil {
+		return nil, err
+	}
+	return props, nil
+}
+
+// daemonProperties are the properties of the daemon that are also used in the
+// status of additional tunnels, the other properties belong to the main VPN
+// connection
+var daemonProperties = map[string]bool{
+	dbusapi.PropertyTrustedNetwork: true,
+	dbusapi.PropertyProxy:          true,
+}
+
+// filterDaemonProperties returns the daemon properties in props
+func filterDaemonProperties(props map[string]dbus.Variant) map[string]dbus.Variant {
+	filtered := make(map[string]dbus.Variant)
+	for k, v := range props {
+		if daemonProperties[k] {
+			filtered[k] = v
+		}
+	}
+	return filtered
+}
+
+// getTunnel returns the additional tunnel in the client config, empty for
+// the main VPN connection
+func (d *DBusClient) getTunnel() string {
+	if config := d.GetConfig(); config != nil {
+		return config.Tunnel
+	}
+	return ""
+}
+
+// Query retrieves the VPN status, the status of the additional tunnel if it
+// is set in the client config
 func (d *DBusClient) Query() (*vpnstatus.Status, error) {
 	// get properties
 	props, err := query(d)
@@ -201,6 +364,18 @@ func (d *DBusClient) Query() (*vpnstatus.Status, error) {
 		return nil, err
 	}
 
+	// get properties of additional tunnel
+	if tunnel := d.getTunnel(); tunnel != "" {
+		props = filterDaemonProperties(props)
+		tprops, err := queryTunnel(d, tunnel)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range tprops {
+			props[k] = v
+		}
+	}
+
 	// get status from properties
 	status := vpnstatus.New()
 	if err := updateStatusFromProperties(status, props); err != nil {
@@ -211,11 +386,21 @@ func (d *DBusClient) Query() (*vpnstatus.Status, error) {
 	return status, nil
 }
 
-// handlePropertiesChanged handles a PropertiesChanged D-Bus signal
-func handlePropertiesChanged(s *dbus.Signal, status *vpnstatus.Status) *vpnstatus.Status {
+// handlePropertiesChanged handles a PropertiesChanged D-Bus signal, of the
+// daemon and the additional tunnel if tunnel is not empty
+func handlePropertiesChanged(s *dbus.Signal, status *vpnstatus.Status, tunnel string) *vpnstatus.Status {
 	// make sure it's a properties changed signal
-	if s.Path != dbusapi.Path ||
-		s.Name != "org.freedesktop.DBus.Properties.PropertiesChanged" {
+	if s.Name != "org.freedesktop.DBus.Properties.PropertiesChanged" {
+		return nil
+	}
+
+	// only use daemon properties of the daemon for additional tunnels
+	daemonOnly := false
+	switch {
+	case s.Path == dbusapi.Path:
+		daemonOnly = tunnel != ""
+	case tunnel != "" && s.Path == dbusapi.TunnelPath(tunnel):
+	default:
 		return nil
 	}
 
@@ -229,6 +414,9 @@ func handlePropertiesChanged(s *dbus.Signal, status *vpnstatus.Status) *vpnstatu
 	if !ok {
 		return nil
 	}
+	if daemonOnly {
+		changed = filterDaemonProperties(changed)
+	}
 
 	err := updateStatusFromProperties(status, changed)
 	if err != nil {
@@ -241,6 +429,10 @@ func handlePropertiesChanged(s *dbus.Signal, status *vpnstatus.Status) *vpnstatu
 		return nil
 	}
 	for _, name := range invalid {
+		if daemonOnly && !daemonProperties[name] {
+			continue
+		}
+
 		// not expected to happen currently, but handle it anyway
 		switch name {
 		case dbusapi.PropertyTrustedNetwork:
@@ -259,6 +451,58 @@ func handlePropertiesChanged(s *dbus.Signal, status *vpnstatus.Status) *vpnstatu
 			status.OCRunning = vpnstatus.OCRunningUnknown
 		case dbusapi.PropertyVPNConfig:
 			status.VPNConfig = nil
+		case dbusapi.PropertyProxy:
+			status.Proxy = dbusapi.ProxyInvalid
+		case dbusapi.PropertyRetryAt:
+			status.RetryAt = dbusapi.RetryAtInvalid
+		case dbusapi.PropertyRetryAttempt:
+			status.RetryAttempt = dbusapi.RetryAttemptInvalid
+		case dbusapi.PropertyRXBytes:
+			status.RXBytes = dbusapi.TrafficStatsInvalid
+		case dbusapi.PropertyTXBytes:
+			status.TXBytes = dbusapi.TrafficStatsInvalid
+		case dbusapi.PropertyRXPackets:
+			status.RXPackets = dbusapi.TrafficStatsInvalid
+		case dbusapi.PropertyTXPackets:
+			status.TXPackets = dbusapi.TrafficStatsInvalid
+		case dbusapi.PropertyDNSLeaksBlocked:
+			status.DNSLeaksBlocked = dbusapi.DNSLeaksBlockedInvalid
+		case dbusapi.PropertyDNSServers:
+			status.DNSServers = dbusapi.DNSDomainsInvalid
+		case dbusapi.PropertyDNSSearchDomains:
+			status.DNSSearchDomains = dbusapi.DNSDomainsInvalid
+		case dbusapi.PropertyDNSSplitDomains:
+			status.DNSSplitDomains = dbusapi.DNSDomainsInvalid
+		case dbusapi.PropertyScheduleState:
+			status.ScheduleState = vpnstatus.ScheduleStateUnknown
+		case dbusapi.PropertyConnectivity:
+			status.Connectivity = vpnstatus.ConnectivityUnknown
+		case dbusapi.PropertyCompression:
+			status.Compression = dbusapi.CompressionInvalid
+		case dbusapi.PropertyTNDState:
+			status.TNDState = vpnstatus.TNDStateUnknown
+		case dbusapi.PropertyCSDWrapper:
+			status.CSDWrapper = dbusapi.CSDWrapperInvalid
+		case dbusapi.PropertyDisconnectReason:
+			status.DisconnectReason = ""
+		case dbusapi.PropertyAPIs:
+			status.APIs = dbusapi.APIsInvalid
+		case dbusapi.PropertyRouteAggregations:
+			status.RouteAggregations = dbusapi.RouteAggregationsInvalid
+		case dbusapi.PropertyGatewayExcludes:
+			status.GatewayExcludes = dbusapi.GatewayExcludesInvalid
+		case dbusapi.PropertyTrafPolState:
+			status.TrafPolState = vpnstatus.TrafPolStateUnknown
+		case dbusapi.PropertyPreferredServer:
+			status.PreferredServer = ""
+		case dbusapi.PropertyUptime:
+			status.Uptime = dbusapi.UptimeInvalid
+		case dbusapi.PropertyOwnerUID:
+			status.OwnerUID = dbusapi.OwnerUIDInvalid
+		case dbusapi.PropertyOwner:
+			status.Owner = dbusapi.OwnerInvalid
+		case dbusapi.PropertyPauseState:
+			status.PauseState = vpnstatus.PauseStateUnknown
 		}
 	}
 
@@ -329,7 +573,7 @@ func (d *DBusClient) Subscribe() (chan *vpnstatus.Status, error) {
 		// handle signals
 		for s := range c {
 			// get status update from signal
-			update := handlePropertiesChanged(s, status.Copy())
+			update := handlePropertiesChanged(s, status.Copy(), d.getTunnel())
 			if update == nil {
 				// invalid update
 				continue
@@ -351,24 +595,35 @@ func (d *DBusClient) Subscribe() (chan *vpnstatus.Status, error) {
 }
 
 // checkStatus checks if client is not connected to a trusted network and the
-// VPN is not already running
-func (d *DBusClient) checkStatus() error {
+// VPN is not already running, returns the current status; trusted networks
+// do not apply to additional tunnels
+func (d *DBusClient) checkStatus() (*vpnstatus.Status, error) {
 	status, err := d.Query()
 	if err != nil {
-		return fmt.Errorf("could not query OC-Daemon: %w", err)
+		return nil, fmt.Errorf("could not query OC-Daemon: %w", err)
 	}
 
 	// check if we need to start the VPN connection
-	if status.TrustedNetwork.Trusted() {
-		return fmt.Errorf("trusted network detected, nothing to do")
+	if status.TrustedNetwork.Trusted() && d.getTunnel() == "" {
+		return nil, fmt.Errorf("trusted network detected, nothing to do")
 	}
 	if status.ConnectionState.Connected() {
-		return fmt.Errorf("VPN already connected, nothing to do")
+		return nil, fmt.Errorf("VPN already connected, nothing to do")
 	}
 	if status.OCRunning.Running() {
-		return fmt.Errorf("OpenConnect client already running, nothing to do")
+		return nil, fmt.Errorf("OpenConnect client already running, nothing to do")
 	}
-	return nil
+	if status.Connectivity.Offline() {
+		return nil, ErrOffline
+	}
+	return status, nil
+}
+
+// reportHostscan reports to the daemon that the csd wrapper ran, the daemon
+// reads the hostscan report of the wrapper itself
+var reportHostscan = func(d *DBusClient) error {
+	return d.conn.Object(dbusapi.Interface, dbusapi.Path).
+		Call(dbusapi.MethodReportHostscan, 0).Store()
 }
 
 // authenticate runs OpenConnect in authentication mode
@@ -381,6 +636,8 @@ var authenticate = func(d *DBusClient) error {
 	//   --sslkey="$PRIVATE_KEY" \
 	//   --cafile="$CA_CERT" \
 	//   --xmlconfig="$XML_CONFIG" \
+	//   --gnutls-priority="$TLS_PRIORITY" \
+	//   --csd-wrapper="$CSD_WRAPPER" \
 	//   --authenticate \
 	//   --quiet \
 	//   "$SERVER"
@@ -390,7 +647,15 @@ var authenticate = func(d *DBusClient) error {
 	sslKey := fmt.Sprintf("--sslkey=%s", config.ClientKey)
 	caFile := fmt.Sprintf("--cafile=%s", config.CACertificate)
 	xmlConfig := fmt.Sprintf("--xmlconfig=%s", config.XMLProfile)
+	if config.Profile != "" {
+		xmlConfig = fmt.Sprintf("--xmlconfig=%s", xmlprofile.ProfilePath(
+			xmlprofile.ProfilesDir, config.XMLProfile, config.Profile))
+	}
 	user := fmt.Sprintf("--user=%s", config.User)
+	priority, err := tlsPriority(config)
+	if err != nil {
+		return err
+	}
 
 	parameters := []string{
 		"--protocol=anyconnect",
@@ -399,11 +664,24 @@ var authenticate = func(d *DBusClient) error {
 		xmlConfig,
 		"--authenticate",
 		"--quiet",
-		"--no-proxy",
+	}
+	if proxy := d.getProxy(); proxy != "" {
+		parameters = append(parameters, fmt.Sprintf("--proxy=%s", proxy))
+	} else {
+		parameters = append(parameters, "--no-proxy")
 	}
 	if config.CACertificate != "" {
 		parameters = append(parameters, caFile)
 	}
+	if priority != "" {
+		parameters = append(parameters,
+			fmt.Sprintf("--gnutls-priority=%s", priority))
+	}
+	csdWrapper := d.getCSDWrapper()
+	if csdWrapper != "" {
+		parameters = append(parameters,
+			fmt.Sprintf("--csd-wrapper=%s", csdWrapper))
+	}
 	if config.User != "" {
 		parameters = append(parameters, user)
 	}
@@ -416,19 +694,32 @@ var authenticate = func(d *DBusClient) error {
 
 	command := exec.Command("openconnect", parameters...)
 
-	// run command: allow user input, show stderr, buffer stdout
-	var b bytes.Buffer
+	// run command: allow user input, show stderr, buffer stdout; also
+	// buffer stderr with the error messages
+	var b, errOutput bytes.Buffer
 	command.Stdin = os.Stdin
 	if config.Password != "" {
 		// disable user input, pass password via stdin
 		command.Stdin = bytes.NewBufferString(config.Password)
 	}
 	command.Stdout = &b
-	command.Stderr = os.Stderr
+	command.Stderr = io.MultiWriter(os.Stderr, &errOutput)
 	command.Env = append(os.Environ(), d.GetEnv()...)
 	if err := command.Run(); err != nil {
-		// TODO: handle failed program start?
-		return err
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			// program did not start
+			return err
+		}
+		return classifyAuthError(errOutput.String(), err)
+	}
+
+	// report hostscan to the daemon for the audit log, failures do not
+	// affect the successful authentication
+	if csdWrapper != "" {
+		if err := reportHostscan(d); err != nil {
+			log.WithError(err).Warn("Client could not report hostscan")
+		}
 	}
 
 	// parse login info, cookie from command line in buffer:
@@ -452,18 +743,55 @@ var authenticate = func(d *DBusClient) error {
 // Authenticate authenticates the client on the VPN server
 func (d *DBusClient) Authenticate() error {
 	// check status
-	if err := d.checkStatus(); err != nil {
+	status, err := d.checkStatus()
+	if err != nil {
 		return err
 	}
 
+	// use proxy detected by the daemon?
+	d.setProxy("")
+	if config := d.GetConfig(); config != nil && config.AutoProxy {
+		d.setProxy(status.Proxy)
+	}
+
+	// use csd wrapper configured in the daemon
+	d.setCSDWrapper(status.CSDWrapper)
+
 	// authenticate
 	return authenticate(d)
 }
 
 // connect sends a connect request with login info to the daemon
 var connect = func(d *DBusClient) error {
-	// call connect
+	// call connect of additional tunnel
 	login := d.GetLogin()
+	if config := d.GetConfig(); config.Tunnel != "" {
+		return d.conn.Object(dbusapi.Interface, dbusapi.Path).
+			Call(dbusapi.MethodConnectTunnel, 0,
+				config.Tunnel,
+				config.Profile,
+				login.Cookie,
+				login.Host,
+				login.ConnectURL,
+				login.Fingerprint,
+				login.Resolve,
+			).Store()
+	}
+
+	// call connect with profile
+	if config := d.GetConfig(); config.Profile != "" {
+		return d.conn.Object(dbusapi.Interface, dbusapi.Path).
+			Call(dbusapi.MethodConnectProfile, 0,
+				config.Profile,
+				login.Cookie,
+				login.Host,
+				login.ConnectURL,
+				login.Fingerprint,
+				login.Resolve,
+			).Store()
+	}
+
+	// call connect
 	return d.conn.Object(dbusapi.Interface, dbusapi.Path).
 		Call(dbusapi.MethodConnect, 0,
 			login.Cookie,
@@ -478,16 +806,155 @@ var connect = func(d *DBusClient) error {
 // authentication with Authenticate
 func (d *DBusClient) Connect() error {
 	// check status
-	if err := d.checkStatus(); err != nil {
+	if _, err := d.checkStatus(); err != nil {
 		return err
 	}
 
 	// send login info to daemon
-	return connect(d)
+	err := connect(d)
+	if e, ok := toDBusError(err); ok && e.Name == dbusapi.ErrorOffline {
+		return ErrOffline
+	}
+	return err
+}
+
+// connectCached sends a connect request without login info to the daemon
+var connectCached = func(d *DBusClient) error {
+	return d.conn.Object(dbusapi.Interface, dbusapi.Path).
+		Call(dbusapi.MethodConnectCached, 0).Store()
+}
+
+// ConnectCached connects the client with the VPN server using the login
+// info of the user's last connection cached in the daemon, it does not
+// require authentication with Authenticate but fails if the daemon has no
+// login info of the user or the VPN server rejects it
+func (d *DBusClient) ConnectCached() error {
+	// check status
+	if _, err := d.checkStatus(); err != nil {
+		return err
+	}
+
+	// connect with login info in daemon
+	err := connectCached(d)
+	if e, ok := toDBusError(err); ok && e.Name == dbusapi.ErrorOffline {
+		return ErrOffline
+	}
+	return err
+}
+
+// reconnect sends a reconnect request to the daemon
+var reconnect = func(d *DBusClient) error {
+	return d.conn.Object(dbusapi.Interface, dbusapi.Path).
+		Call(dbusapi.MethodReconnect, 0).Store()
+}
+
+// Reconnect reconnects the VPN: the daemon disconnects the VPN, waits for
+// the teardown and connects again with the login info of the user's last
+// connection; it returns when the VPN is connected or the reconnect failed,
+// ErrReauthRequired if the user has to authenticate again with Authenticate
+// and connect with Connect
+func (d *DBusClient) Reconnect() error {
+	err := reconnect(d)
+	if e, ok := toDBusError(err); ok {
+		switch e.Name {
+		case dbusapi.ErrorOffline:
+			return ErrOffline
+		case dbusapi.ErrorReauthRequired:
+			return ErrReauthRequired
+		}
+	}
+	return err
+}
+
+// ErrOffline is returned if the host is offline, i.e., there is no network
+// device with carrier and default route
+var ErrOffline = dbusapi.ErrOffline
+
+// ErrReauthRequired is returned by Reconnect if the user has to
+// authenticate again, e.g., because the VPN server rejected the login info
+// of the last connection
+var ErrReauthRequired = dbusapi.ErrReauthRequired
+
+// toDBusError returns err as D-Bus error and whether err is a D-Bus error
+func toDBusError(err error) (dbus.Error, bool) {
+	switch e := err.(type) {
+	case dbus.Error:
+		return e, true
+	case *dbus.Error:
+		if e != nil {
+			return *e, true
+		}
+	}
+	return dbus.Error{}, false
+}
+
+// RetryAfter returns the time after which a connect request rejected by the
+// rate limiting of the daemon with err can be retried, and whether err is
+// such a rejection
+func RetryAfter(err error) (time.Duration, bool) {
+	dbusErr, ok := toDBusError(err)
+	if !ok || dbusErr.Name != dbusapi.ErrorTooManyRequests ||
+		len(dbusErr.Body) < 2 {
+		return 0, false
+	}
+	seconds, ok := dbusErr.Body[1].(uint32)
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// DeviceCode is the user code and verification URIs of a VPN connect with
+// device authorization, the user approves the login with them on another
+// device, e.g., a phone
+type DeviceCode struct {
+	UserCode                string
+	VerificationURI         string
+	VerificationURIComplete string
+}
+
+// connectDevice sends a connect request with device authorization to the
+// daemon
+var connectDevice = func(d *DBusClient) (*DeviceCode, error) {
+	config := d.GetConfig()
+	code := &DeviceCode{}
+	err := d.conn.Object(dbusapi.Interface, dbusapi.Path).
+		Call(dbusapi.MethodConnectDevice, 0,
+			config.Profile,
+			config.VPNServer,
+		).Store(&code.UserCode, &code.VerificationURI,
+		&code.VerificationURIComplete)
+	if err != nil {
+		return nil, err
+	}
+	return code, nil
+}
+
+// ConnectDevice connects the client with the VPN server using the device
+// authorization of the daemon instead of Authenticate; it returns the device
+// code the user needs to approve the login, the daemon connects the VPN
+// after approval
+func (d *DBusClient) ConnectDevice() (*DeviceCode, error) {
+	// check status
+	if _, err := d.checkStatus(); err != nil {
+		return nil, err
+	}
+	if d.getTunnel() != "" {
+		return nil, fmt.Errorf("device authorization not supported for tunnels")
+	}
+
+	// send connect request to daemon
+	return connectDevice(d)
 }
 
 // disconnect sends a disconnect request to the daemon
 var disconnect = func(d *DBusClient) error {
+	// call disconnect of additional tunnel
+	if tunnel := d.getTunnel(); tunnel != "" {
+		return d.conn.Object(dbusapi.Interface, dbusapi.Path).
+			Call(dbusapi.MethodDisconnectTunnel, 0, tunnel).Store()
+	}
+
 	// call connect
 	return d.conn.Object(dbusapi.Interface, dbusapi.Path).
 		Call(dbusapi.MethodDisconnect, 0).Store()
@@ -508,6 +975,479 @@ func (d *DBusClient) Disconnect() error {
 	return disconnect(d)
 }
 
+// setTND sends a request to enable or disable trusted network detection to
+// the daemon
+var setTND = func(d *DBusClient, enabled bool, timeout uint32) error {
+	return d.conn.Object(dbusapi.Interface, dbusapi.Path).
+		Call(dbusapi.MethodSetTND, 0, enabled, timeout).Store()
+}
+
+// SetTND enables or disables the trusted network detection of the daemon,
+// a disabled trusted network detection is enabled again after timeout if
+// timeout is not 0; this requires root privileges
+func (d *DBusClient) SetTND(enabled bool, timeout time.Duration) error {
+	if timeout < 0 || timeout > math.MaxUint32*time.Second {
+		return fmt.Errorf("invalid timeout: %s", timeout)
+	}
+	seconds := uint32((timeout + time.Second - 1) / time.Second)
+	return setTND(d, enabled, seconds)
+}
+
+// setTrafPol sends a request to enable or disable traffic policing to the
+// daemon
+var setTrafPol = func(d *DBusClient, enabled bool, timeout uint32) error {
+	return d.conn.Object(dbusapi.Interface, dbusapi.Path).
+		Call(dbusapi.MethodSetTrafPol, 0, enabled, timeout).Store()
+}
+
+// SetTrafPol enables or disables the traffic policing of the daemon, a
+// disabled traffic policing is enabled again after timeout if timeout is
+// not 0; this requires root privileges
+func (d *DBusClient) SetTrafPol(enabled bool, timeout time.Duration) error {
+	if timeout < 0 || timeout > math.MaxUint32*time.Second {
+		return fmt.Errorf("invalid timeout: %s", timeout)
+	}
+	seconds := uint32((timeout + time.Second - 1) / time.Second)
+	return setTrafPol(d, enabled, seconds)
+}
+
+// pause sends a request to pause the VPN connection to the daemon
+var pause = func(d *DBusClient, timeout uint32) error {
+	return d.conn.Object(dbusapi.Interface, dbusapi.Path).
+		Call(dbusapi.MethodPause, 0, timeout).Store()
+}
+
+// Pause pauses the VPN connection of the daemon: the connection stays
+// established but split routing and DNS are removed, so all traffic
+// bypasses the VPN; the connection is resumed after timeout if timeout is
+// not 0
+func (d *DBusClient) Pause(timeout time.Duration) error {
+	if timeout < 0 || timeout > math.MaxUint32*time.Second {
+		return fmt.Errorf("invalid timeout: %s", timeout)
+	}
+	seconds := uint32((timeout + time.Second - 1) / time.Second)
+	return pause(d, seconds)
+}
+
+// resume sends a request to resume the paused VPN connection to the daemon
+var resume = func(d *DBusClient) error {
+	return d.conn.Object(dbusapi.Interface, dbusapi.Path).
+		Call(dbusapi.MethodResume, 0).Store()
+}
+
+// Resume resumes the paused VPN connection of the daemon and restores split
+// routing and DNS
+func (d *DBusClient) Resume() error {
+	return resume(d)
+}
+
+// getLogs requests the last lines entries of the recent log from the
+// daemon
+var getLogs = func(d *DBusClient, lines uint32) ([]string, error) {
+	logs := []string{}
+	err := d.conn.Object(dbusapi.Interface, dbusapi.Path).
+		Call(dbusapi.MethodGetLogs, 0, lines).Store(&logs)
+	return logs, err
+}
+
+// GetLogs returns the last lines entries of the recent log of the daemon,
+// all recent entries if lines is 0
+func (d *DBusClient) GetLogs(lines int) ([]string, error) {
+	if lines < 0 || int64(lines) > math.MaxUint32 {
+		return nil, fmt.Errorf("invalid number of lines: %d", lines)
+	}
+	return getLogs(d, uint32(lines))
+}
+
+// Capabilities of the daemon, see Capabilities
+const (
+	CapabilityMultiTunnel        = dbusapi.CapabilityMultiTunnel
+	CapabilityStats              = dbusapi.CapabilityStats
+	CapabilityDeviceAuth         = dbusapi.CapabilityDeviceAuth
+	CapabilityDNSProxy           = dbusapi.CapabilityDNSProxy
+	CapabilityTrafPol            = dbusapi.CapabilityTrafPol
+	CapabilityPreferredServer    = dbusapi.CapabilityPreferredServer
+	CapabilityCancel             = dbusapi.CapabilityCancel
+	CapabilityConnectionObjects  = dbusapi.CapabilityConnectionObjects
+	CapabilitySplitExcludes      = dbusapi.CapabilitySplitExcludes
+	CapabilityConnectCached      = dbusapi.CapabilityConnectCached
+	CapabilityPause              = dbusapi.CapabilityPause
+	CapabilityReconnect          = dbusapi.CapabilityReconnect
+	CapabilityValidateConfig     = dbusapi.CapabilityValidateConfig
+	CapabilityNotificationPolicy = dbusapi.CapabilityNotificationPolicy
+	CapabilityUsage              = dbusapi.CapabilityUsage
+	CapabilityMetrics            = dbusapi.CapabilityMetrics
+)
+
+// Capabilities are the D-Bus API version and the optional features of the
+// daemon, Version is 0 for daemons without versioned API
+type Capabilities struct {
+	Version      uint32
+	Capabilities []string
+}
+
+// Has returns whether the daemon has capability
+func (c *Capabilities) Has(capability string) bool {
+	for _, have := range c.Capabilities {
+		if have == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCapabilities returns the D-Bus API version and capabilities of the
+// daemon, so clients can detect features of the daemon
+func (d *DBusClient) GetCapabilities() (*Capabilities, error) {
+	props, err := query(d)
+	if err != nil {
+		return nil, err
+	}
+	caps := &Capabilities{}
+	if v, ok := props[dbusapi.PropertyVersion]; ok {
+		if err := v.Store(&caps.Version); err != nil {
+			return nil, err
+		}
+	}
+	if v, ok := props[dbusapi.PropertyCapabilities]; ok {
+		if err := v.Store(&caps.Capabilities); err != nil {
+			return nil, err
+		}
+	}
+	return caps, nil
+}
+
+// Server is a VPN server in the XML profile of the daemon, Reachable and
+// Latency are only set if the server was pinged
+type Server struct {
+	Name      string
+	Address   string
+	Reachable bool
+	Latency   time.Duration
+}
+
+// listServers requests the VPN servers in the XML profile from the daemon
+var listServers = func(d *DBusClient, ping bool) ([]dbusapi.Server, error) {
+	servers := []dbusapi.Server{}
+	err := d.conn.Object(dbusapi.Interface, dbusapi.Path).
+		Call(dbusapi.MethodListServers, 0, ping).Store(&servers)
+	return servers, err
+}
+
+// ListServers returns the VPN servers in the XML profile of the daemon, if
+// ping is set, the daemon probes their reachability and latency
+func (d *DBusClient) ListServers(ping bool) ([]*Server, error) {
+	servers, err := listServers(d, ping)
+	if err != nil {
+		return nil, err
+	}
+	list := []*Server{}
+	for _, s := range servers {
+		list = append(list, &Server{
+			Name:      s.Name,
+			Address:   s.Address,
+			Reachable: s.Reachable,
+			Latency:   time.Duration(s.Latency) * time.Microsecond,
+		})
+	}
+	return list, nil
+}
+
+// Usage is the cumulative VPN usage of the daemon in a month, e.g.,
+// "2026-10", in local time of the daemon
+type Usage struct {
+	Month       string
+	RXBytes     uint64
+	TXBytes     uint64
+	Connections uint64
+	Connected   time.Duration
+}
+
+// getUsage requests the VPN usage per month from the daemon
+var getUsage = func(d *DBusClient) ([]dbusapi.Usage, error) {
+	usage := []dbusapi.Usage{}
+	err := d.conn.Object(dbusapi.Interface, dbusapi.Path).
+		Call(dbusapi.MethodGetUsage, 0).Store(&usage)
+	return usage, err
+}
+
+// GetUsage returns the VPN usage of the recent months of the daemon in
+// ascending order, it is kept across restarts of the daemon
+func (d *DBusClient) GetUsage() ([]*Usage, error) {
+	usage, err := getUsage(d)
+	if err != nil {
+		return nil, err
+	}
+	list := []*Usage{}
+	for _, u := range usage {
+		list = append(list, &Usage{
+			Month:       u.Month,
+			RXBytes:     u.RXBytes,
+			TXBytes:     u.TXBytes,
+			Connections: u.Connections,
+			Connected:   time.Duration(u.ConnectedSeconds) * time.Second,
+		})
+	}
+	return list, nil
+}
+
+// MetricsLatencyBuckets are the upper bounds of the latency histogram
+// buckets in Metrics, the last bucket counts the requests above the last
+// bound
+var MetricsLatencyBuckets = metrics.LatencyBuckets
+
+// Metrics are the metrics of a request type of an API of the daemon, e.g.,
+// "Connect" of the "dbus" API, since the start of the daemon: the number of
+// requests, the number of failed requests, the sum of their latencies and
+// the number of requests per bucket in MetricsLatencyBuckets
+type Metrics struct {
+	API            string
+	Request        string
+	Count          uint64
+	Errors         uint64
+	Latency        time.Duration
+	LatencyBuckets []uint64
+}
+
+// getMetrics requests the metrics of the API requests from the daemon
+var getMetrics = func(d *DBusClient) ([]dbusapi.RequestMetrics, error) {
+	metrics := []dbusapi.RequestMetrics{}
+	err := d.conn.Object(dbusapi.Interface, dbusapi.Path).
+		Call(dbusapi.MethodGetMetrics, 0).Store(&metrics)
+	return metrics, err
+}
+
+// GetMetrics returns the metrics of the API requests of the daemon sorted
+// by API and request type
+func (d *DBusClient) GetMetrics() ([]*Metrics, error) {
+	metrics, err := getMetrics(d)
+	if err != nil {
+		return nil, err
+	}
+	list := []*Metrics{}
+	for _, m := range metrics {
+		list = append(list, &Metrics{
+			API:            m.API,
+			Request:        m.Request,
+			Count:          m.Count,
+			Errors:         m.Errors,
+			Latency:        time.Duration(m.LatencyMicroseconds) * time.Microsecond,
+			LatencyBuckets: m.LatencyBuckets,
+		})
+	}
+	return list, nil
+}
+
+// cancel sends a request to cancel the connection attempt to the daemon
+var cancel = func(d *DBusClient) error {
+	return d.conn.Object(dbusapi.Interface, dbusapi.Path).
+		Call(dbusapi.MethodCancel, 0).Store()
+}
+
+// Cancel aborts a connection attempt of the daemon that is still
+// connecting, e.g., in a hanging openconnect handshake
+func (d *DBusClient) Cancel() error {
+	// check status
+	status, err := d.Query()
+	if err != nil {
+		return fmt.Errorf("could not query OC-Daemon: %w", err)
+	}
+	if status.ConnectionState != vpnstatus.ConnectionStateConnecting {
+		return fmt.Errorf("VPN is not connecting, nothing to do")
+	}
+
+	// cancel
+	return cancel(d)
+}
+
+// setPreferredServer sends a request to set the preferred VPN server to the
+// daemon
+var setPreferredServer = func(d *DBusClient, server string) error {
+	return d.conn.Object(dbusapi.Interface, dbusapi.Path).
+		Call(dbusapi.MethodSetPreferredServer, 0, server).Store()
+}
+
+// SetPreferredServer sets the preferred VPN server in the XML profile of the
+// daemon to the host name or address server, the daemon keeps it across
+// restarts; an empty server resets the preferred server
+func (d *DBusClient) SetPreferredServer(server string) error {
+	return setPreferredServer(d, server)
+}
+
+// addSplitExclude sends a request to add a split exclude to the daemon
+var addSplitExclude = func(d *DBusClient, address string) error {
+	return d.conn.Object(dbusapi.Interface, dbusapi.Path).
+		Call(dbusapi.MethodAddSplitExclude, 0, address).Store()
+}
+
+// AddSplitExclude adds the IP address or network address to the split
+// excludes of the current VPN connection of the daemon, e.g., to fix routing
+// problems without changing the XML profile; the exclude is removed on
+// disconnect; this requires root
+func (d *DBusClient) AddSplitExclude(address string) error {
+	return addSplitExclude(d, address)
+}
+
+// removeSplitExclude sends a request to remove a split exclude to the daemon
+var removeSplitExclude = func(d *DBusClient, address string) error {
+	return d.conn.Object(dbusapi.Interface, dbusapi.Path).
+		Call(dbusapi.MethodRemoveSplitExclude, 0, address).Store()
+}
+
+// RemoveSplitExclude removes the address added with AddSplitExclude from the
+// split excludes of the current VPN connection of the daemon; this requires
+// root
+func (d *DBusClient) RemoveSplitExclude(address string) error {
+	return removeSplitExclude(d, address)
+}
+
+// listSplitExcludes requests the split excludes added with AddSplitExclude
+// from the daemon
+var listSplitExcludes = func(d *DBusClient) ([]string, error) {
+	excludes := []string{}
+	err := d.conn.Object(dbusapi.Interface, dbusapi.Path).
+		Call(dbusapi.MethodListSplitExcludes, 0).Store(&excludes)
+	return excludes, err
+}
+
+// ListSplitExcludes returns the addresses added with AddSplitExclude to the
+// split excludes of the current VPN connection of the daemon; this requires
+// root
+func (d *DBusClient) ListSplitExcludes() ([]string, error) {
+	return listSplitExcludes(d)
+}
+
+// Kinds of candidate configurations of ValidateConfig
+const (
+	ValidateKindConfig  = dbusapi.ValidateKindConfig
+	ValidateKindProfile = dbusapi.ValidateKindProfile
+)
+
+// Severities of findings of ValidateConfig
+const (
+	SeverityError   = dbusapi.SeverityError
+	SeverityWarning = dbusapi.SeverityWarning
+)
+
+// Finding is a finding of ValidateConfig, Field is the affected setting if
+// known
+type Finding struct {
+	Severity string
+	Field    string
+	Message  string
+}
+
+// validateConfig sends a candidate configuration to the daemon for
+// validation
+var validateConfig = func(d *DBusClient, kind, data string) ([]dbusapi.Finding, error) {
+	findings := []dbusapi.Finding{}
+	err := d.conn.Object(dbusapi.Interface, dbusapi.Path).
+		Call(dbusapi.MethodValidateConfig, 0, kind, data).Store(&findings)
+	return findings, err
+}
+
+// ValidateConfig validates the candidate daemon configuration or XML profile
+// in data, depending on kind, against the running daemon without applying it
+// and returns the findings; the candidate is valid if there are no findings
+// with SeverityError; this requires root
+func (d *DBusClient) ValidateConfig(kind string, data []byte) ([]*Finding, error) {
+	findings, err := validateConfig(d, kind, string(data))
+	if err != nil {
+		return nil, err
+	}
+	list := []*Finding{}
+	for _, f := range findings {
+		list = append(list, &Finding{
+			Severity: f.Severity,
+			Field:    f.Field,
+			Message:  f.Message,
+		})
+	}
+	return list, nil
+}
+
+// Notification severities of NotificationPolicy
+const (
+	NotificationSeverityInfo     = "info"
+	NotificationSeverityWarning  = "warning"
+	NotificationSeverityCritical = "critical"
+)
+
+// QuietWindow is a time window of the quiet hours of notifications, it
+// starts on Days, every day if empty, at the local time Start, e.g.,
+// "22:00", and ends at End
+type QuietWindow struct {
+	Days  []string
+	Start string
+	End   string
+}
+
+// NotificationPolicy is the policy for the notification signals of the
+// daemon, e.g., "ConnectionDropped": signals below MinSeverity and during
+// QuietHours are not emitted, each signal is limited to RateBurst signals at
+// once and one more every RateInterval if RateInterval is not 0; critical
+// signals like "ReauthRequired" are always emitted
+type NotificationPolicy struct {
+	MinSeverity  string
+	RateInterval time.Duration
+	RateBurst    int
+	QuietHours   []QuietWindow
+}
+
+// setNotificationPolicy sends the JSON notification policy to the daemon
+var setNotificationPolicy = func(d *DBusClient, policy string) error {
+	return d.conn.Object(dbusapi.Interface, dbusapi.Path).
+		Call(dbusapi.MethodSetNotificationPolicy, 0, policy).Store()
+}
+
+// SetNotificationPolicy sets the notification policy of the daemon until the
+// next config reload, nil resets it to the daemon configuration
+func (d *DBusClient) SetNotificationPolicy(policy *NotificationPolicy) error {
+	if policy == nil {
+		return setNotificationPolicy(d, "")
+	}
+	b, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	return setNotificationPolicy(d, string(b))
+}
+
+// reloadProfile sends a request to read the XML profile again to the daemon
+var reloadProfile = func(d *DBusClient) error {
+	return d.conn.Object(dbusapi.Interface, dbusapi.Path).
+		Call(dbusapi.MethodReloadProfile, 0).Store()
+}
+
+// ReloadProfile makes the daemon read its XML profile again immediately, it
+// returns an error if the profile cannot be parsed; this requires root
+// privileges
+func (d *DBusClient) ReloadProfile() error {
+	return reloadProfile(d)
+}
+
+// getStatus requests the complete VPN status as JSON from the daemon
+var getStatus = func(d *DBusClient) (string, error) {
+	status := ""
+	err := d.conn.Object(dbusapi.Interface, dbusapi.Path).
+		Call(dbusapi.MethodGetStatus, 0).Store(&status)
+	return status, err
+}
+
+// GetStatus returns the complete VPN status of the daemon, unlike Query, it
+// gets all values in one call as a consistent snapshot; this is only
+// supported for the main VPN connection
+func (d *DBusClient) GetStatus() (*vpnstatus.Status, error) {
+	if tunnel := d.getTunnel(); tunnel != "" {
+		return nil, fmt.Errorf("status snapshot not supported for tunnel %s", tunnel)
+	}
+	b, err := getStatus(d)
+	if err != nil {
+		return nil, err
+	}
+	return vpnstatus.NewFromJSON([]byte(b))
+}
+
 // Close closes the DBusClient
 func (d *DBusClient) Close() error {
 	var err error
@@ -526,6 +1466,16 @@ func (d *DBusClient) Close() error {
 	return err
 }
 
+// newDBusClient returns a new DBusClient with D-Bus connection conn
+func newDBusClient(config *Config, conn *dbus.Conn) *DBusClient {
+	return &DBusClient{
+		config:  config,
+		conn:    conn,
+		updates: make(chan *vpnstatus.Status),
+		done:    make(chan struct{}),
+	}
+}
+
 // NewDBusClient returns a new DBusClient
 func NewDBusClient(config *Config) (*DBusClient, error) {
 	// connect to system bus
@@ -535,17 +1485,29 @@ func NewDBusClient(config *Config) (*DBusClient, error) {
 	}
 
 	// create client
-	client := &DBusClient{
-		config:  config,
-		conn:    conn,
-		updates: make(chan *vpnstatus.Status),
-		done:    make(chan struct{}),
+	return newDBusClient(config, conn), nil
+}
+
+// NewRemoteDBusClient returns a new DBusClient that uses the D-Bus API of
+// OC-Daemon on the remote host over ssh, host can contain a user, e.g.,
+// "user@machine"
+func NewRemoteDBusClient(config *Config, host string) (*DBusClient, error) {
+	// connect to system bus on remote host
+	conn, err := dbusConnectRemote(host)
+	if err != nil {
+		return nil, err
 	}
 
-	return client, nil
+	// create client
+	return newDBusClient(config, conn), nil
 }
 
 // NewClient returns a new Client
 func NewClient(config *Config) (Client, error) {
 	return NewDBusClient(config)
 }
+
+// NewRemoteClient returns a new Client for OC-Daemon on the remote host
+func NewRemoteClient(config *Config, host string) (Client, error) {
+	return NewRemoteDBusClient(config, host)
+}
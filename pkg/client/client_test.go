@@ -1,8 +1,10 @@
 package client
 
 import (
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/godbus/dbus/v5"
 	"github.com/telekom-mms/oc-daemon/internal/dbusapi"
@@ -71,6 +73,82 @@ func TestDBusClientQuery(t *testing.T) {
 	}
 }
 
+// TestDBusClientQueryTunnel tests Query of DBusClient with additional tunnel
+func TestDBusClientQueryTunnel(t *testing.T) {
+	client := &DBusClient{config: NewConfig()}
+	client.config.Tunnel = "lab"
+	query = func(*DBusClient) (map[string]dbus.Variant, error) {
+		props := map[string]dbus.Variant{
+			dbusapi.PropertyTrustedNetwork: dbus.MakeVariant(dbusapi.TrustedNetworkTrusted),
+			dbusapi.PropertyDevice:         dbus.MakeVariant("oc-daemon-tun0"),
+		}
+		return props, nil
+	}
+	queryTunnel = func(_ *DBusClient, tunnel string) (map[string]dbus.Variant, error) {
+		if tunnel != "lab" {
+			t.Errorf("got %s, want lab", tunnel)
+		}
+		props := map[string]dbus.Variant{
+			dbusapi.PropertyDevice: dbus.MakeVariant("oc-daemon-tun1"),
+		}
+		return props, nil
+	}
+	want := vpnstatus.New()
+	want.TrustedNetwork = vpnstatus.TrustedNetworkTrusted
+	want.Device = "oc-daemon-tun1"
+	got, err := client.Query()
+	if err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestHandlePropertiesChangedTunnel tests handlePropertiesChanged with
+// additional tunnel
+func TestHandlePropertiesChangedTunnel(t *testing.T) {
+	signal := func(path dbus.ObjectPath) *dbus.Signal {
+		return &dbus.Signal{
+			Path: path,
+			Name: "org.freedesktop.DBus.Properties.PropertiesChanged",
+			Body: []any{
+				dbusapi.Interface,
+				map[string]dbus.Variant{
+					dbusapi.PropertyTrustedNetwork: dbus.MakeVariant(dbusapi.TrustedNetworkTrusted),
+					dbusapi.PropertyDevice:         dbus.MakeVariant("tun"),
+				},
+				[]string{},
+			},
+		}
+	}
+
+	// main connection ignores tunnel
+	if got := handlePropertiesChanged(signal(dbusapi.TunnelPath("lab")), vpnstatus.New(), ""); got != nil {
+		t.Errorf("got %v, want nil", got)
+	}
+
+	// tunnel ignores other tunnels
+	if got := handlePropertiesChanged(signal(dbusapi.TunnelPath("other")), vpnstatus.New(), "lab"); got != nil {
+		t.Errorf("got %v, want nil", got)
+	}
+
+	// tunnel only uses daemon properties of daemon
+	want := vpnstatus.New()
+	want.TrustedNetwork = vpnstatus.TrustedNetworkTrusted
+	got := handlePropertiesChanged(signal(dbusapi.Path), vpnstatus.New(), "lab")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// tunnel uses all properties of tunnel
+	want.Device = "tun"
+	got = handlePropertiesChanged(signal(dbusapi.TunnelPath("lab")), vpnstatus.New(), "lab")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
 // TestDBusClientAuthenticate tests Authenticate of DBusClient
 func TestDBusClientAuthenticate(t *testing.T) {
 	client := &DBusClient{}
@@ -92,6 +170,25 @@ func TestDBusClientAuthenticate(t *testing.T) {
 	if !reflect.DeepEqual(got, want) {
 		t.Errorf("got %v, want %v", got, want)
 	}
+
+	// csd wrapper configured in daemon
+	query = func(*DBusClient) (map[string]dbus.Variant, error) {
+		props := map[string]dbus.Variant{
+			dbusapi.PropertyCSDWrapper: dbus.MakeVariant("/usr/libexec/csd-wrapper"),
+		}
+		return props, nil
+	}
+	wrapper := ""
+	authenticate = func(d *DBusClient) error {
+		wrapper = d.getCSDWrapper()
+		return nil
+	}
+	if err := client.Authenticate(); err != nil {
+		t.Error(err)
+	}
+	if wrapper != "/usr/libexec/csd-wrapper" {
+		t.Errorf("got %s, want /usr/libexec/csd-wrapper", wrapper)
+	}
 }
 
 // TestDBusClientConnect tests Connect of DBusClient
@@ -109,6 +206,625 @@ func TestDBusClientConnect(t *testing.T) {
 	}
 }
 
+// TestDBusClientConnectOffline tests Connect of DBusClient while offline
+func TestDBusClientConnectOffline(t *testing.T) {
+	client := &DBusClient{}
+
+	// offline status
+	query = func(*DBusClient) (map[string]dbus.Variant, error) {
+		return map[string]dbus.Variant{
+			dbusapi.PropertyConnectivity: dbus.MakeVariant(dbusapi.ConnectivityOffline),
+		}, nil
+	}
+	connect = func(*DBusClient) error {
+		t.Error("connect should not be called")
+		return nil
+	}
+	if err := client.Connect(); !errors.Is(err, ErrOffline) {
+		t.Errorf("got %v, want %v", err, ErrOffline)
+	}
+
+	// offline error of daemon
+	query = func(*DBusClient) (map[string]dbus.Variant, error) {
+		return nil, nil
+	}
+	connect = func(*DBusClient) error {
+		return dbus.Error{Name: dbusapi.ErrorOffline}
+	}
+	if err := client.Connect(); !errors.Is(err, ErrOffline) {
+		t.Errorf("got %v, want %v", err, ErrOffline)
+	}
+}
+
+// TestDBusClientConnectCached tests ConnectCached of DBusClient
+func TestDBusClientConnectCached(t *testing.T) {
+	client := &DBusClient{}
+	query = func(*DBusClient) (map[string]dbus.Variant, error) {
+		return nil, nil
+	}
+	connectCached = func(d *DBusClient) error {
+		return nil
+	}
+	if err := client.ConnectCached(); err != nil {
+		t.Error(err)
+	}
+
+	// offline error of daemon
+	connectCached = func(*DBusClient) error {
+		return dbus.Error{Name: dbusapi.ErrorOffline}
+	}
+	if err := client.ConnectCached(); !errors.Is(err, ErrOffline) {
+		t.Errorf("got %v, want %v", err, ErrOffline)
+	}
+}
+
+// TestDBusClientReconnect tests Reconnect of DBusClient
+func TestDBusClientReconnect(t *testing.T) {
+	client := &DBusClient{}
+	reconnect = func(*DBusClient) error {
+		return nil
+	}
+	if err := client.Reconnect(); err != nil {
+		t.Error(err)
+	}
+
+	// errors of daemon
+	for name, want := range map[string]error{
+		dbusapi.ErrorOffline:        ErrOffline,
+		dbusapi.ErrorReauthRequired: ErrReauthRequired,
+	} {
+		reconnect = func(*DBusClient) error {
+			return dbus.Error{Name: name}
+		}
+		if err := client.Reconnect(); !errors.Is(err, want) {
+			t.Errorf("got %v, want %v", err, want)
+		}
+	}
+}
+
+// TestRetryAfter tests RetryAfter
+func TestRetryAfter(t *testing.T) {
+	tooMany := dbus.Error{
+		Name: dbusapi.ErrorTooManyRequests,
+		Body: []any{"too many requests, retry after 3s", uint32(3)},
+	}
+	for _, test := range []struct {
+		err  error
+		want time.Duration
+		ok   bool
+	}{
+		{errors.New("test error"), 0, false},
+		{dbus.Error{Name: dbusapi.Interface + ".ConnectAborted"}, 0, false},
+		{dbus.Error{Name: dbusapi.ErrorTooManyRequests}, 0, false},
+		{tooMany, 3 * time.Second, true},
+		{&tooMany, 3 * time.Second, true},
+	} {
+		got, ok := RetryAfter(test.err)
+		if got != test.want || ok != test.ok {
+			t.Errorf("%v: got %v, %t, want %v, %t", test.err, got, ok,
+				test.want, test.ok)
+		}
+	}
+}
+
+// TestDBusClientConnectDevice tests ConnectDevice of DBusClient
+func TestDBusClientConnectDevice(t *testing.T) {
+	client := &DBusClient{config: NewConfig()}
+	query = func(*DBusClient) (map[string]dbus.Variant, error) {
+		return nil, nil
+	}
+	want := &DeviceCode{
+		UserCode:        "USER-CODE",
+		VerificationURI: "https://idp.example.com/device",
+	}
+	connectDevice = func(d *DBusClient) (*DeviceCode, error) {
+		return want, nil
+	}
+	got, err := client.ConnectDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// tunnel
+	client.config.Tunnel = "lab"
+	if _, err := client.ConnectDevice(); err == nil {
+		t.Error("connect device should fail for tunnels")
+	}
+}
+
+// TestDBusClientSetTND tests SetTND of DBusClient
+func TestDBusClientSetTND(t *testing.T) {
+	client := &DBusClient{}
+	var gotEnabled bool
+	var gotTimeout uint32
+	setTND = func(_ *DBusClient, enabled bool, timeout uint32) error {
+		gotEnabled = enabled
+		gotTimeout = timeout
+		return nil
+	}
+
+	// disable with timeout, rounded up to seconds
+	if err := client.SetTND(false, 90*time.Second+time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if gotEnabled || gotTimeout != 91 {
+		t.Errorf("got %t %d, want false 91", gotEnabled, gotTimeout)
+	}
+
+	// enable
+	if err := client.SetTND(true, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !gotEnabled || gotTimeout != 0 {
+		t.Errorf("got %t %d, want true 0", gotEnabled, gotTimeout)
+	}
+
+	// invalid timeout
+	if err := client.SetTND(false, -time.Second); err == nil {
+		t.Error("invalid timeout should return error")
+	}
+}
+
+// TestDBusClientGetLogs tests GetLogs of DBusClient
+func TestDBusClientGetLogs(t *testing.T) {
+	client := &DBusClient{}
+	want := []string{"level=info msg=test"}
+	var gotLines uint32
+	getLogs = func(_ *DBusClient, lines uint32) ([]string, error) {
+		gotLines = lines
+		return want, nil
+	}
+
+	// get logs
+	got, err := client.GetLogs(50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotLines != 50 || !reflect.DeepEqual(got, want) {
+		t.Errorf("got %d %v, want 50 %v", gotLines, got, want)
+	}
+
+	// invalid lines
+	if _, err := client.GetLogs(-1); err == nil {
+		t.Error("invalid lines should return error")
+	}
+}
+
+// TestDBusClientListServers tests ListServers of DBusClient
+func TestDBusClientListServers(t *testing.T) {
+	client := &DBusClient{}
+	var gotPing bool
+	listServers = func(_ *DBusClient, ping bool) ([]dbusapi.Server, error) {
+		gotPing = ping
+		return []dbusapi.Server{{
+			Name:      "VPN",
+			Address:   "vpn.example.com",
+			Reachable: true,
+			Latency:   23000,
+		}}, nil
+	}
+
+	// list servers
+	got, err := client.ListServers(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Server{{
+		Name:      "VPN",
+		Address:   "vpn.example.com",
+		Reachable: true,
+		Latency:   23 * time.Millisecond,
+	}}
+	if !gotPing || !reflect.DeepEqual(got, want) {
+		t.Errorf("got %t %v, want true %v", gotPing, got, want)
+	}
+
+	// error
+	listServers = func(*DBusClient, bool) ([]dbusapi.Server, error) {
+		return nil, errors.New("test error")
+	}
+	if _, err := client.ListServers(false); err == nil {
+		t.Error("list servers should return error")
+	}
+}
+
+// TestDBusClientGetUsage tests GetUsage of DBusClient
+func TestDBusClientGetUsage(t *testing.T) {
+	client := &DBusClient{}
+	getUsage = func(*DBusClient) ([]dbusapi.Usage, error) {
+		return []dbusapi.Usage{{
+			Month:            "2026-10",
+			RXBytes:          2048,
+			TXBytes:          1024,
+			Connections:      3,
+			ConnectedSeconds: 90,
+		}}, nil
+	}
+
+	// get usage
+	got, err := client.GetUsage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Usage{{
+		Month:       "2026-10",
+		RXBytes:     2048,
+		TXBytes:     1024,
+		Connections: 3,
+		Connected:   90 * time.Second,
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// error
+	getUsage = func(*DBusClient) ([]dbusapi.Usage, error) {
+		return nil, errors.New("test error")
+	}
+	if _, err := client.GetUsage(); err == nil {
+		t.Error("get usage should return error")
+	}
+}
+
+// TestDBusClientGetMetrics tests GetMetrics of DBusClient
+func TestDBusClientGetMetrics(t *testing.T) {
+	client := &DBusClient{}
+	getMetrics = func(*DBusClient) ([]dbusapi.RequestMetrics, error) {
+		return []dbusapi.RequestMetrics{{
+			API:                 "dbus",
+			Request:             "Connect",
+			Count:               2,
+			Errors:              1,
+			LatencyMicroseconds: 1500,
+			LatencyBuckets:      []uint64{0, 2, 0, 0, 0, 0},
+		}}, nil
+	}
+
+	// get metrics
+	got, err := client.GetMetrics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Metrics{{
+		API:            "dbus",
+		Request:        "Connect",
+		Count:          2,
+		Errors:         1,
+		Latency:        1500 * time.Microsecond,
+		LatencyBuckets: []uint64{0, 2, 0, 0, 0, 0},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// error
+	getMetrics = func(*DBusClient) ([]dbusapi.RequestMetrics, error) {
+		return nil, errors.New("test error")
+	}
+	if _, err := client.GetMetrics(); err == nil {
+		t.Error("get metrics should return error")
+	}
+}
+
+// TestDBusClientPauseResume tests Pause and Resume of DBusClient
+func TestDBusClientPauseResume(t *testing.T) {
+	client := &DBusClient{}
+	var gotTimeout uint32
+	pause = func(_ *DBusClient, timeout uint32) error {
+		gotTimeout = timeout
+		return nil
+	}
+	resumed := false
+	resume = func(*DBusClient) error {
+		resumed = true
+		return nil
+	}
+
+	// pause with timeout, rounded up to seconds
+	if err := client.Pause(5*time.Minute + time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if gotTimeout != 301 {
+		t.Errorf("got %d, want 301", gotTimeout)
+	}
+
+	// invalid timeout
+	if err := client.Pause(-time.Second); err == nil {
+		t.Error("invalid timeout should return error")
+	}
+
+	// resume
+	if err := client.Resume(); err != nil || !resumed {
+		t.Errorf("resume failed: %v", err)
+	}
+}
+
+// TestDBusClientSetTrafPol tests SetTrafPol of DBusClient
+func TestDBusClientSetTrafPol(t *testing.T) {
+	client := &DBusClient{}
+	var gotEnabled bool
+	var gotTimeout uint32
+	setTrafPol = func(_ *DBusClient, enabled bool, timeout uint32) error {
+		gotEnabled = enabled
+		gotTimeout = timeout
+		return nil
+	}
+
+	// disable with timeout, rounded up to seconds
+	if err := client.SetTrafPol(false, 90*time.Second+time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if gotEnabled || gotTimeout != 91 {
+		t.Errorf("got %t %d, want false 91", gotEnabled, gotTimeout)
+	}
+
+	// enable
+	if err := client.SetTrafPol(true, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !gotEnabled || gotTimeout != 0 {
+		t.Errorf("got %t %d, want true 0", gotEnabled, gotTimeout)
+	}
+
+	// invalid timeout
+	if err := client.SetTrafPol(false, -time.Second); err == nil {
+		t.Error("invalid timeout should return error")
+	}
+}
+
+// TestDBusClientReloadProfile tests ReloadProfile of DBusClient
+func TestDBusClientReloadProfile(t *testing.T) {
+	client := &DBusClient{}
+	reloadProfile = func(*DBusClient) error {
+		return nil
+	}
+	if err := client.ReloadProfile(); err != nil {
+		t.Error(err)
+	}
+
+	// error
+	reloadProfile = func(*DBusClient) error {
+		return errors.New("test error")
+	}
+	if err := client.ReloadProfile(); err == nil {
+		t.Error("reload profile should return error")
+	}
+}
+
+// TestDBusClientSetPreferredServer tests SetPreferredServer of DBusClient
+func TestDBusClientSetPreferredServer(t *testing.T) {
+	client := &DBusClient{}
+	got := ""
+	setPreferredServer = func(_ *DBusClient, server string) error {
+		got = server
+		return nil
+	}
+	if err := client.SetPreferredServer("vpn.example.com"); err != nil {
+		t.Error(err)
+	}
+	if got != "vpn.example.com" {
+		t.Errorf("got %s, want vpn.example.com", got)
+	}
+
+	// error
+	setPreferredServer = func(*DBusClient, string) error {
+		return errors.New("test error")
+	}
+	if err := client.SetPreferredServer(""); err == nil {
+		t.Error("set preferred server should return error")
+	}
+}
+
+// TestDBusClientSplitExcludes tests AddSplitExclude, RemoveSplitExclude and
+// ListSplitExcludes of DBusClient
+func TestDBusClientSplitExcludes(t *testing.T) {
+	client := &DBusClient{}
+	excludes := []string{}
+	addSplitExclude = func(_ *DBusClient, address string) error {
+		excludes = append(excludes, address)
+		return nil
+	}
+	removeSplitExclude = func(_ *DBusClient, address string) error {
+		if len(excludes) == 0 || excludes[0] != address {
+			return errors.New("test error")
+		}
+		excludes = excludes[1:]
+		return nil
+	}
+	listSplitExcludes = func(*DBusClient) ([]string, error) {
+		return excludes, nil
+	}
+
+	if err := client.AddSplitExclude("192.168.1.0/24"); err != nil {
+		t.Error(err)
+	}
+	got, err := client.ListSplitExcludes()
+	if err != nil {
+		t.Error(err)
+	}
+	want := []string{"192.168.1.0/24"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if err := client.RemoveSplitExclude("10.0.0.0/8"); err == nil {
+		t.Error("remove split exclude should return error")
+	}
+	if err := client.RemoveSplitExclude("192.168.1.0/24"); err != nil {
+		t.Error(err)
+	}
+	if got, _ := client.ListSplitExcludes(); len(got) != 0 {
+		t.Errorf("got %v, want empty", got)
+	}
+}
+
+// TestDBusClientSetNotificationPolicy tests SetNotificationPolicy of
+// DBusClient
+func TestDBusClientSetNotificationPolicy(t *testing.T) {
+	client := &DBusClient{}
+	got := "invalid"
+	setNotificationPolicy = func(_ *DBusClient, policy string) error {
+		got = policy
+		return nil
+	}
+
+	// set policy
+	if err := client.SetNotificationPolicy(&NotificationPolicy{
+		MinSeverity:  NotificationSeverityWarning,
+		RateInterval: time.Minute,
+		RateBurst:    1,
+		QuietHours:   []QuietWindow{{Start: "22:00", End: "07:00"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"MinSeverity":"warning","RateInterval":60000000000,` +
+		`"RateBurst":1,"QuietHours":[{"Days":null,"Start":"22:00","End":"07:00"}]}`
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	// reset policy
+	if err := client.SetNotificationPolicy(nil); err != nil {
+		t.Fatal(err)
+	}
+	if got != "" {
+		t.Errorf("got %s, want empty policy", got)
+	}
+}
+
+// TestDBusClientValidateConfig tests ValidateConfig of DBusClient
+func TestDBusClientValidateConfig(t *testing.T) {
+	client := &DBusClient{}
+	validateConfig = func(_ *DBusClient, kind, data string) ([]dbusapi.Finding, error) {
+		if kind != ValidateKindConfig || data != "{}" {
+			return nil, errors.New("test error")
+		}
+		return []dbusapi.Finding{{
+			Severity: SeverityWarning,
+			Field:    "Tunnels",
+			Message:  "changes require a restart of the daemon",
+		}}, nil
+	}
+
+	got, err := client.ValidateConfig(ValidateKindConfig, []byte("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Finding{{
+		Severity: SeverityWarning,
+		Field:    "Tunnels",
+		Message:  "changes require a restart of the daemon",
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// test error
+	if _, err := client.ValidateConfig(ValidateKindProfile, nil); err == nil {
+		t.Error("validate config should return error")
+	}
+}
+
+// TestDBusClientGetCapabilities tests GetCapabilities of DBusClient
+func TestDBusClientGetCapabilities(t *testing.T) {
+	client := &DBusClient{}
+
+	// daemon without versioned api
+	query = func(*DBusClient) (map[string]dbus.Variant, error) {
+		return map[string]dbus.Variant{}, nil
+	}
+	caps, err := client.GetCapabilities()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caps.Version != 0 || caps.Has(CapabilityStats) {
+		t.Errorf("got %v", caps)
+	}
+
+	// daemon with capabilities
+	query = func(*DBusClient) (map[string]dbus.Variant, error) {
+		return map[string]dbus.Variant{
+			dbusapi.PropertyVersion: dbus.MakeVariant(dbusapi.APIVersion),
+			dbusapi.PropertyCapabilities: dbus.MakeVariant([]string{
+				CapabilityMultiTunnel, CapabilityStats,
+			}),
+		}, nil
+	}
+	caps, err = client.GetCapabilities()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caps.Version != dbusapi.APIVersion || !caps.Has(CapabilityStats) ||
+		caps.Has(CapabilityDeviceAuth) {
+		t.Errorf("got %v", caps)
+	}
+
+	// invalid property type
+	query = func(*DBusClient) (map[string]dbus.Variant, error) {
+		return map[string]dbus.Variant{
+			dbusapi.PropertyVersion: dbus.MakeVariant("invalid"),
+		}, nil
+	}
+	if _, err := client.GetCapabilities(); err == nil {
+		t.Error("invalid version should return error")
+	}
+
+	// error
+	query = func(*DBusClient) (map[string]dbus.Variant, error) {
+		return nil, errors.New("test error")
+	}
+	if _, err := client.GetCapabilities(); err == nil {
+		t.Error("query error should return error")
+	}
+}
+
+// TestDBusClientGetStatus tests GetStatus of DBusClient
+func TestDBusClientGetStatus(t *testing.T) {
+	client := &DBusClient{}
+	want := vpnstatus.New()
+	want.ConnectionState = vpnstatus.ConnectionStateConnected
+	want.Servers = []string{"test server"}
+	getStatus = func(*DBusClient) (string, error) {
+		b, err := want.JSON()
+		return string(b), err
+	}
+
+	// get status
+	got, err := client.GetStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// invalid json
+	getStatus = func(*DBusClient) (string, error) {
+		return "{", nil
+	}
+	if _, err := client.GetStatus(); err == nil {
+		t.Error("invalid json should return error")
+	}
+
+	// error
+	getStatus = func(*DBusClient) (string, error) {
+		return "", errors.New("test error")
+	}
+	if _, err := client.GetStatus(); err == nil {
+		t.Error("get status should return error")
+	}
+
+	// additional tunnel
+	config := NewConfig()
+	config.Tunnel = "lab"
+	client.SetConfig(config)
+	if _, err := client.GetStatus(); err == nil {
+		t.Error("get status of tunnel should return error")
+	}
+}
+
 // TestDBusClientDisconnect tests Disconnect of DBusClient
 func TestDBusClientDisconnect(t *testing.T) {
 	client := &DBusClient{}
@@ -129,6 +845,30 @@ func TestDBusClientDisconnect(t *testing.T) {
 	}
 }
 
+// TestDBusClientCancel tests Cancel of DBusClient
+func TestDBusClientCancel(t *testing.T) {
+	client := &DBusClient{}
+	state := dbusapi.ConnectionStateConnecting
+	query = func(*DBusClient) (map[string]dbus.Variant, error) {
+		props := map[string]dbus.Variant{
+			dbusapi.PropertyConnectionState: dbus.MakeVariant(state),
+		}
+		return props, nil
+	}
+	cancel = func(d *DBusClient) error {
+		return nil
+	}
+	if err := client.Cancel(); err != nil {
+		t.Error(err)
+	}
+
+	// not connecting
+	state = dbusapi.ConnectionStateConnected
+	if err := client.Cancel(); err == nil {
+		t.Error("cancel should fail when not connecting")
+	}
+}
+
 // TestNewDBusClient tests NewDBusClient
 func TestNewDBusClient(t *testing.T) {
 	dbusConnectSystemBus = func() (*dbus.Conn, error) {
@@ -41,8 +41,40 @@ type Config struct {
 	CACertificate     string
 	XMLProfile        string
 	VPNServer         string
-	User              string
-	Password          string
+
+	// Profile is the name of the named XML profile used for the
+	// connection instead of XMLProfile, empty for XMLProfile
+	Profile string
+
+	// Tunnel is the name of the additional tunnel of the daemon used
+	// for the connection and status, empty for the main VPN connection
+	Tunnel string
+
+	User      string
+	Password  string
+	AutoProxy bool
+
+	// StickyServers specifies if the last successfully used VPN server
+	// on a network is preferred on the next connect on this network
+	StickyServers bool
+
+	// AuthCacheTimeout is the time after a successful authentication in
+	// which reconnects use the login info cached in the daemon instead of
+	// a new authentication, 0 disables it
+	AuthCacheTimeout time.Duration
+
+	// MinTLSVersion is the minimum TLS version used for authentication,
+	// "1.2" or "1.3", empty uses the OpenConnect default
+	MinTLSVersion string
+
+	// TLSGroups are the key exchange groups used for authentication in
+	// order of preference, e.g., "X25519-MLKEM768" and "X25519", empty
+	// uses the OpenConnect default
+	TLSGroups []string
+
+	// TLSPriority is a GnuTLS priority string used for authentication,
+	// it overrides MinTLSVersion and TLSGroups
+	TLSPriority string
 
 	SocketFile        string
 	ConnectionTimeout time.Duration
@@ -55,6 +87,9 @@ func (c *Config) Copy() *Config {
 		return nil
 	}
 	cp := *c
+	if c.TLSGroups != nil {
+		cp.TLSGroups = append([]string{}, c.TLSGroups...)
+	}
 	return &cp
 }
 
@@ -109,6 +144,7 @@ func (c *Config) Save(file string) error {
 func NewConfig() *Config {
 	return &Config{
 		XMLProfile:        xmlprofile.SystemProfile,
+		StickyServers:     true,
 		SocketFile:        SocketFile,
 		ConnectionTimeout: ConnectionTimeout,
 		RequestTimeout:    RequestTimeout,
@@ -15,6 +15,17 @@ func TestConfigCopy(t *testing.T) {
 	if !reflect.DeepEqual(got, want) {
 		t.Errorf("got %v, want %v", got, want)
 	}
+
+	// test with tls groups
+	want.TLSGroups = []string{"X25519"}
+	got = want.Copy()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	got.TLSGroups[0] = "SECP256R1"
+	if want.TLSGroups[0] != "X25519" {
+		t.Errorf("copy should not modify original")
+	}
 }
 
 // TestConfigEmpty tests Empty of Config
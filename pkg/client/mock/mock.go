@@ -0,0 +1,506 @@
+// Package mock contains a mock OC-Daemon client for testing code that uses
+// the client package without a running daemon
+package mock
+
+import (
+	"sync"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/pkg/client"
+	"github.com/telekom-mms/oc-daemon/pkg/logininfo"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// Names of the client methods used in Calls and Errors of Client
+const (
+	MethodPing                  = "Ping"
+	MethodQuery                 = "Query"
+	MethodSubscribe             = "Subscribe"
+	MethodAuthenticate          = "Authenticate"
+	MethodConnect               = "Connect"
+	MethodConnectCached         = "ConnectCached"
+	MethodReconnect             = "Reconnect"
+	MethodConnectDevice         = "ConnectDevice"
+	MethodDisconnect            = "Disconnect"
+	MethodCancel                = "Cancel"
+	MethodPause                 = "Pause"
+	MethodResume                = "Resume"
+	MethodSetTND                = "SetTND"
+	MethodSetTrafPol            = "SetTrafPol"
+	MethodGetLogs               = "GetLogs"
+	MethodListServers           = "ListServers"
+	MethodReloadProfile         = "ReloadProfile"
+	MethodSetPreferredServer    = "SetPreferredServer"
+	MethodAddSplitExclude       = "AddSplitExclude"
+	MethodRemoveSplitExclude    = "RemoveSplitExclude"
+	MethodListSplitExcludes     = "ListSplitExcludes"
+	MethodValidateConfig        = "ValidateConfig"
+	MethodSetNotificationPolicy = "SetNotificationPolicy"
+	MethodGetUsage              = "GetUsage"
+	MethodGetMetrics            = "GetMetrics"
+	MethodGetStatus             = "GetStatus"
+	MethodGetCapabilities       = "GetCapabilities"
+	MethodClose                 = "Close"
+)
+
+// Client is a mock OC-Daemon client, it returns the values set in its
+// fields, records the calls of its methods and returns the errors set for
+// them; Connect and Disconnect also update the connection state in Status
+type Client struct {
+	mutex sync.Mutex
+
+	config *client.Config
+	env    []string
+	login  *logininfo.LoginInfo
+
+	// Status is the VPN status returned by Query and GetStatus
+	Status *vpnstatus.Status
+
+	// DeviceCode is the device code returned by ConnectDevice
+	DeviceCode *client.DeviceCode
+
+	// Logs are the log entries returned by GetLogs
+	Logs []string
+
+	// Servers are the VPN servers returned by ListServers
+	Servers []*client.Server
+
+	// Usage is the VPN usage returned by GetUsage
+	Usage []*client.Usage
+
+	// Metrics are the request metrics returned by GetMetrics
+	Metrics []*client.Metrics
+
+	// SplitExcludes are the split excludes returned by ListSplitExcludes
+	SplitExcludes []string
+
+	// Findings are the findings returned by ValidateConfig
+	Findings []*client.Finding
+
+	// Capabilities are the capabilities returned by GetCapabilities
+	Capabilities *client.Capabilities
+
+	// Errors are the errors returned by the methods with the names
+	Errors map[string]error
+
+	// calls are the names of the called methods
+	calls []string
+
+	// updates is the channel of status updates returned by Subscribe
+	updates chan *vpnstatus.Status
+}
+
+// call records the call of method and returns its error
+func (c *Client) call(method string) error {
+	c.calls = append(c.calls, method)
+	return c.Errors[method]
+}
+
+// status returns a copy of the status
+func (c *Client) status() *vpnstatus.Status {
+	if c.Status == nil {
+		return vpnstatus.New()
+	}
+	return c.Status.Copy()
+}
+
+// setConnectionState sets the connection state in status
+func (c *Client) setConnectionState(state vpnstatus.ConnectionState) {
+	if c.Status == nil {
+		c.Status = vpnstatus.New()
+	}
+	c.Status.ConnectionState = state
+}
+
+// setPauseState sets the pause state in status
+func (c *Client) setPauseState(state vpnstatus.PauseState) {
+	if c.Status == nil {
+		c.Status = vpnstatus.New()
+	}
+	c.Status.PauseState = state
+}
+
+// SetConfig sets the client config
+func (c *Client) SetConfig(config *client.Config) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.config = config
+}
+
+// GetConfig returns the client config
+func (c *Client) GetConfig() *client.Config {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.config
+}
+
+// SetEnv sets additional environment variables
+func (c *Client) SetEnv(env []string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.env = env
+}
+
+// GetEnv returns the additional environment variables
+func (c *Client) GetEnv() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.env
+}
+
+// SetLogin sets the login information
+func (c *Client) SetLogin(login *logininfo.LoginInfo) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.login = login
+}
+
+// GetLogin returns the login information
+func (c *Client) GetLogin() *logininfo.LoginInfo {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.login
+}
+
+// Ping records the call and returns its error
+func (c *Client) Ping() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.call(MethodPing)
+}
+
+// Query returns a copy of Status
+func (c *Client) Query() (*vpnstatus.Status, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.call(MethodQuery); err != nil {
+		return nil, err
+	}
+	return c.status(), nil
+}
+
+// Subscribe returns the channel of the status updates sent with Update,
+// the current status is sent first
+func (c *Client) Subscribe() (chan *vpnstatus.Status, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.call(MethodSubscribe); err != nil {
+		return nil, err
+	}
+	if c.updates == nil {
+		c.updates = make(chan *vpnstatus.Status, 1)
+		c.updates <- c.status()
+	}
+	return c.updates, nil
+}
+
+// Update sets Status to status and sends it to the subscription channel,
+// it blocks until the update is received if there is a subscriber
+func (c *Client) Update(status *vpnstatus.Status) {
+	c.mutex.Lock()
+	c.Status = status.Copy()
+	updates := c.updates
+	c.mutex.Unlock()
+
+	if updates != nil {
+		updates <- status.Copy()
+	}
+}
+
+// Authenticate records the call and returns its error, it sets an empty
+// login information if it succeeds
+func (c *Client) Authenticate() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.call(MethodAuthenticate); err != nil {
+		return err
+	}
+	if c.login == nil {
+		c.login = &logininfo.LoginInfo{}
+	}
+	return nil
+}
+
+// Connect records the call and returns its error, the connection state is
+// connected if it succeeds
+func (c *Client) Connect() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.call(MethodConnect); err != nil {
+		return err
+	}
+	c.setConnectionState(vpnstatus.ConnectionStateConnected)
+	return nil
+}
+
+// ConnectCached records the call and returns its error, the connection
+// state is connected if it succeeds
+func (c *Client) ConnectCached() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.call(MethodConnectCached); err != nil {
+		return err
+	}
+	c.setConnectionState(vpnstatus.ConnectionStateConnected)
+	return nil
+}
+
+// Reconnect records the call and returns its error, the connection state
+// is connected if it succeeds
+func (c *Client) Reconnect() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.call(MethodReconnect); err != nil {
+		return err
+	}
+	c.setConnectionState(vpnstatus.ConnectionStateConnected)
+	return nil
+}
+
+// ConnectDevice returns DeviceCode, the connection state is connected if it
+// succeeds
+func (c *Client) ConnectDevice() (*client.DeviceCode, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.call(MethodConnectDevice); err != nil {
+		return nil, err
+	}
+	c.setConnectionState(vpnstatus.ConnectionStateConnected)
+	return c.DeviceCode, nil
+}
+
+// Disconnect records the call and returns its error, the connection state
+// is disconnected if it succeeds
+func (c *Client) Disconnect() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.call(MethodDisconnect); err != nil {
+		return err
+	}
+	c.setConnectionState(vpnstatus.ConnectionStateDisconnected)
+	return nil
+}
+
+// Cancel records the call and returns its error, the connection state is
+// disconnected if it succeeds
+func (c *Client) Cancel() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.call(MethodCancel); err != nil {
+		return err
+	}
+	c.setConnectionState(vpnstatus.ConnectionStateDisconnected)
+	return nil
+}
+
+// Pause records the call and returns its error, the pause state is paused
+// if it succeeds
+func (c *Client) Pause(time.Duration) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.call(MethodPause); err != nil {
+		return err
+	}
+	c.setPauseState(vpnstatus.PauseStatePaused)
+	return nil
+}
+
+// Resume records the call and returns its error, the pause state is not
+// paused if it succeeds
+func (c *Client) Resume() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.call(MethodResume); err != nil {
+		return err
+	}
+	c.setPauseState(vpnstatus.PauseStateNotPaused)
+	return nil
+}
+
+// SetTND records the call and returns its error
+func (c *Client) SetTND(bool, time.Duration) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.call(MethodSetTND)
+}
+
+// SetTrafPol records the call and returns its error
+func (c *Client) SetTrafPol(bool, time.Duration) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.call(MethodSetTrafPol)
+}
+
+// GetLogs returns the last lines entries of Logs, all entries if lines is 0
+func (c *Client) GetLogs(lines int) ([]string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.call(MethodGetLogs); err != nil {
+		return nil, err
+	}
+	logs := append([]string{}, c.Logs...)
+	if lines > 0 && lines < len(logs) {
+		logs = logs[len(logs)-lines:]
+	}
+	return logs, nil
+}
+
+// ListServers returns Servers
+func (c *Client) ListServers(bool) ([]*client.Server, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.call(MethodListServers); err != nil {
+		return nil, err
+	}
+	return append([]*client.Server{}, c.Servers...), nil
+}
+
+// ReloadProfile records the call and returns its error
+func (c *Client) ReloadProfile() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.call(MethodReloadProfile)
+}
+
+// SetPreferredServer sets the preferred server in Status if it succeeds
+func (c *Client) SetPreferredServer(server string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.call(MethodSetPreferredServer); err != nil {
+		return err
+	}
+	if c.Status == nil {
+		c.Status = vpnstatus.New()
+	}
+	c.Status.PreferredServer = server
+	return nil
+}
+
+// AddSplitExclude adds address to SplitExcludes if it succeeds
+func (c *Client) AddSplitExclude(address string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.call(MethodAddSplitExclude); err != nil {
+		return err
+	}
+	c.SplitExcludes = append(c.SplitExcludes, address)
+	return nil
+}
+
+// RemoveSplitExclude removes address from SplitExcludes if it succeeds
+func (c *Client) RemoveSplitExclude(address string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.call(MethodRemoveSplitExclude); err != nil {
+		return err
+	}
+	excludes := []string{}
+	for _, e := range c.SplitExcludes {
+		if e != address {
+			excludes = append(excludes, e)
+		}
+	}
+	c.SplitExcludes = excludes
+	return nil
+}
+
+// ListSplitExcludes returns a copy of SplitExcludes
+func (c *Client) ListSplitExcludes() ([]string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.call(MethodListSplitExcludes); err != nil {
+		return nil, err
+	}
+	return append([]string{}, c.SplitExcludes...), nil
+}
+
+// ValidateConfig returns Findings
+func (c *Client) ValidateConfig(string, []byte) ([]*client.Finding, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.call(MethodValidateConfig); err != nil {
+		return nil, err
+	}
+	return append([]*client.Finding{}, c.Findings...), nil
+}
+
+// SetNotificationPolicy records the call and returns its error
+func (c *Client) SetNotificationPolicy(*client.NotificationPolicy) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.call(MethodSetNotificationPolicy)
+}
+
+// GetUsage returns Usage
+func (c *Client) GetUsage() ([]*client.Usage, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.call(MethodGetUsage); err != nil {
+		return nil, err
+	}
+	return append([]*client.Usage{}, c.Usage...), nil
+}
+
+// GetMetrics returns Metrics
+func (c *Client) GetMetrics() ([]*client.Metrics, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.call(MethodGetMetrics); err != nil {
+		return nil, err
+	}
+	return append([]*client.Metrics{}, c.Metrics...), nil
+}
+
+// GetStatus returns a copy of Status
+func (c *Client) GetStatus() (*vpnstatus.Status, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.call(MethodGetStatus); err != nil {
+		return nil, err
+	}
+	return c.status(), nil
+}
+
+// GetCapabilities returns a copy of Capabilities
+func (c *Client) GetCapabilities() (*client.Capabilities, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.call(MethodGetCapabilities); err != nil {
+		return nil, err
+	}
+	if c.Capabilities == nil {
+		return &client.Capabilities{}, nil
+	}
+	return &client.Capabilities{
+		Version:      c.Capabilities.Version,
+		Capabilities: append([]string{}, c.Capabilities.Capabilities...),
+	}, nil
+}
+
+// Close records the call, closes the subscription channel and returns its
+// error
+func (c *Client) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.updates != nil {
+		close(c.updates)
+		c.updates = nil
+	}
+	return c.call(MethodClose)
+}
+
+// Calls returns the names of the called methods in the order of the calls
+func (c *Client) Calls() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]string{}, c.calls...)
+}
+
+// NewClient returns a new mock Client with config and status
+func NewClient(config *client.Config, status *vpnstatus.Status) *Client {
+	return &Client{
+		config: config,
+		Status: status,
+		Errors: make(map[string]error),
+	}
+}
@@ -0,0 +1,255 @@
+package mock
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/pkg/client"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// TestClientInterface tests that Client implements client.Client
+func TestClientInterface(t *testing.T) {
+	var c client.Client = NewClient(client.NewConfig(), nil)
+	if c.GetConfig() == nil {
+		t.Error("config should be set")
+	}
+}
+
+// TestClientConnectDisconnect tests Connect and Disconnect of Client
+func TestClientConnectDisconnect(t *testing.T) {
+	c := NewClient(nil, nil)
+
+	// connect
+	if err := c.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	status, err := c.Query()
+	if err != nil || !status.ConnectionState.Connected() {
+		t.Errorf("got %v %v, want connected", status, err)
+	}
+
+	// disconnect with error
+	c.Errors[MethodDisconnect] = errors.New("test error")
+	if err := c.Disconnect(); err == nil {
+		t.Error("disconnect should return error")
+	}
+	if status, _ := c.GetStatus(); !status.ConnectionState.Connected() {
+		t.Error("should still be connected")
+	}
+
+	// disconnect
+	delete(c.Errors, MethodDisconnect)
+	if err := c.Disconnect(); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := c.GetStatus(); status.ConnectionState.Connected() {
+		t.Error("should be disconnected")
+	}
+
+	// calls
+	want := []string{MethodConnect, MethodQuery, MethodDisconnect,
+		MethodGetStatus, MethodDisconnect, MethodGetStatus}
+	if got := c.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestClientConnectCached tests ConnectCached of Client
+func TestClientConnectCached(t *testing.T) {
+	c := NewClient(nil, nil)
+
+	// error
+	c.Errors[MethodConnectCached] = errors.New("test error")
+	if err := c.ConnectCached(); err == nil {
+		t.Error("connect cached should return error")
+	}
+	if status, _ := c.GetStatus(); status.ConnectionState.Connected() {
+		t.Error("should not be connected")
+	}
+
+	// connect
+	delete(c.Errors, MethodConnectCached)
+	if err := c.ConnectCached(); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := c.GetStatus(); !status.ConnectionState.Connected() {
+		t.Error("should be connected")
+	}
+}
+
+// TestClientReconnect tests Reconnect of Client
+func TestClientReconnect(t *testing.T) {
+	c := NewClient(nil, nil)
+
+	// error
+	c.Errors[MethodReconnect] = client.ErrReauthRequired
+	if err := c.Reconnect(); !errors.Is(err, client.ErrReauthRequired) {
+		t.Errorf("got %v, want %v", err, client.ErrReauthRequired)
+	}
+	if status, _ := c.GetStatus(); status.ConnectionState.Connected() {
+		t.Error("should not be connected")
+	}
+
+	// reconnect
+	delete(c.Errors, MethodReconnect)
+	if err := c.Reconnect(); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := c.GetStatus(); !status.ConnectionState.Connected() {
+		t.Error("should be connected")
+	}
+}
+
+// TestClientPauseResume tests Pause and Resume of Client
+func TestClientPauseResume(t *testing.T) {
+	c := NewClient(nil, nil)
+
+	// pause
+	if err := c.Pause(0); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := c.GetStatus(); !status.PauseState.Paused() {
+		t.Error("should be paused")
+	}
+
+	// resume with error
+	c.Errors[MethodResume] = errors.New("test error")
+	if err := c.Resume(); err == nil {
+		t.Error("resume should return error")
+	}
+	if status, _ := c.GetStatus(); !status.PauseState.Paused() {
+		t.Error("should still be paused")
+	}
+
+	// resume
+	delete(c.Errors, MethodResume)
+	if err := c.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := c.GetStatus(); status.PauseState.Paused() {
+		t.Error("should not be paused")
+	}
+}
+
+// TestClientSubscribe tests Subscribe and Update of Client
+func TestClientSubscribe(t *testing.T) {
+	c := NewClient(nil, vpnstatus.New())
+	updates, err := c.Subscribe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// current status
+	if got := <-updates; !reflect.DeepEqual(got, vpnstatus.New()) {
+		t.Errorf("got %v", got)
+	}
+
+	// update
+	want := vpnstatus.New()
+	want.IP = "192.168.0.123"
+	go c.Update(want)
+	if got := <-updates; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// close
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-updates; ok {
+		t.Error("updates should be closed")
+	}
+}
+
+// TestClientSplitExcludes tests the split excludes of Client
+func TestClientSplitExcludes(t *testing.T) {
+	c := NewClient(nil, nil)
+	_ = c.AddSplitExclude("192.168.1.0/24")
+	_ = c.AddSplitExclude("10.0.0.0/8")
+	_ = c.RemoveSplitExclude("192.168.1.0/24")
+
+	got, err := c.ListSplitExcludes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"10.0.0.0/8"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestClientValidateConfig tests ValidateConfig of Client
+func TestClientValidateConfig(t *testing.T) {
+	c := NewClient(nil, nil)
+	c.Findings = []*client.Finding{{
+		Severity: client.SeverityError,
+		Field:    "LogLevel",
+		Message:  "invalid setting",
+	}}
+
+	got, err := c.ValidateConfig(client.ValidateKindConfig, []byte("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, c.Findings) {
+		t.Errorf("got %v, want %v", got, c.Findings)
+	}
+}
+
+// TestClientGetUsage tests GetUsage of Client
+func TestClientGetUsage(t *testing.T) {
+	c := NewClient(nil, nil)
+	c.Usage = []*client.Usage{{
+		Month:       "2026-10",
+		RXBytes:     2048,
+		Connections: 1,
+	}}
+
+	got, err := c.GetUsage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, c.Usage) {
+		t.Errorf("got %v, want %v", got, c.Usage)
+	}
+}
+
+// TestClientGetMetrics tests GetMetrics of Client
+func TestClientGetMetrics(t *testing.T) {
+	c := NewClient(nil, nil)
+	c.Metrics = []*client.Metrics{{
+		API:     "dbus",
+		Request: "Connect",
+		Count:   1,
+	}}
+
+	got, err := c.GetMetrics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, c.Metrics) {
+		t.Errorf("got %v, want %v", got, c.Metrics)
+	}
+}
+
+// TestClientGetLogs tests GetLogs of Client
+func TestClientGetLogs(t *testing.T) {
+	c := NewClient(nil, nil)
+	c.Logs = []string{"test 1", "test 2", "test 3"}
+
+	got, err := c.GetLogs(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"test 2", "test 3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	got, _ = c.GetLogs(0)
+	if !reflect.DeepEqual(got, c.Logs) {
+		t.Errorf("got %v, want %v", got, c.Logs)
+	}
+}
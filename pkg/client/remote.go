@@ -0,0 +1,88 @@
+package client
+
+import (
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/godbus/dbus/v5"
+)
+
+var (
+	// sshCommand is the command used to connect to remote hosts
+	sshCommand = []string{"ssh", "-xT"}
+
+	// remoteBridge is the command run on remote hosts to forward the
+	// D-Bus connection to the system bus
+	remoteBridge = []string{"systemd-stdio-bridge"}
+)
+
+// execConn is a connection over stdin and stdout of a command
+type execConn struct {
+	io.ReadCloser
+	io.WriteCloser
+
+	cmd *exec.Cmd
+}
+
+// Close closes the connection and stops the command
+func (e *execConn) Close() error {
+	_ = e.WriteCloser.Close()
+	_ = e.ReadCloser.Close()
+	if e.cmd.Process != nil {
+		_ = e.cmd.Process.Kill()
+	}
+	_ = e.cmd.Wait()
+	return nil
+}
+
+// newExecConn starts the command with args and returns a connection over
+// its stdin and stdout
+func newExecConn(args ...string) (*execConn, error) {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &execConn{
+		ReadCloser:  stdout,
+		WriteCloser: stdin,
+		cmd:         cmd,
+	}, nil
+}
+
+// dbusConnectRemote connects to the system bus on host over ssh, host is
+// passed to ssh and can contain a user, e.g., "user@machine"
+var dbusConnectRemote = func(host string) (*dbus.Conn, error) {
+	args := append(sshCommand[:len(sshCommand):len(sshCommand)], host)
+	args = append(args, remoteBridge...)
+	rwc, err := newExecConn(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := dbus.NewConn(rwc)
+	if err != nil {
+		_ = rwc.Close()
+		return nil, err
+	}
+
+	// the bridge is already authenticated on the remote system bus
+	if err := conn.Auth([]dbus.Auth{dbus.AuthAnonymous()}); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if err := conn.Hello(); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
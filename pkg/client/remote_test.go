@@ -0,0 +1,69 @@
+package client
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// TestNewExecConn tests newExecConn
+func TestNewExecConn(t *testing.T) {
+	conn, err := newExecConn("cat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	want := []byte("test")
+	if _, err := conn.Write(want); err != nil {
+		t.Fatal(err)
+	}
+	got := make([]byte, len(want))
+	if _, err := io.ReadFull(conn, got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+// TestDBusConnectRemote tests dbusConnectRemote with an invalid bridge
+func TestDBusConnectRemote(t *testing.T) {
+	oldSSH, oldBridge := sshCommand, remoteBridge
+	defer func() { sshCommand, remoteBridge = oldSSH, oldBridge }()
+
+	// "true" exits immediately, so authentication must fail
+	sshCommand = []string{"true"}
+	remoteBridge = nil
+	if _, err := dbusConnectRemote("user@machine"); err == nil {
+		t.Errorf("connect should fail")
+	}
+}
+
+// TestNewRemoteClient tests NewRemoteClient
+func TestNewRemoteClient(t *testing.T) {
+	oldConnect := dbusConnectRemote
+	defer func() { dbusConnectRemote = oldConnect }()
+
+	host := ""
+	dbusConnectRemote = func(h string) (*dbus.Conn, error) {
+		host = h
+		return nil, nil
+	}
+	config := NewConfig()
+	client, err := NewRemoteClient(config, "user@machine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if host != "user@machine" {
+		t.Errorf("got %s, want user@machine", host)
+	}
+	if !reflect.DeepEqual(client.GetConfig(), config) {
+		t.Errorf("got %v, want %v", client.GetConfig(), config)
+	}
+	if err := client.Close(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TLS versions
+const (
+	TLSVersion12 = "1.2"
+	TLSVersion13 = "1.3"
+)
+
+// tlsVersions are the GnuTLS priority string versions enabled for each
+// minimum TLS version
+var tlsVersions = map[string]string{
+	TLSVersion12: "-VERS-ALL:+VERS-TLS1.3:+VERS-TLS1.2",
+	TLSVersion13: "-VERS-ALL:+VERS-TLS1.3",
+}
+
+// tlsPriority returns the GnuTLS priority string for the TLS settings in
+// config, empty if the OpenConnect defaults should be used
+func tlsPriority(config *Config) (string, error) {
+	if config.TLSPriority != "" {
+		return config.TLSPriority, nil
+	}
+	if config.MinTLSVersion == "" && len(config.TLSGroups) == 0 {
+		return "", nil
+	}
+
+	priority := []string{"NORMAL"}
+	if config.MinTLSVersion != "" {
+		versions, ok := tlsVersions[config.MinTLSVersion]
+		if !ok {
+			return "", fmt.Errorf("invalid minimum TLS version: %s",
+				config.MinTLSVersion)
+		}
+		priority = append(priority, versions)
+	}
+	if len(config.TLSGroups) > 0 {
+		priority = append(priority, "-GROUP-ALL")
+		for _, g := range config.TLSGroups {
+			if g == "" || strings.ContainsAny(g, ":+! ") {
+				return "", fmt.Errorf("invalid TLS group: %q", g)
+			}
+			priority = append(priority, "+GROUP-"+strings.ToUpper(g))
+		}
+	}
+	return strings.Join(priority, ":"), nil
+}
@@ -0,0 +1,57 @@
+package client
+
+import "testing"
+
+// TestTLSPriority tests tlsPriority
+func TestTLSPriority(t *testing.T) {
+	// test valid
+	for _, test := range []struct {
+		config *Config
+		want   string
+	}{
+		{&Config{}, ""},
+		{&Config{TLSPriority: "SECURE256"}, "SECURE256"},
+		{
+			&Config{MinTLSVersion: TLSVersion12},
+			"NORMAL:-VERS-ALL:+VERS-TLS1.3:+VERS-TLS1.2",
+		},
+		{
+			&Config{MinTLSVersion: TLSVersion13},
+			"NORMAL:-VERS-ALL:+VERS-TLS1.3",
+		},
+		{
+			&Config{
+				MinTLSVersion: TLSVersion13,
+				TLSGroups:     []string{"x25519-mlkem768", "X25519"},
+			},
+			"NORMAL:-VERS-ALL:+VERS-TLS1.3:-GROUP-ALL:" +
+				"+GROUP-X25519-MLKEM768:+GROUP-X25519",
+		},
+		{
+			&Config{
+				TLSPriority:   "SECURE256",
+				MinTLSVersion: TLSVersion12,
+			},
+			"SECURE256",
+		},
+	} {
+		got, err := tlsPriority(test.config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("got %s, want %s", got, test.want)
+		}
+	}
+
+	// test invalid
+	for _, invalid := range []*Config{
+		{MinTLSVersion: "1.1"},
+		{TLSGroups: []string{""}},
+		{TLSGroups: []string{"X25519:+VERS-TLS1.0"}},
+	} {
+		if _, err := tlsPriority(invalid); err == nil {
+			t.Errorf("config should be invalid: %v", invalid)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package grpcapi
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// ipString returns ip as string, empty if ip is not set
+func ipString(ip net.IP) string {
+	if len(ip) == 0 {
+		return ""
+	}
+	return ip.String()
+}
+
+// maskString returns mask as string in IP address notation, e.g.,
+// "255.255.255.0", empty if mask is not set
+func maskString(mask net.IPMask) string {
+	if len(mask) == 0 {
+		return ""
+	}
+	return net.IP(mask).String()
+}
+
+// ipStrings returns ips as strings
+func ipStrings(ips []net.IP) []string {
+	s := []string{}
+	for _, ip := range ips {
+		s = append(s, ipString(ip))
+	}
+	return s
+}
+
+// ipNetStrings returns ipnets as strings
+func ipNetStrings(ipnets []*net.IPNet) []string {
+	s := []string{}
+	for _, ipnet := range ipnets {
+		s = append(s, ipnet.String())
+	}
+	return s
+}
+
+// parseIP parses the IP address in s, it returns nil if s is empty
+func parseIP(s string) (net.IP, error) {
+	if s == "" {
+		return nil, nil
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address %q", s)
+	}
+	return ip, nil
+}
+
+// parseMask parses the netmask in IP address notation in s, it returns nil
+// if s is empty
+func parseMask(s string) (net.IPMask, error) {
+	ip, err := parseIP(s)
+	if err != nil || ip == nil {
+		return nil, err
+	}
+	if ip4 := ip.To4(); ip4 != nil && strings.Contains(s, ".") {
+		return net.IPMask(ip4), nil
+	}
+	return net.IPMask(ip), nil
+}
+
+// parseIPs parses the IP addresses in s, it returns nil if s is empty
+func parseIPs(s []string) ([]net.IP, error) {
+	var ips []net.IP
+	for _, i := range s {
+		ip, err := parseIP(i)
+		if err != nil {
+			return nil, err
+		}
+		ips = append(ips, ip)
+	}
+	return ips, nil
+}
+
+// parseIPNets parses the networks in s, it returns nil if s is empty
+func parseIPNets(s []string) ([]*net.IPNet, error) {
+	var ipnets []*net.IPNet
+	for _, i := range s {
+		_, ipnet, err := net.ParseCIDR(i)
+		if err != nil {
+			return nil, err
+		}
+		ipnets = append(ipnets, ipnet)
+	}
+	return ipnets, nil
+}
+
+// NewVPNConfig returns the VPN configuration c as VPNConfig, nil if c is nil
+func NewVPNConfig(c *vpnconfig.Config) *VPNConfig {
+	if c == nil {
+		return nil
+	}
+	var transports map[string]string
+	if len(c.DNS.Transports) > 0 {
+		transports = make(map[string]string)
+		for k, v := range c.DNS.Transports {
+			transports[k] = v
+		}
+	}
+	return &VPNConfig{
+		Gateway: ipString(c.Gateway),
+		Pid:     int32(c.PID),
+		Timeout: int32(c.Timeout),
+		Device: &VPNConfig_Device{
+			Name: c.Device.Name,
+			Mtu:  int32(c.Device.MTU),
+		},
+		Ipv4: &VPNConfig_Address{
+			Address: ipString(c.IPv4.Address),
+			Netmask: maskString(c.IPv4.Netmask),
+		},
+		Ipv6: &VPNConfig_Address{
+			Address: ipString(c.IPv6.Address),
+			Netmask: maskString(c.IPv6.Netmask),
+		},
+		Dns: &VPNConfig_DNS{
+			DefaultDomain: c.DNS.DefaultDomain,
+			ServersIpv4:   ipStrings(c.DNS.ServersIPv4),
+			ServersIpv6:   ipStrings(c.DNS.ServersIPv6),
+			SplitDomains:  append([]string{}, c.DNS.SplitDomains...),
+			TunnelAll:     c.DNS.TunnelAll,
+			Transports:    transports,
+		},
+		Split: &VPNConfig_Split{
+			IncludeIpv4: ipNetStrings(c.Split.IncludeIPv4),
+			IncludeIpv6: ipNetStrings(c.Split.IncludeIPv6),
+			ExcludeIpv4: ipNetStrings(c.Split.ExcludeIPv4),
+			ExcludeIpv6: ipNetStrings(c.Split.ExcludeIPv6),
+			ExcludeDns:  append([]string{}, c.Split.ExcludeDNS...),
+
+			ExcludeVirtualSubnetsOnlyIpv4: c.Split.ExcludeVirtualSubnetsOnlyIPv4,
+		},
+		Flags: &VPNConfig_Flags{
+			DisableAlwaysOnVpn: c.Flags.DisableAlwaysOnVPN,
+		},
+		Banner: &VPNConfig_Banner{
+			Text:     c.Banner.Text,
+			Language: c.Banner.Language,
+		},
+	}
+}
+
+// ParseVPNConfig returns c as VPN configuration, it returns an error if
+// addresses or networks in c are invalid
+func ParseVPNConfig(c *VPNConfig) (*vpnconfig.Config, error) {
+	if c == nil {
+		return nil, nil
+	}
+	config := vpnconfig.New()
+	var err error
+
+	// gateway, device and addresses
+	if config.Gateway, err = parseIP(c.GetGateway()); err != nil {
+		return nil, err
+	}
+	config.PID = int(c.GetPid())
+	config.Timeout = int(c.GetTimeout())
+	config.Device.Name = c.GetDevice().GetName()
+	config.Device.MTU = int(c.GetDevice().GetMtu())
+	if config.IPv4.Address, err = parseIP(c.GetIpv4().GetAddress()); err != nil {
+		return nil, err
+	}
+	if config.IPv4.Netmask, err = parseMask(c.GetIpv4().GetNetmask()); err != nil {
+		return nil, err
+	}
+	if config.IPv6.Address, err = parseIP(c.GetIpv6().GetAddress()); err != nil {
+		return nil, err
+	}
+	if config.IPv6.Netmask, err = parseMask(c.GetIpv6().GetNetmask()); err != nil {
+		return nil, err
+	}
+
+	// dns
+	dns := c.GetDns()
+	config.DNS.DefaultDomain = dns.GetDefaultDomain()
+	if config.DNS.ServersIPv4, err = parseIPs(dns.GetServersIpv4()); err != nil {
+		return nil, err
+	}
+	if config.DNS.ServersIPv6, err = parseIPs(dns.GetServersIpv6()); err != nil {
+		return nil, err
+	}
+	if len(dns.GetSplitDomains()) > 0 {
+		config.DNS.SplitDomains = append([]string{}, dns.GetSplitDomains()...)
+	}
+	config.DNS.TunnelAll = dns.GetTunnelAll()
+	if len(dns.GetTransports()) > 0 {
+		config.DNS.Transports = make(map[string]string)
+		for k, v := range dns.GetTransports() {
+			config.DNS.Transports[k] = v
+		}
+	}
+
+	// split routing
+	split := c.GetSplit()
+	if config.Split.IncludeIPv4, err = parseIPNets(split.GetIncludeIpv4()); err != nil {
+		return nil, err
+	}
+	if config.Split.IncludeIPv6, err = parseIPNets(split.GetIncludeIpv6()); err != nil {
+		return nil, err
+	}
+	if config.Split.ExcludeIPv4, err = parseIPNets(split.GetExcludeIpv4()); err != nil {
+		return nil, err
+	}
+	if config.Split.ExcludeIPv6, err = parseIPNets(split.GetExcludeIpv6()); err != nil {
+		return nil, err
+	}
+	if len(split.GetExcludeDns()) > 0 {
+		config.Split.ExcludeDNS = append([]string{}, split.GetExcludeDns()...)
+	}
+	config.Split.ExcludeVirtualSubnetsOnlyIPv4 =
+		split.GetExcludeVirtualSubnetsOnlyIpv4()
+
+	// flags and banner
+	config.Flags.DisableAlwaysOnVPN = c.GetFlags().GetDisableAlwaysOnVpn()
+	config.Banner.Text = c.GetBanner().GetText()
+	config.Banner.Language = c.GetBanner().GetLanguage()
+
+	return config, nil
+}
+
+// NewStatus returns the status s as Status
+func NewStatus(s *vpnstatus.Status) *Status {
+	return &Status{
+		TrustedNetwork:    uint32(s.TrustedNetwork),
+		ConnectionState:   uint32(s.ConnectionState),
+		Ip:                s.IP,
+		Device:            s.Device,
+		ConnectedAt:       s.ConnectedAt,
+		Servers:           s.Servers,
+		OcRunning:         uint32(s.OCRunning),
+		VpnConfig:         NewVPNConfig(s.VPNConfig),
+		Proxy:             s.Proxy,
+		RetryAt:           s.RetryAt,
+		RetryAttempt:      s.RetryAttempt,
+		RxBytes:           s.RXBytes,
+		TxBytes:           s.TXBytes,
+		RxPackets:         s.RXPackets,
+		TxPackets:         s.TXPackets,
+		DnsLeaksBlocked:   s.DNSLeaksBlocked,
+		RouteAggregations: s.RouteAggregations,
+		GatewayExcludes:   s.GatewayExcludes,
+		DnsServers:        s.DNSServers,
+		DnsSearchDomains:  s.DNSSearchDomains,
+		DnsSplitDomains:   s.DNSSplitDomains,
+		ScheduleState:     uint32(s.ScheduleState),
+		Connectivity:      uint32(s.Connectivity),
+		Compression:       s.Compression,
+		TndState:          uint32(s.TNDState),
+		CsdWrapper:        s.CSDWrapper,
+		DisconnectReason:  s.DisconnectReason,
+		Apis:              s.APIs,
+		TrafPolState:      uint32(s.TrafPolState),
+		PreferredServer:   s.PreferredServer,
+		Uptime:            s.Uptime,
+		OwnerUid:          s.OwnerUID,
+		Owner:             s.Owner,
+		PauseState:        uint32(s.PauseState),
+	}
+}
@@ -0,0 +1,118 @@
+package grpcapi
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// testVPNConfig returns a VPN configuration for testing
+func testVPNConfig() *vpnconfig.Config {
+	c := vpnconfig.New()
+	c.Gateway = net.ParseIP("192.168.0.1")
+	c.PID = 123
+	c.Timeout = 300
+	c.Device.Name = "oc-daemon-tun0"
+	c.Device.MTU = 1300
+	c.IPv4.Address = net.ParseIP("192.168.0.123")
+	c.IPv4.Netmask = net.CIDRMask(24, 32)
+	c.IPv6.Address = net.ParseIP("2001:42:42:42::1")
+	c.IPv6.Netmask = net.CIDRMask(64, 128)
+	c.DNS.DefaultDomain = "mycompany.com"
+	c.DNS.ServersIPv4 = []net.IP{net.ParseIP("192.168.0.53")}
+	c.DNS.ServersIPv6 = []net.IP{net.ParseIP("2001:53:53:53::53")}
+	c.DNS.SplitDomains = []string{"internal.mycompany.com"}
+	c.DNS.Transports = map[string]string{"192.168.0.53": "tcp"}
+	_, include, _ := net.ParseCIDR("10.0.0.0/8")
+	_, exclude, _ := net.ParseCIDR("10.1.0.0/16")
+	_, exclude6, _ := net.ParseCIDR("2001:1::/32")
+	c.Split.IncludeIPv4 = []*net.IPNet{include}
+	c.Split.ExcludeIPv4 = []*net.IPNet{exclude}
+	c.Split.ExcludeIPv6 = []*net.IPNet{exclude6}
+	c.Split.ExcludeDNS = []string{"this.other.com"}
+	c.Split.ExcludeVirtualSubnetsOnlyIPv4 = true
+	c.Flags.DisableAlwaysOnVPN = true
+	c.Banner.Text = "Welcome"
+	c.Banner.Language = "en"
+	return c
+}
+
+// TestVPNConfig tests NewVPNConfig and ParseVPNConfig
+func TestVPNConfig(t *testing.T) {
+	// nil config
+	if NewVPNConfig(nil) != nil {
+		t.Error("nil config should be nil")
+	}
+	if c, err := ParseVPNConfig(nil); c != nil || err != nil {
+		t.Errorf("got %v, %v, want nil", c, err)
+	}
+
+	// valid config
+	want := testVPNConfig()
+	c := NewVPNConfig(want)
+	if c.GetIpv4().GetNetmask() != "255.255.255.0" {
+		t.Errorf("got %q, want 255.255.255.0", c.GetIpv4().GetNetmask())
+	}
+	got, err := ParseVPNConfig(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// empty config
+	got, err = ParseVPNConfig(&VPNConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Empty() {
+		t.Errorf("got %v, want empty config", got)
+	}
+
+	// invalid addresses and networks
+	for _, invalid := range []*VPNConfig{
+		{Gateway: "invalid"},
+		{Ipv4: &VPNConfig_Address{Address: "invalid"}},
+		{Ipv4: &VPNConfig_Address{Netmask: "invalid"}},
+		{Ipv6: &VPNConfig_Address{Address: "invalid"}},
+		{Ipv6: &VPNConfig_Address{Netmask: "invalid"}},
+		{Dns: &VPNConfig_DNS{ServersIpv4: []string{"invalid"}}},
+		{Dns: &VPNConfig_DNS{ServersIpv6: []string{"invalid"}}},
+		{Split: &VPNConfig_Split{IncludeIpv4: []string{"10.0.0.1"}}},
+		{Split: &VPNConfig_Split{IncludeIpv6: []string{"invalid"}}},
+		{Split: &VPNConfig_Split{ExcludeIpv4: []string{"invalid"}}},
+		{Split: &VPNConfig_Split{ExcludeIpv6: []string{"invalid"}}},
+	} {
+		if _, err := ParseVPNConfig(invalid); err == nil {
+			t.Errorf("%v: config should be invalid", invalid)
+		}
+	}
+}
+
+// TestNewStatus tests NewStatus
+func TestNewStatus(t *testing.T) {
+	s := vpnstatus.New()
+	s.ConnectionState = vpnstatus.ConnectionStateConnected
+	s.IP = "192.168.0.123"
+	s.Servers = []string{"server1", "server2"}
+	s.VPNConfig = testVPNConfig()
+	s.APIs = []string{vpnstatus.APISocket, vpnstatus.APIGRPC}
+	s.OwnerUID = 1000
+
+	got := NewStatus(s)
+	if got.GetConnectionState() != uint32(vpnstatus.ConnectionStateConnected) ||
+		got.GetIp() != s.IP || got.GetOwnerUid() != s.OwnerUID {
+		t.Errorf("got %v, want %v", got, s)
+	}
+	if !reflect.DeepEqual(got.GetServers(), s.Servers) ||
+		!reflect.DeepEqual(got.GetApis(), s.APIs) {
+		t.Errorf("got %v, want %v", got, s)
+	}
+	if got.GetVpnConfig().GetDevice().GetName() != "oc-daemon-tun0" {
+		t.Errorf("got %v, want %v", got.GetVpnConfig(), s.VPNConfig)
+	}
+}
@@ -0,0 +1,1703 @@
+// Protocol buffer definitions of the gRPC API of the oc-daemon.
+//
+// The gRPC API is an optional alternative to the framed-message protocol on
+// the unix socket of the daemon for non-Go integrations. Property and signal
+// names in events are the names in the D-Bus API.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        v3.21.12
+// source: daemon.proto
+
+package grpcapi
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// GetStatusRequest is the request of GetStatus
+type GetStatusRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetStatusRequest) Reset() {
+	*x = GetStatusRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusRequest) ProtoMessage() {}
+
+func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetStatusRequest) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{0}
+}
+
+// SubscribeRequest is the request of Subscribe
+type SubscribeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{1}
+}
+
+// GetChallengeRequest is the request of GetChallenge
+type GetChallengeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetChallengeRequest) Reset() {
+	*x = GetChallengeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetChallengeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetChallengeRequest) ProtoMessage() {}
+
+func (x *GetChallengeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetChallengeRequest.ProtoReflect.Descriptor instead.
+func (*GetChallengeRequest) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{2}
+}
+
+// UpdateVPNConfigResponse is the response of UpdateVPNConfig
+type UpdateVPNConfigResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *UpdateVPNConfigResponse) Reset() {
+	*x = UpdateVPNConfigResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateVPNConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateVPNConfigResponse) ProtoMessage() {}
+
+func (x *UpdateVPNConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateVPNConfigResponse.ProtoReflect.Descriptor instead.
+func (*UpdateVPNConfigResponse) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{3}
+}
+
+// Challenge is a challenge for authenticating a VPN config update
+type Challenge struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Challenge []byte `protobuf:"bytes,1,opt,name=challenge,proto3" json:"challenge,omitempty"`
+	// expires is the expiry time of the challenge as unix time in seconds
+	Expires int64 `protobuf:"varint,2,opt,name=expires,proto3" json:"expires,omitempty"`
+}
+
+func (x *Challenge) Reset() {
+	*x = Challenge{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Challenge) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Challenge) ProtoMessage() {}
+
+func (x *Challenge) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Challenge.ProtoReflect.Descriptor instead.
+func (*Challenge) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Challenge) GetChallenge() []byte {
+	if x != nil {
+		return x.Challenge
+	}
+	return nil
+}
+
+func (x *Challenge) GetExpires() int64 {
+	if x != nil {
+		return x.Expires
+	}
+	return 0
+}
+
+// VPNConfigUpdate is a VPN config update: reason is "connect", "disconnect"
+// or "attempt-reconnect"; response is the HMAC-SHA256 of challenge with the
+// secret of the VPN connection; config is not set on disconnect
+type VPNConfigUpdate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Reason    string     `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	Challenge []byte     `protobuf:"bytes,2,opt,name=challenge,proto3" json:"challenge,omitempty"`
+	Response  []byte     `protobuf:"bytes,3,opt,name=response,proto3" json:"response,omitempty"`
+	Config    *VPNConfig `protobuf:"bytes,4,opt,name=config,proto3" json:"config,omitempty"`
+}
+
+func (x *VPNConfigUpdate) Reset() {
+	*x = VPNConfigUpdate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VPNConfigUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VPNConfigUpdate) ProtoMessage() {}
+
+func (x *VPNConfigUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VPNConfigUpdate.ProtoReflect.Descriptor instead.
+func (*VPNConfigUpdate) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *VPNConfigUpdate) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *VPNConfigUpdate) GetChallenge() []byte {
+	if x != nil {
+		return x.Challenge
+	}
+	return nil
+}
+
+func (x *VPNConfigUpdate) GetResponse() []byte {
+	if x != nil {
+		return x.Response
+	}
+	return nil
+}
+
+func (x *VPNConfigUpdate) GetConfig() *VPNConfig {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+// VPNConfig is a VPN configuration, IP addresses, netmasks and networks are
+// in their text representation, e.g., "10.0.0.0/8"
+type VPNConfig struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Gateway string             `protobuf:"bytes,1,opt,name=gateway,proto3" json:"gateway,omitempty"`
+	Pid     int32              `protobuf:"varint,2,opt,name=pid,proto3" json:"pid,omitempty"`
+	Timeout int32              `protobuf:"varint,3,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	Device  *VPNConfig_Device  `protobuf:"bytes,4,opt,name=device,proto3" json:"device,omitempty"`
+	Ipv4    *VPNConfig_Address `protobuf:"bytes,5,opt,name=ipv4,proto3" json:"ipv4,omitempty"`
+	Ipv6    *VPNConfig_Address `protobuf:"bytes,6,opt,name=ipv6,proto3" json:"ipv6,omitempty"`
+	Dns     *VPNConfig_DNS     `protobuf:"bytes,7,opt,name=dns,proto3" json:"dns,omitempty"`
+	Split   *VPNConfig_Split   `protobuf:"bytes,8,opt,name=split,proto3" json:"split,omitempty"`
+	Flags   *VPNConfig_Flags   `protobuf:"bytes,9,opt,name=flags,proto3" json:"flags,omitempty"`
+	Banner  *VPNConfig_Banner  `protobuf:"bytes,10,opt,name=banner,proto3" json:"banner,omitempty"`
+}
+
+func (x *VPNConfig) Reset() {
+	*x = VPNConfig{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VPNConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VPNConfig) ProtoMessage() {}
+
+func (x *VPNConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VPNConfig.ProtoReflect.Descriptor instead.
+func (*VPNConfig) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *VPNConfig) GetGateway() string {
+	if x != nil {
+		return x.Gateway
+	}
+	return ""
+}
+
+func (x *VPNConfig) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *VPNConfig) GetTimeout() int32 {
+	if x != nil {
+		return x.Timeout
+	}
+	return 0
+}
+
+func (x *VPNConfig) GetDevice() *VPNConfig_Device {
+	if x != nil {
+		return x.Device
+	}
+	return nil
+}
+
+func (x *VPNConfig) GetIpv4() *VPNConfig_Address {
+	if x != nil {
+		return x.Ipv4
+	}
+	return nil
+}
+
+func (x *VPNConfig) GetIpv6() *VPNConfig_Address {
+	if x != nil {
+		return x.Ipv6
+	}
+	return nil
+}
+
+func (x *VPNConfig) GetDns() *VPNConfig_DNS {
+	if x != nil {
+		return x.Dns
+	}
+	return nil
+}
+
+func (x *VPNConfig) GetSplit() *VPNConfig_Split {
+	if x != nil {
+		return x.Split
+	}
+	return nil
+}
+
+func (x *VPNConfig) GetFlags() *VPNConfig_Flags {
+	if x != nil {
+		return x.Flags
+	}
+	return nil
+}
+
+func (x *VPNConfig) GetBanner() *VPNConfig_Banner {
+	if x != nil {
+		return x.Banner
+	}
+	return nil
+}
+
+// Status is the status of the daemon, the numeric states have the values of
+// the corresponding properties in the D-Bus API
+type Status struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TrustedNetwork    uint32     `protobuf:"varint,1,opt,name=trusted_network,json=trustedNetwork,proto3" json:"trusted_network,omitempty"`
+	ConnectionState   uint32     `protobuf:"varint,2,opt,name=connection_state,json=connectionState,proto3" json:"connection_state,omitempty"`
+	Ip                string     `protobuf:"bytes,3,opt,name=ip,proto3" json:"ip,omitempty"`
+	Device            string     `protobuf:"bytes,4,opt,name=device,proto3" json:"device,omitempty"`
+	ConnectedAt       int64      `protobuf:"varint,5,opt,name=connected_at,json=connectedAt,proto3" json:"connected_at,omitempty"`
+	Servers           []string   `protobuf:"bytes,6,rep,name=servers,proto3" json:"servers,omitempty"`
+	OcRunning         uint32     `protobuf:"varint,7,opt,name=oc_running,json=ocRunning,proto3" json:"oc_running,omitempty"`
+	VpnConfig         *VPNConfig `protobuf:"bytes,8,opt,name=vpn_config,json=vpnConfig,proto3" json:"vpn_config,omitempty"`
+	Proxy             string     `protobuf:"bytes,9,opt,name=proxy,proto3" json:"proxy,omitempty"`
+	RetryAt           int64      `protobuf:"varint,10,opt,name=retry_at,json=retryAt,proto3" json:"retry_at,omitempty"`
+	RetryAttempt      uint32     `protobuf:"varint,11,opt,name=retry_attempt,json=retryAttempt,proto3" json:"retry_attempt,omitempty"`
+	RxBytes           uint64     `protobuf:"varint,12,opt,name=rx_bytes,json=rxBytes,proto3" json:"rx_bytes,omitempty"`
+	TxBytes           uint64     `protobuf:"varint,13,opt,name=tx_bytes,json=txBytes,proto3" json:"tx_bytes,omitempty"`
+	RxPackets         uint64     `protobuf:"varint,14,opt,name=rx_packets,json=rxPackets,proto3" json:"rx_packets,omitempty"`
+	TxPackets         uint64     `protobuf:"varint,15,opt,name=tx_packets,json=txPackets,proto3" json:"tx_packets,omitempty"`
+	DnsLeaksBlocked   uint64     `protobuf:"varint,16,opt,name=dns_leaks_blocked,json=dnsLeaksBlocked,proto3" json:"dns_leaks_blocked,omitempty"`
+	RouteAggregations uint64     `protobuf:"varint,17,opt,name=route_aggregations,json=routeAggregations,proto3" json:"route_aggregations,omitempty"`
+	GatewayExcludes   []string   `protobuf:"bytes,18,rep,name=gateway_excludes,json=gatewayExcludes,proto3" json:"gateway_excludes,omitempty"`
+	DnsServers        []string   `protobuf:"bytes,19,rep,name=dns_servers,json=dnsServers,proto3" json:"dns_servers,omitempty"`
+	DnsSearchDomains  []string   `protobuf:"bytes,20,rep,name=dns_search_domains,json=dnsSearchDomains,proto3" json:"dns_search_domains,omitempty"`
+	DnsSplitDomains   []string   `protobuf:"bytes,21,rep,name=dns_split_domains,json=dnsSplitDomains,proto3" json:"dns_split_domains,omitempty"`
+	ScheduleState     uint32     `protobuf:"varint,22,opt,name=schedule_state,json=scheduleState,proto3" json:"schedule_state,omitempty"`
+	Connectivity      uint32     `protobuf:"varint,23,opt,name=connectivity,proto3" json:"connectivity,omitempty"`
+	Compression       string     `protobuf:"bytes,24,opt,name=compression,proto3" json:"compression,omitempty"`
+	TndState          uint32     `protobuf:"varint,25,opt,name=tnd_state,json=tndState,proto3" json:"tnd_state,omitempty"`
+	CsdWrapper        string     `protobuf:"bytes,26,opt,name=csd_wrapper,json=csdWrapper,proto3" json:"csd_wrapper,omitempty"`
+	DisconnectReason  string     `protobuf:"bytes,27,opt,name=disconnect_reason,json=disconnectReason,proto3" json:"disconnect_reason,omitempty"`
+	Apis              []string   `protobuf:"bytes,28,rep,name=apis,proto3" json:"apis,omitempty"`
+	TrafPolState      uint32     `protobuf:"varint,29,opt,name=traf_pol_state,json=trafPolState,proto3" json:"traf_pol_state,omitempty"`
+	PreferredServer   string     `protobuf:"bytes,30,opt,name=preferred_server,json=preferredServer,proto3" json:"preferred_server,omitempty"`
+	Uptime            uint64     `protobuf:"varint,31,opt,name=uptime,proto3" json:"uptime,omitempty"`
+	OwnerUid          int64      `protobuf:"varint,32,opt,name=owner_uid,json=ownerUid,proto3" json:"owner_uid,omitempty"`
+	Owner             string     `protobuf:"bytes,33,opt,name=owner,proto3" json:"owner,omitempty"`
+	PauseState        uint32     `protobuf:"varint,34,opt,name=pause_state,json=pauseState,proto3" json:"pause_state,omitempty"`
+}
+
+func (x *Status) Reset() {
+	*x = Status{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Status) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Status) ProtoMessage() {}
+
+func (x *Status) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Status.ProtoReflect.Descriptor instead.
+func (*Status) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Status) GetTrustedNetwork() uint32 {
+	if x != nil {
+		return x.TrustedNetwork
+	}
+	return 0
+}
+
+func (x *Status) GetConnectionState() uint32 {
+	if x != nil {
+		return x.ConnectionState
+	}
+	return 0
+}
+
+func (x *Status) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *Status) GetDevice() string {
+	if x != nil {
+		return x.Device
+	}
+	return ""
+}
+
+func (x *Status) GetConnectedAt() int64 {
+	if x != nil {
+		return x.ConnectedAt
+	}
+	return 0
+}
+
+func (x *Status) GetServers() []string {
+	if x != nil {
+		return x.Servers
+	}
+	return nil
+}
+
+func (x *Status) GetOcRunning() uint32 {
+	if x != nil {
+		return x.OcRunning
+	}
+	return 0
+}
+
+func (x *Status) GetVpnConfig() *VPNConfig {
+	if x != nil {
+		return x.VpnConfig
+	}
+	return nil
+}
+
+func (x *Status) GetProxy() string {
+	if x != nil {
+		return x.Proxy
+	}
+	return ""
+}
+
+func (x *Status) GetRetryAt() int64 {
+	if x != nil {
+		return x.RetryAt
+	}
+	return 0
+}
+
+func (x *Status) GetRetryAttempt() uint32 {
+	if x != nil {
+		return x.RetryAttempt
+	}
+	return 0
+}
+
+func (x *Status) GetRxBytes() uint64 {
+	if x != nil {
+		return x.RxBytes
+	}
+	return 0
+}
+
+func (x *Status) GetTxBytes() uint64 {
+	if x != nil {
+		return x.TxBytes
+	}
+	return 0
+}
+
+func (x *Status) GetRxPackets() uint64 {
+	if x != nil {
+		return x.RxPackets
+	}
+	return 0
+}
+
+func (x *Status) GetTxPackets() uint64 {
+	if x != nil {
+		return x.TxPackets
+	}
+	return 0
+}
+
+func (x *Status) GetDnsLeaksBlocked() uint64 {
+	if x != nil {
+		return x.DnsLeaksBlocked
+	}
+	return 0
+}
+
+func (x *Status) GetRouteAggregations() uint64 {
+	if x != nil {
+		return x.RouteAggregations
+	}
+	return 0
+}
+
+func (x *Status) GetGatewayExcludes() []string {
+	if x != nil {
+		return x.GatewayExcludes
+	}
+	return nil
+}
+
+func (x *Status) GetDnsServers() []string {
+	if x != nil {
+		return x.DnsServers
+	}
+	return nil
+}
+
+func (x *Status) GetDnsSearchDomains() []string {
+	if x != nil {
+		return x.DnsSearchDomains
+	}
+	return nil
+}
+
+func (x *Status) GetDnsSplitDomains() []string {
+	if x != nil {
+		return x.DnsSplitDomains
+	}
+	return nil
+}
+
+func (x *Status) GetScheduleState() uint32 {
+	if x != nil {
+		return x.ScheduleState
+	}
+	return 0
+}
+
+func (x *Status) GetConnectivity() uint32 {
+	if x != nil {
+		return x.Connectivity
+	}
+	return 0
+}
+
+func (x *Status) GetCompression() string {
+	if x != nil {
+		return x.Compression
+	}
+	return ""
+}
+
+func (x *Status) GetTndState() uint32 {
+	if x != nil {
+		return x.TndState
+	}
+	return 0
+}
+
+func (x *Status) GetCsdWrapper() string {
+	if x != nil {
+		return x.CsdWrapper
+	}
+	return ""
+}
+
+func (x *Status) GetDisconnectReason() string {
+	if x != nil {
+		return x.DisconnectReason
+	}
+	return ""
+}
+
+func (x *Status) GetApis() []string {
+	if x != nil {
+		return x.Apis
+	}
+	return nil
+}
+
+func (x *Status) GetTrafPolState() uint32 {
+	if x != nil {
+		return x.TrafPolState
+	}
+	return 0
+}
+
+func (x *Status) GetPreferredServer() string {
+	if x != nil {
+		return x.PreferredServer
+	}
+	return ""
+}
+
+func (x *Status) GetUptime() uint64 {
+	if x != nil {
+		return x.Uptime
+	}
+	return 0
+}
+
+func (x *Status) GetOwnerUid() int64 {
+	if x != nil {
+		return x.OwnerUid
+	}
+	return 0
+}
+
+func (x *Status) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *Status) GetPauseState() uint32 {
+	if x != nil {
+		return x.PauseState
+	}
+	return 0
+}
+
+// Event is either the change of the status property with the name property
+// to value or the signal with the name signal and values, value and values
+// are encoded as JSON
+type Event struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Property string `protobuf:"bytes,1,opt,name=property,proto3" json:"property,omitempty"`
+	Value    string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Signal   string `protobuf:"bytes,3,opt,name=signal,proto3" json:"signal,omitempty"`
+	Values   string `protobuf:"bytes,4,opt,name=values,proto3" json:"values,omitempty"`
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Event) GetProperty() string {
+	if x != nil {
+		return x.Property
+	}
+	return ""
+}
+
+func (x *Event) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *Event) GetSignal() string {
+	if x != nil {
+		return x.Signal
+	}
+	return ""
+}
+
+func (x *Event) GetValues() string {
+	if x != nil {
+		return x.Values
+	}
+	return ""
+}
+
+// Device is a VPN device configuration
+type VPNConfig_Device struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Mtu  int32  `protobuf:"varint,2,opt,name=mtu,proto3" json:"mtu,omitempty"`
+}
+
+func (x *VPNConfig_Device) Reset() {
+	*x = VPNConfig_Device{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VPNConfig_Device) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VPNConfig_Device) ProtoMessage() {}
+
+func (x *VPNConfig_Device) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VPNConfig_Device.ProtoReflect.Descriptor instead.
+func (*VPNConfig_Device) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{6, 0}
+}
+
+func (x *VPNConfig_Device) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *VPNConfig_Device) GetMtu() int32 {
+	if x != nil {
+		return x.Mtu
+	}
+	return 0
+}
+
+// Address is an IPv4 or IPv6 address configuration
+type VPNConfig_Address struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Address string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Netmask string `protobuf:"bytes,2,opt,name=netmask,proto3" json:"netmask,omitempty"`
+}
+
+func (x *VPNConfig_Address) Reset() {
+	*x = VPNConfig_Address{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VPNConfig_Address) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VPNConfig_Address) ProtoMessage() {}
+
+func (x *VPNConfig_Address) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VPNConfig_Address.ProtoReflect.Descriptor instead.
+func (*VPNConfig_Address) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{6, 1}
+}
+
+func (x *VPNConfig_Address) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *VPNConfig_Address) GetNetmask() string {
+	if x != nil {
+		return x.Netmask
+	}
+	return ""
+}
+
+// DNS is a DNS configuration, transports maps DNS server IP addresses
+// to "udp", "tcp" or "tls"
+type VPNConfig_DNS struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DefaultDomain string            `protobuf:"bytes,1,opt,name=default_domain,json=defaultDomain,proto3" json:"default_domain,omitempty"`
+	ServersIpv4   []string          `protobuf:"bytes,2,rep,name=servers_ipv4,json=serversIpv4,proto3" json:"servers_ipv4,omitempty"`
+	ServersIpv6   []string          `protobuf:"bytes,3,rep,name=servers_ipv6,json=serversIpv6,proto3" json:"servers_ipv6,omitempty"`
+	SplitDomains  []string          `protobuf:"bytes,4,rep,name=split_domains,json=splitDomains,proto3" json:"split_domains,omitempty"`
+	TunnelAll     bool              `protobuf:"varint,5,opt,name=tunnel_all,json=tunnelAll,proto3" json:"tunnel_all,omitempty"`
+	Transports    map[string]string `protobuf:"bytes,6,rep,name=transports,proto3" json:"transports,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *VPNConfig_DNS) Reset() {
+	*x = VPNConfig_DNS{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VPNConfig_DNS) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VPNConfig_DNS) ProtoMessage() {}
+
+func (x *VPNConfig_DNS) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VPNConfig_DNS.ProtoReflect.Descriptor instead.
+func (*VPNConfig_DNS) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{6, 2}
+}
+
+func (x *VPNConfig_DNS) GetDefaultDomain() string {
+	if x != nil {
+		return x.DefaultDomain
+	}
+	return ""
+}
+
+func (x *VPNConfig_DNS) GetServersIpv4() []string {
+	if x != nil {
+		return x.ServersIpv4
+	}
+	return nil
+}
+
+func (x *VPNConfig_DNS) GetServersIpv6() []string {
+	if x != nil {
+		return x.ServersIpv6
+	}
+	return nil
+}
+
+func (x *VPNConfig_DNS) GetSplitDomains() []string {
+	if x != nil {
+		return x.SplitDomains
+	}
+	return nil
+}
+
+func (x *VPNConfig_DNS) GetTunnelAll() bool {
+	if x != nil {
+		return x.TunnelAll
+	}
+	return false
+}
+
+func (x *VPNConfig_DNS) GetTransports() map[string]string {
+	if x != nil {
+		return x.Transports
+	}
+	return nil
+}
+
+// Split is a split routing configuration
+type VPNConfig_Split struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IncludeIpv4                   []string `protobuf:"bytes,1,rep,name=include_ipv4,json=includeIpv4,proto3" json:"include_ipv4,omitempty"`
+	IncludeIpv6                   []string `protobuf:"bytes,2,rep,name=include_ipv6,json=includeIpv6,proto3" json:"include_ipv6,omitempty"`
+	ExcludeIpv4                   []string `protobuf:"bytes,3,rep,name=exclude_ipv4,json=excludeIpv4,proto3" json:"exclude_ipv4,omitempty"`
+	ExcludeIpv6                   []string `protobuf:"bytes,4,rep,name=exclude_ipv6,json=excludeIpv6,proto3" json:"exclude_ipv6,omitempty"`
+	ExcludeDns                    []string `protobuf:"bytes,5,rep,name=exclude_dns,json=excludeDns,proto3" json:"exclude_dns,omitempty"`
+	ExcludeVirtualSubnetsOnlyIpv4 bool     `protobuf:"varint,6,opt,name=exclude_virtual_subnets_only_ipv4,json=excludeVirtualSubnetsOnlyIpv4,proto3" json:"exclude_virtual_subnets_only_ipv4,omitempty"`
+}
+
+func (x *VPNConfig_Split) Reset() {
+	*x = VPNConfig_Split{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VPNConfig_Split) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VPNConfig_Split) ProtoMessage() {}
+
+func (x *VPNConfig_Split) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VPNConfig_Split.ProtoReflect.Descriptor instead.
+func (*VPNConfig_Split) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{6, 3}
+}
+
+func (x *VPNConfig_Split) GetIncludeIpv4() []string {
+	if x != nil {
+		return x.IncludeIpv4
+	}
+	return nil
+}
+
+func (x *VPNConfig_Split) GetIncludeIpv6() []string {
+	if x != nil {
+		return x.IncludeIpv6
+	}
+	return nil
+}
+
+func (x *VPNConfig_Split) GetExcludeIpv4() []string {
+	if x != nil {
+		return x.ExcludeIpv4
+	}
+	return nil
+}
+
+func (x *VPNConfig_Split) GetExcludeIpv6() []string {
+	if x != nil {
+		return x.ExcludeIpv6
+	}
+	return nil
+}
+
+func (x *VPNConfig_Split) GetExcludeDns() []string {
+	if x != nil {
+		return x.ExcludeDns
+	}
+	return nil
+}
+
+func (x *VPNConfig_Split) GetExcludeVirtualSubnetsOnlyIpv4() bool {
+	if x != nil {
+		return x.ExcludeVirtualSubnetsOnlyIpv4
+	}
+	return false
+}
+
+// Flags are the flags of a VPN configuration
+type VPNConfig_Flags struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DisableAlwaysOnVpn bool `protobuf:"varint,1,opt,name=disable_always_on_vpn,json=disableAlwaysOnVpn,proto3" json:"disable_always_on_vpn,omitempty"`
+}
+
+func (x *VPNConfig_Flags) Reset() {
+	*x = VPNConfig_Flags{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VPNConfig_Flags) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VPNConfig_Flags) ProtoMessage() {}
+
+func (x *VPNConfig_Flags) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VPNConfig_Flags.ProtoReflect.Descriptor instead.
+func (*VPNConfig_Flags) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{6, 4}
+}
+
+func (x *VPNConfig_Flags) GetDisableAlwaysOnVpn() bool {
+	if x != nil {
+		return x.DisableAlwaysOnVpn
+	}
+	return false
+}
+
+// Banner is the banner of the VPN gateway
+type VPNConfig_Banner struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Text     string `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Language string `protobuf:"bytes,2,opt,name=language,proto3" json:"language,omitempty"`
+}
+
+func (x *VPNConfig_Banner) Reset() {
+	*x = VPNConfig_Banner{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VPNConfig_Banner) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VPNConfig_Banner) ProtoMessage() {}
+
+func (x *VPNConfig_Banner) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VPNConfig_Banner.ProtoReflect.Descriptor instead.
+func (*VPNConfig_Banner) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{6, 5}
+}
+
+func (x *VPNConfig_Banner) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *VPNConfig_Banner) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+var File_daemon_proto protoreflect.FileDescriptor
+
+var file_daemon_proto_rawDesc = []byte{
+	0x0a, 0x0c, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x19,
+	0x63, 0x6f, 0x6d, 0x2e, 0x74, 0x65, 0x6c, 0x65, 0x6b, 0x6f, 0x6d, 0x5f, 0x6d, 0x6d, 0x73, 0x2e,
+	0x6f, 0x63, 0x5f, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x22, 0x12, 0x0a, 0x10, 0x47, 0x65, 0x74,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x12, 0x0a,
+	0x10, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x22, 0x15, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x43, 0x68, 0x61, 0x6c, 0x6c, 0x65, 0x6e, 0x67,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x19, 0x0a, 0x17, 0x55, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x56, 0x50, 0x4e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x43, 0x0a, 0x09, 0x43, 0x68, 0x61, 0x6c, 0x6c, 0x65, 0x6e, 0x67, 0x65,
+	0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x61, 0x6c, 0x6c, 0x65, 0x6e, 0x67, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6c, 0x6c, 0x65, 0x6e, 0x67, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x07, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x22, 0xa1, 0x01, 0x0a, 0x0f, 0x56, 0x50, 0x4e,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x16, 0x0a, 0x06,
+	0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65,
+	0x61, 0x73, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x61, 0x6c, 0x6c, 0x65, 0x6e, 0x67,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6c, 0x6c, 0x65, 0x6e,
+	0x67, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3c,
+	0x0a, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x24,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x74, 0x65, 0x6c, 0x65, 0x6b, 0x6f, 0x6d, 0x5f, 0x6d, 0x6d, 0x73,
+	0x2e, 0x6f, 0x63, 0x5f, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x2e, 0x56, 0x50, 0x4e, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x52, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x22, 0xd7, 0x0a, 0x0a,
+	0x09, 0x56, 0x50, 0x4e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x67, 0x61,
+	0x74, 0x65, 0x77, 0x61, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x67, 0x61, 0x74,
+	0x65, 0x77, 0x61, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x70, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x03, 0x70, 0x69, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75,
+	0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74,
+	0x12, 0x43, 0x0a, 0x06, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x2b, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x74, 0x65, 0x6c, 0x65, 0x6b, 0x6f, 0x6d, 0x5f, 0x6d,
+	0x6d, 0x73, 0x2e, 0x6f, 0x63, 0x5f, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x2e, 0x56, 0x50, 0x4e,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x52, 0x06, 0x64,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x12, 0x40, 0x0a, 0x04, 0x69, 0x70, 0x76, 0x34, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x74, 0x65, 0x6c, 0x65, 0x6b, 0x6f,
+	0x6d, 0x5f, 0x6d, 0x6d, 0x73, 0x2e, 0x6f, 0x63, 0x5f, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x2e,
+	0x56, 0x50, 0x4e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73,
+	0x73, 0x52, 0x04, 0x69, 0x70, 0x76, 0x34, 0x12, 0x40, 0x0a, 0x04, 0x69, 0x70, 0x76, 0x36, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x74, 0x65, 0x6c, 0x65,
+	0x6b, 0x6f, 0x6d, 0x5f, 0x6d, 0x6d, 0x73, 0x2e, 0x6f, 0x63, 0x5f, 0x64, 0x61, 0x65, 0x6d, 0x6f,
+	0x6e, 0x2e, 0x56, 0x50, 0x4e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x41, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x52, 0x04, 0x69, 0x70, 0x76, 0x36, 0x12, 0x3a, 0x0a, 0x03, 0x64, 0x6e, 0x73,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x74, 0x65, 0x6c,
+	0x65, 0x6b, 0x6f, 0x6d, 0x5f, 0x6d, 0x6d, 0x73, 0x2e, 0x6f, 0x63, 0x5f, 0x64, 0x61, 0x65, 0x6d,
+	0x6f, 0x6e, 0x2e, 0x56, 0x50, 0x4e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x44, 0x4e, 0x53,
+	0x52, 0x03, 0x64, 0x6e, 0x73, 0x12, 0x40, 0x0a, 0x05, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x74, 0x65, 0x6c, 0x65, 0x6b,
+	0x6f, 0x6d, 0x5f, 0x6d, 0x6d, 0x73, 0x2e, 0x6f, 0x63, 0x5f, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e,
+	0x2e, 0x56, 0x50, 0x4e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x53, 0x70, 0x6c, 0x69, 0x74,
+	0x52, 0x05, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x12, 0x40, 0x0a, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x74, 0x65, 0x6c,
+	0x65, 0x6b, 0x6f, 0x6d, 0x5f, 0x6d, 0x6d, 0x73, 0x2e, 0x6f, 0x63, 0x5f, 0x64, 0x61, 0x65, 0x6d,
+	0x6f, 0x6e, 0x2e, 0x56, 0x50, 0x4e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x46, 0x6c, 0x61,
+	0x67, 0x73, 0x52, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x12, 0x43, 0x0a, 0x06, 0x62, 0x61, 0x6e,
+	0x6e, 0x65, 0x72, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2b, 0x2e, 0x63, 0x6f, 0x6d, 0x2e,
+	0x74, 0x65, 0x6c, 0x65, 0x6b, 0x6f, 0x6d, 0x5f, 0x6d, 0x6d, 0x73, 0x2e, 0x6f, 0x63, 0x5f, 0x64,
+	0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x2e, 0x56, 0x50, 0x4e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e,
+	0x42, 0x61, 0x6e, 0x6e, 0x65, 0x72, 0x52, 0x06, 0x62, 0x61, 0x6e, 0x6e, 0x65, 0x72, 0x1a, 0x2e,
+	0x0a, 0x06, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x10, 0x0a, 0x03,
+	0x6d, 0x74, 0x75, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x6d, 0x74, 0x75, 0x1a, 0x3d,
+	0x0a, 0x07, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64,
+	0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6e, 0x65, 0x74, 0x6d, 0x61, 0x73, 0x6b, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6e, 0x65, 0x74, 0x6d, 0x61, 0x73, 0x6b, 0x1a, 0xcf, 0x02,
+	0x0a, 0x03, 0x44, 0x4e, 0x53, 0x12, 0x25, 0x0a, 0x0e, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74,
+	0x5f, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x64,
+	0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x44, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x12, 0x21, 0x0a, 0x0c,
+	0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x5f, 0x69, 0x70, 0x76, 0x34, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x0b, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x49, 0x70, 0x76, 0x34, 0x12,
+	0x21, 0x0a, 0x0c, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x5f, 0x69, 0x70, 0x76, 0x36, 0x18,
+	0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x49, 0x70,
+	0x76, 0x36, 0x12, 0x23, 0x0a, 0x0d, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x5f, 0x64, 0x6f, 0x6d, 0x61,
+	0x69, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x73, 0x70, 0x6c, 0x69, 0x74,
+	0x44, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x75, 0x6e, 0x6e, 0x65,
+	0x6c, 0x5f, 0x61, 0x6c, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x74, 0x75, 0x6e,
+	0x6e, 0x65, 0x6c, 0x41, 0x6c, 0x6c, 0x12, 0x58, 0x0a, 0x0a, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x70,
+	0x6f, 0x72, 0x74, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x38, 0x2e, 0x63, 0x6f, 0x6d,
+	0x2e, 0x74, 0x65, 0x6c, 0x65, 0x6b, 0x6f, 0x6d, 0x5f, 0x6d, 0x6d, 0x73, 0x2e, 0x6f, 0x63, 0x5f,
+	0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x2e, 0x56, 0x50, 0x4e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x2e, 0x44, 0x4e, 0x53, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x0a, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x73,
+	0x1a, 0x3d, 0x0a, 0x0f, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a,
+	0xfe, 0x01, 0x0a, 0x05, 0x53, 0x70, 0x6c, 0x69, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x69, 0x6e, 0x63,
+	0x6c, 0x75, 0x64, 0x65, 0x5f, 0x69, 0x70, 0x76, 0x34, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x0b, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x49, 0x70, 0x76, 0x34, 0x12, 0x21, 0x0a, 0x0c,
+	0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x5f, 0x69, 0x70, 0x76, 0x36, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x0b, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x49, 0x70, 0x76, 0x36, 0x12,
+	0x21, 0x0a, 0x0c, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x5f, 0x69, 0x70, 0x76, 0x34, 0x18,
+	0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x49, 0x70,
+	0x76, 0x34, 0x12, 0x21, 0x0a, 0x0c, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x5f, 0x69, 0x70,
+	0x76, 0x36, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64,
+	0x65, 0x49, 0x70, 0x76, 0x36, 0x12, 0x1f, 0x0a, 0x0b, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65,
+	0x5f, 0x64, 0x6e, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x65, 0x78, 0x63, 0x6c,
+	0x75, 0x64, 0x65, 0x44, 0x6e, 0x73, 0x12, 0x48, 0x0a, 0x21, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64,
+	0x65, 0x5f, 0x76, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x5f, 0x73, 0x75, 0x62, 0x6e, 0x65, 0x74,
+	0x73, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x5f, 0x69, 0x70, 0x76, 0x34, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x1d, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x56, 0x69, 0x72, 0x74, 0x75, 0x61,
+	0x6c, 0x53, 0x75, 0x62, 0x6e, 0x65, 0x74, 0x73, 0x4f, 0x6e, 0x6c, 0x79, 0x49, 0x70, 0x76, 0x34,
+	0x1a, 0x3a, 0x0a, 0x05, 0x46, 0x6c, 0x61, 0x67, 0x73, 0x12, 0x31, 0x0a, 0x15, 0x64, 0x69, 0x73,
+	0x61, 0x62, 0x6c, 0x65, 0x5f, 0x61, 0x6c, 0x77, 0x61, 0x79, 0x73, 0x5f, 0x6f, 0x6e, 0x5f, 0x76,
+	0x70, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x12, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c,
+	0x65, 0x41, 0x6c, 0x77, 0x61, 0x79, 0x73, 0x4f, 0x6e, 0x56, 0x70, 0x6e, 0x1a, 0x38, 0x0a, 0x06,
+	0x42, 0x61, 0x6e, 0x6e, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x61,
+	0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6c, 0x61,
+	0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x22, 0x99, 0x09, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x27, 0x0a, 0x0f, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x5f, 0x6e, 0x65, 0x74,
+	0x77, 0x6f, 0x72, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0e, 0x74, 0x72, 0x75, 0x73,
+	0x74, 0x65, 0x64, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x12, 0x29, 0x0a, 0x10, 0x63, 0x6f,
+	0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0f, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x02, 0x69, 0x70, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x12, 0x21, 0x0a,
+	0x0c, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x41, 0x74,
+	0x12, 0x18, 0x0a, 0x07, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x07, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x6f, 0x63,
+	0x5f, 0x72, 0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09,
+	0x6f, 0x63, 0x52, 0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x43, 0x0a, 0x0a, 0x76, 0x70, 0x6e,
+	0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x24, 0x2e,
+	0x63, 0x6f, 0x6d, 0x2e, 0x74, 0x65, 0x6c, 0x65, 0x6b, 0x6f, 0x6d, 0x5f, 0x6d, 0x6d, 0x73, 0x2e,
+	0x6f, 0x63, 0x5f, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x2e, 0x56, 0x50, 0x4e, 0x43, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x52, 0x09, 0x76, 0x70, 0x6e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x14,
+	0x0a, 0x05, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70,
+	0x72, 0x6f, 0x78, 0x79, 0x12, 0x19, 0x0a, 0x08, 0x72, 0x65, 0x74, 0x72, 0x79, 0x5f, 0x61, 0x74,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x72, 0x65, 0x74, 0x72, 0x79, 0x41, 0x74, 0x12,
+	0x23, 0x0a, 0x0d, 0x72, 0x65, 0x74, 0x72, 0x79, 0x5f, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x72, 0x65, 0x74, 0x72, 0x79, 0x41, 0x74, 0x74,
+	0x65, 0x6d, 0x70, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x72, 0x78, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73,
+	0x18, 0x0c, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x72, 0x78, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12,
+	0x19, 0x0a, 0x08, 0x74, 0x78, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x0d, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x07, 0x74, 0x78, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x78,
+	0x5f, 0x70, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09,
+	0x72, 0x78, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x78, 0x5f,
+	0x70, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x74,
+	0x78, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x12, 0x2a, 0x0a, 0x11, 0x64, 0x6e, 0x73, 0x5f,
+	0x6c, 0x65, 0x61, 0x6b, 0x73, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x18, 0x10, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0f, 0x64, 0x6e, 0x73, 0x4c, 0x65, 0x61, 0x6b, 0x73, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x65, 0x64, 0x12, 0x2d, 0x0a, 0x12, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x61, 0x67,
+	0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x11, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x11, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x41, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x12, 0x29, 0x0a, 0x10, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x5f, 0x65,
+	0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x73, 0x18, 0x12, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0f, 0x67,
+	0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x45, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x73, 0x12, 0x1f,
+	0x0a, 0x0b, 0x64, 0x6e, 0x73, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x18, 0x13, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x0a, 0x64, 0x6e, 0x73, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x12,
+	0x2c, 0x0a, 0x12, 0x64, 0x6e, 0x73, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x5f, 0x64, 0x6f,
+	0x6d, 0x61, 0x69, 0x6e, 0x73, 0x18, 0x14, 0x20, 0x03, 0x28, 0x09, 0x52, 0x10, 0x64, 0x6e, 0x73,
+	0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x44, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x73, 0x12, 0x2a, 0x0a,
+	0x11, 0x64, 0x6e, 0x73, 0x5f, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x5f, 0x64, 0x6f, 0x6d, 0x61, 0x69,
+	0x6e, 0x73, 0x18, 0x15, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0f, 0x64, 0x6e, 0x73, 0x53, 0x70, 0x6c,
+	0x69, 0x74, 0x44, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x73, 0x63, 0x68,
+	0x65, 0x64, 0x75, 0x6c, 0x65, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x16, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x0d, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x12, 0x22, 0x0a, 0x0c, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74, 0x79,
+	0x18, 0x17, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69,
+	0x76, 0x69, 0x74, 0x79, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x18, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6d, 0x70, 0x72,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x6e, 0x64, 0x5f, 0x73, 0x74,
+	0x61, 0x74, 0x65, 0x18, 0x19, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x74, 0x6e, 0x64, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x73, 0x64, 0x5f, 0x77, 0x72, 0x61, 0x70, 0x70,
+	0x65, 0x72, 0x18, 0x1a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x73, 0x64, 0x57, 0x72, 0x61,
+	0x70, 0x70, 0x65, 0x72, 0x12, 0x2b, 0x0a, 0x11, 0x64, 0x69, 0x73, 0x63, 0x6f, 0x6e, 0x6e, 0x65,
+	0x63, 0x74, 0x5f, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x1b, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x10, 0x64, 0x69, 0x73, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x52, 0x65, 0x61, 0x73, 0x6f,
+	0x6e, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x70, 0x69, 0x73, 0x18, 0x1c, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x04, 0x61, 0x70, 0x69, 0x73, 0x12, 0x24, 0x0a, 0x0e, 0x74, 0x72, 0x61, 0x66, 0x5f, 0x70, 0x6f,
+	0x6c, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x1d, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x74,
+	0x72, 0x61, 0x66, 0x50, 0x6f, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x29, 0x0a, 0x10, 0x70,
+	0x72, 0x65, 0x66, 0x65, 0x72, 0x72, 0x65, 0x64, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x18,
+	0x1e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x70, 0x72, 0x65, 0x66, 0x65, 0x72, 0x72, 0x65, 0x64,
+	0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x75, 0x70, 0x74, 0x69, 0x6d, 0x65,
+	0x18, 0x1f, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x75, 0x70, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x1b,
+	0x0a, 0x09, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x5f, 0x75, 0x69, 0x64, 0x18, 0x20, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x08, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x55, 0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x6f,
+	0x77, 0x6e, 0x65, 0x72, 0x18, 0x21, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6f, 0x77, 0x6e, 0x65,
+	0x72, 0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x61, 0x75, 0x73, 0x65, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65,
+	0x18, 0x22, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x70, 0x61, 0x75, 0x73, 0x65, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x22, 0x69, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x70,
+	0x72, 0x6f, 0x70, 0x65, 0x72, 0x74, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70,
+	0x72, 0x6f, 0x70, 0x65, 0x72, 0x74, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x16, 0x0a,
+	0x06, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73,
+	0x69, 0x67, 0x6e, 0x61, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x32, 0x9c, 0x03,
+	0x0a, 0x06, 0x44, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x12, 0x5b, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x2b, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x74, 0x65, 0x6c, 0x65,
+	0x6b, 0x6f, 0x6d, 0x5f, 0x6d, 0x6d, 0x73, 0x2e, 0x6f, 0x63, 0x5f, 0x64, 0x61, 0x65, 0x6d, 0x6f,
+	0x6e, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x21, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x74, 0x65, 0x6c, 0x65, 0x6b, 0x6f, 0x6d,
+	0x5f, 0x6d, 0x6d, 0x73, 0x2e, 0x6f, 0x63, 0x5f, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x2e, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x5c, 0x0a, 0x09, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69,
+	0x62, 0x65, 0x12, 0x2b, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x74, 0x65, 0x6c, 0x65, 0x6b, 0x6f, 0x6d,
+	0x5f, 0x6d, 0x6d, 0x73, 0x2e, 0x6f, 0x63, 0x5f, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x2e, 0x53,
+	0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x20, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x74, 0x65, 0x6c, 0x65, 0x6b, 0x6f, 0x6d, 0x5f, 0x6d, 0x6d,
+	0x73, 0x2e, 0x6f, 0x63, 0x5f, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x2e, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x30, 0x01, 0x12, 0x64, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x43, 0x68, 0x61, 0x6c, 0x6c, 0x65,
+	0x6e, 0x67, 0x65, 0x12, 0x2e, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x74, 0x65, 0x6c, 0x65, 0x6b, 0x6f,
+	0x6d, 0x5f, 0x6d, 0x6d, 0x73, 0x2e, 0x6f, 0x63, 0x5f, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x2e,
+	0x47, 0x65, 0x74, 0x43, 0x68, 0x61, 0x6c, 0x6c, 0x65, 0x6e, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x74, 0x65, 0x6c, 0x65, 0x6b, 0x6f,
+	0x6d, 0x5f, 0x6d, 0x6d, 0x73, 0x2e, 0x6f, 0x63, 0x5f, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x2e,
+	0x43, 0x68, 0x61, 0x6c, 0x6c, 0x65, 0x6e, 0x67, 0x65, 0x12, 0x71, 0x0a, 0x0f, 0x55, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x56, 0x50, 0x4e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x2a, 0x2e, 0x63,
+	0x6f, 0x6d, 0x2e, 0x74, 0x65, 0x6c, 0x65, 0x6b, 0x6f, 0x6d, 0x5f, 0x6d, 0x6d, 0x73, 0x2e, 0x6f,
+	0x63, 0x5f, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x2e, 0x56, 0x50, 0x4e, 0x43, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x1a, 0x32, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x74,
+	0x65, 0x6c, 0x65, 0x6b, 0x6f, 0x6d, 0x5f, 0x6d, 0x6d, 0x73, 0x2e, 0x6f, 0x63, 0x5f, 0x64, 0x61,
+	0x65, 0x6d, 0x6f, 0x6e, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x56, 0x50, 0x4e, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x2e, 0x5a, 0x2c,
+	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x74, 0x65, 0x6c, 0x65, 0x6b,
+	0x6f, 0x6d, 0x2d, 0x6d, 0x6d, 0x73, 0x2f, 0x6f, 0x63, 0x2d, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e,
+	0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_daemon_proto_rawDescOnce sync.Once
+	file_daemon_proto_rawDescData = file_daemon_proto_rawDesc
+)
+
+func file_daemon_proto_rawDescGZIP() []byte {
+	file_daemon_proto_rawDescOnce.Do(func() {
+		file_daemon_proto_rawDescData = protoimpl.X.CompressGZIP(file_daemon_proto_rawDescData)
+	})
+	return file_daemon_proto_rawDescData
+}
+
+var file_daemon_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_daemon_proto_goTypes = []interface{}{
+	(*GetStatusRequest)(nil),        // 0: com.telekom_mms.oc_daemon.GetStatusRequest
+	(*SubscribeRequest)(nil),        // 1: com.telekom_mms.oc_daemon.SubscribeRequest
+	(*GetChallengeRequest)(nil),     // 2: com.telekom_mms.oc_daemon.GetChallengeRequest
+	(*UpdateVPNConfigResponse)(nil), // 3: com.telekom_mms.oc_daemon.UpdateVPNConfigResponse
+	(*Challenge)(nil),               // 4: com.telekom_mms.oc_daemon.Challenge
+	(*VPNConfigUpdate)(nil),         // 5: com.telekom_mms.oc_daemon.VPNConfigUpdate
+	(*VPNConfig)(nil),               // 6: com.telekom_mms.oc_daemon.VPNConfig
+	(*Status)(nil),                  // 7: com.telekom_mms.oc_daemon.Status
+	(*Event)(nil),                   // 8: com.telekom_mms.oc_daemon.Event
+	(*VPNConfig_Device)(nil),        // 9: com.telekom_mms.oc_daemon.VPNConfig.Device
+	(*VPNConfig_Address)(nil),       // 10: com.telekom_mms.oc_daemon.VPNConfig.Address
+	(*VPNConfig_DNS)(nil),           // 11: com.telekom_mms.oc_daemon.VPNConfig.DNS
+	(*VPNConfig_Split)(nil),         // 12: com.telekom_mms.oc_daemon.VPNConfig.Split
+	(*VPNConfig_Flags)(nil),         // 13: com.telekom_mms.oc_daemon.VPNConfig.Flags
+	(*VPNConfig_Banner)(nil),        // 14: com.telekom_mms.oc_daemon.VPNConfig.Banner
+	nil,                             // 15: com.telekom_mms.oc_daemon.VPNConfig.DNS.TransportsEntry
+}
+var file_daemon_proto_depIdxs = []int32{
+	6,  // 0: com.telekom_mms.oc_daemon.VPNConfigUpdate.config:type_name -> com.telekom_mms.oc_daemon.VPNConfig
+	9,  // 1: com.telekom_mms.oc_daemon.VPNConfig.device:type_name -> com.telekom_mms.oc_daemon.VPNConfig.Device
+	10, // 2: com.telekom_mms.oc_daemon.VPNConfig.ipv4:type_name -> com.telekom_mms.oc_daemon.VPNConfig.Address
+	10, // 3: com.telekom_mms.oc_daemon.VPNConfig.ipv6:type_name -> com.telekom_mms.oc_daemon.VPNConfig.Address
+	11, // 4: com.telekom_mms.oc_daemon.VPNConfig.dns:type_name -> com.telekom_mms.oc_daemon.VPNConfig.DNS
+	12, // 5: com.telekom_mms.oc_daemon.VPNConfig.split:type_name -> com.telekom_mms.oc_daemon.VPNConfig.Split
+	13, // 6: com.telekom_mms.oc_daemon.VPNConfig.flags:type_name -> com.telekom_mms.oc_daemon.VPNConfig.Flags
+	14, // 7: com.telekom_mms.oc_daemon.VPNConfig.banner:type_name -> com.telekom_mms.oc_daemon.VPNConfig.Banner
+	6,  // 8: com.telekom_mms.oc_daemon.Status.vpn_config:type_name -> com.telekom_mms.oc_daemon.VPNConfig
+	15, // 9: com.telekom_mms.oc_daemon.VPNConfig.DNS.transports:type_name -> com.telekom_mms.oc_daemon.VPNConfig.DNS.TransportsEntry
+	0,  // 10: com.telekom_mms.oc_daemon.Daemon.GetStatus:input_type -> com.telekom_mms.oc_daemon.GetStatusRequest
+	1,  // 11: com.telekom_mms.oc_daemon.Daemon.Subscribe:input_type -> com.telekom_mms.oc_daemon.SubscribeRequest
+	2,  // 12: com.telekom_mms.oc_daemon.Daemon.GetChallenge:input_type -> com.telekom_mms.oc_daemon.GetChallengeRequest
+	5,  // 13: com.telekom_mms.oc_daemon.Daemon.UpdateVPNConfig:input_type -> com.telekom_mms.oc_daemon.VPNConfigUpdate
+	7,  // 14: com.telekom_mms.oc_daemon.Daemon.GetStatus:output_type -> com.telekom_mms.oc_daemon.Status
+	8,  // 15: com.telekom_mms.oc_daemon.Daemon.Subscribe:output_type -> com.telekom_mms.oc_daemon.Event
+	4,  // 16: com.telekom_mms.oc_daemon.Daemon.GetChallenge:output_type -> com.telekom_mms.oc_daemon.Challenge
+	3,  // 17: com.telekom_mms.oc_daemon.Daemon.UpdateVPNConfig:output_type -> com.telekom_mms.oc_daemon.UpdateVPNConfigResponse
+	14, // [14:18] is the sub-list for method output_type
+	10, // [10:14] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_daemon_proto_init() }
+func file_daemon_proto_init() {
+	if File_daemon_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_daemon_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetStatusRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubscribeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetChallengeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdateVPNConfigResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Challenge); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VPNConfigUpdate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VPNConfig); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Status); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Event); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VPNConfig_Device); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VPNConfig_Address); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VPNConfig_DNS); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VPNConfig_Split); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VPNConfig_Flags); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VPNConfig_Banner); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_daemon_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_daemon_proto_goTypes,
+		DependencyIndexes: file_daemon_proto_depIdxs,
+		MessageInfos:      file_daemon_proto_msgTypes,
+	}.Build()
+	File_daemon_proto = out.File
+	file_daemon_proto_rawDesc = nil
+	file_daemon_proto_goTypes = nil
+	file_daemon_proto_depIdxs = nil
+}
@@ -0,0 +1,270 @@
+// Protocol buffer definitions of the gRPC API of the oc-daemon.
+//
+// The gRPC API is an optional alternative to the framed-message protocol on
+// the unix socket of the daemon for non-Go integrations. Property and signal
+// names in events are the names in the D-Bus API.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v3.21.12
+// source: daemon.proto
+
+package grpcapi
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Daemon_GetStatus_FullMethodName       = "/com.telekom_mms.oc_daemon.Daemon/GetStatus"
+	Daemon_Subscribe_FullMethodName       = "/com.telekom_mms.oc_daemon.Daemon/Subscribe"
+	Daemon_GetChallenge_FullMethodName    = "/com.telekom_mms.oc_daemon.Daemon/GetChallenge"
+	Daemon_UpdateVPNConfig_FullMethodName = "/com.telekom_mms.oc_daemon.Daemon/UpdateVPNConfig"
+)
+
+// DaemonClient is the client API for Daemon service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DaemonClient interface {
+	// GetStatus returns the current status of the daemon
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Status, error)
+	// Subscribe returns the status changes and signals of the daemon until
+	// the client cancels the call
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (Daemon_SubscribeClient, error)
+	// GetChallenge returns a new challenge for authenticating a VPN config
+	// update, it can only be used once and expires after a short time
+	GetChallenge(ctx context.Context, in *GetChallengeRequest, opts ...grpc.CallOption) (*Challenge, error)
+	// UpdateVPNConfig updates the VPN configuration of the daemon, e.g.,
+	// from a vpnc-script; only root, the owner and the group of the socket
+	// file may send config updates
+	UpdateVPNConfig(ctx context.Context, in *VPNConfigUpdate, opts ...grpc.CallOption) (*UpdateVPNConfigResponse, error)
+}
+
+type daemonClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDaemonClient(cc grpc.ClientConnInterface) DaemonClient {
+	return &daemonClient{cc}
+}
+
+func (c *daemonClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Status, error) {
+	out := new(Status)
+	err := c.cc.Invoke(ctx, Daemon_GetStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (Daemon_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Daemon_ServiceDesc.Streams[0], Daemon_Subscribe_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &daemonSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Daemon_SubscribeClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type daemonSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *daemonSubscribeClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *daemonClient) GetChallenge(ctx context.Context, in *GetChallengeRequest, opts ...grpc.CallOption) (*Challenge, error) {
+	out := new(Challenge)
+	err := c.cc.Invoke(ctx, Daemon_GetChallenge_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonClient) UpdateVPNConfig(ctx context.Context, in *VPNConfigUpdate, opts ...grpc.CallOption) (*UpdateVPNConfigResponse, error) {
+	out := new(UpdateVPNConfigResponse)
+	err := c.cc.Invoke(ctx, Daemon_UpdateVPNConfig_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DaemonServer is the server API for Daemon service.
+// All implementations must embed UnimplementedDaemonServer
+// for forward compatibility
+type DaemonServer interface {
+	// GetStatus returns the current status of the daemon
+	GetStatus(context.Context, *GetStatusRequest) (*Status, error)
+	// Subscribe returns the status changes and signals of the daemon until
+	// the client cancels the call
+	Subscribe(*SubscribeRequest, Daemon_SubscribeServer) error
+	// GetChallenge returns a new challenge for authenticating a VPN config
+	// update, it can only be used once and expires after a short time
+	GetChallenge(context.Context, *GetChallengeRequest) (*Challenge, error)
+	// UpdateVPNConfig updates the VPN configuration of the daemon, e.g.,
+	// from a vpnc-script; only root, the owner and the group of the socket
+	// file may send config updates
+	UpdateVPNConfig(context.Context, *VPNConfigUpdate) (*UpdateVPNConfigResponse, error)
+	mustEmbedUnimplementedDaemonServer()
+}
+
+// UnimplementedDaemonServer must be embedded to have forward compatible implementations.
+type UnimplementedDaemonServer struct {
+}
+
+func (UnimplementedDaemonServer) GetStatus(context.Context, *GetStatusRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedDaemonServer) Subscribe(*SubscribeRequest, Daemon_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedDaemonServer) GetChallenge(context.Context, *GetChallengeRequest) (*Challenge, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChallenge not implemented")
+}
+func (UnimplementedDaemonServer) UpdateVPNConfig(context.Context, *VPNConfigUpdate) (*UpdateVPNConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateVPNConfig not implemented")
+}
+func (UnimplementedDaemonServer) mustEmbedUnimplementedDaemonServer() {}
+
+// UnsafeDaemonServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DaemonServer will
+// result in compilation errors.
+type UnsafeDaemonServer interface {
+	mustEmbedUnimplementedDaemonServer()
+}
+
+func RegisterDaemonServer(s grpc.ServiceRegistrar, srv DaemonServer) {
+	s.RegisterService(&Daemon_ServiceDesc, srv)
+}
+
+func _Daemon_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Daemon_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Daemon_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DaemonServer).Subscribe(m, &daemonSubscribeServer{stream})
+}
+
+type Daemon_SubscribeServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type daemonSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *daemonSubscribeServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Daemon_GetChallenge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetChallengeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServer).GetChallenge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Daemon_GetChallenge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServer).GetChallenge(ctx, req.(*GetChallengeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Daemon_UpdateVPNConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VPNConfigUpdate)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServer).UpdateVPNConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Daemon_UpdateVPNConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServer).UpdateVPNConfig(ctx, req.(*VPNConfigUpdate))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Daemon_ServiceDesc is the grpc.ServiceDesc for Daemon service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Daemon_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "com.telekom_mms.oc_daemon.Daemon",
+	HandlerType: (*DaemonServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetStatus",
+			Handler:    _Daemon_GetStatus_Handler,
+		},
+		{
+			MethodName: "GetChallenge",
+			Handler:    _Daemon_GetChallenge_Handler,
+		},
+		{
+			MethodName: "UpdateVPNConfig",
+			Handler:    _Daemon_UpdateVPNConfig_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _Daemon_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "daemon.proto",
+}
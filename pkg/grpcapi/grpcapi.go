@@ -0,0 +1,6 @@
+// Package grpcapi contains the protocol buffer definitions of the optional
+// gRPC API of the oc-daemon, the generated code and conversions from and to
+// the status and VPN configuration types of the daemon.
+package grpcapi
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative daemon.proto
@@ -2,8 +2,10 @@ package vpnconfig
 
 import (
 	"encoding/json"
+	"fmt"
 	"net"
 	"reflect"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -36,11 +38,39 @@ func (a *Address) Copy() Address {
 	}
 }
 
+// DNS server transports
+const (
+	DNSTransportUDP = "udp"
+	DNSTransportTCP = "tcp"
+	DNSTransportTLS = "tls"
+)
+
+// ValidDNSTransport returns whether transport is a valid DNS server transport
+func ValidDNSTransport(transport string) bool {
+	switch transport {
+	case DNSTransportUDP, DNSTransportTCP, DNSTransportTLS:
+		return true
+	}
+	return false
+}
+
 // DNS is a DNS configuration in Config
 type DNS struct {
 	DefaultDomain string
 	ServersIPv4   []net.IP
 	ServersIPv6   []net.IP
+
+	// SplitDomains are the domains resolved with the VPN DNS servers in
+	// split-DNS mode
+	SplitDomains []string
+
+	// TunnelAll specifies that all DNS queries must be resolved with the
+	// VPN DNS servers, it overrides SplitDomains
+	TunnelAll bool
+
+	// Transports maps DNS server IP addresses to the transport used for
+	// queries, servers not in the map use UDP
+	Transports map[string]string
 }
 
 // Copy returns a copy of DNS
@@ -63,25 +93,81 @@ func (d *DNS) Copy() DNS {
 		serversIPv6 = append(serversIPv6, ip)
 	}
 
+	var transports map[string]string
+	if d.Transports != nil {
+		transports = make(map[string]string)
+		for k, v := range d.Transports {
+			transports[k] = v
+		}
+	}
+
 	return DNS{
 		DefaultDomain: d.DefaultDomain,
 		ServersIPv4:   serversIPv4,
 		ServersIPv6:   serversIPv6,
+		SplitDomains:  append(d.SplitDomains[:0:0], d.SplitDomains...),
+		TunnelAll:     d.TunnelAll,
+		Transports:    transports,
+	}
+}
+
+// SplitDNS returns whether split-DNS mode is used, i.e., only the default
+// domain and split domains are resolved with the VPN DNS servers. Otherwise,
+// all domains are resolved with the VPN DNS servers
+func (d *DNS) SplitDNS() bool {
+	return !d.TunnelAll && len(d.SplitDomains) > 0
+}
+
+// remote returns the remote address of DNS server ip including port number
+// 53, or 853 and prefix "tls://" for TLS, or prefix "tcp://" for TCP
+func (d *DNS) remote(ip net.IP) string {
+	transport := d.Transports[ip.String()]
+	port := "53"
+	if transport == DNSTransportTLS {
+		port = "853"
+	}
+	address := net.JoinHostPort(ip.String(), port)
+	switch transport {
+	case DNSTransportTCP, DNSTransportTLS:
+		return transport + "://" + address
 	}
+	return address
+}
+
+// Servers returns the remote addresses of the IPv4 and IPv6 DNS servers,
+// see remote
+func (d *DNS) Servers() []string {
+	servers := []string{}
+	for _, s := range d.ServersIPv4 {
+		servers = append(servers, d.remote(s))
+	}
+	for _, s := range d.ServersIPv6 {
+		servers = append(servers, d.remote(s))
+	}
+	return servers
 }
 
 // Remotes returns a map of DNS remotes from the DNS configuration that maps
 // domain "." to the IPv4 and IPv6 DNS servers in the configuration including
-// port number 53
+// port number 53 and transport, see remote. In split-DNS mode, it maps the
+// default domain and the split domains instead of domain "."
 func (d *DNS) Remotes() map[string][]string {
+	servers := d.Servers()
 	remotes := map[string][]string{}
-	for _, s := range d.ServersIPv4 {
-		server := s.String() + ":53"
-		remotes["."] = append(remotes["."], server)
+	if len(servers) == 0 {
+		return remotes
 	}
-	for _, s := range d.ServersIPv6 {
-		server := "[" + s.String() + "]:53"
-		remotes["."] = append(remotes["."], server)
+	if !d.SplitDNS() {
+		remotes["."] = servers
+		return remotes
+	}
+	domains := append([]string{d.DefaultDomain}, d.SplitDomains...)
+	for _, domain := range domains {
+		if domain == "" {
+			continue
+		}
+		domain = strings.TrimSuffix(domain, ".") + "."
+		remotes[domain] = append([]string{}, servers...)
 	}
 
 	return remotes
@@ -89,6 +175,11 @@ func (d *DNS) Remotes() map[string][]string {
 
 // Split is a split routing configuration in Config
 type Split struct {
+	// IncludeIPv4 and IncludeIPv6 are the networks tunneled through
+	// the VPN
+	IncludeIPv4 []*net.IPNet
+	IncludeIPv6 []*net.IPNet
+
 	ExcludeIPv4 []*net.IPNet
 	ExcludeIPv6 []*net.IPNet
 	ExcludeDNS  []string
@@ -96,35 +187,30 @@ type Split struct {
 	ExcludeVirtualSubnetsOnlyIPv4 bool
 }
 
-// Copy returns a copy of split
-func (s *Split) Copy() Split {
-	excludeIPv4 := []*net.IPNet{}
-	if s.ExcludeIPv4 == nil {
-		excludeIPv4 = nil
-	}
-	for _, e := range s.ExcludeIPv4 {
-		ipnet := &net.IPNet{
-			IP:   append(e.IP[:0:0], e.IP...),
-			Mask: append(e.Mask[:0:0], e.Mask...),
-		}
-		excludeIPv4 = append(excludeIPv4, ipnet)
-	}
-
-	excludeIPv6 := []*net.IPNet{}
-	if s.ExcludeIPv6 == nil {
-		excludeIPv6 = nil
+// copyIPNets returns a copy of ipnets
+func copyIPNets(ipnets []*net.IPNet) []*net.IPNet {
+	if ipnets == nil {
+		return nil
 	}
-	for _, e := range s.ExcludeIPv6 {
+	c := []*net.IPNet{}
+	for _, i := range ipnets {
 		ipnet := &net.IPNet{
-			IP:   append(e.IP[:0:0], e.IP...),
-			Mask: append(e.Mask[:0:0], e.Mask...),
+			IP:   append(i.IP[:0:0], i.IP...),
+			Mask: append(i.Mask[:0:0], i.Mask...),
 		}
-		excludeIPv6 = append(excludeIPv6, ipnet)
+		c = append(c, ipnet)
 	}
+	return c
+}
 
+// Copy returns a copy of split
+func (s *Split) Copy() Split {
 	return Split{
-		ExcludeIPv4: excludeIPv4,
-		ExcludeIPv6: excludeIPv6,
+		IncludeIPv4: copyIPNets(s.IncludeIPv4),
+		IncludeIPv6: copyIPNets(s.IncludeIPv6),
+
+		ExcludeIPv4: copyIPNets(s.ExcludeIPv4),
+		ExcludeIPv6: copyIPNets(s.ExcludeIPv6),
 		ExcludeDNS:  append(s.ExcludeDNS[:0:0], s.ExcludeDNS...),
 
 		ExcludeVirtualSubnetsOnlyIPv4: s.ExcludeVirtualSubnetsOnlyIPv4,
@@ -143,6 +229,62 @@ func (s *Split) DNSExcludes() []string {
 	return excludes
 }
 
+// reverseZones returns the reverse DNS zones in "in-addr.arpa." or
+// "ip6.arpa." of ipnet including the trailing ".". Zones are delegated on
+// octet boundaries for IPv4 and nibble boundaries for IPv6, so a network with
+// a prefix length in between is covered by multiple zones
+func reverseZones(ipnet *net.IPNet) []string {
+	ones, bits := ipnet.Mask.Size()
+	ip := ipnet.IP.Mask(ipnet.Mask)
+	if ip == nil {
+		return nil
+	}
+
+	// get digits of the address, octets for IPv4 and nibbles for IPv6
+	size, format, suffix := 4, "%x", "ip6.arpa."
+	digits := []int{}
+	if bits == 8*net.IPv4len {
+		size, format, suffix = 8, "%d", "in-addr.arpa."
+		for _, b := range ip.To4() {
+			digits = append(digits, int(b))
+		}
+	} else {
+		for _, b := range ip {
+			digits = append(digits, int(b>>4), int(b&0xf))
+		}
+	}
+
+	// n is the number of digits in the zones, the last digit of the
+	// zones contains free bits not covered by the prefix length
+	n := (ones + size - 1) / size
+	free := n*size - ones
+	zones := []string{}
+	for i := 0; i < 1<<free; i++ {
+		zone := suffix
+		for j := 0; j < n; j++ {
+			d := digits[j]
+			if j == n-1 {
+				d |= i
+			}
+			zone = fmt.Sprintf(format, d) + "." + zone
+		}
+		zones = append(zones, zone)
+	}
+	return zones
+}
+
+// ReverseZones returns the reverse DNS zones of the include networks in the
+// split routing configuration including the trailing "."
+func (s *Split) ReverseZones() []string {
+	zones := []string{}
+	for _, ipnets := range [][]*net.IPNet{s.IncludeIPv4, s.IncludeIPv6} {
+		for _, ipnet := range ipnets {
+			zones = append(zones, reverseZones(ipnet)...)
+		}
+	}
+	return zones
+}
+
 // Flags are other configuration settings in Config
 type Flags struct {
 	DisableAlwaysOnVPN bool
@@ -155,6 +297,25 @@ func (f *Flags) Copy() Flags {
 	}
 }
 
+// Banner is the banner of the VPN gateway in Config
+type Banner struct {
+	// Text is the banner text as UTF-8, banners in legacy charsets are
+	// converted
+	Text string
+
+	// Language is the BCP 47 language tag of Text, e.g., "de", empty if
+	// unknown
+	Language string
+}
+
+// Copy returns a copy of banner
+func (b *Banner) Copy() Banner {
+	return Banner{
+		Text:     b.Text,
+		Language: b.Language,
+	}
+}
+
 // Config is a VPN configuration
 type Config struct {
 	Gateway net.IP
@@ -166,6 +327,7 @@ type Config struct {
 	DNS     DNS
 	Split   Split
 	Flags   Flags
+	Banner  Banner
 }
 
 // Copy returns a new copy of config
@@ -183,9 +345,26 @@ func (c *Config) Copy() *Config {
 		DNS:     c.DNS.Copy(),
 		Split:   c.Split.Copy(),
 		Flags:   c.Flags.Copy(),
+		Banner:  c.Banner.Copy(),
 	}
 }
 
+// DNSRemotes returns a map of DNS remotes from the configuration, see
+// DNS.Remotes. In split-DNS mode, it also maps the reverse zones of the
+// include networks to the VPN DNS servers, so reverse lookups of tunneled
+// addresses are resolved with the VPN DNS servers
+func (c *Config) DNSRemotes() map[string][]string {
+	remotes := c.DNS.Remotes()
+	if len(remotes) == 0 || !c.DNS.SplitDNS() {
+		return remotes
+	}
+
+	for _, zone := range c.Split.ReverseZones() {
+		remotes[zone] = c.DNS.Servers()
+	}
+	return remotes
+}
+
 // Empty returns if the config is empty
 func (c *Config) Empty() bool {
 	empty := New()
@@ -220,11 +399,57 @@ func (c *Config) Valid() bool {
 			return false
 		}
 	}
+
+	// check dns transports
+	for server, transport := range c.DNS.Transports {
+		if net.ParseIP(server) == nil || !ValidDNSTransport(transport) {
+			log.WithFields(log.Fields{
+				"server":    server,
+				"transport": transport,
+			}).Error("VPNConfig has invalid DNS transport")
+			return false
+		}
+	}
 	// TODO: check more?
 
 	return true
 }
 
+// JSONVersion is the version of the JSON encoding of Config, it is increased
+// on incompatible changes of the encoding
+const JSONVersion = 1
+
+// configFields are the fields of Config without its JSON methods
+type configFields Config
+
+// jsonConfig is the JSON encoding of Config: the version is followed by the
+// fields in the order of Config, no field is omitted
+type jsonConfig struct {
+	Version int
+	*configFields
+}
+
+// MarshalJSON returns the configuration in its versioned JSON encoding
+func (c *Config) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&jsonConfig{
+		Version:      JSONVersion,
+		configFields: (*configFields)(c),
+	})
+}
+
+// UnmarshalJSON parses the configuration in its versioned JSON encoding, a
+// missing version is accepted for compatibility with older encodings
+func (c *Config) UnmarshalJSON(b []byte) error {
+	j := &jsonConfig{configFields: (*configFields)(c)}
+	if err := json.Unmarshal(b, j); err != nil {
+		return err
+	}
+	if j.Version > JSONVersion {
+		return fmt.Errorf("unsupported config JSON version %d", j.Version)
+	}
+	return nil
+}
+
 // JSON returns the configuration as JSON
 func (c *Config) JSON() ([]byte, error) {
 	b, err := json.Marshal(c)
@@ -65,6 +65,76 @@ func TestDNSRemotes(t *testing.T) {
 	if !reflect.DeepEqual(got, want) {
 		t.Errorf("got %v, want %v", got, want)
 	}
+
+	// test split dns
+	c.DNS.DefaultDomain = "example.com"
+	c.DNS.SplitDomains = []string{"test.com."}
+	want = map[string][]string{
+		"example.com.": {dns4 + ":53", "[" + dns6 + "]:53"},
+		"test.com.":    {dns4 + ":53", "[" + dns6 + "]:53"},
+	}
+	got = c.DNS.Remotes()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// test tunnel all dns
+	c.DNS.TunnelAll = true
+	want = map[string][]string{
+		".": {dns4 + ":53", "[" + dns6 + "]:53"},
+	}
+	got = c.DNS.Remotes()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// test transports
+	c.DNS.Transports = map[string]string{
+		dns4: DNSTransportTCP,
+		dns6: DNSTransportTLS,
+	}
+	want = map[string][]string{
+		".": {"tcp://" + dns4 + ":53", "tls://[" + dns6 + "]:853"},
+	}
+	got = c.DNS.Remotes()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestDNSServers tests Servers of DNS
+func TestDNSServers(t *testing.T) {
+	d := &DNS{}
+	if got := d.Servers(); len(got) != 0 {
+		t.Errorf("got %v, want empty", got)
+	}
+
+	d.ServersIPv4 = []net.IP{net.ParseIP("192.168.1.1")}
+	d.ServersIPv6 = []net.IP{net.ParseIP("2001:db8::1")}
+	d.Transports = map[string]string{"2001:db8::1": DNSTransportTLS}
+	want := []string{"192.168.1.1:53", "tls://[2001:db8::1]:853"}
+	got := d.Servers()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestDNSSplitDNS tests SplitDNS of DNS
+func TestDNSSplitDNS(t *testing.T) {
+	d := &DNS{}
+	if d.SplitDNS() {
+		t.Error("empty DNS should not be split DNS")
+	}
+
+	d.SplitDomains = []string{"example.com"}
+	if !d.SplitDNS() {
+		t.Error("DNS with split domains should be split DNS")
+	}
+
+	d.TunnelAll = true
+	if d.SplitDNS() {
+		t.Error("DNS with tunnel all should not be split DNS")
+	}
 }
 
 // TestSplitDNSExcludes tests DNSExcludes of Split
@@ -87,6 +157,87 @@ func TestSplitDNSExcludes(t *testing.T) {
 	}
 }
 
+// TestSplitReverseZones tests ReverseZones of Split
+func TestSplitReverseZones(t *testing.T) {
+	// test empty
+	c := New()
+	if len(c.Split.ReverseZones()) != 0 {
+		t.Errorf("got %d, want 0", len(c.Split.ReverseZones()))
+	}
+
+	// test networks
+	for _, test := range []struct {
+		ipnet string
+		want  []string
+	}{
+		{"10.0.0.0/8", []string{"10.in-addr.arpa."}},
+		{"192.168.1.0/24", []string{"1.168.192.in-addr.arpa."}},
+		{"172.16.0.0/14", []string{
+			"16.172.in-addr.arpa.",
+			"17.172.in-addr.arpa.",
+			"18.172.in-addr.arpa.",
+			"19.172.in-addr.arpa.",
+		}},
+		{"10.1.2.3/32", []string{"3.2.1.10.in-addr.arpa."}},
+		{"0.0.0.0/0", []string{"in-addr.arpa."}},
+		{"2001:db8::/32", []string{"8.b.d.0.1.0.0.2.ip6.arpa."}},
+		{"2001:db8::/31", []string{
+			"8.b.d.0.1.0.0.2.ip6.arpa.",
+			"9.b.d.0.1.0.0.2.ip6.arpa.",
+		}},
+		{"fd00::/8", []string{"d.f.ip6.arpa."}},
+	} {
+		c := New()
+		_, ipnet, err := net.ParseCIDR(test.ipnet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ipnet.IP.To4() != nil {
+			c.Split.IncludeIPv4 = []*net.IPNet{ipnet}
+		} else {
+			c.Split.IncludeIPv6 = []*net.IPNet{ipnet}
+		}
+		got := c.Split.ReverseZones()
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.ipnet, got, test.want)
+		}
+	}
+}
+
+// TestConfigDNSRemotes tests DNSRemotes of Config
+func TestConfigDNSRemotes(t *testing.T) {
+	// test empty
+	c := New()
+	c.Split.IncludeIPv4 = []*net.IPNet{{
+		IP:   net.IPv4(10, 0, 0, 0).To4(),
+		Mask: net.CIDRMask(8, 32),
+	}}
+	if len(c.DNSRemotes()) != 0 {
+		t.Errorf("got %d, want 0", len(c.DNSRemotes()))
+	}
+
+	// test without split dns
+	c.DNS.ServersIPv4 = []net.IP{net.IPv4(10, 0, 0, 1)}
+	want := map[string][]string{
+		".": {"10.0.0.1:53"},
+	}
+	got := c.DNSRemotes()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// test split dns
+	c.DNS.SplitDomains = []string{"example.com"}
+	want = map[string][]string{
+		"example.com.":     {"10.0.0.1:53"},
+		"10.in-addr.arpa.": {"10.0.0.1:53"},
+	}
+	got = c.DNSRemotes()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
 // TestConfigCopy tests Copy of Config
 func TestConfigCopy(t *testing.T) {
 	want := getValidTestConfig()
@@ -153,6 +304,13 @@ func getValidTestConfig() *Config {
 	c.IPv4.Netmask = net.IPv4Mask(255, 255, 255, 0)
 	c.DNS.DefaultDomain = "mycompany.com"
 	c.DNS.ServersIPv4 = []net.IP{net.IPv4(192, 168, 0, 1)}
+	c.DNS.Transports = map[string]string{"192.168.0.1": DNSTransportTCP}
+	c.Split.IncludeIPv4 = []*net.IPNet{
+		{
+			IP:   net.IPv4(10, 0, 0, 0),
+			Mask: net.IPv4Mask(255, 0, 0, 0),
+		},
+	}
 	c.Split.ExcludeIPv4 = []*net.IPNet{
 		{
 			IP:   net.IPv4(0, 0, 0, 0),
@@ -166,6 +324,8 @@ func getValidTestConfig() *Config {
 	c.Split.ExcludeDNS = []string{"this.other.com", "that.other.com"}
 	c.Split.ExcludeVirtualSubnetsOnlyIPv4 = true
 	c.Flags.DisableAlwaysOnVPN = true
+	c.Banner.Text = "Willkommen, Grüße aus dem Büro"
+	c.Banner.Language = "de"
 
 	return c
 }
@@ -196,6 +356,18 @@ func TestConfigValid(t *testing.T) {
 	if got != want {
 		t.Errorf("got %t, want %t", got, want)
 	}
+
+	// test invalid dns transports
+	for _, transports := range []map[string]string{
+		{"192.168.0.1": "invalid"},
+		{"invalid": DNSTransportTCP},
+	} {
+		c = getValidTestConfig()
+		c.DNS.Transports = transports
+		if c.Valid() {
+			t.Errorf("got valid, want invalid for %v", transports)
+		}
+	}
 }
 
 // TestConfigJSON tests JSON of Config
@@ -219,6 +391,44 @@ func TestConfigJSON(t *testing.T) {
 	}
 }
 
+// TestConfigJSONFormat tests the format of the JSON encoding of Config
+func TestConfigJSONFormat(t *testing.T) {
+	// all fields in order, none omitted
+	c := New()
+	c.Gateway = net.ParseIP("192.168.0.1")
+	c.Device.Name = "tun0"
+	want := `{"Version":1,"Gateway":"192.168.0.1","PID":0,"Timeout":0,` +
+		`"Device":{"Name":"tun0","MTU":0},` +
+		`"IPv4":{"Address":"","Netmask":null},` +
+		`"IPv6":{"Address":"","Netmask":null},` +
+		`"DNS":{"DefaultDomain":"","ServersIPv4":null,` +
+		`"ServersIPv6":null,"SplitDomains":null,"TunnelAll":false,` +
+		`"Transports":null},` +
+		`"Split":{"IncludeIPv4":null,"IncludeIPv6":null,` +
+		`"ExcludeIPv4":null,"ExcludeIPv6":null,"ExcludeDNS":null,` +
+		`"ExcludeVirtualSubnetsOnlyIPv4":false},` +
+		`"Flags":{"DisableAlwaysOnVPN":false},` +
+		`"Banner":{"Text":"","Language":""}}`
+	b, err := c.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+
+	// older encoding without version
+	c, err = NewFromJSON([]byte(`{"PID":123}`))
+	if err != nil || c.PID != 123 {
+		t.Errorf("got %v, %v", c, err)
+	}
+
+	// unsupported version
+	if _, err := NewFromJSON([]byte(`{"Version":2}`)); err == nil {
+		t.Error("unsupported version should return error")
+	}
+}
+
 // TestNew tests New
 func TestNew(t *testing.T) {
 	c := New()
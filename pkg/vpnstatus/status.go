@@ -2,6 +2,7 @@ package vpnstatus
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
 )
@@ -96,6 +97,166 @@ func (o OCRunning) String() string {
 	return ""
 }
 
+// ScheduleState is the current state of the connection schedule
+type ScheduleState uint32
+
+// ScheduleState states
+const (
+	ScheduleStateUnknown ScheduleState = iota
+	ScheduleStateDisabled
+	ScheduleStateInactive
+	ScheduleStateConnect
+	ScheduleStateForbid
+)
+
+// Forbidden returns whether ScheduleState is in state "forbid"
+func (s ScheduleState) Forbidden() bool {
+	return s == ScheduleStateForbid
+}
+
+// String returns ScheduleState as string
+func (s ScheduleState) String() string {
+	switch s {
+	case ScheduleStateUnknown:
+		return "unknown"
+	case ScheduleStateDisabled:
+		return "disabled"
+	case ScheduleStateInactive:
+		return "inactive"
+	case ScheduleStateConnect:
+		return "connect"
+	case ScheduleStateForbid:
+		return "forbid"
+	}
+	return ""
+}
+
+// CompressionDefault is the compression of connections that use the default
+// compression mode of openconnect
+const CompressionDefault = "default"
+
+// Connectivity is the network connectivity of the host
+type Connectivity uint32
+
+// Connectivity states
+const (
+	ConnectivityUnknown Connectivity = iota
+	ConnectivityOffline
+	ConnectivityOnline
+)
+
+// Offline returns whether Connectivity is in state "offline"
+func (c Connectivity) Offline() bool {
+	return c == ConnectivityOffline
+}
+
+// String returns Connectivity as string
+func (c Connectivity) String() string {
+	switch c {
+	case ConnectivityUnknown:
+		return "unknown"
+	case ConnectivityOffline:
+		return "offline"
+	case ConnectivityOnline:
+		return "online"
+	}
+	return ""
+}
+
+// TNDState is the state of the trusted network detection
+type TNDState uint32
+
+// TNDState states
+const (
+	TNDStateUnknown TNDState = iota
+	TNDStateEnabled
+	TNDStateDisabled
+)
+
+// Disabled returns whether TNDState is in state "disabled"
+func (t TNDState) Disabled() bool {
+	return t == TNDStateDisabled
+}
+
+// String returns TNDState as string
+func (t TNDState) String() string {
+	switch t {
+	case TNDStateUnknown:
+		return "unknown"
+	case TNDStateEnabled:
+		return "enabled"
+	case TNDStateDisabled:
+		return "disabled"
+	}
+	return ""
+}
+
+// TrafPolState is the state of the traffic policing
+type TrafPolState uint32
+
+// TrafPolState states
+const (
+	TrafPolStateUnknown TrafPolState = iota
+	TrafPolStateActive
+	TrafPolStateInactive
+	TrafPolStateDisabled
+)
+
+// Active returns whether TrafPolState is in state "active"
+func (t TrafPolState) Active() bool {
+	return t == TrafPolStateActive
+}
+
+// String returns TrafPolState as string
+func (t TrafPolState) String() string {
+	switch t {
+	case TrafPolStateUnknown:
+		return "unknown"
+	case TrafPolStateActive:
+		return "active"
+	case TrafPolStateInactive:
+		return "inactive"
+	case TrafPolStateDisabled:
+		return "disabled"
+	}
+	return ""
+}
+
+// PauseState is the pause state of the VPN connection
+type PauseState uint32
+
+// PauseState states
+const (
+	PauseStateUnknown PauseState = iota
+	PauseStateNotPaused
+	PauseStatePaused
+)
+
+// Paused returns whether PauseState is in state "paused"
+func (p PauseState) Paused() bool {
+	return p == PauseStatePaused
+}
+
+// String returns PauseState as string
+func (p PauseState) String() string {
+	switch p {
+	case PauseStateUnknown:
+		return "unknown"
+	case PauseStateNotPaused:
+		return "not paused"
+	case PauseStatePaused:
+		return "paused"
+	}
+	return ""
+}
+
+// APIs of the daemon
+const (
+	APISocket = "socket"
+	APIDBus   = "dbus"
+	APIGRPC   = "grpc"
+)
+
 // Status is a VPN status
 type Status struct {
 	TrustedNetwork  TrustedNetwork
@@ -106,6 +267,79 @@ type Status struct {
 	Servers         []string
 	OCRunning       OCRunning
 	VPNConfig       *vpnconfig.Config
+	Proxy           string
+	RetryAt         int64
+	RetryAttempt    uint32
+	RXBytes         uint64
+	TXBytes         uint64
+	RXPackets       uint64
+	TXPackets       uint64
+	DNSLeaksBlocked uint64
+
+	// RouteAggregations is the number of domains whose DNS-based split
+	// excludes were aggregated into covering prefixes because of route
+	// churn
+	RouteAggregations uint64
+
+	// GatewayExcludes are the addresses of the VPN gateway that are
+	// always excluded from the tunnel, e.g., "192.0.2.1/32", including
+	// alternative addresses the gateway roams to during the connection
+	GatewayExcludes []string
+
+	// DNSServers are the VPN DNS servers in use, DNSSearchDomains the
+	// search domains and DNSSplitDomains the domains resolved with the VPN
+	// DNS servers in split-DNS mode
+	DNSServers       []string
+	DNSSearchDomains []string
+	DNSSplitDomains  []string
+
+	// ScheduleState is the state of the connection schedule
+	ScheduleState ScheduleState
+
+	// Connectivity is the network connectivity of the host, it is
+	// offline without carrier or default route
+	Connectivity Connectivity
+
+	// Compression is the compression mode of the current connection,
+	// e.g., "stateless"
+	Compression string
+
+	// TNDState is the state of the trusted network detection, it can be
+	// disabled temporarily at runtime
+	TNDState TNDState
+
+	// CSDWrapper is the trusted CSD wrapper script that clients use for
+	// hostscan during authentication, empty if not configured
+	CSDWrapper string
+
+	// DisconnectReason is the reason of the last gateway-initiated
+	// disconnect, e.g., "idle-timeout", empty if unknown
+	DisconnectReason string
+
+	// APIs are the active APIs of the daemon, e.g., "socket" and "dbus"
+	APIs []string
+
+	// TrafPolState is the state of the traffic policing, it can be
+	// disabled temporarily at runtime
+	TrafPolState TrafPolState
+
+	// PreferredServer is the host name of the preferred VPN server in the
+	// XML profile, empty if it is not set
+	PreferredServer string
+
+	// Uptime is the duration of the current connection in seconds, it is
+	// updated with the traffic statistics
+	Uptime uint64
+
+	// OwnerUID is the user ID of the user that initiated the current
+	// connection, -1 if there is no connection or the user is unknown;
+	// Owner is the name of the user
+	OwnerUID int64
+	Owner    string
+
+	// PauseState is the pause state of the VPN connection, split routing
+	// and DNS of a paused connection are removed
+	PauseState PauseState
 }
 
 // Copy returns a copy of Status
@@ -122,9 +356,74 @@ func (s *Status) Copy() *Status {
 		Servers:         append(s.Servers[:0:0], s.Servers...),
 		OCRunning:       s.OCRunning,
 		VPNConfig:       s.VPNConfig.Copy(),
+		Proxy:           s.Proxy,
+		RetryAt:         s.RetryAt,
+		RetryAttempt:    s.RetryAttempt,
+		RXBytes:         s.RXBytes,
+		TXBytes:         s.TXBytes,
+		RXPackets:       s.RXPackets,
+		TXPackets:       s.TXPackets,
+		DNSLeaksBlocked: s.DNSLeaksBlocked,
+
+		RouteAggregations: s.RouteAggregations,
+		GatewayExcludes:   append(s.GatewayExcludes[:0:0], s.GatewayExcludes...),
+
+		DNSServers:       append(s.DNSServers[:0:0], s.DNSServers...),
+		DNSSearchDomains: append(s.DNSSearchDomains[:0:0], s.DNSSearchDomains...),
+		DNSSplitDomains:  append(s.DNSSplitDomains[:0:0], s.DNSSplitDomains...),
+
+		ScheduleState: s.ScheduleState,
+		Connectivity:  s.Connectivity,
+		Compression:   s.Compression,
+		TNDState:      s.TNDState,
+		CSDWrapper:    s.CSDWrapper,
+
+		DisconnectReason: s.DisconnectReason,
+		APIs:             append(s.APIs[:0:0], s.APIs...),
+		TrafPolState:     s.TrafPolState,
+		PreferredServer:  s.PreferredServer,
+		Uptime:           s.Uptime,
+		OwnerUID:         s.OwnerUID,
+		Owner:            s.Owner,
+		PauseState:       s.PauseState,
 	}
 }
 
+// JSONVersion is the version of the JSON encoding of Status, it is increased
+// on incompatible changes of the encoding
+const JSONVersion = 1
+
+// statusFields are the fields of Status without its JSON methods
+type statusFields Status
+
+// jsonStatus is the JSON encoding of Status: the version is followed by the
+// fields in the order of Status, no field is omitted
+type jsonStatus struct {
+	Version int
+	*statusFields
+}
+
+// MarshalJSON returns the status in its versioned JSON encoding
+func (s *Status) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&jsonStatus{
+		Version:      JSONVersion,
+		statusFields: (*statusFields)(s),
+	})
+}
+
+// UnmarshalJSON parses the status in its versioned JSON encoding, a missing
+// version is accepted for compatibility with older encodings
+func (s *Status) UnmarshalJSON(b []byte) error {
+	j := &jsonStatus{statusFields: (*statusFields)(s)}
+	if err := json.Unmarshal(b, j); err != nil {
+		return err
+	}
+	if j.Version > JSONVersion {
+		return fmt.Errorf("unsupported status JSON version %d", j.Version)
+	}
+	return nil
+}
+
 // JSON returns the Status as JSON
 func (s *Status) JSON() ([]byte, error) {
 	b, err := json.Marshal(s)
@@ -148,5 +447,7 @@ func NewFromJSON(b []byte) (*Status, error) {
 
 // New returns a new Status
 func New() *Status {
-	return &Status{}
+	return &Status{
+		OwnerUID: -1,
+	}
 }
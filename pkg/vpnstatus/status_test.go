@@ -14,6 +14,20 @@ func TestStatusCopy(t *testing.T) {
 	if !reflect.DeepEqual(got, want) {
 		t.Errorf("got %v, want %v", got, want)
 	}
+
+	// dns details
+	want.DNSServers = []string{"192.168.1.1:53"}
+	want.DNSSearchDomains = []string{"example.com"}
+	want.DNSSplitDomains = []string{"internal.example.com"}
+	got = want.Copy()
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	got.DNSServers[0] = "changed"
+	if want.DNSServers[0] == "changed" {
+		t.Error("copy should not share dns servers")
+	}
 }
 
 // TestJSON tests JSON and NewFromJSON of Status
@@ -32,6 +46,39 @@ func TestJSON(t *testing.T) {
 	}
 }
 
+// TestJSONFormat tests the format of the JSON encoding of Status
+func TestJSONFormat(t *testing.T) {
+	// all fields in order, none omitted
+	want := `{"Version":1,"TrustedNetwork":0,"ConnectionState":0,` +
+		`"IP":"","Device":"","ConnectedAt":0,"Servers":null,` +
+		`"OCRunning":0,"VPNConfig":null,"Proxy":"","RetryAt":0,` +
+		`"RetryAttempt":0,"RXBytes":0,"TXBytes":0,"RXPackets":0,` +
+		`"TXPackets":0,"DNSLeaksBlocked":0,"RouteAggregations":0,` +
+		`"GatewayExcludes":null,"DNSServers":null,"DNSSearchDomains":null,` +
+		`"DNSSplitDomains":null,"ScheduleState":0,"Connectivity":0,` +
+		`"Compression":"","TNDState":0,"CSDWrapper":"",` +
+		`"DisconnectReason":"","APIs":null,"TrafPolState":0,"PreferredServer":"",` +
+		`"Uptime":0,"OwnerUID":-1,"Owner":"","PauseState":0}`
+	b, err := New().JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+
+	// older encoding without version
+	s, err := NewFromJSON([]byte(`{"IP":"192.168.0.123"}`))
+	if err != nil || s.IP != "192.168.0.123" {
+		t.Errorf("got %v, %v", s, err)
+	}
+
+	// unsupported version
+	if _, err := NewFromJSON([]byte(`{"Version":2}`)); err == nil {
+		t.Error("unsupported version should return error")
+	}
+}
+
 // TestNew tests New
 func TestNew(t *testing.T) {
 	s := New()
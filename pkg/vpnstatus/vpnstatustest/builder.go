@@ -0,0 +1,89 @@
+// Package vpnstatustest contains builders of VPN status values for testing
+// code that uses the VPN status of OC-Daemon
+package vpnstatustest
+
+import (
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// Builder builds a VPN status, its methods can be chained
+type Builder struct {
+	status *vpnstatus.Status
+}
+
+// TrustedNetwork sets the trusted network state
+func (b *Builder) TrustedNetwork(trusted bool) *Builder {
+	b.status.TrustedNetwork = vpnstatus.TrustedNetworkNotTrusted
+	if trusted {
+		b.status.TrustedNetwork = vpnstatus.TrustedNetworkTrusted
+	}
+	return b
+}
+
+// Servers sets the VPN servers in the XML profile
+func (b *Builder) Servers(servers ...string) *Builder {
+	b.status.Servers = append([]string{}, servers...)
+	return b
+}
+
+// Connected sets the status of a connection with ip on device established
+// at connectedAt
+func (b *Builder) Connected(ip, device string, connectedAt time.Time) *Builder {
+	b.status.ConnectionState = vpnstatus.ConnectionStateConnected
+	b.status.OCRunning = vpnstatus.OCRunningRunning
+	b.status.IP = ip
+	b.status.Device = device
+	b.status.ConnectedAt = connectedAt.Unix()
+	return b
+}
+
+// Disconnected sets the status of no connection
+func (b *Builder) Disconnected() *Builder {
+	b.status.ConnectionState = vpnstatus.ConnectionStateDisconnected
+	b.status.OCRunning = vpnstatus.OCRunningNotRunning
+	b.status.IP = ""
+	b.status.Device = ""
+	b.status.ConnectedAt = 0
+	b.status.VPNConfig = nil
+	return b
+}
+
+// VPNConfig sets the VPN configuration of the connection
+func (b *Builder) VPNConfig(config *vpnconfig.Config) *Builder {
+	b.status.VPNConfig = config.Copy()
+	return b
+}
+
+// Traffic sets the traffic statistics of the connection
+func (b *Builder) Traffic(rxBytes, txBytes uint64) *Builder {
+	b.status.RXBytes = rxBytes
+	b.status.TXBytes = txBytes
+	return b
+}
+
+// Build returns a copy of the built status
+func (b *Builder) Build() *vpnstatus.Status {
+	return b.status.Copy()
+}
+
+// NewBuilder returns a new Builder for a status without connection on an
+// untrusted network
+func NewBuilder() *Builder {
+	b := &Builder{status: vpnstatus.New()}
+	return b.TrustedNetwork(false).Disconnected()
+}
+
+// Connected returns the status of a connection with ip on device
+// established now
+func Connected(ip, device string) *vpnstatus.Status {
+	return NewBuilder().Connected(ip, device, time.Now()).Build()
+}
+
+// Disconnected returns the status without connection on an untrusted
+// network
+func Disconnected() *vpnstatus.Status {
+	return NewBuilder().Build()
+}
@@ -0,0 +1,58 @@
+package vpnstatustest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
+)
+
+// TestBuilder tests Builder
+func TestBuilder(t *testing.T) {
+	now := time.Now()
+	config := vpnconfig.New()
+	config.Device.Name = "tun0"
+
+	b := NewBuilder().
+		TrustedNetwork(true).
+		Servers("server 1", "server 2").
+		Connected("192.168.0.123", "tun0", now).
+		VPNConfig(config).
+		Traffic(1, 2)
+	s := b.Build()
+	if !s.TrustedNetwork.Trusted() ||
+		len(s.Servers) != 2 ||
+		!s.ConnectionState.Connected() ||
+		!s.OCRunning.Running() ||
+		s.IP != "192.168.0.123" ||
+		s.ConnectedAt != now.Unix() ||
+		s.VPNConfig.Device.Name != "tun0" ||
+		s.RXBytes != 1 || s.TXBytes != 2 {
+		t.Errorf("got %+v", s)
+	}
+
+	// built status is a copy
+	s.IP = "changed"
+	if b.Build().IP == "changed" {
+		t.Error("build should return copy")
+	}
+
+	// disconnect
+	s = b.Disconnected().Build()
+	if s.ConnectionState.Connected() || s.OCRunning.Running() ||
+		s.IP != "" || s.VPNConfig != nil {
+		t.Errorf("got %+v", s)
+	}
+}
+
+// TestConnectedDisconnected tests Connected and Disconnected
+func TestConnectedDisconnected(t *testing.T) {
+	if s := Connected("192.168.0.123", "tun0"); !s.ConnectionState.Connected() ||
+		s.IP != "192.168.0.123" || s.Device != "tun0" {
+		t.Errorf("got %+v", s)
+	}
+	if s := Disconnected(); s.ConnectionState.Connected() ||
+		s.TrustedNetwork.Trusted() {
+		t.Errorf("got %+v", s)
+	}
+}
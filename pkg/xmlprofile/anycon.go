@@ -27,6 +27,11 @@ type AlwaysOn struct {
 	ConnectFailurePolicy ConnectFailurePolicy `xml:"ConnectFailurePolicy"`
 	AllowVPNDisconnect   string               `xml:"AllowVPNDisconnect"`
 	AllowedHosts         string               `xml:"AllowedHosts"`
+
+	// CaptivePortalDetectionServers is a comma-separated list of
+	// captive portal detection servers, it is not part of AnyConnect
+	// profiles and only used by oc-daemon
+	CaptivePortalDetectionServers string `xml:"CaptivePortalDetectionServers"`
 }
 
 // AutomaticVPNPolicy contains the automatic vpn policy in the profile
@@ -31,6 +31,21 @@ func (p *Profile) GetAllowedHosts() (hosts []string) {
 	return
 }
 
+// GetCPDServers returns the captive portal detection servers in the XML
+// profile
+func (p *Profile) GetCPDServers() (servers []string) {
+	ss := p.AutomaticVPNPolicy.AlwaysOn.CaptivePortalDetectionServers
+	for _, s := range strings.Split(ss, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		log.WithField("server", s).Debug("Getting CPD server from Profile")
+		servers = append(servers, s)
+	}
+	return
+}
+
 // GetVPNServers returns the VPN servers in the XML profile
 func (p *Profile) GetVPNServers() (servers []string) {
 	for _, h := range p.ServerList.HostEntry {
@@ -62,6 +77,18 @@ func (p *Profile) GetVPNServerHostNames() (servers []string) {
 	return
 }
 
+// GetVPNServerHostEntries returns the host entries of the VPN servers in
+// the XML profile
+func (p *Profile) GetVPNServerHostEntries() (entries []HostEntry) {
+	for _, s := range p.ServerList.HostEntry {
+		if strings.HasPrefix(s.PrimaryProtocol.Flag, "IPsec") {
+			continue
+		}
+		entries = append(entries, s)
+	}
+	return
+}
+
 // GetTNDServers returns the TND servers in the XML profile
 func (p *Profile) GetTNDServers() (servers []string) {
 	for _, s := range p.AutomaticVPNPolicy.TrustedHTTPSServerList {
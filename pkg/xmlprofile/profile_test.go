@@ -32,6 +32,30 @@ func TestProfileGetAllowedHosts(t *testing.T) {
 	}
 }
 
+// TestProfileGetCPDServers tests GetCPDServers of Profile
+func TestProfileGetCPDServers(t *testing.T) {
+	p := NewProfile()
+
+	// test empty
+	var want []string
+	got := p.GetCPDServers()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// test filled
+	p.AutomaticVPNPolicy.AlwaysOn.CaptivePortalDetectionServers =
+		"cpd1.example.com, cpd2.example.com,"
+	want = []string{
+		"cpd1.example.com",
+		"cpd2.example.com",
+	}
+	got = p.GetCPDServers()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
 // TestProfileGetVPNServers tests GetVPNServers of Profile
 func TestProfileGetVPNServers(t *testing.T) {
 	p := NewProfile()
@@ -94,6 +118,33 @@ func TestProfileGetVPNServerHostNames(t *testing.T) {
 	}
 }
 
+// TestProfileGetVPNServerHostEntries tests GetVPNServerHostEntries of Profile
+func TestProfileGetVPNServerHostEntries(t *testing.T) {
+	p := NewProfile()
+
+	// test empty
+	if got := p.GetVPNServerHostEntries(); got != nil {
+		t.Errorf("got %v, want nil", got)
+	}
+
+	// test filled, without ipsec servers
+	vpn1 := HostEntry{
+		HostName:    "VPN 1",
+		HostAddress: "vpn1.mycompany.com",
+	}
+	ipsec := HostEntry{
+		HostName:    "IPsec",
+		HostAddress: "ipsec.mycompany.com",
+	}
+	ipsec.PrimaryProtocol.Flag = "IPsec"
+	p.ServerList.HostEntry = []HostEntry{vpn1, ipsec}
+	want := []HostEntry{vpn1}
+	got := p.GetVPNServerHostEntries()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
 // TestProfileGetTNDServers tests GetTNDServers of Profile
 func TestProfileGetTNDServers(t *testing.T) {
 	p := NewProfile()
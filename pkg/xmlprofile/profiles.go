@@ -0,0 +1,82 @@
+package xmlprofile
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var (
+	// ProfilesDir is the directory of additional named XML profiles, a
+	// profile with name is stored in the file <name>.xml
+	ProfilesDir = "/var/lib/oc-daemon/profiles"
+)
+
+// profileExt is the file extension of named XML profiles
+const profileExt = ".xml"
+
+// ValidProfileName returns whether name is a valid name of a named profile
+func ValidProfileName(name string) bool {
+	if name == "" || strings.HasPrefix(name, ".") {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z',
+			r >= 'A' && r <= 'Z',
+			r >= '0' && r <= '9',
+			r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ProfilePath returns the file path of the named profile with name in dir,
+// the default profile if name is empty
+func ProfilePath(dir, defaultProfile, name string) string {
+	if name == "" {
+		return defaultProfile
+	}
+	return filepath.Join(dir, name+profileExt)
+}
+
+// ListProfiles returns the names of the named profiles in dir
+func ListProfiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	names := []string{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), profileExt) {
+			continue
+		}
+		name := strings.TrimSuffix(e.Name(), profileExt)
+		if ValidProfileName(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// LoadNamedProfile loads the named profile with name from ProfilesDir, the
+// system profile if name is empty, returns nil if the profile could not be
+// loaded
+func LoadNamedProfile(name string) *Profile {
+	if name != "" && !ValidProfileName(name) {
+		return nil
+	}
+	profile, err := LoadProfile(ProfilePath(ProfilesDir, SystemProfile, name))
+	if err != nil {
+		return nil
+	}
+	return profile
+}
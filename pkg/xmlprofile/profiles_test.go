@@ -0,0 +1,99 @@
+package xmlprofile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestValidProfileName tests ValidProfileName
+func TestValidProfileName(t *testing.T) {
+	for _, valid := range []string{
+		"test", "Test-1", "test_profile", "test.example.com",
+	} {
+		if !ValidProfileName(valid) {
+			t.Errorf("%s should be valid", valid)
+		}
+	}
+	for _, invalid := range []string{
+		"", ".", "..", ".hidden", "../test", "test/profile", "test profile",
+	} {
+		if ValidProfileName(invalid) {
+			t.Errorf("%s should be invalid", invalid)
+		}
+	}
+}
+
+// TestProfilePath tests ProfilePath
+func TestProfilePath(t *testing.T) {
+	want := "/default/profile.xml"
+	got := ProfilePath("/profiles", want, "")
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	want = "/profiles/test.xml"
+	got = ProfilePath("/profiles", "/default/profile.xml", "test")
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+// TestListProfiles tests ListProfiles
+func TestListProfiles(t *testing.T) {
+	dir := t.TempDir()
+
+	// test not existing dir
+	got, err := ListProfiles(filepath.Join(dir, "does-not-exist"))
+	if err != nil || got != nil {
+		t.Errorf("got %v, %v, want nil, nil", got, err)
+	}
+
+	// test with profiles
+	for _, f := range []string{"b.xml", "a.xml", ".hidden.xml", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, f), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "d.xml"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a", "b"}
+	got, err = ListProfiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestLoadNamedProfile tests LoadNamedProfile
+func TestLoadNamedProfile(t *testing.T) {
+	dir := t.TempDir()
+	oldDir, oldSystem := ProfilesDir, SystemProfile
+	defer func() { ProfilesDir, SystemProfile = oldDir, oldSystem }()
+	ProfilesDir = dir
+	SystemProfile = filepath.Join(dir, "system.xml")
+
+	// test invalid name and not existing profiles
+	for _, name := range []string{"../test", "", "test"} {
+		if p := LoadNamedProfile(name); p != nil {
+			t.Errorf("got %v, want nil", p)
+		}
+	}
+
+	// test existing profiles
+	for _, f := range []string{"system.xml", "test.xml"} {
+		if err := os.WriteFile(filepath.Join(dir, f),
+			[]byte("<AnyConnectProfile></AnyConnectProfile>"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"", "test"} {
+		if p := LoadNamedProfile(name); p == nil {
+			t.Errorf("profile %q should be loaded", name)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+#!/bin/bash
+
+go run ./tools/dnsload \
+	-embedded \
+	-duration 10s \
+	-concurrency 10 \
+	-names "example.com.,example.org."
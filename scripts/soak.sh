@@ -0,0 +1,8 @@
+#!/bin/bash
+
+go build ./tools/soak
+sudo ./soak \
+	-cycles 1000 \
+	-check-every 100 \
+	-reload-profile \
+	-config "$PWD/oc-client.json"
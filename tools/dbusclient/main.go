@@ -35,6 +35,34 @@ func main() {
 	servers := dbusapi.ServersInvalid
 	ocRunning := dbusapi.OCRunningUnknown
 	vpnConfig := dbusapi.VPNConfigInvalid
+	proxy := dbusapi.ProxyInvalid
+	retryAt := dbusapi.RetryAtInvalid
+	retryAttempt := dbusapi.RetryAttemptInvalid
+	rxBytes := dbusapi.TrafficStatsInvalid
+	txBytes := dbusapi.TrafficStatsInvalid
+	rxPackets := dbusapi.TrafficStatsInvalid
+	txPackets := dbusapi.TrafficStatsInvalid
+	dnsLeaksBlocked := dbusapi.DNSLeaksBlockedInvalid
+	dnsServers := dbusapi.DNSDomainsInvalid
+	dnsSearchDomains := dbusapi.DNSDomainsInvalid
+	dnsSplitDomains := dbusapi.DNSDomainsInvalid
+	scheduleState := dbusapi.ScheduleStateUnknown
+	connectivity := dbusapi.ConnectivityUnknown
+	compression := dbusapi.CompressionInvalid
+	tndState := dbusapi.TNDStateUnknown
+	csdWrapper := dbusapi.CSDWrapperInvalid
+	disconnectReason := dbusapi.DisconnectReasonInvalid
+	apis := dbusapi.APIsInvalid
+	routeAggregations := dbusapi.RouteAggregationsInvalid
+	gatewayExcludes := dbusapi.GatewayExcludesInvalid
+	trafPolState := dbusapi.TrafPolStateUnknown
+	preferredServer := dbusapi.PreferredServerUnset
+	version := uint32(0)
+	capabilities := dbusapi.CapabilitiesInvalid
+	uptime := dbusapi.UptimeInvalid
+	ownerUID := dbusapi.OwnerUIDInvalid
+	owner := dbusapi.OwnerInvalid
+	pauseState := dbusapi.PauseStateUnknown
 
 	getProperty := func(name string, val any) {
 		err = conn.Object(dbusapi.Interface, dbusapi.Path).
@@ -51,6 +79,34 @@ func main() {
 	getProperty(dbusapi.PropertyServers, &servers)
 	getProperty(dbusapi.PropertyOCRunning, &ocRunning)
 	getProperty(dbusapi.PropertyVPNConfig, &vpnConfig)
+	getProperty(dbusapi.PropertyProxy, &proxy)
+	getProperty(dbusapi.PropertyRetryAt, &retryAt)
+	getProperty(dbusapi.PropertyRetryAttempt, &retryAttempt)
+	getProperty(dbusapi.PropertyRXBytes, &rxBytes)
+	getProperty(dbusapi.PropertyTXBytes, &txBytes)
+	getProperty(dbusapi.PropertyRXPackets, &rxPackets)
+	getProperty(dbusapi.PropertyTXPackets, &txPackets)
+	getProperty(dbusapi.PropertyDNSLeaksBlocked, &dnsLeaksBlocked)
+	getProperty(dbusapi.PropertyDNSServers, &dnsServers)
+	getProperty(dbusapi.PropertyDNSSearchDomains, &dnsSearchDomains)
+	getProperty(dbusapi.PropertyDNSSplitDomains, &dnsSplitDomains)
+	getProperty(dbusapi.PropertyScheduleState, &scheduleState)
+	getProperty(dbusapi.PropertyConnectivity, &connectivity)
+	getProperty(dbusapi.PropertyCompression, &compression)
+	getProperty(dbusapi.PropertyTNDState, &tndState)
+	getProperty(dbusapi.PropertyCSDWrapper, &csdWrapper)
+	getProperty(dbusapi.PropertyDisconnectReason, &disconnectReason)
+	getProperty(dbusapi.PropertyAPIs, &apis)
+	getProperty(dbusapi.PropertyRouteAggregations, &routeAggregations)
+	getProperty(dbusapi.PropertyGatewayExcludes, &gatewayExcludes)
+	getProperty(dbusapi.PropertyTrafPolState, &trafPolState)
+	getProperty(dbusapi.PropertyPreferredServer, &preferredServer)
+	getProperty(dbusapi.PropertyVersion, &version)
+	getProperty(dbusapi.PropertyCapabilities, &capabilities)
+	getProperty(dbusapi.PropertyUptime, &uptime)
+	getProperty(dbusapi.PropertyOwnerUID, &ownerUID)
+	getProperty(dbusapi.PropertyOwner, &owner)
+	getProperty(dbusapi.PropertyPauseState, &pauseState)
 
 	log.Println("TrustedNetwork:", trustedNetwork)
 	log.Println("ConnectionState:", connectionState)
@@ -60,6 +116,34 @@ func main() {
 	log.Println("Servers:", servers)
 	log.Println("OCRunning:", ocRunning)
 	log.Println("VPNConfig:", vpnConfig)
+	log.Println("Proxy:", proxy)
+	log.Println("RetryAt:", retryAt)
+	log.Println("RetryAttempt:", retryAttempt)
+	log.Println("RXBytes:", rxBytes)
+	log.Println("TXBytes:", txBytes)
+	log.Println("RXPackets:", rxPackets)
+	log.Println("TXPackets:", txPackets)
+	log.Println("DNSLeaksBlocked:", dnsLeaksBlocked)
+	log.Println("DNSServers:", dnsServers)
+	log.Println("DNSSearchDomains:", dnsSearchDomains)
+	log.Println("DNSSplitDomains:", dnsSplitDomains)
+	log.Println("ScheduleState:", scheduleState)
+	log.Println("Connectivity:", connectivity)
+	log.Println("Compression:", compression)
+	log.Println("TNDState:", tndState)
+	log.Println("CSDWrapper:", csdWrapper)
+	log.Println("DisconnectReason:", disconnectReason)
+	log.Println("APIs:", apis)
+	log.Println("RouteAggregations:", routeAggregations)
+	log.Println("GatewayExcludes:", gatewayExcludes)
+	log.Println("TrafPolState:", trafPolState)
+	log.Println("PreferredServer:", preferredServer)
+	log.Println("Version:", version)
+	log.Println("Capabilities:", capabilities)
+	log.Println("Uptime:", uptime)
+	log.Println("OwnerUID:", ownerUID)
+	log.Println("Owner:", owner)
+	log.Println("PauseState:", pauseState)
 
 	// handle signals
 	c := make(chan *dbus.Signal, 10)
@@ -127,6 +211,141 @@ func main() {
 					log.Fatal(err)
 				}
 				fmt.Println(vpnConfig)
+			case dbusapi.PropertyProxy:
+				if err := value.Store(&proxy); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(proxy)
+			case dbusapi.PropertyRetryAt:
+				if err := value.Store(&retryAt); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(retryAt)
+			case dbusapi.PropertyRetryAttempt:
+				if err := value.Store(&retryAttempt); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(retryAttempt)
+			case dbusapi.PropertyRXBytes:
+				if err := value.Store(&rxBytes); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(rxBytes)
+			case dbusapi.PropertyTXBytes:
+				if err := value.Store(&txBytes); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(txBytes)
+			case dbusapi.PropertyRXPackets:
+				if err := value.Store(&rxPackets); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(rxPackets)
+			case dbusapi.PropertyTXPackets:
+				if err := value.Store(&txPackets); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(txPackets)
+			case dbusapi.PropertyDNSLeaksBlocked:
+				if err := value.Store(&dnsLeaksBlocked); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(dnsLeaksBlocked)
+			case dbusapi.PropertyDNSServers:
+				if err := value.Store(&dnsServers); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(dnsServers)
+			case dbusapi.PropertyDNSSearchDomains:
+				if err := value.Store(&dnsSearchDomains); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(dnsSearchDomains)
+			case dbusapi.PropertyDNSSplitDomains:
+				if err := value.Store(&dnsSplitDomains); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(dnsSplitDomains)
+			case dbusapi.PropertyScheduleState:
+				if err := value.Store(&scheduleState); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(scheduleState)
+			case dbusapi.PropertyConnectivity:
+				if err := value.Store(&connectivity); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(connectivity)
+			case dbusapi.PropertyCompression:
+				if err := value.Store(&compression); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(compression)
+			case dbusapi.PropertyTNDState:
+				if err := value.Store(&tndState); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(tndState)
+			case dbusapi.PropertyCSDWrapper:
+				if err := value.Store(&csdWrapper); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(csdWrapper)
+			case dbusapi.PropertyDisconnectReason:
+				if err := value.Store(&disconnectReason); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(disconnectReason)
+			case dbusapi.PropertyAPIs:
+				if err := value.Store(&apis); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(apis)
+			case dbusapi.PropertyRouteAggregations:
+				if err := value.Store(&routeAggregations); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(routeAggregations)
+			case dbusapi.PropertyGatewayExcludes:
+				if err := value.Store(&gatewayExcludes); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(gatewayExcludes)
+			case dbusapi.PropertyTrafPolState:
+				if err := value.Store(&trafPolState); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(trafPolState)
+			case dbusapi.PropertyPreferredServer:
+				if err := value.Store(&preferredServer); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(preferredServer)
+			case dbusapi.PropertyCapabilities:
+				if err := value.Store(&capabilities); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(capabilities)
+			case dbusapi.PropertyUptime:
+				if err := value.Store(&uptime); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(uptime)
+			case dbusapi.PropertyOwnerUID:
+				if err := value.Store(&ownerUID); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(ownerUID)
+			case dbusapi.PropertyOwner:
+				if err := value.Store(&owner); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(owner)
+			case dbusapi.PropertyPauseState:
+				if err := value.Store(&pauseState); err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(pauseState)
 			}
 		}
 
@@ -155,6 +374,60 @@ func main() {
 				ocRunning = dbusapi.OCRunningUnknown
 			case dbusapi.PropertyVPNConfig:
 				vpnConfig = dbusapi.VPNConfigInvalid
+			case dbusapi.PropertyProxy:
+				proxy = dbusapi.ProxyInvalid
+			case dbusapi.PropertyRetryAt:
+				retryAt = dbusapi.RetryAtInvalid
+			case dbusapi.PropertyRetryAttempt:
+				retryAttempt = dbusapi.RetryAttemptInvalid
+			case dbusapi.PropertyRXBytes:
+				rxBytes = dbusapi.TrafficStatsInvalid
+			case dbusapi.PropertyTXBytes:
+				txBytes = dbusapi.TrafficStatsInvalid
+			case dbusapi.PropertyRXPackets:
+				rxPackets = dbusapi.TrafficStatsInvalid
+			case dbusapi.PropertyTXPackets:
+				txPackets = dbusapi.TrafficStatsInvalid
+			case dbusapi.PropertyDNSLeaksBlocked:
+				dnsLeaksBlocked = dbusapi.DNSLeaksBlockedInvalid
+			case dbusapi.PropertyDNSServers:
+				dnsServers = dbusapi.DNSDomainsInvalid
+			case dbusapi.PropertyDNSSearchDomains:
+				dnsSearchDomains = dbusapi.DNSDomainsInvalid
+			case dbusapi.PropertyDNSSplitDomains:
+				dnsSplitDomains = dbusapi.DNSDomainsInvalid
+			case dbusapi.PropertyScheduleState:
+				scheduleState = dbusapi.ScheduleStateUnknown
+			case dbusapi.PropertyConnectivity:
+				connectivity = dbusapi.ConnectivityUnknown
+			case dbusapi.PropertyCompression:
+				compression = dbusapi.CompressionInvalid
+			case dbusapi.PropertyTNDState:
+				tndState = dbusapi.TNDStateUnknown
+			case dbusapi.PropertyCSDWrapper:
+				csdWrapper = dbusapi.CSDWrapperInvalid
+			case dbusapi.PropertyDisconnectReason:
+				disconnectReason = dbusapi.DisconnectReasonInvalid
+			case dbusapi.PropertyAPIs:
+				apis = dbusapi.APIsInvalid
+			case dbusapi.PropertyRouteAggregations:
+				routeAggregations = dbusapi.RouteAggregationsInvalid
+			case dbusapi.PropertyGatewayExcludes:
+				gatewayExcludes = dbusapi.GatewayExcludesInvalid
+			case dbusapi.PropertyTrafPolState:
+				trafPolState = dbusapi.TrafPolStateUnknown
+			case dbusapi.PropertyPreferredServer:
+				preferredServer = dbusapi.PreferredServerUnset
+			case dbusapi.PropertyCapabilities:
+				capabilities = dbusapi.CapabilitiesInvalid
+			case dbusapi.PropertyUptime:
+				uptime = dbusapi.UptimeInvalid
+			case dbusapi.PropertyOwnerUID:
+				ownerUID = dbusapi.OwnerUIDInvalid
+			case dbusapi.PropertyOwner:
+				owner = dbusapi.OwnerInvalid
+			case dbusapi.PropertyPauseState:
+				pauseState = dbusapi.PauseStateUnknown
 			}
 			fmt.Printf("Invalidated property: %s\n", name)
 		}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/telekom-mms/oc-daemon/internal/devmon"
 )
@@ -8,7 +10,9 @@ import (
 func main() {
 	log.SetLevel(log.DebugLevel)
 	d := devmon.NewDevMon()
-	go d.Start()
+	if err := d.Start(context.Background()); err != nil {
+		log.WithError(err).Fatal("DevMon could not start")
+	}
 	for u := range d.Updates() {
 		log.Println(u)
 	}
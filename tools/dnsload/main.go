@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"net"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	log "github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/dnsproxy"
+)
+
+var (
+	// command line arguments
+	address     = "127.0.0.1:5301"
+	duration    = 10 * time.Second
+	concurrency = 10
+	names       = []string{"example.com."}
+	unique      = false
+	embedded    = false
+	network     = "udp"
+)
+
+// result is the result of a load test worker
+type result struct {
+	latencies []time.Duration
+	errors    int
+}
+
+// parseCommandLine parses command line arguments
+func parseCommandLine() {
+	addr := flag.String("address", address, "set DNS server `address`")
+	dur := flag.Duration("duration", duration, "set test `duration`")
+	conc := flag.Int("concurrency", concurrency, "set `number` of "+
+		"concurrent clients")
+	nms := flag.String("names", strings.Join(names, ","), "set query "+
+		"`names` as comma-separated list")
+	uniq := flag.Bool("unique", unique, "prefix query names with random "+
+		"labels to avoid cache hits")
+	embd := flag.Bool("embedded", embedded, "run an embedded DNS-Proxy "+
+		"with a local remote server on address")
+	tcp := flag.Bool("tcp", false, "use TCP instead of UDP")
+	flag.Parse()
+
+	address = *addr
+	duration = *dur
+	concurrency = *conc
+	names = strings.Split(*nms, ",")
+	unique = *uniq
+	embedded = *embd
+	if *tcp {
+		network = "tcp"
+	}
+	if concurrency < 1 {
+		log.Fatal("DNS-Load got invalid concurrency from command line")
+	}
+}
+
+// startRemote starts a local remote DNS server that answers all A queries
+// and returns its address
+func startRemote() string {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		log.WithError(err).Fatal("DNS-Load could not start remote server")
+	}
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		m.Answer = append(m.Answer, &dns.A{
+			Hdr: dns.RR_Header{
+				Name:   r.Question[0].Name,
+				Rrtype: dns.TypeA,
+				Class:  dns.ClassINET,
+				Ttl:    300,
+			},
+			A: net.IPv4(192, 168, 1, 1),
+		})
+		_ = w.WriteMsg(m)
+	})
+	started := make(chan struct{})
+	server := &dns.Server{
+		PacketConn:        pc,
+		Handler:           handler,
+		NotifyStartedFunc: func() { close(started) },
+	}
+	go func() { _ = server.ActivateAndServe() }()
+	<-started
+	return pc.LocalAddr().String()
+}
+
+// startProxy starts an embedded DNS-Proxy on address that forwards all
+// queries to a local remote server
+func startProxy() *dnsproxy.Proxy {
+	remote := startRemote()
+	p := dnsproxy.NewProxy(address)
+	p.SetRemotes(map[string][]string{".": {remote}})
+	if err := p.Start(context.Background()); err != nil {
+		log.WithError(err).Fatal("DNS-Load could not start DNS-Proxy")
+	}
+	go func() {
+		for r := range p.Reports() {
+			r.Done()
+		}
+	}()
+
+	// give the dns servers time to start
+	time.Sleep(100 * time.Millisecond)
+	return p
+}
+
+// queryName returns the query name for query i
+func queryName(i int) string {
+	name := names[i%len(names)]
+	if unique {
+		name = fmt.Sprintf("r%d-%d.%s", rand.Int63(), i, name)
+	}
+	return name
+}
+
+// runWorker sends queries until stop is closed
+func runWorker(stop chan struct{}, results chan *result) {
+	r := &result{}
+	defer func() { results <- r }()
+
+	client := &dns.Client{
+		Net:     network,
+		Timeout: 2 * time.Second,
+	}
+	for i := 0; ; i++ {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		q := new(dns.Msg)
+		q.SetQuestion(queryName(i), dns.TypeA)
+		start := time.Now()
+		_, _, err := client.Exchange(q, address)
+		if err != nil {
+			r.errors++
+			continue
+		}
+		r.latencies = append(r.latencies, time.Since(start))
+	}
+}
+
+// percentile returns the p-th percentile of the sorted latencies
+func percentile(latencies []time.Duration, p float64) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	i := int(float64(len(latencies)-1) * p)
+	return latencies[i]
+}
+
+func main() {
+	parseCommandLine()
+
+	// start embedded proxy
+	if embedded {
+		p := startProxy()
+		defer p.Stop()
+	}
+
+	// run load test
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	stop := make(chan struct{})
+	results := make(chan *result, concurrency)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runWorker(stop, results)
+		}()
+	}
+	time.Sleep(duration)
+	close(stop)
+	wg.Wait()
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	close(results)
+
+	// collect results
+	latencies := []time.Duration{}
+	errors := 0
+	for r := range results {
+		latencies = append(latencies, r.latencies...)
+		errors += r.errors
+	}
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+
+	// print results
+	queries := len(latencies)
+	fmt.Printf("Queries:      %d\n", queries)
+	fmt.Printf("Errors:       %d\n", errors)
+	fmt.Printf("QPS:          %.1f\n", float64(queries)/elapsed.Seconds())
+	fmt.Printf("Latency p50:  %s\n", percentile(latencies, 0.50))
+	fmt.Printf("Latency p99:  %s\n", percentile(latencies, 0.99))
+	if queries > 0 {
+		// note: this includes allocations of the load generator and,
+		// in embedded mode, of the DNS-Proxy
+		allocs := after.Mallocs - before.Mallocs
+		bytes := after.TotalAlloc - before.TotalAlloc
+		fmt.Printf("Allocs/query: %d\n", allocs/uint64(queries))
+		fmt.Printf("Bytes/query:  %d\n", bytes/uint64(queries))
+	}
+}
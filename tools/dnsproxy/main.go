@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"strings"
 
@@ -71,7 +72,9 @@ func main() {
 	p := dnsproxy.NewProxy(address)
 	p.SetRemotes(remotes)
 	p.SetWatches(watches)
-	go p.Start()
+	if err := p.Start(context.Background()); err != nil {
+		log.WithError(err).Fatal("DNS-Proxy could not start")
+	}
 	for r := range p.Reports() {
 		log.WithField("report", r).Debug("DNS-Proxy got watched domain report")
 		r.Done()
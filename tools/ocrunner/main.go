@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"time"
 
@@ -56,7 +57,9 @@ func main() {
 	// connect client
 	c := ocrunner.NewConnect(*profile, *script, "oc-daemon-tun0")
 	done := make(chan struct{})
-	go c.Start()
+	if err := c.Start(context.Background()); err != nil {
+		log.Fatal(err)
+	}
 	go func() {
 		for e := range c.Events() {
 			log.WithField("event", e).Debug("OC-Runner got event")
@@ -67,7 +70,7 @@ func main() {
 		done <- struct{}{}
 	}()
 	if *connect {
-		c.Connect(a.GetLogin(), []string{})
+		c.Connect(a.GetLogin(), []string{}, "", "", "", "", false)
 	}
 
 	// disconnect client
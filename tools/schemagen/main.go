@@ -0,0 +1,59 @@
+// Command schemagen generates the JSON Schemas of the JSON payloads of the
+// daemon in api/schema, run it with "go generate ./..." after changing the
+// payload types
+package main
+
+//go:generate go run . -dir ../../api/schema
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/internal/audit"
+	"github.com/telekom-mms/oc-daemon/internal/daemon"
+	"github.com/telekom-mms/oc-daemon/internal/jsonschema"
+	"github.com/telekom-mms/oc-daemon/pkg/logininfo"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnconfig"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+)
+
+// payloads are the JSON payloads of the daemon by schema file name
+var payloads = []struct {
+	file  string
+	title string
+	value any
+}{
+	{"vpnstatus.schema.json", "VPN Status", vpnstatus.Status{}},
+	{"vpnconfig.schema.json", "VPN Configuration", vpnconfig.Config{}},
+	{"vpnconfigupdate.schema.json", "VPN Configuration Update",
+		daemon.VPNConfigUpdate{}},
+	{"logininfo.schema.json", "Login Information", logininfo.LoginInfo{}},
+	{"audit.schema.json", "Audit Log Record", audit.Record{}},
+}
+
+func main() {
+	dir := flag.String("dir", "api/schema", "write schemas to `directory`")
+	flag.Parse()
+
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		log.Fatal(err)
+	}
+
+	g := jsonschema.NewGenerator()
+	g.Versions[reflect.TypeOf(vpnstatus.Status{})] = vpnstatus.JSONVersion
+	g.Versions[reflect.TypeOf(vpnconfig.Config{})] = vpnconfig.JSONVersion
+	for _, p := range payloads {
+		b, err := g.Generate(p.value, p.title).JSON()
+		if err != nil {
+			log.Fatal(err)
+		}
+		file := filepath.Join(*dir, p.file)
+		if err := os.WriteFile(file, b, 0644); err != nil {
+			log.Fatal(err)
+		}
+		log.WithField("file", file).Info("Generated schema")
+	}
+}
@@ -0,0 +1,235 @@
+// Command soak is a soak test of a running daemon: it drives repeated
+// connect/disconnect cycles, XML profile reloads and network flaps and
+// verifies that no routes, rules or file descriptors of the daemon leak
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/telekom-mms/oc-daemon/pkg/client"
+	"github.com/telekom-mms/oc-daemon/pkg/vpnstatus"
+	"github.com/vishvananda/netlink"
+)
+
+var (
+	// command line arguments
+	cycles         = 1000
+	checkEvery     = 100
+	stateTimeout   = time.Minute
+	pause          = time.Second
+	reloadProfile  = false
+	flapDevice     = ""
+	flapDuration   = 5 * time.Second
+	netnsName      = ""
+	lockFile       = "/run/oc-daemon/daemon.pid"
+	fdSlack        = 2
+	configFile     = ""
+	continueOnLeak = false
+)
+
+// parseCommandLine parses the command line arguments
+func parseCommandLine() {
+	flag.IntVar(&cycles, "cycles", cycles, "run `number` of "+
+		"connect/disconnect cycles")
+	flag.IntVar(&checkEvery, "check-every", checkEvery, "check for leaks "+
+		"every `number` of cycles")
+	flag.DurationVar(&stateTimeout, "timeout", stateTimeout, "wait "+
+		"`duration` for connection state changes")
+	flag.DurationVar(&pause, "pause", pause, "pause `duration` between "+
+		"steps of a cycle")
+	flag.BoolVar(&reloadProfile, "reload-profile", reloadProfile,
+		"reload the XML profile while connected")
+	flag.StringVar(&flapDevice, "flap", flapDevice, "flap network "+
+		"`device` while connected, e.g., eth0")
+	flag.DurationVar(&flapDuration, "flap-duration", flapDuration,
+		"keep flapped device down for `duration`")
+	flag.StringVar(&netnsName, "netns", netnsName, "use network "+
+		"namespace `name` of a daemon running in a test network namespace")
+	flag.StringVar(&lockFile, "lockfile", lockFile, "read daemon pid "+
+		"from run lock `file`")
+	flag.IntVar(&fdSlack, "fd-slack", fdSlack, "allow `number` of "+
+		"additional daemon file descriptors")
+	flag.StringVar(&configFile, "config", configFile, "use client config "+
+		"`file` instead of user or system config")
+	flag.BoolVar(&continueOnLeak, "continue", continueOnLeak,
+		"continue after leaks were found")
+	flag.Parse()
+}
+
+// waitState waits until the connection state of the daemon is state
+func waitState(c client.Client, state vpnstatus.ConnectionState) error {
+	deadline := time.Now().Add(stateTimeout)
+	for time.Now().Before(deadline) {
+		status, err := c.Query()
+		if err != nil {
+			return err
+		}
+		if status.ConnectionState == state {
+			return nil
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	return fmt.Errorf("timeout waiting for connection state %s", state)
+}
+
+// connect authenticates and connects to the VPN and waits until the VPN
+// is connected
+func connect(c client.Client) error {
+	if err := c.Authenticate(); err != nil {
+		return fmt.Errorf("could not authenticate: %w", err)
+	}
+	if err := c.Connect(); err != nil {
+		return fmt.Errorf("could not connect: %w", err)
+	}
+	return waitState(c, vpnstatus.ConnectionStateConnected)
+}
+
+// disconnect disconnects from the VPN and waits until the VPN is
+// disconnected
+func disconnect(c client.Client) error {
+	if err := c.Disconnect(); err != nil {
+		return fmt.Errorf("could not disconnect: %w", err)
+	}
+	return waitState(c, vpnstatus.ConnectionStateDisconnected)
+}
+
+// flap sets the network device down and up again
+func flap(h *netlink.Handle) error {
+	link, err := h.LinkByName(flapDevice)
+	if err != nil {
+		return fmt.Errorf("could not get device %s: %w", flapDevice, err)
+	}
+	if err := h.LinkSetDown(link); err != nil {
+		return fmt.Errorf("could not set device %s down: %w", flapDevice, err)
+	}
+	time.Sleep(flapDuration)
+	if err := h.LinkSetUp(link); err != nil {
+		return fmt.Errorf("could not set device %s up: %w", flapDevice, err)
+	}
+	return nil
+}
+
+// cycle runs one connect/disconnect cycle with the optional profile reload
+// and network flap while connected
+func cycle(c client.Client, h *netlink.Handle) error {
+	if err := connect(c); err != nil {
+		return err
+	}
+	time.Sleep(pause)
+
+	if reloadProfile {
+		if err := c.ReloadProfile(); err != nil {
+			return fmt.Errorf("could not reload profile: %w", err)
+		}
+		time.Sleep(pause)
+	}
+
+	if flapDevice != "" {
+		if err := flap(h); err != nil {
+			return err
+		}
+		time.Sleep(pause)
+	}
+
+	if err := disconnect(c); err != nil {
+		return err
+	}
+	time.Sleep(pause)
+	return nil
+}
+
+// newClient returns a new client with the configuration
+func newClient() (client.Client, error) {
+	config := client.LoadUserSystemConfig()
+	if configFile != "" {
+		c, err := client.LoadConfig(configFile)
+		if err != nil {
+			return nil, err
+		}
+		config = c
+	}
+	if config == nil || config.Empty() {
+		return nil, errors.New("empty client configuration")
+	}
+	return client.NewClient(config)
+}
+
+// run runs the soak test, it returns an error if it failed or leaks were
+// found
+func run() error {
+	h, err := newHandle(netnsName)
+	if err != nil {
+		return err
+	}
+	defer h.Delete()
+
+	c, err := newClient()
+	if err != nil {
+		return fmt.Errorf("could not create client: %w", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	// take baseline snapshot while disconnected
+	if err := waitState(c, vpnstatus.ConnectionStateDisconnected); err != nil {
+		return fmt.Errorf("daemon is not disconnected: %w", err)
+	}
+	base, err := takeSnapshot(h, lockFile)
+	if err != nil {
+		return err
+	}
+	log.WithField("baseline", base).Info("Soak test starting")
+
+	failed := false
+	start := time.Now()
+	for i := 1; i <= cycles; i++ {
+		if err := cycle(c, h); err != nil {
+			return fmt.Errorf("cycle %d failed: %w", i, err)
+		}
+		if i%checkEvery != 0 && i != cycles {
+			continue
+		}
+
+		// check for leaks
+		s, err := takeSnapshot(h, lockFile)
+		if err != nil {
+			return err
+		}
+		leaks := s.leaks(base, fdSlack)
+		log.WithFields(log.Fields{
+			"cycle":    i,
+			"elapsed":  time.Since(start).Round(time.Second),
+			"snapshot": s,
+		}).Info("Soak test checked resources")
+		if len(leaks) > 0 {
+			failed = true
+			log.WithField("leaks", strings.Join(leaks, ", ")).
+				Error("Soak test found leaks")
+			if !continueOnLeak {
+				break
+			}
+		}
+	}
+
+	if failed {
+		return errors.New("leaks found")
+	}
+	return nil
+}
+
+func main() {
+	parseCommandLine()
+	if cycles < 1 || checkEvery < 1 {
+		log.Fatal("Soak test needs at least 1 cycle and check")
+	}
+	if err := run(); err != nil {
+		log.WithError(err).Error("Soak test failed")
+		os.Exit(1)
+	}
+	log.Info("Soak test passed")
+}
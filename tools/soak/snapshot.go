@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
+)
+
+// snapshot contains the system resources used by the daemon that must not
+// leak across connect/disconnect cycles
+type snapshot struct {
+	Routes int
+	Rules  int
+	FDs    int
+}
+
+// String returns the snapshot as string
+func (s *snapshot) String() string {
+	return fmt.Sprintf("routes: %d, rules: %d, fds: %d", s.Routes, s.Rules,
+		s.FDs)
+}
+
+// leaks returns the resources in s that grew compared to base, file
+// descriptors may grow by fdSlack
+func (s *snapshot) leaks(base *snapshot, fdSlack int) []string {
+	leaks := []string{}
+	if s.Routes > base.Routes {
+		leaks = append(leaks, fmt.Sprintf("%d routes", s.Routes-base.Routes))
+	}
+	if s.Rules > base.Rules {
+		leaks = append(leaks, fmt.Sprintf("%d rules", s.Rules-base.Rules))
+	}
+	if s.FDs > base.FDs+fdSlack {
+		leaks = append(leaks, fmt.Sprintf("%d fds", s.FDs-base.FDs))
+	}
+	return leaks
+}
+
+// newHandle returns a netlink handle in the network namespace with name,
+// the current network namespace if name is empty
+func newHandle(name string) (*netlink.Handle, error) {
+	if name == "" {
+		return netlink.NewHandle()
+	}
+	ns, err := netns.GetFromName(name)
+	if err != nil {
+		return nil, fmt.Errorf("could not get network namespace %s: %w",
+			name, err)
+	}
+	defer func() { _ = ns.Close() }()
+	return netlink.NewHandleAt(ns)
+}
+
+// daemonPID returns the pid of the daemon in the run lock file
+func daemonPID(lockFile string) (int, error) {
+	b, err := os.ReadFile(lockFile)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(b)))
+}
+
+// countFDs returns the number of open file descriptors of process pid
+func countFDs(pid int) (int, error) {
+	entries, err := os.ReadDir(fmt.Sprintf("/proc/%d/fd", pid))
+	if err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+// takeSnapshot returns a snapshot of the routes and rules in all tables of
+// handle h and of the file descriptors of the daemon
+func takeSnapshot(h *netlink.Handle, lockFile string) (*snapshot, error) {
+	routes, err := h.RouteListFiltered(netlink.FAMILY_ALL,
+		&netlink.Route{Table: unix.RT_TABLE_UNSPEC}, netlink.RT_FILTER_TABLE)
+	if err != nil {
+		return nil, fmt.Errorf("could not list routes: %w", err)
+	}
+	rules, err := h.RuleList(netlink.FAMILY_ALL)
+	if err != nil {
+		return nil, fmt.Errorf("could not list rules: %w", err)
+	}
+	pid, err := daemonPID(lockFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not get daemon pid: %w", err)
+	}
+	fds, err := countFDs(pid)
+	if err != nil {
+		return nil, fmt.Errorf("could not count daemon fds: %w", err)
+	}
+	return &snapshot{
+		Routes: len(routes),
+		Rules:  len(rules),
+		FDs:    fds,
+	}, nil
+}